@@ -65,6 +65,13 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 	flLabels := opts.NewListOpts(nil)
 	cmd.Var(&flLabels, []string{"-label"}, "Set metadata for an image")
 
+	flOutput := cmd.String([]string{"o", "-output"}, "", "Export the filesystem of the image to a directory or tar archive, instead of keeping the image")
+
+	flCheck := cmd.Bool([]string{"-check"}, false, "Only check the Dockerfile for problems, without building it")
+
+	flSecrets := opts.NewListOpts(nil)
+	cmd.Var(&flSecrets, []string{"-secret"}, "Secret file to expose to RUN --mount=type=secret (format: id=mysecret,src=/local/secret)")
+
 	ulimits := make(map[string]*units.Ulimit)
 	flUlimits := runconfigopts.NewUlimitOpt(&ulimits)
 	cmd.Var(flUlimits, []string{"-ulimit"}, "Ulimit options")
@@ -91,8 +98,28 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 		buildBuff     io.Writer
 	)
 
+	var output *buildOutput
+	if *flOutput != "" {
+		if *flCheck {
+			return fmt.Errorf("--output and --check cannot be used together")
+		}
+		output, err = parseBuildOutput(*flOutput)
+		if err != nil {
+			return err
+		}
+	}
+
+	secrets, err := readBuildSecrets(flSecrets.GetAll())
+	if err != nil {
+		return err
+	}
+
 	progBuff = cli.out
 	buildBuff = cli.out
+	if output != nil && output.toStdout() {
+		progBuff = cli.err
+		buildBuff = cli.err
+	}
 	if *suppressOutput {
 		progBuff = bytes.NewBuffer(nil)
 		buildBuff = bytes.NewBuffer(nil)
@@ -233,6 +260,8 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 		BuildArgs:      runconfigopts.ConvertKVStringsToMap(flBuildArg.GetAll()),
 		AuthConfigs:    cli.retrieveAuthConfigs(),
 		Labels:         runconfigopts.ConvertKVStringsToMap(flLabels.GetAll()),
+		Secrets:        secrets,
+		CheckOnly:      *flCheck,
 	}
 
 	response, err := cli.client.ImageBuild(context.Background(), body, options)
@@ -241,7 +270,8 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 	}
 	defer response.Body.Close()
 
-	err = jsonmessage.DisplayJSONMessagesStream(response.Body, buildBuff, cli.outFd, cli.isTerminalOut, nil)
+	var result types.BuildResult
+	err = jsonmessage.DisplayJSONMessagesStream(response.Body, buildBuff, cli.outFd, cli.isTerminalOut, buildResultAux(&result))
 	if err != nil {
 		if jerr, ok := err.(*jsonmessage.JSONError); ok {
 			// If no error code is set, default to 1
@@ -264,7 +294,27 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 	// Everything worked so if -q was provided the output from the daemon
 	// should be just the image ID and we'll print that to stdout.
 	if *suppressOutput {
-		fmt.Fprintf(cli.out, "%s", buildBuff)
+		if output != nil && output.toStdout() {
+			fmt.Fprintf(cli.err, "%s", buildBuff)
+		} else {
+			fmt.Fprintf(cli.out, "%s", buildBuff)
+		}
+	}
+
+	if *flCheck {
+		if len(result.Warnings) > 0 {
+			return Cli.StatusError{StatusCode: 1}
+		}
+		return nil
+	}
+
+	if output != nil {
+		if result.ID == "" {
+			return fmt.Errorf("the daemon did not report the ID of the image built, it may be too old to support --output")
+		}
+		if err := cli.exportBuildOutput(context.Background(), result.ID, output, len(flTags.GetAll()) == 0); err != nil {
+			return fmt.Errorf("Error exporting the build output: %v", err)
+		}
 	}
 
 	if isTrusted() {
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/container"
+)
+
+// buildOutput is where `docker build --output` exports the filesystem of the
+// image built.
+type buildOutput struct {
+	// typ is "local" to extract the filesystem to the directory dest, or
+	// "tar" to write it as a tar archive to the file dest, or to STDOUT if
+	// dest is "-".
+	typ  string
+	dest string
+}
+
+// parseBuildOutput parses the value of --output, a comma separated list of
+// type=local|tar and dest=<path> options, or the path of a directory as a
+// shortcut for type=local,dest=<path>.
+func parseBuildOutput(val string) (*buildOutput, error) {
+	if !strings.Contains(val, "=") {
+		return &buildOutput{typ: "local", dest: val}, nil
+	}
+	out := &buildOutput{}
+	for _, field := range strings.Split(val, ",") {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid output option %q: expected key=value", field)
+		}
+		switch key, value := strings.ToLower(strings.TrimSpace(parts[0])), strings.TrimSpace(parts[1]); key {
+		case "type":
+			if value != "local" && value != "tar" {
+				return nil, fmt.Errorf("invalid output type %q: must be local or tar", value)
+			}
+			out.typ = value
+		case "dest":
+			out.dest = value
+		default:
+			return nil, fmt.Errorf("unknown output option %q", key)
+		}
+	}
+	if out.typ == "" {
+		return nil, fmt.Errorf("invalid output %q: type is required", val)
+	}
+	if out.dest == "" {
+		return nil, fmt.Errorf("invalid output %q: dest is required", val)
+	}
+	if out.typ == "local" && out.dest == "-" {
+		return nil, fmt.Errorf("invalid output %q: only the tar type can be written to STDOUT", val)
+	}
+	return out, nil
+}
+
+// toStdout returns whether the output is written to STDOUT, in which case the
+// build output is written to STDERR instead.
+func (out *buildOutput) toStdout() bool {
+	return out.typ == "tar" && out.dest == "-"
+}
+
+// buildResultAux returns a callback for the aux messages of a build, storing
+// the ID of the image built and the warnings found in result.
+func buildResultAux(result *types.BuildResult) func(*json.RawMessage) {
+	return func(aux *json.RawMessage) {
+		var r types.BuildResult
+		if err := json.Unmarshal(*aux, &r); err != nil {
+			return
+		}
+		if r.ID != "" {
+			result.ID = r.ID
+		}
+		result.Warnings = append(result.Warnings, r.Warnings...)
+	}
+}
+
+// exportBuildOutput exports the filesystem of the image imageID to out,
+// through a container that is created but never started. With removeImage,
+// the image is removed afterwards, keeping its parents for the build cache.
+func (cli *DockerCli) exportBuildOutput(ctx context.Context, imageID string, out *buildOutput, removeImage bool) (err error) {
+	if removeImage {
+		defer func() {
+			if _, rmErr := cli.client.ImageRemove(ctx, imageID, types.ImageRemoveOptions{}); rmErr != nil && err == nil {
+				err = rmErr
+			}
+		}()
+	}
+
+	// The command is required to create the container, but never runs.
+	resp, err := cli.client.ContainerCreate(ctx, &container.Config{Image: imageID, Cmd: []string{"true"}}, &container.HostConfig{}, nil, types.ContainerCreateOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if rmErr := cli.client.ContainerRemove(ctx, resp.ID, types.ContainerRemoveOptions{RemoveVolumes: true, Force: true}); rmErr != nil && err == nil {
+			err = rmErr
+		}
+	}()
+
+	content, err := cli.client.ContainerExport(ctx, resp.ID)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	switch {
+	case out.toStdout():
+		_, err = io.Copy(cli.out, content)
+		return err
+	case out.typ == "tar":
+		f, err := os.Create(out.dest)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(f, content); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	default:
+		if err := os.MkdirAll(out.dest, 0755); err != nil {
+			return err
+		}
+		// The files are owned by the user running the client.
+		return archive.Untar(content, out.dest, &archive.TarOptions{NoLchown: true})
+	}
+}
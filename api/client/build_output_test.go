@@ -0,0 +1,35 @@
+package client
+
+import "testing"
+
+func TestParseBuildOutput(t *testing.T) {
+	valid := map[string]buildOutput{
+		"./out":                     {typ: "local", dest: "./out"},
+		"type=local,dest=./out":     {typ: "local", dest: "./out"},
+		"dest=out.tar, type=tar":    {typ: "tar", dest: "out.tar"},
+		"type=tar,dest=-":           {typ: "tar", dest: "-"},
+		"Type=local,Dest=/tmp/out/": {typ: "local", dest: "/tmp/out/"},
+	}
+	for val, expected := range valid {
+		out, err := parseBuildOutput(val)
+		if err != nil {
+			t.Fatalf("%s: %v", val, err)
+		}
+		if *out != expected {
+			t.Fatalf("%s: expected %+v, got %+v", val, expected, *out)
+		}
+	}
+
+	for _, val := range []string{
+		"type=image,dest=out",
+		"type=local",
+		"dest=out",
+		"type=local,dest=-",
+		"type=tar,dest=out,compression=gzip",
+		"type=tar,out",
+	} {
+		if _, err := parseBuildOutput(val); err == nil {
+			t.Fatalf("%s: expected an error", val)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// maxBuildSecretSize is the maximum size of a secret passed with
+// `docker build --secret`, as secrets are sent to the daemon in a header.
+const maxBuildSecretSize = 500 * 1024
+
+// parseBuildSecret parses the value of --secret, a comma separated list of
+// id=<id> and src=<path> options, and returns the id and the path.
+func parseBuildSecret(val string) (id, src string, err error) {
+	for _, field := range strings.Split(val, ",") {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 {
+			return "", "", fmt.Errorf("invalid secret option %q: expected key=value", field)
+		}
+		switch key, value := strings.ToLower(strings.TrimSpace(parts[0])), strings.TrimSpace(parts[1]); key {
+		case "id":
+			id = value
+		case "src", "source":
+			src = value
+		default:
+			return "", "", fmt.Errorf("unknown secret option %q", key)
+		}
+	}
+	if id == "" {
+		return "", "", fmt.Errorf("invalid secret %q: id is required", val)
+	}
+	if strings.ContainsAny(id, `/\`) {
+		return "", "", fmt.Errorf("invalid secret %q: id must not contain a path separator", val)
+	}
+	if src == "" {
+		return "", "", fmt.Errorf("invalid secret %q: src is required", val)
+	}
+	return id, src, nil
+}
+
+// readBuildSecrets reads the files of the secrets given with --secret.
+func readBuildSecrets(vals []string) (map[string][]byte, error) {
+	if len(vals) == 0 {
+		return nil, nil
+	}
+	secrets := make(map[string][]byte, len(vals))
+	for _, val := range vals {
+		id, src, err := parseBuildSecret(val)
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := secrets[id]; exists {
+			return nil, fmt.Errorf("secret %s is specified more than once", id)
+		}
+		fi, err := os.Stat(src)
+		if err != nil {
+			return nil, fmt.Errorf("error reading secret %s: %v", id, err)
+		}
+		if fi.Size() > maxBuildSecretSize {
+			return nil, fmt.Errorf("secret %s is too large, the maximum size is %d bytes", id, maxBuildSecretSize)
+		}
+		data, err := ioutil.ReadFile(src)
+		if err != nil {
+			return nil, fmt.Errorf("error reading secret %s: %v", id, err)
+		}
+		secrets[id] = data
+	}
+	return secrets, nil
+}
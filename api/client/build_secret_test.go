@@ -0,0 +1,51 @@
+package client
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseBuildSecret(t *testing.T) {
+	id, src, err := parseBuildSecret("id=npmrc,src=/home/user/.npmrc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "npmrc" || src != "/home/user/.npmrc" {
+		t.Fatalf("expected npmrc and /home/user/.npmrc, got %s and %s", id, src)
+	}
+
+	for _, val := range []string{"src=/file", "id=foo", "id=a/b,src=/file", "id=foo,src=/file,mode=0400", "foo"} {
+		if _, _, err := parseBuildSecret(val); err == nil {
+			t.Fatalf("expected an error for %q", val)
+		}
+	}
+}
+
+func TestReadBuildSecrets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "build-secret-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	src := filepath.Join(dir, "secret")
+	if err := ioutil.WriteFile(src, []byte("s3cr3t"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	secrets, err := readBuildSecrets([]string{"id=foo,src=" + src})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(secrets["foo"]) != "s3cr3t" {
+		t.Fatalf("expected the content of secret foo to be s3cr3t, got %q", secrets["foo"])
+	}
+
+	if _, err := readBuildSecrets([]string{"id=foo,src=" + src, "id=foo,src=" + src}); err == nil {
+		t.Fatal("expected an error for a duplicate secret id")
+	}
+	if _, err := readBuildSecrets([]string{"id=foo,src=" + filepath.Join(dir, "missing")}); err == nil {
+		t.Fatal("expected an error for a missing secret file")
+	}
+}
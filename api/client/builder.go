@@ -0,0 +1,83 @@
+package client
+
+import (
+	"fmt"
+
+	"golang.org/x/net/context"
+
+	Cli "github.com/docker/docker/cli"
+	"github.com/docker/docker/opts"
+	flag "github.com/docker/docker/pkg/mflag"
+	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/filters"
+	"github.com/docker/go-units"
+)
+
+// CmdBuilder is the parent subcommand for all builder commands
+//
+// Usage: docker builder <COMMAND> <OPTS>
+func (cli *DockerCli) CmdBuilder(args ...string) error {
+	description := Cli.DockerCommands["builder"].Description + "\n\nCommands:\n"
+	commands := [][]string{
+		{"prune", "Remove unused build cache"},
+	}
+
+	for _, cmd := range commands {
+		description += fmt.Sprintf("  %-25.25s%s\n", cmd[0], cmd[1])
+	}
+
+	description += "\nRun 'docker builder COMMAND --help' for more information on a command"
+	cmd := Cli.Subcmd("builder", []string{"[COMMAND]"}, description, false)
+
+	cmd.Require(flag.Exact, 0)
+	err := cmd.ParseFlags(args, true)
+	cmd.Usage()
+	return err
+}
+
+// CmdBuilderPrune removes the images committed by builds that are no longer
+// used, from the least recently used.
+//
+// Usage: docker builder prune [OPTIONS]
+func (cli *DockerCli) CmdBuilderPrune(args ...string) error {
+	cmd := Cli.Subcmd("builder prune", nil, "Remove unused build cache", true)
+	force := cmd.Bool([]string{"f", "-force"}, false, "Do not prompt for confirmation")
+	keepStorage := cmd.String([]string{"-keep-storage"}, "", "Amount of build cache to keep, such as 10GB")
+	flFilter := opts.NewListOpts(nil)
+	cmd.Var(&flFilter, []string{"-filter"}, "Provide filter values (i.e. 'until=24h')")
+
+	cmd.Require(flag.Exact, 0)
+	cmd.ParseFlags(args, true)
+
+	options := types.BuildCachePruneOptions{
+		Filters: filters.NewArgs(),
+	}
+	for _, f := range flFilter.GetAll() {
+		var err error
+		options.Filters, err = filters.ParseFlag(f, options.Filters)
+		if err != nil {
+			return err
+		}
+	}
+	if *keepStorage != "" {
+		var err error
+		options.KeepStorage, err = units.FromHumanSize(*keepStorage)
+		if err != nil {
+			return fmt.Errorf("invalid --keep-storage %q: %v", *keepStorage, err)
+		}
+	}
+
+	if !*force && !cli.confirm("WARNING! This will remove the build cache no image or container uses.") {
+		return nil
+	}
+
+	report, err := cli.client.BuildCachePrune(context.Background(), options)
+	if err != nil {
+		return err
+	}
+	for _, id := range report.CachesDeleted {
+		fmt.Fprintf(cli.out, "Deleted: %s\n", id)
+	}
+	fmt.Fprintf(cli.out, "Total reclaimed space: %s\n", units.HumanSize(float64(report.SpaceReclaimed)))
+	return nil
+}
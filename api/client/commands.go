@@ -5,7 +5,13 @@ func (cli *DockerCli) Command(name string) func(...string) error {
 	return map[string]func(...string) error{
 		"attach":             cli.CmdAttach,
 		"build":              cli.CmdBuild,
+		"builder":            cli.CmdBuilder,
+		"builder prune":      cli.CmdBuilderPrune,
 		"commit":             cli.CmdCommit,
+		"container":          cli.CmdContainer,
+		"container clone":    cli.CmdContainerClone,
+		"container prune":    cli.CmdContainerPrune,
+		"container refresh":  cli.CmdContainerRefresh,
 		"cp":                 cli.CmdCp,
 		"create":             cli.CmdCreate,
 		"diff":               cli.CmdDiff,
@@ -17,6 +23,11 @@ func (cli *DockerCli) Command(name string) func(...string) error {
 		"import":             cli.CmdImport,
 		"info":               cli.CmdInfo,
 		"inspect":            cli.CmdInspect,
+		"job":                cli.CmdJob,
+		"job create":         cli.CmdJobCreate,
+		"job inspect":        cli.CmdJobInspect,
+		"job ls":             cli.CmdJobLs,
+		"job rm":             cli.CmdJobRm,
 		"kill":               cli.CmdKill,
 		"load":               cli.CmdLoad,
 		"login":              cli.CmdLogin,
@@ -28,6 +39,7 @@ func (cli *DockerCli) Command(name string) func(...string) error {
 		"network disconnect": cli.CmdNetworkDisconnect,
 		"network inspect":    cli.CmdNetworkInspect,
 		"network ls":         cli.CmdNetworkLs,
+		"network prune":      cli.CmdNetworkPrune,
 		"network rm":         cli.CmdNetworkRm,
 		"pause":              cli.CmdPause,
 		"port":               cli.CmdPort,
@@ -46,6 +58,13 @@ func (cli *DockerCli) Command(name string) func(...string) error {
 		"stop":               cli.CmdStop,
 		"tag":                cli.CmdTag,
 		"top":                cli.CmdTop,
+		"trust":              cli.CmdTrust,
+		"trust backup":       cli.CmdTrustBackup,
+		"trust generate":     cli.CmdTrustGenerate,
+		"trust keys":         cli.CmdTrustKeys,
+		"trust rotate":       cli.CmdTrustRotate,
+		"trust sign":         cli.CmdTrustSign,
+		"trust signers":      cli.CmdTrustSigners,
 		"unpause":            cli.CmdUnpause,
 		"update":             cli.CmdUpdate,
 		"version":            cli.CmdVersion,
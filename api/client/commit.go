@@ -19,6 +19,7 @@ import (
 func (cli *DockerCli) CmdCommit(args ...string) error {
 	cmd := Cli.Subcmd("commit", []string{"CONTAINER [REPOSITORY[:TAG]]"}, Cli.DockerCommands["commit"].Description, true)
 	flPause := cmd.Bool([]string{"p", "-pause"}, true, "Pause container during commit")
+	flSnapshot := cmd.Bool([]string{"-snapshot"}, false, "Commit a snapshot of the container taken without pausing it")
 	flComment := cmd.String([]string{"m", "-message"}, "", "Commit message")
 	flAuthor := cmd.String([]string{"a", "-author"}, "", "Author (e.g., \"John Hannibal Smith <hannibal@a-team.com>\")")
 	flChanges := opts.NewListOpts(nil)
@@ -49,6 +50,7 @@ func (cli *DockerCli) CmdCommit(args ...string) error {
 		Author:    *flAuthor,
 		Changes:   flChanges.GetAll(),
 		Pause:     *flPause,
+		Snapshot:  *flSnapshot,
 		Config:    config,
 	}
 
@@ -0,0 +1,129 @@
+package client
+
+import (
+	"fmt"
+
+	"golang.org/x/net/context"
+
+	Cli "github.com/docker/docker/cli"
+	"github.com/docker/docker/opts"
+	"github.com/docker/docker/pkg/jsonmessage"
+	flag "github.com/docker/docker/pkg/mflag"
+	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/filters"
+)
+
+// CmdContainer is the parent subcommand for all container commands
+//
+// Usage: docker container <COMMAND> <OPTS>
+func (cli *DockerCli) CmdContainer(args ...string) error {
+	description := Cli.DockerCommands["container"].Description + "\n\nCommands:\n"
+	commands := [][]string{
+		{"clone", "Create a copy of a container"},
+		{"prune", "Remove all stopped containers"},
+		{"refresh", "Recreate a container from a newer image"},
+	}
+
+	for _, cmd := range commands {
+		description += fmt.Sprintf("  %-25.25s%s\n", cmd[0], cmd[1])
+	}
+
+	description += "\nRun 'docker container COMMAND --help' for more information on a command"
+	cmd := Cli.Subcmd("container", []string{"[COMMAND]"}, description, false)
+
+	cmd.Require(flag.Exact, 0)
+	err := cmd.ParseFlags(args, true)
+	cmd.Usage()
+	return err
+}
+
+// CmdContainerPrune removes all stopped containers matching the filters.
+//
+// Usage: docker container prune [OPTIONS]
+func (cli *DockerCli) CmdContainerPrune(args ...string) error {
+	cmd := Cli.Subcmd("container prune", nil, "Remove all stopped containers", true)
+	force := cmd.Bool([]string{"f", "-force"}, false, "Do not prompt for confirmation")
+	flFilter := opts.NewListOpts(nil)
+	cmd.Var(&flFilter, []string{"-filter"}, "Provide filter values (i.e. 'until=24h')")
+
+	cmd.Require(flag.Exact, 0)
+	cmd.ParseFlags(args, true)
+
+	pruneFilters := filters.NewArgs()
+	for _, f := range flFilter.GetAll() {
+		var err error
+		pruneFilters, err = filters.ParseFlag(f, pruneFilters)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !*force && !cli.confirm("WARNING! This will remove all stopped containers.") {
+		return nil
+	}
+
+	responseBody, err := cli.client.ContainersPrune(context.Background(), pruneFilters)
+	if err != nil {
+		return err
+	}
+	defer responseBody.Close()
+
+	return jsonmessage.DisplayJSONMessagesStream(responseBody, cli.out, cli.outFd, cli.isTerminalOut, nil)
+}
+
+// CmdContainerRefresh recreates a container from the configuration it was
+// created with and the image its image reference, or --image, currently
+// points to.
+//
+// Usage: docker container refresh [OPTIONS] CONTAINER
+func (cli *DockerCli) CmdContainerRefresh(args ...string) error {
+	cmd := Cli.Subcmd("container refresh", []string{"CONTAINER"}, "Recreate a container from a newer image", true)
+	image := cmd.String([]string{"-image"}, "", "Image to recreate the container from")
+	nSeconds := cmd.Int([]string{"t", "-time"}, 10, "Seconds to wait for stop before killing it")
+	cmd.Require(flag.Exact, 1)
+
+	cmd.ParseFlags(args, true)
+
+	options := types.ContainerRefreshOptions{
+		Image:   *image,
+		Timeout: *nSeconds,
+	}
+	response, err := cli.client.ContainerRefresh(context.Background(), cmd.Arg(0), options)
+	if err != nil {
+		return err
+	}
+	for _, warning := range response.Warnings {
+		fmt.Fprintf(cli.err, "WARNING: %s\n", warning)
+	}
+	fmt.Fprintf(cli.out, "%s\n", response.ID)
+	return nil
+}
+
+// CmdContainerClone creates a new stopped container with the configuration
+// of an existing one.
+//
+// Usage: docker container clone [OPTIONS] CONTAINER
+func (cli *DockerCli) CmdContainerClone(args ...string) error {
+	cmd := Cli.Subcmd("container clone", []string{"CONTAINER"}, "Create a copy of a container", true)
+	name := cmd.String([]string{"-name"}, "", "Assign a name to the clone")
+	copyLayer := cmd.Bool([]string{"-copy-layer"}, false, "Copy the changes made to the container's filesystem")
+	copyVolumes := cmd.Bool([]string{"-copy-volumes"}, false, "Copy the content of the container's anonymous volumes")
+	cmd.Require(flag.Exact, 1)
+
+	cmd.ParseFlags(args, true)
+
+	options := types.ContainerCloneOptions{
+		Name:        *name,
+		CopyLayer:   *copyLayer,
+		CopyVolumes: *copyVolumes,
+	}
+	response, err := cli.client.ContainerClone(context.Background(), cmd.Arg(0), options)
+	if err != nil {
+		return err
+	}
+	for _, warning := range response.Warnings {
+		fmt.Fprintf(cli.err, "WARNING: %s\n", warning)
+	}
+	fmt.Fprintf(cli.out, "%s\n", response.ID)
+	return nil
+}
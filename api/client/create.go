@@ -25,14 +25,7 @@ func (cli *DockerCli) pullImage(image string, out io.Writer) error {
 		return err
 	}
 
-	// Resolve the Repository name from fqn to RepositoryInfo
-	repoInfo, err := registry.ParseRepositoryInfo(ref)
-	if err != nil {
-		return err
-	}
-
-	authConfig := cli.resolveAuthConfig(repoInfo.Index)
-	encodedAuth, err := encodeAuthToBase64(authConfig)
+	encodedAuth, err := cli.encodedRegistryAuth(ref)
 	if err != nil {
 		return err
 	}
@@ -69,7 +62,36 @@ func newCIDFile(path string) (*cidFile, error) {
 	return &cidFile{path: path, file: f}, nil
 }
 
-func (cli *DockerCli) createContainer(config *container.Config, hostConfig *container.HostConfig, networkingConfig *networktypes.NetworkingConfig, cidfile, name string) (*types.ContainerCreateResponse, error) {
+// encodedRegistryAuth returns the credentials for the registry of ref,
+// encoded for the X-Registry-Auth header.
+func (cli *DockerCli) encodedRegistryAuth(ref reference.Named) (string, error) {
+	// Resolve the Repository name from fqn to RepositoryInfo
+	repoInfo, err := registry.ParseRepositoryInfo(ref)
+	if err != nil {
+		return "", err
+	}
+
+	authConfig := cli.resolveAuthConfig(repoInfo.Index)
+	return encodeAuthToBase64(authConfig)
+}
+
+// validatePullPolicy checks the value of the --pull flag of run and create.
+func validatePullPolicy(pull string) error {
+	switch pull {
+	case types.PullAlways, types.PullMissing, types.PullNever:
+		return nil
+	}
+	return fmt.Errorf("invalid --pull value %q, must be %s, %s or %s", pull, types.PullAlways, types.PullMissing, types.PullNever)
+}
+
+// createContainer creates a container, pulling its image according to the
+// pull policy. The daemon evaluates the always and never policies, while the
+// image is pulled here with progress for the missing policy.
+func (cli *DockerCli) createContainer(config *container.Config, hostConfig *container.HostConfig, networkingConfig *networktypes.NetworkingConfig, cidfile, name, pull string) (*types.ContainerCreateResponse, error) {
+	if err := validatePullPolicy(pull); err != nil {
+		return nil, err
+	}
+
 	var containerIDFile *cidFile
 	if cidfile != "" {
 		var err error
@@ -97,12 +119,22 @@ func (cli *DockerCli) createContainer(config *container.Config, hostConfig *cont
 		}
 	}
 
+	options := types.ContainerCreateOptions{Name: name}
+	if pull != types.PullMissing {
+		options.Pull = pull
+		if ref != nil {
+			if options.RegistryAuth, err = cli.encodedRegistryAuth(ref); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	//create the container
-	response, err := cli.client.ContainerCreate(context.Background(), config, hostConfig, networkingConfig, name)
+	response, err := cli.client.ContainerCreate(context.Background(), config, hostConfig, networkingConfig, options)
 
 	//if image not found try to pull it
 	if err != nil {
-		if client.IsErrImageNotFound(err) && ref != nil {
+		if client.IsErrImageNotFound(err) && ref != nil && pull == types.PullMissing {
 			fmt.Fprintf(cli.err, "Unable to find image '%s' locally\n", ref.String())
 
 			// we don't want to write to stdout anything apart from container.ID
@@ -116,13 +148,18 @@ func (cli *DockerCli) createContainer(config *container.Config, hostConfig *cont
 			}
 			// Retry
 			var retryErr error
-			response, retryErr = cli.client.ContainerCreate(context.Background(), config, hostConfig, networkingConfig, name)
+			response, retryErr = cli.client.ContainerCreate(context.Background(), config, hostConfig, networkingConfig, options)
 			if retryErr != nil {
 				return nil, retryErr
 			}
 		} else {
 			return nil, err
 		}
+	} else if ref, ok := ref.(reference.NamedTagged); ok && trustedRef != nil && pull == types.PullAlways {
+		// the daemon pulled the trusted digest, tag it as it is above
+		if err := cli.tagTrusted(trustedRef, ref); err != nil {
+			return nil, err
+		}
 	}
 
 	for _, warning := range response.Warnings {
@@ -136,6 +173,20 @@ func (cli *DockerCli) createContainer(config *container.Config, hostConfig *cont
 	return &response, nil
 }
 
+// validateContainer asks the daemon to validate the configuration of a
+// container without creating it, and prints the warnings.
+func (cli *DockerCli) validateContainer(config *container.Config, hostConfig *container.HostConfig, networkingConfig *networktypes.NetworkingConfig, name string) error {
+	options := types.ContainerCreateOptions{Name: name, DryRun: true}
+	response, err := cli.client.ContainerCreate(context.Background(), config, hostConfig, networkingConfig, options)
+	if err != nil {
+		return err
+	}
+	for _, warning := range response.Warnings {
+		fmt.Fprintf(cli.err, "WARNING: %s\n", warning)
+	}
+	return nil
+}
+
 // CmdCreate creates a new container from a given image.
 //
 // Usage: docker create [OPTIONS] IMAGE [COMMAND] [ARG...]
@@ -145,7 +196,9 @@ func (cli *DockerCli) CmdCreate(args ...string) error {
 
 	// These are flags not stored in Config/HostConfig
 	var (
-		flName = cmd.String([]string{"-name"}, "", "Assign a name to the container")
+		flName   = cmd.String([]string{"-name"}, "", "Assign a name to the container")
+		flPull   = cmd.String([]string{"-pull"}, types.PullMissing, "Pull image before creating (always, missing, never)")
+		flDryRun = cmd.Bool([]string{"-dry-run"}, false, "Validate the configuration without creating the container")
 	)
 
 	config, hostConfig, networkingConfig, cmd, err := runconfigopts.Parse(cmd, args)
@@ -158,7 +211,10 @@ func (cli *DockerCli) CmdCreate(args ...string) error {
 		cmd.Usage()
 		return nil
 	}
-	response, err := cli.createContainer(config, hostConfig, networkingConfig, hostConfig.ContainerIDFile, *flName)
+	if *flDryRun {
+		return cli.validateContainer(config, hostConfig, networkingConfig, *flName)
+	}
+	response, err := cli.createContainer(config, hostConfig, networkingConfig, hostConfig.ContainerIDFile, *flName, *flPull)
 	if err != nil {
 		return err
 	}
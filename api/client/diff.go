@@ -8,17 +8,19 @@ import (
 	Cli "github.com/docker/docker/cli"
 	"github.com/docker/docker/pkg/archive"
 	flag "github.com/docker/docker/pkg/mflag"
+	"github.com/docker/go-units"
 )
 
 // CmdDiff shows changes on a container's filesystem.
 //
 // Each changed file is printed on a separate line, prefixed with a single
 // character that indicates the status of the file: C (modified), A (added),
-// or D (deleted).
+// D (deleted) or R (renamed).
 //
-// Usage: docker diff CONTAINER
+// Usage: docker diff [OPTIONS] CONTAINER
 func (cli *DockerCli) CmdDiff(args ...string) error {
 	cmd := Cli.Subcmd("diff", []string{"CONTAINER"}, Cli.DockerCommands["diff"].Description, true)
+	verbose := cmd.Bool([]string{"v", "-verbose"}, false, "Show the size and mode of changed files")
 	cmd.Require(flag.Exact, 1)
 
 	cmd.ParseFlags(args, true)
@@ -41,8 +43,25 @@ func (cli *DockerCli) CmdDiff(args ...string) error {
 			kind = "A"
 		case archive.ChangeDelete:
 			kind = "D"
+		case archive.ChangeRename:
+			kind = "R"
 		}
-		fmt.Fprintf(cli.out, "%s %s\n", kind, change.Path)
+		path := change.Path
+		if change.Kind == archive.ChangeRename {
+			path = change.OldPath + " -> " + change.Path
+		}
+		if !*verbose || change.Kind == archive.ChangeDelete {
+			fmt.Fprintf(cli.out, "%s %s\n", kind, path)
+			continue
+		}
+		details := change.Mode.String()
+		if change.OldMode != 0 {
+			details = change.OldMode.String() + " -> " + details
+		}
+		if !change.Mode.IsDir() {
+			details = units.HumanSize(float64(change.Size)) + ", " + details
+		}
+		fmt.Fprintf(cli.out, "%s %s (%s)\n", kind, path, details)
 	}
 
 	return nil
@@ -7,6 +7,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"text/template"
 	"time"
 
 	"golang.org/x/net/context"
@@ -16,6 +17,7 @@ import (
 	"github.com/docker/docker/opts"
 	"github.com/docker/docker/pkg/jsonlog"
 	flag "github.com/docker/docker/pkg/mflag"
+	"github.com/docker/docker/utils/templates"
 	"github.com/docker/engine-api/types"
 	eventtypes "github.com/docker/engine-api/types/events"
 	"github.com/docker/engine-api/types/filters"
@@ -30,10 +32,20 @@ func (cli *DockerCli) CmdEvents(args ...string) error {
 	until := cmd.String([]string{"-until"}, "", "Stream events until this timestamp")
 	flFilter := opts.NewListOpts(nil)
 	cmd.Var(&flFilter, []string{"f", "-filter"}, "Filter output based on conditions provided")
+	tmplStr := cmd.String([]string{"-format"}, "", "Format the output using the given go template")
 	cmd.Require(flag.Exact, 0)
 
 	cmd.ParseFlags(args, true)
 
+	var tmpl *template.Template
+	if *tmplStr != "" {
+		var err error
+		if tmpl, err = templates.Parse(*tmplStr); err != nil {
+			return Cli.StatusError{StatusCode: 64,
+				Status: "Template parsing error: " + err.Error()}
+		}
+	}
+
 	eventFilterArgs := filters.NewArgs()
 
 	// Consolidate all filter flags, and sanity check them early.
@@ -58,17 +70,21 @@ func (cli *DockerCli) CmdEvents(args ...string) error {
 	}
 	defer responseBody.Close()
 
-	return streamEvents(responseBody, cli.out)
+	return streamEvents(responseBody, cli.out, tmpl)
 }
 
-// streamEvents decodes prints the incoming events in the provided output.
-func streamEvents(input io.Reader, output io.Writer) error {
+// streamEvents decodes prints the incoming events in the provided output,
+// formatted with tmpl if it is not nil.
+func streamEvents(input io.Reader, output io.Writer, tmpl *template.Template) error {
 	return decodeEvents(input, func(event eventtypes.Message, err error) error {
 		if err != nil {
 			return err
 		}
-		printOutput(event, output)
-		return nil
+		if tmpl == nil {
+			printOutput(event, output)
+			return nil
+		}
+		return formatEvent(event, output, tmpl)
 	})
 }
 
@@ -118,6 +134,15 @@ func printOutput(event eventtypes.Message, output io.Writer) {
 	fmt.Fprint(output, "\n")
 }
 
+// formatEvent prints an event formatted with tmpl, followed by a newline.
+func formatEvent(event eventtypes.Message, output io.Writer, tmpl *template.Template) error {
+	if err := tmpl.Execute(output, event); err != nil {
+		return err
+	}
+	fmt.Fprint(output, "\n")
+	return nil
+}
+
 type eventHandler struct {
 	handlers map[string]func(eventtypes.Message)
 	mu       sync.Mutex
@@ -0,0 +1,36 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/docker/docker/utils/templates"
+	eventtypes "github.com/docker/engine-api/types/events"
+)
+
+func TestStreamEventsWithFormat(t *testing.T) {
+	var input bytes.Buffer
+	enc := json.NewEncoder(&input)
+	for _, e := range []eventtypes.Message{
+		{Type: "container", Action: "die", Actor: eventtypes.Actor{ID: "a", Attributes: map[string]string{"exitCode": "1"}}},
+		{Type: "container", Action: "start", Actor: eventtypes.Actor{ID: "b"}},
+	} {
+		if err := enc.Encode(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tmpl, err := templates.Parse(`{{.Action}} {{.Actor.ID}} {{index .Actor.Attributes "exitCode"}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var output bytes.Buffer
+	if err := streamEvents(&input, &output, tmpl); err != nil {
+		t.Fatal(err)
+	}
+	expected := "die a 1\nstart b \n"
+	if output.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, output.String())
+	}
+}
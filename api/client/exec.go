@@ -3,6 +3,7 @@ package client
 import (
 	"fmt"
 	"io"
+	"strconv"
 
 	"golang.org/x/net/context"
 
@@ -11,6 +12,7 @@ import (
 	flag "github.com/docker/docker/pkg/mflag"
 	"github.com/docker/docker/pkg/promise"
 	"github.com/docker/engine-api/types"
+	"github.com/docker/go-units"
 )
 
 // CmdExec runs a command in a running container.
@@ -128,6 +130,8 @@ func ParseExec(cmd *flag.FlagSet, args []string) (*types.ExecConfig, error) {
 		flDetach     = cmd.Bool([]string{"d", "-detach"}, false, "Detached mode: run command in the background")
 		flUser       = cmd.String([]string{"u", "-user"}, "", "Username or UID (format: <name|uid>[:<group|gid>])")
 		flPrivileged = cmd.Bool([]string{"-privileged"}, false, "Give extended privileges to the command")
+		flCPUs       = cmd.String([]string{"-cpus"}, "", "Number of CPUs the command can use")
+		flMemory     = cmd.String([]string{"m", "-memory"}, "", "Memory limit of the command")
 		execCmd      []string
 	)
 	cmd.Require(flag.Min, 2)
@@ -145,6 +149,21 @@ func ParseExec(cmd *flag.FlagSet, args []string) (*types.ExecConfig, error) {
 		Detach:     *flDetach,
 	}
 
+	if *flCPUs != "" {
+		cpus, err := strconv.ParseFloat(*flCPUs, 64)
+		if err != nil || cpus <= 0 {
+			return nil, fmt.Errorf("invalid number of CPUs: %s", *flCPUs)
+		}
+		execConfig.NanoCPUs = int64(cpus * 1e9)
+	}
+	if *flMemory != "" {
+		memory, err := units.RAMInBytes(*flMemory)
+		if err != nil {
+			return nil, err
+		}
+		execConfig.Memory = memory
+	}
+
 	// If -d is not set, attach to everything by default
 	if !*flDetach {
 		execConfig.AttachStdout = true
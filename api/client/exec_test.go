@@ -15,9 +15,11 @@ type arguments struct {
 
 func TestParseExec(t *testing.T) {
 	invalids := map[*arguments]error{
-		&arguments{[]string{"-unknown"}}: fmt.Errorf("flag provided but not defined: -unknown"),
-		&arguments{[]string{"-u"}}:       fmt.Errorf("flag needs an argument: -u"),
-		&arguments{[]string{"--user"}}:   fmt.Errorf("flag needs an argument: --user"),
+		&arguments{[]string{"-unknown"}}:                              fmt.Errorf("flag provided but not defined: -unknown"),
+		&arguments{[]string{"-u"}}:                                    fmt.Errorf("flag needs an argument: -u"),
+		&arguments{[]string{"--user"}}:                                fmt.Errorf("flag needs an argument: --user"),
+		&arguments{[]string{"--cpus", "-1", "container", "command"}}:  fmt.Errorf("invalid number of CPUs: -1"),
+		&arguments{[]string{"--cpus", "one", "container", "command"}}: fmt.Errorf("invalid number of CPUs: one"),
 	}
 	valids := map[*arguments]*types.ExecConfig{
 		&arguments{
@@ -44,6 +46,15 @@ func TestParseExec(t *testing.T) {
 			Tty:          true,
 			Cmd:          []string{"command"},
 		},
+		&arguments{
+			[]string{"--cpus", "0.5", "-m", "64m", "container", "command"},
+		}: {
+			AttachStdout: true,
+			AttachStderr: true,
+			Cmd:          []string{"command"},
+			NanoCPUs:     500000000,
+			Memory:       64 * 1024 * 1024,
+		},
 		&arguments{
 			[]string{"-d", "container", "command"},
 		}: {
@@ -110,6 +121,9 @@ func compareExecConfig(config1 *types.ExecConfig, config2 *types.ExecConfig) boo
 	if config1.User != config2.User {
 		return false
 	}
+	if config1.NanoCPUs != config2.NanoCPUs || config1.Memory != config2.Memory {
+		return false
+	}
 	if len(config1.Cmd) != len(config2.Cmd) {
 		return false
 	}
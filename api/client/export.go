@@ -18,6 +18,7 @@ import (
 func (cli *DockerCli) CmdExport(args ...string) error {
 	cmd := Cli.Subcmd("export", []string{"CONTAINER"}, Cli.DockerCommands["export"].Description, true)
 	outfile := cmd.String([]string{"o", "-output"}, "", "Write to a file, instead of STDOUT")
+	snapshot := cmd.Bool([]string{"-snapshot"}, false, "Export a snapshot of the container taken without pausing it")
 	cmd.Require(flag.Exact, 1)
 
 	cmd.ParseFlags(args, true)
@@ -26,7 +27,15 @@ func (cli *DockerCli) CmdExport(args ...string) error {
 		return errors.New("Cowardly refusing to save to a terminal. Use the -o flag or redirect.")
 	}
 
-	responseBody, err := cli.client.ContainerExport(context.Background(), cmd.Arg(0))
+	var (
+		responseBody io.ReadCloser
+		err          error
+	)
+	if *snapshot {
+		responseBody, err = cli.client.ContainerExportSnapshot(context.Background(), cmd.Arg(0))
+	} else {
+		responseBody, err = cli.client.ContainerExport(context.Background(), cmd.Arg(0))
+	}
 	if err != nil {
 		return err
 	}
@@ -32,6 +32,9 @@ const (
 	tagHeader          = "TAG"
 	digestHeader       = "DIGEST"
 	mountsHeader       = "MOUNTS"
+	sharedSizeHeader   = "SHARED SIZE"
+	uniqueSizeHeader   = "UNIQUE SIZE"
+	containersHeader   = "CONTAINERS"
 )
 
 type containerContext struct {
@@ -210,6 +213,21 @@ func (c *imageContext) Size() string {
 	return units.HumanSize(float64(c.i.Size))
 }
 
+func (c *imageContext) SharedSize() string {
+	c.addHeader(sharedSizeHeader)
+	return units.HumanSize(float64(c.i.SharedSize))
+}
+
+func (c *imageContext) UniqueSize() string {
+	c.addHeader(uniqueSizeHeader)
+	return units.HumanSize(float64(c.i.UniqueSize))
+}
+
+func (c *imageContext) Containers() string {
+	c.addHeader(containersHeader)
+	return strconv.Itoa(c.i.Containers)
+}
+
 type subContext interface {
 	fullHeader() string
 	addHeader(header string)
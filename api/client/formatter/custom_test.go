@@ -131,6 +131,18 @@ func TestImagesContext(t *testing.T) {
 			i:     types.Image{Size: 10},
 			trunc: true,
 		}, "10 B", sizeHeader, ctx.Size},
+		{imageContext{
+			i:     types.Image{Size: 30, SharedSize: 20, UniqueSize: 10},
+			trunc: true,
+		}, "20 B", sharedSizeHeader, ctx.SharedSize},
+		{imageContext{
+			i:     types.Image{Size: 30, SharedSize: 20, UniqueSize: 10},
+			trunc: true,
+		}, "10 B", uniqueSizeHeader, ctx.UniqueSize},
+		{imageContext{
+			i:     types.Image{Containers: 2},
+			trunc: true,
+		}, "2", containersHeader, ctx.Containers},
 		{imageContext{
 			i:     types.Image{Created: unix},
 			trunc: true,
@@ -25,6 +25,7 @@ func (cli *DockerCli) CmdImport(args ...string) error {
 	flChanges := opts.NewListOpts(nil)
 	cmd.Var(&flChanges, []string{"c", "-change"}, "Apply Dockerfile instruction to the created image")
 	message := cmd.String([]string{"m", "-message"}, "", "Set commit message for imported image")
+	platform := cmd.String([]string{"-platform"}, "", "Set the platform (os[/arch]) of the imported image")
 	cmd.Require(flag.Min, 1)
 
 	cmd.ParseFlags(args, true)
@@ -62,9 +63,10 @@ func (cli *DockerCli) CmdImport(args ...string) error {
 	}
 
 	options := types.ImageImportOptions{
-		Message: *message,
-		Tag:     tag,
-		Changes: changes,
+		Message:  *message,
+		Tag:      tag,
+		Changes:  changes,
+		Platform: *platform,
 	}
 
 	responseBody, err := cli.client.ImageImport(context.Background(), source, ref, options)
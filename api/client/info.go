@@ -2,6 +2,7 @@ package client
 
 import (
 	"fmt"
+	"io"
 	"strings"
 
 	"golang.org/x/net/context"
@@ -10,18 +11,30 @@ import (
 	"github.com/docker/docker/pkg/ioutils"
 	flag "github.com/docker/docker/pkg/mflag"
 	"github.com/docker/docker/utils"
+	"github.com/docker/engine-api/types"
 	"github.com/docker/go-units"
 )
 
 // CmdInfo displays system-wide information.
 //
-// Usage: docker info
+// Usage: docker info [OPTIONS]
 func (cli *DockerCli) CmdInfo(args ...string) error {
 	cmd := Cli.Subcmd("info", nil, Cli.DockerCommands["info"].Description, true)
+	bundle := cmd.String([]string{"-bundle"}, "", "Write a debug bundle of the daemon to a tar file")
 	cmd.Require(flag.Exact, 0)
 
 	cmd.ParseFlags(args, true)
 
+	if *bundle != "" {
+		responseBody, err := cli.client.InfoBundle(context.Background())
+		if err != nil {
+			return err
+		}
+		defer responseBody.Close()
+
+		return copyToFile(*bundle, responseBody)
+	}
+
 	info, err := cli.client.Info(context.Background())
 	if err != nil {
 		return err
@@ -53,6 +66,7 @@ func (cli *DockerCli) CmdInfo(args ...string) error {
 	ioutils.FprintfIfNotEmpty(cli.out, "Execution Driver: %s\n", info.ExecutionDriver)
 	ioutils.FprintfIfNotEmpty(cli.out, "Logging Driver: %s\n", info.LoggingDriver)
 	ioutils.FprintfIfNotEmpty(cli.out, "Cgroup Driver: %s\n", info.CgroupDriver)
+	ioutils.FprintfIfNotEmpty(cli.out, "Cgroup Version: %s\n", info.CgroupVersion)
 
 	fmt.Fprintf(cli.out, "Plugins: \n")
 	fmt.Fprintf(cli.out, " Volume:")
@@ -74,6 +88,8 @@ func (cli *DockerCli) CmdInfo(args ...string) error {
 	ioutils.FprintfIfNotEmpty(cli.out, "Architecture: %s\n", info.Architecture)
 	fmt.Fprintf(cli.out, "CPUs: %d\n", info.NCPU)
 	fmt.Fprintf(cli.out, "Total Memory: %s\n", units.BytesSize(float64(info.MemTotal)))
+	fmt.Fprintf(cli.out, "Reserved CPUs: %.2f\n", info.ReservedCPUs)
+	fmt.Fprintf(cli.out, "Reserved Memory: %s\n", units.BytesSize(float64(info.ReservedMemory)))
 	ioutils.FprintfIfNotEmpty(cli.out, "Name: %s\n", info.Name)
 	ioutils.FprintfIfNotEmpty(cli.out, "ID: %s\n", info.ID)
 	fmt.Fprintf(cli.out, "Docker Root Dir: %s\n", info.DockerRootDir)
@@ -87,6 +103,9 @@ func (cli *DockerCli) CmdInfo(args ...string) error {
 		fmt.Fprintf(cli.out, " EventsListeners: %d\n", info.NEventsListener)
 	}
 
+	printTransferThrottle(cli.out, "Download", info.DownloadThrottle)
+	printTransferThrottle(cli.out, "Upload", info.UploadThrottle)
+
 	ioutils.FprintfIfNotEmpty(cli.out, "Http Proxy: %s\n", info.HTTPProxy)
 	ioutils.FprintfIfNotEmpty(cli.out, "Https Proxy: %s\n", info.HTTPSProxy)
 	ioutils.FprintfIfNotEmpty(cli.out, "No Proxy: %s\n", info.NoProxy)
@@ -167,3 +186,21 @@ func (cli *DockerCli) CmdInfo(args ...string) error {
 	}
 	return nil
 }
+
+// printTransferThrottle prints the bandwidth limits of the pulls or pushes,
+// if they are limited.
+func printTransferThrottle(out io.Writer, direction string, throttle types.TransferThrottle) {
+	if throttle.Rate == 0 && throttle.PerLayerRate == 0 {
+		return
+	}
+	rate := func(r int64) string {
+		if r == 0 {
+			return "unlimited"
+		}
+		return units.HumanSize(float64(r)) + "/s"
+	}
+	fmt.Fprintf(out, "%s Throttle:\n", direction)
+	fmt.Fprintf(out, " Rate: %s\n", rate(throttle.Rate))
+	fmt.Fprintf(out, " Per Layer Rate: %s\n", rate(throttle.PerLayerRate))
+	fmt.Fprintf(out, " Active Transfers: %d\n", throttle.ActiveTransfers)
+}
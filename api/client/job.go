@@ -0,0 +1,177 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/net/context"
+
+	Cli "github.com/docker/docker/cli"
+	flag "github.com/docker/docker/pkg/mflag"
+	"github.com/docker/docker/pkg/stringid"
+	runconfigopts "github.com/docker/docker/runconfig/opts"
+	"github.com/docker/engine-api/types"
+	"github.com/docker/go-units"
+)
+
+// CmdJob is the parent subcommand for all job commands
+//
+// Usage: docker job <COMMAND> <OPTS>
+func (cli *DockerCli) CmdJob(args ...string) error {
+	description := Cli.DockerCommands["job"].Description + "\n\nCommands:\n"
+	commands := [][]string{
+		{"create", "Create a job running a container on a schedule"},
+		{"inspect", "Return low-level information on a job"},
+		{"ls", "List jobs"},
+		{"rm", "Remove a job"},
+	}
+
+	for _, cmd := range commands {
+		description += fmt.Sprintf("  %-25.25s%s\n", cmd[0], cmd[1])
+	}
+
+	description += "\nRun 'docker job COMMAND --help' for more information on a command"
+	cmd := Cli.Subcmd("job", []string{"[COMMAND]"}, description, false)
+
+	cmd.Require(flag.Exact, 0)
+	err := cmd.ParseFlags(args, true)
+	cmd.Usage()
+	return err
+}
+
+// CmdJobCreate creates a job running a new container on a schedule. The
+// container is configured with the options of docker create.
+//
+// Usage: docker job create [OPTIONS] --schedule SCHEDULE IMAGE [COMMAND] [ARG...]
+func (cli *DockerCli) CmdJobCreate(args ...string) error {
+	cmd := Cli.Subcmd("job create", []string{"--schedule SCHEDULE IMAGE [COMMAND] [ARG...]"}, "Create a job running a container on a schedule", true)
+	flName := cmd.String([]string{"-name"}, "", "Assign a name to the job")
+	flSchedule := cmd.String([]string{"-schedule"}, "", "Schedule in the cron format (e.g. '0 3 * * *')")
+	flOverlap := cmd.String([]string{"-overlap"}, types.JobOverlapSkip, "Policy for overlapping runs (skip, allow, replace)")
+	flHistory := cmd.Int([]string{"-history"}, 10, "Number of finished runs to keep")
+
+	config, hostConfig, networkingConfig, cmd, err := runconfigopts.Parse(cmd, args)
+	if err != nil {
+		cmd.ReportError(err.Error(), true)
+		os.Exit(1)
+	}
+	if config.Image == "" || *flSchedule == "" {
+		cmd.Usage()
+		return nil
+	}
+
+	job, err := cli.client.JobCreate(context.Background(), types.JobCreateRequest{
+		Name: *flName,
+		JobSpec: types.JobSpec{
+			Schedule:         *flSchedule,
+			OverlapPolicy:    *flOverlap,
+			HistoryLimit:     *flHistory,
+			Config:           config,
+			HostConfig:       hostConfig,
+			NetworkingConfig: networkingConfig,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cli.out, "%s\n", job.Name)
+	return nil
+}
+
+// CmdJobLs outputs a list of the jobs.
+//
+// Usage: docker job ls [OPTIONS]
+func (cli *DockerCli) CmdJobLs(args ...string) error {
+	cmd := Cli.Subcmd("job ls", nil, "List jobs", true)
+	quiet := cmd.Bool([]string{"q", "-quiet"}, false, "Only display job names")
+
+	cmd.Require(flag.Exact, 0)
+	cmd.ParseFlags(args, true)
+
+	jobs, err := cli.client.JobList(context.Background())
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(cli.out, 20, 1, 3, ' ', 0)
+	if !*quiet {
+		fmt.Fprintln(w, "JOB ID\tNAME\tSCHEDULE\tNEXT RUN\tLAST RUN")
+	}
+	now := time.Now().UTC()
+	for _, job := range jobs {
+		if *quiet {
+			fmt.Fprintln(w, job.Name)
+			continue
+		}
+		next := "Never"
+		if !job.NextRun.IsZero() {
+			next = "in " + units.HumanDuration(job.NextRun.Sub(now))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", stringid.TruncateID(job.ID), job.Name, job.Spec.Schedule, next, lastJobRun(job, now))
+	}
+	w.Flush()
+	return nil
+}
+
+// lastJobRun describes the status of the last run of job.
+func lastJobRun(job types.Job, now time.Time) string {
+	if len(job.Runs) == 0 {
+		return ""
+	}
+	run := job.Runs[len(job.Runs)-1]
+	switch {
+	case run.Running:
+		return fmt.Sprintf("Running for %s", units.HumanDuration(now.Sub(run.Started)))
+	case run.Error != "":
+		return fmt.Sprintf("Failed %s ago", units.HumanDuration(now.Sub(run.Finished)))
+	default:
+		return fmt.Sprintf("Exited (%d) %s ago", run.ExitCode, units.HumanDuration(now.Sub(run.Finished)))
+	}
+}
+
+// CmdJobInspect displays low-level information on one or more jobs.
+//
+// Usage: docker job inspect [OPTIONS] JOB [JOB...]
+func (cli *DockerCli) CmdJobInspect(args ...string) error {
+	cmd := Cli.Subcmd("job inspect", []string{"JOB [JOB...]"}, "Return low-level information on a job", true)
+	tmplStr := cmd.String([]string{"f", "-format"}, "", "Format the output using the given go template")
+
+	cmd.Require(flag.Min, 1)
+	cmd.ParseFlags(args, true)
+
+	inspectSearcher := func(name string) (interface{}, []byte, error) {
+		i, err := cli.client.JobInspect(context.Background(), name)
+		return i, nil, err
+	}
+
+	return cli.inspectElements(*tmplStr, cmd.Args(), inspectSearcher)
+}
+
+// CmdJobRm removes one or more jobs, and the containers of their finished
+// runs.
+//
+// Usage: docker job rm JOB [JOB...]
+func (cli *DockerCli) CmdJobRm(args ...string) error {
+	cmd := Cli.Subcmd("job rm", []string{"JOB [JOB...]"}, "Remove a job", true)
+	cmd.Require(flag.Min, 1)
+	cmd.ParseFlags(args, true)
+
+	var status = 0
+
+	for _, name := range cmd.Args() {
+		if err := cli.client.JobRemove(context.Background(), name); err != nil {
+			fmt.Fprintf(cli.err, "%s\n", err)
+			status = 1
+			continue
+		}
+		fmt.Fprintf(cli.out, "%s\n", name)
+	}
+
+	if status != 0 {
+		return Cli.StatusError{StatusCode: status}
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"golang.org/x/net/context"
 
 	Cli "github.com/docker/docker/cli"
+	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/jsonmessage"
 	flag "github.com/docker/docker/pkg/mflag"
 )
@@ -14,23 +15,37 @@ import (
 // CmdLoad loads an image from a tar archive.
 //
 // The tar archive is read from STDIN by default, or from a tar archive file.
+// A directory, such as an OCI image layout, is archived and sent as is.
 //
 // Usage: docker load [OPTIONS]
 func (cli *DockerCli) CmdLoad(args ...string) error {
 	cmd := Cli.Subcmd("load", nil, Cli.DockerCommands["load"].Description, true)
-	infile := cmd.String([]string{"i", "-input"}, "", "Read from a tar archive file, instead of STDIN")
+	infile := cmd.String([]string{"i", "-input"}, "", "Read from a tar archive file or a directory, instead of STDIN")
 	quiet := cmd.Bool([]string{"q", "-quiet"}, false, "Suppress the load output")
 	cmd.Require(flag.Exact, 0)
 	cmd.ParseFlags(args, true)
 
 	var input io.Reader = cli.in
 	if *infile != "" {
-		file, err := os.Open(*infile)
+		fi, err := os.Stat(*infile)
 		if err != nil {
 			return err
 		}
-		defer file.Close()
-		input = file
+		if fi.IsDir() {
+			dir, err := archive.Tar(*infile, archive.Uncompressed)
+			if err != nil {
+				return err
+			}
+			defer dir.Close()
+			input = dir
+		} else {
+			file, err := os.Open(*infile)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			input = file
+		}
 	}
 	if !cli.isTerminalOut {
 		*quiet = true
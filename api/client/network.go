@@ -45,6 +45,7 @@ func (cli *DockerCli) CmdNetworkCreate(args ...string) error {
 	flIpamAux := opts.NewMapOpts(nil, nil)
 	flIpamOpt := opts.NewMapOpts(nil, nil)
 	flLabels := opts.NewListOpts(nil)
+	flIsolationAllow := opts.NewListOpts(runconfigopts.ValidateIsolationRule)
 
 	cmd.Var(&flIpamSubnet, []string{"-subnet"}, "subnet in CIDR format that represents a network segment")
 	cmd.Var(&flIpamIPRange, []string{"-ip-range"}, "allocate container ip from a sub-range")
@@ -53,9 +54,11 @@ func (cli *DockerCli) CmdNetworkCreate(args ...string) error {
 	cmd.Var(flOpts, []string{"o", "-opt"}, "set driver specific options")
 	cmd.Var(flIpamOpt, []string{"-ipam-opt"}, "set IPAM driver specific options")
 	cmd.Var(&flLabels, []string{"-label"}, "set metadata on a network")
+	cmd.Var(&flIsolationAllow, []string{"-isolation-allow"}, "allow traffic between containers of an isolated network")
 
 	flInternal := cmd.Bool([]string{"-internal"}, false, "restricts external access to the network")
 	flIPv6 := cmd.Bool([]string{"-ipv6"}, false, "enable IPv6 networking")
+	flIsolated := cmd.Bool([]string{"-isolated"}, false, "deny traffic between containers of the network by default")
 
 	cmd.Require(flag.Exact, 1)
 	err := cmd.ParseFlags(args, true)
@@ -82,6 +85,8 @@ func (cli *DockerCli) CmdNetworkCreate(args ...string) error {
 		Options:        flOpts.GetAll(),
 		CheckDuplicate: true,
 		Internal:       *flInternal,
+		Isolated:       *flIsolated,
+		IsolationAllow: flIsolationAllow.GetAll(),
 		EnableIPv6:     *flIPv6,
 		Labels:         runconfigopts.ConvertKVStringsToMap(flLabels.GetAll()),
 	}
@@ -118,6 +123,47 @@ func (cli *DockerCli) CmdNetworkRm(args ...string) error {
 	return nil
 }
 
+// CmdNetworkPrune removes all networks not used by any container
+//
+// Usage: docker network prune [OPTIONS]
+func (cli *DockerCli) CmdNetworkPrune(args ...string) error {
+	cmd := Cli.Subcmd("network prune", nil, "Remove all unused networks", false)
+	force := cmd.Bool([]string{"f", "-force"}, false, "Do not prompt for confirmation")
+	dryRun := cmd.Bool([]string{"-dry-run"}, false, "Only list the networks that would be removed")
+	flFilter := opts.NewListOpts(nil)
+	cmd.Var(&flFilter, []string{"-filter"}, "Provide filter values (i.e. 'driver=bridge')")
+
+	cmd.Require(flag.Exact, 0)
+	if err := cmd.ParseFlags(args, true); err != nil {
+		return err
+	}
+
+	options := types.NetworksPruneOptions{
+		Filters: filters.NewArgs(),
+		DryRun:  *dryRun,
+	}
+	for _, f := range flFilter.GetAll() {
+		var err error
+		options.Filters, err = filters.ParseFlag(f, options.Filters)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !*force && !*dryRun && !cli.confirm("WARNING! This will remove all networks not used by at least one container.") {
+		return nil
+	}
+
+	report, err := cli.client.NetworksPrune(context.Background(), options)
+	if err != nil {
+		return err
+	}
+	for _, name := range report.NetworksDeleted {
+		fmt.Fprintf(cli.out, "%s\n", name)
+	}
+	return nil
+}
+
 // CmdNetworkConnect connects a container to a network
 //
 // Usage: docker network connect [OPTIONS] <NETWORK> <CONTAINER>
@@ -233,6 +279,7 @@ func (r byNetworkName) Less(i, j int) bool { return r[i].Name < r[j].Name }
 func (cli *DockerCli) CmdNetworkInspect(args ...string) error {
 	cmd := Cli.Subcmd("network inspect", []string{"NETWORK [NETWORK...]"}, "Displays detailed information on one or more networks", false)
 	tmplStr := cmd.String([]string{"f", "-format"}, "", "Format the output using the given go template")
+	diagnose := cmd.String([]string{"-diagnose"}, "", "Run the connectivity checks of the endpoint of a container")
 	cmd.Require(flag.Min, 1)
 
 	if err := cmd.ParseFlags(args, true); err != nil {
@@ -240,6 +287,10 @@ func (cli *DockerCli) CmdNetworkInspect(args ...string) error {
 	}
 
 	inspectSearcher := func(name string) (interface{}, []byte, error) {
+		if *diagnose != "" {
+			report, err := cli.client.NetworkDiagnose(context.Background(), name, *diagnose)
+			return report, nil, err
+		}
 		i, err := cli.client.NetworkInspect(context.Background(), name)
 		return i, nil, err
 	}
@@ -377,6 +428,7 @@ func networkUsage() string {
 		{"disconnect", "Disconnect container from a network"},
 		{"inspect", "Display detailed network information"},
 		{"ls", "List all networks"},
+		{"prune", "Remove all unused networks"},
 		{"rm", "Remove a network"},
 	}
 
@@ -3,21 +3,27 @@ package client
 import (
 	"errors"
 	"io"
+	"os"
 
 	"golang.org/x/net/context"
 
 	Cli "github.com/docker/docker/cli"
+	"github.com/docker/docker/pkg/archive"
 	flag "github.com/docker/docker/pkg/mflag"
+	"github.com/docker/engine-api/types"
 )
 
 // CmdSave saves one or more images to a tar archive.
 //
 // The tar archive is written to STDOUT by default, or written to a file.
+// When the output is an existing directory, the archive is extracted into
+// it, which with --format oci produces an OCI image layout directory.
 //
 // Usage: docker save [OPTIONS] IMAGE [IMAGE...]
 func (cli *DockerCli) CmdSave(args ...string) error {
 	cmd := Cli.Subcmd("save", []string{"IMAGE [IMAGE...]"}, Cli.DockerCommands["save"].Description+" (streamed to STDOUT by default)", true)
-	outfile := cmd.String([]string{"o", "-output"}, "", "Write to a file, instead of STDOUT")
+	outfile := cmd.String([]string{"o", "-output"}, "", "Write to a file or directory, instead of STDOUT")
+	format := cmd.String([]string{"-format"}, "docker", "Archive format (docker or oci)")
 	cmd.Require(flag.Min, 1)
 
 	cmd.ParseFlags(args, true)
@@ -26,7 +32,10 @@ func (cli *DockerCli) CmdSave(args ...string) error {
 		return errors.New("Cowardly refusing to save to a terminal. Use the -o flag or redirect.")
 	}
 
-	responseBody, err := cli.client.ImageSave(context.Background(), cmd.Args())
+	options := types.ImageSaveOptions{
+		Format: *format,
+	}
+	responseBody, err := cli.client.ImageSave(context.Background(), cmd.Args(), options)
 	if err != nil {
 		return err
 	}
@@ -37,6 +46,9 @@ func (cli *DockerCli) CmdSave(args ...string) error {
 		return err
 	}
 
+	if fi, err := os.Stat(*outfile); err == nil && fi.IsDir() {
+		return archive.Untar(responseBody, *outfile, &archive.TarOptions{NoLchown: true})
+	}
 	return copyToFile(*outfile, responseBody)
 
 }
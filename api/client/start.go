@@ -112,11 +112,13 @@ func (cli *DockerCli) CmdStart(args ...string) error {
 		})
 
 		// 3. Start the container.
-		if err := cli.client.ContainerStart(context.Background(), container); err != nil {
+		response, err := cli.client.ContainerStart(context.Background(), container)
+		if err != nil {
 			cancelFun()
 			<-cErr
 			return err
 		}
+		printStartWarnings(cli.err, response)
 
 		// 4. Wait for attachment to break.
 		if c.Config.Tty && cli.isTerminalOut {
@@ -146,10 +148,12 @@ func (cli *DockerCli) CmdStart(args ...string) error {
 func (cli *DockerCli) startContainersWithoutAttachments(containers []string) error {
 	var failedContainers []string
 	for _, container := range containers {
-		if err := cli.client.ContainerStart(context.Background(), container); err != nil {
+		response, err := cli.client.ContainerStart(context.Background(), container)
+		if err != nil {
 			fmt.Fprintf(cli.err, "%s\n", err)
 			failedContainers = append(failedContainers, container)
 		} else {
+			printStartWarnings(cli.err, response)
 			fmt.Fprintf(cli.out, "%s\n", container)
 		}
 	}
@@ -159,3 +163,11 @@ func (cli *DockerCli) startContainersWithoutAttachments(containers []string) err
 	}
 	return nil
 }
+
+// printStartWarnings prints the warnings of the daemon about the settings of
+// a container it started.
+func printStartWarnings(out io.Writer, response types.ContainerStartResponse) {
+	for _, warning := range response.Warnings {
+		fmt.Fprintf(out, "WARNING: %s\n", warning)
+	}
+}
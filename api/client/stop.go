@@ -2,11 +2,13 @@ package client
 
 import (
 	"fmt"
+	"io"
 	"strings"
 
 	"golang.org/x/net/context"
 
 	Cli "github.com/docker/docker/cli"
+	"github.com/docker/docker/pkg/jsonmessage"
 	flag "github.com/docker/docker/pkg/mflag"
 )
 
@@ -24,7 +26,7 @@ func (cli *DockerCli) CmdStop(args ...string) error {
 
 	var errs []string
 	for _, name := range cmd.Args() {
-		if err := cli.client.ContainerStop(context.Background(), name, *nSeconds); err != nil {
+		if err := cli.stopContainer(name, *nSeconds, false); err != nil {
 			errs = append(errs, err.Error())
 		} else {
 			fmt.Fprintf(cli.out, "%s\n", name)
@@ -35,3 +37,32 @@ func (cli *DockerCli) CmdStop(args ...string) error {
 	}
 	return nil
 }
+
+// stopContainer stops, or restarts, the container name. When the output is
+// a terminal, the phases of the stop are displayed, so that a container
+// slow to stop doesn't look hung.
+func (cli *DockerCli) stopContainer(name string, seconds int, restart bool) error {
+	ctx := context.Background()
+	if !cli.isTerminalOut {
+		if restart {
+			return cli.client.ContainerRestart(ctx, name, seconds)
+		}
+		return cli.client.ContainerStop(ctx, name, seconds)
+	}
+
+	var (
+		responseBody io.ReadCloser
+		err          error
+	)
+	if restart {
+		responseBody, err = cli.client.ContainerRestartWithProgress(ctx, name, seconds)
+	} else {
+		responseBody, err = cli.client.ContainerStopWithProgress(ctx, name, seconds)
+	}
+	if err != nil {
+		return err
+	}
+	defer responseBody.Close()
+
+	return jsonmessage.DisplayJSONMessagesStream(responseBody, cli.out, cli.outFd, cli.isTerminalOut, nil)
+}
@@ -0,0 +1,306 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/net/context"
+
+	Cli "github.com/docker/docker/cli"
+	flag "github.com/docker/docker/pkg/mflag"
+	"github.com/docker/docker/reference"
+	"github.com/docker/docker/registry"
+	"github.com/docker/engine-api/types"
+	"github.com/docker/notary/tuf/data"
+)
+
+// CmdTrust is the parent subcommand for all the commands managing the
+// content trust keys of the daemon
+//
+// Usage: docker trust <COMMAND> <OPTS>
+func (cli *DockerCli) CmdTrust(args ...string) error {
+	description := Cli.DockerCommands["trust"].Description + "\n\nCommands:\n"
+	commands := [][]string{
+		{"backup", "Back up the signing keys of the daemon"},
+		{"generate", "Generate a signing key"},
+		{"keys", "List the signing keys of the daemon"},
+		{"rotate", "Rotate a key of the trust data of a repository"},
+		{"sign", "Sign a tag of a repository as it is in its registry"},
+		{"signers", "List the signers of a repository"},
+	}
+
+	for _, cmd := range commands {
+		description += fmt.Sprintf("  %-25.25s%s\n", cmd[0], cmd[1])
+	}
+
+	description += "\nRun 'docker trust COMMAND --help' for more information on a command"
+	cmd := Cli.Subcmd("trust", []string{"[COMMAND]"}, description, false)
+
+	cmd.Require(flag.Exact, 0)
+	err := cmd.ParseFlags(args, true)
+	cmd.Usage()
+	return err
+}
+
+// CmdTrustKeys lists the signing keys of the daemon.
+//
+// Usage: docker trust keys [OPTIONS]
+func (cli *DockerCli) CmdTrustKeys(args ...string) error {
+	cmd := Cli.Subcmd("trust keys", nil, "List the signing keys of the daemon", true)
+	quiet := cmd.Bool([]string{"q", "-quiet"}, false, "Only display key IDs")
+	cmd.Require(flag.Exact, 0)
+	cmd.ParseFlags(args, true)
+
+	keys, err := cli.client.TrustKeyList(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if *quiet {
+		for _, key := range keys {
+			fmt.Fprintln(cli.out, key.ID)
+		}
+		return nil
+	}
+	w := tabwriter.NewWriter(cli.out, 20, 1, 3, ' ', 0)
+	fmt.Fprintf(w, "ROLE\tKEY ID\tREPOSITORY\tSTORE\n")
+	for _, key := range keys {
+		store := "file"
+		if key.Hardware {
+			store = "hardware"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", key.Role, key.ID, key.GUN, store)
+	}
+	w.Flush()
+	return nil
+}
+
+// CmdTrustGenerate generates a root key, or the key of a signer, in the
+// daemon.
+//
+// Usage: docker trust generate [OPTIONS] [NAME]
+func (cli *DockerCli) CmdTrustGenerate(args ...string) error {
+	cmd := Cli.Subcmd("trust generate", []string{"[NAME]"}, "Generate a root key, or the signing key of the signer NAME, and print its public key", true)
+	root := cmd.Bool([]string{"-root"}, false, "Generate a root key")
+	hardware := cmd.Bool([]string{"-pkcs11"}, false, "Store the root key on the hardware token of the daemon")
+	cmd.Require(flag.Max, 1)
+	cmd.ParseFlags(args, true)
+
+	req := types.TrustKeyCreateRequest{
+		Name:     cmd.Arg(0),
+		Root:     *root,
+		Hardware: *hardware,
+	}
+	var err error
+	if *root {
+		req.TrustPassphrases, err = cli.trustPassphrases(true, *hardware, data.CanonicalRootRole)
+	} else {
+		req.TrustPassphrases, err = cli.trustPassphrases(true, *hardware, data.CanonicalTargetsRole)
+	}
+	if err != nil {
+		return err
+	}
+
+	key, err := cli.client.TrustKeyCreate(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cli.err, "Generated %s key %s\n", key.Role, key.ID)
+	fmt.Fprint(cli.out, key.PublicKey)
+	return nil
+}
+
+// CmdTrustBackup writes the encrypted signing keys of the daemon to a tar
+// archive.
+//
+// Usage: docker trust backup [OPTIONS]
+func (cli *DockerCli) CmdTrustBackup(args ...string) error {
+	cmd := Cli.Subcmd("trust backup", nil, "Back up the encrypted signing keys of the daemon to a tar archive (streamed to STDOUT by default)", true)
+	outfile := cmd.String([]string{"o", "-output"}, "", "Write to a file, instead of STDOUT")
+	cmd.Require(flag.Exact, 0)
+	cmd.ParseFlags(args, true)
+
+	if *outfile == "" && cli.isTerminalOut {
+		return errors.New("Cowardly refusing to save to a terminal. Use the -o flag or redirect.")
+	}
+
+	responseBody, err := cli.client.TrustKeyBackup(context.Background())
+	if err != nil {
+		return err
+	}
+	defer responseBody.Close()
+
+	if *outfile == "" {
+		_, err := io.Copy(cli.out, responseBody)
+		return err
+	}
+	return copyToFile(*outfile, responseBody)
+}
+
+// CmdTrustSigners lists the signers and the base roles of a repository.
+//
+// Usage: docker trust signers REPOSITORY
+func (cli *DockerCli) CmdTrustSigners(args ...string) error {
+	cmd := Cli.Subcmd("trust signers", []string{"REPOSITORY"}, "List the signers and the keys of a repository", true)
+	cmd.Require(flag.Exact, 1)
+	cmd.ParseFlags(args, true)
+
+	options, err := cli.trustRepositoryOptions(cmd.Arg(0), "trust signers")
+	if err != nil {
+		return err
+	}
+	repo, err := cli.client.TrustSigners(context.Background(), cmd.Arg(0), options)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(cli.out, 20, 1, 3, ' ', 0)
+	fmt.Fprintf(w, "SIGNER\tKEY IDS\tPATHS\n")
+	for _, signer := range repo.Signers {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", signer.Name, strings.Join(signer.KeyIDs, ","), strings.Join(signer.Paths, ","))
+	}
+	w.Flush()
+
+	fmt.Fprintln(cli.out)
+	w = tabwriter.NewWriter(cli.out, 20, 1, 3, ' ', 0)
+	fmt.Fprintf(w, "ROLE\tKEY IDS\n")
+	for _, role := range repo.BaseRoles {
+		fmt.Fprintf(w, "%s\t%s\n", role.Name, strings.Join(role.KeyIDs, ","))
+	}
+	w.Flush()
+	return nil
+}
+
+// CmdTrustRotate rotates a key of the trust data of a repository.
+//
+// Usage: docker trust rotate [OPTIONS] REPOSITORY
+func (cli *DockerCli) CmdTrustRotate(args ...string) error {
+	cmd := Cli.Subcmd("trust rotate", []string{"REPOSITORY"}, "Rotate a key of the trust data of a repository", true)
+	role := cmd.String([]string{"-role"}, data.CanonicalTargetsRole, "Role whose key is rotated (root, targets, snapshot or timestamp)")
+	serverManaged := cmd.Bool([]string{"-server-managed"}, false, "Let the notary server manage the new key (snapshot or timestamp)")
+	hardware := cmd.Bool([]string{"-pkcs11"}, false, "Sign with the root key of the hardware token of the daemon")
+	cmd.Require(flag.Exact, 1)
+	cmd.ParseFlags(args, true)
+
+	options, err := cli.trustRepositoryOptions(cmd.Arg(0), "trust rotate")
+	if err != nil {
+		return err
+	}
+	req := types.TrustRotateRequest{
+		Role:          *role,
+		ServerManaged: *serverManaged,
+	}
+	aliases := []string{data.CanonicalRootRole}
+	if !*serverManaged && *role != data.CanonicalRootRole {
+		aliases = append(aliases, data.CanonicalTargetsRole)
+	}
+	if req.TrustPassphrases, err = cli.trustPassphrases(false, *hardware, aliases...); err != nil {
+		return err
+	}
+
+	if err := cli.client.TrustKeyRotate(context.Background(), cmd.Arg(0), req, options); err != nil {
+		return notaryError(cmd.Arg(0), err)
+	}
+	fmt.Fprintf(cli.out, "Successfully rotated the %s key of %s\n", *role, cmd.Arg(0))
+	return nil
+}
+
+// CmdTrustSign signs a tag of a repository, as it is in its registry, with
+// the keys of the daemon.
+//
+// Usage: docker trust sign [OPTIONS] NAME:TAG
+func (cli *DockerCli) CmdTrustSign(args ...string) error {
+	cmd := Cli.Subcmd("trust sign", []string{"NAME:TAG"}, "Sign a tag of a repository as it is in its registry", true)
+	hardware := cmd.Bool([]string{"-pkcs11"}, false, "Initialize new trust data with the root key of the hardware token of the daemon")
+	cmd.Require(flag.Exact, 1)
+	cmd.ParseFlags(args, true)
+
+	ref, err := reference.ParseNamed(cmd.Arg(0))
+	if err != nil {
+		return err
+	}
+	tagged, ok := ref.(reference.NamedTagged)
+	if !ok {
+		return fmt.Errorf("%s has no tag to sign", cmd.Arg(0))
+	}
+	repository := ref.Name()
+	options, err := cli.trustRepositoryOptions(repository, "trust sign")
+	if err != nil {
+		return err
+	}
+
+	// The root key only signs the trust data of a repository that has none
+	// yet.
+	aliases := []string{data.CanonicalTargetsRole}
+	if _, err := cli.client.TrustSigners(context.Background(), repository, options); err != nil {
+		aliases = append(aliases, data.CanonicalRootRole)
+	}
+	req := types.TrustSignRequest{
+		Tag:      tagged.Tag(),
+		Hardware: *hardware,
+	}
+	if req.TrustPassphrases, err = cli.trustPassphrases(false, *hardware, aliases...); err != nil {
+		return err
+	}
+
+	target, err := cli.client.TrustSign(context.Background(), repository, req, options)
+	if err != nil {
+		return notaryError(repository, err)
+	}
+	fmt.Fprintf(cli.out, "Successfully signed %s:%s (%s) into %s\n", repository, target.Tag, target.Digest, strings.Join(target.Roles, ", "))
+	return nil
+}
+
+// trustRepositoryOptions returns the options of the requests on the trust
+// data of repository, with the credentials of its registry.
+func (cli *DockerCli) trustRepositoryOptions(repository, cmdName string) (types.TrustRepositoryOptions, error) {
+	ref, err := reference.ParseNamed(repository)
+	if err != nil {
+		return types.TrustRepositoryOptions{}, err
+	}
+	repoInfo, err := registry.ParseRepositoryInfo(ref)
+	if err != nil {
+		return types.TrustRepositoryOptions{}, err
+	}
+	encodedAuth, err := encodeAuthToBase64(cli.resolveAuthConfig(repoInfo.Index))
+	if err != nil {
+		return types.TrustRepositoryOptions{}, err
+	}
+	return types.TrustRepositoryOptions{
+		RegistryAuth:  encodedAuth,
+		PrivilegeFunc: cli.registryAuthenticationPrivilegedFunc(repoInfo.Index, cmdName),
+		Server:        os.Getenv("DOCKER_CONTENT_TRUST_SERVER"),
+	}, nil
+}
+
+// trustPassphrases returns the passphrases of the keys of aliases, and the
+// PIN of the hardware token if hardware is set, from the environment or
+// prompted for. The passphrases of new keys are asked twice if createNew is
+// set.
+func (cli *DockerCli) trustPassphrases(createNew, hardware bool, aliases ...string) (types.TrustPassphrases, error) {
+	var p types.TrustPassphrases
+	retriever := cli.getPassphraseRetriever()
+	for _, alias := range aliases {
+		secret, _, err := retriever(alias, alias, createNew, 0)
+		if err != nil {
+			return p, err
+		}
+		if alias == data.CanonicalRootRole {
+			p.RootPassphrase = secret
+		} else {
+			p.RepositoryPassphrase = secret
+		}
+	}
+	if hardware {
+		pin, _, err := retriever("User Pin", "yubikey", false, 0)
+		if err != nil {
+			return p, err
+		}
+		p.PIN = pin
+	}
+	return p, nil
+}
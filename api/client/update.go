@@ -28,7 +28,16 @@ func (cli *DockerCli) CmdUpdate(args ...string) error {
 	flMemoryReservation := cmd.String([]string{"-memory-reservation"}, "", "Memory soft limit")
 	flMemorySwap := cmd.String([]string{"-memory-swap"}, "", "Swap limit equal to memory plus swap: '-1' to enable unlimited swap")
 	flKernelMemory := cmd.String([]string{"-kernel-memory"}, "", "Kernel memory limit")
+	flSwappiness := cmd.Int64([]string{"-memory-swappiness"}, -1, "Tune container memory swappiness (0 to 100)")
 	flRestartPolicy := cmd.String([]string{"-restart"}, "", "Restart policy to apply when a container exits")
+	flDeviceReadBps := opts.NewThrottledeviceOpt(opts.ValidateThrottleBpsDevice)
+	flDeviceWriteBps := opts.NewThrottledeviceOpt(opts.ValidateThrottleBpsDevice)
+	flDeviceReadIOps := opts.NewThrottledeviceOpt(opts.ValidateThrottleIOpsDevice)
+	flDeviceWriteIOps := opts.NewThrottledeviceOpt(opts.ValidateThrottleIOpsDevice)
+	cmd.Var(&flDeviceReadBps, []string{"-device-read-bps"}, "Limit read rate (bytes per second) from a device, 0 to remove the limit")
+	cmd.Var(&flDeviceWriteBps, []string{"-device-write-bps"}, "Limit write rate (bytes per second) to a device, 0 to remove the limit")
+	cmd.Var(&flDeviceReadIOps, []string{"-device-read-iops"}, "Limit read rate (IO per second) from a device, 0 to remove the limit")
+	cmd.Var(&flDeviceWriteIOps, []string{"-device-write-iops"}, "Limit write rate (IO per second) to a device, 0 to remove the limit")
 
 	cmd.Require(flag.Min, 1)
 	cmd.ParseFlags(args, true)
@@ -73,6 +82,14 @@ func (cli *DockerCli) CmdUpdate(args ...string) error {
 		}
 	}
 
+	var swappiness *int64
+	if cmd.IsSet("-memory-swappiness") {
+		if *flSwappiness < 0 || *flSwappiness > 100 {
+			return fmt.Errorf("invalid value: %d. Valid memory swappiness range is 0-100", *flSwappiness)
+		}
+		swappiness = flSwappiness
+	}
+
 	var restartPolicy container.RestartPolicy
 	if *flRestartPolicy != "" {
 		restartPolicy, err = opts.ParseRestartPolicy(*flRestartPolicy)
@@ -82,16 +99,21 @@ func (cli *DockerCli) CmdUpdate(args ...string) error {
 	}
 
 	resources := container.Resources{
-		BlkioWeight:       *flBlkioWeight,
-		CpusetCpus:        *flCpusetCpus,
-		CpusetMems:        *flCpusetMems,
-		CPUShares:         *flCPUShares,
-		Memory:            flMemory,
-		MemoryReservation: memoryReservation,
-		MemorySwap:        memorySwap,
-		KernelMemory:      kernelMemory,
-		CPUPeriod:         *flCPUPeriod,
-		CPUQuota:          *flCPUQuota,
+		BlkioWeight:          *flBlkioWeight,
+		CpusetCpus:           *flCpusetCpus,
+		CpusetMems:           *flCpusetMems,
+		CPUShares:            *flCPUShares,
+		Memory:               flMemory,
+		MemoryReservation:    memoryReservation,
+		MemorySwap:           memorySwap,
+		KernelMemory:         kernelMemory,
+		MemorySwappiness:     swappiness,
+		BlkioDeviceReadBps:   flDeviceReadBps.GetList(),
+		BlkioDeviceWriteBps:  flDeviceWriteBps.GetList(),
+		BlkioDeviceReadIOps:  flDeviceReadIOps.GetList(),
+		BlkioDeviceWriteIOps: flDeviceWriteIOps.GetList(),
+		CPUPeriod:            *flCPUPeriod,
+		CPUQuota:             *flCPUQuota,
 	}
 
 	updateConfig := container.UpdateConfig{
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"bufio"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -10,6 +11,7 @@ import (
 	gosignal "os/signal"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"time"
 
 	"golang.org/x/net/context"
@@ -199,3 +201,12 @@ func (cli *DockerCli) retrieveAuthConfigs() map[string]types.AuthConfig {
 	acs, _ := getAllCredentials(cli.configFile)
 	return acs
 }
+
+// confirm prints message and asks the user whether to continue. It returns
+// true only if the user answered yes.
+func (cli *DockerCli) confirm(message string) bool {
+	fmt.Fprintf(cli.out, "%s\nAre you sure you want to continue? [y/N] ", message)
+	answer, _ := bufio.NewReader(cli.in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
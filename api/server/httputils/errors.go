@@ -5,6 +5,8 @@ import (
 	"strings"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/errors"
+	"github.com/docker/engine-api/types"
 )
 
 // httpStatusError is an interface
@@ -24,6 +26,70 @@ type inputValidationError interface {
 	IsValidationError() bool
 }
 
+// errorWithCode is an interface
+// that typed errors implement to
+// tell the api layer their stable
+// code, and the subsystem of the
+// container runtime they come from.
+type errorWithCode interface {
+	ErrorCode() string
+	Subsystem() string
+}
+
+// errorWithDetails is an interface
+// that errors with details implement
+// to have them sent to the client
+// along with their message, as JSON.
+type errorWithDetails interface {
+	ErrorResponse() types.ErrorResponse
+}
+
+// plainError is an error sent as plain
+// text with the status code of the
+// error it replaces.
+type plainError struct {
+	message    string
+	statusCode int
+}
+
+func (e plainError) Error() string {
+	return e.message
+}
+
+func (e plainError) HTTPErrorStatusCode() int {
+	return e.statusCode
+}
+
+// WithoutDetails returns an error sent as plain text
+// in place of err, for the clients of the API versions
+// that expect all errors as text.
+func WithoutDetails(err error) error {
+	return plainError{message: err.Error(), statusCode: GetHTTPErrorStatusCode(err)}
+}
+
+// ErrorResponse returns the JSON body of the response
+// of the error err, sent with the status code statusCode.
+// The errors without a code of their own get the code
+// of their status code.
+func ErrorResponse(err error, statusCode int) types.ErrorResponse {
+	resp := types.ErrorResponse{Message: err.Error()}
+	if e, ok := err.(errorWithDetails); ok {
+		resp = e.ErrorResponse()
+	}
+	if e, ok := err.(errorWithCode); ok {
+		if resp.Code == "" {
+			resp.Code = e.ErrorCode()
+		}
+		if resp.Subsystem == "" {
+			resp.Subsystem = e.Subsystem()
+		}
+	}
+	if resp.Code == "" {
+		resp.Code = errors.StatusCode(statusCode)
+	}
+	return resp
+}
+
 // GetHTTPErrorStatusCode retrieve status code from error message
 func GetHTTPErrorStatusCode(err error) int {
 	if err == nil {
@@ -78,5 +144,11 @@ func WriteError(w http.ResponseWriter, err error) {
 	}
 
 	statusCode := GetHTTPErrorStatusCode(err)
-	http.Error(w, err.Error(), statusCode)
+	if _, ok := err.(plainError); ok {
+		http.Error(w, err.Error(), statusCode)
+		return
+	}
+	if writeErr := WriteJSON(w, statusCode, ErrorResponse(err, statusCode)); writeErr != nil {
+		logrus.Errorf("Error writing the error response of %v: %v", err, writeErr)
+	}
 }
@@ -0,0 +1,76 @@
+package httputils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/docker/docker/errors"
+	"github.com/docker/engine-api/types"
+)
+
+func TestWriteError(t *testing.T) {
+	for _, c := range []struct {
+		err      error
+		status   int
+		expected types.ErrorResponse
+	}{
+		{
+			err:      errors.NewRequestNotFoundError(fmt.Errorf("No such container: foo")),
+			status:   http.StatusNotFound,
+			expected: types.ErrorResponse{Message: "No such container: foo", Code: types.ErrorCodeNotFound},
+		},
+		{
+			err:      errors.NewResourceExhaustedError(fmt.Errorf("cannot allocate 4 CPUs")),
+			status:   429,
+			expected: types.ErrorResponse{Message: "cannot allocate 4 CPUs", Code: types.ErrorCodeResourceExhausted},
+		},
+		{
+			err:      errors.NewRuntimeError(fmt.Errorf("container not found"), types.ErrorCodeNotFound, "hcs"),
+			status:   http.StatusNotFound,
+			expected: types.ErrorResponse{Message: "container not found", Code: types.ErrorCodeNotFound, Subsystem: "hcs"},
+		},
+		{
+			err:      errors.NewRuntimeError(fmt.Errorf("shim error"), types.ErrorCodeRuntime, "containerd"),
+			status:   http.StatusInternalServerError,
+			expected: types.ErrorResponse{Message: "shim error", Code: types.ErrorCodeRuntime, Subsystem: "containerd"},
+		},
+		{
+			// the untyped errors get the code of their status code
+			err:      fmt.Errorf("Conflict. The name \"/foo\" is already in use"),
+			status:   http.StatusConflict,
+			expected: types.ErrorResponse{Message: "Conflict. The name \"/foo\" is already in use", Code: types.ErrorCodeConflict},
+		},
+		{
+			err:      fmt.Errorf("something went wrong"),
+			status:   http.StatusInternalServerError,
+			expected: types.ErrorResponse{Message: "something went wrong", Code: types.ErrorCodeInternal},
+		},
+	} {
+		w := httptest.NewRecorder()
+		WriteError(w, c.err)
+		if w.Code != c.status {
+			t.Fatalf("Expected status %d for %v, got %d", c.status, c.err, w.Code)
+		}
+		var resp types.ErrorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Failed to decode the response of %v: %v", c.err, err)
+		}
+		if resp != c.expected {
+			t.Fatalf("Expected response %+v for %v, got %+v", c.expected, c.err, resp)
+		}
+	}
+}
+
+func TestWriteErrorWithoutDetails(t *testing.T) {
+	w := httptest.NewRecorder()
+	WriteError(w, WithoutDetails(errors.NewRequestConflictError(fmt.Errorf("Container foo is paused"))))
+	if w.Code != http.StatusConflict {
+		t.Fatalf("Expected status %d, got %d", http.StatusConflict, w.Code)
+	}
+	if body := w.Body.String(); body != "Container foo is paused\n" {
+		t.Fatalf("Expected the error as plain text, got %q", body)
+	}
+}
@@ -4,12 +4,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"strings"
+	"time"
 
 	"golang.org/x/net/context"
 
 	"github.com/docker/docker/api"
+	"github.com/docker/docker/pkg/peercred"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/docker/engine-api/types"
 )
 
 // APIVersionKey is the client's requested API version.
@@ -18,14 +23,36 @@ const APIVersionKey = "api-version"
 // UAStringKey is used as key type for user-agent string in net/context struct
 const UAStringKey = "upstream-user-agent"
 
+// TenantKey is the user a request is restricted to in tenancy mode.
+const TenantKey = "tenant"
+
+// StreamIdleTimeoutKey is the duration after which the streams of a request
+// without any traffic are closed.
+const StreamIdleTimeoutKey = "stream-idle-timeout"
+
+// StreamEndTrailer is the HTTP trailer of the logs and events streams
+// explaining why the daemon ended them.
+const StreamEndTrailer = "X-Docker-Stream-End"
+
 // APIFunc is an adapter to allow the use of ordinary functions as Docker API endpoints.
 // Any function that has the appropriate signature can be registered as a API endpoint (e.g. getVersion).
 type APIFunc func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error
 
+// ErrHijackUnsupported is returned by the streaming endpoints for the
+// connections which can't be hijacked, such as the HTTP/2 ones.
+var ErrHijackUnsupported error = plainError{
+	message:    "streaming endpoints require HTTP/1.1, the connection can't be hijacked",
+	statusCode: http.StatusHTTPVersionNotSupported,
+}
+
 // HijackConnection interrupts the http response writer to get the
 // underlying connection and operate with it.
 func HijackConnection(w http.ResponseWriter) (io.ReadCloser, io.Writer, error) {
-	conn, _, err := w.(http.Hijacker).Hijack()
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, nil, ErrHijackUnsupported
+	}
+	conn, _, err := hijacker.Hijack()
 	if err != nil {
 		return nil, nil, err
 	}
@@ -104,3 +131,65 @@ func VersionFromContext(ctx context.Context) (ver string) {
 	}
 	return val.(string)
 }
+
+// TenantFromContext returns the user the request of ctx is restricted to in
+// tenancy mode, or an empty string for the requests of admins and without
+// tenancy.
+func TenantFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	tenant, _ := ctx.Value(TenantKey).(string)
+	return tenant
+}
+
+// StreamIdleTimeoutFromContext returns the duration after which the streams
+// of the request of ctx without any traffic are closed, or 0 if they are
+// kept open.
+func StreamIdleTimeoutFromContext(ctx context.Context) time.Duration {
+	if ctx == nil {
+		return 0
+	}
+	timeout, _ := ctx.Value(StreamIdleTimeoutKey).(time.Duration)
+	return timeout
+}
+
+// IdleStreamNotice returns the notice sent to the clients of the streams
+// closed after timeout without any traffic.
+func IdleStreamNotice(timeout time.Duration) string {
+	return fmt.Sprintf("stream closed by the daemon after %s without traffic", timeout)
+}
+
+// idleNoticeTimeout bounds the time spent sending the notice of the closing
+// of an idle stream to a client which may be gone.
+const idleNoticeTimeout = 5 * time.Second
+
+// CloseIdleStream sends notice to the client of the hijacked connection conn,
+// in a stdcopy.Systemerr frame if the stream is multiplexed, and closes conn.
+func CloseIdleStream(conn io.Closer, notice string, muxed bool) {
+	if c, ok := conn.(net.Conn); ok {
+		c.SetWriteDeadline(time.Now().Add(idleNoticeTimeout))
+	}
+	if w, ok := conn.(io.Writer); ok {
+		if muxed {
+			stdcopy.NewStdWriter(w, stdcopy.Systemerr).Write([]byte(notice))
+		} else {
+			fmt.Fprintf(w, "\r\n%s\r\n", notice)
+		}
+	}
+	conn.Close()
+}
+
+// RequesterFromRequest returns the identity of the client of r: the common
+// name of its TLS client certificate, its address and its user agent.
+func RequesterFromRequest(r *http.Request) types.Requester {
+	requester := types.Requester{UserAgent: r.Header.Get("User-Agent")}
+	if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		requester.User = r.TLS.PeerCertificates[0].Subject.CommonName
+	}
+	// the clients of unix sockets have no address, only credentials
+	if _, ok := peercred.Parse(r.RemoteAddr); !ok && r.RemoteAddr != "@" {
+		requester.RemoteAddr = r.RemoteAddr
+	}
+	return requester
+}
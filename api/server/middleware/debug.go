@@ -6,16 +6,17 @@ import (
 	"io"
 	"net/http"
 
-	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/api/server/httputils"
 	"github.com/docker/docker/pkg/ioutils"
+	"github.com/docker/docker/pkg/requestid"
 	"golang.org/x/net/context"
 )
 
 // DebugRequestMiddleware dumps the request to logger
 func DebugRequestMiddleware(handler func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error) func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
-		logrus.Debugf("Calling %s %s", r.Method, r.RequestURI)
+		logger := requestid.Logger(ctx)
+		logger.Debugf("Calling %s %s", r.Method, r.RequestURI)
 
 		if r.Method != "POST" {
 			return handler(ctx, w, r, vars)
@@ -45,9 +46,9 @@ func DebugRequestMiddleware(handler func(ctx context.Context, w http.ResponseWri
 			}
 			formStr, errMarshal := json.Marshal(postForm)
 			if errMarshal == nil {
-				logrus.Debugf("form data: %s", string(formStr))
+				logger.Debugf("form data: %s", string(formStr))
 			} else {
-				logrus.Debugf("form data: %q", postForm)
+				logger.Debugf("form data: %q", postForm)
 			}
 		}
 
@@ -0,0 +1,300 @@
+package middleware
+
+import (
+	"expvar"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/server/httputils"
+	"github.com/docker/docker/errors"
+	"github.com/docker/docker/pkg/peercred"
+	"golang.org/x/net/context"
+)
+
+var (
+	// rateLimitedRequests counts the requests rejected by each rate limit,
+	// and inFlightRequests the requests in flight matching each rate limit
+	// with a MaxInFlight. They are exported on the /debug/vars endpoint.
+	rateLimitedRequests = expvar.NewMap("api_rate_limited_requests")
+	inFlightRequests    = expvar.NewMap("api_in_flight_requests")
+)
+
+// rateLimitSweepInterval is the interval between the removals of the state
+// of the clients without requests in flight and with a full bucket.
+const rateLimitSweepInterval = time.Minute
+
+// statusTooManyRequests is the status of the requests rejected by a rate
+// limit. net/http only defines it as of Go 1.6.
+const statusTooManyRequests = 429
+
+// RateLimit limits the requests to the endpoints matching Method and Path:
+// to Rate requests per second, with bursts of Burst requests, and to
+// MaxInFlight concurrent requests. The limits apply to the requests of each
+// client if PerClient is set, and to all the requests otherwise.
+type RateLimit struct {
+	// Method is the method of the requests, empty for all methods.
+	Method string
+	// Path is a pattern, in the syntax of path.Match, of the path of the
+	// requests without its API version, empty for all paths.
+	Path string
+
+	// Rate is the number of requests per second, 0 for no rate.
+	Rate float64
+	// Burst is the number of requests above Rate accepted at once.
+	Burst int
+	// MaxInFlight is the number of concurrent requests, 0 for no limit.
+	MaxInFlight int
+
+	PerClient bool
+}
+
+// Name returns the endpoints limited by l, such as "GET /containers/json".
+func (l RateLimit) Name() string {
+	method, p := l.Method, l.Path
+	if method == "" {
+		method = "*"
+	}
+	if p == "" {
+		p = "*"
+	}
+	return method + " " + p
+}
+
+// ParseRateLimit parses a rate limit in the comma-separated key=value
+// format of the --api-limit option of the daemon, with the keys method,
+// path, rate, burst, max-in-flight and scope, either global or client, such
+// as "method=GET,path=/containers/json,rate=5,scope=client".
+func ParseRateLimit(spec string) (RateLimit, error) {
+	l := RateLimit{}
+	for _, field := range strings.Split(spec, ",") {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 {
+			return RateLimit{}, fmt.Errorf("invalid API limit %q: %q is not a key=value pair", spec, field)
+		}
+		key, value := strings.ToLower(strings.TrimSpace(parts[0])), strings.TrimSpace(parts[1])
+
+		var err error
+		switch key {
+		case "method":
+			l.Method = strings.ToUpper(value)
+		case "path":
+			if _, err = path.Match(value, "/"); err == nil && !strings.HasPrefix(value, "/") {
+				err = fmt.Errorf("the path must start with /")
+			}
+			l.Path = value
+		case "rate":
+			l.Rate, err = strconv.ParseFloat(value, 64)
+			if err == nil && (l.Rate < 0 || math.IsInf(l.Rate, 0) || math.IsNaN(l.Rate)) {
+				err = fmt.Errorf("the rate must be positive")
+			}
+		case "burst":
+			l.Burst, err = strconv.Atoi(value)
+			if err == nil && l.Burst < 1 {
+				err = fmt.Errorf("the burst must be at least 1")
+			}
+		case "max-in-flight":
+			l.MaxInFlight, err = strconv.Atoi(value)
+			if err == nil && l.MaxInFlight < 0 {
+				err = fmt.Errorf("the maximum of requests in flight must be positive")
+			}
+		case "scope":
+			switch value {
+			case "global":
+				l.PerClient = false
+			case "client":
+				l.PerClient = true
+			default:
+				err = fmt.Errorf("the scope must be global or client")
+			}
+		default:
+			err = fmt.Errorf("unknown key %s", key)
+		}
+		if err != nil {
+			return RateLimit{}, fmt.Errorf("invalid API limit %q: %v", spec, err)
+		}
+	}
+
+	if l.Rate == 0 && l.MaxInFlight == 0 {
+		return RateLimit{}, fmt.Errorf("invalid API limit %q: a rate or a maximum of requests in flight is required", spec)
+	}
+	if l.Burst != 0 && l.Rate == 0 {
+		return RateLimit{}, fmt.Errorf("invalid API limit %q: a burst requires a rate", spec)
+	}
+	if l.Burst == 0 && l.Rate != 0 {
+		l.Burst = int(math.Max(1, math.Ceil(l.Rate)))
+	}
+	return l, nil
+}
+
+// RateLimitMiddleware rejects the requests exceeding the rate limits of
+// their endpoint with a 429 error.
+type RateLimitMiddleware struct {
+	limiters []*limiter
+}
+
+// NewRateLimitMiddleware creates a new RateLimitMiddleware enforcing limits.
+func NewRateLimitMiddleware(limits []RateLimit) *RateLimitMiddleware {
+	m := &RateLimitMiddleware{}
+	for _, l := range limits {
+		m.limiters = append(m.limiters, &limiter{
+			limit:   l,
+			name:    l.Name(),
+			clients: make(map[string]*clientLimit),
+		})
+	}
+	return m
+}
+
+// WrapHandler returns a new handler function wrapping the previous one in the request chain.
+func (m *RateLimitMiddleware) WrapHandler(handler func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error) func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		p := r.URL.Path
+		if v := vars["version"]; v != "" {
+			p = strings.TrimPrefix(p, "/v"+v)
+		}
+		client := clientKey(r)
+		now := time.Now()
+
+		var releases []func()
+		defer func() {
+			for _, release := range releases {
+				release()
+			}
+		}()
+		for _, l := range m.limiters {
+			if !l.matches(r.Method, p) {
+				continue
+			}
+			release, retryAfter, err := l.acquire(client, now)
+			if err != nil {
+				rateLimitedRequests.Add(l.name, 1)
+				if retryAfter > 0 {
+					w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				}
+				return errors.NewErrorWithStatusCode(err, statusTooManyRequests)
+			}
+			releases = append(releases, release)
+		}
+		return handler(ctx, w, r, vars)
+	}
+}
+
+// clientKey identifies the client of r: by the common name of its TLS
+// client certificate, by its user ID on the Unix sockets, or by its IP
+// address.
+func clientKey(r *http.Request) string {
+	if user := httputils.RequesterFromRequest(r).User; user != "" {
+		return "user=" + user
+	}
+	if creds, ok := peercred.Parse(r.RemoteAddr); ok {
+		return fmt.Sprintf("uid=%d", creds.UID)
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// limiter enforces a RateLimit.
+type limiter struct {
+	limit RateLimit
+	name  string
+
+	mu        sync.Mutex
+	clients   map[string]*clientLimit // by client, or "" for a global limit
+	lastSweep time.Time
+}
+
+// clientLimit is the state of the limit of a client: a token bucket and the
+// number of requests in flight.
+type clientLimit struct {
+	tokens   float64
+	last     time.Time
+	inFlight int
+}
+
+func (l *limiter) matches(method, p string) bool {
+	if l.limit.Method != "" && l.limit.Method != method {
+		return false
+	}
+	if l.limit.Path != "" {
+		if ok, _ := path.Match(l.limit.Path, p); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// acquire returns the function to call once the request of client sent at
+// now completes, or an error and the time after which the client may retry
+// if the request exceeds the limit.
+func (l *limiter) acquire(client string, now time.Time) (func(), time.Duration, error) {
+	if !l.limit.PerClient {
+		client = ""
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+	c, ok := l.clients[client]
+	if !ok {
+		c = &clientLimit{tokens: float64(l.limit.Burst), last: now}
+		l.clients[client] = c
+	}
+
+	if l.limit.MaxInFlight > 0 && c.inFlight >= l.limit.MaxInFlight {
+		return nil, 0, fmt.Errorf("too many requests in flight to %s, the maximum is %d", l.name, l.limit.MaxInFlight)
+	}
+	if l.limit.Rate > 0 {
+		l.refill(c, now)
+		if c.tokens < 1 {
+			retryAfter := time.Duration((1 - c.tokens) / l.limit.Rate * float64(time.Second))
+			return nil, retryAfter, fmt.Errorf("too many requests to %s, the rate is limited to %g per second", l.name, l.limit.Rate)
+		}
+		c.tokens--
+	}
+
+	if l.limit.MaxInFlight == 0 {
+		return func() {}, 0, nil
+	}
+	c.inFlight++
+	inFlightRequests.Add(l.name, 1)
+	return func() {
+		l.mu.Lock()
+		c.inFlight--
+		l.mu.Unlock()
+		inFlightRequests.Add(l.name, -1)
+	}, 0, nil
+}
+
+// refill adds to the bucket of c the tokens accumulated since its last
+// refill.
+func (l *limiter) refill(c *clientLimit, now time.Time) {
+	if l.limit.Rate > 0 {
+		c.tokens = math.Min(float64(l.limit.Burst), c.tokens+now.Sub(c.last).Seconds()*l.limit.Rate)
+	}
+	c.last = now
+}
+
+// sweep removes, at most every rateLimitSweepInterval, the clients whose
+// state is the one of a new client.
+func (l *limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for client, c := range l.clients {
+		l.refill(c, now)
+		if c.inFlight == 0 && c.tokens >= float64(l.limit.Burst) {
+			delete(l.clients, client)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/server/httputils"
+	"golang.org/x/net/context"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	l, err := ParseRateLimit("method=get,path=/containers/*/json,rate=2.5,scope=client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := RateLimit{Method: "GET", Path: "/containers/*/json", Rate: 2.5, Burst: 3, PerClient: true}
+	if l != expected {
+		t.Fatalf("Expected %+v, got %+v", expected, l)
+	}
+	if l.Name() != "GET /containers/*/json" {
+		t.Fatalf("Unexpected name %s", l.Name())
+	}
+
+	l, err = ParseRateLimit("max-in-flight=4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Name() != "* *" || l.MaxInFlight != 4 || l.Burst != 0 {
+		t.Fatalf("Unexpected limit %+v", l)
+	}
+
+	for _, spec := range []string{
+		"",
+		"path=/containers/json",
+		"path=containers/json,rate=1",
+		"rate=-1",
+		"rate=1,burst=0",
+		"burst=2,max-in-flight=1",
+		"rate=1,scope=user",
+		"rate=1,timeout=3",
+		"rate",
+	} {
+		if _, err := ParseRateLimit(spec); err == nil {
+			t.Fatalf("Expected an error for %q", spec)
+		}
+	}
+}
+
+func TestRateLimitMiddlewareRate(t *testing.T) {
+	m := NewRateLimitMiddleware([]RateLimit{
+		{Method: "GET", Path: "/containers/json", Rate: 1, Burst: 2, PerClient: true},
+	})
+	h := m.WrapHandler(func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		return nil
+	})
+
+	send := func(w http.ResponseWriter, method, path, user string, vars map[string]string) error {
+		return h(context.Background(), w, tenantRequest(method, path, user), vars)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := send(httptest.NewRecorder(), "GET", "/containers/json", "alice", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w := httptest.NewRecorder()
+	err := send(w, "GET", "/v1.24/containers/json", "alice", map[string]string{"version": "1.24"})
+	if err == nil || httputils.GetHTTPErrorStatusCode(err) != statusTooManyRequests {
+		t.Fatalf("Expected a 429 error, got %v", err)
+	}
+	if w.Header().Get("Retry-After") != "1" {
+		t.Fatalf("Expected to retry after 1 second, got %q", w.Header().Get("Retry-After"))
+	}
+
+	// the other clients and endpoints aren't limited
+	if err := send(httptest.NewRecorder(), "GET", "/containers/json", "bob", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := send(httptest.NewRecorder(), "POST", "/containers/json", "alice", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := send(httptest.NewRecorder(), "GET", "/images/json", "alice", nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRateLimitMiddlewareMaxInFlight(t *testing.T) {
+	m := NewRateLimitMiddleware([]RateLimit{{Path: "/containers/*/attach", MaxInFlight: 1}})
+
+	started, done := make(chan struct{}), make(chan struct{})
+	h := m.WrapHandler(func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		close(started)
+		<-done
+		return nil
+	})
+
+	errs := make(chan error)
+	go func() {
+		errs <- h(context.Background(), httptest.NewRecorder(), tenantRequest("POST", "/containers/web/attach", "alice"), nil)
+	}()
+	<-started
+
+	err := h(context.Background(), httptest.NewRecorder(), tenantRequest("POST", "/containers/db/attach", "bob"), nil)
+	if err == nil || httputils.GetHTTPErrorStatusCode(err) != statusTooManyRequests {
+		t.Fatalf("Expected a 429 error, got %v", err)
+	}
+
+	close(done)
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+
+	// the request completed, releasing its slot
+	started = make(chan struct{})
+	if err := h(context.Background(), httptest.NewRecorder(), tenantRequest("POST", "/containers/db/attach", "bob"), nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRateLimiterSweep(t *testing.T) {
+	l := NewRateLimitMiddleware([]RateLimit{{Rate: 10, Burst: 1, PerClient: true}}).limiters[0]
+	now := time.Now()
+	for _, client := range []string{"alice", "bob"} {
+		if _, _, err := l.acquire(client, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(l.clients) != 2 {
+		t.Fatalf("Expected 2 clients, got %d", len(l.clients))
+	}
+
+	if _, _, err := l.acquire("alice", now.Add(rateLimitSweepInterval)); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := l.clients["bob"]; ok || len(l.clients) != 1 {
+		t.Fatalf("Expected the idle client to be removed, got %v", l.clients)
+	}
+}
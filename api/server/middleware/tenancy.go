@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/docker/docker/api/server/httputils"
+	"github.com/docker/docker/errors"
+	"golang.org/x/net/context"
+)
+
+// TenancyBackend checks the access of the users to the objects of the
+// daemon in tenancy mode.
+type TenancyBackend interface {
+	// CheckTenant returns an error unless tenant owns the object name of
+	// kind, "containers", "exec", "images", "jobs", "networks" or "volumes".
+	CheckTenant(kind, name, tenant string) error
+}
+
+// adminRoutes are the routes reserved to the admins in tenancy mode, which
+// act on the objects of all the users.
+var adminRoutes = map[string]bool{
+	"/build/prune":      true,
+	"/containers/prune": true,
+	"/images/load":      true,
+	"/info/bundle":      true,
+	"/networks/prune":   true,
+	"/ports":            true,
+}
+
+// TenancyMiddleware restricts the users identified by their TLS client
+// certificate to the objects they own, unless they are admins. The requests
+// without client certificate, such as the ones of the local socket, are
+// admin requests.
+type TenancyMiddleware struct {
+	backend TenancyBackend
+	admins  map[string]bool
+}
+
+// NewTenancyMiddleware creates a new TenancyMiddleware checking the owners
+// of the objects with backend, and granting all the objects to admins.
+func NewTenancyMiddleware(backend TenancyBackend, admins []string) TenancyMiddleware {
+	m := TenancyMiddleware{
+		backend: backend,
+		admins:  make(map[string]bool, len(admins)),
+	}
+	for _, admin := range admins {
+		m.admins[admin] = true
+	}
+	return m
+}
+
+// WrapHandler returns a new handler function wrapping the previous one in the request chain.
+func (m TenancyMiddleware) WrapHandler(handler func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error) func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		tenant := httputils.RequesterFromRequest(r).User
+		if tenant == "" || m.admins[tenant] {
+			return handler(ctx, w, r, vars)
+		}
+		if err := m.checkAccess(r, vars, tenant); err != nil {
+			return err
+		}
+		ctx = context.WithValue(ctx, httputils.TenantKey, tenant)
+		return handler(ctx, w, r, vars)
+	}
+}
+
+// checkAccess returns an error unless tenant may send r, whose route has
+// the variables vars.
+func (m TenancyMiddleware) checkAccess(r *http.Request, vars map[string]string, tenant string) error {
+	path := r.URL.Path
+	if version := vars["version"]; version != "" {
+		path = strings.TrimPrefix(path, "/v"+version)
+	}
+	// the debug endpoints expose the state of the whole daemon
+	if adminRoutes[path] || strings.HasPrefix(path, "/trust/") || strings.HasPrefix(path, "/debug/") {
+		return errors.NewRequestForbiddenError(fmt.Errorf("%s is reserved to the admins of the daemon", path))
+	}
+
+	query := r.URL.Query()
+	switch path {
+	case "/commit":
+		return m.backend.CheckTenant("containers", query.Get("container"), tenant)
+	case "/images/get":
+		for _, name := range query["names"] {
+			if err := m.backend.CheckTenant("images", name, tenant); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	kind := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 2)[0]
+	switch kind {
+	case "containers", "exec", "images", "jobs", "networks", "volumes":
+		name := vars["name"]
+		if name == "" {
+			name = vars["id"]
+		}
+		if name != "" {
+			if err := m.backend.CheckTenant(kind, name, tenant); err != nil {
+				return err
+			}
+		}
+		// the diagnostics of a network are run for a container
+		if container := query.Get("container"); kind == "networks" && container != "" {
+			return m.backend.CheckTenant("containers", container, tenant)
+		}
+	}
+	return nil
+}
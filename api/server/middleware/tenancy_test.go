@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/docker/docker/api/server/httputils"
+	"golang.org/x/net/context"
+)
+
+// fakeTenancyBackend holds the owners of objects, by kind and name.
+type fakeTenancyBackend struct {
+	owners map[string]string
+}
+
+func (b *fakeTenancyBackend) CheckTenant(kind, name, tenant string) error {
+	if b.owners[kind+"/"+name] != tenant {
+		return fmt.Errorf("No such %s: %s", kind, name)
+	}
+	return nil
+}
+
+// tenantRequest returns a request to path of the client with a certificate
+// for user.
+func tenantRequest(method, path, user string) *http.Request {
+	req, _ := http.NewRequest(method, path, nil)
+	if user != "" {
+		req.TLS = &tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: user}}},
+		}
+	}
+	return req
+}
+
+func TestTenancyMiddleware(t *testing.T) {
+	backend := &fakeTenancyBackend{owners: map[string]string{
+		"containers/web": "alice",
+		"networks/front": "alice",
+		"images/busybox": "bob",
+	}}
+	m := NewTenancyMiddleware(backend, []string{"root"})
+
+	var tenant string
+	h := m.WrapHandler(func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		tenant = httputils.TenantFromContext(ctx)
+		return nil
+	})
+
+	cases := []struct {
+		method, path, user string
+		vars               map[string]string
+		tenant             string
+		fails              bool
+	}{
+		{"GET", "/containers/json", "alice", nil, "alice", false},
+		{"GET", "/v1.24/containers/web/json", "alice", map[string]string{"version": "1.24", "name": "web"}, "alice", false},
+		{"POST", "/networks/front/connect", "alice", map[string]string{"id": "front"}, "alice", false},
+		{"GET", "/networks/front/diagnose?container=web", "alice", map[string]string{"id": "front"}, "alice", false},
+		{"GET", "/networks/front/diagnose?container=db", "alice", map[string]string{"id": "front"}, "", true},
+		{"GET", "/images/busybox/json", "alice", map[string]string{"name": "busybox"}, "", true},
+		{"POST", "/commit?container=db", "alice", nil, "", true},
+		{"GET", "/images/get?names=busybox", "bob", nil, "bob", false},
+		{"POST", "/v1.24/containers/prune", "alice", map[string]string{"version": "1.24"}, "", true},
+		{"POST", "/trust/keys/create", "alice", nil, "", true},
+		{"GET", "/debug/pprof/goroutine", "alice", nil, "", true},
+		{"GET", "/debug/vars", "alice", nil, "", true},
+		{"GET", "/debug/pprof/profile", "alice", nil, "", true},
+		{"GET", "/debug/pprof/goroutine", "root", nil, "", false},
+		// admins and the clients without certificate are not restricted
+		{"GET", "/images/busybox/json", "root", map[string]string{"name": "busybox"}, "", false},
+		{"POST", "/containers/prune", "", nil, "", false},
+	}
+	for _, c := range cases {
+		tenant = ""
+		err := h(context.Background(), httptest.NewRecorder(), tenantRequest(c.method, c.path, c.user), c.vars)
+		if c.fails {
+			if err == nil {
+				t.Fatalf("Expected %s %s of %q to fail", c.method, c.path, c.user)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error for %s %s of %q: %v", c.method, c.path, c.user, err)
+		}
+		if tenant != c.tenant {
+			t.Fatalf("Expected %s %s of %q to be restricted to %q, got %q", c.method, c.path, c.user, c.tenant, tenant)
+		}
+	}
+}
@@ -6,22 +6,39 @@ import (
 	"net/http"
 	"net/http/pprof"
 
+	"github.com/docker/docker/api/server/httputils"
 	"github.com/gorilla/mux"
+	"golang.org/x/net/context"
 )
 
 const debugPathPrefix = "/debug/"
 
-func profilerSetup(mainRouter *mux.Router) {
+// profilerSetup registers the profiling and execution trace endpoints. They
+// go through the global middlewares like any other API route, so that
+// authorization plugins can allow or deny access to them.
+func (s *Server) profilerSetup(mainRouter *mux.Router) {
 	var r = mainRouter.PathPrefix(debugPathPrefix).Subrouter()
-	r.HandleFunc("/vars", expVars)
-	r.HandleFunc("/pprof/", pprof.Index)
-	r.HandleFunc("/pprof/cmdline", pprof.Cmdline)
-	r.HandleFunc("/pprof/profile", pprof.Profile)
-	r.HandleFunc("/pprof/symbol", pprof.Symbol)
-	r.HandleFunc("/pprof/block", pprof.Handler("block").ServeHTTP)
-	r.HandleFunc("/pprof/heap", pprof.Handler("heap").ServeHTTP)
-	r.HandleFunc("/pprof/goroutine", pprof.Handler("goroutine").ServeHTTP)
-	r.HandleFunc("/pprof/threadcreate", pprof.Handler("threadcreate").ServeHTTP)
+	handle := func(path string, h http.HandlerFunc) {
+		r.HandleFunc(path, s.makeHTTPHandler(profilerHandler(h)))
+	}
+	handle("/vars", expVars)
+	handle("/pprof/", pprof.Index)
+	handle("/pprof/cmdline", pprof.Cmdline)
+	handle("/pprof/profile", pprof.Profile)
+	handle("/pprof/symbol", pprof.Symbol)
+	handle("/pprof/trace", pprof.Trace)
+	handle("/pprof/block", pprof.Handler("block").ServeHTTP)
+	handle("/pprof/heap", pprof.Handler("heap").ServeHTTP)
+	handle("/pprof/goroutine", pprof.Handler("goroutine").ServeHTTP)
+	handle("/pprof/threadcreate", pprof.Handler("threadcreate").ServeHTTP)
+}
+
+// profilerHandler adapts a plain http handler to an API function.
+func profilerHandler(h http.HandlerFunc) httputils.APIFunc {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		h(w, r)
+		return nil
+	}
 }
 
 // Replicated from expvar.go as not public.
@@ -5,6 +5,7 @@ import (
 
 	"github.com/docker/docker/api/types/backend"
 	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/filters"
 	"golang.org/x/net/context"
 )
 
@@ -18,3 +19,19 @@ type Backend interface {
 	// TODO: make this return a reference instead of string
 	BuildFromContext(ctx context.Context, src io.ReadCloser, remote string, buildOptions *types.ImageBuildOptions, pg backend.ProgressWriter) (string, error)
 }
+
+// CacheBackend manages the images committed by builds.
+type CacheBackend interface {
+	// BuildCachePrune removes the unused build cache images matching
+	// pruneFilters, keeping up to keepStorage bytes of cache.
+	BuildCachePrune(pruneFilters filters.Args, keepStorage int64) (*types.BuildCachePruneReport, error)
+}
+
+// TenancyBackend records the owners of the images built in tenancy mode.
+type TenancyBackend interface {
+	// ClaimImage records tenant as an owner of the image refOrID.
+	ClaimImage(refOrID, tenant string) error
+	// CheckTenantReference returns an error if the reference ref refers
+	// to an image tenant does not own.
+	CheckTenantReference(ref, tenant string) error
+}
@@ -5,13 +5,17 @@ import "github.com/docker/docker/api/server/router"
 // buildRouter is a router to talk with the build controller
 type buildRouter struct {
 	backend Backend
+	cache   CacheBackend
+	tenancy TenancyBackend
 	routes  []router.Route
 }
 
 // NewRouter initializes a new build router
-func NewRouter(b Backend) router.Router {
+func NewRouter(b Backend, cache CacheBackend, tenancy TenancyBackend) router.Router {
 	r := &buildRouter{
 		backend: b,
+		cache:   cache,
+		tenancy: tenancy,
 	}
 	r.initRoutes()
 	return r
@@ -25,5 +29,6 @@ func (r *buildRouter) Routes() []router.Route {
 func (r *buildRouter) initRoutes() {
 	r.routes = []router.Route{
 		router.Cancellable(router.NewPostRoute("/build", r.postBuild)),
+		router.NewPostRoute("/build/prune", r.postBuildPrune),
 	}
 }
@@ -19,6 +19,7 @@ import (
 	"github.com/docker/docker/pkg/streamformatter"
 	"github.com/docker/engine-api/types"
 	"github.com/docker/engine-api/types/container"
+	"github.com/docker/engine-api/types/filters"
 	"github.com/docker/engine-api/types/versions"
 	"github.com/docker/go-units"
 	"golang.org/x/net/context"
@@ -42,6 +43,7 @@ func newImageBuildOptions(ctx context.Context, r *http.Request) (*types.ImageBui
 	options.SuppressOutput = httputils.BoolValue(r, "q")
 	options.NoCache = httputils.BoolValue(r, "nocache")
 	options.ForceRemove = httputils.BoolValue(r, "forcerm")
+	options.CheckOnly = httputils.BoolValue(r, "check")
 	options.MemorySwap = httputils.Int64ValueOrZero(r, "memswap")
 	options.Memory = httputils.Int64ValueOrZero(r, "memory")
 	options.CPUShares = httputils.Int64ValueOrZero(r, "cpushares")
@@ -93,6 +95,13 @@ func newImageBuildOptions(ctx context.Context, r *http.Request) (*types.ImageBui
 		options.Labels = labels
 	}
 
+	if secretsEncoded := r.Header.Get("X-Build-Secrets"); secretsEncoded != "" {
+		secretsJSON := base64.NewDecoder(base64.URLEncoding, strings.NewReader(secretsEncoded))
+		if err := json.NewDecoder(secretsJSON).Decode(&options.Secrets); err != nil {
+			return nil, fmt.Errorf("invalid X-Build-Secrets header: %v", err)
+		}
+	}
+
 	return options, nil
 }
 
@@ -150,6 +159,13 @@ func (br *buildRouter) postBuild(ctx context.Context, w http.ResponseWriter, r *
 		return errf(err)
 	}
 	buildOptions.AuthConfigs = authConfigs
+	if tenant := httputils.TenantFromContext(ctx); tenant != "" {
+		for _, ref := range buildOptions.Tags {
+			if err := br.tenancy.CheckTenantReference(ref, tenant); err != nil {
+				return errf(err)
+			}
+		}
+	}
 
 	remoteURL := r.FormValue("remote")
 
@@ -183,6 +199,24 @@ func (br *buildRouter) postBuild(ctx context.Context, w http.ResponseWriter, r *
 		return errf(err)
 	}
 
+	// Nothing is built when only the checks of the Dockerfile are run.
+	if buildOptions.CheckOnly {
+		if buildOptions.SuppressOutput {
+			output.Write(notVerboseBuffer.Bytes())
+		}
+		return nil
+	}
+
+	if tenant := httputils.TenantFromContext(ctx); tenant != "" {
+		if err := br.tenancy.ClaimImage(imgID, tenant); err != nil {
+			return errf(err)
+		}
+	}
+
+	// The ID of the image built is reported out-of-band for clients, such
+	// as `docker build --output`, that need it.
+	output.Write(sf.FormatProgress("", "", nil, types.BuildResult{ID: imgID}))
+
 	// Everything worked so if -q was provided the output from the daemon
 	// should be just the image ID and we'll print that to stdout.
 	if buildOptions.SuppressOutput {
@@ -192,3 +226,27 @@ func (br *buildRouter) postBuild(ctx context.Context, w http.ResponseWriter, r *
 
 	return nil
 }
+
+func (br *buildRouter) postBuildPrune(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	pruneFilters, err := filters.FromParam(r.Form.Get("filters"))
+	if err != nil {
+		return err
+	}
+	var keepStorage int64
+	if v := r.FormValue("keep-storage"); v != "" {
+		keepStorage, err = strconv.ParseInt(v, 10, 64)
+		if err != nil || keepStorage < 0 {
+			return fmt.Errorf("invalid keep-storage %q: must be a positive number of bytes", v)
+		}
+	}
+
+	report, err := br.cache.BuildCachePrune(pruneFilters, keepStorage)
+	if err != nil {
+		return err
+	}
+	return httputils.WriteJSON(w, http.StatusOK, report)
+}
@@ -10,14 +10,16 @@ import (
 	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/engine-api/types"
 	"github.com/docker/engine-api/types/container"
+	"github.com/docker/engine-api/types/filters"
 )
 
 // execBackend includes functions to implement to provide exec functionality.
 type execBackend interface {
 	ContainerExecCreate(name string, config *types.ExecConfig) (string, error)
+	ContainerExecHistory(name string) ([]types.ExecRecord, error)
 	ContainerExecInspect(id string) (*backend.ExecInspect, error)
 	ContainerExecResize(name string, height, width int) error
-	ContainerExecStart(name string, stdin io.ReadCloser, stdout io.Writer, stderr io.Writer) error
+	ContainerExecStart(ctx context.Context, name string, stdin io.ReadCloser, stdout io.Writer, stderr io.Writer) error
 	ExecExists(name string) (bool, error)
 }
 
@@ -26,29 +28,36 @@ type copyBackend interface {
 	ContainerArchivePath(name string, path string) (content io.ReadCloser, stat *types.ContainerPathStat, err error)
 	ContainerCopy(name string, res string) (io.ReadCloser, error)
 	ContainerExport(name string, out io.Writer) error
+	ContainerExportSnapshot(name string, out io.Writer) error
+	ContainerSnapshotCreate(ctx context.Context, name string, config *types.ContainerSnapshotConfig) (*types.ContainerSnapshot, error)
+	ContainerSnapshotRemove(name, id string) error
 	ContainerExtractToDir(name, path string, noOverwriteDirNonDir bool, content io.Reader) error
 	ContainerStatPath(name string, path string) (stat *types.ContainerPathStat, err error)
 }
 
 // stateBackend includes functions to implement to provide container state lifecycle functionality.
 type stateBackend interface {
+	ContainerClone(name string, config *types.ContainerCloneConfig) (types.ContainerCreateResponse, error)
 	ContainerCreate(types.ContainerCreateConfig) (types.ContainerCreateResponse, error)
 	ContainerKill(name string, sig uint64) error
 	ContainerPause(name string) error
+	ContainerRefresh(name string, config *types.ContainerRefreshConfig) (types.ContainerCreateResponse, error)
 	ContainerRename(oldName, newName string) error
 	ContainerResize(name string, height, width int) error
-	ContainerRestart(name string, seconds int) error
+	ContainerRestart(name string, seconds int, outStream io.Writer) error
 	ContainerRm(name string, config *types.ContainerRmConfig) error
-	ContainerStart(name string, hostConfig *container.HostConfig) error
-	ContainerStop(name string, seconds int) error
+	ContainerStart(ctx context.Context, name string, hostConfig *container.HostConfig) (types.ContainerStartResponse, error)
+	ContainerStop(name string, seconds int, outStream io.Writer) error
 	ContainerUnpause(name string) error
 	ContainerUpdate(name string, hostConfig *container.HostConfig) ([]string, error)
 	ContainerWait(name string, timeout time.Duration) (int, error)
+	ContainersBatch(action string, config *backend.ContainersBatchConfig) (*types.ContainersBatchReport, error)
+	ContainersPrune(pruneFilters filters.Args, outStream io.Writer) error
 }
 
 // monitorBackend includes functions to implement to provide containers monitoring functionality.
 type monitorBackend interface {
-	ContainerChanges(name string) ([]archive.Change, error)
+	ContainerChanges(name string, fn func(archive.Change) error) error
 	ContainerInspect(name string, size bool, version string) (interface{}, error)
 	ContainerLogs(ctx context.Context, name string, config *backend.ContainerLogsConfig, started chan struct{}) error
 	ContainerStats(ctx context.Context, name string, config *backend.ContainerStatsConfig) error
@@ -41,10 +41,15 @@ func (r *containerRouter) initRoutes() {
 		router.Cancellable(router.NewGetRoute("/containers/{name:.*}/logs", r.getContainersLogs)),
 		router.Cancellable(router.NewGetRoute("/containers/{name:.*}/stats", r.getContainersStats)),
 		router.NewGetRoute("/containers/{name:.*}/attach/ws", r.wsContainersAttach),
+		router.NewGetRoute("/containers/{name:.*}/execs", r.getContainerExecHistory),
 		router.NewGetRoute("/exec/{id:.*}/json", r.getExecByID),
 		router.NewGetRoute("/containers/{name:.*}/archive", r.getContainersArchive),
 		// POST
 		router.NewPostRoute("/containers/create", r.postContainersCreate),
+		router.NewPostRoute("/containers/prune", r.postContainersPrune),
+		router.NewPostRoute("/containers/kill", r.postContainersBatch("kill")),
+		router.NewPostRoute("/containers/stop", r.postContainersBatch("stop")),
+		router.NewPostRoute("/containers/restart", r.postContainersBatch("restart")),
 		router.NewPostRoute("/containers/{name:.*}/kill", r.postContainersKill),
 		router.NewPostRoute("/containers/{name:.*}/pause", r.postContainersPause),
 		router.NewPostRoute("/containers/{name:.*}/unpause", r.postContainersUnpause),
@@ -55,14 +60,19 @@ func (r *containerRouter) initRoutes() {
 		router.NewPostRoute("/containers/{name:.*}/resize", r.postContainersResize),
 		router.NewPostRoute("/containers/{name:.*}/attach", r.postContainersAttach),
 		router.NewPostRoute("/containers/{name:.*}/copy", r.postContainersCopy),
+		router.NewPostRoute("/containers/{name:.*}/export", r.postContainersExport),
+		router.NewPostRoute("/containers/{name:.*}/snapshots", r.postContainerSnapshots),
 		router.NewPostRoute("/containers/{name:.*}/exec", r.postContainerExecCreate),
 		router.NewPostRoute("/exec/{name:.*}/start", r.postContainerExecStart),
 		router.NewPostRoute("/exec/{name:.*}/resize", r.postContainerExecResize),
 		router.NewPostRoute("/containers/{name:.*}/rename", r.postContainerRename),
+		router.NewPostRoute("/containers/{name:.*}/refresh", r.postContainerRefresh),
+		router.NewPostRoute("/containers/{name:.*}/clone", r.postContainerClone),
 		router.NewPostRoute("/containers/{name:.*}/update", r.postContainerUpdate),
 		// PUT
 		router.NewPutRoute("/containers/{name:.*}/archive", r.putContainersArchive),
 		// DELETE
+		router.NewDeleteRoute("/containers/{name:.*}/snapshots/{id:.*}", r.deleteContainerSnapshot),
 		router.NewDeleteRoute("/containers/{name:.*}", r.deleteContainers),
 	}
 }
@@ -1,9 +1,11 @@
 package container
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"strconv"
 	"strings"
@@ -13,8 +15,10 @@ import (
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/api/server/httputils"
 	"github.com/docker/docker/api/types/backend"
+	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/ioutils"
 	"github.com/docker/docker/pkg/signal"
+	"github.com/docker/docker/pkg/streamformatter"
 	"github.com/docker/engine-api/types"
 	"github.com/docker/engine-api/types/container"
 	"github.com/docker/engine-api/types/filters"
@@ -31,6 +35,10 @@ func (s *containerRouter) getContainersJSON(ctx context.Context, w http.Response
 	if err != nil {
 		return err
 	}
+	// the users only see their containers in tenancy mode
+	if tenant := httputils.TenantFromContext(ctx); tenant != "" {
+		filter.Add("label", backend.OwnerLabel+"="+tenant)
+	}
 
 	config := &types.ContainerListOptions{
 		All:    httputils.BoolValue(r, "all"),
@@ -90,6 +98,21 @@ func (s *containerRouter) getContainersLogs(ctx context.Context, w http.Response
 		return fmt.Errorf("Bad parameters: you must choose at least one stream")
 	}
 
+	// the stream is closed once idleTimeout elapsed without any log
+	var (
+		outStream io.Writer = w
+		watcher   *ioutils.IdleWatcher
+	)
+	if idleTimeout := httputils.StreamIdleTimeoutFromContext(ctx); idleTimeout > 0 {
+		w.Header().Set("Trailer", httputils.StreamEndTrailer)
+		var cancel func()
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+		watcher = ioutils.NewIdleWatcher(idleTimeout, cancel)
+		defer watcher.Stop()
+		outStream = watcher.Writer(w)
+	}
+
 	containerName := vars["name"]
 	logsConfig := &backend.ContainerLogsConfig{
 		ContainerLogsOptions: types.ContainerLogsOptions{
@@ -101,7 +124,7 @@ func (s *containerRouter) getContainersLogs(ctx context.Context, w http.Response
 			ShowStderr: stderr,
 			Details:    httputils.BoolValue(r, "details"),
 		},
-		OutStream: w,
+		OutStream: outStream,
 	}
 
 	chStarted := make(chan struct{})
@@ -117,6 +140,9 @@ func (s *containerRouter) getContainersLogs(ctx context.Context, w http.Response
 		}
 	}
 
+	if watcher != nil && watcher.Idle() {
+		w.Header().Set(httputils.StreamEndTrailer, httputils.IdleStreamNotice(watcher.Timeout()))
+	}
 	return nil
 }
 
@@ -124,6 +150,16 @@ func (s *containerRouter) getContainersExport(ctx context.Context, w http.Respon
 	return s.backend.ContainerExport(vars["name"], w)
 }
 
+func (s *containerRouter) postContainersExport(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+	if httputils.BoolValue(r, "snapshot") {
+		return s.backend.ContainerExportSnapshot(vars["name"], w)
+	}
+	return s.backend.ContainerExport(vars["name"], w)
+}
+
 func (s *containerRouter) postContainersStart(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	// If contentLength is -1, we can assumed chunked encoding
 	// or more technically that the length is unknown
@@ -145,9 +181,13 @@ func (s *containerRouter) postContainersStart(ctx context.Context, w http.Respon
 		hostConfig = c
 	}
 
-	if err := s.backend.ContainerStart(vars["name"], hostConfig); err != nil {
+	csr, err := s.backend.ContainerStart(ctx, vars["name"], hostConfig)
+	if err != nil {
 		return err
 	}
+	if len(csr.WarningDetails) > 0 && !versions.LessThan(httputils.VersionFromContext(ctx), "1.24") {
+		return httputils.WriteJSON(w, http.StatusOK, csr)
+	}
 	w.WriteHeader(http.StatusNoContent)
 	return nil
 }
@@ -159,7 +199,13 @@ func (s *containerRouter) postContainersStop(ctx context.Context, w http.Respons
 
 	seconds, _ := strconv.Atoi(r.Form.Get("t"))
 
-	if err := s.backend.ContainerStop(vars["name"], seconds); err != nil {
+	if httputils.BoolValue(r, "progress") {
+		return writeStopProgress(w, func(output io.Writer) error {
+			return s.backend.ContainerStop(vars["name"], seconds, output)
+		})
+	}
+
+	if err := s.backend.ContainerStop(vars["name"], seconds, nil); err != nil {
 		return err
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -167,6 +213,24 @@ func (s *containerRouter) postContainersStop(ctx context.Context, w http.Respons
 	return nil
 }
 
+// writeStopProgress runs the stop or restart run, streaming the JSON
+// messages of its progress to w. The errors once the stream has started
+// are written to it.
+func writeStopProgress(w http.ResponseWriter, run func(output io.Writer) error) error {
+	output := ioutils.NewWriteFlusher(w)
+	defer output.Close()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := run(output); err != nil {
+		if !output.Flushed() {
+			return err
+		}
+		output.Write(streamformatter.NewJSONStreamFormatter().FormatError(err))
+	}
+	return nil
+}
+
 type errContainerIsRunning interface {
 	ContainerIsRunning() bool
 }
@@ -213,7 +277,13 @@ func (s *containerRouter) postContainersRestart(ctx context.Context, w http.Resp
 
 	timeout, _ := strconv.Atoi(r.Form.Get("t"))
 
-	if err := s.backend.ContainerRestart(vars["name"], timeout); err != nil {
+	if httputils.BoolValue(r, "progress") {
+		return writeStopProgress(w, func(output io.Writer) error {
+			return s.backend.ContainerRestart(vars["name"], timeout, output)
+		})
+	}
+
+	if err := s.backend.ContainerRestart(vars["name"], timeout, nil); err != nil {
 		return err
 	}
 
@@ -262,12 +332,62 @@ func (s *containerRouter) postContainersWait(ctx context.Context, w http.Respons
 }
 
 func (s *containerRouter) getContainersChanges(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
-	changes, err := s.backend.ContainerChanges(vars["name"])
+	// Changes are streamed as a JSON array as they are found, rather than
+	// collected first, so that the response for a container with millions
+	// of changes does not have to be held in memory.
+	legacy := versions.LessThan(httputils.VersionFromContext(ctx), "1.24")
+	enc := json.NewEncoder(w)
+	started := false
+	write := func(v interface{}) error {
+		sep := ","
+		if !started {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			sep = "["
+			started = true
+		}
+		if _, err := io.WriteString(w, sep); err != nil {
+			return err
+		}
+		return enc.Encode(v)
+	}
+
+	err := s.backend.ContainerChanges(vars["name"], func(change archive.Change) error {
+		if !legacy {
+			return write(change)
+		}
+		// Older clients only know about paths and kinds, so a rename is
+		// reported as the removal of the old path and the addition of
+		// the new one.
+		if change.Kind == archive.ChangeRename {
+			if err := write(legacyChange{Path: change.OldPath, Kind: archive.ChangeDelete}); err != nil {
+				return err
+			}
+			change.Kind = archive.ChangeAdd
+		}
+		return write(legacyChange{Path: change.Path, Kind: change.Kind})
+	})
 	if err != nil {
-		return err
+		if !started {
+			return err
+		}
+		// The status has already been sent; the client sees a
+		// truncated, invalid response.
+		logrus.Errorf("Error streaming changes of container %s: %v", vars["name"], err)
+		return nil
+	}
+	if !started {
+		return httputils.WriteJSON(w, http.StatusOK, []archive.Change{})
 	}
+	_, err = io.WriteString(w, "]")
+	return err
+}
 
-	return httputils.WriteJSON(w, http.StatusOK, changes)
+// legacyChange is a filesystem change as reported by API versions before
+// 1.24.
+type legacyChange struct {
+	Path string
+	Kind archive.ChangeType
 }
 
 func (s *containerRouter) getContainersTop(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
@@ -297,6 +417,73 @@ func (s *containerRouter) postContainerRename(ctx context.Context, w http.Respon
 	return nil
 }
 
+func (s *containerRouter) postContainerRefresh(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	seconds, _ := strconv.Atoi(r.Form.Get("t"))
+	ccr, err := s.backend.ContainerRefresh(vars["name"], &types.ContainerRefreshConfig{
+		Image:       r.Form.Get("image"),
+		StopTimeout: seconds,
+	})
+	if err != nil {
+		return err
+	}
+
+	return httputils.WriteJSON(w, http.StatusCreated, ccr)
+}
+
+func (s *containerRouter) postContainerClone(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	ccr, err := s.backend.ContainerClone(vars["name"], &types.ContainerCloneConfig{
+		Name:        r.Form.Get("name"),
+		CopyLayer:   httputils.BoolValue(r, "layer"),
+		CopyVolumes: httputils.BoolValue(r, "volumes"),
+	})
+	if err != nil {
+		return err
+	}
+
+	return httputils.WriteJSON(w, http.StatusCreated, ccr)
+}
+
+func (s *containerRouter) postContainerSnapshots(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	// the quiesce and thaw commands are optional
+	config := &types.ContainerSnapshotConfig{}
+	if r.Body != nil && (r.ContentLength > 0 || r.ContentLength == -1) {
+		if err := httputils.CheckForJSON(r); err != nil {
+			return err
+		}
+		if err := json.NewDecoder(r.Body).Decode(config); err != nil {
+			return err
+		}
+	}
+
+	snapshot, err := s.backend.ContainerSnapshotCreate(ctx, vars["name"], config)
+	if err != nil {
+		return err
+	}
+
+	return httputils.WriteJSON(w, http.StatusCreated, snapshot)
+}
+
+func (s *containerRouter) deleteContainerSnapshot(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := s.backend.ContainerSnapshotRemove(vars["name"], vars["id"]); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
 func (s *containerRouter) postContainerUpdate(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if err := httputils.ParseForm(r); err != nil {
 		return err
@@ -342,20 +529,44 @@ func (s *containerRouter) postContainersCreate(ctx context.Context, w http.Respo
 	if err != nil {
 		return err
 	}
+	if tenant := httputils.TenantFromContext(ctx); tenant != "" && config != nil {
+		if config.Labels == nil {
+			config.Labels = make(map[string]string)
+		}
+		config.Labels[backend.OwnerLabel] = tenant
+	}
 	version := httputils.VersionFromContext(ctx)
 	adjustCPUShares := versions.LessThan(version, "1.19")
 
+	authConfig := &types.AuthConfig{}
+	if authEncoded := r.Header.Get("X-Registry-Auth"); authEncoded != "" {
+		authJSON := base64.NewDecoder(base64.URLEncoding, strings.NewReader(authEncoded))
+		if err := json.NewDecoder(authJSON).Decode(authConfig); err != nil {
+			// as for a pull, it is not an error if no auth was given
+			authConfig = &types.AuthConfig{}
+		}
+	}
+
+	dryRun := httputils.BoolValue(r, "dry_run")
 	ccr, err := s.backend.ContainerCreate(types.ContainerCreateConfig{
 		Name:             name,
 		Config:           config,
 		HostConfig:       hostConfig,
 		NetworkingConfig: networkingConfig,
 		AdjustCPUShares:  adjustCPUShares,
+		PullPolicy:       r.Form.Get("pull"),
+		AuthConfig:       authConfig,
+		DryRun:           dryRun,
+		Requester:        httputils.RequesterFromRequest(r),
 	})
 	if err != nil {
 		return err
 	}
 
+	if dryRun {
+		// nothing was created
+		return httputils.WriteJSON(w, http.StatusOK, ccr)
+	}
 	return httputils.WriteJSON(w, http.StatusCreated, ccr)
 }
 
@@ -369,6 +580,7 @@ func (s *containerRouter) deleteContainers(ctx context.Context, w http.ResponseW
 		ForceRemove:  httputils.BoolValue(r, "force"),
 		RemoveVolume: httputils.BoolValue(r, "v"),
 		RemoveLink:   httputils.BoolValue(r, "link"),
+		Cleanup:      httputils.BoolValue(r, "cleanup"),
 	}
 
 	if err := s.backend.ContainerRm(name, config); err != nil {
@@ -384,6 +596,70 @@ func (s *containerRouter) deleteContainers(ctx context.Context, w http.ResponseW
 	return nil
 }
 
+func (s *containerRouter) postContainersPrune(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	pruneFilters, err := filters.FromParam(r.Form.Get("filters"))
+	if err != nil {
+		return err
+	}
+
+	output := ioutils.NewWriteFlusher(w)
+	defer output.Close()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := s.backend.ContainersPrune(pruneFilters, output); err != nil {
+		if !output.Flushed() {
+			return err
+		}
+		output.Write(streamformatter.NewJSONStreamFormatter().FormatError(err))
+	}
+	return nil
+}
+
+// postContainersBatch returns the handler running action on all the
+// containers matching the filters of the request.
+func (s *containerRouter) postContainersBatch(action string) httputils.APIFunc {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		if err := httputils.ParseForm(r); err != nil {
+			return err
+		}
+
+		batchFilters, err := filters.FromParam(r.Form.Get("filters"))
+		if err != nil {
+			return err
+		}
+		// the users only act on their containers in tenancy mode
+		if tenant := httputils.TenantFromContext(ctx); tenant != "" {
+			batchFilters.Add("label", backend.OwnerLabel+"="+tenant)
+		}
+
+		config := &backend.ContainersBatchConfig{Filters: batchFilters}
+		if sigStr := r.Form.Get("signal"); sigStr != "" {
+			sig, err := signal.ParseSignal(sigStr)
+			if err != nil {
+				return err
+			}
+			config.Signal = uint64(sig)
+		}
+		config.Timeout, _ = strconv.Atoi(r.Form.Get("t"))
+		if p := r.Form.Get("parallelism"); p != "" {
+			if config.Parallelism, err = strconv.Atoi(p); err != nil {
+				return err
+			}
+		}
+
+		report, err := s.backend.ContainersBatch(action, config)
+		if err != nil {
+			return err
+		}
+		return httputils.WriteJSON(w, http.StatusOK, report)
+	}
+}
+
 func (s *containerRouter) postContainersResize(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if err := httputils.ParseForm(r); err != nil {
 		return err
@@ -413,14 +689,16 @@ func (s *containerRouter) postContainersAttach(ctx context.Context, w http.Respo
 
 	hijacker, ok := w.(http.Hijacker)
 	if !ok {
-		return fmt.Errorf("error attaching to container %s, hijack connection missing", containerName)
+		return httputils.ErrHijackUnsupported
 	}
 
+	var conn net.Conn
 	setupStreams := func() (io.ReadCloser, io.Writer, io.Writer, error) {
-		conn, _, err := hijacker.Hijack()
+		c, _, err := hijacker.Hijack()
 		if err != nil {
 			return nil, nil, nil, err
 		}
+		conn = c
 
 		// set raw mode
 		conn.Write([]byte{})
@@ -449,6 +727,15 @@ func (s *containerRouter) postContainersAttach(ctx context.Context, w http.Respo
 		MuxStreams: true,
 	}
 
+	// the streams are closed once idleTimeout elapsed without any traffic
+	if idleTimeout := httputils.StreamIdleTimeoutFromContext(ctx); idleTimeout > 0 {
+		notice := httputils.IdleStreamNotice(idleTimeout)
+		attachConfig.IdleTimeout = idleTimeout
+		attachConfig.CloseIdle = func(muxed bool) {
+			httputils.CloseIdleStream(conn, notice, muxed)
+		}
+	}
+
 	if err = s.backend.ContainerAttach(containerName, attachConfig); err != nil {
 		logrus.Errorf("Handler for %s %s returned error: %v", r.Method, r.URL.Path, err)
 		// Remember to close stream if error happens
@@ -476,6 +763,7 @@ func (s *containerRouter) wsContainersAttach(ctx context.Context, w http.Respons
 
 	done := make(chan struct{})
 	started := make(chan struct{})
+	var framed *wsAttachConn
 
 	setupStreams := func() (io.ReadCloser, io.Writer, io.Writer, error) {
 		wsChan := make(chan *websocket.Conn)
@@ -484,14 +772,22 @@ func (s *containerRouter) wsContainersAttach(ctx context.Context, w http.Respons
 			<-done
 		}
 
-		srv := websocket.Server{Handler: h, Handshake: nil}
+		srv := websocket.Server{Handler: h, Handshake: wsSelectProtocol}
 		go func() {
 			close(started)
 			srv.ServeHTTP(w, r)
 		}()
 
 		conn := <-wsChan
-		return conn, conn, conn, nil
+		if len(conn.Config().Protocol) == 0 {
+			return conn, conn, conn, nil
+		}
+		resize := func(height, width int) error {
+			return s.backend.ContainerResize(containerName, height, width)
+		}
+		var stdin io.ReadCloser
+		framed, stdin = newWSAttachConn(conn, resize, done)
+		return stdin, framed.writer(wsStdout), framed.writer(wsStderr), nil
 	}
 
 	attachConfig := &backend.ContainerAttachConfig{
@@ -506,6 +802,9 @@ func (s *containerRouter) wsContainersAttach(ctx context.Context, w http.Respons
 	}
 
 	err = s.backend.ContainerAttach(containerName, attachConfig)
+	if err != nil && framed != nil {
+		framed.sendError(err)
+	}
 	close(done)
 	select {
 	case <-started:
@@ -9,6 +9,7 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/api/server/httputils"
+	"github.com/docker/docker/pkg/ioutils"
 	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/docker/engine-api/types"
 	"github.com/docker/engine-api/types/versions"
@@ -24,6 +25,15 @@ func (s *containerRouter) getExecByID(ctx context.Context, w http.ResponseWriter
 	return httputils.WriteJSON(w, http.StatusOK, eConfig)
 }
 
+func (s *containerRouter) getContainerExecHistory(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	history, err := s.backend.ContainerExecHistory(vars["name"])
+	if err != nil {
+		return err
+	}
+
+	return httputils.WriteJSON(w, http.StatusOK, history)
+}
+
 func (s *containerRouter) postContainerExecCreate(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if err := httputils.ParseForm(r); err != nil {
 		return err
@@ -41,6 +51,7 @@ func (s *containerRouter) postContainerExecCreate(ctx context.Context, w http.Re
 	if len(execConfig.Cmd) == 0 {
 		return fmt.Errorf("No exec command specified")
 	}
+	execConfig.Requester = httputils.RequesterFromRequest(r)
 
 	// Register an instance of Exec in container.
 	id, err := s.backend.ContainerExecCreate(name, execConfig)
@@ -97,6 +108,17 @@ func (s *containerRouter) postContainerExecStart(ctx context.Context, w http.Res
 			fmt.Fprintf(outStream, "HTTP/1.1 200 OK\r\nContent-Type: application/vnd.docker.raw-stream\r\n\r\n")
 		}
 
+		// the stream is closed once idleTimeout elapsed without any traffic
+		if idleTimeout := httputils.StreamIdleTimeoutFromContext(ctx); idleTimeout > 0 {
+			conn, notice := inStream, httputils.IdleStreamNotice(idleTimeout)
+			watcher := ioutils.NewIdleWatcher(idleTimeout, func() {
+				httputils.CloseIdleStream(conn, notice, !execStartCheck.Tty)
+			})
+			defer watcher.Stop()
+			inStream = ioutils.NewReadCloserWrapper(watcher.Reader(conn), conn.Close)
+			outStream = watcher.Writer(outStream)
+		}
+
 		stdin = inStream
 		stdout = outStream
 		if !execStartCheck.Tty {
@@ -106,7 +128,7 @@ func (s *containerRouter) postContainerExecStart(ctx context.Context, w http.Res
 	}
 
 	// Now run the user process in container.
-	if err := s.backend.ContainerExecStart(execName, stdin, stdout, stderr); err != nil {
+	if err := s.backend.ContainerExecStart(ctx, execName, stdin, stdout, stderr); err != nil {
 		if execStartCheck.Detach {
 			return err
 		}
@@ -0,0 +1,180 @@
+package container
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"golang.org/x/net/websocket"
+)
+
+// wsAttachProtocol is the WebSocket subprotocol of the attach endpoint with
+// binary framing. The clients not requesting it get the raw stream of the
+// container.
+const wsAttachProtocol = "docker.attach.v2"
+
+// The first byte of the binary frames of wsAttachProtocol is the type of
+// their payload. The stream types are the ones of pkg/stdcopy.
+const (
+	wsStdin   byte = 0
+	wsStdout  byte = 1
+	wsStderr  byte = 2
+	wsError   byte = 3
+	wsControl byte = 4
+)
+
+// wsPingInterval is the interval at which pings are sent to the clients of
+// wsAttachProtocol, so that idle connections are kept open by proxies and
+// the dead ones are detected.
+const wsPingInterval = 30 * time.Second
+
+// wsControlMessage is the JSON payload of the control frames. The clients
+// resize the TTY of the container with a "resize" message.
+type wsControlMessage struct {
+	Type   string
+	Height int `json:",omitempty"`
+	Width  int `json:",omitempty"`
+}
+
+// wsFrame is a WebSocket frame, sent and received with wsFrameCodec.
+type wsFrame struct {
+	payloadType byte
+	data        []byte
+}
+
+var wsFrameCodec = websocket.Codec{
+	Marshal: func(v interface{}) ([]byte, byte, error) {
+		f := v.(wsFrame)
+		return f.data, f.payloadType, nil
+	},
+	Unmarshal: func(data []byte, payloadType byte, v interface{}) error {
+		f := v.(*wsFrame)
+		f.payloadType = payloadType
+		f.data = data
+		return nil
+	},
+}
+
+// wsSelectProtocol is the handshake of the attach endpoint, selecting
+// wsAttachProtocol if the client requests it. The origin is not checked,
+// as most clients are not browsers.
+func wsSelectProtocol(config *websocket.Config, r *http.Request) error {
+	for _, protocol := range config.Protocol {
+		if protocol == wsAttachProtocol {
+			config.Protocol = []string{wsAttachProtocol}
+			return nil
+		}
+	}
+	config.Protocol = nil
+	return nil
+}
+
+// wsAttachConn frames the streams of an attach over a WebSocket connection
+// of wsAttachProtocol.
+type wsAttachConn struct {
+	conn   *websocket.Conn
+	stdin  *io.PipeWriter
+	resize func(height, width int) error
+}
+
+// newWSAttachConn returns the framed connection over conn and the stdin
+// stream it receives. The resize messages of the client are passed to
+// resize. It reads conn and pings the client until done is closed.
+func newWSAttachConn(conn *websocket.Conn, resize func(height, width int) error, done <-chan struct{}) (*wsAttachConn, io.ReadCloser) {
+	r, w := io.Pipe()
+	c := &wsAttachConn{
+		conn:   conn,
+		stdin:  w,
+		resize: resize,
+	}
+	go c.receive()
+	go c.keepalive(done)
+	return c, r
+}
+
+// writer returns the writer sending its writes as frames of stream.
+func (c *wsAttachConn) writer(stream byte) io.Writer {
+	return &wsStreamWriter{conn: c.conn, stream: stream}
+}
+
+// sendError sends err to the client in an error frame.
+func (c *wsAttachConn) sendError(err error) {
+	c.writer(wsError).Write([]byte(err.Error()))
+}
+
+// receive passes the stdin and the control frames of the client on, until
+// the connection is closed. Text frames are taken as stdin.
+func (c *wsAttachConn) receive() {
+	for {
+		var f wsFrame
+		if err := wsFrameCodec.Receive(c.conn, &f); err != nil {
+			c.stdin.CloseWithError(err)
+			return
+		}
+		if f.payloadType == websocket.TextFrame {
+			if _, err := c.stdin.Write(f.data); err != nil {
+				return
+			}
+			continue
+		}
+		if len(f.data) == 0 {
+			continue
+		}
+		switch f.data[0] {
+		case wsStdin:
+			if _, err := c.stdin.Write(f.data[1:]); err != nil {
+				return
+			}
+		case wsControl:
+			var msg wsControlMessage
+			if err := json.Unmarshal(f.data[1:], &msg); err != nil {
+				c.sendError(err)
+				continue
+			}
+			if msg.Type == "resize" {
+				if err := c.resize(msg.Height, msg.Width); err != nil {
+					c.sendError(err)
+				}
+			}
+		default:
+			logrus.Debugf("Ignoring websocket attach frame of type %d", f.data[0])
+		}
+	}
+}
+
+// keepalive pings the client every wsPingInterval until done is closed,
+// and closes the connection once a ping fails.
+func (c *wsAttachConn) keepalive(done <-chan struct{}) {
+	ticker := time.NewTicker(wsPingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := wsFrameCodec.Send(c.conn, wsFrame{payloadType: websocket.PingFrame}); err != nil {
+				logrus.Debugf("Closing websocket attach: %v", err)
+				c.conn.Close()
+				return
+			}
+		}
+	}
+}
+
+// wsStreamWriter sends its writes as binary frames of stream.
+type wsStreamWriter struct {
+	conn   *websocket.Conn
+	stream byte
+}
+
+func (w *wsStreamWriter) Write(p []byte) (int, error) {
+	data := make([]byte, len(p)+1)
+	data[0] = w.stream
+	copy(data[1:], p)
+	if err := wsFrameCodec.Send(w.conn, wsFrame{payloadType: websocket.BinaryFrame, data: data}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
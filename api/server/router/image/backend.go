@@ -28,16 +28,20 @@ type imageBackend interface {
 	Images(filterArgs string, filter string, all bool) ([]*types.Image, error)
 	LookupImage(name string) (*types.ImageInspect, error)
 	TagImage(imageName, repository, tag string) error
+	ClaimImage(refOrID, tenant string) error
+	CheckTenantReference(ref, tenant string) error
+	ImageOwners(refOrID string) ([]string, error)
 }
 
 type importExportBackend interface {
 	LoadImage(inTar io.ReadCloser, outStream io.Writer, quiet bool) error
-	ImportImage(src string, repository, tag string, msg string, inConfig io.ReadCloser, outStream io.Writer, changes []string) error
-	ExportImage(names []string, outStream io.Writer) error
+	ImportImage(src string, repository, tag string, msg string, platform string, inConfig io.ReadCloser, outStream io.Writer, changes []string) error
+	ExportImage(names []string, format string, outStream io.Writer) error
 }
 
 type registryBackend interface {
 	PullImage(ctx context.Context, image, tag string, metaHeaders map[string][]string, authConfig *types.AuthConfig, outStream io.Writer) error
 	PushImage(ctx context.Context, image, tag string, metaHeaders map[string][]string, authConfig *types.AuthConfig, outStream io.Writer) error
-	SearchRegistryForImages(ctx context.Context, filtersArgs string, term string, authConfig *types.AuthConfig, metaHeaders map[string][]string) (*registry.SearchResults, error)
+	SearchRegistryForImages(ctx context.Context, filtersArgs string, term string, limit int, authConfig *types.AuthConfig, metaHeaders map[string][]string) (*registry.SearchResults, error)
+	ImageRemoteTags(ctx context.Context, image string, withDigests bool, metaHeaders map[string][]string, authConfig *types.AuthConfig) ([]types.RemoteTag, error)
 }
@@ -10,14 +10,19 @@ import (
 
 	"github.com/docker/docker/api/server/httputils"
 	"github.com/docker/docker/api/types/backend"
+	"github.com/docker/docker/errors"
 	"github.com/docker/docker/pkg/ioutils"
 	"github.com/docker/docker/pkg/streamformatter"
 	"github.com/docker/engine-api/types"
 	"github.com/docker/engine-api/types/container"
+	"github.com/docker/engine-api/types/filters"
 	"github.com/docker/engine-api/types/versions"
 	"golang.org/x/net/context"
 )
 
+// maxSearchLimit is the maximum number of results of a search.
+const maxSearchLimit = 100
+
 func (s *imageRouter) postCommit(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if err := httputils.ParseForm(r); err != nil {
 		return err
@@ -46,6 +51,7 @@ func (s *imageRouter) postCommit(ctx context.Context, w http.ResponseWriter, r *
 	commitCfg := &backend.ContainerCommitConfig{
 		ContainerCommitConfig: types.ContainerCommitConfig{
 			Pause:        pause,
+			Snapshot:     httputils.BoolValue(r, "snapshot"),
 			Repo:         r.Form.Get("repo"),
 			Tag:          r.Form.Get("tag"),
 			Author:       r.Form.Get("author"),
@@ -53,13 +59,26 @@ func (s *imageRouter) postCommit(ctx context.Context, w http.ResponseWriter, r *
 			Config:       c,
 			MergeConfigs: true,
 		},
-		Changes: r.Form["changes"],
+		Changes:      r.Form["changes"],
+		RecordOrigin: true,
+	}
+
+	tenant := httputils.TenantFromContext(ctx)
+	if tenant != "" && commitCfg.Repo != "" {
+		if err := s.backend.CheckTenantReference(imageReference(commitCfg.Repo, commitCfg.Tag), tenant); err != nil {
+			return err
+		}
 	}
 
 	imgID, err := s.backend.Commit(cname, commitCfg)
 	if err != nil {
 		return err
 	}
+	if tenant != "" {
+		if err := s.backend.ClaimImage(imgID, tenant); err != nil {
+			return err
+		}
+	}
 
 	return httputils.WriteJSON(w, http.StatusCreated, &types.ContainerCommitResponse{
 		ID: string(imgID),
@@ -77,6 +96,7 @@ func (s *imageRouter) postImagesCreate(ctx context.Context, w http.ResponseWrite
 		repo    = r.Form.Get("repo")
 		tag     = r.Form.Get("tag")
 		message = r.Form.Get("message")
+		tenant  = httputils.TenantFromContext(ctx)
 		err     error
 		output  = ioutils.NewWriteFlusher(w)
 	)
@@ -104,12 +124,27 @@ func (s *imageRouter) postImagesCreate(ctx context.Context, w http.ResponseWrite
 		}
 
 		err = s.backend.PullImage(ctx, image, tag, metaHeaders, authConfig, output)
+		if err == nil && tenant != "" {
+			// all the tags pulled are claimed by the latest one
+			err = s.backend.ClaimImage(imageReference(strings.TrimSuffix(image, ":"), tag), tenant)
+		}
 	} else { //import
+		if tenant != "" {
+			if repo == "" {
+				return fmt.Errorf("the images imported in tenancy mode must be tagged in a repository")
+			}
+			if err := s.backend.CheckTenantReference(imageReference(repo, tag), tenant); err != nil {
+				return err
+			}
+		}
 		src := r.Form.Get("fromSrc")
 		// 'err' MUST NOT be defined within this block, we need any error
 		// generated from the download to be available to the output
 		// stream processing below
-		err = s.backend.ImportImage(src, repo, tag, message, r.Body, output, r.Form["changes"])
+		err = s.backend.ImportImage(src, repo, tag, message, r.Form.Get("platform"), r.Body, output, r.Form["changes"])
+		if err == nil && tenant != "" {
+			err = s.backend.ClaimImage(imageReference(repo, tag), tenant)
+		}
 	}
 	if err != nil {
 		if !output.Flushed() {
@@ -183,7 +218,7 @@ func (s *imageRouter) getImagesGet(ctx context.Context, w http.ResponseWriter, r
 		names = r.Form["names"]
 	}
 
-	if err := s.backend.ExportImage(names, output); err != nil {
+	if err := s.backend.ExportImage(names, r.Form.Get("format"), output); err != nil {
 		if !output.Flushed() {
 			return err
 		}
@@ -226,6 +261,17 @@ func (s *imageRouter) deleteImages(ctx context.Context, w http.ResponseWriter, r
 	force := httputils.BoolValue(r, "force")
 	prune := !httputils.BoolValue(r, "noprune")
 
+	// the images shared by several users stay for the others
+	if tenant := httputils.TenantFromContext(ctx); tenant != "" {
+		owners, err := s.backend.ImageOwners(name)
+		if err != nil {
+			return err
+		}
+		if len(owners) > 1 {
+			return errors.NewRequestConflictError(fmt.Errorf("conflict: unable to delete %s - image is owned by other users", name))
+		}
+	}
+
 	list, err := s.backend.ImageDelete(name, force, prune)
 	if err != nil {
 		return err
@@ -248,8 +294,21 @@ func (s *imageRouter) getImagesJSON(ctx context.Context, w http.ResponseWriter,
 		return err
 	}
 
+	imageFilters := r.Form.Get("filters")
+	// the users only see their images in tenancy mode
+	if tenant := httputils.TenantFromContext(ctx); tenant != "" {
+		args, err := filters.FromParam(imageFilters)
+		if err != nil {
+			return err
+		}
+		args.Add("owner", tenant)
+		if imageFilters, err = filters.ToParam(args); err != nil {
+			return err
+		}
+	}
+
 	// FIXME: The filter parameter could just be a match filter
-	images, err := s.backend.Images(r.Form.Get("filters"), r.Form.Get("filter"), httputils.BoolValue(r, "all"))
+	images, err := s.backend.Images(imageFilters, r.Form.Get("filter"), httputils.BoolValue(r, "all"))
 	if err != nil {
 		return err
 	}
@@ -271,7 +330,13 @@ func (s *imageRouter) postImagesTag(ctx context.Context, w http.ResponseWriter,
 	if err := httputils.ParseForm(r); err != nil {
 		return err
 	}
-	if err := s.backend.TagImage(vars["name"], r.Form.Get("repo"), r.Form.Get("tag")); err != nil {
+	repo, tag := r.Form.Get("repo"), r.Form.Get("tag")
+	if tenant := httputils.TenantFromContext(ctx); tenant != "" {
+		if err := s.backend.CheckTenantReference(imageReference(repo, tag), tenant); err != nil {
+			return err
+		}
+	}
+	if err := s.backend.TagImage(vars["name"], repo, tag); err != nil {
 		return err
 	}
 	w.WriteHeader(http.StatusCreated)
@@ -301,9 +366,57 @@ func (s *imageRouter) getImagesSearch(ctx context.Context, w http.ResponseWriter
 			headers[k] = v
 		}
 	}
-	query, err := s.backend.SearchRegistryForImages(ctx, r.Form.Get("filters"), r.Form.Get("term"), config, headers)
+	limit, err := httputils.Int64ValueOrDefault(r, "limit", 0)
+	if err != nil {
+		return err
+	}
+	if limit < 0 || limit > maxSearchLimit {
+		return fmt.Errorf("Limit %d is outside the range of [1, %d]", limit, maxSearchLimit)
+	}
+	query, err := s.backend.SearchRegistryForImages(ctx, r.Form.Get("filters"), r.Form.Get("term"), int(limit), config, headers)
 	if err != nil {
 		return err
 	}
 	return httputils.WriteJSON(w, http.StatusOK, query.Results)
 }
+
+func (s *imageRouter) getImagesRemoteTags(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	metaHeaders := map[string][]string{}
+	for k, v := range r.Header {
+		if strings.HasPrefix(k, "X-Meta-") {
+			metaHeaders[k] = v
+		}
+	}
+	authEncoded := r.Header.Get("X-Registry-Auth")
+	authConfig := &types.AuthConfig{}
+	if authEncoded != "" {
+		authJSON := base64.NewDecoder(base64.URLEncoding, strings.NewReader(authEncoded))
+		if err := json.NewDecoder(authJSON).Decode(authConfig); err != nil {
+			// as for a pull, it is not an error if no auth was given
+			authConfig = &types.AuthConfig{}
+		}
+	}
+
+	tags, err := s.backend.ImageRemoteTags(ctx, vars["name"], httputils.BoolValue(r, "digests"), metaHeaders, authConfig)
+	if err != nil {
+		return err
+	}
+	return httputils.WriteJSON(w, http.StatusOK, tags)
+}
+
+// imageReference returns the reference of the image name with tag, which
+// may be a digest.
+func imageReference(name, tag string) string {
+	switch {
+	case tag == "":
+		return name
+	case strings.Contains(tag, ":"):
+		return name + "@" + tag
+	default:
+		return name + ":" + tag
+	}
+}
@@ -0,0 +1,12 @@
+package job
+
+import "github.com/docker/engine-api/types"
+
+// Backend is the methods that need to be implemented to provide
+// the scheduled jobs functionality
+type Backend interface {
+	Jobs() []*types.Job
+	JobInspect(name string) (*types.Job, error)
+	JobCreate(name string, spec types.JobSpec) (*types.Job, error)
+	JobRm(name string) error
+}
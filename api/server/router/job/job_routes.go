@@ -0,0 +1,82 @@
+package job
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/docker/docker/api/server/httputils"
+	"github.com/docker/docker/api/types/backend"
+	"github.com/docker/engine-api/types"
+	"golang.org/x/net/context"
+)
+
+func (j *jobRouter) getJobsList(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	jobs := j.backend.Jobs()
+	// the users only see their jobs in tenancy mode
+	if tenant := httputils.TenantFromContext(ctx); tenant != "" {
+		owned := []*types.Job{}
+		for _, job := range jobs {
+			if job.Spec.Config.Labels[backend.OwnerLabel] == tenant {
+				owned = append(owned, job)
+			}
+		}
+		jobs = owned
+	}
+	return httputils.WriteJSON(w, http.StatusOK, jobs)
+}
+
+func (j *jobRouter) getJobByName(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	job, err := j.backend.JobInspect(vars["name"])
+	if err != nil {
+		return err
+	}
+	return httputils.WriteJSON(w, http.StatusOK, job)
+}
+
+func (j *jobRouter) postJobsCreate(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	if err := httputils.CheckForJSON(r); err != nil {
+		return err
+	}
+
+	var req types.JobCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return err
+	}
+
+	// the containers of the jobs belong to their owner
+	if tenant := httputils.TenantFromContext(ctx); tenant != "" && req.Config != nil {
+		if req.Config.Labels == nil {
+			req.Config.Labels = make(map[string]string)
+		}
+		req.Config.Labels[backend.OwnerLabel] = tenant
+	}
+
+	job, err := j.backend.JobCreate(req.Name, req.JobSpec)
+	if err != nil {
+		return err
+	}
+	return httputils.WriteJSON(w, http.StatusCreated, job)
+}
+
+func (j *jobRouter) deleteJobs(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+	if err := j.backend.JobRm(vars["name"]); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
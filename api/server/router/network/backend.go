@@ -18,4 +18,8 @@ type Backend interface {
 	ConnectContainerToNetwork(containerName, networkName string, endpointConfig *network.EndpointSettings) error
 	DisconnectContainerFromNetwork(containerName string, network libnetwork.Network, force bool) error
 	DeleteNetwork(name string) error
+	NetworksPrune(pruneFilters filters.Args, dryRun bool) (*types.NetworksPruneReport, error)
+	PortAllocations() []types.PortAllocation
+	CheckTenant(kind, name, tenant string) error
+	DiagnoseNetwork(networkName, containerName string) (*types.NetworkDiagnostics, error)
 }
@@ -26,9 +26,12 @@ func (r *networkRouter) initRoutes() {
 	r.routes = []router.Route{
 		// GET
 		router.NewGetRoute("/networks", r.getNetworksList),
+		router.NewGetRoute("/networks/{id:.*}/diagnose", r.getNetworkDiagnostics),
 		router.NewGetRoute("/networks/{id:.*}", r.getNetwork),
+		router.NewGetRoute("/ports", r.getPortsList),
 		// POST
 		router.NewPostRoute("/networks/create", r.postNetworkCreate),
+		router.NewPostRoute("/networks/prune", r.postNetworksPrune),
 		router.NewPostRoute("/networks/{id:.*}/connect", r.postNetworkConnect),
 		router.NewPostRoute("/networks/{id:.*}/disconnect", r.postNetworkDisconnect),
 		// DELETE
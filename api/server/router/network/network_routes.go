@@ -7,6 +7,7 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/docker/docker/api/server/httputils"
+	"github.com/docker/docker/api/types/backend"
 	"github.com/docker/engine-api/types"
 	"github.com/docker/engine-api/types/filters"
 	"github.com/docker/engine-api/types/network"
@@ -23,6 +24,10 @@ func (n *networkRouter) getNetworksList(ctx context.Context, w http.ResponseWrit
 	if err != nil {
 		return err
 	}
+	// the users only see their networks in tenancy mode
+	if tenant := httputils.TenantFromContext(ctx); tenant != "" {
+		netFilters.Add("label", backend.OwnerLabel+"="+tenant)
+	}
 
 	list := []*types.NetworkResource{}
 
@@ -32,7 +37,7 @@ func (n *networkRouter) getNetworksList(ctx context.Context, w http.ResponseWrit
 	}
 
 	for _, nw := range nwList {
-		list = append(list, buildNetworkResource(nw))
+		list = append(list, n.filterTenantContainers(ctx, buildNetworkResource(nw)))
 	}
 
 	return httputils.WriteJSON(w, http.StatusOK, list)
@@ -47,7 +52,39 @@ func (n *networkRouter) getNetwork(ctx context.Context, w http.ResponseWriter, r
 	if err != nil {
 		return err
 	}
-	return httputils.WriteJSON(w, http.StatusOK, buildNetworkResource(nw))
+	return httputils.WriteJSON(w, http.StatusOK, n.filterTenantContainers(ctx, buildNetworkResource(nw)))
+}
+
+// filterTenantContainers removes from the network resource r the containers
+// the user of the request doesn't own in tenancy mode, the predefined
+// networks being shared by all the users.
+func (n *networkRouter) filterTenantContainers(ctx context.Context, r *types.NetworkResource) *types.NetworkResource {
+	tenant := httputils.TenantFromContext(ctx)
+	if tenant == "" {
+		return r
+	}
+	for id := range r.Containers {
+		if n.backend.CheckTenant("containers", id, tenant) != nil {
+			delete(r.Containers, id)
+		}
+	}
+	return r
+}
+
+func (n *networkRouter) getNetworkDiagnostics(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	report, err := n.backend.DiagnoseNetwork(vars["id"], r.Form.Get("container"))
+	if err != nil {
+		return err
+	}
+	return httputils.WriteJSON(w, http.StatusOK, report)
+}
+
+func (n *networkRouter) getPortsList(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	return httputils.WriteJSON(w, http.StatusOK, n.backend.PortAllocations())
 }
 
 func (n *networkRouter) postNetworkCreate(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
@@ -64,6 +101,12 @@ func (n *networkRouter) postNetworkCreate(ctx context.Context, w http.ResponseWr
 	if err := json.NewDecoder(r.Body).Decode(&create); err != nil {
 		return err
 	}
+	if tenant := httputils.TenantFromContext(ctx); tenant != "" {
+		if create.Labels == nil {
+			create.Labels = make(map[string]string)
+		}
+		create.Labels[backend.OwnerLabel] = tenant
+	}
 
 	nw, err := n.backend.CreateNetwork(create)
 	if err != nil {
@@ -86,6 +129,11 @@ func (n *networkRouter) postNetworkConnect(ctx context.Context, w http.ResponseW
 	if err := json.NewDecoder(r.Body).Decode(&connect); err != nil {
 		return err
 	}
+	if tenant := httputils.TenantFromContext(ctx); tenant != "" {
+		if err := n.backend.CheckTenant("containers", connect.Container, tenant); err != nil {
+			return err
+		}
+	}
 
 	nw, err := n.backend.FindNetwork(vars["id"])
 	if err != nil {
@@ -108,6 +156,11 @@ func (n *networkRouter) postNetworkDisconnect(ctx context.Context, w http.Respon
 	if err := json.NewDecoder(r.Body).Decode(&disconnect); err != nil {
 		return err
 	}
+	if tenant := httputils.TenantFromContext(ctx); tenant != "" {
+		if err := n.backend.CheckTenant("containers", disconnect.Container, tenant); err != nil {
+			return err
+		}
+	}
 
 	nw, err := n.backend.FindNetwork(vars["id"])
 	if err != nil {
@@ -128,6 +181,23 @@ func (n *networkRouter) deleteNetwork(ctx context.Context, w http.ResponseWriter
 	return nil
 }
 
+func (n *networkRouter) postNetworksPrune(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	pruneFilters, err := filters.FromParam(r.Form.Get("filters"))
+	if err != nil {
+		return err
+	}
+
+	report, err := n.backend.NetworksPrune(pruneFilters, httputils.BoolValue(r, "dryrun"))
+	if err != nil {
+		return err
+	}
+	return httputils.WriteJSON(w, http.StatusOK, report)
+}
+
 func buildNetworkResource(nw libnetwork.Network) *types.NetworkResource {
 	r := &types.NetworkResource{}
 	if nw == nil {
@@ -145,6 +215,12 @@ func buildNetworkResource(nw libnetwork.Network) *types.NetworkResource {
 	r.Containers = make(map[string]types.EndpointResource)
 	buildIpamResources(r, info)
 	r.Internal = info.Internal()
+	if isolated, rules := info.Isolation(); isolated {
+		r.Isolated = true
+		for _, rule := range rules {
+			r.IsolationAllow = append(r.IsolationAllow, rule.String())
+		}
+	}
 	r.Labels = info.Labels()
 
 	epl := nw.Endpoints()
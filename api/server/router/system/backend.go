@@ -1,6 +1,7 @@
 package system
 
 import (
+	"io"
 	"time"
 
 	"github.com/docker/engine-api/types"
@@ -13,7 +14,10 @@ import (
 // system specific functionality.
 type Backend interface {
 	SystemInfo() (*types.Info, error)
+	SystemBundle(w io.Writer) error
 	SystemVersion() types.Version
+	SystemHealth() *types.DaemonHealth
+	SystemReadiness() *types.DaemonReadiness
 	SubscribeToEvents(since, until time.Time, ef filters.Args) ([]events.Message, chan interface{})
 	UnsubscribeFromEvents(chan interface{})
 	AuthenticateToRegistry(ctx context.Context, authConfig *types.AuthConfig) (string, string, error)
@@ -18,8 +18,11 @@ func NewRouter(b Backend) router.Router {
 	r.routes = []router.Route{
 		router.NewOptionsRoute("/{anyroute:.*}", optionsHandler),
 		router.NewGetRoute("/_ping", pingHandler),
+		router.NewGetRoute("/healthz", r.getHealthz),
+		router.NewGetRoute("/readyz", r.getReadyz),
 		router.Cancellable(router.NewGetRoute("/events", r.getEvents)),
 		router.NewGetRoute("/info", r.getInfo),
+		router.NewGetRoute("/info/bundle", r.getInfoBundle),
 		router.NewGetRoute("/version", r.getVersion),
 		router.NewPostRoute("/auth", r.postAuth),
 	}
@@ -3,12 +3,14 @@ package system
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/api"
 	"github.com/docker/docker/api/server/httputils"
+	"github.com/docker/docker/api/types/backend"
 	"github.com/docker/docker/errors"
 	"github.com/docker/docker/pkg/ioutils"
 	"github.com/docker/engine-api/types"
@@ -28,6 +30,28 @@ func pingHandler(ctx context.Context, w http.ResponseWriter, r *http.Request, va
 	return err
 }
 
+// getHealthz reports the health of the subsystems of the daemon, with a
+// 503 status if one of them is unhealthy.
+func (s *systemRouter) getHealthz(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	health := s.backend.SystemHealth()
+	status := http.StatusOK
+	if !health.Healthy {
+		status = http.StatusServiceUnavailable
+	}
+	return httputils.WriteJSON(w, status, health)
+}
+
+// getReadyz reports whether the daemon is ready, with a 503 status if it
+// is not.
+func (s *systemRouter) getReadyz(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	readiness := s.backend.SystemReadiness()
+	status := http.StatusOK
+	if !readiness.Ready {
+		status = http.StatusServiceUnavailable
+	}
+	return httputils.WriteJSON(w, status, readiness)
+}
+
 func (s *systemRouter) getInfo(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	info, err := s.backend.SystemInfo()
 	if err != nil {
@@ -37,6 +61,11 @@ func (s *systemRouter) getInfo(ctx context.Context, w http.ResponseWriter, r *ht
 	return httputils.WriteJSON(w, http.StatusOK, info)
 }
 
+func (s *systemRouter) getInfoBundle(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	w.Header().Set("Content-Type", "application/x-tar")
+	return s.backend.SystemBundle(w)
+}
+
 func (s *systemRouter) getVersion(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	info := s.backend.SystemVersion()
 	info.APIVersion = api.DefaultVersion
@@ -81,13 +110,33 @@ func (s *systemRouter) getEvents(ctx context.Context, w http.ResponseWriter, r *
 	if err != nil {
 		return err
 	}
+	// the users only receive the events of their containers in tenancy mode
+	if tenant := httputils.TenantFromContext(ctx); tenant != "" {
+		ef.Add("label", backend.OwnerLabel+"="+tenant)
+	}
+
+	// the stream is closed once idleTimeout elapsed without any event
+	idleTimeout := httputils.StreamIdleTimeoutFromContext(ctx)
+	if idleTimeout > 0 {
+		w.Header().Set("Trailer", httputils.StreamEndTrailer)
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	output := ioutils.NewWriteFlusher(w)
 	defer output.Close()
 	output.Flush()
 
-	enc := json.NewEncoder(output)
+	var (
+		stream io.Writer = output
+		idle   chan struct{}
+	)
+	if idleTimeout > 0 {
+		idle = make(chan struct{})
+		watcher := ioutils.NewIdleWatcher(idleTimeout, func() { close(idle) })
+		defer watcher.Stop()
+		stream = watcher.Writer(output)
+	}
+	enc := json.NewEncoder(stream)
 
 	buffered, l := s.backend.SubscribeToEvents(since, until, ef)
 	defer s.backend.UnsubscribeFromEvents(l)
@@ -115,6 +164,9 @@ func (s *systemRouter) getEvents(ctx context.Context, w http.ResponseWriter, r *
 			}
 		case <-timeout:
 			return nil
+		case <-idle:
+			w.Header().Set(httputils.StreamEndTrailer, httputils.IdleStreamNotice(idleTimeout))
+			return nil
 		case <-ctx.Done():
 			logrus.Debug("Client context cancelled, stop sending events")
 			return nil
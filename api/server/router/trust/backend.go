@@ -0,0 +1,19 @@
+package trust
+
+import (
+	"io"
+
+	"github.com/docker/engine-api/types"
+	"golang.org/x/net/context"
+)
+
+// Backend is all the methods that need to be implemented
+// to provide content trust specific functionality.
+type Backend interface {
+	TrustKeys() ([]types.TrustKey, error)
+	TrustKeyCreate(req types.TrustKeyCreateRequest) (types.TrustKey, error)
+	TrustKeysBackup() (io.ReadCloser, error)
+	TrustSigners(ctx context.Context, repository, server string, metaHeaders map[string][]string, authConfig *types.AuthConfig) (types.TrustRepository, error)
+	TrustKeyRotate(ctx context.Context, repository, server string, req types.TrustRotateRequest, metaHeaders map[string][]string, authConfig *types.AuthConfig) error
+	TrustSign(ctx context.Context, repository, server string, req types.TrustSignRequest, metaHeaders map[string][]string, authConfig *types.AuthConfig) (types.TrustTarget, error)
+}
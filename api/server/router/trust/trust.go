@@ -0,0 +1,37 @@
+package trust
+
+import "github.com/docker/docker/api/server/router"
+
+// trustRouter is a router to talk with the content trust service of the
+// daemon
+type trustRouter struct {
+	backend Backend
+	routes  []router.Route
+}
+
+// NewRouter initializes a new trust router
+func NewRouter(b Backend) router.Router {
+	r := &trustRouter{
+		backend: b,
+	}
+	r.initRoutes()
+	return r
+}
+
+// Routes returns the available routes to the content trust service
+func (r *trustRouter) Routes() []router.Route {
+	return r.routes
+}
+
+func (r *trustRouter) initRoutes() {
+	r.routes = []router.Route{
+		// GET
+		router.NewGetRoute("/trust/keys", r.getTrustKeys),
+		router.NewGetRoute("/trust/keys/backup", r.getTrustKeysBackup),
+		router.NewGetRoute("/trust/repositories/{name:.*}/signers", r.getTrustSigners),
+		// POST
+		router.NewPostRoute("/trust/keys/create", r.postTrustKeysCreate),
+		router.NewPostRoute("/trust/repositories/{name:.*}/rotate", r.postTrustRotate),
+		router.NewPostRoute("/trust/repositories/{name:.*}/sign", r.postTrustSign),
+	}
+}
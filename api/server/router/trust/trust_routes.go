@@ -0,0 +1,126 @@
+package trust
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/docker/docker/api/server/httputils"
+	"github.com/docker/engine-api/types"
+	"golang.org/x/net/context"
+)
+
+func (r *trustRouter) getTrustKeys(ctx context.Context, w http.ResponseWriter, req *http.Request, vars map[string]string) error {
+	keys, err := r.backend.TrustKeys()
+	if err != nil {
+		return err
+	}
+	return httputils.WriteJSON(w, http.StatusOK, keys)
+}
+
+func (r *trustRouter) postTrustKeysCreate(ctx context.Context, w http.ResponseWriter, req *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(req); err != nil {
+		return err
+	}
+	if err := httputils.CheckForJSON(req); err != nil {
+		return err
+	}
+
+	var createReq types.TrustKeyCreateRequest
+	if err := json.NewDecoder(req.Body).Decode(&createReq); err != nil {
+		return err
+	}
+	key, err := r.backend.TrustKeyCreate(createReq)
+	if err != nil {
+		return err
+	}
+	return httputils.WriteJSON(w, http.StatusCreated, key)
+}
+
+func (r *trustRouter) getTrustKeysBackup(ctx context.Context, w http.ResponseWriter, req *http.Request, vars map[string]string) error {
+	archive, err := r.backend.TrustKeysBackup()
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	w.Header().Set("Content-Type", "application/x-tar")
+	_, err = io.Copy(w, archive)
+	return err
+}
+
+func (r *trustRouter) getTrustSigners(ctx context.Context, w http.ResponseWriter, req *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(req); err != nil {
+		return err
+	}
+
+	metaHeaders, authConfig := registryCredentials(req)
+	signers, err := r.backend.TrustSigners(ctx, vars["name"], req.Form.Get("server"), metaHeaders, authConfig)
+	if err != nil {
+		return err
+	}
+	return httputils.WriteJSON(w, http.StatusOK, signers)
+}
+
+func (r *trustRouter) postTrustRotate(ctx context.Context, w http.ResponseWriter, req *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(req); err != nil {
+		return err
+	}
+	if err := httputils.CheckForJSON(req); err != nil {
+		return err
+	}
+
+	var rotateReq types.TrustRotateRequest
+	if err := json.NewDecoder(req.Body).Decode(&rotateReq); err != nil {
+		return err
+	}
+	metaHeaders, authConfig := registryCredentials(req)
+	if err := r.backend.TrustKeyRotate(ctx, vars["name"], req.Form.Get("server"), rotateReq, metaHeaders, authConfig); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (r *trustRouter) postTrustSign(ctx context.Context, w http.ResponseWriter, req *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(req); err != nil {
+		return err
+	}
+	if err := httputils.CheckForJSON(req); err != nil {
+		return err
+	}
+
+	var signReq types.TrustSignRequest
+	if err := json.NewDecoder(req.Body).Decode(&signReq); err != nil {
+		return err
+	}
+	metaHeaders, authConfig := registryCredentials(req)
+	target, err := r.backend.TrustSign(ctx, vars["name"], req.Form.Get("server"), signReq, metaHeaders, authConfig)
+	if err != nil {
+		return err
+	}
+	return httputils.WriteJSON(w, http.StatusOK, target)
+}
+
+// registryCredentials returns the X-Meta- headers of req and the registry
+// credentials of its X-Registry-Auth header, which the notary server of the
+// registry accepts.
+func registryCredentials(req *http.Request) (map[string][]string, *types.AuthConfig) {
+	metaHeaders := map[string][]string{}
+	for k, v := range req.Header {
+		if strings.HasPrefix(k, "X-Meta-") {
+			metaHeaders[k] = v
+		}
+	}
+	authConfig := &types.AuthConfig{}
+	if authEncoded := req.Header.Get("X-Registry-Auth"); authEncoded != "" {
+		authJSON := base64.NewDecoder(base64.URLEncoding, strings.NewReader(authEncoded))
+		if err := json.NewDecoder(authJSON).Decode(authConfig); err != nil {
+			// as for a pull, it is not an error if no auth was given
+			authConfig = &types.AuthConfig{}
+		}
+	}
+	return metaHeaders, authConfig
+}
@@ -2,10 +2,15 @@ package volume
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 
 	"github.com/docker/docker/api/server/httputils"
+	"github.com/docker/docker/api/types/backend"
+	"github.com/docker/docker/errors"
+	volumepkg "github.com/docker/docker/volume"
 	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/filters"
 	"golang.org/x/net/context"
 )
 
@@ -14,7 +19,20 @@ func (v *volumeRouter) getVolumesList(ctx context.Context, w http.ResponseWriter
 		return err
 	}
 
-	volumes, warnings, err := v.backend.Volumes(r.Form.Get("filters"))
+	filter := r.Form.Get("filters")
+	// the users only see their volumes in tenancy mode
+	if tenant := httputils.TenantFromContext(ctx); tenant != "" {
+		volFilters, err := filters.FromParam(filter)
+		if err != nil {
+			return err
+		}
+		volFilters.Add("label", backend.OwnerLabel+"="+tenant)
+		if filter, err = filters.ToParam(volFilters); err != nil {
+			return err
+		}
+	}
+
+	volumes, warnings, err := v.backend.Volumes(filter)
 	if err != nil {
 		return err
 	}
@@ -47,6 +65,17 @@ func (v *volumeRouter) postVolumesCreate(ctx context.Context, w http.ResponseWri
 		return err
 	}
 
+	if tenant := httputils.TenantFromContext(ctx); tenant != "" {
+		// the options of the local driver can bind-mount any path of the host
+		if len(req.DriverOpts) > 0 && (req.Driver == "" || req.Driver == volumepkg.DefaultDriverName) {
+			return errors.NewRequestForbiddenError(fmt.Errorf("options of the %s volume driver are not allowed in tenancy mode", volumepkg.DefaultDriverName))
+		}
+		if req.Labels == nil {
+			req.Labels = make(map[string]string)
+		}
+		req.Labels[backend.OwnerLabel] = tenant
+	}
+
 	volume, err := v.backend.VolumeCreate(req.Name, req.Driver, req.DriverOpts, req.Labels)
 	if err != nil {
 		return err
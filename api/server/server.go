@@ -5,13 +5,18 @@ import (
 	"net"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/api/server/httputils"
 	"github.com/docker/docker/api/server/middleware"
 	"github.com/docker/docker/api/server/router"
+	"github.com/docker/docker/pkg/requestid"
+	"github.com/docker/docker/pkg/tracing"
+	"github.com/docker/engine-api/types/versions"
 	"github.com/gorilla/mux"
 	"golang.org/x/net/context"
+	"golang.org/x/net/http2"
 )
 
 // versionMatcher defines a variable matcher to be parsed by the router
@@ -26,6 +31,15 @@ type Config struct {
 	Version     string
 	SocketGroup string
 	TLSConfig   *tls.Config
+	// TCPKeepAlive is the period of the keep-alive probes of the TCP
+	// connections, 0 to disable them.
+	TCPKeepAlive time.Duration
+	// StreamIdleTimeout is the duration after which the attach, exec, logs
+	// and events streams without any traffic are closed, 0 to keep them open.
+	StreamIdleTimeout time.Duration
+	// HTTP2 enables HTTP/2 on the TLS listeners, for the clients negotiating
+	// it. TLSConfig must advertise the "h2" protocol.
+	HTTP2 bool
 }
 
 // Server contains instance details for the server
@@ -60,6 +74,9 @@ func (s *Server) Accept(addr string, listeners ...net.Listener) {
 			},
 			l: listener,
 		}
+		if s.cfg.HTTP2 {
+			http2.ConfigureServer(httpServer.srv, nil)
+		}
 		s.servers = append(s.servers, httpServer)
 	}
 }
@@ -126,7 +143,23 @@ func (s *Server) makeHTTPHandler(handler httputils.APIFunc) http.HandlerFunc {
 		// apply to all requests. Data that is specific to the
 		// immediate function being called should still be passed
 		// as 'args' on the function call.
-		ctx := context.Background()
+		id := requestid.FromHeader(r.Header)
+		if id == "" {
+			id = requestid.Generate()
+		}
+		w.Header().Set(requestid.Header, id)
+		ctx := requestid.WithID(context.Background(), id)
+
+		name := r.Method + " " + r.URL.Path
+		if route := mux.CurrentRoute(r); route != nil && route.GetName() != "" {
+			name = route.GetName()
+		}
+		span, ctx := tracing.StartSpan(tracing.WithRemoteParent(ctx, r.Header), name)
+		span.SetKind("SERVER")
+		span.SetTag("http.path", r.URL.Path)
+		if s.cfg.StreamIdleTimeout > 0 {
+			ctx = context.WithValue(ctx, httputils.StreamIdleTimeoutKey, s.cfg.StreamIdleTimeout)
+		}
 		handlerFunc := s.handleWithGlobalMiddlewares(handler)
 
 		vars := mux.Vars(r)
@@ -134,8 +167,13 @@ func (s *Server) makeHTTPHandler(handler httputils.APIFunc) http.HandlerFunc {
 			vars = make(map[string]string)
 		}
 
-		if err := handlerFunc(ctx, w, r, vars); err != nil {
-			logrus.Errorf("Handler for %s %s returned error: %v", r.Method, r.URL.Path, err)
+		err := handlerFunc(ctx, w, r, vars)
+		span.Finish(err)
+		if err != nil {
+			requestid.Logger(ctx).Errorf("Handler for %s %s returned error: %v", r.Method, r.URL.Path, err)
+			if v := vars["version"]; v != "" && versions.LessThan(v, "1.24") {
+				err = httputils.WithoutDetails(err)
+			}
 			httputils.WriteError(w, err)
 		}
 	}
@@ -150,7 +188,7 @@ func (s *Server) InitRouter(enableProfiler bool, routers ...router.Router) {
 
 	m := s.createMux()
 	if enableProfiler {
-		profilerSetup(m)
+		s.profilerSetup(m)
 	}
 	s.routerSwapper = &routerSwapper{
 		router: m,
@@ -167,8 +205,11 @@ func (s *Server) createMux() *mux.Router {
 			f := s.makeHTTPHandler(r.Handler())
 
 			logrus.Debugf("Registering %s, %s", r.Method(), r.Path())
-			m.Path(versionMatcher + r.Path()).Methods(r.Method()).Handler(f)
-			m.Path(r.Path()).Methods(r.Method()).Handler(f)
+			// The routes are named after their path templates, which
+			// name the tracing spans of their requests.
+			name := r.Method() + " " + r.Path()
+			m.Path(versionMatcher + r.Path()).Methods(r.Method()).Handler(f).Name(name)
+			m.Path(r.Path()).Methods(r.Method()).Handler(f).Name(name)
 		}
 	}
 
@@ -195,6 +236,6 @@ func (s *Server) DisableProfiler() {
 // EnableProfiler reloads the server mux adding the profiler routes.
 func (s *Server) EnableProfiler() {
 	m := s.createMux()
-	profilerSetup(m)
+	s.profilerSetup(m)
 	s.routerSwapper.Swap(m)
 }
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -9,6 +10,7 @@ import (
 	"github.com/docker/docker/api"
 	"github.com/docker/docker/api/server/httputils"
 	"github.com/docker/docker/api/server/middleware"
+	"github.com/docker/docker/pkg/requestid"
 
 	"golang.org/x/net/context"
 )
@@ -44,3 +46,63 @@ func TestMiddlewares(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+type denyMiddleware struct {
+	paths []string
+}
+
+func (d *denyMiddleware) WrapHandler(handler func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error) func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		d.paths = append(d.paths, r.URL.Path)
+		return errors.New("denied")
+	}
+}
+
+func TestProfilerUsesMiddlewares(t *testing.T) {
+	srv := &Server{
+		cfg: &Config{},
+	}
+	deny := &denyMiddleware{}
+	srv.UseMiddleware(deny)
+	srv.InitRouter(true)
+
+	for _, path := range []string{"/debug/pprof/goroutine", "/debug/pprof/trace", "/debug/vars"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		resp := httptest.NewRecorder()
+		srv.routerSwapper.ServeHTTP(resp, req)
+		if resp.Code != http.StatusInternalServerError {
+			t.Fatalf("Expected %s to be denied, got status %d", path, resp.Code)
+		}
+	}
+	if len(deny.paths) != 3 {
+		t.Fatalf("Expected the middleware to see 3 requests, got %v", deny.paths)
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	srv := &Server{
+		cfg: &Config{},
+	}
+	var ids []string
+	h := srv.makeHTTPHandler(func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		ids = append(ids, requestid.FromContext(ctx))
+		return nil
+	})
+
+	for _, header := range []http.Header{
+		{},
+		{"X-Request-Id": {"run-42"}},
+		{"Traceparent": {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}},
+	} {
+		req, _ := http.NewRequest("GET", "/containers/json", nil)
+		req.Header = header
+		resp := httptest.NewRecorder()
+		h(resp, req)
+		if id := resp.Header().Get("X-Request-Id"); id != ids[len(ids)-1] {
+			t.Fatalf("Expected the response to hold the request ID %q, got %q", ids[len(ids)-1], id)
+		}
+	}
+	if len(ids[0]) != 32 || ids[1] != "run-42" || ids[2] != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("Unexpected request IDs %v", ids)
+	}
+}
@@ -5,11 +5,17 @@ package backend
 
 import (
 	"io"
+	"time"
 
 	"github.com/docker/docker/pkg/streamformatter"
 	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/filters"
 )
 
+// OwnerLabel is the label holding the user owning the containers, networks
+// and volumes created in tenancy mode.
+const OwnerLabel = "com.docker.tenancy.owner"
+
 // ContainerAttachConfig holds the streams to use when connecting to a container to view logs.
 type ContainerAttachConfig struct {
 	GetStreams func() (io.ReadCloser, io.Writer, io.Writer, error)
@@ -25,6 +31,12 @@ type ContainerAttachConfig struct {
 	// HOWEVER, the websocket endpoint is using a single stream and SHOULD be encoded with stdout/stderr as is done for HTTP since it is still just a single stream.
 	// Since such a change is an API change unrelated to the current changeset we'll keep it as is here and change separately.
 	MuxStreams bool
+
+	// IdleTimeout is the duration after which the streams without any
+	// traffic are closed with CloseIdle, 0 to keep them open. CloseIdle is
+	// told whether the streams are multiplexed.
+	IdleTimeout time.Duration
+	CloseIdle   func(muxed bool)
 }
 
 // ContainerLogsConfig holds configs for logging operations. Exists
@@ -42,6 +54,20 @@ type ContainerStatsConfig struct {
 	Version   string
 }
 
+// ContainersBatchConfig holds the configuration of a stop, kill or restart
+// of all the containers matching Filters.
+type ContainersBatchConfig struct {
+	Filters filters.Args
+	// Signal is the signal sent by kill, 0 for SIGKILL.
+	Signal uint64
+	// Timeout is the number of seconds stop and restart wait before
+	// killing the containers.
+	Timeout int
+	// Parallelism is the maximum number of containers handled at the
+	// same time, the daemon default if 0.
+	Parallelism int
+}
+
 // ExecInspect holds information about a running process started
 // with docker exec.
 type ExecInspect struct {
@@ -73,6 +99,12 @@ type ExecProcessConfig struct {
 type ContainerCommitConfig struct {
 	types.ContainerCommitConfig
 	Changes []string
+	// RecordOrigin records the container and the command it runs in the
+	// history of the image, rather than the command only.
+	RecordOrigin bool
+	// BuildCache records the image as build cache, which `docker builder
+	// prune` removes once unused.
+	BuildCache bool
 }
 
 // ProgressWriter is an interface
@@ -124,7 +124,7 @@ type Backend interface {
 	// ContainerKill stops the container execution abruptly.
 	ContainerKill(containerID string, sig uint64) error
 	// ContainerStart starts a new container
-	ContainerStart(containerID string, hostConfig *container.HostConfig) error
+	ContainerStart(ctx context.Context, containerID string, hostConfig *container.HostConfig) (types.ContainerStartResponse, error)
 	// ContainerWait stops processing until the given container is stopped.
 	ContainerWait(containerID string, timeout time.Duration) (int, error)
 	// ContainerUpdateCmdOnBuild updates container.Path and container.Args
@@ -146,6 +146,16 @@ type Image interface {
 	RunConfig() *container.Config
 }
 
+// TenancyBackend restricts the builds of the users to their own images in
+// tenancy mode.
+type TenancyBackend interface {
+	// CheckTenant returns an error unless tenant owns the object name of
+	// kind, such as "images".
+	CheckTenant(kind, name, tenant string) error
+	// ClaimImage records tenant as an owner of the image refOrID.
+	ClaimImage(refOrID, tenant string) error
+}
+
 // ImageCache abstracts an image cache store.
 // (parent image, child runconfig) -> child image
 type ImageCache interface {
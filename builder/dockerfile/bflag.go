@@ -11,6 +11,7 @@ type FlagType int
 const (
 	boolType FlagType = iota
 	stringType
+	stringsType
 )
 
 // BFlags contains all flags information for the builder
@@ -27,6 +28,8 @@ type Flag struct {
 	name     string
 	flagType FlagType
 	Value    string
+	// StringValues holds every value given to a strings flag, in order.
+	StringValues []string
 }
 
 // NewBFlags return the new BFlags struct
@@ -63,6 +66,13 @@ func (bf *BFlags) AddString(name string, def string) *Flag {
 	return flag
 }
 
+// AddStrings adds a string flag to BFlags that may be given more than once.
+// Each value is appended to StringValues.
+// Note, any error will be generated when Parse() is called (see Parse).
+func (bf *BFlags) AddStrings(name string) *Flag {
+	return bf.addFlag(name, stringsType)
+}
+
 // addFlag is a generic func used by the other AddXXX() func
 // to add a new flag to the BFlags struct.
 // Note, any error will be generated when Parse() is called (see Parse).
@@ -105,7 +115,9 @@ func (fl *Flag) IsTrue() bool {
 // compile time error so it doesn't matter too much when we stop our
 // processing as long as we do stop it, so this allows the code
 // around AddXXX() to be just:
-//     defFlag := AddString("description", "")
+//
+//	defFlag := AddString("description", "")
+//
 // w/o needing to add an if-statement around each one.
 func (bf *BFlags) Parse() error {
 	// If there was an error while defining the possible flags
@@ -138,7 +150,7 @@ func (bf *BFlags) Parse() error {
 			return fmt.Errorf("Unknown flag: %s", arg)
 		}
 
-		if _, ok = bf.used[arg]; ok {
+		if _, ok = bf.used[arg]; ok && flag.flagType != stringsType {
 			return fmt.Errorf("Duplicate flag specified: %s", arg)
 		}
 
@@ -166,6 +178,12 @@ func (bf *BFlags) Parse() error {
 			}
 			flag.Value = value
 
+		case stringsType:
+			if index < 0 {
+				return fmt.Errorf("Missing a value on flag: %s", arg)
+			}
+			flag.StringValues = append(flag.StringValues, value)
+
 		default:
 			panic(fmt.Errorf("No idea what kind of flag we have! Should never get here!"))
 		}
@@ -10,11 +10,15 @@ import (
 	"strings"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/api/server/httputils"
 	"github.com/docker/docker/api/types/backend"
 	"github.com/docker/docker/builder"
 	"github.com/docker/docker/builder/dockerfile/parser"
 	"github.com/docker/docker/image"
+	"github.com/docker/docker/pkg/staging"
+	"github.com/docker/docker/pkg/streamformatter"
 	"github.com/docker/docker/pkg/stringid"
+	"github.com/docker/docker/pkg/tracing"
 	"github.com/docker/docker/reference"
 	"github.com/docker/engine-api/types"
 	"github.com/docker/engine-api/types/container"
@@ -28,6 +32,7 @@ var validCommitCommands = map[string]bool{
 	"expose":     true,
 	"label":      true,
 	"onbuild":    true,
+	"stopsignal": true,
 	"user":       true,
 	"volume":     true,
 	"workdir":    true,
@@ -58,6 +63,8 @@ type Builder struct {
 	context   builder.Context
 	clientCtx context.Context
 	cancel    context.CancelFunc
+	owner     string        // user the build runs for in tenancy mode
+	staging   *staging.Area // area of the temporary files of the build, such as its secrets
 
 	dockerfile       *parser.Node
 	runConfig        *container.Config // runconfig for cmd, run, entrypoint etc.
@@ -78,16 +85,17 @@ type Builder struct {
 // BuildManager implements builder.Backend and is shared across all Builder objects.
 type BuildManager struct {
 	backend builder.Backend
+	staging *staging.Area
 }
 
-// NewBuildManager creates a BuildManager.
-func NewBuildManager(b builder.Backend) (bm *BuildManager) {
-	return &BuildManager{backend: b}
+// NewBuildManager creates a BuildManager staging the build contexts in area.
+func NewBuildManager(b builder.Backend, area *staging.Area) (bm *BuildManager) {
+	return &BuildManager{backend: b, staging: area}
 }
 
 // BuildFromContext builds a new image from a given context.
 func (bm *BuildManager) BuildFromContext(ctx context.Context, src io.ReadCloser, remote string, buildOptions *types.ImageBuildOptions, pg backend.ProgressWriter) (string, error) {
-	buildContext, dockerfileName, err := builder.DetectContextFromRemoteURL(src, remote, pg.ProgressReaderFunc)
+	buildContext, dockerfileName, err := builder.DetectContextFromRemoteURL(src, remote, pg.ProgressReaderFunc, bm.staging)
 	if err != nil {
 		return "", err
 	}
@@ -103,6 +111,7 @@ func (bm *BuildManager) BuildFromContext(ctx context.Context, src io.ReadCloser,
 	if err != nil {
 		return "", err
 	}
+	b.staging = bm.staging
 	return b.build(pg.StdoutFormatter, pg.StderrFormatter, pg.Output)
 }
 
@@ -120,6 +129,7 @@ func NewBuilder(clientCtx context.Context, config *types.ImageBuildOptions, back
 	b = &Builder{
 		clientCtx:        ctx,
 		cancel:           cancel,
+		owner:            httputils.TenantFromContext(clientCtx),
 		options:          config,
 		Stdout:           os.Stdout,
 		Stderr:           os.Stderr,
@@ -212,6 +222,13 @@ func (b *Builder) build(stdout io.Writer, stderr io.Writer, out io.Writer) (stri
 		return "", err
 	}
 
+	warnings := lint(b.dockerfile)
+	b.reportWarnings(warnings)
+	if b.options.CheckOnly {
+		fmt.Fprintf(b.Stdout, "Check complete, %d warning(s) found\n", len(warnings))
+		return "", nil
+	}
+
 	if len(b.options.Labels) > 0 {
 		line := "LABEL "
 		for k, v := range b.options.Labels {
@@ -234,7 +251,11 @@ func (b *Builder) build(stdout io.Writer, stderr io.Writer, out io.Writer) (stri
 		default:
 			// Not cancelled yet, keep going...
 		}
-		if err := b.dispatch(i, n); err != nil {
+		span, _ := tracing.StartSpan(b.clientCtx, fmt.Sprintf("build step %d", i+1))
+		span.SetTag("instruction", n.Original)
+		err := b.dispatch(i, n)
+		span.Finish(err)
+		if err != nil {
 			if b.options.ForceRemove {
 				b.clearTmp()
 			}
@@ -275,6 +296,22 @@ func (b *Builder) build(stdout io.Writer, stderr io.Writer, out io.Writer) (stri
 	return b.image, nil
 }
 
+// reportWarnings writes the warnings found by the checks of the Dockerfile
+// to the build output, and to the aux field of a message for the clients
+// that process them.
+func (b *Builder) reportWarnings(warnings []types.BuildWarning) {
+	if len(warnings) == 0 {
+		return
+	}
+	for _, w := range warnings {
+		fmt.Fprintf(b.Stdout, "[WARNING] %s (line %d): %s\n", w.Rule, w.Line, w.Message)
+	}
+	if b.Output != nil {
+		sf := streamformatter.NewJSONStreamFormatter()
+		b.Output.Write(sf.FormatProgress("", "", nil, types.BuildResult{Warnings: warnings}))
+	}
+}
+
 // Cancel cancels an ongoing Dockerfile build.
 func (b *Builder) Cancel() {
 	b.cancel()
@@ -9,6 +9,7 @@ package dockerfile
 
 import (
 	"fmt"
+	"os"
 	"regexp"
 	"runtime"
 	"sort"
@@ -201,12 +202,19 @@ func from(b *Builder, args []string, attributes map[string]bool, original string
 		if !b.options.PullParent {
 			image, err = b.docker.GetImageOnBuild(name)
 			// TODO: shouldn't we error out if error is different from "not found" ?
+			// The image of another user is pulled from the registry instead.
+			if image != nil && !b.ownsImage(image.ImageID()) {
+				image = nil
+			}
 		}
 		if image == nil {
 			image, err = b.docker.PullOnBuild(b.clientCtx, name, b.options.AuthConfigs, b.Output)
 			if err != nil {
 				return err
 			}
+			if err := b.claimImage(image.ImageID()); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -284,10 +292,23 @@ func run(b *Builder, args []string, attributes map[string]bool, original string)
 		return fmt.Errorf("Please provide a source image with `from` prior to run")
 	}
 
+	flMount := b.flags.AddStrings("mount")
 	if err := b.flags.Parse(); err != nil {
 		return err
 	}
 
+	var mounts []*runMount
+	for _, val := range flMount.StringValues {
+		if runtime.GOOS == "windows" {
+			return fmt.Errorf("RUN --mount is not supported on Windows")
+		}
+		m, err := parseRunMount(val)
+		if err != nil {
+			return err
+		}
+		mounts = append(mounts, m)
+	}
+
 	args = handleJSONArgs(args, attributes)
 
 	if !attributes["json"] {
@@ -376,7 +397,17 @@ func run(b *Builder, args []string, attributes map[string]bool, original string)
 
 	logrus.Debugf("[BUILDER] Command to be executed: %v", b.runConfig.Cmd)
 
-	cID, err := b.create()
+	// The mounts are not part of the cache key: a cache mount only speeds up
+	// the command and a secret must never end up in the image metadata.
+	binds, secretsDir, err := b.runMountBinds(mounts)
+	if err != nil {
+		return err
+	}
+	if secretsDir != "" {
+		defer os.RemoveAll(secretsDir)
+	}
+
+	cID, err := b.create(binds)
 	if err != nil {
 		return err
 	}
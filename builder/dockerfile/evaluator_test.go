@@ -78,7 +78,7 @@ func executeTestCase(t *testing.T, testCase dispatchTestCase) {
 		}
 	}()
 
-	context, err := builder.MakeTarSumContext(tarStream)
+	context, err := builder.MakeTarSumContext(tarStream, nil)
 
 	if err != nil {
 		t.Fatalf("Error when creating tar context: %s", err)
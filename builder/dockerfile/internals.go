@@ -64,7 +64,7 @@ func (b *Builder) commit(id string, autoCmd strslice.StrSlice, comment string) e
 		} else if hit {
 			return nil
 		}
-		id, err = b.create()
+		id, err = b.create(nil)
 		if err != nil {
 			return err
 		}
@@ -80,6 +80,7 @@ func (b *Builder) commit(id string, autoCmd strslice.StrSlice, comment string) e
 			Pause:  true,
 			Config: &autoConfig,
 		},
+		BuildCache: true,
 	}
 
 	// Commit the container
@@ -87,6 +88,9 @@ func (b *Builder) commit(id string, autoCmd strslice.StrSlice, comment string) e
 	if err != nil {
 		return err
 	}
+	if err := b.claimImage(imageID); err != nil {
+		return err
+	}
 
 	b.image = imageID
 	return nil
@@ -190,7 +194,7 @@ func (b *Builder) runContextCommand(args []string, allowRemote bool, allowLocalD
 		return nil
 	}
 
-	container, err := b.docker.ContainerCreate(types.ContainerCreateConfig{Config: b.runConfig})
+	container, err := b.docker.ContainerCreate(types.ContainerCreateConfig{Config: b.containerConfig()})
 	if err != nil {
 		return err
 	}
@@ -473,7 +477,8 @@ func (b *Builder) probeCache() (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	if len(cache) == 0 {
+	// the images built by another user are not shared
+	if len(cache) == 0 || !b.ownsImage(cache) {
 		logrus.Debugf("[BUILDER] Cache miss: %s", b.runConfig.Cmd)
 		b.cacheBusted = true
 		return false, nil
@@ -486,7 +491,7 @@ func (b *Builder) probeCache() (bool, error) {
 	return true, nil
 }
 
-func (b *Builder) create() (string, error) {
+func (b *Builder) create(binds []string) (string, error) {
 	if b.image == "" && !b.noBaseImage {
 		return "", fmt.Errorf("Please provide a source image with `from` prior to run")
 	}
@@ -509,13 +514,14 @@ func (b *Builder) create() (string, error) {
 		Isolation: b.options.Isolation,
 		ShmSize:   b.options.ShmSize,
 		Resources: resources,
+		Binds:     binds,
 	}
 
 	config := *b.runConfig
 
 	// Create the container
 	c, err := b.docker.ContainerCreate(types.ContainerCreateConfig{
-		Config:     b.runConfig,
+		Config:     b.containerConfig(),
 		HostConfig: hostConfig,
 	})
 	if err != nil {
@@ -561,7 +567,7 @@ func (b *Builder) run(cID string) (err error) {
 		}
 	}()
 
-	if err := b.docker.ContainerStart(cID, nil); err != nil {
+	if _, err := b.docker.ContainerStart(b.clientCtx, cID, nil); err != nil {
 		return err
 	}
 
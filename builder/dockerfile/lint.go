@@ -0,0 +1,77 @@
+package dockerfile
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/docker/docker/api"
+	"github.com/docker/docker/builder/dockerfile/command"
+	"github.com/docker/docker/builder/dockerfile/parser"
+	"github.com/docker/docker/reference"
+	"github.com/docker/engine-api/types"
+)
+
+// The IDs of the rules checked by lint.
+const (
+	ruleFromUnpinned         = "FromUnpinned"
+	ruleMaintainerDeprecated = "MaintainerDeprecated"
+	ruleShellFormSignals     = "ShellFormSignals"
+	ruleWindowsPathEscape    = "WindowsPathEscape"
+)
+
+// windowsPath matches an absolute Windows path such as C:\foo, in which the
+// backslashes are taken as escape characters.
+var windowsPath = regexp.MustCompile(`(^|[^\w])[a-zA-Z]:\\`)
+
+// lint checks the instructions of a Dockerfile for common problems that do
+// not prevent the build, and returns a warning for each problem found.
+func lint(ast *parser.Node) []types.BuildWarning {
+	var warnings []types.BuildWarning
+	warn := func(rule string, n *parser.Node, format string, args ...interface{}) {
+		warnings = append(warnings, types.BuildWarning{
+			Rule:    rule,
+			Line:    n.StartLine,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	for _, n := range ast.Children {
+		switch n.Value {
+		case command.From:
+			if n.Next == nil {
+				continue
+			}
+			name := n.Next.Value
+			if name == api.NoBaseImageSpecifier {
+				continue
+			}
+			ref, err := reference.ParseNamed(name)
+			if err != nil {
+				continue
+			}
+			if _, ok := ref.(reference.Canonical); ok {
+				continue
+			}
+			if tagged, ok := ref.(reference.NamedTagged); !ok || tagged.Tag() == reference.DefaultTag {
+				warn(ruleFromUnpinned, n, "FROM %s does not pin the base image to a tag or digest, the build may change when the image is updated", name)
+			}
+		case command.Maintainer:
+			warn(ruleMaintainerDeprecated, n, "MAINTAINER is deprecated, use LABEL maintainer=<name> instead")
+		case command.Cmd, command.Entrypoint:
+			if n.Next != nil && !n.Attributes["json"] {
+				warn(ruleShellFormSignals, n, "%s in shell form runs the command as a child of /bin/sh -c, which does not pass it signals such as the one sent by docker stop; use the JSON form instead", strings.ToUpper(n.Value))
+			}
+		}
+
+		if replaceEnvAllowed[n.Value] {
+			for arg := n.Next; arg != nil; arg = arg.Next {
+				if windowsPath.MatchString(arg.Value) {
+					warn(ruleWindowsPathEscape, n, "backslashes are escape characters in %s, write the path %s with forward slashes or doubled backslashes", strings.ToUpper(n.Value), arg.Value)
+					break
+				}
+			}
+		}
+	}
+	return warnings
+}
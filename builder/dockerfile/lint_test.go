@@ -0,0 +1,49 @@
+package dockerfile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/builder/dockerfile/parser"
+)
+
+func TestLint(t *testing.T) {
+	dockerfile := `FROM busybox
+MAINTAINER someone
+FROM busybox:1.24
+FROM busybox:latest
+FROM busybox@sha256:a59906e33509d14c036c8678d687bd4eec81ed7c4b8ce907b888c607f6a1e0e6
+FROM scratch
+CMD ["top"]
+CMD top
+ENTRYPOINT top -b
+WORKDIR C:\windows
+RUN echo C:\windows
+COPY foo C:/foo
+`
+	ast, err := parser.Parse(strings.NewReader(dockerfile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		rule string
+		line int
+	}{
+		{ruleFromUnpinned, 1},
+		{ruleMaintainerDeprecated, 2},
+		{ruleFromUnpinned, 4},
+		{ruleShellFormSignals, 8},
+		{ruleShellFormSignals, 9},
+		{ruleWindowsPathEscape, 10},
+	}
+	warnings := lint(ast)
+	if len(warnings) != len(expected) {
+		t.Fatalf("expected %d warnings, got %d: %+v", len(expected), len(warnings), warnings)
+	}
+	for i, w := range warnings {
+		if w.Rule != expected[i].rule || w.Line != expected[i].line {
+			t.Fatalf("expected warning %d to be %s at line %d, got %s at line %d", i, expected[i].rule, expected[i].line, w.Rule, w.Line)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package dockerfile
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	runMountTypeCache  = "cache"
+	runMountTypeSecret = "secret"
+
+	// cacheVolumePrefix prefixes the names of the volumes that back the
+	// cache mounts of RUN instructions.
+	cacheVolumePrefix = "build-cache-"
+
+	// defaultSecretDir is where secrets are mounted when no target is given.
+	defaultSecretDir = "/run/secrets"
+)
+
+// runMount is a mount requested with RUN --mount. It is only mounted while
+// the RUN instruction executes and its content is never committed to the
+// image.
+type runMount struct {
+	Type     string
+	ID       string
+	Target   string
+	ReadOnly bool
+}
+
+// parseRunMount parses the value of a RUN --mount flag, a comma separated
+// list of key=value options such as "type=cache,target=/root/.cache".
+func parseRunMount(val string) (*runMount, error) {
+	m := &runMount{}
+	for _, field := range strings.Split(val, ",") {
+		parts := strings.SplitN(field, "=", 2)
+		key := strings.ToLower(strings.TrimSpace(parts[0]))
+		value := ""
+		if len(parts) == 2 {
+			value = strings.TrimSpace(parts[1])
+		}
+		switch key {
+		case "type":
+			m.Type = value
+		case "id":
+			m.ID = value
+		case "target", "dst", "destination":
+			m.Target = value
+		case "ro", "readonly":
+			m.ReadOnly = value == "" || value == "true"
+		default:
+			return nil, fmt.Errorf("unknown option %q in --mount=%s", key, val)
+		}
+	}
+
+	switch m.Type {
+	case runMountTypeCache:
+		if m.Target == "" {
+			return nil, fmt.Errorf("--mount=%s: target is required for a cache mount", val)
+		}
+		if m.ID == "" {
+			m.ID = m.Target
+		}
+	case runMountTypeSecret:
+		if m.ID == "" {
+			return nil, fmt.Errorf("--mount=%s: id is required for a secret mount", val)
+		}
+		if strings.ContainsAny(m.ID, `/\`) {
+			return nil, fmt.Errorf("--mount=%s: invalid secret id %q", val, m.ID)
+		}
+		if m.Target == "" {
+			m.Target = path.Join(defaultSecretDir, m.ID)
+		}
+		// secrets are always mounted read-only
+		m.ReadOnly = true
+	case "":
+		return nil, fmt.Errorf("--mount=%s: type is required", val)
+	default:
+		return nil, fmt.Errorf("--mount=%s: unsupported mount type %q, must be cache or secret", val, m.Type)
+	}
+
+	if !path.IsAbs(m.Target) {
+		return nil, fmt.Errorf("--mount=%s: target must be an absolute path", val)
+	}
+	return m, nil
+}
+
+// cacheVolumeName returns the name of the volume that backs the cache mounts
+// with the given id in the builds of owner, the user the build runs for in
+// tenancy mode. The same id always maps to the same volume, so the cache is
+// shared by all the builds of the same user that use it.
+func cacheVolumeName(owner, id string) string {
+	if owner != "" {
+		id = owner + "\x00" + id
+	}
+	sum := sha256.Sum256([]byte(id))
+	return cacheVolumePrefix + hex.EncodeToString(sum[:])[:16]
+}
+
+// runMountBinds returns the binds of the container that runs a RUN
+// instruction with the given mounts. The secrets are written to files in a
+// temporary directory of the build staging area that the caller must remove
+// once the container exited.
+func (b *Builder) runMountBinds(mounts []*runMount) (binds []string, secretsDir string, err error) {
+	for _, m := range mounts {
+		var source string
+		switch m.Type {
+		case runMountTypeCache:
+			source = cacheVolumeName(b.owner, m.ID)
+		case runMountTypeSecret:
+			data, ok := b.options.Secrets[m.ID]
+			if !ok {
+				err = fmt.Errorf("secret %s was not provided, use docker build --secret id=%s,src=<file>", m.ID, m.ID)
+				break
+			}
+			if secretsDir == "" {
+				if secretsDir, err = b.staging.TempDir("docker-build-secrets-"); err != nil {
+					break
+				}
+			}
+			source = filepath.Join(secretsDir, m.ID)
+			err = ioutil.WriteFile(source, data, 0444)
+		}
+		if err != nil {
+			if secretsDir != "" {
+				os.RemoveAll(secretsDir)
+			}
+			return nil, "", err
+		}
+
+		bind := source + ":" + m.Target
+		if m.ReadOnly {
+			bind += ":ro"
+		}
+		binds = append(binds, bind)
+	}
+	return binds, secretsDir, nil
+}
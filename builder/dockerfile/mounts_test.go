@@ -0,0 +1,100 @@
+package dockerfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/pkg/staging"
+	"github.com/docker/engine-api/types"
+)
+
+func TestParseRunMount(t *testing.T) {
+	valid := map[string]runMount{
+		"type=cache,target=/root/.cache":           {Type: "cache", ID: "/root/.cache", Target: "/root/.cache"},
+		"type=cache,id=go,dst=/go/pkg,ro":          {Type: "cache", ID: "go", Target: "/go/pkg", ReadOnly: true},
+		"type=secret,id=npmrc":                     {Type: "secret", ID: "npmrc", Target: "/run/secrets/npmrc", ReadOnly: true},
+		"type=secret,id=npmrc,target=/root/.npmrc": {Type: "secret", ID: "npmrc", Target: "/root/.npmrc", ReadOnly: true},
+	}
+	for val, expected := range valid {
+		m, err := parseRunMount(val)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", val, err)
+		}
+		if *m != expected {
+			t.Fatalf("%s: expected %+v, got %+v", val, expected, *m)
+		}
+	}
+
+	invalid := map[string]string{
+		"target=/cache":                 "type is required",
+		"type=bind,target=/src":         "unsupported mount type",
+		"type=cache":                    "target is required",
+		"type=cache,target=cache":       "absolute path",
+		"type=secret":                   "id is required",
+		"type=secret,id=../etc/passwd":  "invalid secret id",
+		"type=cache,target=/c,size=10G": "unknown option",
+	}
+	for val, expected := range invalid {
+		if _, err := parseRunMount(val); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("%s: expected an error containing %q, got %v", val, expected, err)
+		}
+	}
+}
+
+func TestCacheVolumeName(t *testing.T) {
+	name := cacheVolumeName("", "/root/.cache")
+	if !strings.HasPrefix(name, cacheVolumePrefix) {
+		t.Fatalf("expected the volume name to start with %s, got %s", cacheVolumePrefix, name)
+	}
+	if name != cacheVolumeName("", "/root/.cache") {
+		t.Fatalf("expected the same id to map to the same volume")
+	}
+	if name == cacheVolumeName("", "/root/.npm") {
+		t.Fatalf("expected different ids to map to different volumes")
+	}
+	if owned := cacheVolumeName("alice", "/root/.cache"); owned == name || owned == cacheVolumeName("bob", "/root/.cache") {
+		t.Fatalf("expected the same id of different users to map to different volumes")
+	}
+}
+
+func TestRunMountBindsSecretsInStagingArea(t *testing.T) {
+	dir, err := ioutil.TempDir("", "build-staging-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	area, err := staging.New(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := &Builder{
+		owner:   "alice",
+		staging: area,
+		options: &types.ImageBuildOptions{Secrets: map[string][]byte{"npmrc": []byte("token")}},
+	}
+	mounts := []*runMount{
+		{Type: runMountTypeCache, ID: "/root/.cache", Target: "/root/.cache"},
+		{Type: runMountTypeSecret, ID: "npmrc", Target: "/run/secrets/npmrc", ReadOnly: true},
+	}
+	binds, secretsDir, err := b.runMountBinds(mounts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(secretsDir)
+
+	if filepath.Dir(secretsDir) != dir {
+		t.Fatalf("expected the secrets in the staging area %s, got %s", dir, secretsDir)
+	}
+	expected := []string{
+		cacheVolumeName("alice", "/root/.cache") + ":/root/.cache",
+		filepath.Join(secretsDir, "npmrc") + ":/run/secrets/npmrc:ro",
+	}
+	if !reflect.DeepEqual(binds, expected) {
+		t.Fatalf("expected binds %v, got %v", expected, binds)
+	}
+}
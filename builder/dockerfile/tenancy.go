@@ -0,0 +1,44 @@
+package dockerfile
+
+import (
+	"github.com/docker/docker/api/types/backend"
+	"github.com/docker/docker/builder"
+	"github.com/docker/engine-api/types/container"
+)
+
+// ownsImage returns whether the user the build runs for, if any, owns the
+// image id.
+func (b *Builder) ownsImage(id string) bool {
+	t, ok := b.docker.(builder.TenancyBackend)
+	if b.owner == "" || !ok {
+		return true
+	}
+	return t.CheckTenant("images", id, b.owner) == nil
+}
+
+// claimImage records the user the build runs for, if any, as an owner of the
+// image id, pulled or committed by the build.
+func (b *Builder) claimImage(id string) error {
+	t, ok := b.docker.(builder.TenancyBackend)
+	if b.owner == "" || !ok {
+		return nil
+	}
+	return t.ClaimImage(id, b.owner)
+}
+
+// containerConfig returns the config of a build container, labeled with the
+// user the build runs for, if any, so that the daemon checks what the
+// container uses like for the other containers of the user. The label is not
+// set in the config of the images built.
+func (b *Builder) containerConfig() *container.Config {
+	if b.owner == "" {
+		return b.runConfig
+	}
+	config := *b.runConfig
+	config.Labels = make(map[string]string, len(b.runConfig.Labels)+1)
+	for k, v := range b.runConfig.Labels {
+		config.Labels[k] = v
+	}
+	config.Labels[backend.OwnerLabel] = b.owner
+	return &config
+}
@@ -0,0 +1,64 @@
+package dockerfile
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/docker/docker/api/types/backend"
+	"github.com/docker/docker/builder"
+	"github.com/docker/engine-api/types/container"
+)
+
+type tenancyBackend struct {
+	builder.Backend
+	owners map[string]string
+}
+
+func (t *tenancyBackend) CheckTenant(kind, name, tenant string) error {
+	if t.owners[name] != tenant {
+		return fmt.Errorf("No such %s: %s", kind, name)
+	}
+	return nil
+}
+
+func (t *tenancyBackend) ClaimImage(refOrID, tenant string) error {
+	t.owners[refOrID] = tenant
+	return nil
+}
+
+func TestBuilderTenancy(t *testing.T) {
+	docker := &tenancyBackend{owners: map[string]string{"a": "alice", "b": "bob"}}
+	b := &Builder{docker: docker, owner: "alice", runConfig: &container.Config{Labels: map[string]string{"k": "v"}}}
+
+	if !b.ownsImage("a") {
+		t.Fatal("expected alice to own her image")
+	}
+	if b.ownsImage("b") {
+		t.Fatal("expected alice not to own the image of bob")
+	}
+	if err := b.claimImage("c"); err != nil {
+		t.Fatal(err)
+	}
+	if !b.ownsImage("c") {
+		t.Fatal("expected alice to own the image she claimed")
+	}
+
+	config := b.containerConfig()
+	if config.Labels[backend.OwnerLabel] != "alice" || config.Labels["k"] != "v" {
+		t.Fatalf("unexpected labels of the build container: %v", config.Labels)
+	}
+	if _, ok := b.runConfig.Labels[backend.OwnerLabel]; ok {
+		t.Fatal("expected the owner label not to be set in the config of the images built")
+	}
+
+	b.owner = ""
+	if !b.ownsImage("b") {
+		t.Fatal("expected the images to be shared outside of tenancy mode")
+	}
+	if err := b.claimImage("d"); err != nil || docker.owners["d"] != "" {
+		t.Fatalf("expected no claim outside of tenancy mode, got %v", err)
+	}
+	if b.containerConfig() != b.runConfig {
+		t.Fatal("expected the build containers not to be labeled outside of tenancy mode")
+	}
+}
@@ -5,10 +5,12 @@ import (
 
 	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/gitutils"
+	"github.com/docker/docker/pkg/staging"
 )
 
-// MakeGitContext returns a Context from gitURL that is cloned in a temporary directory.
-func MakeGitContext(gitURL string) (ModifiableContext, error) {
+// MakeGitContext returns a Context from gitURL that is cloned in a temporary directory,
+// and staged in area.
+func MakeGitContext(gitURL string, area *staging.Area) (ModifiableContext, error) {
 	root, err := gitutils.Clone(gitURL)
 	if err != nil {
 		return nil, err
@@ -24,5 +26,5 @@ func MakeGitContext(gitURL string) (ModifiableContext, error) {
 		c.Close()
 		os.RemoveAll(root)
 	}()
-	return MakeTarSumContext(c)
+	return MakeTarSumContext(c, area)
 }
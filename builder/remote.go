@@ -10,6 +10,7 @@ import (
 
 	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/httputils"
+	"github.com/docker/docker/pkg/staging"
 	"github.com/docker/docker/pkg/urlutil"
 )
 
@@ -29,8 +30,9 @@ var mimeRe = regexp.MustCompile(acceptableRemoteMIME)
 //
 // If a match is found, then the body is sent to the contentType handler and a (potentially compressed) tar stream is expected
 // to be returned. If no match is found, it is assumed the body is a tar stream (compressed or not).
-// In either case, an (assumed) tar stream is passed to MakeTarSumContext whose result is returned.
-func MakeRemoteContext(remoteURL string, contentTypeHandlers map[string]func(io.ReadCloser) (io.ReadCloser, error)) (ModifiableContext, error) {
+// In either case, an (assumed) tar stream is passed to MakeTarSumContext, with the staging area
+// area, whose result is returned.
+func MakeRemoteContext(remoteURL string, contentTypeHandlers map[string]func(io.ReadCloser) (io.ReadCloser, error), area *staging.Area) (ModifiableContext, error) {
 	f, err := httputils.Download(remoteURL)
 	if err != nil {
 		return nil, fmt.Errorf("Error downloading remote context %s: %v", remoteURL, err)
@@ -64,18 +66,19 @@ func MakeRemoteContext(remoteURL string, contentTypeHandlers map[string]func(io.
 
 	// Pass through - this is a pre-packaged context, presumably
 	// with a Dockerfile with the right name inside it.
-	return MakeTarSumContext(contextReader)
+	return MakeTarSumContext(contextReader, area)
 }
 
 // DetectContextFromRemoteURL returns a context and in certain cases the name of the dockerfile to be used
 // irrespective of user input.
 // progressReader is only used if remoteURL is actually a URL (not empty, and not a Git endpoint).
-func DetectContextFromRemoteURL(r io.ReadCloser, remoteURL string, createProgressReader func(in io.ReadCloser) io.ReadCloser) (context ModifiableContext, dockerfileName string, err error) {
+// The context is staged in area.
+func DetectContextFromRemoteURL(r io.ReadCloser, remoteURL string, createProgressReader func(in io.ReadCloser) io.ReadCloser, area *staging.Area) (context ModifiableContext, dockerfileName string, err error) {
 	switch {
 	case remoteURL == "":
-		context, err = MakeTarSumContext(r)
+		context, err = MakeTarSumContext(r, area)
 	case urlutil.IsGitURL(remoteURL):
-		context, err = MakeGitContext(remoteURL)
+		context, err = MakeGitContext(remoteURL, area)
 	case urlutil.IsURL(remoteURL):
 		context, err = MakeRemoteContext(remoteURL, map[string]func(io.ReadCloser) (io.ReadCloser, error){
 			httputils.MimeTypes.TextPlain: func(rc io.ReadCloser) (io.ReadCloser, error) {
@@ -95,7 +98,7 @@ func DetectContextFromRemoteURL(r io.ReadCloser, remoteURL string, createProgres
 			"": func(rc io.ReadCloser) (io.ReadCloser, error) {
 				return createProgressReader(rc), nil
 			},
-		})
+		}, area)
 	default:
 		err = fmt.Errorf("remoteURL (%s) could not be recognized as URL", remoteURL)
 	}
@@ -174,7 +174,7 @@ func TestMakeRemoteContext(t *testing.T) {
 			}
 			return archive.Generate(DefaultDockerfileName, string(dockerfile))
 		},
-	})
+	}, nil)
 
 	if err != nil {
 		t.Fatalf("Error when executing DetectContextFromRemoteURL: %s", err)
@@ -8,17 +8,29 @@ import (
 
 	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/chrootarchive"
-	"github.com/docker/docker/pkg/ioutils"
+	"github.com/docker/docker/pkg/staging"
 	"github.com/docker/docker/pkg/symlink"
 	"github.com/docker/docker/pkg/tarsum"
 )
 
+// contextDirPrefix is the prefix of the temporary directories in which the
+// build contexts are extracted.
+const contextDirPrefix = "docker-builder"
+
+// StagingPrefixes are the prefixes of the names of the temporary
+// directories of the builds in their staging area.
+var StagingPrefixes = []string{contextDirPrefix}
+
 type tarSumContext struct {
-	root string
-	sums tarsum.FileInfoSums
+	root    string
+	sums    tarsum.FileInfoSums
+	release func()
 }
 
 func (c *tarSumContext) Close() error {
+	if c.release != nil {
+		c.release()
+	}
 	return os.RemoveAll(c.root)
 }
 
@@ -72,14 +84,15 @@ func (c *tarSumContext) Stat(path string) (string, FileInfo, error) {
 
 // MakeTarSumContext returns a build Context from a tar stream.
 //
-// It extracts the tar stream to a temporary folder that is deleted as soon as
-// the Context is closed.
+// It extracts the tar stream to a temporary folder of the staging area that
+// is deleted as soon as the Context is closed, and fails if the context
+// exceeds the quota of the area. A nil area is the temporary directory.
 // As the extraction happens, a tarsum is calculated for every file, and the set of
 // all those sums then becomes the source of truth for all operations on this Context.
 //
 // Closing tarStream has to be done by the caller.
-func MakeTarSumContext(tarStream io.Reader) (ModifiableContext, error) {
-	root, err := ioutils.TempDir("", "docker-builder")
+func MakeTarSumContext(tarStream io.Reader, area *staging.Area) (ModifiableContext, error) {
+	root, err := area.TempDir(contextDirPrefix)
 	if err != nil {
 		return nil, err
 	}
@@ -99,7 +112,10 @@ func MakeTarSumContext(tarStream io.Reader) (ModifiableContext, error) {
 		return nil, err
 	}
 
-	sum, err := tarsum.NewTarSum(decompressedStream, true, tarsum.Version1)
+	stagedStream, release := area.Reader(decompressedStream)
+	tsc.release = release
+
+	sum, err := tarsum.NewTarSum(stagedStream, true, tarsum.Version1)
 	if err != nil {
 		return nil, err
 	}
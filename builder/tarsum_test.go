@@ -206,7 +206,7 @@ func TestMakeSumTarContext(t *testing.T) {
 
 	defer tarStream.Close()
 
-	tarSum, err := MakeTarSumContext(tarStream)
+	tarSum, err := MakeTarSumContext(tarStream, nil)
 
 	if err != nil {
 		t.Fatalf("Error when executing MakeSumContext: %s", err)
@@ -10,7 +10,9 @@ type Command struct {
 var DockerCommandUsage = []Command{
 	{"attach", "Attach to a running container"},
 	{"build", "Build an image from a Dockerfile"},
+	{"builder", "Manage the build cache"},
 	{"commit", "Create a new image from a container's changes"},
+	{"container", "Manage containers"},
 	{"cp", "Copy files/folders between a container and the local filesystem"},
 	{"create", "Create a new container"},
 	{"diff", "Inspect changes on a container's filesystem"},
@@ -22,6 +24,7 @@ var DockerCommandUsage = []Command{
 	{"import", "Import the contents from a tarball to create a filesystem image"},
 	{"info", "Display system-wide information"},
 	{"inspect", "Return low-level information on a container or image"},
+	{"job", "Manage jobs running containers on a schedule"},
 	{"kill", "Kill a running container"},
 	{"load", "Load an image from a tar archive or STDIN"},
 	{"login", "Log in to a Docker registry"},
@@ -45,6 +48,7 @@ var DockerCommandUsage = []Command{
 	{"stop", "Stop a running container"},
 	{"tag", "Tag an image into a repository"},
 	{"top", "Display the running processes of a container"},
+	{"trust", "Manage the content trust keys of the daemon"},
 	{"unpause", "Unpause all processes within a container"},
 	{"update", "Update configuration of one or more containers"},
 	{"version", "Show the Docker version information"},
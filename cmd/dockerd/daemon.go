@@ -4,6 +4,8 @@ import (
 	"crypto/tls"
 	"fmt"
 	"io"
+	"net"
+	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -19,8 +21,10 @@ import (
 	"github.com/docker/docker/api/server/router/build"
 	"github.com/docker/docker/api/server/router/container"
 	"github.com/docker/docker/api/server/router/image"
+	"github.com/docker/docker/api/server/router/job"
 	"github.com/docker/docker/api/server/router/network"
 	systemrouter "github.com/docker/docker/api/server/router/system"
+	trustrouter "github.com/docker/docker/api/server/router/trust"
 	"github.com/docker/docker/api/server/router/volume"
 	"github.com/docker/docker/builder/dockerfile"
 	cliflags "github.com/docker/docker/cli/flags"
@@ -35,12 +39,15 @@ import (
 	"github.com/docker/docker/pkg/listeners"
 	flag "github.com/docker/docker/pkg/mflag"
 	"github.com/docker/docker/pkg/pidfile"
+	"github.com/docker/docker/pkg/resolver"
 	"github.com/docker/docker/pkg/signal"
 	"github.com/docker/docker/pkg/system"
+	"github.com/docker/docker/pkg/tracing"
 	"github.com/docker/docker/registry"
 	"github.com/docker/docker/runconfig"
 	"github.com/docker/docker/utils"
 	"github.com/docker/go-connections/tlsconfig"
+	"golang.org/x/net/http2"
 )
 
 const (
@@ -82,10 +89,9 @@ func NewDaemonCli() *DaemonCli {
 	}
 }
 
-func migrateKey() (err error) {
+func migrateKey(newPath string) (err error) {
 	// Migrate trust key if exists at ~/.docker/key.json and owned by current user
 	oldPath := filepath.Join(cliconfig.ConfigDir(), cliflags.DefaultTrustKeyFile)
-	newPath := filepath.Join(getDaemonConfDir(), cliflags.DefaultTrustKeyFile)
 	if _, statErr := os.Stat(newPath); os.IsNotExist(statErr) && currentUserIsOwner(oldPath) {
 		defer func() {
 			// Ensure old path is removed if no error occurred
@@ -97,7 +103,7 @@ func migrateKey() (err error) {
 			}
 		}()
 
-		if err := system.MkdirAll(getDaemonConfDir(), os.FileMode(0644)); err != nil {
+		if err := system.MkdirAll(filepath.Dir(newPath), os.FileMode(0644)); err != nil {
 			return fmt.Errorf("Unable to create daemon configuration directory: %s", err)
 		}
 
@@ -142,6 +148,10 @@ func (cli *DaemonCli) start() (err error) {
 	}
 	cli.Config = cliConfig
 
+	if exited, err := cli.setupRootless(flags); exited || err != nil {
+		return err
+	}
+
 	if cli.Config.Debug {
 		utils.EnableDebug()
 	}
@@ -177,12 +187,24 @@ func (cli *DaemonCli) start() (err error) {
 		}()
 	}
 
+	if cli.Config.TracingEndpoint != "" {
+		tracer := tracing.NewTracer(cli.Config.TracingEndpoint, "dockerd", cli.Config.TracingSampleRate)
+		tracing.SetTracer(tracer)
+		defer func() {
+			tracing.SetTracer(nil)
+			tracer.Close()
+		}()
+	}
+
 	serverConfig := &apiserver.Config{
-		Logging:     true,
-		SocketGroup: cli.Config.SocketGroup,
-		Version:     dockerversion.Version,
-		EnableCors:  cli.Config.EnableCors,
-		CorsHeaders: cli.Config.CorsHeaders,
+		Logging:           true,
+		SocketGroup:       cli.Config.SocketGroup,
+		Version:           dockerversion.Version,
+		EnableCors:        cli.Config.EnableCors,
+		CorsHeaders:       cli.Config.CorsHeaders,
+		TCPKeepAlive:      time.Duration(cli.Config.APITCPKeepAlive) * time.Second,
+		StreamIdleTimeout: time.Duration(cli.Config.APIStreamIdleTimeout) * time.Second,
+		HTTP2:             cli.Config.APIHTTP2,
 	}
 
 	if cli.Config.TLS {
@@ -200,6 +222,9 @@ func (cli *DaemonCli) start() (err error) {
 		if err != nil {
 			return err
 		}
+		if cli.Config.APIHTTP2 {
+			tlsConfig.NextProtos = []string{http2.NextProtoTLS, "http/1.1"}
+		}
 		serverConfig.TLSConfig = tlsConfig
 	}
 
@@ -228,7 +253,7 @@ func (cli *DaemonCli) start() (err error) {
 		if proto == "tcp" && (serverConfig.TLSConfig == nil || serverConfig.TLSConfig.ClientAuth != tls.RequireAndVerifyClientCert) {
 			logrus.Warn("[!] DON'T BIND ON ANY IP ADDRESS WITHOUT setting -tlsverify IF YOU DON'T KNOW WHAT YOU'RE DOING [!]")
 		}
-		ls, err := listeners.Init(proto, addr, serverConfig.SocketGroup, serverConfig.TLSConfig)
+		ls, err := listeners.Init(proto, addr, serverConfig.SocketGroup, serverConfig.TLSConfig, serverConfig.TCPKeepAlive)
 		if err != nil {
 			return err
 		}
@@ -243,11 +268,15 @@ func (cli *DaemonCli) start() (err error) {
 		api.Accept(protoAddrParts[1], ls...)
 	}
 
-	if err := migrateKey(); err != nil {
+	if err := migrateKey(cli.commonFlags.TrustKey); err != nil {
 		return err
 	}
 	cli.TrustKeyPath = cli.commonFlags.TrustKey
 
+	setProxyEnv(cli.Config)
+	if err := setResolver(cli.Config); err != nil {
+		return err
+	}
 	registryService := registry.NewService(cli.Config.ServiceOptions)
 	containerdRemote, err := libcontainerd.New(cli.getLibcontainerdRoot(), cli.getPlatformRemoteOptions()...)
 	if err != nil {
@@ -267,7 +296,7 @@ func (cli *DaemonCli) start() (err error) {
 		"graphdriver": d.GraphDriverName(),
 	}).Info("Docker daemon")
 
-	cli.initMiddlewares(api, serverConfig)
+	cli.initMiddlewares(api, serverConfig, d)
 	initRouter(api, d)
 
 	cli.d = d
@@ -300,6 +329,48 @@ func (cli *DaemonCli) start() (err error) {
 	return nil
 }
 
+// setProxyEnv exports the proxy settings of config to the environment of the
+// daemon, where they are picked up by all its HTTP clients, such as the ones
+// pulling images and fetching remote build contexts, and by the commands it
+// runs, such as git. They override the environment the daemon was started
+// with.
+func setProxyEnv(config *daemon.Config) {
+	for key, value := range map[string]string{
+		"HTTP_PROXY":  config.HTTPProxy,
+		"HTTPS_PROXY": config.HTTPSProxy,
+		"NO_PROXY":    config.NoProxy,
+	} {
+		if value == "" {
+			continue
+		}
+		os.Setenv(key, value)
+		os.Setenv(strings.ToLower(key), value)
+	}
+}
+
+// setResolver makes the daemon resolve the hosts of its own requests, such
+// as the registries it pulls from and the remote build contexts it fetches,
+// with the nameservers and host entries of config instead of the resolver of
+// the host. The commands the daemon runs, such as git, still use the resolver
+// of the host.
+func setResolver(config *daemon.Config) error {
+	if len(config.DaemonDNS) == 0 && !config.DaemonDNSTCP && len(config.DaemonHosts) == 0 {
+		return nil
+	}
+	r, err := resolver.New(config.DaemonDNS, config.DaemonDNSTCP, config.DaemonHosts)
+	if err != nil {
+		return fmt.Errorf("Error configuring the resolver of the daemon: %v", err)
+	}
+	registry.SetResolver(r)
+	if t, ok := http.DefaultTransport.(*http.Transport); ok {
+		t.Dial = registry.Dialer(&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		})
+	}
+	return nil
+}
+
 func (cli *DaemonCli) reloadConfig() {
 	reload := func(config *daemon.Config) {
 		if err := cli.d.Reload(config); err != nil {
@@ -380,6 +451,22 @@ func loadDaemonCliConfig(config *daemon.Config, flags *flag.FlagSet, commonConfi
 		config.TLS = true
 	}
 
+	// the users of tenancy mode are identified by their client certificate
+	if config.Tenancy && !config.TLSVerify {
+		return nil, fmt.Errorf("--tenancy requires --tlsverify to identify the users by their TLS client certificate")
+	}
+
+	// HTTP/2 is negotiated during the TLS handshake
+	if config.APIHTTP2 && !config.TLS {
+		return nil, fmt.Errorf("--api-http2 requires --tls or --tlsverify")
+	}
+
+	for _, limit := range config.APILimits {
+		if _, err := middleware.ParseRateLimit(limit); err != nil {
+			return nil, err
+		}
+	}
+
 	// ensure that the log level is the one set after merging configurations
 	cliflags.SetDaemonLogLevel(config.LogLevel)
 
@@ -394,7 +481,9 @@ func initRouter(s *apiserver.Server, d *daemon.Daemon) {
 		image.NewRouter(d, decoder),
 		systemrouter.NewRouter(d),
 		volume.NewRouter(d),
-		build.NewRouter(dockerfile.NewBuildManager(d)),
+		job.NewRouter(d),
+		trustrouter.NewRouter(d),
+		build.NewRouter(dockerfile.NewBuildManager(d, d.BuildStaging()), d, d),
 	}
 	if d.NetworkControllerEnabled() {
 		routers = append(routers, network.NewRouter(d))
@@ -403,7 +492,7 @@ func initRouter(s *apiserver.Server, d *daemon.Daemon) {
 	s.InitRouter(utils.IsDebugEnabled(), routers...)
 }
 
-func (cli *DaemonCli) initMiddlewares(s *apiserver.Server, cfg *apiserver.Config) {
+func (cli *DaemonCli) initMiddlewares(s *apiserver.Server, cfg *apiserver.Config, d *daemon.Daemon) {
 	v := cfg.Version
 
 	vm := middleware.NewVersionMiddleware(v, api.DefaultVersion, api.MinVersion)
@@ -422,4 +511,20 @@ func (cli *DaemonCli) initMiddlewares(s *apiserver.Server, cfg *apiserver.Config
 		handleAuthorization := authorization.NewMiddleware(authZPlugins)
 		s.UseMiddleware(handleAuthorization)
 	}
+
+	if cli.Config.Tenancy {
+		t := middleware.NewTenancyMiddleware(d, cli.Config.TenancyAdmins)
+		s.UseMiddleware(t)
+	}
+
+	// the rate limits are evaluated first, rejecting the excess requests
+	// before any other processing
+	if len(cli.Config.APILimits) > 0 {
+		var limits []middleware.RateLimit
+		for _, spec := range cli.Config.APILimits {
+			limit, _ := middleware.ParseRateLimit(spec) // validated with the configuration
+			limits = append(limits, limit)
+		}
+		s.UseMiddleware(middleware.NewRateLimitMiddleware(limits))
+	}
 }
@@ -11,9 +11,12 @@ import (
 	"strconv"
 	"syscall"
 
+	cliflags "github.com/docker/docker/cli/flags"
 	"github.com/docker/docker/cmd/dockerd/hack"
 	"github.com/docker/docker/daemon"
 	"github.com/docker/docker/libcontainerd"
+	flag "github.com/docker/docker/pkg/mflag"
+	"github.com/docker/docker/pkg/rootless"
 	"github.com/docker/docker/pkg/system"
 	"github.com/docker/libnetwork/portallocator"
 )
@@ -47,6 +50,49 @@ func getDaemonConfDir() string {
 	return "/etc/docker"
 }
 
+// setupRootless runs the daemon in the namespaces of the rootless mode with
+// --rootless, and returns true once it exits. In these namespaces, the
+// directories and the socket of the daemon default to ones of the user.
+func (cli *DaemonCli) setupRootless(flags *flag.FlagSet) (bool, error) {
+	if !cli.Config.Rootless {
+		return false, nil
+	}
+	if !rootless.IsChild() {
+		return true, rootless.Run(os.Args[1:], cli.Config.RootlessNetHelper)
+	}
+
+	dataDir, runtimeDir, err := rootless.Dirs()
+	if err != nil {
+		return false, err
+	}
+	isSet := func(key string, names ...string) bool {
+		for _, name := range names {
+			if flags.IsSet(name) {
+				return true
+			}
+		}
+		return cli.Config.IsValueSet(key)
+	}
+	if !isSet("graph", "g", "-graph") {
+		cli.Config.Root = dataDir
+	}
+	if !isSet("exec-root", "-exec-root") {
+		cli.Config.ExecRoot = runtimeDir
+	}
+	if !isSet("pidfile", "p", "-pidfile") {
+		cli.Config.Pidfile = filepath.Join(runtimeDir, "docker.pid")
+	}
+	if len(cli.Config.Hosts) == 0 {
+		cli.Config.Hosts = []string{"unix://" + filepath.Join(runtimeDir, "docker.sock")}
+	}
+	// the other storage drivers need privileges of the host
+	if cli.Config.GraphDriver == "" {
+		cli.Config.GraphDriver = "vfs"
+	}
+	cli.commonFlags.TrustKey = filepath.Join(dataDir, cliflags.DefaultTrustKeyFile)
+	return false, nil
+}
+
 // setupConfigReloadTrap configures the USR2 signal to reload the configuration.
 func (cli *DaemonCli) setupConfigReloadTrap() {
 	c := make(chan os.Signal, 1)
@@ -66,9 +112,17 @@ func (cli *DaemonCli) getPlatformRemoteOptions() []libcontainerd.RemoteOption {
 		opts = append(opts, libcontainerd.WithRemoteAddr(cli.Config.ContainerdAddr))
 	} else {
 		opts = append(opts, libcontainerd.WithStartDaemon(true))
+		opts = append(opts, libcontainerd.WithOOMScore(cli.Config.ContainerdOOMScore))
 	}
+	var args []string
 	if daemon.UsingSystemd(cli.Config) {
-		args := []string{"--systemd-cgroup=true"}
+		args = append(args, "--systemd-cgroup=true")
+	}
+	if cli.Config.Rootless {
+		// let runc run the containers without access to the cgroups
+		args = append(args, "--rootless=true")
+	}
+	if len(args) > 0 {
 		opts = append(opts, libcontainerd.WithRuntimeArgs(args))
 	}
 	return opts
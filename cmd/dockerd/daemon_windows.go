@@ -4,10 +4,12 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"path/filepath"
 	"syscall"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/libcontainerd"
+	flag "github.com/docker/docker/pkg/mflag"
 	"github.com/docker/docker/pkg/system"
 )
 
@@ -45,6 +47,11 @@ func notifyShutdown(err error) {
 	}
 }
 
+// setupRootless does nothing on windows, which has no rootless mode.
+func (cli *DaemonCli) setupRootless(flags *flag.FlagSet) (bool, error) {
+	return false, nil
+}
+
 // setupConfigReloadTrap configures a Win32 event to reload the configuration.
 func (cli *DaemonCli) setupConfigReloadTrap() {
 	go func() {
@@ -70,7 +77,7 @@ func (cli *DaemonCli) getPlatformRemoteOptions() []libcontainerd.RemoteOption {
 // state. The Windows libcontainerd implementation does not need to write a spec
 // or state to disk, so this is a no-op.
 func (cli *DaemonCli) getLibcontainerdRoot() string {
-	return ""
+	return filepath.Join(cli.Config.Root, "libcontainerd")
 }
 
 func allocateDaemonPort(addr string) error {
@@ -13,9 +13,12 @@ import (
 )
 
 var (
-	daemonCli = NewDaemonCli()
-	flHelp    = flag.Bool([]string{"h", "-help"}, false, "Print usage")
-	flVersion = flag.Bool([]string{"v", "-version"}, false, "Print version information and quit")
+	daemonCli        = NewDaemonCli()
+	flHelp           = flag.Bool([]string{"h", "-help"}, false, "Print usage")
+	flVersion        = flag.Bool([]string{"v", "-version"}, false, "Print version information and quit")
+	flValidate       = flag.Bool([]string{"-validate"}, false, "Validate the daemon configuration and quit")
+	flMigrateStorage = flag.String([]string{"-migrate-storage"}, "", "Migrate the images and containers to a storage driver and quit")
+	flRelocateRoot   = flag.String([]string{"-relocate-root"}, "", "Fix up the references to a data root moved from a directory and quit")
 )
 
 func main() {
@@ -57,6 +60,18 @@ func main() {
 		return
 	}
 
+	if *flValidate {
+		os.Exit(daemonCli.validate(stdout))
+	}
+
+	if *flMigrateStorage != "" {
+		os.Exit(daemonCli.migrateStorage(*flMigrateStorage, stdout))
+	}
+
+	if *flRelocateRoot != "" {
+		os.Exit(daemonCli.relocateRoot(*flRelocateRoot, stdout))
+	}
+
 	// On Windows, this may be launching as a service or with an option to
 	// register the service.
 	stop, err := initService()
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon"
+	flag "github.com/docker/docker/pkg/mflag"
+	"github.com/docker/docker/pkg/pidfile"
+)
+
+// maintain runs the maintenance task fn on the configuration of the daemon
+// without starting the daemon, and returns the exit status of dockerd. The
+// pid file of the daemon is held while fn runs, so that the daemon can't be
+// started meanwhile.
+func (cli *DaemonCli) maintain(fn func(config *daemon.Config) error) int {
+	cli.commonFlags.PostParse()
+	config, err := loadDaemonCliConfig(cli.Config, flag.CommandLine, cli.commonFlags, *cli.configFile)
+	if err != nil {
+		logrus.Error(err)
+		return 1
+	}
+	if config.Pidfile != "" {
+		pf, err := pidfile.New(config.Pidfile)
+		if err != nil {
+			logrus.Errorf("Error running the maintenance task, the daemon must be stopped: %v", err)
+			return 1
+		}
+		defer func() {
+			if err := pf.Remove(); err != nil {
+				logrus.Error(err)
+			}
+		}()
+	}
+	if err := fn(config); err != nil {
+		logrus.Error(err)
+		return 1
+	}
+	return 0
+}
+
+// migrateStorage migrates the images and containers of the daemon to the
+// storage driver target, and writes its progress to w.
+func (cli *DaemonCli) migrateStorage(target string, w io.Writer) int {
+	return cli.maintain(func(config *daemon.Config) error {
+		if err := daemon.MigrateStorage(config, target, w); err != nil {
+			return fmt.Errorf("%v, run dockerd --migrate-storage=%s again to resume the migration", err, target)
+		}
+		return nil
+	})
+}
+
+// relocateRoot fixes up the references of the containers to the data root
+// moved from the directory from, and writes its progress to w.
+func (cli *DaemonCli) relocateRoot(from string, w io.Writer) int {
+	return cli.maintain(func(config *daemon.Config) error {
+		return daemon.RelocateDataRoot(config, from, w)
+	})
+}
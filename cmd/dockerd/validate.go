@@ -0,0 +1,129 @@
+package main
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	cliflags "github.com/docker/docker/cli/flags"
+	"github.com/docker/docker/daemon"
+	"github.com/docker/docker/opts"
+	flag "github.com/docker/docker/pkg/mflag"
+	"github.com/docker/go-connections/tlsconfig"
+)
+
+// certificateExpiryWarning is how long before the expiry of the certificate
+// of the daemon dockerd --validate starts warning about it.
+const certificateExpiryWarning = 30 * 24 * time.Hour
+
+// validationResult is the output of dockerd --validate. Valid is false when
+// one of the diagnostics is an error.
+type validationResult struct {
+	Valid       bool
+	Diagnostics []daemon.Diagnostic
+}
+
+// validate checks the configuration of the daemon without starting it,
+// writes the result to w as JSON, and returns the exit status of dockerd.
+func (cli *DaemonCli) validate(w io.Writer) int {
+	cli.commonFlags.PostParse()
+	result := validateDaemonConfig(cli.Config, flag.CommandLine, cli.commonFlags, *cli.configFile)
+	out, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		logrus.Error(err)
+		return 1
+	}
+	fmt.Fprintf(w, "%s\n", out)
+	if !result.Valid {
+		return 1
+	}
+	return 0
+}
+
+// validateDaemonConfig loads the configuration of the daemon from the flags
+// and configFile as the daemon would, and checks it.
+func validateDaemonConfig(config *daemon.Config, flags *flag.FlagSet, commonConfig *cliflags.CommonFlags, configFile string) validationResult {
+	result := validationResult{Valid: true, Diagnostics: []daemon.Diagnostic{}}
+	config, err := loadDaemonCliConfig(config, flags, commonConfig, configFile)
+	if err != nil {
+		result.Valid = false
+		result.Diagnostics = append(result.Diagnostics, daemon.Diagnostic{
+			Check:    "config",
+			Severity: daemon.DiagnosticError,
+			Message:  strings.TrimSpace(err.Error()),
+		})
+		return result
+	}
+
+	result.Diagnostics = append(result.Diagnostics, daemon.ValidateConfig(config)...)
+	for _, host := range config.Hosts {
+		if _, err := opts.ParseHost(config.TLS, host); err != nil {
+			result.Diagnostics = append(result.Diagnostics, daemon.Diagnostic{
+				Check:    "hosts",
+				Severity: daemon.DiagnosticError,
+				Message:  fmt.Sprintf("error parsing -H %s : %v", host, err),
+			})
+		}
+	}
+	result.Diagnostics = append(result.Diagnostics, validateTLS(config, time.Now())...)
+
+	for _, d := range result.Diagnostics {
+		if d.Severity == daemon.DiagnosticError {
+			result.Valid = false
+		}
+	}
+	return result
+}
+
+// validateTLS checks that the certificate, key and CA files of the daemon
+// can be loaded, and that its certificate is not expired at now, warning
+// when it is about to.
+func validateTLS(config *daemon.Config, now time.Time) []daemon.Diagnostic {
+	if !config.TLS {
+		return nil
+	}
+	tlsError := func(err error) []daemon.Diagnostic {
+		return []daemon.Diagnostic{{Check: "tls", Severity: daemon.DiagnosticError, Message: err.Error()}}
+	}
+
+	options := tlsconfig.Options{
+		CAFile:   config.CommonTLSOptions.CAFile,
+		CertFile: config.CommonTLSOptions.CertFile,
+		KeyFile:  config.CommonTLSOptions.KeyFile,
+	}
+	if _, err := tlsconfig.Server(options); err != nil {
+		return tlsError(err)
+	}
+
+	content, err := ioutil.ReadFile(options.CertFile)
+	if err != nil {
+		return tlsError(err)
+	}
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return tlsError(fmt.Errorf("no PEM certificate found in %s", options.CertFile))
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return tlsError(fmt.Errorf("parsing the certificate %s: %v", options.CertFile, err))
+	}
+	switch {
+	case now.After(cert.NotAfter):
+		return tlsError(fmt.Errorf("the certificate %s expired on %s", options.CertFile, cert.NotAfter.Format(time.RFC3339)))
+	case now.Before(cert.NotBefore):
+		return tlsError(fmt.Errorf("the certificate %s is not valid before %s", options.CertFile, cert.NotBefore.Format(time.RFC3339)))
+	case cert.NotAfter.Sub(now) < certificateExpiryWarning:
+		return []daemon.Diagnostic{{
+			Check:    "tls",
+			Severity: daemon.DiagnosticWarning,
+			Message:  fmt.Sprintf("the certificate %s expires on %s", options.CertFile, cert.NotAfter.Format(time.RFC3339)),
+		}}
+	}
+	return nil
+}
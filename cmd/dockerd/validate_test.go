@@ -0,0 +1,116 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	cliflags "github.com/docker/docker/cli/flags"
+	"github.com/docker/docker/daemon"
+	"github.com/docker/docker/pkg/mflag"
+)
+
+func TestValidateDaemonConfigInvalidFile(t *testing.T) {
+	f, err := ioutil.TempFile("", "docker-config-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.Write([]byte(`{"log-driver": `))
+	f.Close()
+
+	flags := mflag.NewFlagSet("test", mflag.ContinueOnError)
+	result := validateDaemonConfig(&daemon.Config{}, flags, &cliflags.CommonFlags{}, f.Name())
+	if result.Valid {
+		t.Fatal("expected an invalid configuration file to fail the validation")
+	}
+	if len(result.Diagnostics) != 1 || result.Diagnostics[0].Check != "config" {
+		t.Fatalf("expected a config diagnostic, got %+v", result.Diagnostics)
+	}
+}
+
+func TestValidateDaemonConfigLogDriver(t *testing.T) {
+	c := &daemon.Config{}
+	c.LogConfig.Type = "nonexistent"
+	flags := mflag.NewFlagSet("test", mflag.ContinueOnError)
+	result := validateDaemonConfig(c, flags, &cliflags.CommonFlags{}, "/tmp/fooobarbaz")
+	if result.Valid {
+		t.Fatal("expected an unknown log driver to fail the validation")
+	}
+	found := false
+	for _, d := range result.Diagnostics {
+		if d.Check == "log-driver" && d.Severity == daemon.DiagnosticError {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected a log-driver diagnostic, got %+v", result.Diagnostics)
+	}
+}
+
+func TestValidateTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docker-validate-tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "dockerd"},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(10 * 24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	if err := ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &daemon.Config{}
+	c.TLS = true
+	c.CommonTLSOptions.CertFile = certFile
+	c.CommonTLSOptions.KeyFile = keyFile
+
+	if diags := validateTLS(c, now); len(diags) != 1 || diags[0].Severity != daemon.DiagnosticWarning {
+		t.Fatalf("expected a warning about the expiry of the certificate, got %+v", diags)
+	}
+	if diags := validateTLS(c, now.Add(-2*time.Hour)); len(diags) != 1 || diags[0].Severity != daemon.DiagnosticError {
+		t.Fatalf("expected an error about a certificate not valid yet, got %+v", diags)
+	}
+	if diags := validateTLS(c, now.Add(20*24*time.Hour)); len(diags) != 1 || diags[0].Severity != daemon.DiagnosticError {
+		t.Fatalf("expected an error about an expired certificate, got %+v", diags)
+	}
+
+	c.CommonTLSOptions.KeyFile = filepath.Join(dir, "missing.pem")
+	if diags := validateTLS(c, now); len(diags) != 1 || diags[0].Severity != daemon.DiagnosticError {
+		t.Fatalf("expected an error about the missing key, got %+v", diags)
+	}
+}
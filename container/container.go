@@ -62,7 +62,8 @@ type CommonContainer struct {
 	Path            string
 	Args            []string
 	Config          *containertypes.Config
-	ImageID         image.ID `json:"Image"`
+	CreateConfig    *containertypes.Config `json:",omitempty"` // Config as passed on create, before being merged with the image's
+	ImageID         image.ID               `json:"Image"`
 	NetworkSettings *network.Settings
 	LogPath         string
 	Name            string
@@ -74,7 +75,9 @@ type CommonContainer struct {
 	HasBeenStartedBefore   bool
 	HasBeenManuallyStopped bool // used for unless-stopped restart policy
 	MountPoints            map[string]*volume.MountPoint
-	HostConfig             *containertypes.HostConfig `json:"-"` // do not serialize the host config in the json, otherwise we'll make the container unportable
+	CPUPlacement           *CPUPlacement              `json:",omitempty"` // CPUs allocated by the daemon for an auto cpuset
+	StartTimings           *StartTimings              `json:",omitempty"` // durations of the stages of the last start
+	HostConfig             *containertypes.HostConfig `json:"-"`          // do not serialize the host config in the json, otherwise we'll make the container unportable
 	ExecCommands           *exec.Store                `json:"-"`
 	// logDriver for closing
 	LogDriver      logger.Logger  `json:"-"`
@@ -83,6 +86,23 @@ type CommonContainer struct {
 	attachContext  *attachContext
 }
 
+// CPUPlacement is the placement on the CPUs of the host that the daemon
+// allocated to a container created with --cpuset-cpus=auto:<count>.
+type CPUPlacement struct {
+	CPUs     string // the CPUs assigned exclusively to the container, such as "4-7"
+	NUMANode int    // the NUMA node of the CPUs
+}
+
+// StartTimings are the durations of the stages of the start of a
+// container, from the start request until its process runs.
+type StartTimings struct {
+	ImageMount    time.Duration // mounting the root filesystem
+	NetworkSetup  time.Duration // setting up the networking
+	RuntimeCreate time.Duration // generating the runtime spec, and the runtime creating the container and its process
+	ProcessStart  time.Duration // attaching the streams of the process and marking the container running
+	Total         time.Duration
+}
+
 // NewBaseContainer creates a new container with its
 // basic configuration.
 func NewBaseContainer(id, root string) *Container {
@@ -298,6 +318,17 @@ func (container *Container) ConfigPath() (string, error) {
 	return container.GetRootResourcePath(configFileName)
 }
 
+// RelocatePaths rewrites with rewrite the paths on the host of the files of
+// the container, and of the sources of its mounts, after the data root of
+// the daemon is moved.
+func (container *Container) RelocatePaths(rewrite func(string) string) {
+	container.LogPath = rewrite(container.LogPath)
+	for _, m := range container.MountPoints {
+		m.Source = rewrite(m.Source)
+	}
+	container.relocatePlatformPaths(rewrite)
+}
+
 // StartLogger starts a new logger driver for the container.
 func (container *Container) StartLogger(cfg containertypes.LogConfig) (logger.Logger, error) {
 	c, err := logger.GetLogDriver(cfg.Type)
@@ -775,7 +806,8 @@ func (container *Container) BuildCreateEndpointOptions(n libnetwork.Network, epC
 		ipam := epConfig.IPAMConfig
 		if ipam != nil && (ipam.IPv4Address != "" || ipam.IPv6Address != "") {
 			createOptions = append(createOptions,
-				libnetwork.CreateOptionIpam(net.ParseIP(ipam.IPv4Address), net.ParseIP(ipam.IPv6Address), nil))
+				libnetwork.CreateOptionIpam(net.ParseIP(ipam.IPv4Address), net.ParseIP(ipam.IPv6Address), nil),
+				libnetwork.CreateOptionAddressOwner(container.ID))
 		}
 
 		for _, alias := range epConfig.Aliases {
@@ -807,6 +839,18 @@ func (container *Container) BuildCreateEndpointOptions(n libnetwork.Network, epC
 		}
 	}
 
+	if len(container.HostConfig.EgressAllow) > 0 {
+		rules, err := runconfigopts.ParseEgressRules(container.HostConfig.EgressAllow)
+		if err != nil {
+			return nil, err
+		}
+		createOptions = append(createOptions, libnetwork.CreateOptionEgressPolicy(rules))
+	}
+
+	if isolated, _ := n.Info().Isolation(); isolated {
+		createOptions = append(createOptions, libnetwork.CreateOptionContainerLabels(container.Config.Labels))
+	}
+
 	// Port-mapping rules belong to the container & applicable only to non-internal networks
 	portmaps := getSandboxPortMapInfo(sb)
 	if n.Info().Internal() || len(portmaps) > 0 {
@@ -902,6 +946,16 @@ func (container *Container) RestartManager(reset bool) restartmanager.RestartMan
 	return container.restartManager
 }
 
+// RestartStatus returns the status of the restart manager of the container,
+// and false if the container has none. Unlike RestartManager, it never
+// creates one.
+func (container *Container) RestartStatus() (restartmanager.Status, bool) {
+	if container.restartManager == nil {
+		return restartmanager.Status{}, false
+	}
+	return container.restartManager.Status(), true
+}
+
 type attachContext struct {
 	ctx    context.Context
 	cancel context.CancelFunc
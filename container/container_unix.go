@@ -17,6 +17,7 @@ import (
 	"github.com/docker/docker/pkg/system"
 	"github.com/docker/docker/utils"
 	"github.com/docker/docker/volume"
+	"github.com/docker/engine-api/types/blkiodev"
 	containertypes "github.com/docker/engine-api/types/container"
 	"github.com/opencontainers/runc/libcontainer/label"
 )
@@ -250,6 +251,15 @@ func (container *Container) IpcMounts() []Mount {
 	return mounts
 }
 
+// relocatePlatformPaths rewrites the paths of the network and IPC files of
+// the container.
+func (container *Container) relocatePlatformPaths(rewrite func(string) string) {
+	container.HostnamePath = rewrite(container.HostnamePath)
+	container.HostsPath = rewrite(container.HostsPath)
+	container.ResolvConfPath = rewrite(container.ResolvConfPath)
+	container.ShmPath = rewrite(container.ShmPath)
+}
+
 // UpdateContainer updates configuration of a container.
 func (container *Container) UpdateContainer(hostConfig *containertypes.HostConfig) error {
 	container.Lock()
@@ -288,6 +298,13 @@ func (container *Container) UpdateContainer(hostConfig *containertypes.HostConfi
 	if resources.KernelMemory != 0 {
 		cResources.KernelMemory = resources.KernelMemory
 	}
+	if resources.MemorySwappiness != nil {
+		cResources.MemorySwappiness = resources.MemorySwappiness
+	}
+	cResources.BlkioDeviceReadBps = updateThrottleDevices(cResources.BlkioDeviceReadBps, resources.BlkioDeviceReadBps)
+	cResources.BlkioDeviceWriteBps = updateThrottleDevices(cResources.BlkioDeviceWriteBps, resources.BlkioDeviceWriteBps)
+	cResources.BlkioDeviceReadIOps = updateThrottleDevices(cResources.BlkioDeviceReadIOps, resources.BlkioDeviceReadIOps)
+	cResources.BlkioDeviceWriteIOps = updateThrottleDevices(cResources.BlkioDeviceWriteIOps, resources.BlkioDeviceWriteIOps)
 
 	// update HostConfig of container
 	if hostConfig.RestartPolicy.Name != "" {
@@ -302,6 +319,34 @@ func (container *Container) UpdateContainer(hostConfig *containertypes.HostConfi
 	return nil
 }
 
+// updateThrottleDevices returns the block IO throttling of devs updated with
+// the throttling of updates. A rate of 0 removes the throttling of a device.
+func updateThrottleDevices(devs, updates []*blkiodev.ThrottleDevice) []*blkiodev.ThrottleDevice {
+	if len(updates) == 0 {
+		return devs
+	}
+	// devs may be shared with a backup of the host config
+	devs = append([]*blkiodev.ThrottleDevice(nil), devs...)
+	for _, u := range updates {
+		updated := false
+		for i, d := range devs {
+			if d.Path == u.Path {
+				if u.Rate == 0 {
+					devs = append(devs[:i], devs[i+1:]...)
+				} else {
+					devs[i] = u
+				}
+				updated = true
+				break
+			}
+		}
+		if !updated && u.Rate != 0 {
+			devs = append(devs, u)
+		}
+	}
+	return devs
+}
+
 func detachMounted(path string) error {
 	return syscall.Unmount(path, syscall.MNT_DETACH)
 }
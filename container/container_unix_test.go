@@ -0,0 +1,28 @@
+// +build linux freebsd
+
+package container
+
+import (
+	"testing"
+
+	"github.com/docker/engine-api/types/blkiodev"
+)
+
+func TestUpdateThrottleDevices(t *testing.T) {
+	devs := []*blkiodev.ThrottleDevice{
+		{Path: "/dev/sda", Rate: 1024},
+		{Path: "/dev/sdb", Rate: 2048},
+	}
+	updated := updateThrottleDevices(devs, []*blkiodev.ThrottleDevice{
+		{Path: "/dev/sda", Rate: 0},
+		{Path: "/dev/sdb", Rate: 4096},
+		{Path: "/dev/sdc", Rate: 512},
+		{Path: "/dev/sdd", Rate: 0},
+	})
+	if len(updated) != 2 || updated[0].Path != "/dev/sdb" || updated[0].Rate != 4096 || updated[1].Path != "/dev/sdc" {
+		t.Fatalf("Unexpected throttling %v", updated)
+	}
+	if devs[0].Path != "/dev/sda" || devs[0].Rate != 1024 {
+		t.Fatal("Expected the original throttling to be preserved")
+	}
+}
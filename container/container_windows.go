@@ -57,6 +57,14 @@ func (container *Container) TmpfsMounts() []Mount {
 	return nil
 }
 
+// relocatePlatformPaths rewrites the paths of the network files of the
+// container.
+func (container *Container) relocatePlatformPaths(rewrite func(string) string) {
+	container.HostnamePath = rewrite(container.HostnamePath)
+	container.HostsPath = rewrite(container.HostsPath)
+	container.ResolvConfPath = rewrite(container.ResolvConfPath)
+}
+
 // UpdateContainer updates configuration of a container
 func (container *Container) UpdateContainer(hostConfig *containertypes.HostConfig) error {
 	container.Lock()
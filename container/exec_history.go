@@ -0,0 +1,68 @@
+package container
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/docker/docker/pkg/ioutils"
+	"github.com/docker/engine-api/types"
+)
+
+const execHistoryFileName = "execs.json"
+
+// ExecHistory returns the execs run in the container, from the oldest.
+func (container *Container) ExecHistory() ([]types.ExecRecord, error) {
+	pth, err := container.GetRootResourcePath(execHistoryFileName)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(pth)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []types.ExecRecord{}, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var history []types.ExecRecord
+	if err := json.NewDecoder(f).Decode(&history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// RecordExec adds record to the exec history of the container, replacing
+// the record of the same exec if any, and drops the oldest records beyond
+// size.
+func (container *Container) RecordExec(record types.ExecRecord, size int) error {
+	history, err := container.ExecHistory()
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range history {
+		if history[i].ID == record.ID {
+			history[i] = record
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		history = append(history, record)
+	}
+	if len(history) > size {
+		history = history[len(history)-size:]
+	}
+
+	pth, err := container.GetRootResourcePath(execHistoryFileName)
+	if err != nil {
+		return err
+	}
+	f, err := ioutils.NewAtomicFileWriter(pth, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return json.NewEncoder(f).Encode(history)
+}
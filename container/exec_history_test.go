@@ -0,0 +1,44 @@
+package container
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/docker/engine-api/types"
+)
+
+func TestRecordExec(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-container-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	c := NewBaseContainer("id", root)
+
+	history, err := c.ExecHistory()
+	if err != nil || len(history) != 0 {
+		t.Fatalf("expected an empty history, got %v, %v", history, err)
+	}
+
+	for _, id := range []string{"exec1", "exec2", "exec3"} {
+		if err := c.RecordExec(types.ExecRecord{ID: id}, 2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	exitCode := 1
+	if err := c.RecordExec(types.ExecRecord{ID: "exec3", ExitCode: &exitCode}, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	history, err = c.ExecHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 || history[0].ID != "exec2" || history[1].ID != "exec3" {
+		t.Fatalf("expected the history of exec2 and exec3, got %v", history)
+	}
+	if history[1].ExitCode == nil || *history[1].ExitCode != 1 {
+		t.Fatalf("expected the exit code of exec3 to be updated, got %v", history[1].ExitCode)
+	}
+}
@@ -26,16 +26,37 @@ type State struct {
 	Error             string // contains last known error when starting the container
 	StartedAt         time.Time
 	FinishedAt        time.Time
+	JobStatus         string // completion status of the containers in job mode
 	waitChan          chan struct{}
+	operations        chan struct{}
+	starting          bool // set while the runtime creates the container, see SetStarting
 }
 
 // NewState creates a default state object with a fresh channel for state changes.
 func NewState() *State {
 	return &State{
-		waitChan: make(chan struct{}),
+		waitChan:   make(chan struct{}),
+		operations: make(chan struct{}, 1),
 	}
 }
 
+// BeginOperation waits until the lifecycle operations (start, kill, pause,
+// ...) submitted before it on the container are done, and marks a new one
+// as in progress. Operations are admitted in the order they are submitted.
+// Lifecycle operations only take the state lock to read or update the
+// state, not while they wait for the container runtime, so that a slow
+// runtime call does not block listing or inspecting containers. Every
+// BeginOperation must be paired with a call to EndOperation.
+func (s *State) BeginOperation() {
+	s.operations <- struct{}{}
+}
+
+// EndOperation marks the lifecycle operation in progress as done, letting
+// the next queued operation proceed.
+func (s *State) EndOperation() {
+	<-s.operations
+}
+
 // String returns a human-readable description of the state
 func (s *State) String() string {
 	if s.Running {
@@ -171,6 +192,26 @@ func (s *State) SetRunning(pid int, initial bool) {
 	}
 }
 
+// SetStarting marks the container as being created by the runtime, without
+// locking. The lock is released while the runtime creates the container, and
+// the start is responsible for the failures and the release of the
+// resources of the container until ResetStarting is called.
+func (s *State) SetStarting() {
+	s.starting = true
+}
+
+// ResetStarting marks the container as no longer being created by the
+// runtime, without locking.
+func (s *State) ResetStarting() {
+	s.starting = false
+}
+
+// IsStarting returns whether the runtime is creating the container, without
+// locking.
+func (s *State) IsStarting() bool {
+	return s.starting
+}
+
 // SetStoppedLocking locks the container state is sets it to "stopped".
 func (s *State) SetStoppedLocking(exitStatus *ExitStatus) {
 	s.Lock()
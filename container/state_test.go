@@ -85,3 +85,32 @@ func TestStateTimeoutWait(t *testing.T) {
 	}
 
 }
+
+func TestStateOperationDoesNotHoldLock(t *testing.T) {
+	s := NewState()
+	s.BeginOperation()
+
+	// The state can be read while an operation is in progress.
+	if s.IsRunning() {
+		t.Fatal("Expected state not to be running")
+	}
+
+	started := make(chan struct{})
+	go func() {
+		s.BeginOperation()
+		close(started)
+		s.EndOperation()
+	}()
+	select {
+	case <-started:
+		t.Fatal("Operation started while another one was in progress")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	s.EndOperation()
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("Operation did not start after the previous one ended")
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/docker/docker/container"
 	"github.com/docker/docker/daemon/logger"
 	"github.com/docker/docker/errors"
+	"github.com/docker/docker/pkg/ioutils"
 	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/docker/docker/pkg/term"
 )
@@ -40,7 +41,18 @@ func (daemon *Daemon) ContainerAttach(prefixOrName string, c *backend.ContainerA
 	}
 	defer inStream.Close()
 
-	if !container.Config.Tty && c.MuxStreams {
+	muxed := !container.Config.Tty && c.MuxStreams
+
+	// the streams are closed once IdleTimeout elapsed without any traffic
+	if c.IdleTimeout > 0 {
+		watcher := ioutils.NewIdleWatcher(c.IdleTimeout, func() { c.CloseIdle(muxed) })
+		defer watcher.Stop()
+		inStream = ioutils.NewReadCloserWrapper(watcher.Reader(inStream), inStream.Close)
+		outStream = watcher.Writer(outStream)
+		errStream = watcher.Writer(errStream)
+	}
+
+	if muxed {
 		errStream = stdcopy.NewStdWriter(errStream, stdcopy.Stderr)
 		outStream = stdcopy.NewStdWriter(outStream, stdcopy.Stdout)
 	}
@@ -0,0 +1,84 @@
+package daemon
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/volume"
+	"github.com/docker/engine-api/types"
+	containertypes "github.com/docker/engine-api/types/container"
+	"github.com/docker/engine-api/types/events"
+)
+
+// riskyOptions returns the options of hostConfig that weaken the isolation
+// of a container from its host, and allow its processes to escape it.
+func riskyOptions(hostConfig *containertypes.HostConfig) []string {
+	if hostConfig == nil {
+		return nil
+	}
+	var options []string
+	if hostConfig.Privileged {
+		options = append(options, "privileged")
+	}
+	if hostConfig.PidMode.IsHost() {
+		options = append(options, "pid=host")
+	}
+	if hostConfig.IpcMode.IsHost() {
+		options = append(options, "ipc=host")
+	}
+	if hostConfig.NetworkMode.IsHost() {
+		options = append(options, "network=host")
+	}
+	for _, device := range hostConfig.Devices {
+		options = append(options, "device="+device.PathOnHost)
+	}
+	for _, cap := range hostConfig.CapAdd {
+		switch strings.TrimPrefix(strings.ToUpper(cap), "CAP_") {
+		case "SYS_ADMIN", "ALL":
+			options = append(options, "cap-add="+cap)
+		}
+	}
+	for _, bind := range hostConfig.Binds {
+		// the named volumes are not bind mounts of the host
+		mp, err := volume.ParseMountSpec(bind, hostConfig.VolumeDriver)
+		if err != nil || mp.Source == "" {
+			continue
+		}
+		if filepath.Base(mp.Source) == "docker.sock" {
+			options = append(options, "volume="+mp.Source)
+		}
+	}
+	return options
+}
+
+// auditOptions emits a security event, with the identity of requester, for
+// the use of risky options by action on c.
+func (daemon *Daemon) auditOptions(c *container.Container, action string, options []string, requester types.Requester) {
+	if len(options) == 0 {
+		return
+	}
+	attributes := map[string]string{
+		"container": c.ID,
+		"name":      strings.TrimLeft(c.Name, "/"),
+		"image":     c.Config.Image,
+		"options":   strings.Join(options, ","),
+	}
+	if requester.User != "" {
+		attributes["user"] = requester.User
+	}
+	if requester.RemoteAddr != "" {
+		attributes["remoteAddr"] = requester.RemoteAddr
+	}
+	if requester.UserAgent != "" {
+		attributes["userAgent"] = requester.UserAgent
+	}
+	logrus.Infof("Security audit: %s of container %s with %s requested by user %q from %q", action, c.ID, attributes["options"], requester.User, requester.RemoteAddr)
+
+	actor := events.Actor{
+		ID:         c.ID,
+		Attributes: attributes,
+	}
+	daemon.EventsService.Log(action, events.SecurityEventType, actor)
+}
@@ -0,0 +1,74 @@
+// +build linux
+
+package daemon
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/events"
+	"github.com/docker/engine-api/types"
+	containertypes "github.com/docker/engine-api/types/container"
+	eventtypes "github.com/docker/engine-api/types/events"
+)
+
+func TestRiskyOptions(t *testing.T) {
+	hostConfig := &containertypes.HostConfig{
+		Privileged:  true,
+		PidMode:     "host",
+		IpcMode:     "host",
+		NetworkMode: "host",
+		Binds:       []string{"/var/run/docker.sock:/var/run/docker.sock", "/data:/data", "cache:/cache"},
+		Resources: containertypes.Resources{
+			Devices: []containertypes.DeviceMapping{{PathOnHost: "/dev/sda", PathInContainer: "/dev/sda"}},
+		},
+		CapAdd: []string{"NET_ADMIN", "cap_sys_admin"},
+	}
+	expected := []string{"privileged", "pid=host", "ipc=host", "network=host", "device=/dev/sda", "cap-add=cap_sys_admin", "volume=/var/run/docker.sock"}
+	if options := riskyOptions(hostConfig); !reflect.DeepEqual(options, expected) {
+		t.Fatalf("expected %v, got %v", expected, options)
+	}
+	if options := riskyOptions(&containertypes.HostConfig{Binds: []string{"/data:/data"}}); len(options) != 0 {
+		t.Fatalf("expected no risky option, got %v", options)
+	}
+}
+
+func TestAuditOptions(t *testing.T) {
+	e := events.New()
+	_, l, _ := e.Subscribe()
+	defer e.Evict(l)
+
+	c := &container.Container{
+		CommonContainer: container.CommonContainer{
+			ID:     "container_id",
+			Name:   "/container_name",
+			Config: &containertypes.Config{Image: "busybox"},
+		},
+	}
+	daemon := &Daemon{EventsService: e}
+	daemon.auditOptions(c, "create", nil, types.Requester{})
+	daemon.auditOptions(c, "create", []string{"privileged", "pid=host"}, types.Requester{User: "alice", RemoteAddr: "10.0.0.2:51234"})
+
+	select {
+	case ev := <-l:
+		msg := ev.(eventtypes.Message)
+		if msg.Type != eventtypes.SecurityEventType || msg.Action != "create" || msg.Actor.ID != "container_id" {
+			t.Fatalf("unexpected event %+v", msg)
+		}
+		expected := map[string]string{
+			"container":  "container_id",
+			"name":       "container_name",
+			"image":      "busybox",
+			"options":    "privileged,pid=host",
+			"user":       "alice",
+			"remoteAddr": "10.0.0.2:51234",
+		}
+		if !reflect.DeepEqual(msg.Actor.Attributes, expected) {
+			t.Fatalf("expected %v, got %v", expected, msg.Actor.Attributes)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("LogEvent test timed out")
+	}
+}
@@ -0,0 +1,82 @@
+package daemon
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/docker/docker/api/types/backend"
+	"github.com/docker/docker/errors"
+	"github.com/docker/engine-api/types"
+)
+
+const (
+	// defaultBatchParallelism is the number of containers a batched
+	// operation handles at the same time when the request doesn't say.
+	defaultBatchParallelism = 10
+	// maxBatchParallelism caps the parallelism requested by the clients.
+	maxBatchParallelism = 100
+)
+
+// ContainersBatch runs action, "kill", "stop" or "restart", on all the
+// containers matching config.Filters, at most config.Parallelism at a time,
+// and reports the result for each of them. Like `docker ps`, kill and stop
+// only select the running containers unless a status filter is given. The
+// failure of a container is reported in its result and doesn't stop the
+// batch.
+func (daemon *Daemon) ContainersBatch(action string, config *backend.ContainersBatchConfig) (*types.ContainersBatchReport, error) {
+	var run func(name string) error
+	switch action {
+	case "kill":
+		run = func(name string) error { return daemon.ContainerKill(name, config.Signal) }
+	case "stop":
+		run = func(name string) error { return daemon.ContainerStop(name, config.Timeout, nil) }
+	case "restart":
+		run = func(name string) error { return daemon.ContainerRestart(name, config.Timeout, nil) }
+	default:
+		return nil, errors.NewBadRequestError(fmt.Errorf("invalid batched action: %s", action))
+	}
+
+	parallelism := config.Parallelism
+	if parallelism < 0 || parallelism > maxBatchParallelism {
+		return nil, errors.NewBadRequestError(fmt.Errorf("invalid parallelism %d, it must be between 1 and %d", parallelism, maxBatchParallelism))
+	}
+	if parallelism == 0 {
+		parallelism = defaultBatchParallelism
+	}
+
+	containers, err := daemon.Containers(&types.ContainerListOptions{
+		All:    action == "restart",
+		Filter: config.Filters,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &types.ContainersBatchReport{
+		Results: make([]types.ContainerBatchResult, len(containers)),
+	}
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, parallelism)
+	for i, c := range containers {
+		result := &report.Results[i]
+		result.ID = c.ID
+		if len(c.Names) > 0 {
+			result.Name = strings.TrimPrefix(c.Names[0], "/")
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(id string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := run(id); err != nil {
+				result.Error = err.Error()
+			}
+		}(c.ID)
+	}
+	wg.Wait()
+	return report, nil
+}
@@ -0,0 +1,254 @@
+package daemon
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/layer"
+	"github.com/docker/docker/pkg/directory"
+	"github.com/docker/docker/pkg/ioutils"
+	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/filters"
+)
+
+// acceptedBuildCachePruneFilterTags are the filters accepted when pruning
+// the build cache.
+var acceptedBuildCachePruneFilterTags = map[string]bool{
+	"until": true,
+}
+
+// buildCacheVolumePrefix prefixes the names of the volumes backing the
+// RUN --mount=type=cache mounts of the builder.
+const buildCacheVolumePrefix = "build-cache-"
+
+// buildCacheRecord records when a build cache image was created, and last
+// used by a build.
+type buildCacheRecord struct {
+	Created  time.Time
+	LastUsed time.Time
+}
+
+// buildCache holds the records of the images committed by builds, stored
+// as JSON in a file.
+type buildCache struct {
+	mu      sync.Mutex
+	path    string
+	records map[image.ID]*buildCacheRecord
+}
+
+// newBuildCache returns the build cache stored at path.
+func newBuildCache(path string) (*buildCache, error) {
+	c := &buildCache{
+		path:    path,
+		records: make(map[image.ID]*buildCacheRecord),
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &c.records); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// add records id as created by a build.
+func (c *buildCache) add(id image.ID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now().UTC()
+	c.records[id] = &buildCacheRecord{Created: now, LastUsed: now}
+	c.save()
+}
+
+// use records that a build used id, if it is a build cache image.
+func (c *buildCache) use(id image.ID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if r, ok := c.records[id]; ok {
+		r.LastUsed = time.Now().UTC()
+		c.save()
+	}
+}
+
+// remove forgets the records of ids.
+func (c *buildCache) remove(ids ...image.ID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, id := range ids {
+		delete(c.records, id)
+	}
+	c.save()
+}
+
+// list returns a copy of the records.
+func (c *buildCache) list() map[image.ID]buildCacheRecord {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	records := make(map[image.ID]buildCacheRecord, len(c.records))
+	for id, r := range c.records {
+		records[id] = *r
+	}
+	return records
+}
+
+// save writes the records to disk. c must be locked.
+func (c *buildCache) save() {
+	b, err := json.Marshal(c.records)
+	if err == nil {
+		err = ioutils.AtomicWriteFile(c.path, b, 0600)
+	}
+	if err != nil {
+		logrus.Errorf("Failed to save the build cache records: %v", err)
+	}
+}
+
+// buildCacheEntry is a build cache image considered for pruning.
+type buildCacheEntry struct {
+	id       image.ID
+	lastUsed time.Time
+	size     int64
+}
+
+// byLastUsed sorts build cache entries from the least recently used.
+type byLastUsed []buildCacheEntry
+
+func (s byLastUsed) Len() int           { return len(s) }
+func (s byLastUsed) Less(i, j int) bool { return s[i].lastUsed.Before(s[j].lastUsed) }
+func (s byLastUsed) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// BuildCachePrune removes the images committed by builds that are no longer
+// tagged, used by containers or the parent of another image, from the least
+// recently used. Only the images last used before the "until" filter are
+// removed, and with keepStorage, only until the build cache fits in
+// keepStorage bytes.
+func (daemon *Daemon) BuildCachePrune(pruneFilters filters.Args, keepStorage int64) (*types.BuildCachePruneReport, error) {
+	if err := pruneFilters.Validate(acceptedBuildCachePruneFilterTags); err != nil {
+		return nil, err
+	}
+	until, err := getUntilFromPruneFilters(pruneFilters)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		entries []buildCacheEntry
+		stale   []image.ID
+		total   int64
+	)
+	for id, r := range daemon.buildCache.list() {
+		img, err := daemon.imageStore.Get(id)
+		if err != nil {
+			stale = append(stale, id)
+			continue
+		}
+		entry := buildCacheEntry{id: id, lastUsed: r.LastUsed, size: daemon.addedLayerSize(img)}
+		total += entry.size
+		if until.IsZero() || r.LastUsed.Before(until) {
+			entries = append(entries, entry)
+		}
+	}
+	daemon.buildCache.remove(stale...)
+	sort.Sort(byLastUsed(entries))
+
+	report := &types.BuildCachePruneReport{}
+	// Removing an image makes its parent removable, so candidates are
+	// tried again until none can be removed.
+	for removed := true; removed && total > keepStorage; {
+		removed = false
+		for i, entry := range entries {
+			if entry.id == "" || !daemon.buildCacheRemovable(entry.id) {
+				continue
+			}
+			if _, err := daemon.ImageDelete(entry.id.String(), false, false); err != nil {
+				logrus.Warnf("prune: could not remove build cache image %s: %v", entry.id, err)
+				entries[i].id = ""
+				continue
+			}
+			daemon.buildCache.remove(entry.id)
+			entries[i].id = ""
+			total -= entry.size
+			report.CachesDeleted = append(report.CachesDeleted, entry.id.String())
+			report.SpaceReclaimed += uint64(entry.size)
+			removed = true
+			if total <= keepStorage {
+				break
+			}
+		}
+	}
+
+	// The cache mounts have no last use time, so they are only removed when
+	// the whole cache is pruned.
+	if until.IsZero() && keepStorage == 0 {
+		daemon.pruneBuildCacheVolumes(report)
+	}
+	return report, nil
+}
+
+// pruneBuildCacheVolumes removes the volumes of the builder cache mounts that
+// are not in use, adding them to report.
+func (daemon *Daemon) pruneBuildCacheVolumes(report *types.BuildCachePruneReport) {
+	vols, _, err := daemon.volumes.List()
+	if err != nil {
+		logrus.Warnf("prune: could not list build cache volumes: %v", err)
+		return
+	}
+	for _, v := range vols {
+		if !strings.HasPrefix(v.Name(), buildCacheVolumePrefix) {
+			continue
+		}
+		size, _ := directory.Size(v.Path())
+		if err := daemon.VolumeRm(v.Name()); err != nil {
+			logrus.Debugf("prune: could not remove build cache volume %s: %v", v.Name(), err)
+			continue
+		}
+		report.CachesDeleted = append(report.CachesDeleted, v.Name())
+		report.SpaceReclaimed += uint64(size)
+	}
+}
+
+// buildCacheRemovable returns whether the image id is not tagged, used by a
+// container, or the parent of another image.
+func (daemon *Daemon) buildCacheRemovable(id image.ID) bool {
+	if !daemon.imageIsDangling(id) {
+		return false
+	}
+	for _, c := range daemon.List() {
+		if c.ImageID == id {
+			return false
+		}
+	}
+	return true
+}
+
+// addedLayerSize returns the size of the layer img adds to its parent, or 0
+// if it only changes the configuration.
+func (daemon *Daemon) addedLayerSize(img *image.Image) int64 {
+	if len(img.History) > 0 && img.History[len(img.History)-1].EmptyLayer {
+		return 0
+	}
+	chainID := img.RootFS.ChainID()
+	if chainID == "" {
+		return 0
+	}
+	l, err := daemon.layerStore.Get(chainID)
+	if err != nil {
+		return 0
+	}
+	defer layer.ReleaseAndLog(daemon.layerStore, l)
+	size, err := l.DiffSize()
+	if err != nil {
+		return 0
+	}
+	return size
+}
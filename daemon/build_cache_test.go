@@ -0,0 +1,47 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/image"
+)
+
+func TestBuildCacheRecords(t *testing.T) {
+	dir, err := ioutil.TempDir("", "build-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "buildcache.json")
+
+	c, err := newBuildCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.add("sha256:a")
+	c.add("sha256:b")
+	c.use("sha256:c")
+	created := c.list()["sha256:a"]
+	c.use("sha256:a")
+	c.remove("sha256:b")
+
+	// The records are kept across restarts.
+	c, err = newBuildCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := c.list()
+	if len(records) != 1 {
+		t.Fatalf("expected a single record, got %v", records)
+	}
+	r, ok := records[image.ID("sha256:a")]
+	if !ok {
+		t.Fatalf("expected a record for sha256:a, got %v", records)
+	}
+	if !r.Created.Equal(created.Created) || r.LastUsed.Before(created.LastUsed) {
+		t.Fatalf("expected sha256:a to be created at %v and used since, got %+v", created.Created, r)
+	}
+}
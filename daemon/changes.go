@@ -2,14 +2,19 @@ package daemon
 
 import "github.com/docker/docker/pkg/archive"
 
-// ContainerChanges returns a list of container fs changes
-func (daemon *Daemon) ContainerChanges(name string) ([]archive.Change, error) {
+// ContainerChanges calls fn for each change of the container's filesystem
+// from its image. The changes are not collected first, so that containers
+// with many changes can be walked without holding them all in memory.
+func (daemon *Daemon) ContainerChanges(name string, fn func(archive.Change) error) error {
 	container, err := daemon.GetContainer(name)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
+	// The walk may take long and is paced by fn, so the container lock is
+	// only held to look up its layer.
 	container.Lock()
-	defer container.Unlock()
-	return daemon.changes(container)
+	rwlayer := container.RWLayer
+	container.Unlock()
+	return rwlayer.WalkChanges(fn)
 }
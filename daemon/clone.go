@@ -0,0 +1,161 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/chrootarchive"
+	"github.com/docker/docker/pkg/stringid"
+	"github.com/docker/docker/volume"
+	"github.com/docker/engine-api/types"
+	networktypes "github.com/docker/engine-api/types/network"
+)
+
+// ContainerClone creates a new stopped container with the configuration the
+// container name was created with, from the same image, and connected to the
+// same networks. The clone shares the named volumes and bind mounts of the
+// original. Its anonymous volumes are empty unless config.CopyVolumes is set,
+// and its filesystem is the image's unless config.CopyLayer is set.
+func (daemon *Daemon) ContainerClone(name string, config *types.ContainerCloneConfig) (types.ContainerCreateResponse, error) {
+	src, err := daemon.GetContainer(name)
+	if err != nil {
+		return types.ContainerCreateResponse{}, err
+	}
+
+	src.Lock()
+	params, warnings, err := createConfigOf(src)
+	if err != nil {
+		src.Unlock()
+		return types.ContainerCreateResponse{}, err
+	}
+	anonymous := anonymousVolumes(src)
+	networks := make(map[string]*networktypes.EndpointSettings)
+	for nw, ep := range src.NetworkSettings.Networks {
+		if ep != nil {
+			// Addresses and aliases are left for the clone to get its own.
+			networks[nw] = &networktypes.EndpointSettings{Links: ep.Links}
+		}
+	}
+	imageID := src.ImageID
+	src.Unlock()
+
+	params.Name = config.Name
+	if img, err := daemon.GetImage(params.Config.Image); err != nil || img.ID() != imageID {
+		// The reference was moved to another image since.
+		params.Config.Image = imageID.String()
+	}
+	primary := params.HostConfig.NetworkMode.NetworkName()
+	if ep, ok := networks[primary]; ok {
+		params.NetworkingConfig = &networktypes.NetworkingConfig{
+			EndpointsConfig: map[string]*networktypes.EndpointSettings{primary: ep},
+		}
+		delete(networks, primary)
+	}
+
+	resp, err := daemon.ContainerCreate(params)
+	if err != nil {
+		return resp, err
+	}
+	resp.Warnings = append(warnings, resp.Warnings...)
+
+	clone, err := daemon.GetContainer(resp.ID)
+	if err != nil {
+		return resp, err
+	}
+	for nw, epConfig := range networks {
+		if err := daemon.ConnectToNetwork(clone, nw, epConfig); err != nil {
+			resp.Warnings = append(resp.Warnings, fmt.Sprintf("Could not connect to network %s: %v", nw, err))
+		}
+	}
+
+	if err := daemon.copyContainerData(src, clone, anonymous, config); err != nil {
+		if rmErr := daemon.ContainerRm(clone.ID, &types.ContainerRmConfig{ForceRemove: true, RemoveVolume: true}); rmErr != nil {
+			resp.Warnings = append(resp.Warnings, fmt.Sprintf("Could not remove the incomplete clone %s: %v", clone.ID, rmErr))
+		}
+		return types.ContainerCreateResponse{Warnings: resp.Warnings}, err
+	}
+
+	daemon.LogContainerEventWithAttributes(clone, "clone", map[string]string{
+		"sourceID": src.ID,
+	})
+	return resp, nil
+}
+
+// copyContainerData copies the writable layer and the anonymous volumes of
+// src to clone, as requested by config.
+func (daemon *Daemon) copyContainerData(src, clone *container.Container, anonymous map[string]*volume.MountPoint, config *types.ContainerCloneConfig) error {
+	if config.CopyLayer {
+		if err := daemon.copyRWLayer(src, clone); err != nil {
+			return fmt.Errorf("Error copying the filesystem of %s: %v", src.ID, err)
+		}
+	}
+	if config.CopyVolumes {
+		for dest, mp := range anonymous {
+			target, ok := clone.MountPoints[dest]
+			if !ok {
+				continue
+			}
+			if err := daemon.copyVolume(mp, target); err != nil {
+				return fmt.Errorf("Error copying volume %s of %s: %v", dest, src.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// copyRWLayer applies the changes src made to its image to the filesystem of
+// dst.
+func (daemon *Daemon) copyRWLayer(src, dst *container.Container) error {
+	diff, err := src.RWLayer.TarStream()
+	if err != nil {
+		return err
+	}
+	defer diff.Close()
+
+	if err := daemon.Mount(dst); err != nil {
+		return err
+	}
+	defer daemon.Unmount(dst)
+
+	uidMaps, gidMaps := daemon.GetUIDGIDMaps()
+	_, err = chrootarchive.ApplyUncompressedLayer(dst.BaseFS, diff, &archive.TarOptions{
+		UIDMaps: uidMaps,
+		GIDMaps: gidMaps,
+	})
+	return err
+}
+
+// copyVolume copies the content of the volume of src into the volume of dst.
+func (daemon *Daemon) copyVolume(src, dst *volume.MountPoint) error {
+	srcVolume, err := daemon.mountPointVolume(src)
+	if err != nil {
+		return err
+	}
+	dstVolume, err := daemon.mountPointVolume(dst)
+	if err != nil {
+		return err
+	}
+
+	id := stringid.GenerateNonCryptoID()
+	srcPath, err := srcVolume.Mount(id)
+	if err != nil {
+		return err
+	}
+	defer srcVolume.Unmount(id)
+	dstPath, err := dstVolume.Mount(id)
+	if err != nil {
+		return err
+	}
+	defer dstVolume.Unmount(id)
+
+	return chrootarchive.CopyWithTar(srcPath, dstPath)
+}
+
+// mountPointVolume returns the volume mounted by mp.
+func (daemon *Daemon) mountPointVolume(mp *volume.MountPoint) (volume.Volume, error) {
+	if mp.Volume != nil {
+		return mp.Volume, nil
+	}
+	return daemon.volumes.Get(mp.Name)
+}
@@ -3,6 +3,7 @@ package daemon
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"runtime"
 	"strings"
 	"time"
@@ -10,11 +11,13 @@ import (
 	"github.com/docker/docker/api/types/backend"
 	"github.com/docker/docker/builder/dockerfile"
 	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/graphdriver"
 	"github.com/docker/docker/dockerversion"
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/layer"
 	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/ioutils"
+	"github.com/docker/docker/pkg/stringid"
 	"github.com/docker/docker/reference"
 	containertypes "github.com/docker/engine-api/types/container"
 	"github.com/docker/go-connections/nat"
@@ -110,7 +113,7 @@ func (daemon *Daemon) Commit(name string, c *backend.ContainerCommitConfig) (str
 		return "", fmt.Errorf("Windows does not support commit of a running container")
 	}
 
-	if c.Pause && !container.IsPaused() {
+	if c.Pause && !c.Snapshot && !container.IsPaused() {
 		daemon.containerPause(container)
 		defer daemon.containerUnpause(container)
 	}
@@ -126,7 +129,15 @@ func (daemon *Daemon) Commit(name string, c *backend.ContainerCommitConfig) (str
 		}
 	}
 
-	rwTar, err := daemon.exportContainerRw(container)
+	var rwTar io.ReadCloser
+	if c.Snapshot {
+		rwTar, err = container.RWLayer.SnapshotTarStream()
+		if err == graphdriver.ErrSnapshotNotSupported {
+			return "", fmt.Errorf("The %s storage driver cannot snapshot container %s, commit it with --pause instead", daemon.GraphDriverName(), name)
+		}
+	} else {
+		rwTar, err = daemon.exportContainerRw(container)
+	}
 	if err != nil {
 		return "", err
 	}
@@ -158,10 +169,15 @@ func (daemon *Daemon) Commit(name string, c *backend.ContainerCommitConfig) (str
 	}
 	defer layer.ReleaseAndLog(daemon.layerStore, l)
 
+	createdBy := strings.Join(container.Config.Cmd, " ")
+	if c.RecordOrigin {
+		command := strings.Join(append([]string{container.Path}, container.Args...), " ")
+		createdBy = fmt.Sprintf("#(commit) %s %s: %s", strings.TrimPrefix(container.Name, "/"), stringid.TruncateID(container.ID), command)
+	}
 	h := image.History{
 		Author:     c.Author,
 		Created:    time.Now().UTC(),
-		CreatedBy:  strings.Join(container.Config.Cmd, " "),
+		CreatedBy:  createdBy,
 		Comment:    c.Comment,
 		EmptyLayer: true,
 	}
@@ -204,6 +220,9 @@ func (daemon *Daemon) Commit(name string, c *backend.ContainerCommitConfig) (str
 			return "", err
 		}
 	}
+	if c.BuildCache {
+		daemon.buildCache.add(id)
+	}
 
 	if c.Repo != "" {
 		newTag, err := reference.WithName(c.Repo) // todo: should move this to API layer
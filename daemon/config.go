@@ -6,14 +6,20 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
+	"net/url"
+	"path/filepath"
 	"strings"
 	"sync"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/distribution/scan"
 	"github.com/docker/docker/opts"
 	"github.com/docker/docker/pkg/discovery"
 	flag "github.com/docker/docker/pkg/mflag"
+	"github.com/docker/docker/pkg/resolver"
 	"github.com/docker/docker/registry"
+	"github.com/docker/go-units"
 	"github.com/imdario/mergo"
 )
 
@@ -26,6 +32,15 @@ const (
 	// maximum number of uploads that
 	// may take place at a time for each push.
 	defaultMaxConcurrentUploads = 5
+	// defaultStuckOperationTimeout is the default number of seconds
+	// after which a container operation is reported as stuck.
+	defaultStuckOperationTimeout = 120
+	// defaultExecHistorySize is the default number of execs kept in the
+	// history of each container.
+	defaultExecHistorySize = 100
+	// defaultAPITCPKeepAlive is the default number of seconds between the
+	// keep-alive probes of the API connections over TCP.
+	defaultAPITCPKeepAlive = 30
 )
 
 const (
@@ -113,6 +128,152 @@ type CommonConfig struct {
 	// may take place at a time for each push.
 	MaxConcurrentUploads *int `json:"max-concurrent-uploads,omitempty"`
 
+	// StuckOperationTimeout is the number of seconds after which a
+	// container operation that still holds the container lock is
+	// reported as stuck. Zero disables the watchdog.
+	StuckOperationTimeout int `json:"stuck-operation-timeout,omitempty"`
+
+	// NetworkCleanupDryRun makes the daemon only report the networking
+	// artifacts left behind by an unclean shutdown, instead of removing
+	// them on startup.
+	NetworkCleanupDryRun bool `json:"network-cleanup-dry-run,omitempty"`
+
+	// RequireDigestPins makes the daemon refuse to create containers from
+	// image references that are not pinned by digest.
+	RequireDigestPins bool `json:"require-digest-pins,omitempty"`
+
+	// DigestPinExemptions holds the repositories, or "host/namespace/*"
+	// patterns, whose tags may be used when RequireDigestPins is set.
+	DigestPinExemptions []string `json:"digest-pin-exemptions,omitempty"`
+
+	// MaxDownloadRate and MaxUploadRate limit the bandwidth of all the
+	// pulls, and of all the pushes, in bytes per second, such as "10MB".
+	// MaxDownloadRatePerLayer and MaxUploadRatePerLayer limit the bandwidth
+	// of each layer transfer. An empty or zero rate is unlimited.
+	MaxDownloadRate         string `json:"max-download-rate,omitempty"`
+	MaxUploadRate           string `json:"max-upload-rate,omitempty"`
+	MaxDownloadRatePerLayer string `json:"max-download-rate-per-layer,omitempty"`
+	MaxUploadRatePerLayer   string `json:"max-upload-rate-per-layer,omitempty"`
+
+	// HTTPProxy, HTTPSProxy and NoProxy set the proxy the daemon uses for
+	// its outgoing HTTP and HTTPS requests, overriding the HTTP_PROXY,
+	// HTTPS_PROXY and NO_PROXY environment variables.
+	HTTPProxy  string `json:"http-proxy,omitempty"`
+	HTTPSProxy string `json:"https-proxy,omitempty"`
+	NoProxy    string `json:"no-proxy,omitempty"`
+
+	// DaemonDNS, DaemonDNSTCP and DaemonHosts set the resolver the daemon
+	// uses for its own requests, such as pulls and remote build contexts,
+	// instead of the resolver of the host: the nameservers to query, over
+	// TCP if DaemonDNSTCP is set, and host entries of the form host:ip that
+	// take precedence over them. DaemonDNSTCP alone queries the nameservers
+	// of the host over TCP.
+	DaemonDNS    []string `json:"daemon-dns,omitempty"`
+	DaemonDNSTCP bool     `json:"daemon-dns-tcp,omitempty"`
+	DaemonHosts  []string `json:"daemon-hosts,omitempty"`
+
+	// LayerPeers holds the URLs of the daemons, serving their layer blob
+	// cache with LayerPeerListen, that pulls try before the registry.
+	LayerPeers []string `json:"layer-peers,omitempty"`
+
+	// LayerPeerListen is the address on which the daemon serves the layer
+	// blobs it pulled to its peers, without authentication. The blobs are
+	// kept in a cache of at most LayerPeerCacheSize, such as "10GB".
+	LayerPeerListen    string `json:"layer-peer-listen,omitempty"`
+	LayerPeerCacheSize string `json:"layer-peer-cache-size,omitempty"`
+
+	// PushCompression is the compression of the layers pushed to
+	// registries, "gzip" or "zstd".
+	PushCompression string `json:"push-compression,omitempty"`
+
+	// RejectOvercommit makes the daemon refuse to create and start
+	// containers whose CPU or memory reservations, added to those of the
+	// running containers, exceed the capacity of the host times
+	// OvercommitFactor.
+	RejectOvercommit bool    `json:"reject-overcommit,omitempty"`
+	OvercommitFactor float64 `json:"overcommit-factor,omitempty"`
+
+	// EventWebhook is an http:// or https:// URL to which the daemon posts
+	// the events that match EventWebhookFilters, signed with the secret read
+	// from EventWebhookSecretFile if set.
+	EventWebhook           string   `json:"event-webhook,omitempty"`
+	EventWebhookFilters    []string `json:"event-webhook-filters,omitempty"`
+	EventWebhookSecretFile string   `json:"event-webhook-secret-file,omitempty"`
+
+	// LifecycleHooks are the executables of the host run on the events of
+	// the containers, of the form "<event>=<path>". The hooks are killed
+	// after LifecycleHookTimeout seconds. LifecycleHookFailure is "abort" to
+	// fail the create or start of a container on the failure of its hooks,
+	// "ignore" to only log the failures.
+	LifecycleHooks       []string `json:"lifecycle-hooks,omitempty"`
+	LifecycleHookTimeout int      `json:"lifecycle-hook-timeout,omitempty"`
+	LifecycleHookFailure string   `json:"lifecycle-hook-failure,omitempty"`
+
+	// ImageScanner is the name of the image scanner plugin the images are
+	// submitted to after they are pulled and before they are pushed.
+	// ImageScanPolicy is "warn" to only report the images that fail their
+	// scan, "block" to fail their pull or push.
+	ImageScanner    string `json:"image-scanner,omitempty"`
+	ImageScanPolicy string `json:"image-scan-policy,omitempty"`
+
+	// Tenancy restricts the users identified by their TLS client
+	// certificate to the containers, images, networks and volumes they
+	// create, except the TenancyAdmins, who manage all the objects.
+	Tenancy       bool     `json:"tenancy,omitempty"`
+	TenancyAdmins []string `json:"tenancy-admins,omitempty"`
+
+	// ExecHistorySize is the number of execs, the most recent, kept in the
+	// history of each container. Zero disables the history.
+	ExecHistorySize int `json:"exec-history-size,omitempty"`
+
+	// APITCPKeepAlive is the number of seconds between the keep-alive
+	// probes of the API connections over TCP, detecting the connections
+	// severed by a load balancer or a firewall. Zero disables the probes.
+	APITCPKeepAlive int `json:"api-tcp-keepalive,omitempty"`
+	// APIStreamIdleTimeout is the number of seconds after which the attach,
+	// exec, logs and events streams without any traffic are closed. Zero
+	// keeps the idle streams open.
+	APIStreamIdleTimeout int `json:"api-stream-idle-timeout,omitempty"`
+	// APIHTTP2 enables HTTP/2 on the TLS API sockets, for the clients
+	// negotiating it.
+	APIHTTP2 bool `json:"api-http2,omitempty"`
+	// APILimits are the rate limits and the limits of requests in flight
+	// of the API endpoints, in the format of middleware.ParseRateLimit.
+	APILimits []string `json:"api-limits,omitempty"`
+
+	// TracingEndpoint is the Zipkin v2 endpoint of the collector to which
+	// the tracing spans of the daemon are exported, such as
+	// http://zipkin:9411/api/v2/spans. Tracing is disabled if it is empty.
+	TracingEndpoint string `json:"tracing-endpoint,omitempty"`
+	// TracingSampleRate is the fraction, between 0 and 1, of the traces
+	// started by the daemon which are exported.
+	TracingSampleRate float64 `json:"tracing-sample-rate,omitempty"`
+
+	// DeltaPulls enables the experimental delta pulls, which download from
+	// the registry only the difference between the layers of the image
+	// already pulled and of the new version of the image.
+	DeltaPulls bool `json:"delta-pulls,omitempty"`
+
+	// ImageRoot, ContainerRoot, VolumeRoot and TmpRoot are the directories
+	// in which the daemon keeps the images, with the data of the storage
+	// driver, the containers, the volumes of the local driver and its
+	// temporary files, instead of the daemon root, for the hosts with
+	// tiered storage. They default to the daemon root.
+	ImageRoot     string `json:"image-root,omitempty"`
+	ContainerRoot string `json:"container-root,omitempty"`
+	VolumeRoot    string `json:"volume-root,omitempty"`
+	TmpRoot       string `json:"tmp-root,omitempty"`
+
+	// DownloadStagingDir and BuildStagingDir are the directories in which
+	// the layers being pulled are downloaded and the build contexts are
+	// extracted, instead of the temporary directory of the daemon. The
+	// size of the data staged in them is limited to DownloadStagingSize
+	// and BuildStagingSize, such as "20GB", if set.
+	DownloadStagingDir  string `json:"download-staging-dir,omitempty"`
+	DownloadStagingSize string `json:"download-staging-size,omitempty"`
+	BuildStagingDir     string `json:"build-staging-dir,omitempty"`
+	BuildStagingSize    string `json:"build-staging-size,omitempty"`
+
 	Debug     bool     `json:"debug,omitempty"`
 	Hosts     []string `json:"hosts,omitempty"`
 	LogLevel  string   `json:"log-level,omitempty"`
@@ -161,6 +322,51 @@ func (config *Config) InstallCommonFlags(cmd *flag.FlagSet, usageFn func(string)
 	cmd.StringVar(&config.CorsHeaders, []string{"-api-cors-header"}, "", usageFn("Set CORS headers in the remote API"))
 	cmd.IntVar(&maxConcurrentDownloads, []string{"-max-concurrent-downloads"}, defaultMaxConcurrentDownloads, usageFn("Set the max concurrent downloads for each pull"))
 	cmd.IntVar(&maxConcurrentUploads, []string{"-max-concurrent-uploads"}, defaultMaxConcurrentUploads, usageFn("Set the max concurrent uploads for each push"))
+	cmd.IntVar(&config.StuckOperationTimeout, []string{"-stuck-operation-timeout"}, defaultStuckOperationTimeout, usageFn("Seconds after which a blocked container operation is reported, 0 to disable"))
+	cmd.BoolVar(&config.NetworkCleanupDryRun, []string{"-network-cleanup-dry-run"}, false, usageFn("Only report stale networking artifacts found on startup"))
+	cmd.StringVar(&config.MaxDownloadRate, []string{"-max-download-rate"}, "", usageFn("Set the max bandwidth of all the pulls, per second"))
+	cmd.StringVar(&config.MaxUploadRate, []string{"-max-upload-rate"}, "", usageFn("Set the max bandwidth of all the pushes, per second"))
+	cmd.StringVar(&config.MaxDownloadRatePerLayer, []string{"-max-download-rate-per-layer"}, "", usageFn("Set the max bandwidth of each layer download, per second"))
+	cmd.StringVar(&config.MaxUploadRatePerLayer, []string{"-max-upload-rate-per-layer"}, "", usageFn("Set the max bandwidth of each layer upload, per second"))
+	cmd.StringVar(&config.HTTPProxy, []string{"-http-proxy"}, "", usageFn("HTTP proxy URL for the requests of the daemon"))
+	cmd.StringVar(&config.HTTPSProxy, []string{"-https-proxy"}, "", usageFn("HTTPS proxy URL for the requests of the daemon"))
+	cmd.StringVar(&config.NoProxy, []string{"-no-proxy"}, "", usageFn("Comma-separated list of hosts the daemon reaches without proxy"))
+	cmd.Var(opts.NewNamedListOptsRef("daemon-dns", &config.DaemonDNS, resolver.ValidateNameserver), []string{"-daemon-dns"}, usageFn("DNS server for the requests of the daemon, such as pulls"))
+	cmd.BoolVar(&config.DaemonDNSTCP, []string{"-daemon-dns-tcp"}, false, usageFn("Query the DNS servers of the daemon over TCP"))
+	cmd.Var(opts.NewNamedListOptsRef("daemon-hosts", &config.DaemonHosts, resolver.ValidateHost), []string{"-daemon-add-host"}, usageFn("Add a host entry for the requests of the daemon (host:ip)"))
+	cmd.Var(opts.NewNamedListOptsRef("layer-peers", &config.LayerPeers, validateLayerPeer), []string{"-layer-peer"}, usageFn("URL of a daemon to download layers from before the registry"))
+	cmd.StringVar(&config.LayerPeerListen, []string{"-layer-peer-listen"}, "", usageFn("Address on which to serve pulled layers to peers"))
+	cmd.StringVar(&config.LayerPeerCacheSize, []string{"-layer-peer-cache-size"}, defaultLayerPeerCacheSize, usageFn("Max size of the layers kept to serve to peers"))
+	cmd.StringVar(&config.PushCompression, []string{"-push-compression"}, "gzip", usageFn("Compression of the layers pushed to registries (gzip or zstd)"))
+	cmd.BoolVar(&config.RequireDigestPins, []string{"-require-digest-pins"}, false, usageFn("Require image references pinned by digest to create containers"))
+	cmd.Var(opts.NewNamedListOptsRef("digest-pin-exemptions", &config.DigestPinExemptions, validateDigestPinExemption), []string{"-digest-pin-exemption"}, usageFn("Repository allowed to be referenced by tag with --require-digest-pins"))
+	cmd.BoolVar(&config.RejectOvercommit, []string{"-reject-overcommit"}, false, usageFn("Reject containers that reserve more CPUs or memory than the host has"))
+	cmd.Float64Var(&config.OvercommitFactor, []string{"-overcommit-factor"}, 1, usageFn("Factor of the host capacity that --reject-overcommit allows to reserve"))
+	cmd.StringVar(&config.EventWebhook, []string{"-event-webhook"}, "", usageFn("URL to post the events of the daemon to"))
+	cmd.Var(opts.NewNamedListOptsRef("event-webhook-filters", &config.EventWebhookFilters, nil), []string{"-event-webhook-filter"}, usageFn("Filter the events posted to the event webhook"))
+	cmd.StringVar(&config.EventWebhookSecretFile, []string{"-event-webhook-secret-file"}, "", usageFn("Path to the secret signing the event webhook requests"))
+	cmd.Var(opts.NewNamedListOptsRef("lifecycle-hooks", &config.LifecycleHooks, validateLifecycleHook), []string{"-lifecycle-hook"}, usageFn("Executable to run on an event of the containers (<event>=<path>)"))
+	cmd.IntVar(&config.LifecycleHookTimeout, []string{"-lifecycle-hook-timeout"}, defaultLifecycleHookTimeout, usageFn("Seconds after which a lifecycle hook is killed"))
+	cmd.StringVar(&config.LifecycleHookFailure, []string{"-lifecycle-hook-failure"}, hookFailureIgnore, usageFn("Policy on the failure of a create or start hook (ignore or abort)"))
+	cmd.StringVar(&config.ImageScanner, []string{"-image-scanner"}, "", usageFn("Image scanner plugin scanning the images pulled and pushed"))
+	cmd.StringVar(&config.ImageScanPolicy, []string{"-image-scan-policy"}, scan.PolicyWarn, usageFn("Policy on the images failing their scan (warn or block)"))
+	cmd.BoolVar(&config.Tenancy, []string{"-tenancy"}, false, usageFn("Restrict the users to the containers, images, networks and volumes they create"))
+	cmd.Var(opts.NewNamedListOptsRef("tenancy-admins", &config.TenancyAdmins, nil), []string{"-tenancy-admin"}, usageFn("User managing the objects of all the users in tenancy mode"))
+	cmd.IntVar(&config.ExecHistorySize, []string{"-exec-history-size"}, defaultExecHistorySize, usageFn("Number of execs kept in the history of each container, 0 to disable"))
+	cmd.IntVar(&config.APITCPKeepAlive, []string{"-api-tcp-keepalive"}, defaultAPITCPKeepAlive, usageFn("Seconds between the keep-alive probes of the API connections over TCP, 0 to disable"))
+	cmd.IntVar(&config.APIStreamIdleTimeout, []string{"-api-stream-idle-timeout"}, 0, usageFn("Seconds after which the API streams without traffic are closed, 0 to disable"))
+	cmd.BoolVar(&config.APIHTTP2, []string{"-api-http2"}, false, usageFn("Enable HTTP/2 on the TLS API sockets"))
+	cmd.Var(opts.NewNamedListOptsRef("api-limits", &config.APILimits, nil), []string{"-api-limit"}, usageFn("Limit the rate or the concurrency of the requests to API endpoints"))
+	cmd.StringVar(&config.TracingEndpoint, []string{"-tracing-endpoint"}, "", usageFn("Zipkin endpoint to export the tracing spans of the daemon to"))
+	cmd.Float64Var(&config.TracingSampleRate, []string{"-tracing-sample-rate"}, 1, usageFn("Fraction of the traces started by the daemon to export"))
+	cmd.StringVar(&config.ImageRoot, []string{"-image-root"}, "", usageFn("Root of the images and the storage driver data, instead of the daemon root"))
+	cmd.StringVar(&config.ContainerRoot, []string{"-container-root"}, "", usageFn("Root of the containers, instead of the daemon root"))
+	cmd.StringVar(&config.VolumeRoot, []string{"-volume-root"}, "", usageFn("Root of the local volumes, instead of the daemon root"))
+	cmd.StringVar(&config.TmpRoot, []string{"-tmp-root"}, "", usageFn("Root of the temporary files, instead of the daemon root"))
+	cmd.StringVar(&config.DownloadStagingDir, []string{"-download-staging-dir"}, "", usageFn("Directory in which the layers being pulled are downloaded"))
+	cmd.StringVar(&config.DownloadStagingSize, []string{"-download-staging-size"}, "", usageFn("Max size of the layers being downloaded at a time"))
+	cmd.StringVar(&config.BuildStagingDir, []string{"-build-staging-dir"}, "", usageFn("Directory in which the build contexts are extracted"))
+	cmd.StringVar(&config.BuildStagingSize, []string{"-build-staging-size"}, "", usageFn("Max size of the build contexts extracted at a time"))
 
 	config.MaxConcurrentDownloads = &maxConcurrentDownloads
 	config.MaxConcurrentUploads = &maxConcurrentUploads
@@ -414,5 +620,163 @@ func validateConfiguration(config *Config) error {
 	if config.IsValueSet("max-concurrent-uploads") && config.MaxConcurrentUploads != nil && *config.MaxConcurrentUploads < 0 {
 		return fmt.Errorf("invalid max concurrent uploads: %d", *config.MaxConcurrentUploads)
 	}
+
+	// validate AllowedRegistries and BlockedRegistries
+	for _, scope := range append(config.AllowedRegistries, config.BlockedRegistries...) {
+		if _, err := registry.ValidateRegistryScope(scope); err != nil {
+			return err
+		}
+	}
+
+	// validate the transfer rates
+	for _, rate := range []string{config.MaxDownloadRate, config.MaxUploadRate, config.MaxDownloadRatePerLayer, config.MaxUploadRatePerLayer} {
+		if _, err := parseTransferRate(rate); err != nil {
+			return err
+		}
+	}
+
+	// validate the data roots
+	for _, root := range []string{config.ImageRoot, config.ContainerRoot, config.VolumeRoot, config.TmpRoot} {
+		if root != "" && !filepath.IsAbs(root) {
+			return fmt.Errorf("invalid data root %s, it must be an absolute path", root)
+		}
+	}
+
+	// validate the staging areas
+	for _, dir := range []string{config.DownloadStagingDir, config.BuildStagingDir} {
+		if dir != "" && !filepath.IsAbs(dir) {
+			return fmt.Errorf("invalid staging directory %s, it must be an absolute path", dir)
+		}
+	}
+	for _, size := range []string{config.DownloadStagingSize, config.BuildStagingSize} {
+		if _, err := parseStagingSize(size); err != nil {
+			return err
+		}
+	}
+
+	// validate the layer peers
+	for _, url := range config.LayerPeers {
+		if _, err := validateLayerPeer(url); err != nil {
+			return err
+		}
+	}
+	if config.LayerPeerListen != "" {
+		if _, _, err := net.SplitHostPort(config.LayerPeerListen); err != nil {
+			return fmt.Errorf("invalid layer peer listen address %s: %v", config.LayerPeerListen, err)
+		}
+	}
+	if _, err := parseLayerPeerCacheSize(config.LayerPeerCacheSize); err != nil {
+		return err
+	}
+
+	// validate OvercommitFactor
+	if config.IsValueSet("overcommit-factor") && config.OvercommitFactor <= 0 {
+		return fmt.Errorf("invalid overcommit factor: %g, it must be greater than 0", config.OvercommitFactor)
+	}
+
+	// validate the event webhook
+	if config.EventWebhook != "" {
+		if err := validateEventWebhook(config.EventWebhook); err != nil {
+			return err
+		}
+	}
+	if _, err := parseEventWebhookFilters(config.EventWebhookFilters); err != nil {
+		return err
+	}
+
+	// validate the event log
+	if err := validateEventLogConfig(config); err != nil {
+		return err
+	}
+
+	// validate the lifecycle hooks
+	for _, hook := range config.LifecycleHooks {
+		if _, err := validateLifecycleHook(hook); err != nil {
+			return err
+		}
+	}
+	if config.IsValueSet("lifecycle-hook-timeout") && config.LifecycleHookTimeout <= 0 {
+		return fmt.Errorf("invalid lifecycle hook timeout: %d, it must be greater than 0", config.LifecycleHookTimeout)
+	}
+	if err := validateLifecycleHookFailure(config.LifecycleHookFailure); err != nil {
+		return err
+	}
+
+	// validate the API connection timeouts
+	if config.APITCPKeepAlive < 0 {
+		return fmt.Errorf("invalid API TCP keep-alive: %d, it must be positive or 0", config.APITCPKeepAlive)
+	}
+	if config.APIStreamIdleTimeout < 0 {
+		return fmt.Errorf("invalid API stream idle timeout: %d, it must be positive or 0", config.APIStreamIdleTimeout)
+	}
+
+	// validate the tracing settings
+	if config.TracingEndpoint != "" {
+		if u, err := url.Parse(config.TracingEndpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid tracing endpoint: %s, it must be an http or https URL", config.TracingEndpoint)
+		}
+	}
+	if config.TracingSampleRate < 0 || config.TracingSampleRate > 1 {
+		return fmt.Errorf("invalid tracing sample rate: %g, it must be between 0 and 1", config.TracingSampleRate)
+	}
+
+	// validate ImageScanPolicy
+	if err := scan.ValidatePolicy(config.ImageScanPolicy); err != nil {
+		return err
+	}
+
+	// validate PushCompression
+	if _, err := parsePushCompression(config.PushCompression); err != nil {
+		return err
+	}
+
+	// validate HTTPProxy and HTTPSProxy
+	for _, proxy := range []string{config.HTTPProxy, config.HTTPSProxy} {
+		if proxy == "" {
+			continue
+		}
+		if _, err := registry.ValidateProxyURL(proxy); err != nil {
+			return err
+		}
+	}
+
+	// validate DaemonDNS and DaemonHosts
+	for _, ns := range config.DaemonDNS {
+		if _, err := resolver.ValidateNameserver(ns); err != nil {
+			return err
+		}
+	}
+	for _, h := range config.DaemonHosts {
+		if _, err := resolver.ValidateHost(h); err != nil {
+			return err
+		}
+	}
+
+	// validate RegistryProxies
+	for hostname, proxy := range config.RegistryProxies {
+		if _, err := registry.ValidateRegistryProxy(hostname + "=" + proxy); err != nil {
+			return err
+		}
+	}
+
+	// validate DigestPinExemptions
+	for _, exemption := range config.DigestPinExemptions {
+		if _, err := validateDigestPinExemption(exemption); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// parseTransferRate parses a bandwidth limit in bytes per second, such as
+// "512KB" or "10MB". An empty rate is 0, which means unlimited.
+func parseTransferRate(rate string) (int64, error) {
+	if rate == "" {
+		return 0, nil
+	}
+	n, err := units.FromHumanSize(rate)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid transfer rate: %s", rate)
+	}
+	return n, nil
+}
@@ -5,4 +5,5 @@ package daemon
 import flag "github.com/docker/docker/pkg/mflag"
 
 func (config *Config) attachExperimentalFlags(cmd *flag.FlagSet, usageFn func(string) string) {
+	cmd.BoolVar(&config.DeltaPulls, []string{"-delta-pulls"}, false, usageFn("Download only the changes between image versions from registries that support it"))
 }
@@ -276,3 +276,70 @@ func TestValidateConfiguration(t *testing.T) {
 		t.Fatal("expected error, got nil")
 	}
 }
+
+func TestValidateTracingConfiguration(t *testing.T) {
+	for _, tc := range []struct {
+		endpoint string
+		rate     float64
+		valid    bool
+	}{
+		{"http://zipkin:9411/api/v2/spans", 0.1, true},
+		{"https://jaeger.example.com/api/v2/spans", 1, true},
+		{"zipkin:9411", 1, false},
+		{"udp://zipkin:9411", 1, false},
+		{"http://zipkin:9411/api/v2/spans", 1.5, false},
+		{"", -1, false},
+	} {
+		err := validateConfiguration(&Config{CommonConfig: CommonConfig{TracingEndpoint: tc.endpoint, TracingSampleRate: tc.rate}})
+		if tc.valid && err != nil {
+			t.Fatalf("expected no error for %s at %g, got error %v", tc.endpoint, tc.rate, err)
+		}
+		if !tc.valid && err == nil {
+			t.Fatalf("expected an error for %s at %g, got nil", tc.endpoint, tc.rate)
+		}
+	}
+}
+
+func TestValidateDataRoots(t *testing.T) {
+	for _, tc := range []struct {
+		config *Config
+		valid  bool
+	}{
+		{&Config{CommonConfig: CommonConfig{ImageRoot: os.TempDir(), VolumeRoot: os.TempDir()}}, true},
+		{&Config{CommonConfig: CommonConfig{ImageRoot: "ssd/docker"}}, false},
+		{&Config{CommonConfig: CommonConfig{ContainerRoot: "ssd/docker"}}, false},
+		{&Config{CommonConfig: CommonConfig{VolumeRoot: "."}}, false},
+		{&Config{CommonConfig: CommonConfig{TmpRoot: "tmp"}}, false},
+	} {
+		c := tc.config
+		err := validateConfiguration(c)
+		if tc.valid && err != nil {
+			t.Fatalf("expected no error for the data roots %q, %q, %q and %q, got error %v", c.ImageRoot, c.ContainerRoot, c.VolumeRoot, c.TmpRoot, err)
+		}
+		if !tc.valid && err == nil {
+			t.Fatalf("expected an error for the data roots %q, %q, %q and %q, got nil", c.ImageRoot, c.ContainerRoot, c.VolumeRoot, c.TmpRoot)
+		}
+	}
+}
+
+func TestValidateStaging(t *testing.T) {
+	for _, tc := range []struct {
+		config *Config
+		valid  bool
+	}{
+		{&Config{CommonConfig: CommonConfig{DownloadStagingDir: os.TempDir(), DownloadStagingSize: "10G", BuildStagingSize: "512m"}}, true},
+		{&Config{CommonConfig: CommonConfig{DownloadStagingDir: "tmp"}}, false},
+		{&Config{CommonConfig: CommonConfig{BuildStagingDir: "."}}, false},
+		{&Config{CommonConfig: CommonConfig{DownloadStagingSize: "big"}}, false},
+		{&Config{CommonConfig: CommonConfig{BuildStagingSize: "0"}}, false},
+	} {
+		c := tc.config
+		err := validateConfiguration(c)
+		if tc.valid && err != nil {
+			t.Fatalf("expected no error for the staging areas %q (%q) and %q (%q), got error %v", c.DownloadStagingDir, c.DownloadStagingSize, c.BuildStagingDir, c.BuildStagingSize, err)
+		}
+		if !tc.valid && err == nil {
+			t.Fatalf("expected an error for the staging areas %q (%q) and %q (%q), got nil", c.DownloadStagingDir, c.DownloadStagingSize, c.BuildStagingDir, c.BuildStagingSize)
+		}
+	}
+}
@@ -26,9 +26,13 @@ type Config struct {
 	// Fields below here are platform specific.
 	CgroupParent         string                   `json:"cgroup-parent,omitempty"`
 	ContainerdAddr       string                   `json:"containerd,omitempty"`
+	ContainerdOOMScore   int                      `json:"containerd-oom-score-adjust,omitempty"`
 	EnableSelinuxSupport bool                     `json:"selinux-enabled,omitempty"`
 	ExecRoot             string                   `json:"exec-root,omitempty"`
+	OCIHooksDir          string                   `json:"oci-hooks-dir,omitempty"`
 	RemappedRoot         string                   `json:"userns-remap,omitempty"`
+	Rootless             bool                     `json:"rootless,omitempty"`
+	RootlessNetHelper    string                   `json:"rootless-net-helper,omitempty"`
 	Ulimits              map[string]*units.Ulimit `json:"default-ulimits,omitempty"`
 }
 
@@ -43,6 +47,7 @@ type bridgeConfig struct {
 	EnableIPForward             bool   `json:"ip-forward,omitempty"`
 	EnableIPMasq                bool   `json:"ip-mask,omitempty"`
 	EnableUserlandProxy         bool   `json:"userland-proxy,omitempty"`
+	PublishedPortRange          string `json:"published-port-range,omitempty"`
 	DefaultIP                   net.IP `json:"ip,omitempty"`
 	IP                          string `json:"bip,omitempty"`
 	FixedCIDRv6                 string `json:"fixed-cidr-v6,omitempty"`
@@ -78,10 +83,15 @@ func (config *Config) InstallFlags(cmd *flag.FlagSet, usageFn func(string) strin
 	cmd.BoolVar(&config.bridgeConfig.InterContainerCommunication, []string{"#icc", "-icc"}, true, usageFn("Enable inter-container communication"))
 	cmd.Var(opts.NewIPOpt(&config.bridgeConfig.DefaultIP, "0.0.0.0"), []string{"#ip", "-ip"}, usageFn("Default IP when binding container ports"))
 	cmd.BoolVar(&config.bridgeConfig.EnableUserlandProxy, []string{"-userland-proxy"}, true, usageFn("Use userland proxy for loopback traffic"))
+	cmd.StringVar(&config.bridgeConfig.PublishedPortRange, []string{"-published-port-range"}, "", usageFn("Range of the host ports allocated to the published ports of containers"))
 	cmd.BoolVar(&config.EnableCors, []string{"#api-enable-cors", "#-api-enable-cors"}, false, usageFn("Enable CORS headers in the remote API, this is deprecated by --api-cors-header"))
 	cmd.StringVar(&config.CgroupParent, []string{"-cgroup-parent"}, "", usageFn("Set parent cgroup for all containers"))
 	cmd.StringVar(&config.RemappedRoot, []string{"-userns-remap"}, "", usageFn("User/Group setting for user namespaces"))
 	cmd.StringVar(&config.ContainerdAddr, []string{"-containerd"}, "", usageFn("Path to containerd socket"))
+	cmd.IntVar(&config.ContainerdOOMScore, []string{"-containerd-oom-score-adjust"}, 0, usageFn("Set the oom_score_adj of the containerd process started by the daemon"))
+	cmd.StringVar(&config.OCIHooksDir, []string{"-oci-hooks-dir"}, "", usageFn("Directory of the OCI hooks allowed for containers"))
+	cmd.BoolVar(&config.Rootless, []string{"-rootless"}, false, usageFn("Run the daemon and its containers as an unprivileged user"))
+	cmd.StringVar(&config.RootlessNetHelper, []string{"-rootless-net-helper"}, "slirp4netns", usageFn("Network helper connecting a rootless daemon to the network of the host"))
 
 	config.attachExperimentalFlags(cmd, usageFn)
 }
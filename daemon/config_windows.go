@@ -3,6 +3,7 @@ package daemon
 import (
 	"os"
 
+	"github.com/docker/docker/opts"
 	flag "github.com/docker/docker/pkg/mflag"
 )
 
@@ -23,8 +24,21 @@ type bridgeConfig struct {
 type Config struct {
 	CommonConfig
 
-	// Fields below here are platform specific. (There are none presently
-	// for the Windows daemon.)
+	// Fields below here are platform specific.
+
+	// EventLog is the source under which the lifecycle events of the
+	// containers are written to the Windows Event Log, with the event IDs
+	// of EventLogIDs, of the form "<event>=<id>", overriding the defaults.
+	EventLog    string   `json:"event-log,omitempty"`
+	EventLogIDs []string `json:"event-log-ids,omitempty"`
+
+	// OrphanPolicy is what to do with the compute systems left running by
+	// a previous run of the daemon, "terminate" or "adopt".
+	OrphanPolicy string `json:"orphan-policy,omitempty"`
+
+	// LiveRestore keeps the containers running when the daemon shuts down,
+	// their compute systems being reattached to when it starts again.
+	LiveRestore bool `json:"live-restore,omitempty"`
 }
 
 // InstallFlags adds command-line options to the top-level flag parser for
@@ -39,4 +53,8 @@ func (config *Config) InstallFlags(cmd *flag.FlagSet, usageFn func(string) strin
 	cmd.StringVar(&config.bridgeConfig.FixedCIDR, []string{"-fixed-cidr"}, "", usageFn("IPv4 subnet for fixed IPs"))
 	cmd.StringVar(&config.bridgeConfig.Iface, []string{"b", "-bridge"}, "", "Attach containers to a virtual switch")
 	cmd.StringVar(&config.SocketGroup, []string{"G", "-group"}, "", usageFn("Users or groups that can access the named pipe"))
+	cmd.StringVar(&config.EventLog, []string{"-event-log"}, "", usageFn("Source to write the container events to the Windows Event Log under"))
+	cmd.Var(opts.NewNamedListOptsRef("event-log-ids", &config.EventLogIDs, nil), []string{"-event-log-id"}, usageFn("Set the event ID of a container event in the Windows Event Log"))
+	cmd.StringVar(&config.OrphanPolicy, []string{"-orphan-policy"}, orphanPolicyTerminate, usageFn("Terminate or adopt the compute systems left running by a previous run of the daemon"))
+	cmd.BoolVar(&config.LiveRestore, []string{"-live-restore"}, false, usageFn("Keep containers running while the daemon is down"))
 }
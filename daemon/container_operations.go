@@ -397,6 +397,10 @@ func (daemon *Daemon) allocateNetwork(container *container.Container) error {
 		}
 	}
 
+	if err := daemon.rootlessPorts.forward(container); err != nil {
+		return err
+	}
+
 	return container.WriteHostConfig()
 }
 
@@ -697,6 +701,7 @@ func (daemon *Daemon) releaseNetwork(container *container.Container) {
 
 	sid := container.NetworkSettings.SandboxID
 	settings := container.NetworkSettings.Networks
+	daemon.rootlessPorts.release(container)
 	container.NetworkSettings.Ports = nil
 
 	if sid == "" || len(settings) == 0 {
@@ -717,9 +722,16 @@ func (daemon *Daemon) releaseNetwork(container *container.Container) {
 		return
 	}
 
+	// The network devices of the container go back to the host before its
+	// network namespace is removed.
+	if len(container.HostConfig.NetworkDevices) > 0 {
+		daemon.restoreNetworkDevices(container, sb.Key())
+	}
+
 	if err := sb.Delete(); err != nil {
 		logrus.Errorf("Error deleting sandbox id %s for container %s: %v", sid, container.ID, err)
 	}
+	daemon.reserveStaticAddresses(container)
 
 	attributes := map[string]string{
 		"container": container.ID,
@@ -111,8 +111,14 @@ func (daemon *Daemon) ConnectToNetwork(container *container.Container, idOrName
 		if _, err := daemon.updateNetworkConfig(container, idOrName, endpointConfig, true); err != nil {
 			return err
 		}
+		if err := daemon.checkStaticAddressConflicts(container.ID, map[string]*networktypes.EndpointSettings{idOrName: endpointConfig}, "", ""); err != nil {
+			return err
+		}
 		container.NetworkSettings.Networks[idOrName] = endpointConfig
 	} else {
+		if err := daemon.checkStaticAddressConflicts(container.ID, map[string]*networktypes.EndpointSettings{idOrName: endpointConfig}, "", ""); err != nil {
+			return err
+		}
 		if err := daemon.connectToNetwork(container, idOrName, endpointConfig, true); err != nil {
 			return err
 		}
@@ -134,6 +140,7 @@ func (daemon *Daemon) DisconnectFromNetwork(container *container.Container, n li
 		}
 		if _, ok := container.NetworkSettings.Networks[n.Name()]; ok {
 			delete(container.NetworkSettings.Networks, n.Name())
+			daemon.releaseStaticAddresses(container, n.Name())
 		} else {
 			return fmt.Errorf("container %s is not connected to the network %s", container.ID, n.Name())
 		}
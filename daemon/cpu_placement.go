@@ -0,0 +1,182 @@
+package daemon
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/errors"
+	"github.com/docker/docker/pkg/parsers"
+	containertypes "github.com/docker/engine-api/types/container"
+)
+
+// autoCpusetPrefix prefixes the --cpuset-cpus of the containers that let
+// the daemon pick their CPUs, such as "auto:4" for 4 exclusive CPUs.
+const autoCpusetPrefix = "auto:"
+
+// numaNode is a NUMA node of the host and its CPUs, in ascending order.
+type numaNode struct {
+	id   int
+	cpus []int
+}
+
+// parseAutoCpuset returns the number of CPUs requested by a cpuset of the
+// form "auto:<count>", and whether cpuset is of that form.
+func parseAutoCpuset(cpuset string) (int, bool, error) {
+	if !strings.HasPrefix(cpuset, autoCpusetPrefix) {
+		return 0, false, nil
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(cpuset, autoCpusetPrefix))
+	if err != nil || n <= 0 {
+		return 0, true, fmt.Errorf("Invalid value %s for cpuset cpus, the number of CPUs of auto must be a positive integer", cpuset)
+	}
+	return n, true, nil
+}
+
+// cpuAllocator assigns the CPUs of the host exclusively to the containers
+// created with an auto cpuset.
+type cpuAllocator struct {
+	mu       sync.Mutex
+	assigned map[int]string // CPU to the ID of the container it is assigned to
+}
+
+func newCPUAllocator() *cpuAllocator {
+	return &cpuAllocator{assigned: make(map[int]string)}
+}
+
+// allocate assigns n CPUs of the same NUMA node to the container id. It
+// picks the node with the fewest free CPUs that still has n, which keeps
+// the larger sets of free CPUs for the containers that need them.
+func (a *cpuAllocator) allocate(id string, n int, nodes []numaNode) (*container.CPUPlacement, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var (
+		best     *numaNode
+		bestFree []int
+	)
+	for i := range nodes {
+		var free []int
+		for _, cpu := range nodes[i].cpus {
+			if _, ok := a.assigned[cpu]; !ok {
+				free = append(free, cpu)
+			}
+		}
+		if len(free) >= n && (best == nil || len(free) < len(bestFree)) {
+			best, bestFree = &nodes[i], free
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("cannot allocate %d CPUs: no NUMA node of the host has %d CPUs that are not assigned to other containers", n, n)
+	}
+
+	cpus := bestFree[:n]
+	for _, cpu := range cpus {
+		a.assigned[cpu] = id
+	}
+	return &container.CPUPlacement{CPUs: formatCPUList(cpus), NUMANode: best.id}, nil
+}
+
+// restore assigns the CPUs of placement to the container id again, as
+// allocated before the daemon restarted.
+func (a *cpuAllocator) restore(id string, placement *container.CPUPlacement) error {
+	cpus, err := parseCPUList(placement.CPUs)
+	if err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, cpu := range cpus {
+		if other, ok := a.assigned[cpu]; ok && other != id {
+			return fmt.Errorf("CPU %d is already assigned to container %s", cpu, other)
+		}
+	}
+	for _, cpu := range cpus {
+		a.assigned[cpu] = id
+	}
+	return nil
+}
+
+// release frees the CPUs assigned to the container id.
+func (a *cpuAllocator) release(id string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for cpu, owner := range a.assigned {
+		if owner == id {
+			delete(a.assigned, cpu)
+		}
+	}
+}
+
+// placeCPUs replaces an auto cpuset of hostConfig by the CPUs allocated to
+// c, and restricts its memory to their NUMA node.
+func (daemon *Daemon) placeCPUs(c *container.Container, hostConfig *containertypes.HostConfig) error {
+	n, ok, err := parseAutoCpuset(hostConfig.CpusetCpus)
+	if !ok || err != nil {
+		return err
+	}
+	nodes, err := readNUMATopology()
+	if err != nil {
+		return fmt.Errorf("cannot allocate CPUs: %v", err)
+	}
+	placement, err := daemon.cpuAllocator.allocate(c.ID, n, nodes)
+	if err != nil {
+		return errors.NewResourceExhaustedError(err)
+	}
+	c.CPUPlacement = placement
+	hostConfig.CpusetCpus = placement.CPUs
+	if hostConfig.CpusetMems == "" {
+		hostConfig.CpusetMems = strconv.Itoa(placement.NUMANode)
+	}
+	return nil
+}
+
+// restoreCPUPlacements assigns the CPUs of the containers created with an
+// auto cpuset again when the daemon restarts.
+func (daemon *Daemon) restoreCPUPlacements(containers map[string]*container.Container) {
+	for _, c := range containers {
+		if c.CPUPlacement == nil {
+			continue
+		}
+		if err := daemon.cpuAllocator.restore(c.ID, c.CPUPlacement); err != nil {
+			logrus.Warnf("Failed to restore the CPU placement of container %s: %v", c.ID, err)
+		}
+	}
+}
+
+// parseCPUList parses a list of CPUs such as "0-3,8" into ascending order.
+func parseCPUList(list string) ([]int, error) {
+	set, err := parsers.ParseUintList(list)
+	if err != nil {
+		return nil, err
+	}
+	cpus := make([]int, 0, len(set))
+	for cpu := range set {
+		cpus = append(cpus, cpu)
+	}
+	sort.Ints(cpus)
+	return cpus, nil
+}
+
+// formatCPUList formats CPUs in ascending order as a list of ranges, such
+// as "0-3,8".
+func formatCPUList(cpus []int) string {
+	var ranges []string
+	for i := 0; i < len(cpus); {
+		j := i
+		for j+1 < len(cpus) && cpus[j+1] == cpus[j]+1 {
+			j++
+		}
+		if i == j {
+			ranges = append(ranges, strconv.Itoa(cpus[i]))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", cpus[i], cpus[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(ranges, ",")
+}
@@ -0,0 +1,55 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const sysNodeDir = "/sys/devices/system/node"
+
+// readNUMATopology returns the NUMA nodes of the host. A host without NUMA
+// support has a single node 0 with all the CPUs.
+func readNUMATopology() ([]numaNode, error) {
+	dirs, err := filepath.Glob(filepath.Join(sysNodeDir, "node[0-9]*"))
+	if err != nil {
+		return nil, err
+	}
+	if len(dirs) == 0 {
+		cpus := make([]int, runtime.NumCPU())
+		for i := range cpus {
+			cpus[i] = i
+		}
+		return []numaNode{{id: 0, cpus: cpus}}, nil
+	}
+
+	var nodes []numaNode
+	for _, dir := range dirs {
+		id, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(dir), "node"))
+		if err != nil {
+			continue
+		}
+		list, err := ioutil.ReadFile(filepath.Join(dir, "cpulist"))
+		if err != nil {
+			return nil, err
+		}
+		cpus, err := parseCPUList(strings.TrimSpace(string(list)))
+		if err != nil {
+			return nil, err
+		}
+		if len(cpus) > 0 {
+			nodes = append(nodes, numaNode{id: id, cpus: cpus})
+		}
+	}
+	sort.Sort(byNodeID(nodes))
+	return nodes, nil
+}
+
+type byNodeID []numaNode
+
+func (n byNodeID) Len() int           { return len(n) }
+func (n byNodeID) Less(i, j int) bool { return n[i].id < n[j].id }
+func (n byNodeID) Swap(i, j int)      { n[i], n[j] = n[j], n[i] }
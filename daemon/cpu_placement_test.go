@@ -0,0 +1,79 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/docker/docker/container"
+)
+
+func TestParseAutoCpuset(t *testing.T) {
+	if _, auto, err := parseAutoCpuset("0-3"); auto || err != nil {
+		t.Fatalf("expected 0-3 not to be an auto cpuset, got %v, %v", auto, err)
+	}
+	if n, auto, err := parseAutoCpuset("auto:4"); !auto || err != nil || n != 4 {
+		t.Fatalf("expected auto:4 to request 4 CPUs, got %d, %v, %v", n, auto, err)
+	}
+	for _, invalid := range []string{"auto:", "auto:0", "auto:-1", "auto:x"} {
+		if _, auto, err := parseAutoCpuset(invalid); !auto || err == nil {
+			t.Fatalf("expected %s to be an invalid auto cpuset, got %v, %v", invalid, auto, err)
+		}
+	}
+}
+
+func TestCPUAllocator(t *testing.T) {
+	nodes := []numaNode{
+		{id: 0, cpus: []int{0, 1, 2, 3}},
+		{id: 1, cpus: []int{4, 5, 6, 7, 8, 9}},
+	}
+	a := newCPUAllocator()
+
+	// The node with the fewest free CPUs that fit is picked.
+	p, err := a.allocate("a", 3, nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *p != (container.CPUPlacement{CPUs: "0-2", NUMANode: 0}) {
+		t.Fatalf("unexpected placement %+v", p)
+	}
+	p, err = a.allocate("b", 2, nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *p != (container.CPUPlacement{CPUs: "4-5", NUMANode: 1}) {
+		t.Fatalf("unexpected placement %+v", p)
+	}
+	if _, err := a.allocate("c", 5, nodes); err == nil {
+		t.Fatal("expected no node to have 5 free CPUs")
+	}
+
+	a.release("a")
+	p, err = a.allocate("c", 4, nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *p != (container.CPUPlacement{CPUs: "0-3", NUMANode: 0}) {
+		t.Fatalf("unexpected placement %+v", p)
+	}
+
+	restored := newCPUAllocator()
+	if err := restored.restore("c", p); err != nil {
+		t.Fatal(err)
+	}
+	if err := restored.restore("d", &container.CPUPlacement{CPUs: "3-4", NUMANode: 0}); err == nil {
+		t.Fatal("expected the restore of an assigned CPU to fail")
+	}
+}
+
+func TestFormatCPUList(t *testing.T) {
+	cases := map[string][]int{
+		"":          nil,
+		"3":         {3},
+		"0-3":       {0, 1, 2, 3},
+		"0,2-4,7,9": {0, 2, 3, 4, 7, 9},
+	}
+	for expected, cpus := range cases {
+		if list := formatCPUList(cpus); list != expected {
+			t.Fatalf("expected %q for %v, got %q", expected, cpus, list)
+		}
+	}
+}
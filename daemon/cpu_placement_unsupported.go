@@ -0,0 +1,12 @@
+// +build !linux
+
+package daemon
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func readNUMATopology() ([]numaNode, error) {
+	return nil, fmt.Errorf("auto cpusets are not supported on %s", runtime.GOOS)
+}
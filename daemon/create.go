@@ -2,8 +2,10 @@ package daemon
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/api/types/backend"
 	"github.com/docker/docker/container"
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/layer"
@@ -24,7 +26,7 @@ func (daemon *Daemon) ContainerCreate(params types.ContainerCreateConfig) (types
 
 	warnings, err := daemon.verifyContainerSettings(params.HostConfig, params.Config, false)
 	if err != nil {
-		return types.ContainerCreateResponse{Warnings: warnings}, err
+		return createResponse("", warnings), err
 	}
 
 	err = daemon.verifyNetworkingConfig(params.NetworkingConfig)
@@ -37,15 +39,76 @@ func (daemon *Daemon) ContainerCreate(params types.ContainerCreateConfig) (types
 	}
 	err = daemon.adaptContainerSettings(params.HostConfig, params.AdjustCPUShares)
 	if err != nil {
-		return types.ContainerCreateResponse{Warnings: warnings}, err
+		return createResponse("", warnings), err
+	}
+	if err := daemon.admitReservation(nil, params.HostConfig); err != nil {
+		return createResponse("", warnings), err
+	}
+
+	requestedIsolation := params.HostConfig.Isolation
+	if params.Config.Image != "" {
+		if err := daemon.verifyDigestPin(params.Config.Image); err != nil {
+			return createResponse("", warnings), err
+		}
+		if !params.DryRun {
+			_, missing := daemon.GetImage(params.Config.Image)
+			if err := daemon.pullForCreate(params.Config.Image, params.PullPolicy, params.AuthConfig); err != nil {
+				return createResponse("", warnings), err
+			}
+			// the image pulled for the container belongs to its owner
+			if owner := params.Config.Labels[backend.OwnerLabel]; missing != nil && owner != "" && daemon.configStore.Tenancy {
+				daemon.ClaimImage(params.Config.Image, owner)
+			}
+		}
+		// A missing image is reported by create below.
+		if img, err := daemon.GetImage(params.Config.Image); err == nil {
+			w, err := daemon.verifyImageCompatibility(img, params.HostConfig)
+			warnings = append(warnings, w...)
+			if err != nil {
+				return createResponse("", warnings), err
+			}
+		}
+	}
+
+	if err := daemon.verifyTenantReferences(params.Config.Labels[backend.OwnerLabel], params.Config, params.HostConfig, params.NetworkingConfig); err != nil {
+		return createResponse("", warnings), err
+	}
+
+	var endpointsConfig map[string]*networktypes.EndpointSettings
+	if params.NetworkingConfig != nil {
+		endpointsConfig = params.NetworkingConfig.EndpointsConfig
+	}
+	if err := daemon.checkStaticAddressConflicts("", endpointsConfig, params.HostConfig.NetworkMode, params.Config.MacAddress); err != nil {
+		return createResponse("", warnings), err
+	}
+
+	if params.DryRun {
+		return createResponse("", append(warnings, verifyPortBindings(params.HostConfig)...)), daemon.validateCreate(params)
 	}
 
 	container, err := daemon.create(params)
 	if err != nil {
-		return types.ContainerCreateResponse{Warnings: warnings}, daemon.imageNotExistToErrcode(err)
+		return createResponse("", warnings), daemon.imageNotExistToErrcode(err)
 	}
 
-	return types.ContainerCreateResponse{ID: container.ID, Warnings: warnings}, nil
+	if container.HostConfig.Isolation != requestedIsolation {
+		daemon.LogContainerEventWithAttributes(container, "isolation_fallback", map[string]string{
+			"requested": string(requestedIsolation),
+			"isolation": string(container.HostConfig.Isolation),
+		})
+	}
+
+	return createResponse(container.ID, append(warnings, verifyPortBindings(container.HostConfig)...)), nil
+}
+
+// createResponse returns the response to the create of the container with
+// the given ID.
+func createResponse(id string, warnings []types.ContainerWarning) types.ContainerCreateResponse {
+	return types.ContainerCreateResponse{
+		ID:             id,
+		Warnings:       warningMessages(warnings),
+		WarningDetails: warnings,
+	}
 }
 
 // Create creates a new container from the given configuration with a given name.
@@ -65,6 +128,10 @@ func (daemon *Daemon) create(params types.ContainerCreateConfig) (retC *containe
 		imgID = img.ID()
 	}
 
+	createConfig := &containertypes.Config{}
+	if err := deepCopy(createConfig, params.Config); err != nil {
+		return nil, err
+	}
 	if err := daemon.mergeAndVerifyConfig(params.Config, img); err != nil {
 		return nil, err
 	}
@@ -76,17 +143,29 @@ func (daemon *Daemon) create(params types.ContainerCreateConfig) (retC *containe
 	if container, err = daemon.newContainer(params.Name, params.Config, imgID); err != nil {
 		return nil, err
 	}
+	// Everything allocated for the container is released if a later step
+	// fails, so that a failed create leaves no layer, volume reference or
+	// name behind.
+	var undo rollback
 	defer func() {
 		if retErr != nil {
-			if err := daemon.cleanupContainer(container, true); err != nil {
-				logrus.Errorf("failed to cleanup container on create error: %v", err)
-			}
+			undo.run(container.ID)
 		}
 	}()
+	undo.add("name", func() error {
+		daemon.nameIndex.Delete(container.ID)
+		return nil
+	})
+
+	container.CreateConfig = createConfig
 
 	if err := daemon.setSecurityOptions(container, params.HostConfig); err != nil {
 		return nil, err
 	}
+	undo.add("security labels", func() error {
+		selinuxFreeLxcContexts(container.ProcessLabel)
+		return nil
+	})
 
 	container.HostConfig.StorageOpt = params.HostConfig.StorageOpt
 
@@ -94,6 +173,14 @@ func (daemon *Daemon) create(params types.ContainerCreateConfig) (retC *containe
 	if err := daemon.setRWLayer(container); err != nil {
 		return nil, err
 	}
+	undo.add("root filesystem", func() error {
+		metadata, err := daemon.layerStore.ReleaseRWLayer(container.RWLayer)
+		layer.LogReleaseMetadata(metadata)
+		if err != nil && err != layer.ErrMountDoesNotExist {
+			return err
+		}
+		return nil
+	})
 
 	rootUID, rootGID, err := idtools.GetRootUIDGID(daemon.uidMaps, daemon.gidMaps)
 	if err != nil {
@@ -102,17 +189,27 @@ func (daemon *Daemon) create(params types.ContainerCreateConfig) (retC *containe
 	if err := idtools.MkdirAs(container.Root, 0700, rootUID, rootGID); err != nil {
 		return nil, err
 	}
+	undo.add("container directory", func() error {
+		return os.RemoveAll(container.Root)
+	})
+
+	if err := daemon.placeCPUs(container, params.HostConfig); err != nil {
+		return nil, err
+	}
+	undo.add("CPUs", func() error {
+		daemon.cpuAllocator.release(container.ID)
+		return nil
+	})
 
+	// The mount points and the links are registered one after the other,
+	// undoing them is safe whichever failed.
+	undo.add("mount points", func() error {
+		daemon.linkIndex.delete(container)
+		return daemon.removeMountPoints(container, true)
+	})
 	if err := daemon.setHostConfig(container, params.HostConfig); err != nil {
 		return nil, err
 	}
-	defer func() {
-		if retErr != nil {
-			if err := daemon.removeMountPoints(container, true); err != nil {
-				logrus.Error(err)
-			}
-		}
-	}()
 
 	if err := daemon.createContainerPlatformSpecificSettings(container, params.Config, params.HostConfig); err != nil {
 		return nil, err
@@ -134,7 +231,19 @@ func (daemon *Daemon) create(params types.ContainerCreateConfig) (retC *containe
 	if err := daemon.Register(container); err != nil {
 		return nil, err
 	}
+	undo.add("registration", func() error {
+		daemon.idIndex.Delete(container.ID)
+		daemon.containers.Delete(container.ID)
+		return nil
+	})
+	undo.add("create hooks", func() error {
+		return daemon.runLifecycleHooks(container, hookDestroy)
+	})
+	if err := daemon.runLifecycleHooks(container, hookCreate); err != nil {
+		return nil, err
+	}
 	daemon.LogContainerEvent(container, "create")
+	daemon.auditOptions(container, "create", riskyOptions(container.HostConfig), params.Requester)
 	return container, nil
 }
 
@@ -204,6 +313,12 @@ func (daemon *Daemon) VolumeCreate(name, driverName string, opts, labels map[str
 	if name == "" {
 		name = stringid.GenerateNonCryptoID()
 	}
+	// the volume of another user is not returned as created
+	if owner := labels[backend.OwnerLabel]; owner != "" && daemon.configStore.Tenancy {
+		if v, err := daemon.volumes.Get(name); err == nil && volumeOwner(v) != owner {
+			return nil, fmt.Errorf("A volume named %s already exists. Choose a different volume name.", name)
+		}
+	}
 
 	v, err := daemon.volumes.Create(name, driverName, opts, labels)
 	if err != nil {
@@ -0,0 +1,133 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/volume"
+	volumedrivers "github.com/docker/docker/volume/drivers"
+	"github.com/docker/engine-api/types"
+	containertypes "github.com/docker/engine-api/types/container"
+	networktypes "github.com/docker/engine-api/types/network"
+	"github.com/opencontainers/runc/libcontainer/label"
+)
+
+// validateCreate runs the checks of create on params for a dry run of
+// ContainerCreate, without reserving a name, creating a layer, a volume or
+// an endpoint. The settings verified by ContainerCreate itself are not
+// checked again.
+func (daemon *Daemon) validateCreate(params types.ContainerCreateConfig) error {
+	var img *image.Image
+	if params.Config.Image != "" {
+		var err error
+		if img, err = daemon.GetImage(params.Config.Image); err != nil {
+			return daemon.imageNotExistToErrcode(err)
+		}
+	}
+
+	config := &containertypes.Config{}
+	if err := deepCopy(config, params.Config); err != nil {
+		return err
+	}
+	if err := daemon.mergeAndVerifyConfig(config, img); err != nil {
+		return err
+	}
+	logConfig := params.HostConfig.LogConfig
+	if err := daemon.mergeAndVerifyLogConfig(&logConfig); err != nil {
+		return err
+	}
+
+	if err := daemon.validateName(params.Name); err != nil {
+		return err
+	}
+
+	c := container.NewBaseContainer("", "")
+	if err := parseSecurityOpt(c, params.HostConfig); err != nil {
+		return err
+	}
+	if c.ProcessLabel != "" {
+		label.UnreserveLabel(c.ProcessLabel)
+	}
+
+	if err := daemon.validateMounts(params.HostConfig); err != nil {
+		return err
+	}
+	return daemon.validateNetworks(params.HostConfig, params.NetworkingConfig)
+}
+
+// validateName checks that reserveName would accept name.
+func (daemon *Daemon) validateName(name string) error {
+	if name == "" {
+		return nil
+	}
+	if !validContainerNamePattern.MatchString(name) {
+		return fmt.Errorf("Invalid container name (%s), only %s are allowed", name, validContainerNameChars)
+	}
+	if name[0] != '/' {
+		name = "/" + name
+	}
+	if id, err := daemon.nameIndex.Get(name); err == nil {
+		return fmt.Errorf("Conflict. The name %q is already in use by container %s. You have to remove (or rename) that container to be able to reuse that name.", name, id)
+	}
+	return nil
+}
+
+// validateMounts checks the mounts of hostConfig as registerMountPoints
+// does, without creating the named volumes.
+func (daemon *Daemon) validateMounts(hostConfig *containertypes.HostConfig) error {
+	for _, v := range hostConfig.VolumesFrom {
+		containerID, _, err := volume.ParseVolumesFrom(v)
+		if err != nil {
+			return err
+		}
+		if _, err := daemon.GetContainer(containerID); err != nil {
+			return err
+		}
+	}
+
+	binds := map[string]bool{}
+	for _, b := range hostConfig.Binds {
+		bind, err := volume.ParseMountSpec(b, hostConfig.VolumeDriver)
+		if err != nil {
+			return err
+		}
+		if binds[bind.Destination] {
+			return fmt.Errorf("Duplicate mount point '%s'", bind.Destination)
+		}
+		binds[bind.Destination] = true
+
+		if len(bind.Name) > 0 {
+			if _, err := daemon.volumes.Get(bind.Name); err == nil {
+				continue
+			}
+			if _, err := volumedrivers.GetDriver(bind.Driver); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateNetworks checks that the networks and the container the container
+// would be attached to exist.
+func (daemon *Daemon) validateNetworks(hostConfig *containertypes.HostConfig, networkingConfig *networktypes.NetworkingConfig) error {
+	if hostConfig.NetworkMode.IsContainer() {
+		if _, err := daemon.GetContainer(hostConfig.NetworkMode.ConnectedContainer()); err != nil {
+			return err
+		}
+	} else if hostConfig.NetworkMode.IsUserDefined() {
+		if _, err := daemon.FindNetwork(hostConfig.NetworkMode.NetworkName()); err != nil {
+			return err
+		}
+	}
+
+	if networkingConfig != nil {
+		for nw := range networkingConfig.EndpointsConfig {
+			if _, err := daemon.FindNetwork(nw); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -23,6 +23,7 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	containerd "github.com/docker/containerd/api/grpc/types"
+	"github.com/docker/distribution/digest"
 	"github.com/docker/docker/api"
 	"github.com/docker/docker/builder"
 	"github.com/docker/docker/container"
@@ -38,6 +39,8 @@ import (
 	_ "github.com/docker/docker/daemon/graphdriver/register"
 	"github.com/docker/docker/daemon/network"
 	dmetadata "github.com/docker/docker/distribution/metadata"
+	"github.com/docker/docker/distribution/peer"
+	"github.com/docker/docker/distribution/scan"
 	"github.com/docker/docker/distribution/xfer"
 	"github.com/docker/docker/dockerversion"
 	"github.com/docker/docker/image"
@@ -45,7 +48,6 @@ import (
 	"github.com/docker/docker/layer"
 	"github.com/docker/docker/libcontainerd"
 	"github.com/docker/docker/migrate/v1"
-	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/fileutils"
 	"github.com/docker/docker/pkg/graphdb"
 	"github.com/docker/docker/pkg/idtools"
@@ -53,6 +55,7 @@ import (
 	"github.com/docker/docker/pkg/progress"
 	"github.com/docker/docker/pkg/registrar"
 	"github.com/docker/docker/pkg/signal"
+	"github.com/docker/docker/pkg/staging"
 	"github.com/docker/docker/pkg/streamformatter"
 	"github.com/docker/docker/pkg/stringid"
 	"github.com/docker/docker/pkg/sysinfo"
@@ -61,6 +64,8 @@ import (
 	"github.com/docker/docker/reference"
 	"github.com/docker/docker/registry"
 	"github.com/docker/docker/runconfig"
+	runconfigopts "github.com/docker/docker/runconfig/opts"
+	"github.com/docker/docker/trust"
 	"github.com/docker/docker/utils"
 	volumedrivers "github.com/docker/docker/volume/drivers"
 	"github.com/docker/docker/volume/local"
@@ -98,20 +103,35 @@ type Daemon struct {
 	referenceStore            reference.Store
 	downloadManager           *xfer.LayerDownloadManager
 	uploadManager             *xfer.LayerUploadManager
+	layerPeers                *peer.Peers
+	layerCache                *peer.Cache
+	layerPeerListener         net.Listener
+	downloadStaging           *staging.Area
+	buildStaging              *staging.Area
+	buildCache                *buildCache
+	scanCache                 *scan.Cache
+	scanHook                  *scan.Hook
+	imageOwners               *imageOwners
 	distributionMetadataStore dmetadata.Store
 	trustKey                  libtrust.PrivateKey
+	trustService              *trust.Service
 	idIndex                   *truncindex.TruncIndex
 	configStore               *Config
 	statsCollector            *statsCollector
 	defaultLogConfig          containertypes.LogConfig
 	RegistryService           *registry.Service
 	EventsService             *events.Events
+	eventWebhook              *events.Webhook
+	eventLog                  *events.EventLog
 	netController             libnetwork.NetworkController
 	volumes                   *store.VolumeStore
 	discoveryWatcher          discoveryReloader
 	root                      string
 	seccompEnabled            bool
+	cgroup2Runtime            bool // Set if the runtime supports cgroup v2, see runtimeSupportsCgroup2
 	shutdown                  bool
+	restored                  bool // Set once the containers are restored, see SystemReadiness
+	restoredMu                sync.Mutex
 	uidMaps                   []idtools.IDMap
 	gidMaps                   []idtools.IDMap
 	layerStore                layer.Store
@@ -119,7 +139,17 @@ type Daemon struct {
 	nameIndex                 *registrar.Registrar
 	linkIndex                 *linkIndex
 	containerd                libcontainerd.Client
+	containerdRemote          libcontainerd.Remote
+	logRing                   *logRing
+	operations                operationTracker
+	startTimers               startTimers
+	shutdownCtx               context.Context
+	cancelShutdown            context.CancelFunc
 	defaultIsolation          containertypes.Isolation // Default isolation mode on Windows
+	hyperVFallback            bool                     // Run process isolated containers of mismatched images with hyperv isolation on Windows
+	cpuAllocator              *cpuAllocator            // CPUs assigned to the containers with an auto cpuset
+	rootlessPorts             *rootlessPorts           // Ports forwarded from the host in rootless mode
+	jobs                      *jobStore                // Jobs running containers on a schedule
 }
 
 // GetContainer looks for a container using the provided information, which could be
@@ -280,6 +310,7 @@ func (daemon *Daemon) restore() error {
 			continue
 		}
 	}
+	daemon.restoreCPUPlacements(containers)
 	var wg sync.WaitGroup
 	var mapLock sync.Mutex
 	for _, c := range containers {
@@ -293,7 +324,7 @@ func (daemon *Daemon) restore() error {
 					logrus.Errorf("Failed to ReinitRWLayer for %s due to %s", c.ID, err)
 					return
 				}
-				if err := daemon.containerd.Restore(c.ID, libcontainerd.WithRestartManager(rm)); err != nil {
+				if err := daemon.containerd.Restore(c.ID, libcontainerd.WithRestartManager(rm), libcontainerd.WithRestoredPid(c.Pid)); err != nil {
 					logrus.Errorf("Failed to restore with containerd: %q", err)
 					return
 				}
@@ -305,6 +336,9 @@ func (daemon *Daemon) restore() error {
 				restartContainers[c] = make(chan struct{})
 				mapLock.Unlock()
 			}
+			if !c.IsRunning() && !c.IsPaused() {
+				daemon.reserveStaticAddresses(c)
+			}
 
 			if c.RemovalInProgress {
 				// We probably crashed in the middle of a removal, reset
@@ -376,7 +410,7 @@ func (daemon *Daemon) restore() error {
 
 			// Make sure networks are available before starting
 			daemon.waitForNetworks(c)
-			if err := daemon.containerStart(c); err != nil {
+			if err := daemon.containerStart(context.Background(), c); err != nil {
 				logrus.Errorf("Failed to start container %s: %s", c.ID, err)
 			}
 			close(chNotify)
@@ -637,6 +671,9 @@ func NewDaemon(config *Config, registryService *registry.Service, containerdRemo
 	if err := verifyDaemonSettings(config); err != nil {
 		return nil, err
 	}
+	if err := verifyRootlessEnvironment(config); err != nil {
+		return nil, err
+	}
 
 	// Do we have a disabled network?
 	config.DisableBridge = isBridgeNetworkDisabled(config)
@@ -651,10 +688,6 @@ func NewDaemon(config *Config, registryService *registry.Service, containerdRemo
 		return nil, err
 	}
 
-	// set up SIGUSR1 handler on Unix-like systems, or a Win32 global event
-	// on Windows to dump Go routine stacks
-	setupDumpStackTrap()
-
 	uidMaps, gidMaps, err := setupRemappedRoot(config)
 	if err != nil {
 		return nil, err
@@ -664,25 +697,16 @@ func NewDaemon(config *Config, registryService *registry.Service, containerdRemo
 		return nil, err
 	}
 
-	// get the canonical path to the Docker root directory
-	var realRoot string
-	if _, err := os.Stat(config.Root); err != nil && os.IsNotExist(err) {
-		realRoot = config.Root
-	} else {
-		realRoot, err = fileutils.ReadSymlinkedDirectory(config.Root)
-		if err != nil {
-			return nil, fmt.Errorf("Unable to get the full path to root (%s): %s", config.Root, err)
-		}
-	}
-
-	if err = setupDaemonRoot(config, realRoot, rootUID, rootGID); err != nil {
+	// set up the Docker root directory, and the data roots set apart from
+	// it, with their canonical path
+	if err = setupRoots(config, rootUID, rootGID); err != nil {
 		return nil, err
 	}
 
 	// set up the tmpDir to use a canonical path
-	tmp, err := tempDir(config.Root, rootUID, rootGID)
+	tmp, err := tempDir(config.tmpRoot(), rootUID, rootGID)
 	if err != nil {
-		return nil, fmt.Errorf("Unable to get the TempDir under %s: %s", config.Root, err)
+		return nil, fmt.Errorf("Unable to get the TempDir under %s: %s", config.tmpRoot(), err)
 	}
 	realTmp, err := fileutils.ReadSymlinkedDirectory(tmp)
 	if err != nil {
@@ -691,6 +715,15 @@ func NewDaemon(config *Config, registryService *registry.Service, containerdRemo
 	os.Setenv("TMPDIR", realTmp)
 
 	d := &Daemon{configStore: config}
+	d.shutdownCtx, d.cancelShutdown = context.WithCancel(context.Background())
+	// Keep recent engine logs around for debug bundles
+	d.logRing = newLogRing(bundleLogEntries)
+	logrus.AddHook(d.logRing)
+
+	// set up SIGUSR1 handler on Unix-like systems, or a Win32 global event
+	// on Windows to dump Go routine stacks and the state of the daemon
+	setupDumpStackTrap(d.dumpState)
+
 	// Ensure the daemon is properly shutdown if there is a failure during
 	// initialization
 	defer func() {
@@ -713,7 +746,7 @@ func NewDaemon(config *Config, registryService *registry.Service, containerdRemo
 	}
 
 	installDefaultAppArmorProfile()
-	daemonRepo := filepath.Join(config.Root, "containers")
+	daemonRepo := filepath.Join(config.containerRoot(), "containers")
 	if err := idtools.MkdirAllAs(daemonRepo, 0700, rootUID, rootGID); err != nil && !os.IsExist(err) {
 		return nil, err
 	}
@@ -723,8 +756,8 @@ func NewDaemon(config *Config, registryService *registry.Service, containerdRemo
 		driverName = config.GraphDriver
 	}
 	d.layerStore, err = layer.NewStoreFromOptions(layer.StoreOptions{
-		StorePath:                 config.Root,
-		MetadataStorePathTemplate: filepath.Join(config.Root, "image", "%s", "layerdb"),
+		StorePath:                 config.imageRoot(),
+		MetadataStorePathTemplate: filepath.Join(config.imageRoot(), "image", "%s", "layerdb"),
 		GraphDriver:               driverName,
 		GraphDriverOptions:        config.GraphOptions,
 		UIDMaps:                   uidMaps,
@@ -735,7 +768,7 @@ func NewDaemon(config *Config, registryService *registry.Service, containerdRemo
 	}
 
 	graphDriver := d.layerStore.DriverName()
-	imageRoot := filepath.Join(config.Root, "image", graphDriver)
+	imageRoot := filepath.Join(config.imageRoot(), "image", graphDriver)
 
 	// Configure and validate the kernels security support
 	if err := configureKernelSecuritySupport(config, graphDriver); err != nil {
@@ -746,6 +779,14 @@ func NewDaemon(config *Config, registryService *registry.Service, containerdRemo
 	d.downloadManager = xfer.NewLayerDownloadManager(d.layerStore, *config.MaxConcurrentDownloads)
 	logrus.Debugf("Max Concurrent Uploads: %d", *config.MaxConcurrentUploads)
 	d.uploadManager = xfer.NewLayerUploadManager(*config.MaxConcurrentUploads)
+	d.setTransferRates(config)
+	if err := d.initStaging(config); err != nil {
+		return nil, err
+	}
+
+	if err := d.initLayerPeers(config); err != nil {
+		return nil, err
+	}
 
 	ifs, err := image.NewFSStoreBackend(filepath.Join(imageRoot, "imagedb"))
 	if err != nil {
@@ -773,6 +814,10 @@ func NewDaemon(config *Config, registryService *registry.Service, containerdRemo
 	if err := system.MkdirAll(trustDir, 0700); err != nil {
 		return nil, err
 	}
+	d.trustService, err = trust.NewService(trustDir, registryService)
+	if err != nil {
+		return nil, err
+	}
 
 	distributionMetadataStore, err := dmetadata.NewFSMetadataStore(filepath.Join(imageRoot, "distribution"))
 	if err != nil {
@@ -786,6 +831,24 @@ func NewDaemon(config *Config, registryService *registry.Service, containerdRemo
 		return nil, fmt.Errorf("Couldn't create Tag store repositories: %s", err)
 	}
 
+	d.buildCache, err = newBuildCache(filepath.Join(imageRoot, "buildcache.json"))
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't load the build cache records: %s", err)
+	}
+
+	d.scanCache, err = scan.NewCache(filepath.Join(imageRoot, "scans.json"))
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't load the image scan results: %s", err)
+	}
+	if config.ImageScanner != "" {
+		d.scanHook = scan.NewHook(scan.NewPlugin(config.ImageScanner), config.ImageScanPolicy, d.scanCache)
+	}
+
+	d.imageOwners, err = newImageOwners(filepath.Join(imageRoot, "owners.json"))
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't load the owners of the images: %s", err)
+	}
+
 	if err := restoreCustomImage(d.imageStore, d.layerStore, referenceStore); err != nil {
 		return nil, fmt.Errorf("Couldn't restore custom images: %s", err)
 	}
@@ -802,10 +865,26 @@ func NewDaemon(config *Config, registryService *registry.Service, containerdRemo
 		return nil, err
 	}
 
+	// Terminate the compute systems orphaned by a previous run before
+	// reclaiming the networking artifacts they hold.
+	adopted := d.reapOrphanedComputeSystems(config, daemonRepo)
+
+	// The endpoints are read before the network controller removes those
+	// of the containers that were running.
+	recorded, err := recordedInterfaces(config.Root)
+	if err != nil {
+		logrus.Warnf("Could not read the recorded endpoints to look for stale networking artifacts: %v", err)
+	}
+
 	d.netController, err = d.initNetworkController(config)
 	if err != nil {
 		return nil, fmt.Errorf("Error initializing network controller: %v", err)
 	}
+	// The endpoints of the adopted compute systems can't be told apart from
+	// the stale ones.
+	if !adopted {
+		d.reclaimStaleNetworkResources(recorded, config.NetworkCleanupDryRun)
+	}
 
 	sysInfo := sysinfo.New(false)
 	// Check if Devices cgroup is mounted, it is hard requirement for container security,
@@ -834,12 +913,29 @@ func NewDaemon(config *Config, registryService *registry.Service, containerdRemo
 	d.uidMaps = uidMaps
 	d.gidMaps = gidMaps
 	d.seccompEnabled = sysInfo.Seccomp
+	d.cgroup2Runtime = runtimeSupportsCgroup2()
 
 	d.nameIndex = registrar.NewRegistrar()
 	d.linkIndex = newLinkIndex()
+	d.cpuAllocator = newCPUAllocator()
+	d.rootlessPorts = newRootlessPorts()
+
+	eventWebhook, err := newEventWebhook(config)
+	if err != nil {
+		return nil, err
+	}
+	d.setEventWebhook(eventWebhook)
+
+	eventLog, err := newEventLog(config)
+	if err != nil {
+		return nil, err
+	}
+	d.setEventLog(eventLog)
 
 	go d.execCommandGC()
+	go d.monitorNetworkQuotas()
 
+	d.containerdRemote = containerdRemote
 	d.containerd, err = containerdRemote.Client(d)
 	if err != nil {
 		return nil, err
@@ -849,6 +945,11 @@ func NewDaemon(config *Config, registryService *registry.Service, containerdRemo
 		return nil, err
 	}
 
+	if err := d.initJobs(filepath.Join(config.Root, "jobs.json")); err != nil {
+		return nil, fmt.Errorf("Couldn't load the jobs: %s", err)
+	}
+	d.setRestored()
+
 	return d, nil
 }
 
@@ -863,7 +964,7 @@ func (daemon *Daemon) shutdownContainer(c *container.Container) error {
 		if !ok {
 			return fmt.Errorf("System doesn not support SIGTERM")
 		}
-		if err := daemon.kill(c, int(sig)); err != nil {
+		if err := daemon.kill(context.Background(), c, int(sig)); err != nil {
 			return fmt.Errorf("sending SIGTERM to container %s with error: %v", c.ID, err)
 		}
 		if err := daemon.containerUnpause(c); err != nil {
@@ -875,7 +976,7 @@ func (daemon *Daemon) shutdownContainer(c *container.Container) error {
 			if !ok {
 				return fmt.Errorf("System does not support SIGKILL")
 			}
-			if err := daemon.kill(c, int(sig)); err != nil {
+			if err := daemon.kill(context.Background(), c, int(sig)); err != nil {
 				logrus.Errorf("Failed to SIGKILL container %s", c.ID)
 			}
 			c.WaitStop(-1 * time.Second)
@@ -883,7 +984,7 @@ func (daemon *Daemon) shutdownContainer(c *container.Container) error {
 		}
 	}
 	// If container failed to exit in 10 seconds of SIGTERM, then using the force
-	if err := daemon.containerStop(c, 10); err != nil {
+	if err := daemon.containerStop(c, 10, nil); err != nil {
 		return fmt.Errorf("Stop container %s with error: %v", c.ID, err)
 	}
 
@@ -894,7 +995,18 @@ func (daemon *Daemon) shutdownContainer(c *container.Container) error {
 // Shutdown stops the daemon.
 func (daemon *Daemon) Shutdown() error {
 	daemon.shutdown = true
-	if daemon.containers != nil {
+	// Abort the runtime calls made on behalf of API requests; stopping
+	// containers below does not depend on them.
+	if daemon.cancelShutdown != nil {
+		daemon.cancelShutdown()
+	}
+	// No job starts a container while they are stopped.
+	if daemon.jobs != nil {
+		daemon.jobs.stop()
+	}
+	if daemon.containers != nil && daemon.liveRestoreEnabled() {
+		logrus.Info("Leaving the containers running for live restore")
+	} else if daemon.containers != nil {
 		logrus.Debug("starting clean shutdown of all containers...")
 		daemon.containers.ApplyAll(func(c *container.Container) {
 			if !c.IsRunning() {
@@ -912,6 +1024,13 @@ func (daemon *Daemon) Shutdown() error {
 		})
 	}
 
+	if daemon.layerPeerListener != nil {
+		daemon.layerPeerListener.Close()
+	}
+
+	daemon.setEventWebhook(nil)
+	daemon.setEventLog(nil)
+
 	// trigger libnetwork Stop only if it's initialized
 	if daemon.netController != nil {
 		daemon.netController.Stop()
@@ -962,8 +1081,28 @@ func (daemon *Daemon) Unmount(container *container.Container) error {
 	return nil
 }
 
-func (daemon *Daemon) kill(c *container.Container, sig int) error {
-	return daemon.containerd.Signal(c.ID, sig)
+func (daemon *Daemon) kill(ctx context.Context, c *container.Container, sig int) error {
+	// Signals are also sent while the daemon shuts down, so they are not
+	// tied to the daemon's lifetime like other runtime calls.
+	return daemon.containerd.Signal(ctx, c.ID, sig)
+}
+
+// runtimeContext returns a context for a runtime call made on behalf of
+// ctx, which is also cancelled when the daemon shuts down. The returned
+// cancel function must be called once the call completes.
+func (daemon *Daemon) runtimeContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	if daemon.shutdownCtx == nil {
+		return ctx, cancel
+	}
+	go func() {
+		select {
+		case <-daemon.shutdownCtx.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
 }
 
 func (daemon *Daemon) subscribeToContainerStats(c *container.Container) chan interface{} {
@@ -974,10 +1113,6 @@ func (daemon *Daemon) unsubscribeToContainerStats(c *container.Container, ch cha
 	daemon.statsCollector.unsubscribe(c, ch)
 }
 
-func (daemon *Daemon) changes(container *container.Container) ([]archive.Change, error) {
-	return container.RWLayer.Changes()
-}
-
 func writeDistributionProgress(cancelFunc func(), outStream io.Writer, progressChan <-chan progress.Progress) {
 	progressOutput := streamformatter.NewJSONStreamFormatter().NewProgressOutput(outStream, false)
 	operationCancelled := false
@@ -1011,10 +1146,19 @@ func isBrokenPipe(e error) bool {
 // ExportImage exports a list of images to the given output stream. The
 // exported images are archived into a tar when written to the output
 // stream. All images with the given tag and all versions containing
-// the same tag are exported. names is the set of tags to export, and
+// the same tag are exported. names is the set of tags to export, format
+// is either "docker" (the default) or "oci" for an OCI image layout, and
 // outStream is the writer which the images are written to.
-func (daemon *Daemon) ExportImage(names []string, outStream io.Writer) error {
-	imageExporter := tarexport.NewTarExporter(daemon.imageStore, daemon.layerStore, daemon.referenceStore, daemon)
+func (daemon *Daemon) ExportImage(names []string, format string, outStream io.Writer) error {
+	var imageExporter image.Exporter
+	switch format {
+	case "", "docker":
+		imageExporter = tarexport.NewTarExporter(daemon.imageStore, daemon.layerStore, daemon.referenceStore, daemon)
+	case "oci":
+		imageExporter = tarexport.NewOCIExporter(daemon.imageStore, daemon.layerStore, daemon.referenceStore, daemon)
+	default:
+		return fmt.Errorf("invalid export format %q: must be docker or oci", format)
+	}
 	return imageExporter.Save(names, outStream)
 }
 
@@ -1081,6 +1225,9 @@ func (daemon *Daemon) LookupImage(name string) (*types.ImageInspect, error) {
 		VirtualSize:     size, // TODO: field unused, deprecate
 		RootFS:          rootFSToAPIType(img.RootFS),
 	}
+	if result, ok := daemon.scanCache.Get(digest.Digest(img.ID())); ok {
+		imageInspect.Scan = &result
+	}
 
 	imageInspect.GraphDriver.Name = daemon.GraphDriverName()
 
@@ -1091,7 +1238,7 @@ func (daemon *Daemon) LookupImage(name string) (*types.ImageInspect, error) {
 
 // LoadImage uploads a set of images into the repository. This is the
 // complement of ImageExport.  The input stream is an uncompressed tar
-// ball containing images and metadata.
+// ball containing images and metadata, or an OCI image layout.
 func (daemon *Daemon) LoadImage(inTar io.ReadCloser, outStream io.Writer, quiet bool) error {
 	imageExporter := tarexport.NewTarExporter(daemon.imageStore, daemon.layerStore, daemon.referenceStore, daemon)
 	return imageExporter.Load(inTar, outStream, quiet)
@@ -1214,8 +1361,15 @@ func (daemon *Daemon) GetImage(refOrID string) (*image.Image, error) {
 	return daemon.imageStore.Get(imgID)
 }
 
-// GetImageOnBuild looks up a Docker image referenced by `name`.
+// GetImageOnBuild looks up a Docker image referenced by `name`. A reference
+// to a repository the registry access lists deny is refused even if the
+// image is available locally.
 func (daemon *Daemon) GetImageOnBuild(name string) (builder.Image, error) {
+	if _, ref, err := reference.ParseIDOrReference(name); err == nil && ref != nil {
+		if err := daemon.RegistryService.CheckAccess(ref); err != nil {
+			return nil, err
+		}
+	}
 	img, err := daemon.GetImage(name)
 	if err != nil {
 		return nil, err
@@ -1291,6 +1445,7 @@ func (daemon *Daemon) GetCachedImageOnBuild(imgID string, cfg *containertypes.Co
 	if cache == nil || err != nil {
 		return "", err
 	}
+	daemon.buildCache.use(cache.ID())
 	return cache.ID().String(), nil
 }
 
@@ -1349,7 +1504,7 @@ func setDefaultMtu(config *Config) {
 
 // verifyContainerSettings performs validation of the hostconfig and config
 // structures.
-func (daemon *Daemon) verifyContainerSettings(hostConfig *containertypes.HostConfig, config *containertypes.Config, update bool) ([]string, error) {
+func (daemon *Daemon) verifyContainerSettings(hostConfig *containertypes.HostConfig, config *containertypes.Config, update bool) ([]types.ContainerWarning, error) {
 
 	// First perform verification of settings common across all platforms.
 	if config != nil {
@@ -1384,6 +1539,23 @@ func (daemon *Daemon) verifyContainerSettings(hostConfig *containertypes.HostCon
 		return nil, nil
 	}
 
+	if err := verifyJobMode(hostConfig); err != nil {
+		return nil, err
+	}
+
+	if hostConfig.NetworkQuota < 0 {
+		return nil, fmt.Errorf("Invalid network quota %d: it must be positive", hostConfig.NetworkQuota)
+	}
+
+	if len(hostConfig.EgressAllow) > 0 {
+		if hostConfig.NetworkMode.IsHost() || hostConfig.NetworkMode.IsContainer() {
+			return nil, fmt.Errorf("Conflicting options: an egress policy and the %s network mode", hostConfig.NetworkMode)
+		}
+		if _, err := runconfigopts.ParseEgressRules(hostConfig.EgressAllow); err != nil {
+			return nil, err
+		}
+	}
+
 	for port := range hostConfig.PortBindings {
 		_, portStr := nat.SplitProtoPort(string(port))
 		if _, err := nat.ParsePort(portStr); err != nil {
@@ -1415,13 +1587,13 @@ func (daemon *Daemon) verifyNetworkingConfig(nwConfig *networktypes.NetworkingCo
 }
 
 func configureVolumes(config *Config, rootUID, rootGID int) (*store.VolumeStore, error) {
-	volumesDriver, err := local.New(config.Root, rootUID, rootGID)
+	volumesDriver, err := local.New(config.volumeRoot(), rootUID, rootGID)
 	if err != nil {
 		return nil, err
 	}
 
 	volumedrivers.Register(volumesDriver, volumesDriver.Name())
-	return store.New(config.Root)
+	return store.New(config.volumeRoot())
 }
 
 // AuthenticateToRegistry checks the validity of credentials in authConfig
@@ -1436,8 +1608,9 @@ var acceptedSearchFilterTags = map[string]bool{
 }
 
 // SearchRegistryForImages queries the registry for images matching
-// term. authConfig is used to login.
-func (daemon *Daemon) SearchRegistryForImages(ctx context.Context, filtersArgs string, term string,
+// term, returning at most limit results if limit is not 0. authConfig is
+// used to login.
+func (daemon *Daemon) SearchRegistryForImages(ctx context.Context, filtersArgs string, term string, limit int,
 	authConfig *types.AuthConfig,
 	headers map[string][]string) (*registrytypes.SearchResults, error) {
 
@@ -1449,7 +1622,7 @@ func (daemon *Daemon) SearchRegistryForImages(ctx context.Context, filtersArgs s
 		return nil, err
 	}
 
-	unfilteredResult, err := daemon.RegistryService.Search(term, authConfig, dockerversion.DockerUserAgent(ctx), headers)
+	unfilteredResult, err := daemon.RegistryService.Search(term, limit, authConfig, dockerversion.DockerUserAgent(ctx), headers)
 	if err != nil {
 		return nil, err
 	}
@@ -1501,6 +1674,9 @@ func (daemon *Daemon) SearchRegistryForImages(ctx context.Context, filtersArgs s
 			}
 		}
 		filteredResults = append(filteredResults, result)
+		if limit > 0 && len(filteredResults) == limit {
+			break
+		}
 	}
 
 	return &registrytypes.SearchResults{
@@ -1522,8 +1698,11 @@ func (daemon *Daemon) GetContainerStats(container *container.Container) (*types.
 		return nil, err
 	}
 
-	if stats.Networks, err = daemon.getNetworkStats(container); err != nil {
-		return nil, err
+	// The runtime reports the network stats of the containers on Windows.
+	if stats.Networks == nil {
+		if stats.Networks, err = daemon.getNetworkStats(container); err != nil {
+			return nil, err
+		}
 	}
 
 	return stats, nil
@@ -1610,6 +1789,12 @@ func (daemon *Daemon) initDiscovery(config *Config) error {
 // - Daemon debug log level.
 // - Daemon max concurrent downloads
 // - Daemon max concurrent uploads
+// - Daemon max download and upload rates
+// - Digest pinning policy
+// - Overcommit admission policy
+// - Event webhook (restarted with the new settings).
+// - Windows event log (restarted with the new settings).
+// - Container lifecycle hooks, their timeout and failure policy.
 // - Cluster discovery (reconfigure and restart).
 func (daemon *Daemon) Reload(config *Config) error {
 	daemon.configStore.reloadLock.Lock()
@@ -1647,9 +1832,85 @@ func (daemon *Daemon) Reload(config *Config) error {
 		daemon.uploadManager.SetConcurrency(*daemon.configStore.MaxConcurrentUploads)
 	}
 
+	if config.IsValueSet("max-download-rate") {
+		daemon.configStore.MaxDownloadRate = config.MaxDownloadRate
+	}
+	if config.IsValueSet("max-upload-rate") {
+		daemon.configStore.MaxUploadRate = config.MaxUploadRate
+	}
+	if config.IsValueSet("max-download-rate-per-layer") {
+		daemon.configStore.MaxDownloadRatePerLayer = config.MaxDownloadRatePerLayer
+	}
+	if config.IsValueSet("max-upload-rate-per-layer") {
+		daemon.configStore.MaxUploadRatePerLayer = config.MaxUploadRatePerLayer
+	}
+	daemon.setTransferRates(daemon.configStore)
+
+	if config.IsValueSet("require-digest-pins") {
+		daemon.configStore.RequireDigestPins = config.RequireDigestPins
+	}
+	if config.IsValueSet("digest-pin-exemptions") {
+		daemon.configStore.DigestPinExemptions = config.DigestPinExemptions
+	}
+
+	if config.IsValueSet("reject-overcommit") {
+		daemon.configStore.RejectOvercommit = config.RejectOvercommit
+	}
+	if config.IsValueSet("overcommit-factor") {
+		daemon.configStore.OvercommitFactor = config.OvercommitFactor
+	}
+
+	if config.IsValueSet("event-webhook") || config.IsValueSet("event-webhook-filters") || config.IsValueSet("event-webhook-secret-file") {
+		if config.IsValueSet("event-webhook") {
+			daemon.configStore.EventWebhook = config.EventWebhook
+		}
+		if config.IsValueSet("event-webhook-filters") {
+			daemon.configStore.EventWebhookFilters = config.EventWebhookFilters
+		}
+		if config.IsValueSet("event-webhook-secret-file") {
+			daemon.configStore.EventWebhookSecretFile = config.EventWebhookSecretFile
+		}
+		eventWebhook, err := newEventWebhook(daemon.configStore)
+		if err != nil {
+			return err
+		}
+		daemon.setEventWebhook(eventWebhook)
+	}
+
+	if err := daemon.reloadEventLog(config); err != nil {
+		return err
+	}
+
+	if config.IsValueSet("lifecycle-hooks") {
+		daemon.configStore.LifecycleHooks = config.LifecycleHooks
+	}
+	if config.IsValueSet("lifecycle-hook-timeout") {
+		daemon.configStore.LifecycleHookTimeout = config.LifecycleHookTimeout
+	}
+	if config.IsValueSet("lifecycle-hook-failure") {
+		daemon.configStore.LifecycleHookFailure = config.LifecycleHookFailure
+	}
+
 	return daemon.reloadClusterDiscovery(config)
 }
 
+// setTransferRates applies the bandwidth limits of config to the pulls and
+// pushes.
+func (daemon *Daemon) setTransferRates(config *Config) {
+	// The rates were validated with the configuration.
+	downloadRate, _ := parseTransferRate(config.MaxDownloadRate)
+	perLayerDownloadRate, _ := parseTransferRate(config.MaxDownloadRatePerLayer)
+	uploadRate, _ := parseTransferRate(config.MaxUploadRate)
+	perLayerUploadRate, _ := parseTransferRate(config.MaxUploadRatePerLayer)
+	logrus.Debugf("Max Download Rate: %d (%d per layer), Max Upload Rate: %d (%d per layer)", downloadRate, perLayerDownloadRate, uploadRate, perLayerUploadRate)
+	if daemon.downloadManager != nil {
+		daemon.downloadManager.SetRateLimit(downloadRate, perLayerDownloadRate)
+	}
+	if daemon.uploadManager != nil {
+		daemon.uploadManager.SetRateLimit(uploadRate, perLayerUploadRate)
+	}
+}
+
 func (daemon *Daemon) reloadClusterDiscovery(config *Config) error {
 	var err error
 	newAdvertise := daemon.configStore.ClusterAdvertise
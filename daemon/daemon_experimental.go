@@ -2,8 +2,11 @@
 
 package daemon
 
-import "github.com/docker/engine-api/types/container"
+import (
+	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/container"
+)
 
-func (daemon *Daemon) verifyExperimentalContainerSettings(hostConfig *container.HostConfig, config *container.Config) ([]string, error) {
+func (daemon *Daemon) verifyExperimentalContainerSettings(hostConfig *container.HostConfig, config *container.Config) ([]types.ContainerWarning, error) {
 	return nil, nil
 }
@@ -24,7 +24,8 @@ func (daemon *Daemon) cleanupMountsByID(id string) error {
 }
 
 func (daemon *Daemon) cleanupMountsFromReaderByID(reader io.Reader, id string, unmount func(target string) error) error {
-	if daemon.root == "" {
+	roots := daemon.mountRoots()
+	if len(roots) == 0 {
 		return nil
 	}
 	var errors []string
@@ -33,7 +34,7 @@ func (daemon *Daemon) cleanupMountsFromReaderByID(reader io.Reader, id string, u
 	sc := bufio.NewScanner(reader)
 	for sc.Scan() {
 		if fields := strings.Fields(sc.Text()); len(fields) >= 4 {
-			if mnt := fields[4]; strings.HasPrefix(mnt, daemon.root) {
+			if mnt := fields[4]; hasAnyPrefix(mnt, roots) {
 				for _, p := range regexps {
 					if p.MatchString(mnt) {
 						if err := unmount(mnt); err != nil {
@@ -58,6 +59,33 @@ func (daemon *Daemon) cleanupMountsFromReaderByID(reader io.Reader, id string, u
 	return nil
 }
 
+// mountRoots returns the directories under which the daemon mounts the
+// shm of the containers and the layers of the storage driver: the daemon
+// root, and the image and container roots set apart from it.
+func (daemon *Daemon) mountRoots() []string {
+	var roots []string
+	if daemon.root != "" {
+		roots = append(roots, daemon.root)
+	}
+	if daemon.configStore != nil {
+		for _, root := range []string{daemon.configStore.ImageRoot, daemon.configStore.ContainerRoot} {
+			if root != "" {
+				roots = append(roots, root)
+			}
+		}
+	}
+	return roots
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // cleanupMounts umounts shm/mqueue mounts for old containers
 func (daemon *Daemon) cleanupMounts() error {
 	return daemon.cleanupMountsByID("")
@@ -87,6 +87,38 @@ func TestCleanupMountsByID(t *testing.T) {
 	}
 }
 
+func TestCleanupMountsInDataRoots(t *testing.T) {
+	d := &Daemon{
+		root: "/var/lib/docker",
+		configStore: &Config{
+			CommonConfig: CommonConfig{
+				ImageRoot:     "/ssd/images",
+				ContainerRoot: "/ssd/containers",
+			},
+		},
+	}
+	mountInfo := `120 100 0:122 / /ssd/images/aufs/mnt/03ca4b49e71f1e49a41108829f4d5c70ac95934526e2af8984a1f65f1de0715d rw,relatime - aufs none rw,si=573b861eb147805b,dio
+116 160 0:107 / /ssd/containers/containers/d045dc441d2e2e1d5b3e328d47e5943811a40819fb47497c5f5a5df2d6d13c37/shm rw,nosuid,nodev,noexec,relatime - tmpfs shm rw,size=65536k
+117 160 0:108 / /hdd/containers/d045dc441d2e2e1d5b3e328d47e5943811a40819fb47497c5f5a5df2d6d13c37/shm rw,nosuid,nodev,noexec,relatime - tmpfs shm rw,size=65536k`
+
+	var unmounted []string
+	unmount := func(target string) error {
+		unmounted = append(unmounted, target)
+		return nil
+	}
+
+	d.cleanupMountsFromReaderByID(strings.NewReader(mountInfo), "", unmount)
+	if len(unmounted) != 2 || unmounted[1] != "/ssd/containers/containers/d045dc441d2e2e1d5b3e328d47e5943811a40819fb47497c5f5a5df2d6d13c37/shm" {
+		t.Fatalf("Expected to unmount the mounts in the data roots only, got %v", unmounted)
+	}
+
+	unmounted = nil
+	d.cleanupMountsFromReaderByID(strings.NewReader(mountInfo), "03ca4b49e71f1e49a41108829f4d5c70ac95934526e2af8984a1f65f1de0715d", unmount)
+	if len(unmounted) != 1 || unmounted[0] != "/ssd/images/aufs/mnt/03ca4b49e71f1e49a41108829f4d5c70ac95934526e2af8984a1f65f1de0715d" {
+		t.Fatalf("Expected to unmount the layer in the image root only, got %v", unmounted)
+	}
+}
+
 func TestNotCleanupMounts(t *testing.T) {
 	d := &Daemon{
 		repository: "",
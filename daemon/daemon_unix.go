@@ -3,10 +3,13 @@
 package daemon
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
 	"runtime/debug"
@@ -19,9 +22,11 @@ import (
 	"github.com/docker/docker/container"
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/layer"
+	"github.com/docker/docker/libcontainerd"
 	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/docker/pkg/parsers"
 	"github.com/docker/docker/pkg/parsers/kernel"
+	"github.com/docker/docker/pkg/rootless"
 	"github.com/docker/docker/pkg/sysinfo"
 	"github.com/docker/docker/reference"
 	"github.com/docker/docker/runconfig"
@@ -30,12 +35,14 @@ import (
 	"github.com/docker/engine-api/types/blkiodev"
 	pblkiodev "github.com/docker/engine-api/types/blkiodev"
 	containertypes "github.com/docker/engine-api/types/container"
+	"github.com/docker/go-connections/nat"
 	"github.com/docker/libnetwork"
 	nwconfig "github.com/docker/libnetwork/config"
 	"github.com/docker/libnetwork/drivers/bridge"
 	"github.com/docker/libnetwork/netlabel"
 	"github.com/docker/libnetwork/netutils"
 	"github.com/docker/libnetwork/options"
+	"github.com/docker/libnetwork/portallocator"
 	lntypes "github.com/docker/libnetwork/types"
 	"github.com/opencontainers/runc/libcontainer/label"
 	"github.com/opencontainers/runc/libcontainer/user"
@@ -264,21 +271,35 @@ func (daemon *Daemon) adaptContainerSettings(hostConfig *containertypes.HostConf
 	return nil
 }
 
-func verifyContainerResources(resources *containertypes.Resources, sysInfo *sysinfo.SysInfo, update bool) ([]string, error) {
-	warnings := []string{}
+// errUnsupportedUpdate returns the error of an update of the resource the
+// kernel does not support. Unlike at creation, where such a resource is
+// discarded with a warning, an update fails so that it does not silently
+// keep the previous limit.
+func errUnsupportedUpdate(resource string) error {
+	return fmt.Errorf("Your kernel does not support %s capabilities, the %s cannot be updated", resource, resource)
+}
+
+func verifyContainerResources(resources *containertypes.Resources, sysInfo *sysinfo.SysInfo, update bool) ([]types.ContainerWarning, error) {
+	var warnings []types.ContainerWarning
 
 	// memory subsystem checks and adjustments
 	if resources.Memory != 0 && resources.Memory < linuxMinMemory {
 		return warnings, fmt.Errorf("Minimum memory limit allowed is 4MB")
 	}
 	if resources.Memory > 0 && !sysInfo.MemoryLimit {
-		warnings = append(warnings, "Your kernel does not support memory limit capabilities. Limitation discarded.")
+		if update {
+			return warnings, errUnsupportedUpdate("memory limit")
+		}
+		warnings = append(warnings, newWarning(warningResourceDiscarded, "Your kernel does not support memory limit capabilities. Limitation discarded."))
 		logrus.Warnf("Your kernel does not support memory limit capabilities. Limitation discarded.")
 		resources.Memory = 0
 		resources.MemorySwap = -1
 	}
+	if resources.MemorySwap != 0 && !sysInfo.SwapLimit && update {
+		return warnings, errUnsupportedUpdate("memory swap limit")
+	}
 	if resources.Memory > 0 && resources.MemorySwap != -1 && !sysInfo.SwapLimit {
-		warnings = append(warnings, "Your kernel does not support swap limit capabilities, memory limited without swap.")
+		warnings = append(warnings, newWarning(warningSwapLimitDiscarded, "Your kernel does not support swap limit capabilities, memory limited without swap."))
 		logrus.Warnf("Your kernel does not support swap limit capabilities, memory limited without swap.")
 		resources.MemorySwap = -1
 	}
@@ -289,7 +310,10 @@ func verifyContainerResources(resources *containertypes.Resources, sysInfo *sysi
 		return warnings, fmt.Errorf("You should always set the Memory limit when using Memoryswap limit, see usage")
 	}
 	if resources.MemorySwappiness != nil && *resources.MemorySwappiness != -1 && !sysInfo.MemorySwappiness {
-		warnings = append(warnings, "Your kernel does not support memory swappiness capabilities, memory swappiness discarded.")
+		if update {
+			return warnings, errUnsupportedUpdate("memory swappiness")
+		}
+		warnings = append(warnings, newWarning(warningResourceDiscarded, "Your kernel does not support memory swappiness capabilities, memory swappiness discarded."))
 		logrus.Warnf("Your kernel does not support memory swappiness capabilities, memory swappiness discarded.")
 		resources.MemorySwappiness = nil
 	}
@@ -300,7 +324,10 @@ func verifyContainerResources(resources *containertypes.Resources, sysInfo *sysi
 		}
 	}
 	if resources.MemoryReservation > 0 && !sysInfo.MemoryReservation {
-		warnings = append(warnings, "Your kernel does not support memory soft limit capabilities. Limitation discarded.")
+		if update {
+			return warnings, errUnsupportedUpdate("memory reservation")
+		}
+		warnings = append(warnings, newWarning(warningResourceDiscarded, "Your kernel does not support memory soft limit capabilities. Limitation discarded."))
 		logrus.Warnf("Your kernel does not support memory soft limit capabilities. Limitation discarded.")
 		resources.MemoryReservation = 0
 	}
@@ -311,7 +338,10 @@ func verifyContainerResources(resources *containertypes.Resources, sysInfo *sysi
 		return warnings, fmt.Errorf("Minimum memory limit should be larger than memory reservation limit, see usage")
 	}
 	if resources.KernelMemory > 0 && !sysInfo.KernelMemory {
-		warnings = append(warnings, "Your kernel does not support kernel memory limit capabilities. Limitation discarded.")
+		if update {
+			return warnings, errUnsupportedUpdate("kernel memory limit")
+		}
+		warnings = append(warnings, newWarning(warningResourceDiscarded, "Your kernel does not support kernel memory limit capabilities. Limitation discarded."))
 		logrus.Warnf("Your kernel does not support kernel memory limit capabilities. Limitation discarded.")
 		resources.KernelMemory = 0
 	}
@@ -319,33 +349,33 @@ func verifyContainerResources(resources *containertypes.Resources, sysInfo *sysi
 		return warnings, fmt.Errorf("Minimum kernel memory limit allowed is 4MB")
 	}
 	if resources.KernelMemory > 0 && !checkKernelVersion(4, 0, 0) {
-		warnings = append(warnings, "You specified a kernel memory limit on a kernel older than 4.0. Kernel memory limits are experimental on older kernels, it won't work as expected and can cause your system to be unstable.")
+		warnings = append(warnings, newWarning(warningKernelMemoryUnstable, "You specified a kernel memory limit on a kernel older than 4.0. Kernel memory limits are experimental on older kernels, it won't work as expected and can cause your system to be unstable."))
 		logrus.Warnf("You specified a kernel memory limit on a kernel older than 4.0. Kernel memory limits are experimental on older kernels, it won't work as expected and can cause your system to be unstable.")
 	}
 	if resources.OomKillDisable != nil && !sysInfo.OomKillDisable {
 		// only produce warnings if the setting wasn't to *disable* the OOM Kill; no point
 		// warning the caller if they already wanted the feature to be off
 		if *resources.OomKillDisable {
-			warnings = append(warnings, "Your kernel does not support OomKillDisable, OomKillDisable discarded.")
+			warnings = append(warnings, newWarning(warningResourceDiscarded, "Your kernel does not support OomKillDisable, OomKillDisable discarded."))
 			logrus.Warnf("Your kernel does not support OomKillDisable, OomKillDisable discarded.")
 		}
 		resources.OomKillDisable = nil
 	}
 
 	if resources.PidsLimit != 0 && !sysInfo.PidsLimit {
-		warnings = append(warnings, "Your kernel does not support pids limit capabilities, pids limit discarded.")
+		warnings = append(warnings, newWarning(warningResourceDiscarded, "Your kernel does not support pids limit capabilities, pids limit discarded."))
 		logrus.Warnf("Your kernel does not support pids limit capabilities, pids limit discarded.")
 		resources.PidsLimit = 0
 	}
 
 	// cpu subsystem checks and adjustments
 	if resources.CPUShares > 0 && !sysInfo.CPUShares {
-		warnings = append(warnings, "Your kernel does not support CPU shares. Shares discarded.")
+		warnings = append(warnings, newWarning(warningResourceDiscarded, "Your kernel does not support CPU shares. Shares discarded."))
 		logrus.Warnf("Your kernel does not support CPU shares. Shares discarded.")
 		resources.CPUShares = 0
 	}
 	if resources.CPUPeriod > 0 && !sysInfo.CPUCfsPeriod {
-		warnings = append(warnings, "Your kernel does not support CPU cfs period. Period discarded.")
+		warnings = append(warnings, newWarning(warningResourceDiscarded, "Your kernel does not support CPU cfs period. Period discarded."))
 		logrus.Warnf("Your kernel does not support CPU cfs period. Period discarded.")
 		resources.CPUPeriod = 0
 	}
@@ -353,7 +383,7 @@ func verifyContainerResources(resources *containertypes.Resources, sysInfo *sysi
 		return warnings, fmt.Errorf("CPU cfs period can not be less than 1ms (i.e. 1000) or larger than 1s (i.e. 1000000)")
 	}
 	if resources.CPUQuota > 0 && !sysInfo.CPUCfsQuota {
-		warnings = append(warnings, "Your kernel does not support CPU cfs quota. Quota discarded.")
+		warnings = append(warnings, newWarning(warningResourceDiscarded, "Your kernel does not support CPU cfs quota. Quota discarded."))
 		logrus.Warnf("Your kernel does not support CPU cfs quota. Quota discarded.")
 		resources.CPUQuota = 0
 	}
@@ -361,24 +391,39 @@ func verifyContainerResources(resources *containertypes.Resources, sysInfo *sysi
 		return warnings, fmt.Errorf("CPU cfs quota can not be less than 1ms (i.e. 1000)")
 	}
 	if resources.CPUPercent > 0 {
-		warnings = append(warnings, "%s does not support CPU percent. Percent discarded.", runtime.GOOS)
+		warnings = append(warnings, newWarning(warningResourceDiscarded, "%s does not support CPU percent. Percent discarded.", runtime.GOOS))
 		logrus.Warnf("%s does not support CPU percent. Percent discarded.", runtime.GOOS)
 		resources.CPUPercent = 0
 	}
+	if resources.CPUGroup != "" {
+		warnings = append(warnings, newWarning(warningResourceDiscarded, "%s does not support CPU groups. CPU group discarded.", runtime.GOOS))
+		logrus.Warnf("%s does not support CPU groups. CPU group discarded.", runtime.GOOS)
+		resources.CPUGroup = ""
+	}
 
 	// cpuset subsystem checks and adjustments
 	if (resources.CpusetCpus != "" || resources.CpusetMems != "") && !sysInfo.Cpuset {
-		warnings = append(warnings, "Your kernel does not support cpuset. Cpuset discarded.")
+		warnings = append(warnings, newWarning(warningResourceDiscarded, "Your kernel does not support cpuset. Cpuset discarded."))
 		logrus.Warnf("Your kernel does not support cpuset. Cpuset discarded.")
 		resources.CpusetCpus = ""
 		resources.CpusetMems = ""
 	}
-	cpusAvailable, err := sysInfo.IsCpusetCpusAvailable(resources.CpusetCpus)
-	if err != nil {
-		return warnings, fmt.Errorf("Invalid value %s for cpuset cpus", resources.CpusetCpus)
-	}
-	if !cpusAvailable {
-		return warnings, fmt.Errorf("Requested CPUs are not available - requested %s, available: %s", resources.CpusetCpus, sysInfo.Cpus)
+	if _, auto, err := parseAutoCpuset(resources.CpusetCpus); auto {
+		// The CPUs are allocated when the container is created.
+		if err != nil {
+			return warnings, err
+		}
+		if update {
+			return warnings, fmt.Errorf("The CPUs of an auto cpuset can only be allocated when the container is created")
+		}
+	} else {
+		cpusAvailable, err := sysInfo.IsCpusetCpusAvailable(resources.CpusetCpus)
+		if err != nil {
+			return warnings, fmt.Errorf("Invalid value %s for cpuset cpus", resources.CpusetCpus)
+		}
+		if !cpusAvailable {
+			return warnings, fmt.Errorf("Requested CPUs are not available - requested %s, available: %s", resources.CpusetCpus, sysInfo.Cpus)
+		}
 	}
 	memsAvailable, err := sysInfo.IsCpusetMemsAvailable(resources.CpusetMems)
 	if err != nil {
@@ -390,7 +435,7 @@ func verifyContainerResources(resources *containertypes.Resources, sysInfo *sysi
 
 	// blkio subsystem checks and adjustments
 	if resources.BlkioWeight > 0 && !sysInfo.BlkioWeight {
-		warnings = append(warnings, "Your kernel does not support Block I/O weight. Weight discarded.")
+		warnings = append(warnings, newWarning(warningResourceDiscarded, "Your kernel does not support Block I/O weight. Weight discarded."))
 		logrus.Warnf("Your kernel does not support Block I/O weight. Weight discarded.")
 		resources.BlkioWeight = 0
 	}
@@ -401,27 +446,39 @@ func verifyContainerResources(resources *containertypes.Resources, sysInfo *sysi
 		return warnings, fmt.Errorf("Invalid QoS settings: %s does not support Maximum IO Bandwidth or Maximum IO IOps", runtime.GOOS)
 	}
 	if len(resources.BlkioWeightDevice) > 0 && !sysInfo.BlkioWeightDevice {
-		warnings = append(warnings, "Your kernel does not support Block I/O weight_device.")
+		warnings = append(warnings, newWarning(warningResourceDiscarded, "Your kernel does not support Block I/O weight_device."))
 		logrus.Warnf("Your kernel does not support Block I/O weight_device. Weight-device discarded.")
 		resources.BlkioWeightDevice = []*pblkiodev.WeightDevice{}
 	}
 	if len(resources.BlkioDeviceReadBps) > 0 && !sysInfo.BlkioReadBpsDevice {
-		warnings = append(warnings, "Your kernel does not support Block read limit in bytes per second.")
+		if update {
+			return warnings, errUnsupportedUpdate("Block I/O read limit in bytes per second")
+		}
+		warnings = append(warnings, newWarning(warningResourceDiscarded, "Your kernel does not support Block read limit in bytes per second."))
 		logrus.Warnf("Your kernel does not support Block I/O read limit in bytes per second. --device-read-bps discarded.")
 		resources.BlkioDeviceReadBps = []*pblkiodev.ThrottleDevice{}
 	}
 	if len(resources.BlkioDeviceWriteBps) > 0 && !sysInfo.BlkioWriteBpsDevice {
-		warnings = append(warnings, "Your kernel does not support Block write limit in bytes per second.")
+		if update {
+			return warnings, errUnsupportedUpdate("Block I/O write limit in bytes per second")
+		}
+		warnings = append(warnings, newWarning(warningResourceDiscarded, "Your kernel does not support Block write limit in bytes per second."))
 		logrus.Warnf("Your kernel does not support Block I/O write limit in bytes per second. --device-write-bps discarded.")
 		resources.BlkioDeviceWriteBps = []*pblkiodev.ThrottleDevice{}
 	}
 	if len(resources.BlkioDeviceReadIOps) > 0 && !sysInfo.BlkioReadIOpsDevice {
-		warnings = append(warnings, "Your kernel does not support Block read limit in IO per second.")
+		if update {
+			return warnings, errUnsupportedUpdate("Block I/O read limit in IO per second")
+		}
+		warnings = append(warnings, newWarning(warningResourceDiscarded, "Your kernel does not support Block read limit in IO per second."))
 		logrus.Warnf("Your kernel does not support Block I/O read limit in IO per second. -device-read-iops discarded.")
 		resources.BlkioDeviceReadIOps = []*pblkiodev.ThrottleDevice{}
 	}
 	if len(resources.BlkioDeviceWriteIOps) > 0 && !sysInfo.BlkioWriteIOpsDevice {
-		warnings = append(warnings, "Your kernel does not support Block write limit in IO per second.")
+		if update {
+			return warnings, errUnsupportedUpdate("Block I/O write limit in IO per second")
+		}
+		warnings = append(warnings, newWarning(warningResourceDiscarded, "Your kernel does not support Block write limit in IO per second."))
 		logrus.Warnf("Your kernel does not support Block I/O write limit in IO per second. --device-write-iops discarded.")
 		resources.BlkioDeviceWriteIOps = []*pblkiodev.ThrottleDevice{}
 	}
@@ -466,8 +523,8 @@ func UsingSystemd(config *Config) bool {
 
 // verifyPlatformContainerSettings performs platform-specific validation of the
 // hostconfig and config structures.
-func verifyPlatformContainerSettings(daemon *Daemon, hostConfig *containertypes.HostConfig, config *containertypes.Config, update bool) ([]string, error) {
-	warnings := []string{}
+func verifyPlatformContainerSettings(daemon *Daemon, hostConfig *containertypes.HostConfig, config *containertypes.Config, update bool) ([]types.ContainerWarning, error) {
+	var warnings []types.ContainerWarning
 	sysInfo := sysinfo.New(true)
 
 	warnings, err := daemon.verifyExperimentalContainerSettings(hostConfig, config)
@@ -481,6 +538,12 @@ func verifyPlatformContainerSettings(daemon *Daemon, hostConfig *containertypes.
 	}
 	warnings = append(warnings, w...)
 
+	if daemon.configStore.Rootless {
+		if err := verifyRootlessResources(&hostConfig.Resources); err != nil {
+			return warnings, err
+		}
+	}
+
 	if hostConfig.ShmSize < 0 {
 		return warnings, fmt.Errorf("SHM size must be greater than 0")
 	}
@@ -489,9 +552,17 @@ func verifyPlatformContainerSettings(daemon *Daemon, hostConfig *containertypes.
 		return warnings, fmt.Errorf("Invalid value %d, range for oom score adj is [-1000, 1000]", hostConfig.OomScoreAdj)
 	}
 
+	if err := verifyOCIHooks(daemon.configStore.OCIHooksDir, hostConfig.Hooks); err != nil {
+		return warnings, err
+	}
+
+	if err := verifyNetworkDevices(hostConfig, config); err != nil {
+		return warnings, err
+	}
+
 	// ip-forwarding does not affect container with '--net=host'
 	if sysInfo.IPv4ForwardingDisabled && !hostConfig.NetworkMode.IsHost() {
-		warnings = append(warnings, "IPv4 forwarding is disabled. Networking will not work.")
+		warnings = append(warnings, newWarning(warningIPForwardingDisabled, "IPv4 forwarding is disabled. Networking will not work."))
 		logrus.Warnf("IPv4 forwarding is disabled. Networking will not work")
 	}
 	// check for various conflicting options with user namespaces
@@ -538,9 +609,111 @@ func verifyDaemonSettings(config *Config) error {
 			return fmt.Errorf("cgroup-parent for systemd cgroup should be a valid slice named as \"xxx.slice\"")
 		}
 	}
+	if _, _, err := parsePublishedPortRange(config.bridgeConfig.PublishedPortRange); err != nil {
+		return err
+	}
+	if config.Rootless && config.RemappedRoot != "" {
+		return fmt.Errorf("You specified --rootless with --userns-remap: the rootless mode already runs the daemon in a user namespace")
+	}
+	return nil
+}
+
+// verifyRootlessEnvironment checks that a rootless daemon runs in the user
+// namespace set up by dockerd --rootless, and that the runtime of the
+// containerd it starts supports the rootless mode.
+func verifyRootlessEnvironment(config *Config) error {
+	if !config.Rootless {
+		return nil
+	}
+	if !rootless.IsChild() {
+		return fmt.Errorf("The rootless mode must be started with dockerd --rootless")
+	}
+	if config.ContainerdAddr == "" {
+		return verifyRootlessRuntime(libcontainerd.RuntimeBinary)
+	}
+	return nil
+}
+
+// verifyRootlessRuntime checks that runtime can run containers without
+// access to the cgroups of the host, with the --rootless option of runc.
+func verifyRootlessRuntime(runtime string) error {
+	out, err := exec.Command(runtime, "--help").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("Failed to query the runtime %s: %v", runtime, err)
+	}
+	if !bytes.Contains(out, []byte("--rootless")) {
+		return fmt.Errorf("The runtime %s does not support the rootless mode: it requires a version of runc with the --rootless option", runtime)
+	}
+	return nil
+}
+
+// runtimeSupportsCgroup2 reports whether the runtime of the containers
+// manages their cgroups in the unified hierarchy of a cgroup v2 host.
+func runtimeSupportsCgroup2() bool {
+	if !sysinfo.IsCgroup2UnifiedMode() {
+		return false
+	}
+	if err := verifyCgroup2Runtime(libcontainerd.RuntimeBinary); err != nil {
+		logrus.Warnf("Containers with resource limits cannot run on this cgroup v2 host: %v", err)
+		return false
+	}
+	return true
+}
+
+// verifyCgroup2Runtime checks that runtime reports the support of cgroup v2
+// in its features. The runtimes which predate the features command ignore the
+// unified resources of the spec.
+func verifyCgroup2Runtime(runtime string) error {
+	out, err := exec.Command(runtime, "features").Output()
+	if err != nil {
+		return fmt.Errorf("The runtime %s does not report its features: it requires a version of runc with cgroup v2 support", runtime)
+	}
+	var features struct {
+		Linux struct {
+			Cgroup struct {
+				V2 bool `json:"v2"`
+			} `json:"cgroup"`
+		} `json:"linux"`
+	}
+	if err := json.Unmarshal(out, &features); err != nil {
+		return fmt.Errorf("Failed to parse the features of the runtime %s: %v", runtime, err)
+	}
+	if !features.Linux.Cgroup.V2 {
+		return fmt.Errorf("The runtime %s does not support cgroup v2", runtime)
+	}
+	return nil
+}
+
+// verifyRootlessResources rejects the resource limits of a container, which
+// a rootless daemon cannot enforce without access to the cgroups.
+func verifyRootlessResources(resources *containertypes.Resources) error {
+	limited := resources.Memory != 0 || (resources.MemorySwap != 0 && resources.MemorySwap != -1) ||
+		resources.MemoryReservation != 0 || resources.KernelMemory != 0 || resources.CPUShares != 0 || resources.CPUPeriod != 0 ||
+		resources.CPUQuota != 0 || resources.CpusetCpus != "" || resources.CpusetMems != "" ||
+		resources.BlkioWeight != 0 || len(resources.BlkioWeightDevice) > 0 ||
+		len(resources.BlkioDeviceReadBps) > 0 || len(resources.BlkioDeviceWriteBps) > 0 ||
+		len(resources.BlkioDeviceReadIOps) > 0 || len(resources.BlkioDeviceWriteIOps) > 0 ||
+		(resources.PidsLimit != 0 && resources.PidsLimit != -1)
+	if limited {
+		return fmt.Errorf("Resource limits are not supported in rootless mode")
+	}
 	return nil
 }
 
+// parsePublishedPortRange parses the range of the host ports allocated to
+// the published ports, of the form "<first port>-<last port>". It returns 0
+// and 0 for the system ephemeral port range if portRange is empty.
+func parsePublishedPortRange(portRange string) (int, int, error) {
+	if portRange == "" {
+		return 0, 0, nil
+	}
+	start, end, err := nat.ParsePortRangeToInt(portRange)
+	if err != nil || start == 0 || !strings.Contains(portRange, "-") {
+		return 0, 0, fmt.Errorf("invalid published port range %s: must be of the form <first port>-<last port>", portRange)
+	}
+	return start, end, nil
+}
+
 // checkSystem validates platform-specific requirements
 func checkSystem() error {
 	if os.Geteuid() != 0 {
@@ -590,6 +763,14 @@ func (daemon *Daemon) initNetworkController(config *Config) (libnetwork.NetworkC
 		return nil, err
 	}
 
+	start, end, err := parsePublishedPortRange(config.bridgeConfig.PublishedPortRange)
+	if err != nil {
+		return nil, err
+	}
+	if err := portallocator.Get().SetPortRange(start, end); err != nil {
+		return nil, err
+	}
+
 	controller, err := libnetwork.New(netOptions...)
 	if err != nil {
 		return nil, fmt.Errorf("error obtaining controller instance: %v", err)
@@ -1022,6 +1203,10 @@ func (daemon *Daemon) stats(c *container.Container) (*types.StatsJSON, error) {
 	if !c.IsRunning() {
 		return nil, errNotRunning{c.ID}
 	}
+	// The runtime only reports the stats of the cgroup v1 hierarchies.
+	if sysinfo.IsCgroup2UnifiedMode() {
+		return daemon.cgroup2Stats(c)
+	}
 	stats, err := daemon.containerd.Stats(c.ID)
 	if err != nil {
 		return nil, err
@@ -1091,3 +1276,9 @@ func rootFSToAPIType(rootfs *image.RootFS) types.RootFS {
 		Layers: layers,
 	}
 }
+
+// verifyImageCompatibility checks that img can run on this host with the
+// isolation requested in hostConfig. This is only applicable on Windows.
+func (daemon *Daemon) verifyImageCompatibility(img *image.Image, hostConfig *containertypes.HostConfig) ([]types.ContainerWarning, error) {
+	return nil, nil
+}
@@ -5,6 +5,7 @@ package daemon
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/docker/docker/container"
@@ -197,3 +198,72 @@ func TestNetworkOptions(t *testing.T) {
 		t.Fatalf("Expected networkOptions error, got nil")
 	}
 }
+
+func TestParsePublishedPortRange(t *testing.T) {
+	if start, end, err := parsePublishedPortRange(""); err != nil || start != 0 || end != 0 {
+		t.Fatalf("Expected the system range, got %d-%d, %v", start, end, err)
+	}
+	if start, end, err := parsePublishedPortRange("40000-45000"); err != nil || start != 40000 || end != 45000 {
+		t.Fatalf("Expected 40000-45000, got %d-%d, %v", start, end, err)
+	}
+	for _, invalid := range []string{"40000", "45000-40000", "0-100", "40000-70000", "a-b"} {
+		if _, _, err := parsePublishedPortRange(invalid); err == nil {
+			t.Fatalf("Expected %s to be an invalid range", invalid)
+		}
+	}
+}
+
+func TestVerifyRootlessRuntime(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rootless-runtime")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for name, help := range map[string]string{
+		"rootless": "--root value  root directory\n--rootless value  ignore cgroup permission errors",
+		"old":      "--root value  root directory",
+	} {
+		script := "#!/bin/sh\necho '" + help + "'\n"
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(script), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := verifyRootlessRuntime(filepath.Join(dir, "rootless")); err != nil {
+		t.Fatalf("Expected the runtime to support the rootless mode, got %v", err)
+	}
+	if err := verifyRootlessRuntime(filepath.Join(dir, "old")); err == nil {
+		t.Fatal("Expected a runtime without --rootless to be rejected")
+	}
+	if err := verifyRootlessRuntime(filepath.Join(dir, "missing")); err == nil {
+		t.Fatal("Expected a missing runtime to be rejected")
+	}
+}
+
+func TestVerifyCgroup2Runtime(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cgroup2-runtime")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for name, script := range map[string]string{
+		"cgroup2": "#!/bin/sh\necho '{\"linux\": {\"cgroup\": {\"v1\": true, \"v2\": true}}}'\n",
+		"cgroup1": "#!/bin/sh\necho '{\"linux\": {\"cgroup\": {\"v1\": true}}}'\n",
+		"old":     "#!/bin/sh\necho 'No help topic for features' >&2\nexit 1\n",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(script), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := verifyCgroup2Runtime(filepath.Join(dir, "cgroup2")); err != nil {
+		t.Fatalf("Expected the runtime to support cgroup v2, got %v", err)
+	}
+	for _, name := range []string{"cgroup1", "old", "missing"} {
+		if err := verifyCgroup2Runtime(filepath.Join(dir, name)); err == nil {
+			t.Fatalf("Expected the runtime %s to be rejected", name)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strings"
 
@@ -17,12 +18,14 @@ import (
 	"github.com/docker/docker/dockerversion"
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/layer"
+	"github.com/docker/docker/libcontainerd"
 	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/docker/pkg/parsers"
 	"github.com/docker/docker/pkg/sysinfo"
 	"github.com/docker/docker/pkg/system"
 	"github.com/docker/docker/reference"
 	"github.com/docker/docker/runconfig"
+	runconfigopts "github.com/docker/docker/runconfig/opts"
 	"github.com/docker/engine-api/types"
 	pblkiodev "github.com/docker/engine-api/types/blkiodev"
 	containertypes "github.com/docker/engine-api/types/container"
@@ -95,8 +98,8 @@ func (daemon *Daemon) adaptContainerSettings(hostConfig *containertypes.HostConf
 	return nil
 }
 
-func verifyContainerResources(resources *containertypes.Resources, sysInfo *sysinfo.SysInfo) ([]string, error) {
-	warnings := []string{}
+func verifyContainerResources(resources *containertypes.Resources, sysInfo *sysinfo.SysInfo) ([]types.ContainerWarning, error) {
+	var warnings []types.ContainerWarning
 
 	// cpu subsystem checks and adjustments
 	if resources.CPUPercent < 0 || resources.CPUPercent > 100 {
@@ -107,35 +110,54 @@ func verifyContainerResources(resources *containertypes.Resources, sysInfo *sysi
 		return warnings, fmt.Errorf("Conflicting options: CPU Shares and CPU Percent cannot both be set")
 	}
 
+	if resources.CpusetCpus != "" {
+		if _, _, err := processorAffinity(resources.CpusetCpus); err != nil {
+			return warnings, err
+		}
+	}
+	if resources.CpusetMems != "" {
+		warnings = append(warnings, newWarning(warningResourceDiscarded, "Windows does not support cpuset mems. Cpuset mems discarded."))
+		logrus.Warnf("Windows does not support cpuset mems. --cpuset-mems discarded.")
+		resources.CpusetMems = ""
+	}
+	if resources.CPUGroup != "" && !cpuGroupID.MatchString(resources.CPUGroup) {
+		return warnings, fmt.Errorf("Invalid CPU group %s, it must be the GUID of a CPU group of the host", resources.CPUGroup)
+	}
+
+	// storage QoS checks
+	if resources.IOMaximumBandwidth != 0 && resources.IOMaximumIOps != 0 {
+		return warnings, fmt.Errorf("Conflicting options: Maximum IO Bandwidth and Maximum IO IOps cannot both be set")
+	}
+
 	// TODO Windows: Add more validation of resource settings not supported on Windows
 
 	if resources.BlkioWeight > 0 {
-		warnings = append(warnings, "Windows does not support Block I/O weight. Weight discarded.")
+		warnings = append(warnings, newWarning(warningResourceDiscarded, "Windows does not support Block I/O weight. Weight discarded."))
 		logrus.Warnf("Windows does not support Block I/O weight. --blkio-weight discarded.")
 		resources.BlkioWeight = 0
 	}
 	if len(resources.BlkioWeightDevice) > 0 {
-		warnings = append(warnings, "Windows does not support Block I/O weight_device.")
+		warnings = append(warnings, newWarning(warningResourceDiscarded, "Windows does not support Block I/O weight_device."))
 		logrus.Warnf("Windows does not support Block I/O weight_device. --blkio-weight-device discarded.")
 		resources.BlkioWeightDevice = []*pblkiodev.WeightDevice{}
 	}
 	if len(resources.BlkioDeviceReadBps) > 0 {
-		warnings = append(warnings, "Windows does not support Block read limit in bytes per second.")
+		warnings = append(warnings, newWarning(warningResourceDiscarded, "Windows does not support Block read limit in bytes per second."))
 		logrus.Warnf("Windows does not support Block I/O read limit in bytes per second. --device-read-bps discarded.")
 		resources.BlkioDeviceReadBps = []*pblkiodev.ThrottleDevice{}
 	}
 	if len(resources.BlkioDeviceWriteBps) > 0 {
-		warnings = append(warnings, "Windows does not support Block write limit in bytes per second.")
+		warnings = append(warnings, newWarning(warningResourceDiscarded, "Windows does not support Block write limit in bytes per second."))
 		logrus.Warnf("Windows does not support Block I/O write limit in bytes per second. --device-write-bps discarded.")
 		resources.BlkioDeviceWriteBps = []*pblkiodev.ThrottleDevice{}
 	}
 	if len(resources.BlkioDeviceReadIOps) > 0 {
-		warnings = append(warnings, "Windows does not support Block read limit in IO per second.")
+		warnings = append(warnings, newWarning(warningResourceDiscarded, "Windows does not support Block read limit in IO per second."))
 		logrus.Warnf("Windows does not support Block I/O read limit in IO per second. -device-read-iops discarded.")
 		resources.BlkioDeviceReadIOps = []*pblkiodev.ThrottleDevice{}
 	}
 	if len(resources.BlkioDeviceWriteIOps) > 0 {
-		warnings = append(warnings, "Windows does not support Block write limit in IO per second.")
+		warnings = append(warnings, newWarning(warningResourceDiscarded, "Windows does not support Block write limit in IO per second."))
 		logrus.Warnf("Windows does not support Block I/O write limit in IO per second. --device-write-iops discarded.")
 		resources.BlkioDeviceWriteIOps = []*pblkiodev.ThrottleDevice{}
 	}
@@ -144,8 +166,8 @@ func verifyContainerResources(resources *containertypes.Resources, sysInfo *sysi
 
 // verifyPlatformContainerSettings performs platform-specific validation of the
 // hostconfig and config structures.
-func verifyPlatformContainerSettings(daemon *Daemon, hostConfig *containertypes.HostConfig, config *containertypes.Config, update bool) ([]string, error) {
-	warnings := []string{}
+func verifyPlatformContainerSettings(daemon *Daemon, hostConfig *containertypes.HostConfig, config *containertypes.Config, update bool) ([]types.ContainerWarning, error) {
+	var warnings []types.ContainerWarning
 
 	w, err := verifyContainerResources(&hostConfig.Resources, nil)
 	warnings = append(warnings, w...)
@@ -153,14 +175,73 @@ func verifyPlatformContainerSettings(daemon *Daemon, hostConfig *containertypes.
 		return warnings, err
 	}
 
+	if hostConfig.Hooks != nil {
+		return warnings, fmt.Errorf("Windows does not support OCI hooks")
+	}
+
+	if len(hostConfig.NetworkDevices) > 0 {
+		return warnings, fmt.Errorf("Windows does not support network devices")
+	}
+
+	if rules, err := runconfigopts.ParseEgressRules(hostConfig.EgressAllow); err == nil {
+		for _, r := range rules {
+			if r.Domain != "" {
+				return warnings, fmt.Errorf("Windows does not support the domain egress rule %s", r.String())
+			}
+		}
+	}
+
+	if hostConfig.CPUGroup != "" {
+		hyperV := hostConfig.Isolation.IsHyperV() || (hostConfig.Isolation.IsDefault() && daemon.defaultIsolation.IsHyperV())
+		if !hyperV {
+			return warnings, fmt.Errorf("A CPU group can only be assigned to a container with hyperv isolation")
+		}
+	}
+
 	return warnings, nil
 }
 
+// cpuGroupID matches the GUID of a CPU group.
+var cpuGroupID = regexp.MustCompile(`^\{?[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\}?$`)
+
+// processorAffinity returns the processor group and the affinity mask of the
+// processors of a cpuset. Windows numbers the logical processors in groups
+// of 64, so the processors of a cpuset must be in the same group.
+func processorAffinity(cpuset string) (uint16, uint64, error) {
+	if _, auto, _ := parseAutoCpuset(cpuset); auto {
+		return 0, 0, fmt.Errorf("Auto cpusets are not supported on Windows")
+	}
+	cpus, err := parseCPUList(cpuset)
+	if err != nil || len(cpus) == 0 {
+		return 0, 0, fmt.Errorf("Invalid value %s for cpuset cpus", cpuset)
+	}
+	group := cpus[0] / 64
+	var mask uint64
+	for _, cpu := range cpus {
+		if cpu/64 != group {
+			return 0, 0, fmt.Errorf("Invalid value %s for cpuset cpus, the CPUs must be in the same processor group of 64 CPUs", cpuset)
+		}
+		mask |= 1 << uint(cpu%64)
+	}
+	return uint16(group), mask, nil
+}
+
 // verifyDaemonSettings performs validation of daemon config struct
 func verifyDaemonSettings(config *Config) error {
+	return validateOrphanPolicy(config.OrphanPolicy)
+}
+
+// verifyRootlessEnvironment does nothing, as the rootless mode is not
+// supported on Windows.
+func verifyRootlessEnvironment(config *Config) error {
 	return nil
 }
 
+// runtimeSupportsCgroup2 returns false, as there are no cgroups on Windows.
+func runtimeSupportsCgroup2() bool {
+	return false
+}
+
 // checkSystem validates platform-specific requirements
 func checkSystem() error {
 	// Validate the OS version. Note that docker.exe must be manifested for this
@@ -463,6 +544,96 @@ func driverOptions(config *Config) []nwconfig.Option {
 }
 
 func (daemon *Daemon) stats(c *container.Container) (*types.StatsJSON, error) {
+	if !c.IsRunning() {
+		return nil, errNotRunning{c.ID}
+	}
+	stats, err := daemon.containerd.Stats(c.ID)
+	if err != nil {
+		return nil, err
+	}
+	return statsFromHCS(stats, c.HostConfig.Memory, daemon.statsCollector.machineMemory), nil
+}
+
+// statsFromHCS converts the statistics of the compute system of a container
+// into the stats of the API, the network stats by endpoint. The memory
+// limit is the one of the container, or machineMemory if it has none.
+func statsFromHCS(stats *libcontainerd.Stats, memoryLimit int64, machineMemory uint64) *types.StatsJSON {
+	s := &types.StatsJSON{}
+	s.Read = stats.Timestamp
+
+	// The processor times are in units of 100 nanoseconds.
+	s.CPUStats = types.CPUStats{
+		CPUUsage: types.CPUUsage{
+			TotalUsage:        stats.Processor.TotalRuntime100ns * 100,
+			UsageInKernelmode: stats.Processor.RuntimeKernel100ns * 100,
+			UsageInUsermode:   stats.Processor.RuntimeUser100ns * 100,
+		},
+		OnlineCPUs: uint32(runtime.NumCPU()),
+	}
+
+	s.MemoryStats = types.MemoryStats{
+		Usage:    stats.Memory.UsageCommitBytes,
+		MaxUsage: stats.Memory.UsageCommitPeakBytes,
+		Stats: map[string]uint64{
+			"commit":              stats.Memory.UsageCommitBytes,
+			"commit_peak":         stats.Memory.UsageCommitPeakBytes,
+			"private_working_set": stats.Memory.UsagePrivateWorkingSetBytes,
+		},
+		Limit: machineMemory,
+	}
+	if memoryLimit > 0 {
+		s.MemoryStats.Limit = uint64(memoryLimit)
+	}
+
+	s.BlkioStats = types.BlkioStats{
+		IoServiceBytesRecursive: []types.BlkioStatEntry{
+			{Op: "Read", Value: stats.Storage.ReadSizeBytes},
+			{Op: "Write", Value: stats.Storage.WriteSizeBytes},
+		},
+		IoServicedRecursive: []types.BlkioStatEntry{
+			{Op: "Read", Value: stats.Storage.ReadCountNormalized},
+			{Op: "Write", Value: stats.Storage.WriteCountNormalized},
+		},
+	}
+
+	s.Networks = make(map[string]types.NetworkStats)
+	for _, n := range stats.Network {
+		s.Networks[n.EndpointId] = types.NetworkStats{
+			RxBytes:   n.BytesReceived,
+			RxPackets: n.PacketsReceived,
+			RxDropped: n.DroppedPacketsIncoming,
+			TxBytes:   n.BytesSent,
+			TxPackets: n.PacketsSent,
+			TxDropped: n.DroppedPacketsOutgoing,
+		}
+	}
+	return s
+}
+
+// verifyImageCompatibility checks that img can run on this host with the
+// isolation requested in hostConfig. An image built for another build of
+// Windows can only run in a Hyper-V container. When no isolation is
+// requested, or when process isolation is requested and the daemon runs
+// with the isolation-fallback=hyperv exec-opt, such a container is switched
+// to hyperv isolation.
+func (daemon *Daemon) verifyImageCompatibility(img *image.Image, hostConfig *containertypes.HostConfig) ([]types.ContainerWarning, error) {
+	compat, err := image.HostOSCompatibility(img)
+	switch compat {
+	case image.OSIncompatible:
+		return nil, err
+	case image.OSCompatibleHyperV:
+		if hostConfig.Isolation.IsDefault() && !daemon.defaultIsolation.IsHyperV() {
+			hostConfig.Isolation = containertypes.Isolation("hyperv")
+			return []types.ContainerWarning{newWarning(warningIsolationFallback, "%v, using hyperv isolation", err)}, nil
+		}
+		if !hostConfig.Isolation.IsDefault() && !hostConfig.Isolation.IsHyperV() {
+			if !daemon.hyperVFallback {
+				return nil, fmt.Errorf("%v, run the container with --isolation=hyperv", err)
+			}
+			hostConfig.Isolation = containertypes.Isolation("hyperv")
+			return []types.ContainerWarning{newWarning(warningIsolationFallback, "%v, falling back to hyperv isolation", err)}, nil
+		}
+	}
 	return nil, nil
 }
 
@@ -485,6 +656,15 @@ func (daemon *Daemon) setDefaultIsolation() error {
 			if containertypes.Isolation(val).IsHyperV() {
 				daemon.defaultIsolation = containertypes.Isolation("hyperv")
 			}
+		case "isolation-fallback":
+			switch strings.ToLower(val) {
+			case "hyperv":
+				daemon.hyperVFallback = true
+			case "none":
+				daemon.hyperVFallback = false
+			default:
+				return fmt.Errorf("Invalid exec-opt value for 'isolation-fallback':'%s'", val)
+			}
 		default:
 			return fmt.Errorf("Unrecognised exec-opt '%s'\n", key)
 		}
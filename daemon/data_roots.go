@@ -0,0 +1,91 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/docker/docker/pkg/fileutils"
+	"github.com/docker/docker/pkg/idtools"
+)
+
+// setupRoots creates the daemon root and the data roots of config set apart
+// from it, and sets them to their canonical path. Like the daemon root, the
+// data roots get a subdirectory for the remapped root when user namespaces
+// are enabled.
+func setupRoots(config *Config, rootUID, rootGID int) error {
+	realRoot, err := canonicalRoot(config.Root)
+	if err != nil {
+		return err
+	}
+	if err := setupDaemonRoot(config, realRoot, rootUID, rootGID); err != nil {
+		return err
+	}
+	remapped := strings.TrimPrefix(config.Root, realRoot)
+
+	for _, dir := range []*string{&config.ImageRoot, &config.ContainerRoot, &config.VolumeRoot, &config.TmpRoot} {
+		if *dir == "" {
+			continue
+		}
+		realDir, err := canonicalRoot(*dir)
+		if err != nil {
+			return err
+		}
+		if err := setupDaemonRoot(&Config{}, realDir, rootUID, rootGID); err != nil {
+			return err
+		}
+		*dir = realDir + remapped
+		if remapped != "" {
+			if err := idtools.MkdirAllAs(*dir, 0700, rootUID, rootGID); err != nil {
+				return fmt.Errorf("Cannot create data root: %s: %v", *dir, err)
+			}
+		}
+	}
+	return nil
+}
+
+// canonicalRoot returns the canonical path of the root directory dir, or dir
+// if it doesn't exist yet.
+func canonicalRoot(dir string) (string, error) {
+	if _, err := os.Stat(dir); err != nil && os.IsNotExist(err) {
+		return dir, nil
+	}
+	realDir, err := fileutils.ReadSymlinkedDirectory(dir)
+	if err != nil {
+		return "", fmt.Errorf("Unable to get the full path to root (%s): %s", dir, err)
+	}
+	return realDir, nil
+}
+
+// imageRoot returns the directory of the images and of the data of the
+// storage driver.
+func (config *Config) imageRoot() string {
+	if config.ImageRoot != "" {
+		return config.ImageRoot
+	}
+	return config.Root
+}
+
+// containerRoot returns the directory of the containers.
+func (config *Config) containerRoot() string {
+	if config.ContainerRoot != "" {
+		return config.ContainerRoot
+	}
+	return config.Root
+}
+
+// volumeRoot returns the directory of the volumes of the local driver.
+func (config *Config) volumeRoot() string {
+	if config.VolumeRoot != "" {
+		return config.VolumeRoot
+	}
+	return config.Root
+}
+
+// tmpRoot returns the directory of the temporary files.
+func (config *Config) tmpRoot() string {
+	if config.TmpRoot != "" {
+		return config.TmpRoot
+	}
+	return config.Root
+}
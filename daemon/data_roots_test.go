@@ -0,0 +1,50 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetupRoots(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "data-roots")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	tmp, err = filepath.EvalSymlinks(tmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the image root is reached through a symlink
+	if err := os.MkdirAll(filepath.Join(tmp, "ssd"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(tmp, "ssd"), filepath.Join(tmp, "images")); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &Config{}
+	config.Root = filepath.Join(tmp, "root")
+	config.ImageRoot = filepath.Join(tmp, "images")
+	config.VolumeRoot = filepath.Join(tmp, "volumes")
+	if err := setupRoots(config, os.Getuid(), os.Getgid()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct{ actual, expected string }{
+		{config.imageRoot(), filepath.Join(tmp, "ssd")},
+		{config.containerRoot(), filepath.Join(tmp, "root")},
+		{config.volumeRoot(), filepath.Join(tmp, "volumes")},
+		{config.tmpRoot(), filepath.Join(tmp, "root")},
+	} {
+		actual, expected := c.actual, c.expected
+		if actual != expected {
+			t.Fatalf("Expected the data root %s, got %s", expected, actual)
+		}
+		if _, err := os.Stat(actual); err != nil {
+			t.Fatalf("Expected the data root %s to be created, got %v", actual, err)
+		}
+	}
+}
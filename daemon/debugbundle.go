@@ -0,0 +1,243 @@
+package daemon
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"runtime/pprof"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/libcontainerd"
+)
+
+// bundleLogEntries is the number of recent engine log entries kept in
+// memory for inclusion in debug bundles.
+const bundleLogEntries = 1000
+
+// logRing is a logrus hook that keeps the most recent engine log entries,
+// regardless of the daemon's log destination.
+type logRing struct {
+	mu      sync.Mutex
+	entries [][]byte
+	next    int
+	full    bool
+	format  logrus.Formatter
+}
+
+func newLogRing(size int) *logRing {
+	return &logRing{
+		entries: make([][]byte, size),
+		format:  &logrus.TextFormatter{DisableColors: true, TimestampFormat: time.RFC3339Nano},
+	}
+}
+
+// Levels implements logrus.Hook. Every level is recorded; debug entries are
+// only seen when the daemon runs in debug mode.
+func (r *logRing) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire implements logrus.Hook.
+func (r *logRing) Fire(entry *logrus.Entry) error {
+	line, err := r.format.Format(entry)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.entries[r.next] = line
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+	r.mu.Unlock()
+	return nil
+}
+
+// WriteTo writes the recorded entries, oldest first.
+func (r *logRing) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	entries := append([][]byte{}, r.entries[:r.next]...)
+	if r.full {
+		entries = append(append([][]byte{}, r.entries[r.next:]...), entries...)
+	}
+	r.mu.Unlock()
+
+	var n int64
+	for _, line := range entries {
+		m, err := w.Write(line)
+		n += int64(m)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// bundleWriter adds files to a debug bundle tar archive.
+type bundleWriter struct {
+	tw  *tar.Writer
+	now time.Time
+}
+
+func (b *bundleWriter) add(name string, content []byte) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(content)),
+		ModTime: b.now,
+	}
+	if err := b.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := b.tw.Write(content)
+	return err
+}
+
+func (b *bundleWriter) addJSON(name string, v interface{}) error {
+	content, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return b.add(name, content)
+}
+
+// addFunc adds a file whose content is produced by fn. Collection errors are
+// recorded in the file itself rather than aborting the bundle, as a partial
+// bundle is still useful for diagnosis.
+func (b *bundleWriter) addFunc(name string, fn func(w io.Writer) error) error {
+	var buf bytes.Buffer
+	if err := fn(&buf); err != nil {
+		logrus.Warnf("debug bundle: could not collect %s: %v", name, err)
+		fmt.Fprintf(&buf, "\nerror collecting %s: %v\n", name, err)
+	}
+	return b.add(name, buf.Bytes())
+}
+
+// containerRuntimeState is the runtime view of a running container, as
+// reported by libcontainerd.
+type containerRuntimeState struct {
+	Pids      []int                   `json:",omitempty"`
+	Processes []libcontainerd.Summary `json:",omitempty"`
+	Error     string                  `json:",omitempty"`
+}
+
+// SystemBundle writes a tar archive with diagnostic information about the
+// daemon to w: goroutine stacks, a heap profile, the daemon configuration,
+// recent engine logs, the state of every container and platform specific
+// networking state.
+func (daemon *Daemon) SystemBundle(w io.Writer) error {
+	b := &bundleWriter{tw: tar.NewWriter(w), now: time.Now()}
+
+	if err := b.addFunc("goroutines.txt", func(w io.Writer) error {
+		return pprof.Lookup("goroutine").WriteTo(w, 2)
+	}); err != nil {
+		return err
+	}
+	if err := b.addFunc("heap.pprof", pprof.WriteHeapProfile); err != nil {
+		return err
+	}
+
+	info, err := daemon.SystemInfo()
+	if err != nil {
+		return err
+	}
+	info.HTTPProxy = redactProxyURL(info.HTTPProxy)
+	info.HTTPSProxy = redactProxyURL(info.HTTPSProxy)
+	if err := b.addJSON("info.json", info); err != nil {
+		return err
+	}
+	if err := b.addJSON("version.json", daemon.SystemVersion()); err != nil {
+		return err
+	}
+
+	daemon.configStore.reloadLock.Lock()
+	config, err := redactedConfig(daemon.configStore)
+	daemon.configStore.reloadLock.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := b.add("daemon.json", config); err != nil {
+		return err
+	}
+
+	if daemon.logRing != nil {
+		if err := b.addFunc("engine.log", func(w io.Writer) error {
+			_, err := daemon.logRing.WriteTo(w)
+			return err
+		}); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range daemon.containers.List() {
+		if err := daemon.bundleContainer(b, c); err != nil {
+			return err
+		}
+	}
+
+	if err := daemon.bundlePlatform(b); err != nil {
+		return err
+	}
+
+	return b.tw.Close()
+}
+
+// redactedConfig returns the JSON of config, with the credentials of its
+// proxy URLs redacted.
+func redactedConfig(config *Config) ([]byte, error) {
+	content, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(content, &fields); err != nil {
+		return nil, err
+	}
+	for _, key := range []string{"http-proxy", "https-proxy"} {
+		if proxy, ok := fields[key].(string); ok {
+			fields[key] = redactProxyURL(proxy)
+		}
+	}
+	return json.MarshalIndent(fields, "", "  ")
+}
+
+// redactProxyURL returns proxy with the user and password it holds, if any,
+// replaced with "xxxxx".
+func redactProxyURL(proxy string) string {
+	u, err := url.Parse(proxy)
+	if err != nil || u.User == nil {
+		return proxy
+	}
+	u.User = url.User("xxxxx")
+	return u.String()
+}
+
+func (daemon *Daemon) bundleContainer(b *bundleWriter, c *container.Container) error {
+	c.Lock()
+	content, err := json.MarshalIndent(c, "", "  ")
+	running := c.Running
+	c.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := b.add("containers/"+c.ID+"/config.json", content); err != nil {
+		return err
+	}
+	if !running {
+		return nil
+	}
+
+	var state containerRuntimeState
+	if state.Pids, err = daemon.containerd.GetPidsForContainer(c.ID); err != nil {
+		state.Error = err.Error()
+	} else if state.Processes, err = daemon.containerd.Summary(c.ID); err != nil {
+		state.Error = err.Error()
+	}
+	return b.addJSON("containers/"+c.ID+"/runtime.json", state)
+}
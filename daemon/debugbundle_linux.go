@@ -0,0 +1,16 @@
+package daemon
+
+import (
+	"io"
+	"os/exec"
+)
+
+// bundlePlatform adds the iptables rules of the host to the debug bundle.
+func (daemon *Daemon) bundlePlatform(b *bundleWriter) error {
+	return b.addFunc("iptables.txt", func(w io.Writer) error {
+		cmd := exec.Command("iptables-save")
+		cmd.Stdout = w
+		cmd.Stderr = w
+		return cmd.Run()
+	})
+}
@@ -0,0 +1,7 @@
+// +build !linux,!windows
+
+package daemon
+
+func (daemon *Daemon) bundlePlatform(b *bundleWriter) error {
+	return nil
+}
@@ -0,0 +1,20 @@
+package daemon
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/Microsoft/hcsshim"
+)
+
+// bundlePlatform adds the HNS networks of the host to the debug bundle.
+func (daemon *Daemon) bundlePlatform(b *bundleWriter) error {
+	return b.addFunc("hns-networks.json", func(w io.Writer) error {
+		networks, err := hcsshim.HNSListNetworkRequest("GET", "", "")
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(w)
+		return enc.Encode(networks)
+	})
+}
@@ -0,0 +1,77 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/libcontainerd"
+	"github.com/docker/docker/restartmanager"
+)
+
+// stateDump is the state of the daemon written by dumpState, for debugging
+// the operations that hang.
+type stateDump struct {
+	Time time.Time
+	// Operations are the container operations in progress, holding up the
+	// operations queued on their container, with the stack of the
+	// goroutine running them.
+	Operations []operationState
+	// Restarts are the containers waiting for the delay of their restart
+	// policy before being restarted.
+	Restarts []restartState
+	// Containerd is the state of the containers in libcontainerd, with the
+	// operations holding or waiting for their lock.
+	Containerd []libcontainerd.ContainerState
+}
+
+type operationState struct {
+	operation
+	Stack string `json:",omitempty"`
+}
+
+type restartState struct {
+	Container string
+	restartmanager.Status
+}
+
+// dumpState writes the state of the daemon to a timestamped file in its root
+// directory. It is called on SIGUSR1, or on the signal of the
+// Global\docker-daemon-<pid> event on Windows, after the goroutine stacks
+// are logged.
+func (daemon *Daemon) dumpState() {
+	dump := daemon.collectState()
+	content, err := json.MarshalIndent(dump, "", "  ")
+	if err != nil {
+		logrus.Errorf("Error encoding the state dump of the daemon: %v", err)
+		return
+	}
+	path := filepath.Join(daemon.configStore.Root, fmt.Sprintf("daemon-state-%s.json", dump.Time.Format("20060102T150405Z")))
+	if err := ioutil.WriteFile(path, content, 0600); err != nil {
+		logrus.Errorf("Error writing the state dump of the daemon: %v", err)
+		return
+	}
+	logrus.Infof("Daemon state written to %s", path)
+}
+
+func (daemon *Daemon) collectState() stateDump {
+	dump := stateDump{Time: time.Now().UTC()}
+	for _, op := range daemon.operations.list() {
+		dump.Operations = append(dump.Operations, operationState{operation: op, Stack: goroutineStack(op.Goroutine)})
+	}
+	// The daemon may still be starting.
+	if daemon.containers != nil {
+		for _, c := range daemon.containers.List() {
+			if status, ok := c.RestartStatus(); ok && status.Pending {
+				dump.Restarts = append(dump.Restarts, restartState{Container: c.ID, Status: status})
+			}
+		}
+	}
+	if daemon.containerd != nil {
+		dump.Containerd = daemon.containerd.State()
+	}
+	return dump
+}
@@ -0,0 +1,52 @@
+package daemon
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/container"
+)
+
+func TestDumpState(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-state-dump")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	d := &Daemon{configStore: &Config{CommonConfig: CommonConfig{Root: root}}}
+	c := &container.Container{CommonContainer: container.CommonContainer{ID: "test"}}
+	done := d.watchOperation(c, "start")
+	d.dumpState()
+	done()
+
+	files, err := filepath.Glob(filepath.Join(root, "daemon-state-*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("expected a state dump, got %v", files)
+	}
+	content, err := ioutil.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dump stateDump
+	if err := json.Unmarshal(content, &dump); err != nil {
+		t.Fatal(err)
+	}
+	if len(dump.Operations) != 1 {
+		t.Fatalf("expected an operation in progress, got %+v", dump.Operations)
+	}
+	op := dump.Operations[0]
+	if op.Container != "test" || op.Operation != "start" || op.Stack == "" {
+		t.Fatalf("unexpected operation %+v", op)
+	}
+
+	if ops := d.operations.list(); len(ops) != 0 {
+		t.Fatalf("expected no operation in progress once done, got %+v", ops)
+	}
+}
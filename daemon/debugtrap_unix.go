@@ -10,12 +10,13 @@ import (
 	psignal "github.com/docker/docker/pkg/signal"
 )
 
-func setupDumpStackTrap() {
+func setupDumpStackTrap(dump func()) {
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, syscall.SIGUSR1)
 	go func() {
 		for range c {
 			psignal.DumpStacks()
+			dump()
 		}
 	}()
 }
@@ -10,10 +10,10 @@ import (
 	"github.com/docker/docker/pkg/system"
 )
 
-func setupDumpStackTrap() {
+func setupDumpStackTrap(dump func()) {
 	// Windows does not support signals like *nix systems. So instead of
-	// trapping on SIGUSR1 to dump stacks, we wait on a Win32 event to be
-	// signaled.
+	// trapping on SIGUSR1 to dump stacks and state, we wait on a Win32 event
+	// to be signaled.
 	go func() {
 		sa := syscall.SecurityAttributes{
 			Length: 0,
@@ -24,6 +24,7 @@ func setupDumpStackTrap() {
 			for {
 				syscall.WaitForSingleObject(h, syscall.INFINITE)
 				signal.DumpStacks()
+				dump()
 			}
 		}
 	}()
@@ -24,8 +24,12 @@ func (daemon *Daemon) ContainerRm(name string, config *types.ContainerRmConfig)
 		return err
 	}
 
-	// Container state RemovalInProgress should be used to avoid races.
-	if inProgress := container.SetRemovalInProgress(); inProgress {
+	// Container state RemovalInProgress should be used to avoid races. Wait
+	// for a start that may be in progress to complete before setting it.
+	container.BeginOperation()
+	inProgress := container.SetRemovalInProgress()
+	container.EndOperation()
+	if inProgress {
 		return nil
 	}
 	defer container.ResetRemovalInProgress()
@@ -39,7 +43,11 @@ func (daemon *Daemon) ContainerRm(name string, config *types.ContainerRmConfig)
 		return daemon.rmLink(container, name)
 	}
 
-	err = daemon.cleanupContainer(container, config.ForceRemove)
+	if config.Cleanup && !config.ForceRemove {
+		return fmt.Errorf("Removing container %s with cleanup requires force", container.ID)
+	}
+
+	err = daemon.cleanupContainer(container, config.ForceRemove, config.Cleanup)
 	if err == nil || config.ForceRemove {
 		if e := daemon.removeMountPoints(container, config.RemoveVolume); e != nil {
 			logrus.Error(e)
@@ -77,13 +85,19 @@ func (daemon *Daemon) rmLink(container *container.Container, name string) error
 
 // cleanupContainer unregisters a container from the daemon, stops stats
 // collection and cleanly removes contents and metadata from the filesystem.
-func (daemon *Daemon) cleanupContainer(container *container.Container, forceRemove bool) (err error) {
+// With cleanup, a running container the runtime fails to kill is removed
+// from the daemon regardless, and its removal from the runtime and of its
+// root filesystem are retried in the background.
+func (daemon *Daemon) cleanupContainer(container *container.Container, forceRemove, cleanup bool) (err error) {
+	var wedged bool
 	if container.IsRunning() {
 		if !forceRemove {
 			err := fmt.Errorf("You cannot remove a running container %s. Stop the container before attempting removal or use -f", container.ID)
 			return errors.NewRequestConflictError(err)
 		}
-		if err := daemon.Kill(container); err != nil {
+		if cleanup {
+			wedged = daemon.killWedged(container)
+		} else if err := daemon.Kill(container); err != nil {
 			return fmt.Errorf("Could not kill running container %s, cannot remove - %v", container.ID, err)
 		}
 	}
@@ -92,7 +106,7 @@ func (daemon *Daemon) cleanupContainer(container *container.Container, forceRemo
 	// if stats are currently getting collected.
 	daemon.statsCollector.stopCollection(container)
 
-	if err = daemon.containerStop(container, 3); err != nil {
+	if err = daemon.containerStop(container, 3, nil); err != nil {
 		return err
 	}
 
@@ -115,23 +129,36 @@ func (daemon *Daemon) cleanupContainer(container *container.Container, forceRemo
 			selinuxFreeLxcContexts(container.ProcessLabel)
 			daemon.idIndex.Delete(container.ID)
 			daemon.containers.Delete(container.ID)
+			daemon.cpuAllocator.release(container.ID)
+			daemon.releaseStaticAddresses(container)
 			daemon.LogContainerEvent(container, "destroy")
+			daemon.runLifecycleHooks(container, hookDestroy)
 		}
 	}()
 
+	daemon.removeSnapshots(container)
 	if err = os.RemoveAll(container.Root); err != nil {
 		return fmt.Errorf("Unable to remove filesystem for %v: %v", container.ID, err)
 	}
 
 	// When container creation fails and `RWLayer` has not been created yet, we
 	// do not call `ReleaseRWLayer`
+	var rwLayer layer.RWLayer
 	if container.RWLayer != nil {
 		metadata, err := daemon.layerStore.ReleaseRWLayer(container.RWLayer)
 		layer.LogReleaseMetadata(metadata)
 		if err != nil && err != layer.ErrMountDoesNotExist {
-			return fmt.Errorf("Driver %s failed to remove root filesystem %s: %s", daemon.GraphDriverName(), container.ID, err)
+			if cleanup {
+				logrus.Warnf("Could not remove the root filesystem of container %s, retrying in the background: %v", container.ID, err)
+				rwLayer = container.RWLayer
+			} else {
+				return fmt.Errorf("Driver %s failed to remove root filesystem %s: %s", daemon.GraphDriverName(), container.ID, err)
+			}
 		}
 	}
+	if cleanup {
+		daemon.retryWedgedRemoval(container.ID, wedged, rwLayer)
+	}
 
 	return nil
 }
@@ -1,6 +1,7 @@
 package daemon
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 	"testing"
@@ -40,3 +41,39 @@ func TestContainerDoubleDelete(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestContainerRmCleanupRequiresForce(t *testing.T) {
+	daemon := &Daemon{}
+	daemon.containers = container.NewMemoryStore()
+
+	container := &container.Container{
+		CommonContainer: container.CommonContainer{
+			ID:     "test",
+			State:  container.NewState(),
+			Config: &containertypes.Config{},
+		},
+	}
+	daemon.containers.Add(container.ID, container)
+
+	if err := daemon.ContainerRm(container.ID, &types.ContainerRmConfig{Cleanup: true}); err == nil {
+		t.Fatal("expected an error removing with cleanup without force")
+	}
+	if daemon.containers.Get(container.ID) == nil {
+		t.Fatal("expected the container not to be removed")
+	}
+}
+
+func TestIsRuntimeGone(t *testing.T) {
+	for _, c := range []struct {
+		err  error
+		gone bool
+	}{
+		{fmt.Errorf("rpc error: code = 2 desc = containerd: container not found"), true},
+		{fmt.Errorf("invalid container: test"), true},
+		{fmt.Errorf("HCS shutdown failed: timeout"), false},
+	} {
+		if gone := isRuntimeGone(c.err); gone != c.gone {
+			t.Fatalf("expected isRuntimeGone(%q) to be %v, got %v", c.err, c.gone, gone)
+		}
+	}
+}
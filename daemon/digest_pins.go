@@ -0,0 +1,77 @@
+package daemon
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/reference"
+)
+
+// verifyDigestPin returns an error if the daemon requires image references
+// pinned by digest and imageRef is a tag, or a name without a tag, of a
+// repository that is not exempted. Image IDs are accepted, as they are
+// content addressable as well.
+func (daemon *Daemon) verifyDigestPin(imageRef string) error {
+	if !daemon.configStore.RequireDigestPins {
+		return nil
+	}
+	return checkDigestPin(imageRef, daemon.configStore.DigestPinExemptions)
+}
+
+// checkDigestPin returns an error if imageRef is neither an image ID nor
+// pinned by digest, and its repository does not match any of exemptions.
+func checkDigestPin(imageRef string, exemptions []string) error {
+	id, ref, err := reference.ParseIDOrReference(imageRef)
+	if err != nil {
+		return err
+	}
+	if id != "" {
+		return nil
+	}
+	if _, ok := ref.(reference.Canonical); ok {
+		return nil
+	}
+	for _, exemption := range exemptions {
+		if matchDigestPinExemption(ref, exemption) {
+			return nil
+		}
+	}
+	return fmt.Errorf("image %s is not pinned by digest, which this daemon requires: use %s@sha256:<digest> instead", imageRef, ref.Name())
+}
+
+// matchDigestPinExemption returns whether the repository of ref is exemption,
+// or is under the namespace of an exemption of the form "namespace/*".
+func matchDigestPinExemption(ref reference.Named, exemption string) bool {
+	if strings.HasSuffix(exemption, "/*") {
+		// Name a repository in the namespace so that it is normalized
+		// the same way as ref.
+		namespace := strings.TrimSuffix(exemption, "/*")
+		repo, err := reference.WithName(namespace + "/x")
+		if err != nil {
+			return false
+		}
+		if !strings.Contains(namespace, "/") && (strings.ContainsAny(namespace, ".:") || namespace == "localhost") {
+			// The whole registry is exempted.
+			return ref.Hostname() == repo.Hostname()
+		}
+		return strings.HasPrefix(ref.FullName(), strings.TrimSuffix(repo.FullName(), "x"))
+	}
+	repo, err := reference.WithName(exemption)
+	if err != nil {
+		return false
+	}
+	return repo.FullName() == ref.FullName()
+}
+
+// validateDigestPinExemption validates a repository name, or a
+// "namespace/*" pattern, exempted from digest pinning.
+func validateDigestPinExemption(val string) (string, error) {
+	name := val
+	if strings.HasSuffix(name, "/*") {
+		name = strings.TrimSuffix(name, "*") + "x"
+	}
+	if _, err := reference.WithName(name); err != nil {
+		return "", fmt.Errorf("invalid digest pin exemption %q: %v", val, err)
+	}
+	return val, nil
+}
@@ -0,0 +1,55 @@
+package daemon
+
+import "testing"
+
+func TestCheckDigestPin(t *testing.T) {
+	exemptions := []string{"busybox", "registry.example.com/tools/*", "myorg/*", "localhost:5000/*"}
+	dgst := "sha256:" + "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	valid := []string{
+		"ubuntu@" + dgst,
+		"registry.example.com:5000/app@" + dgst,
+		dgst,
+		"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		"busybox",
+		"library/busybox:latest",
+		"docker.io/busybox:1.24",
+		"registry.example.com/tools/lint:v1",
+		"myorg/app:latest",
+		"localhost:5000/app",
+		"localhost:5000/team/app:v1",
+	}
+	for _, ref := range valid {
+		if err := checkDigestPin(ref, exemptions); err != nil {
+			t.Fatalf("expected %s to be accepted, got %v", ref, err)
+		}
+	}
+
+	invalid := []string{
+		"ubuntu",
+		"ubuntu:16.04",
+		"registry.example.com/app:v1",
+		"registry.example.com/toolsx/lint:v1",
+		"otherorg/app",
+		"myorganization/app",
+		"localhost:5001/app",
+	}
+	for _, ref := range invalid {
+		if err := checkDigestPin(ref, exemptions); err == nil {
+			t.Fatalf("expected %s to be rejected", ref)
+		}
+	}
+}
+
+func TestValidateDigestPinExemption(t *testing.T) {
+	for _, val := range []string{"busybox", "myorg/*", "registry.example.com:5000/team/*"} {
+		if _, err := validateDigestPinExemption(val); err != nil {
+			t.Fatalf("expected %s to be valid, got %v", val, err)
+		}
+	}
+	for _, val := range []string{"UPPER", "*", "myorg/*/app", "myorg/**"} {
+		if _, err := validateDigestPinExemption(val); err == nil {
+			t.Fatalf("expected %s to be invalid", val)
+		}
+	}
+}
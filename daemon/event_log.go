@@ -0,0 +1,46 @@
+package daemon
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// defaultEventLogIDs are the event IDs of the container events written to
+// the event log, by action. The IDs are within 1-1000, the range of the
+// message file of EventCreate.exe that the event source is registered with.
+var defaultEventLogIDs = map[string]uint32{
+	"start": 100,
+	"stop":  101,
+	"die":   102,
+	"oom":   103,
+}
+
+// parseEventLogIDs returns the event IDs of the event log, the defaults
+// overridden by list, of the form "<action>=<id>". An ID of 0 disables the
+// writing of the events of its action.
+func parseEventLogIDs(list []string) (map[string]uint32, error) {
+	ids := make(map[string]uint32, len(defaultEventLogIDs))
+	for action, id := range defaultEventLogIDs {
+		ids[action] = id
+	}
+	for _, s := range list {
+		parts := strings.SplitN(s, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid event log ID %s: must be of the form <event>=<id>", s)
+		}
+		if _, ok := defaultEventLogIDs[parts[0]]; !ok {
+			return nil, fmt.Errorf("invalid event log ID %s: unsupported event %s", s, parts[0])
+		}
+		id, err := strconv.ParseUint(parts[1], 10, 32)
+		if err != nil || id > 1000 {
+			return nil, fmt.Errorf("invalid event log ID %s: the ID must be between 0 and 1000", s)
+		}
+		if id == 0 {
+			delete(ids, parts[0])
+			continue
+		}
+		ids[parts[0]] = uint32(id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,26 @@
+package daemon
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseEventLogIDs(t *testing.T) {
+	ids, err := parseEventLogIDs([]string{"die=500", "stop=0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]uint32{"start": 100, "die": 500, "oom": 103}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Fatalf("expected event IDs %v, got %v", expected, ids)
+	}
+	if defaultEventLogIDs["die"] != 102 || defaultEventLogIDs["stop"] != 101 {
+		t.Fatalf("expected the default event IDs to be left unchanged, got %v", defaultEventLogIDs)
+	}
+
+	for _, invalid := range []string{"die", "pause=104", "die=1001", "die=-1", "die=abc"} {
+		if _, err := parseEventLogIDs([]string{invalid}); err == nil {
+			t.Fatalf("expected event log ID %s to be invalid", invalid)
+		}
+	}
+}
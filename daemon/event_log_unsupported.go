@@ -0,0 +1,24 @@
+// +build !windows
+
+package daemon
+
+import (
+	"github.com/docker/docker/daemon/events"
+)
+
+// validateEventLogConfig does nothing, as the event log is only supported
+// on Windows.
+func validateEventLogConfig(config *Config) error {
+	return nil
+}
+
+func newEventLog(config *Config) (*events.EventLog, error) {
+	return nil, nil
+}
+
+func (daemon *Daemon) setEventLog(l *events.EventLog) {
+}
+
+func (daemon *Daemon) reloadEventLog(config *Config) error {
+	return nil
+}
@@ -0,0 +1,88 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/events"
+	"golang.org/x/sys/windows/registry"
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+// eventLogSourcesKey is the registry key of the event sources of the
+// Application log.
+const eventLogSourcesKey = `SYSTEM\CurrentControlSet\Services\EventLog\Application`
+
+// validateEventLogConfig validates the event IDs of the event log.
+func validateEventLogConfig(config *Config) error {
+	_, err := parseEventLogIDs(config.EventLogIDs)
+	return err
+}
+
+// newEventLog returns the event log of config, or nil if config does not
+// set one. The event source is registered in the Application log if it
+// does not exist yet.
+func newEventLog(config *Config) (*events.EventLog, error) {
+	if config.EventLog == "" {
+		return nil, nil
+	}
+	ids, err := parseEventLogIDs(config.EventLogIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	if err := installEventLogSource(config.EventLog); err != nil {
+		return nil, fmt.Errorf("Error registering the event log source %s: %v", config.EventLog, err)
+	}
+	l, err := eventlog.Open(config.EventLog)
+	if err != nil {
+		return nil, fmt.Errorf("Error opening the event log source %s: %v", config.EventLog, err)
+	}
+	return events.NewEventLog(l, ids), nil
+}
+
+// installEventLogSource registers source in the Application log, with
+// EventCreate.exe as its message file, unless it is already registered.
+func installEventLogSource(source string) error {
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, eventLogSourcesKey+`\`+source, registry.QUERY_VALUE)
+	if err == nil {
+		k.Close()
+		return nil
+	}
+	return eventlog.InstallAsEventCreate(source, eventlog.Info|eventlog.Error)
+}
+
+// setEventLog stops the current event log of the daemon, if any, and
+// starts l in its place. l may be nil.
+func (daemon *Daemon) setEventLog(l *events.EventLog) {
+	if daemon.eventLog != nil {
+		daemon.eventLog.Stop()
+	}
+	daemon.eventLog = l
+	if l != nil {
+		l.Start(daemon.EventsService)
+		logrus.Infof("Writing container events to the event log as %s", daemon.configStore.EventLog)
+	}
+}
+
+// reloadEventLog restarts the event log with the settings of config that
+// are set.
+func (daemon *Daemon) reloadEventLog(config *Config) error {
+	if !config.IsValueSet("event-log") && !config.IsValueSet("event-log-ids") {
+		return nil
+	}
+	if config.IsValueSet("event-log") {
+		daemon.configStore.EventLog = config.EventLog
+	}
+	if config.IsValueSet("event-log-ids") {
+		daemon.configStore.EventLogIDs = config.EventLogIDs
+	}
+	l, err := newEventLog(daemon.configStore)
+	if err != nil {
+		return err
+	}
+	daemon.setEventLog(l)
+	return nil
+}
@@ -0,0 +1,70 @@
+package daemon
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/events"
+	"github.com/docker/engine-api/types/filters"
+)
+
+// validateEventWebhook validates the URL of the event webhook.
+func validateEventWebhook(val string) error {
+	u, err := url.Parse(val)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid event webhook %s: must be an http:// or https:// URL", val)
+	}
+	return nil
+}
+
+// parseEventWebhookFilters parses the filters of the event webhook, of the
+// form accepted by docker events --filter.
+func parseEventWebhookFilters(list []string) (filters.Args, error) {
+	args := filters.NewArgs()
+	for _, f := range list {
+		var err error
+		if args, err = filters.ParseFlag(f, args); err != nil {
+			return args, fmt.Errorf("invalid event webhook filter %s: %v", f, err)
+		}
+	}
+	return args, nil
+}
+
+// newEventWebhook returns the event webhook of config, or nil if config
+// does not set one.
+func newEventWebhook(config *Config) (*events.Webhook, error) {
+	if config.EventWebhook == "" {
+		return nil, nil
+	}
+	if err := validateEventWebhook(config.EventWebhook); err != nil {
+		return nil, err
+	}
+	filter, err := parseEventWebhookFilters(config.EventWebhookFilters)
+	if err != nil {
+		return nil, err
+	}
+	var secret []byte
+	if config.EventWebhookSecretFile != "" {
+		if secret, err = ioutil.ReadFile(config.EventWebhookSecretFile); err != nil {
+			return nil, fmt.Errorf("Error reading the event webhook secret: %v", err)
+		}
+		secret = bytes.TrimSpace(secret)
+	}
+	return events.NewWebhook(config.EventWebhook, filter, secret), nil
+}
+
+// setEventWebhook stops the current event webhook of the daemon, if any,
+// and starts w in its place. w may be nil.
+func (daemon *Daemon) setEventWebhook(w *events.Webhook) {
+	if daemon.eventWebhook != nil {
+		daemon.eventWebhook.Stop()
+	}
+	daemon.eventWebhook = w
+	if w != nil {
+		w.Start(daemon.EventsService)
+		logrus.Infof("Posting events to webhook %s", daemon.configStore.EventWebhook)
+	}
+}
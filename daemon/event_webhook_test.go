@@ -0,0 +1,47 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestParseEventWebhookFilters(t *testing.T) {
+	args, err := parseEventWebhookFilters([]string{"event=die", "event=oom", "type=container"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !args.ExactMatch("event", "oom") || args.ExactMatch("event", "start") || !args.ExactMatch("type", "container") {
+		t.Fatalf("unexpected filters %v", args)
+	}
+	if _, err := parseEventWebhookFilters([]string{"event"}); err == nil {
+		t.Fatal("expected a filter without value to be invalid")
+	}
+}
+
+func TestNewEventWebhook(t *testing.T) {
+	if w, err := newEventWebhook(&Config{}); w != nil || err != nil {
+		t.Fatalf("expected no webhook without URL, got %v, %v", w, err)
+	}
+	if _, err := newEventWebhook(&Config{CommonConfig: CommonConfig{EventWebhook: "ftp://example.com"}}); err == nil {
+		t.Fatal("expected an ftp:// webhook to be invalid")
+	}
+
+	f, err := ioutil.TempFile("", "event-webhook-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.Close()
+	config := &Config{CommonConfig: CommonConfig{
+		EventWebhook:           "https://example.com/events",
+		EventWebhookSecretFile: f.Name(),
+	}}
+	if w, err := newEventWebhook(config); w == nil || err != nil {
+		t.Fatalf("expected a webhook, got %v, %v", w, err)
+	}
+	config.EventWebhookSecretFile = f.Name() + ".missing"
+	if _, err := newEventWebhook(config); err == nil {
+		t.Fatal("expected a missing secret file to be an error")
+	}
+}
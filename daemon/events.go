@@ -1,11 +1,13 @@
 package daemon
 
 import (
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/docker/docker/container"
 	daemonevents "github.com/docker/docker/daemon/events"
+	"github.com/docker/docker/reference"
 	"github.com/docker/engine-api/types/events"
 	"github.com/docker/engine-api/types/filters"
 	"github.com/docker/libnetwork"
@@ -17,11 +19,22 @@ func (daemon *Daemon) LogContainerEvent(container *container.Container, action s
 }
 
 // LogContainerEventWithAttributes generates an event related to a container with specific given attributes.
+// Besides its labels, image and name, the attributes of the container include the ID and the
+// repository digest of its image, and the exit code of its last run once it has exited.
 func (daemon *Daemon) LogContainerEventWithAttributes(container *container.Container, action string, attributes map[string]string) {
 	copyAttributes(attributes, container.Config.Labels)
 	if container.Config.Image != "" {
 		attributes["image"] = container.Config.Image
 	}
+	if container.ImageID != "" {
+		attributes["imageID"] = container.ImageID.String()
+		if digest := daemon.imageDigest(container); digest != "" {
+			attributes["imageDigest"] = digest
+		}
+	}
+	if _, ok := attributes["exitCode"]; !ok && container.State != nil && !container.Running && !container.FinishedAt.IsZero() {
+		attributes["exitCode"] = strconv.Itoa(container.ExitCode)
+	}
 	attributes["name"] = strings.TrimLeft(container.Name, "/")
 
 	actor := events.Actor{
@@ -31,6 +44,31 @@ func (daemon *Daemon) LogContainerEventWithAttributes(container *container.Conta
 	daemon.EventsService.Log(action, events.ContainerEventType, actor)
 }
 
+// imageDigest returns the repository digest of the image of container, the
+// one of the repository it was named by if it has several, or "" if the image
+// was not pulled by digest or pushed.
+func (daemon *Daemon) imageDigest(container *container.Container) string {
+	if daemon.referenceStore == nil {
+		return ""
+	}
+	var name string
+	if named, err := reference.ParseNamed(container.Config.Image); err == nil {
+		name = named.Name()
+	}
+	var digest string
+	for _, ref := range daemon.referenceStore.References(container.ImageID) {
+		if _, ok := ref.(reference.Canonical); ok {
+			if ref.Name() == name {
+				return ref.String()
+			}
+			if digest == "" {
+				digest = ref.String()
+			}
+		}
+	}
+	return digest
+}
+
 // LogImageEvent generates an event related to an image with only the default attributes.
 func (daemon *Daemon) LogImageEvent(imageID, refName, action string) {
 	daemon.LogImageEventWithAttributes(imageID, refName, action, map[string]string{})
@@ -64,6 +102,15 @@ func (daemon *Daemon) LogVolumeEvent(volumeID, action string, attributes map[str
 	daemon.EventsService.Log(action, events.VolumeEventType, actor)
 }
 
+// LogJobEvent generates an event related to a scheduled job.
+func (daemon *Daemon) LogJobEvent(jobID, action string, attributes map[string]string) {
+	actor := events.Actor{
+		ID:         jobID,
+		Attributes: attributes,
+	}
+	daemon.EventsService.Log(action, events.JobEventType, actor)
+}
+
 // LogNetworkEvent generates an event related to a network with only the default attributes.
 func (daemon *Daemon) LogNetworkEvent(nw libnetwork.Network, action string) {
 	daemon.LogNetworkEventWithAttributes(nw, action, map[string]string{})
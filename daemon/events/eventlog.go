@@ -0,0 +1,146 @@
+package events
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	eventtypes "github.com/docker/engine-api/types/events"
+	"github.com/docker/engine-api/types/filters"
+)
+
+const eventLogQueueSize = 1024
+
+// EventLogWriter writes the entries of a system event log, such as the
+// Windows Event Log.
+type EventLogWriter interface {
+	Info(eid uint32, msg string) error
+	Error(eid uint32, msg string) error
+	Close() error
+}
+
+// EventLog writes the lifecycle events of the containers to a system event
+// log, with the event ID configured for their action. The container events
+// whose action has no event ID are not written. Like the webhook, the
+// events are queued so that a slow event log does not block the other
+// listeners.
+type EventLog struct {
+	writer EventLogWriter
+	ids    map[string]uint32
+	filter *Filter
+
+	queue chan eventtypes.Message
+	stop  chan struct{}
+	wg    sync.WaitGroup
+}
+
+// NewEventLog returns an event log writing the container events with the
+// actions of ids to writer, which is closed when the event log is stopped.
+func NewEventLog(writer EventLogWriter, ids map[string]uint32) *EventLog {
+	filter := filters.NewArgs()
+	filter.Add("type", eventtypes.ContainerEventType)
+	for action := range ids {
+		filter.Add("event", action)
+	}
+	return &EventLog{
+		writer: writer,
+		ids:    ids,
+		filter: NewFilter(filter),
+		queue:  make(chan eventtypes.Message, eventLogQueueSize),
+		stop:   make(chan struct{}),
+	}
+}
+
+// Start subscribes the event log to the events of e and starts writing
+// them, until Stop is called.
+func (l *EventLog) Start(e *Events) {
+	_, ch := e.SubscribeTopic(time.Time{}, time.Time{}, l.filter)
+	l.wg.Add(2)
+	go func() {
+		defer l.wg.Done()
+		defer e.Evict(ch)
+		for {
+			select {
+			case m := <-ch:
+				ev := m.(eventtypes.Message)
+				select {
+				case l.queue <- ev:
+				default:
+					logrus.Warnf("Dropping container event %s of %s for the event log: too many events are waiting to be written", ev.Action, ev.Actor.ID)
+				}
+			case <-l.stop:
+				return
+			}
+		}
+	}()
+	go func() {
+		defer l.wg.Done()
+		for {
+			select {
+			case ev := <-l.queue:
+				l.write(ev)
+			case <-l.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the event log and closes its writer. The events that are not
+// written yet are dropped.
+func (l *EventLog) Stop() {
+	close(l.stop)
+	l.wg.Wait()
+	if err := l.writer.Close(); err != nil {
+		logrus.Errorf("Error closing the event log: %v", err)
+	}
+}
+
+func (l *EventLog) write(ev eventtypes.Message) {
+	eid := l.ids[ev.Action]
+	msg := eventLogMessage(ev)
+	write := l.writer.Info
+	if eventLogFailure(ev) {
+		write = l.writer.Error
+	}
+	if err := write(eid, msg); err != nil {
+		logrus.Errorf("Error writing container event %s of %s to the event log: %v", ev.Action, ev.Actor.ID, err)
+	}
+}
+
+// eventLogFailure returns whether ev is a failure of its container, an
+// out of memory or an exit with a non-zero code, written as an error entry
+// so that it can be alerted on. The other events are written as
+// information entries.
+func eventLogFailure(ev eventtypes.Message) bool {
+	switch ev.Action {
+	case "oom":
+		return true
+	case "die":
+		code := ev.Actor.Attributes["exitCode"]
+		return code != "" && code != "0"
+	}
+	return false
+}
+
+// eventLogMessage returns the message of the entry of ev: a summary line,
+// followed by the attributes of the container, one per line.
+func eventLogMessage(ev eventtypes.Message) string {
+	name := ev.Actor.Attributes["name"]
+	if name == "" {
+		name = ev.Actor.ID
+	}
+	lines := []string{fmt.Sprintf("Container %s: %s", name, ev.Action), "", "id=" + ev.Actor.ID}
+	keys := make([]string, 0, len(ev.Actor.Attributes))
+	for k := range ev.Actor.Attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		lines = append(lines, k+"="+ev.Actor.Attributes[k])
+	}
+	return strings.Join(lines, "\r\n")
+}
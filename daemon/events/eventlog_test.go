@@ -0,0 +1,72 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	eventtypes "github.com/docker/engine-api/types/events"
+)
+
+type eventLogEntry struct {
+	level string
+	eid   uint32
+	msg   string
+}
+
+type fakeEventLogWriter struct {
+	entries chan eventLogEntry
+	closed  bool
+}
+
+func (w *fakeEventLogWriter) Info(eid uint32, msg string) error {
+	w.entries <- eventLogEntry{"info", eid, msg}
+	return nil
+}
+
+func (w *fakeEventLogWriter) Error(eid uint32, msg string) error {
+	w.entries <- eventLogEntry{"error", eid, msg}
+	return nil
+}
+
+func (w *fakeEventLogWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func TestEventLogWritesContainerEvents(t *testing.T) {
+	w := &fakeEventLogWriter{entries: make(chan eventLogEntry, 10)}
+	e := New()
+	l := NewEventLog(w, map[string]uint32{"start": 100, "die": 102})
+	l.Start(e)
+
+	e.Log("start", eventtypes.ContainerEventType, eventtypes.Actor{ID: "cont", Attributes: map[string]string{"name": "web", "image": "busybox"}})
+	e.Log("pause", eventtypes.ContainerEventType, eventtypes.Actor{ID: "cont"})
+	e.Log("start", eventtypes.NetworkEventType, eventtypes.Actor{ID: "net"})
+	e.Log("die", eventtypes.ContainerEventType, eventtypes.Actor{ID: "cont", Attributes: map[string]string{"name": "web", "exitCode": "0"}})
+	e.Log("die", eventtypes.ContainerEventType, eventtypes.Actor{ID: "cont", Attributes: map[string]string{"name": "web", "exitCode": "137"}})
+
+	for _, expected := range []eventLogEntry{
+		{"info", 100, "Container web: start\r\n\r\nid=cont\r\nimage=busybox\r\nname=web"},
+		{"info", 102, "Container web: die\r\n\r\nid=cont\r\nexitCode=0\r\nname=web"},
+		{"error", 102, "Container web: die\r\n\r\nid=cont\r\nexitCode=137\r\nname=web"},
+	} {
+		select {
+		case entry := <-w.entries:
+			if entry != expected {
+				t.Fatalf("expected entry %+v, got %+v", expected, entry)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for the entry")
+		}
+	}
+	select {
+	case entry := <-w.entries:
+		t.Fatalf("unexpected entry %+v", entry)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	l.Stop()
+	if !w.closed {
+		t.Fatal("expected the writer to be closed on stop")
+	}
+}
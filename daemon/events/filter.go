@@ -23,6 +23,7 @@ func (ef *Filter) Include(ev events.Message) bool {
 		ef.matchContainer(ev) &&
 		ef.matchVolume(ev) &&
 		ef.matchNetwork(ev) &&
+		ef.matchJob(ev) &&
 		ef.matchImage(ev) &&
 		ef.matchLabels(ev.Actor.Attributes)
 }
@@ -46,6 +47,10 @@ func (ef *Filter) matchNetwork(ev events.Message) bool {
 	return ef.fuzzyMatchName(ev, events.NetworkEventType)
 }
 
+func (ef *Filter) matchJob(ev events.Message) bool {
+	return ef.fuzzyMatchName(ev, events.JobEventType)
+}
+
 func (ef *Filter) fuzzyMatchName(ev events.Message, eventType string) bool {
 	return ef.filter.FuzzyMatch(eventType, ev.Actor.ID) ||
 		ef.filter.FuzzyMatch(eventType, ev.Actor.Attributes["name"])
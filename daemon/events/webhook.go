@@ -0,0 +1,170 @@
+package events
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	eventtypes "github.com/docker/engine-api/types/events"
+	"github.com/docker/engine-api/types/filters"
+)
+
+const (
+	// WebhookSignatureHeader is the header of the webhook requests that holds
+	// the HMAC-SHA256 of their body, as "sha256=<hex digest>", when the
+	// webhook has a secret.
+	WebhookSignatureHeader = "X-Docker-Signature"
+
+	webhookQueueSize  = 1024
+	webhookRetries    = 5
+	webhookTimeout    = 10 * time.Second
+	webhookMinBackoff = 500 * time.Millisecond
+	webhookMaxBackoff = 30 * time.Second
+
+	// statusTooManyRequests is only defined by net/http as of Go 1.6.
+	statusTooManyRequests = 429
+)
+
+// Webhook posts the events that match a filter, one JSON message per
+// request, to an HTTP endpoint. The events are queued so that a slow
+// endpoint does not block the other listeners; the events received while
+// the queue is full are dropped.
+type Webhook struct {
+	url    string
+	filter *Filter
+	secret []byte
+	client *http.Client
+
+	// minBackoff is the delay before the first retry of a failed delivery,
+	// doubled on each retry.
+	minBackoff time.Duration
+
+	queue chan eventtypes.Message
+	stop  chan struct{}
+	wg    sync.WaitGroup
+}
+
+// NewWebhook returns a webhook posting the events that match filter to
+// url. The requests are signed with secret if it is not empty.
+func NewWebhook(url string, filter filters.Args, secret []byte) *Webhook {
+	return &Webhook{
+		url:        url,
+		filter:     NewFilter(filter),
+		secret:     secret,
+		client:     &http.Client{Timeout: webhookTimeout},
+		minBackoff: webhookMinBackoff,
+		queue:      make(chan eventtypes.Message, webhookQueueSize),
+		stop:       make(chan struct{}),
+	}
+}
+
+// Start subscribes the webhook to the events of e and starts delivering
+// them, until Stop is called.
+func (w *Webhook) Start(e *Events) {
+	_, l := e.SubscribeTopic(time.Time{}, time.Time{}, w.filter)
+	w.wg.Add(2)
+	go func() {
+		defer w.wg.Done()
+		defer e.Evict(l)
+		for {
+			select {
+			case m := <-l:
+				ev := m.(eventtypes.Message)
+				select {
+				case w.queue <- ev:
+				default:
+					logrus.Warnf("Dropping %s event %s for webhook %s: too many events are waiting to be delivered", ev.Type, ev.Action, w.url)
+				}
+			case <-w.stop:
+				return
+			}
+		}
+	}()
+	go func() {
+		defer w.wg.Done()
+		for {
+			select {
+			case ev := <-w.queue:
+				w.deliver(ev)
+			case <-w.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the webhook. The events that are not delivered yet are
+// dropped.
+func (w *Webhook) Stop() {
+	close(w.stop)
+	w.wg.Wait()
+}
+
+// deliver posts ev, retrying with an exponential backoff on the errors
+// other than a rejection of the request by the endpoint.
+func (w *Webhook) deliver(ev eventtypes.Message) {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		logrus.Errorf("Error encoding event for webhook %s: %v", w.url, err)
+		return
+	}
+	backoff := w.minBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := w.post(body)
+		if err == nil {
+			return
+		}
+		if !retry || attempt == webhookRetries {
+			logrus.Errorf("Error delivering %s event %s to webhook %s: %v", ev.Type, ev.Action, w.url, err)
+			return
+		}
+		logrus.Debugf("Error delivering %s event %s to webhook %s, retrying in %s: %v", ev.Type, ev.Action, w.url, backoff, err)
+		select {
+		case <-time.After(backoff):
+		case <-w.stop:
+			return
+		}
+		if backoff *= 2; backoff > webhookMaxBackoff {
+			backoff = webhookMaxBackoff
+		}
+	}
+}
+
+// post sends body to the endpoint, and returns whether a failed request is
+// worth retrying.
+func (w *Webhook) post(body []byte) (bool, error) {
+	req, err := http.NewRequest("POST", w.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(w.secret) > 0 {
+		req.Header.Set(WebhookSignatureHeader, Sign(w.secret, body))
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	// The other client errors would fail again.
+	retry := resp.StatusCode >= 500 || resp.StatusCode == statusTooManyRequests || resp.StatusCode == http.StatusRequestTimeout
+	return retry, fmt.Errorf("unexpected status %s", resp.Status)
+}
+
+// Sign returns the signature of body with secret, as set in the
+// WebhookSignatureHeader of the webhook requests.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
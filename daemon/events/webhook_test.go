@@ -0,0 +1,108 @@
+package events
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	eventtypes "github.com/docker/engine-api/types/events"
+	"github.com/docker/engine-api/types/filters"
+)
+
+func TestWebhookDeliversFilteredEvents(t *testing.T) {
+	received := make(chan eventtypes.Message, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if sig := r.Header.Get(WebhookSignatureHeader); sig != Sign([]byte("secret"), body) {
+			t.Errorf("unexpected signature %q", sig)
+		}
+		var ev eventtypes.Message
+		if err := json.Unmarshal(body, &ev); err != nil {
+			t.Error(err)
+		}
+		received <- ev
+	}))
+	defer srv.Close()
+
+	f := filters.NewArgs()
+	f.Add("event", "die")
+	e := New()
+	w := NewWebhook(srv.URL, f, []byte("secret"))
+	w.Start(e)
+	defer w.Stop()
+
+	e.Log("start", eventtypes.ContainerEventType, eventtypes.Actor{ID: "cont"})
+	e.Log("die", eventtypes.ContainerEventType, eventtypes.Actor{ID: "cont", Attributes: map[string]string{"exitCode": "1"}})
+
+	select {
+	case ev := <-received:
+		if ev.Action != "die" || ev.Actor.Attributes["exitCode"] != "1" {
+			t.Fatalf("unexpected event %+v", ev)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the event")
+	}
+	select {
+	case ev := <-received:
+		t.Fatalf("unexpected event %+v", ev)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWebhookRetries(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		attempts int
+	)
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		switch attempts {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			if r.Header.Get(WebhookSignatureHeader) != "" {
+				t.Error("unexpected signature of a webhook without secret")
+			}
+			close(done)
+		}
+	}))
+	defer srv.Close()
+
+	e := New()
+	w := NewWebhook(srv.URL, filters.NewArgs(), nil)
+	w.minBackoff = time.Millisecond
+	w.Start(e)
+	defer w.Stop()
+
+	e.Log("oom", eventtypes.ContainerEventType, eventtypes.Actor{ID: "cont"})
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the retry")
+	}
+}
+
+func TestWebhookDoesNotRetryRejectedEvents(t *testing.T) {
+	w := NewWebhook("", filters.NewArgs(), nil)
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+	w.url = srv.URL
+
+	retry, err := w.post([]byte("{}"))
+	if err == nil || retry {
+		t.Fatalf("expected a rejection not to be retried, got %v, %v", retry, err)
+	}
+}
@@ -75,6 +75,37 @@ func TestLogContainerEventWithAttributes(t *testing.T) {
 	})
 }
 
+func TestLogContainerEventImageAndExitCode(t *testing.T) {
+	e := events.New()
+	_, l, _ := e.Subscribe()
+	defer e.Evict(l)
+
+	state := container.NewState()
+	state.ExitCode = 3
+	state.FinishedAt = time.Now()
+	container := &container.Container{
+		CommonContainer: container.CommonContainer{
+			ID:      "container_id",
+			Name:    "container_name",
+			ImageID: "sha256:0123456789abcdef",
+			State:   state,
+			Config: &containertypes.Config{
+				Image: "image_name",
+			},
+		},
+	}
+	daemon := &Daemon{
+		EventsService: e,
+	}
+	daemon.LogContainerEvent(container, "destroy")
+
+	validateTestAttributes(t, l, map[string]string{
+		"image":    "image_name",
+		"imageID":  "sha256:0123456789abcdef",
+		"exitCode": "3",
+	})
+}
+
 func validateTestAttributes(t *testing.T, l chan interface{}, expectedAttributesToTest map[string]string) {
 	select {
 	case ev := <-l:
@@ -9,6 +9,7 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/api/types/backend"
 	"github.com/docker/docker/container"
 	"github.com/docker/docker/daemon/exec"
 	"github.com/docker/docker/errors"
@@ -94,6 +95,13 @@ func (d *Daemon) ContainerExecCreate(name string, config *types.ExecConfig) (str
 		return "", err
 	}
 
+	if err := verifyExecResources(container, config); err != nil {
+		return "", err
+	}
+	if owner := container.Config.Labels[backend.OwnerLabel]; owner != "" && config.Privileged && d.configStore.Tenancy {
+		return "", errors.NewRequestForbiddenError(fmt.Errorf("privileged mode not allowed in tenancy mode"))
+	}
+
 	cmd := strslice.StrSlice(config.Cmd)
 	entrypoint, args := d.getEntrypointAndArgs(strslice.StrSlice{}, cmd)
 
@@ -117,6 +125,9 @@ func (d *Daemon) ContainerExecCreate(name string, config *types.ExecConfig) (str
 	execConfig.Tty = config.Tty
 	execConfig.Privileged = config.Privileged
 	execConfig.User = config.User
+	execConfig.NanoCPUs = config.NanoCPUs
+	execConfig.Memory = config.Memory
+	execConfig.Requester = config.Requester
 	if len(execConfig.User) == 0 {
 		execConfig.User = container.Config.User
 	}
@@ -124,13 +135,17 @@ func (d *Daemon) ContainerExecCreate(name string, config *types.ExecConfig) (str
 	d.registerExecCommand(container, execConfig)
 
 	d.LogContainerEvent(container, "exec_create: "+execConfig.Entrypoint+" "+strings.Join(execConfig.Args, " "))
+	if execConfig.Privileged {
+		d.auditOptions(container, "exec_create", []string{"privileged"}, config.Requester)
+	}
 
 	return execConfig.ID, nil
 }
 
-// ContainerExecStart starts a previously set up exec instance. The
+// ContainerExecStart starts a previously set up exec instance. Waiting on
+// the container runtime to start the process is given up once ctx is done. The
 // std streams are set up.
-func (d *Daemon) ContainerExecStart(name string, stdin io.ReadCloser, stdout io.Writer, stderr io.Writer) (err error) {
+func (d *Daemon) ContainerExecStart(ctx context.Context, name string, stdin io.ReadCloser, stdout io.Writer, stderr io.Writer) (err error) {
 	var (
 		cStdin           io.ReadCloser
 		cStdout, cStderr io.Writer
@@ -199,8 +214,22 @@ func (d *Daemon) ContainerExecStart(name string, stdin io.ReadCloser, stdout io.
 
 	attachErr := container.AttachStreams(context.Background(), ec.StreamConfig, ec.OpenStdin, true, ec.Tty, cStdin, cStdout, cStderr, ec.DetachKeys)
 
-	if err := d.containerd.AddProcess(c.ID, name, p); err != nil {
-		return err
+	done := d.watchOperation(c, "exec")
+	ec.Started = time.Now().UTC()
+	ctx, cancel := d.runtimeContext(ctx)
+	err = d.containerd.AddProcess(ctx, c.ID, name, p)
+	cancel()
+	done()
+	if err != nil {
+		return runtimeError(err, err)
+	}
+	c.Lock()
+	d.recordExec(c, ec)
+	c.Unlock()
+	if ec.NanoCPUs != 0 || ec.Memory != 0 {
+		if err := d.setExecResources(c, ec); err != nil {
+			return err
+		}
 	}
 
 	err = <-attachErr
@@ -210,6 +239,42 @@ func (d *Daemon) ContainerExecStart(name string, stdin io.ReadCloser, stdout io.
 	return nil
 }
 
+// recordExec adds ec to the exec history of c, which must be locked. The
+// history is kept only if ExecHistorySize is positive.
+func (d *Daemon) recordExec(c *container.Container, ec *exec.Config) {
+	if d.configStore.ExecHistorySize <= 0 {
+		return
+	}
+	record := types.ExecRecord{
+		ID:         ec.ID,
+		Cmd:        append([]string{ec.Entrypoint}, ec.Args...),
+		User:       ec.User,
+		Privileged: ec.Privileged,
+		Requester:  ec.Requester,
+		Started:    ec.Started,
+		ExitCode:   ec.ExitCode,
+	}
+	if ec.ExitCode != nil {
+		finished := time.Now().UTC()
+		record.Finished = &finished
+	}
+	if err := c.RecordExec(record, d.configStore.ExecHistorySize); err != nil {
+		logrus.Errorf("Failed to record exec %s in the history of container %s: %v", ec.ID, c.ID, err)
+	}
+}
+
+// ContainerExecHistory returns the execs run in the container name, from
+// the oldest.
+func (d *Daemon) ContainerExecHistory(name string) ([]types.ExecRecord, error) {
+	c, err := d.GetContainer(name)
+	if err != nil {
+		return nil, err
+	}
+	c.Lock()
+	defer c.Unlock()
+	return c.ExecHistory()
+}
+
 // execCommandGC runs a ticker to clean up the daemon references
 // of exec configs that are no longer part of the container.
 func (d *Daemon) execCommandGC() {
@@ -2,9 +2,11 @@ package exec
 
 import (
 	"sync"
+	"time"
 
 	"github.com/docker/docker/pkg/stringid"
 	"github.com/docker/docker/runconfig"
+	"github.com/docker/engine-api/types"
 )
 
 // Config holds the configurations for execs. The Daemon keeps
@@ -27,6 +29,14 @@ type Config struct {
 	Tty         bool
 	Privileged  bool
 	User        string
+	// NanoCPUs and Memory limit the resources of the process within the
+	// limits of the container.
+	NanoCPUs int64
+	Memory   int64
+	// Requester is the client that created the exec, and Started the
+	// time its process started, recorded in the exec history.
+	Requester types.Requester
+	Started   time.Time
 }
 
 // NewConfig initializes the a new exec configuration
@@ -1,12 +1,26 @@
 package daemon
 
 import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+
+	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/container"
 	"github.com/docker/docker/daemon/caps"
 	"github.com/docker/docker/daemon/exec"
 	"github.com/docker/docker/libcontainerd"
+	"github.com/docker/docker/pkg/sysinfo"
+	"github.com/docker/engine-api/types"
 )
 
+// execCPUPeriod is the CFS period of the cgroups of the execs, in
+// microseconds.
+const execCPUPeriod = 100000
+
 func execSetPlatformOpt(c *container.Container, ec *exec.Config, p *libcontainerd.Process) error {
 	if len(ec.User) > 0 {
 		uid, gid, additionalGids, err := getUser(c, ec.User)
@@ -24,3 +38,98 @@ func execSetPlatformOpt(c *container.Container, ec *exec.Config, p *libcontainer
 	}
 	return nil
 }
+
+// verifyExecResources returns an error if the resource limits of config
+// can't be applied to an exec in c.
+func verifyExecResources(c *container.Container, config *types.ExecConfig) error {
+	if config.NanoCPUs == 0 && config.Memory == 0 {
+		return nil
+	}
+	if config.NanoCPUs < 0 || config.NanoCPUs > 0 && config.NanoCPUs*execCPUPeriod/1e9 < 1000 {
+		return fmt.Errorf("Invalid CPUs for the exec, the minimum is 0.01")
+	}
+	if config.Memory < 0 || config.Memory > 0 && config.Memory < linuxMinMemory {
+		return fmt.Errorf("Minimum memory limit allowed is 4MB")
+	}
+	// the container's processes are in its cgroup, so the unified
+	// hierarchy allows no cgroup with controllers below it
+	if sysinfo.IsCgroup2UnifiedMode() {
+		return fmt.Errorf("The resource limits of execs are not supported with the cgroup v2 unified hierarchy")
+	}
+	sysInfo := sysinfo.New(true)
+	if config.NanoCPUs > 0 && !sysInfo.CPUCfsQuota {
+		return fmt.Errorf("Your kernel does not support CPU cfs quota")
+	}
+	if config.Memory > 0 && !sysInfo.MemoryLimit {
+		return fmt.Errorf("Your kernel does not support memory limit capabilities")
+	}
+	return nil
+}
+
+// execCgroupLimits returns the cgroup files to write the limits of ec to,
+// by subsystem.
+func execCgroupLimits(ec *exec.Config) map[string]map[string]int64 {
+	limits := make(map[string]map[string]int64)
+	if ec.NanoCPUs > 0 {
+		limits["cpu"] = map[string]int64{"cpu.cfs_quota_us": ec.NanoCPUs * execCPUPeriod / 1e9}
+	}
+	if ec.Memory > 0 {
+		limits["memory"] = map[string]int64{"memory.limit_in_bytes": ec.Memory}
+	}
+	return limits
+}
+
+// setExecResources moves the process of ec, which just started in c, to
+// cgroups of its own below the cgroups of c, limited to the resources of
+// ec. The process is killed if it can't be limited.
+func (d *Daemon) setExecResources(c *container.Container, ec *exec.Config) error {
+	pid, err := d.containerd.GetPidForProcess(c.ID, ec.ID)
+	if err != nil {
+		return err
+	}
+	if err := moveToExecCgroups(c.State.Pid, pid, ec); err != nil {
+		syscall.Kill(pid, syscall.SIGKILL)
+		return fmt.Errorf("Cannot limit the resources of exec %s: %v", ec.ID, err)
+	}
+	return nil
+}
+
+func moveToExecCgroups(containerPid, pid int, ec *exec.Config) error {
+	for subsystem, files := range execCgroupLimits(ec) {
+		dir, err := cgroupV1Dir(containerPid, subsystem)
+		if err != nil {
+			return err
+		}
+		dir = filepath.Join(dir, "exec-"+ec.ID)
+		if err := os.Mkdir(dir, 0755); err != nil && !os.IsExist(err) {
+			return err
+		}
+		for file, value := range files {
+			if err := ioutil.WriteFile(filepath.Join(dir, file), []byte(strconv.FormatInt(value, 10)), 0); err != nil {
+				return err
+			}
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, "cgroup.procs"), []byte(strconv.Itoa(pid)), 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cleanupExecResources removes the cgroups of ec once its process exited.
+// The runtime removes them with the cgroups of c if c exited.
+func (d *Daemon) cleanupExecResources(c *container.Container, ec *exec.Config) {
+	if !c.Running {
+		return
+	}
+	for subsystem := range execCgroupLimits(ec) {
+		dir, err := cgroupV1Dir(c.State.Pid, subsystem)
+		if err != nil {
+			continue
+		}
+		// the children the process left behind keep the cgroup busy
+		if err := os.Remove(filepath.Join(dir, "exec-"+ec.ID)); err != nil && !os.IsNotExist(err) {
+			logrus.Debugf("Failed to remove the %s cgroup of exec %s: %v", subsystem, ec.ID, err)
+		}
+	}
+}
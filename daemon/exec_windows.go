@@ -1,13 +1,147 @@
 package daemon
 
 import (
+	"fmt"
+	"runtime"
+	"syscall"
+	"unsafe"
+
 	"github.com/docker/docker/container"
 	"github.com/docker/docker/daemon/exec"
 	"github.com/docker/docker/libcontainerd"
+	"github.com/docker/engine-api/types"
+)
+
+var (
+	modkernel32 = syscall.NewLazyDLL("kernel32.dll")
+
+	procCreateJobObjectW         = modkernel32.NewProc("CreateJobObjectW")
+	procSetInformationJobObject  = modkernel32.NewProc("SetInformationJobObject")
+	procAssignProcessToJobObject = modkernel32.NewProc("AssignProcessToJobObject")
+)
+
+// https://msdn.microsoft.com/en-us/library/windows/desktop/ms686216(v=vs.85).aspx
+const (
+	jobObjectExtendedLimitInformation  = 9
+	jobObjectCPURateControlInformation = 15
+	jobObjectLimitJobMemory            = 0x200
+	jobObjectCPURateControlEnable      = 0x1
+	jobObjectCPURateControlHardCap     = 0x4
+	processSetQuota                    = 0x0100
+	processTerminate                   = 0x0001
+	jobObjectCPURateControlMaxRate     = 10000
+	jobObjectCPURateControlMinRate     = 1
 )
 
+// https://msdn.microsoft.com/en-us/library/windows/desktop/ms684156(v=vs.85).aspx
+type jobObjectExtendedLimitInfo struct {
+	PerProcessUserTimeLimit int64
+	PerJobUserTimeLimit     int64
+	LimitFlags              uint32
+	MinimumWorkingSetSize   uintptr
+	MaximumWorkingSetSize   uintptr
+	ActiveProcessLimit      uint32
+	Affinity                uintptr
+	PriorityClass           uint32
+	SchedulingClass         uint32
+	IoCounters              [6]uint64
+	ProcessMemoryLimit      uintptr
+	JobMemoryLimit          uintptr
+	PeakProcessMemoryUsed   uintptr
+	PeakJobMemoryUsed       uintptr
+}
+
+// https://msdn.microsoft.com/en-us/library/windows/desktop/hh448384(v=vs.85).aspx
+type jobObjectCPURateControlInfo struct {
+	ControlFlags uint32
+	CPURate      uint32
+}
+
 func execSetPlatformOpt(c *container.Container, ec *exec.Config, p *libcontainerd.Process) error {
 	// Process arguments need to be escaped before sending to OCI.
 	p.Args = escapeArgs(p.Args)
 	return nil
 }
+
+// verifyExecResources returns an error if the resource limits of config
+// can't be applied to an exec in c.
+func verifyExecResources(c *container.Container, config *types.ExecConfig) error {
+	if config.NanoCPUs == 0 && config.Memory == 0 {
+		return nil
+	}
+	if config.NanoCPUs < 0 || config.Memory < 0 {
+		return fmt.Errorf("Invalid resource limits for the exec")
+	}
+	// the processes of Hyper-V containers run in the utility VM, out of
+	// the reach of the job objects of the host
+	if c.HostConfig.Isolation.IsHyperV() {
+		return fmt.Errorf("The resource limits of execs are not supported with hyperv isolation")
+	}
+	return nil
+}
+
+// setExecResources assigns the process of ec, which just started in c, to
+// a job object limited to the resources of ec. The process is terminated
+// if it can't be limited. The job object is destroyed once the process and
+// its children exited.
+func (d *Daemon) setExecResources(c *container.Container, ec *exec.Config) error {
+	pid, err := d.containerd.GetPidForProcess(c.ID, ec.ID)
+	if err != nil {
+		return err
+	}
+	process, err := syscall.OpenProcess(processSetQuota|processTerminate, false, uint32(pid))
+	if err != nil {
+		return err
+	}
+	defer syscall.CloseHandle(process)
+	if err := assignToExecJobObject(process, ec); err != nil {
+		syscall.TerminateProcess(process, 1)
+		return fmt.Errorf("Cannot limit the resources of exec %s: %v", ec.ID, err)
+	}
+	return nil
+}
+
+func assignToExecJobObject(process syscall.Handle, ec *exec.Config) error {
+	r, _, err := procCreateJobObjectW.Call(0, 0)
+	if r == 0 {
+		return err
+	}
+	job := syscall.Handle(r)
+	defer syscall.CloseHandle(job)
+
+	if ec.NanoCPUs > 0 {
+		// the rate is the part of the cycles of all the processors, in
+		// hundredths of percent
+		rate := ec.NanoCPUs * jobObjectCPURateControlMaxRate / (int64(runtime.NumCPU()) * 1e9)
+		if rate < jobObjectCPURateControlMinRate {
+			rate = jobObjectCPURateControlMinRate
+		} else if rate > jobObjectCPURateControlMaxRate {
+			rate = jobObjectCPURateControlMaxRate
+		}
+		info := jobObjectCPURateControlInfo{
+			ControlFlags: jobObjectCPURateControlEnable | jobObjectCPURateControlHardCap,
+			CPURate:      uint32(rate),
+		}
+		if r, _, err := procSetInformationJobObject.Call(uintptr(job), jobObjectCPURateControlInformation, uintptr(unsafe.Pointer(&info)), unsafe.Sizeof(info)); r == 0 {
+			return err
+		}
+	}
+	if ec.Memory > 0 {
+		info := jobObjectExtendedLimitInfo{
+			LimitFlags:     jobObjectLimitJobMemory,
+			JobMemoryLimit: uintptr(ec.Memory),
+		}
+		if r, _, err := procSetInformationJobObject.Call(uintptr(job), jobObjectExtendedLimitInformation, uintptr(unsafe.Pointer(&info)), unsafe.Sizeof(info)); r == 0 {
+			return err
+		}
+	}
+	if r, _, err := procAssignProcessToJobObject.Call(uintptr(job), uintptr(process)); r == 0 {
+		return err
+	}
+	return nil
+}
+
+// cleanupExecResources releases the resources set up to limit ec. This is
+// a no-op on Windows, where the job object goes away with the processes.
+func (d *Daemon) cleanupExecResources(c *container.Container, ec *exec.Config) {
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/graphdriver"
 	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/ioutils"
 )
@@ -30,6 +32,49 @@ func (daemon *Daemon) ContainerExport(name string, out io.Writer) error {
 	return nil
 }
 
+// ContainerExportSnapshot writes to out the contents of a point-in-time
+// snapshot of the container, taken without pausing it. The containers which
+// aren't running are exported as is.
+func (daemon *Daemon) ContainerExportSnapshot(name string, out io.Writer) error {
+	container, err := daemon.GetContainer(name)
+	if err != nil {
+		return err
+	}
+	if !container.IsRunning() {
+		return daemon.ContainerExport(name, out)
+	}
+
+	dir, release, err := container.RWLayer.MountSnapshot(container.GetMountLabel())
+	if err == graphdriver.ErrSnapshotNotSupported {
+		return fmt.Errorf("The %s storage driver cannot snapshot container %s, export it without --snapshot instead", daemon.GraphDriverName(), name)
+	}
+	if err != nil {
+		return fmt.Errorf("Error exporting container %s: %v", name, err)
+	}
+	defer func() {
+		if err := release(); err != nil {
+			logrus.Errorf("Failed to release the snapshot of container %s: %v", container.ID, err)
+		}
+	}()
+
+	uidMaps, gidMaps := daemon.GetUIDGIDMaps()
+	data, err := archive.TarWithOptions(dir, &archive.TarOptions{
+		Compression: archive.Uncompressed,
+		UIDMaps:     uidMaps,
+		GIDMaps:     gidMaps,
+	})
+	if err != nil {
+		return fmt.Errorf("Error exporting container %s: %v", name, err)
+	}
+	defer data.Close()
+	daemon.LogContainerEvent(container, "export")
+
+	if _, err := io.Copy(out, data); err != nil {
+		return fmt.Errorf("Error exporting container %s: %v", name, err)
+	}
+	return nil
+}
+
 func (daemon *Daemon) containerExport(container *container.Container) (archive.Archive, error) {
 	if err := daemon.Mount(container); err != nil {
 		return nil, err
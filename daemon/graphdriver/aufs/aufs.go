@@ -418,6 +418,16 @@ func (a *Driver) Changes(id, parent string) ([]archive.Change, error) {
 	return archive.Changes(layers, path.Join(a.rootPath(), "diff", id))
 }
 
+// WalkChanges calls fn for each change between the specified layer and its
+// parent layer.
+func (a *Driver) WalkChanges(id, parent string, fn func(archive.Change) error) error {
+	layers, err := a.getParentLayerPaths(id)
+	if err != nil {
+		return err
+	}
+	return archive.WalkChanges(layers, path.Join(a.rootPath(), "diff", id), fn)
+}
+
 func (a *Driver) getParentLayerPaths(id string) ([]string, error) {
 	parentIds, err := getParentIds(a.rootPath(), id)
 	if err != nil {
@@ -472,6 +472,12 @@ func (d *Driver) setStorageSize(dir string, driver *Driver) error {
 	return nil
 }
 
+// Snapshot creates the subvolume id as a snapshot of the subvolume parent,
+// which may be in use.
+func (d *Driver) Snapshot(id, parent string) error {
+	return d.Create(id, parent, "", nil)
+}
+
 // Remove the filesystem with given id.
 func (d *Driver) Remove(id string) error {
 	dir := d.subvolumesDirID(id)
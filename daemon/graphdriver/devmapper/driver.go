@@ -135,6 +135,12 @@ func (d *Driver) Create(id, parent, mountLabel string, storageOpt map[string]str
 	return nil
 }
 
+// Snapshot creates the device id as a thin snapshot of the device parent,
+// which may be in use.
+func (d *Driver) Snapshot(id, parent string) error {
+	return d.Create(id, parent, "", nil)
+}
+
 // Remove removes a device with a given id, unmounts the filesystem.
 func (d *Driver) Remove(id string) error {
 	if !d.DeviceSet.HasDevice(id) {
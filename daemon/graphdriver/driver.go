@@ -32,6 +32,9 @@ var (
 	ErrPrerequisites = errors.New("prerequisites for driver not satisfied (wrong filesystem?)")
 	// ErrIncompatibleFS returned when file system is not supported.
 	ErrIncompatibleFS = fmt.Errorf("backing file system is unsupported for this graph driver")
+	// ErrSnapshotNotSupported is returned when the driver cannot snapshot
+	// a layer in use.
+	ErrSnapshotNotSupported = errors.New("storage driver cannot snapshot a layer in use")
 )
 
 // InitFunc initializes the storage driver.
@@ -96,6 +99,25 @@ type Driver interface {
 	DiffSize(id, parent string) (size int64, err error)
 }
 
+// ChangeWalker is the interface for drivers that can report the changes
+// between a layer and its parent one at a time, rather than collecting them
+// in memory first.
+type ChangeWalker interface {
+	// WalkChanges calls fn for each change between the specified layer
+	// and its parent layer, which may be "". The walk stops at the first
+	// error returned by fn.
+	WalkChanges(id, parent string, fn func(archive.Change) error) error
+}
+
+// Snapshotter is the interface for drivers that can create a layer with a
+// point-in-time copy of another layer, while that layer is mounted and being
+// written to.
+type Snapshotter interface {
+	// Snapshot creates the layer id with the current content of the
+	// layer parent.
+	Snapshot(id, parent string) error
+}
+
 // DiffGetterDriver is the interface for layered file system drivers that
 // provide a specialized function for getting file contents for tar-split.
 type DiffGetterDriver interface {
@@ -127,6 +149,12 @@ func Register(name string, initFunc InitFunc) error {
 	return nil
 }
 
+// IsRegistered returns whether the driver name is built in.
+func IsRegistered(name string) bool {
+	_, exists := drivers[name]
+	return exists
+}
+
 // GetDriver initializes and returns the registered driver
 func GetDriver(name, home string, options []string, uidMaps, gidMaps []idtools.IDMap) (Driver, error) {
 	if initFunc, exists := drivers[name]; exists {
@@ -116,6 +116,40 @@ func (gdw *NaiveDiffDriver) Changes(id, parent string) ([]archive.Change, error)
 	return archive.ChangesDirs(layerFs, parentFs)
 }
 
+// Snapshot creates the layer id with the current content of the layer
+// parent, if the wrapped driver is a Snapshotter.
+func (gdw *NaiveDiffDriver) Snapshot(id, parent string) error {
+	if s, ok := gdw.ProtoDriver.(Snapshotter); ok {
+		return s.Snapshot(id, parent)
+	}
+	return ErrSnapshotNotSupported
+}
+
+// WalkChanges calls fn for each change between the specified layer and its
+// parent layer. Files renamed in a layer that is a snapshot of its parent
+// are reported as renames.
+func (gdw *NaiveDiffDriver) WalkChanges(id, parent string, fn func(archive.Change) error) error {
+	driver := gdw.ProtoDriver
+
+	layerFs, err := driver.Get(id, "")
+	if err != nil {
+		return err
+	}
+	defer driver.Put(id)
+
+	parentFs := ""
+
+	if parent != "" {
+		parentFs, err = driver.Get(parent, "")
+		if err != nil {
+			return err
+		}
+		defer driver.Put(parent)
+	}
+
+	return archive.WalkChangesDirs(layerFs, parentFs, fn)
+}
+
 // ApplyDiff extracts the changeset from the given diff into the
 // layer with the specified id and parent, returning the size of the
 // new layer in bytes.
@@ -536,6 +536,22 @@ func (d *Driver) GetMetadata(id string) (map[string]string, error) {
 	return m, nil
 }
 
+// layerIOBufferSize is the size of the buffers used to write the backup
+// streams of the files of a layer. Larger buffers mean fewer BackupWrite
+// calls, which dominate the import of layers with many small files.
+const layerIOBufferSize = 1024 * 1024
+
+// readAhead reads r in a goroutine, so that reading and decompressing the
+// layer data overlaps with writing it to the layer.
+func readAhead(r io.Reader) io.ReadCloser {
+	bp := ioutils.NewBytesPipe()
+	go func() {
+		_, err := io.Copy(bp, r)
+		bp.CloseWithError(err)
+	}()
+	return bp
+}
+
 func writeTarFromLayer(r hcsshim.LayerReader, w io.Writer) error {
 	t := tar.NewWriter(w)
 	for {
@@ -567,7 +583,9 @@ func writeTarFromLayer(r hcsshim.LayerReader, w io.Writer) error {
 
 // exportLayer generates an archive from a layer based on the given ID.
 func (d *Driver) exportLayer(id string, parentLayerPaths []string) (archive.Archive, error) {
-	archive, w := io.Pipe()
+	// The archive is buffered, so that the layer is read while the consumer
+	// compresses or hashes the data already read.
+	w := ioutils.NewBytesPipe()
 	go func() {
 		err := winio.RunWithPrivilege(winio.SeBackupPrivilege, func() error {
 			r, err := hcsshim.NewLayerReader(d.info, id, parentLayerPaths)
@@ -585,14 +603,17 @@ func (d *Driver) exportLayer(id string, parentLayerPaths []string) (archive.Arch
 		w.CloseWithError(err)
 	}()
 
-	return archive, nil
+	return w, nil
 }
 
 func writeLayerFromTar(r archive.Reader, w hcsshim.LayerWriter) (int64, error) {
 	t := tar.NewReader(r)
 	hdr, err := t.Next()
 	totalSize := int64(0)
-	buf := bufio.NewWriter(nil)
+	buf := bufio.NewWriterSize(nil, layerIOBufferSize)
+	// Most files of a layer share a few security descriptors, so the VM ACE
+	// is only added once to each.
+	sddls := make(map[string]string)
 	for err == nil {
 		base := path.Base(hdr.Name)
 		if strings.HasPrefix(base, archive.WhiteoutPrefix) {
@@ -635,9 +656,15 @@ func writeLayerFromTar(r archive.Reader, w hcsshim.LayerWriter) (int64, error) {
 					} else {
 						ace = "(A;;0x1200a9;;;S-1-5-83-0)"
 					}
-					if hdr.Winheaders["sd"], ok = addAceToSddlDacl(sddl, ace); !ok {
-						logrus.Debugf("failed to add VM ACE to %s", sddl)
+					key := ace + sddl
+					newSddl, cached := sddls[key]
+					if !cached {
+						if newSddl, ok = addAceToSddlDacl(sddl, ace); !ok {
+							logrus.Debugf("failed to add VM ACE to %s", sddl)
+						}
+						sddls[key] = newSddl
 					}
+					hdr.Winheaders["sd"] = newSddl
 				}
 			}
 
@@ -736,7 +763,10 @@ func writeLayer() {
 			return err
 		}
 
-		size, err := writeLayerFromTar(os.Stdin, w)
+		layerData := readAhead(os.Stdin)
+		defer layerData.Close()
+
+		size, err := writeLayerFromTar(layerData, w)
 		if err != nil {
 			return err
 		}
@@ -1,6 +1,10 @@
 package windows
 
-import "testing"
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
 
 func TestAddAceToSddlDacl(t *testing.T) {
 	cases := [][3]string{
@@ -16,3 +20,17 @@ func TestAddAceToSddlDacl(t *testing.T) {
 		}
 	}
 }
+
+func TestReadAhead(t *testing.T) {
+	data := bytes.Repeat([]byte("layer data"), 1024*1024)
+	r := readAhead(bytes.NewReader(data))
+	defer r.Close()
+
+	read, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(read, data) {
+		t.Fatalf("expected %d bytes to be read ahead, got %d", len(data), len(read))
+	}
+}
@@ -291,6 +291,12 @@ func (d *Driver) create(id, parent string) error {
 	return d.cloneFilesystem(name, d.zfsPath(parent))
 }
 
+// Snapshot creates the dataset id as a clone of a snapshot of the dataset
+// parent, which may be in use.
+func (d *Driver) Snapshot(id, parent string) error {
+	return d.Create(id, parent, "", nil)
+}
+
 // Remove deletes the dataset, filesystem and the cache for the given id.
 func (d *Driver) Remove(id string) error {
 	name := d.zfsPath(id)
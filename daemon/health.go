@@ -0,0 +1,105 @@
+package daemon
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/docker/engine-api/types"
+	"golang.org/x/net/context"
+)
+
+// healthCheckTimeout is how long a subsystem is given to respond to its
+// health check before it is reported as unhealthy.
+const healthCheckTimeout = 5 * time.Second
+
+// healthCheck checks a subsystem of the daemon.
+type healthCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// SystemHealth checks the subsystems the daemon depends on, for the
+// health probes of node agents: that the layer store is writable, and the
+// platform checks such as the connection to containerd. Unlike readiness,
+// health doesn't depend on the restore of the containers.
+func (daemon *Daemon) SystemHealth() *types.DaemonHealth {
+	checks := append([]healthCheck{{"layer-store", daemon.checkLayerStore}}, daemon.platformHealthChecks()...)
+
+	health := &types.DaemonHealth{Healthy: true}
+	for _, c := range checks {
+		r := types.HealthCheckResult{Name: c.name, Healthy: true}
+		if err := runHealthCheck(c.check, healthCheckTimeout); err != nil {
+			r.Healthy = false
+			r.Error = err.Error()
+			health.Healthy = false
+		}
+		health.Checks = append(health.Checks, r)
+	}
+	return health
+}
+
+// SystemReadiness returns whether the daemon is ready to serve the
+// containers, for the readiness probes of load balancers: once it has
+// restored the containers of its previous run, and until it shuts down.
+func (daemon *Daemon) SystemReadiness() *types.DaemonReadiness {
+	switch {
+	case daemon.IsShuttingDown():
+		return &types.DaemonReadiness{Reason: "the daemon is shutting down"}
+	case !daemon.isRestored():
+		return &types.DaemonReadiness{Reason: "the daemon is restoring the containers"}
+	}
+	return &types.DaemonReadiness{Ready: true}
+}
+
+func (daemon *Daemon) setRestored() {
+	daemon.restoredMu.Lock()
+	daemon.restored = true
+	daemon.restoredMu.Unlock()
+}
+
+func (daemon *Daemon) isRestored() bool {
+	daemon.restoredMu.Lock()
+	defer daemon.restoredMu.Unlock()
+	return daemon.restored
+}
+
+// runHealthCheck runs check, failing it if it doesn't return within
+// timeout. A check hanging past its timeout is left running.
+func runHealthCheck(check func(ctx context.Context) error, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- check(ctx)
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("no response within %s", timeout)
+	}
+}
+
+// checkLayerStore checks that the metadata directory of the layer store is
+// writable, by writing a file to its temporary directory, in which it
+// stages the metadata of the layers being registered.
+func (daemon *Daemon) checkLayerStore(ctx context.Context) error {
+	dir := filepath.Join(daemon.configStore.imageRoot(), "image", daemon.GraphDriverName(), "layerdb", "tmp")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(dir, "healthz-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write([]byte("ok")); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,38 @@
+package daemon
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestRunHealthCheck(t *testing.T) {
+	if err := runHealthCheck(func(ctx context.Context) error { return nil }, time.Second); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := runHealthCheck(func(ctx context.Context) error { return errors.New("down") }, time.Second); err == nil || err.Error() != "down" {
+		t.Fatalf("expected the error of the check, got %v", err)
+	}
+	block := make(chan struct{})
+	defer close(block)
+	if err := runHealthCheck(func(ctx context.Context) error { <-block; return nil }, 10*time.Millisecond); err == nil {
+		t.Fatal("expected an error for a check not responding, got nil")
+	}
+}
+
+func TestSystemReadiness(t *testing.T) {
+	d := &Daemon{}
+	if r := d.SystemReadiness(); r.Ready {
+		t.Fatal("expected the daemon not to be ready before the containers are restored")
+	}
+	d.setRestored()
+	if r := d.SystemReadiness(); !r.Ready {
+		t.Fatalf("expected the daemon to be ready, got %s", r.Reason)
+	}
+	d.shutdown = true
+	if r := d.SystemReadiness(); r.Ready {
+		t.Fatal("expected the daemon not to be ready while shutting down")
+	}
+}
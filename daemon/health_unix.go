@@ -0,0 +1,15 @@
+// +build linux freebsd
+
+package daemon
+
+import "golang.org/x/net/context"
+
+// platformHealthChecks returns the health checks of the subsystems of the
+// daemon specific to the platform: on Unix, the connection to containerd.
+func (daemon *Daemon) platformHealthChecks() []healthCheck {
+	return []healthCheck{
+		{"containerd", func(ctx context.Context) error {
+			return daemon.containerdRemote.Ping(ctx)
+		}},
+	}
+}
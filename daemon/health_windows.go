@@ -0,0 +1,18 @@
+package daemon
+
+import (
+	"github.com/Microsoft/hcsshim"
+	"golang.org/x/net/context"
+)
+
+// platformHealthChecks returns the health checks of the subsystems of the
+// daemon specific to the platform: on Windows, that the Host Network
+// Service responds.
+func (daemon *Daemon) platformHealthChecks() []healthCheck {
+	return []healthCheck{
+		{"hns", func(ctx context.Context) error {
+			_, err := hcsshim.HNSListNetworkRequest("GET", "", "")
+			return err
+		}},
+	}
+}
@@ -1,15 +1,19 @@
 package daemon
 
 import (
+	"fmt"
 	"io"
+	"io/ioutil"
 	"strings"
 
 	"github.com/docker/distribution/digest"
 	"github.com/docker/docker/builder"
 	"github.com/docker/docker/distribution"
 	"github.com/docker/docker/pkg/progress"
+	"github.com/docker/docker/pkg/tracing"
 	"github.com/docker/docker/reference"
 	"github.com/docker/docker/registry"
+	"github.com/docker/docker/utils"
 	"github.com/docker/engine-api/types"
 	"golang.org/x/net/context"
 )
@@ -44,6 +48,58 @@ func (daemon *Daemon) PullImage(ctx context.Context, image, tag string, metaHead
 	return daemon.pullImageWithReference(ctx, ref, metaHeaders, authConfig, outStream)
 }
 
+// ImageRemoteTags returns the tags of the repository image in its registry,
+// with the digests of their manifests if withDigests is set.
+func (daemon *Daemon) ImageRemoteTags(ctx context.Context, image string, withDigests bool, metaHeaders map[string][]string, authConfig *types.AuthConfig) ([]types.RemoteTag, error) {
+	ref, err := reference.ParseNamed(image)
+	if err != nil {
+		return nil, err
+	}
+	if !reference.IsNameOnly(ref) {
+		return nil, fmt.Errorf("%s is not a repository name: it must not have a tag or a digest", image)
+	}
+	return distribution.RemoteTags(ctx, ref, &distribution.RemoteTagsConfig{
+		MetaHeaders:     metaHeaders,
+		AuthConfig:      authConfig,
+		RegistryService: daemon.RegistryService,
+		Digests:         withDigests,
+	})
+}
+
+// pullForCreate pulls the image of a container about to be created according
+// to policy. With PullAlways, the pull only downloads the image if the digest
+// the registry has for the reference differs from the local one.
+func (daemon *Daemon) pullForCreate(image, policy string, authConfig *types.AuthConfig) error {
+	switch policy {
+	case "", types.PullNever:
+		return nil
+	case types.PullMissing:
+		if _, err := daemon.GetImage(image); err == nil {
+			return nil
+		}
+	case types.PullAlways:
+	default:
+		return fmt.Errorf("invalid pull policy %q, must be %s, %s or %s", policy, types.PullAlways, types.PullMissing, types.PullNever)
+	}
+
+	_, ref, err := reference.ParseIDOrReference(image)
+	if err != nil {
+		return err
+	}
+	if ref == nil {
+		// an image ID cannot be pulled
+		return nil
+	}
+	if authConfig == nil {
+		authConfig = &types.AuthConfig{}
+	}
+	ref = reference.WithDefaultTag(ref)
+	if err := daemon.pullImageWithReference(context.Background(), ref, nil, authConfig, ioutil.Discard); err != nil {
+		return fmt.Errorf("Error pulling image %s: %v", ref.String(), err)
+	}
+	return nil
+}
+
 // PullOnBuild tells Docker to pull image referenced by `name`.
 func (daemon *Daemon) PullOnBuild(ctx context.Context, name string, authConfigs map[string]types.AuthConfig, output io.Writer) (builder.Image, error) {
 	ref, err := reference.ParseNamed(name)
@@ -97,9 +153,19 @@ func (daemon *Daemon) pullImageWithReference(ctx context.Context, ref reference.
 		ImageStore:       daemon.imageStore,
 		ReferenceStore:   daemon.referenceStore,
 		DownloadManager:  daemon.downloadManager,
+		LayerPeers:       daemon.layerPeers,
+		LayerCache:       daemon.layerCache,
+		ScanHook:         daemon.scanHook,
+		Staging:          daemon.downloadStaging,
+	}
+	if utils.ExperimentalBuild() && daemon.configStore.DeltaPulls {
+		imagePullConfig.DeltaLayerStore = daemon.layerStore
 	}
 
+	span, ctx := tracing.StartSpan(ctx, "image pull")
+	span.SetTag("image", ref.String())
 	err := distribution.Pull(ctx, ref, imagePullConfig)
+	span.Finish(err)
 	close(progressChan)
 	<-writesDone
 	return err
@@ -1,9 +1,11 @@
 package daemon
 
 import (
+	"fmt"
 	"io"
 
 	"github.com/docker/docker/distribution"
+	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/progress"
 	"github.com/docker/docker/reference"
 	"github.com/docker/engine-api/types"
@@ -49,10 +51,25 @@ func (daemon *Daemon) PushImage(ctx context.Context, image, tag string, metaHead
 		ReferenceStore:   daemon.referenceStore,
 		TrustKey:         daemon.trustKey,
 		UploadManager:    daemon.uploadManager,
+		ScanHook:         daemon.scanHook,
 	}
+	// The compression was validated with the configuration.
+	imagePushConfig.LayerCompression, _ = parsePushCompression(daemon.configStore.PushCompression)
 
 	err = distribution.Push(ctx, ref, imagePushConfig)
 	close(progressChan)
 	<-writesDone
 	return err
 }
+
+// parsePushCompression returns the compression of the layers pushed for the
+// value of --push-compression, gzip if it is empty.
+func parsePushCompression(compression string) (archive.Compression, error) {
+	switch compression {
+	case "", "gzip":
+		return archive.Gzip, nil
+	case "zstd":
+		return archive.Zstd, nil
+	}
+	return archive.Gzip, fmt.Errorf("invalid push compression %s: must be gzip or zstd", compression)
+}
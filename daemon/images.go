@@ -15,6 +15,7 @@ import (
 var acceptedImageFilterTags = map[string]bool{
 	"dangling": true,
 	"label":    true,
+	"owner":    true,
 }
 
 // byCreated is a temporary type used to sort a list of images by creation
@@ -75,7 +76,24 @@ func (daemon *Daemon) Images(filterArgs, filter string, all bool) ([]*types.Imag
 		}
 	}
 
+	// Layers are counted over all the images, including those not listed,
+	// to tell the layers shared with other images.
+	allLayers := daemon.layerStore.Map()
+	layerRefs := make(map[layer.ChainID]int)
+	for _, img := range daemon.imageStore.Map() {
+		for _, chainID := range imageLayers(img) {
+			layerRefs[chainID]++
+		}
+	}
+	imageContainers := make(map[image.ID]int)
+	for _, c := range daemon.List() {
+		imageContainers[c.ImageID]++
+	}
+
 	for id, img := range allImages {
+		if imageFilters.Include("owner") && !imageOwnedBy(imageFilters, daemon.imageOwners.get(id)) {
+			continue
+		}
 		if imageFilters.Include("label") {
 			// Very old image that do not have image.Config (or even labels)
 			if img.Config == nil {
@@ -103,6 +121,12 @@ func (daemon *Daemon) Images(filterArgs, filter string, all bool) ([]*types.Imag
 		}
 
 		newImage := newImage(img, size)
+		newImage.Containers = imageContainers[id]
+		newImage.SharedSize, err = sharedSize(img, allLayers, layerRefs)
+		if err != nil {
+			return nil, err
+		}
+		newImage.UniqueSize = size - newImage.SharedSize
 
 		for _, ref := range daemon.referenceStore.References(id) {
 			if filter != "" { // filter by tag/repo name
@@ -160,3 +184,45 @@ func newImage(image *image.Image, size int64) *types.Image {
 	}
 	return newImage
 }
+
+// imageLayers returns the chain IDs of the layers of img, from the base
+// layer up.
+func imageLayers(img *image.Image) []layer.ChainID {
+	chainIDs := make([]layer.ChainID, len(img.RootFS.DiffIDs))
+	for i := range img.RootFS.DiffIDs {
+		chainIDs[i] = layer.CreateChainID(img.RootFS.DiffIDs[:i+1])
+	}
+	return chainIDs
+}
+
+// sharedSize returns the size of the layers of img used by other images,
+// given the number of images using each layer.
+func sharedSize(img *image.Image, allLayers map[layer.ChainID]layer.Layer, layerRefs map[layer.ChainID]int) (int64, error) {
+	var size int64
+	for _, chainID := range imageLayers(img) {
+		if layerRefs[chainID] < 2 {
+			continue
+		}
+		l, ok := allLayers[chainID]
+		if !ok {
+			return 0, fmt.Errorf("layer %s of image %s was not found", chainID, img.ID())
+		}
+		diffSize, err := l.DiffSize()
+		if err != nil {
+			return 0, err
+		}
+		size += diffSize
+	}
+	return size, nil
+}
+
+// imageOwnedBy returns whether one of owners matches the owner filter of
+// imageFilters.
+func imageOwnedBy(imageFilters filters.Args, owners []string) bool {
+	for _, owner := range owners {
+		if imageFilters.ExactMatch("owner", owner) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/layer"
+)
+
+type sizedLayer struct {
+	layer.Layer
+	size int64
+}
+
+func (l sizedLayer) DiffSize() (int64, error) {
+	return l.size, nil
+}
+
+func TestSharedSize(t *testing.T) {
+	base := &image.Image{RootFS: &image.RootFS{Type: "layers", DiffIDs: []layer.DiffID{"sha256:a"}}}
+	child := &image.Image{RootFS: &image.RootFS{Type: "layers", DiffIDs: []layer.DiffID{"sha256:a", "sha256:b"}}}
+	other := &image.Image{RootFS: &image.RootFS{Type: "layers", DiffIDs: []layer.DiffID{"sha256:c", "sha256:b"}}}
+
+	allLayers := make(map[layer.ChainID]layer.Layer)
+	layerRefs := make(map[layer.ChainID]int)
+	sizes := map[layer.DiffID]int64{"sha256:a": 100, "sha256:b": 10, "sha256:c": 1}
+	for _, img := range []*image.Image{base, child, other} {
+		for i, chainID := range imageLayers(img) {
+			allLayers[chainID] = sizedLayer{size: sizes[img.RootFS.DiffIDs[i]]}
+			layerRefs[chainID]++
+		}
+	}
+
+	for _, tc := range []struct {
+		img      *image.Image
+		expected int64
+	}{
+		{base, 100},
+		{child, 100},
+		// The layer b of other has another parent than the layer b of
+		// child, so they are different layers.
+		{other, 0},
+	} {
+		size, err := sharedSize(tc.img, allLayers, layerRefs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if size != tc.expected {
+			t.Fatalf("expected a shared size of %d for %v, got %d", tc.expected, tc.img.RootFS.DiffIDs, size)
+		}
+	}
+
+	delete(allLayers, imageLayers(base)[0])
+	if _, err := sharedSize(base, allLayers, layerRefs); err == nil {
+		t.Fatal("expected an error for a missing layer")
+	}
+}
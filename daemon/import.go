@@ -3,10 +3,12 @@ package daemon
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"runtime"
+	"strings"
 	"time"
 
 	"github.com/docker/docker/builder/dockerfile"
@@ -24,8 +26,10 @@ import (
 // ImportImage imports an image, getting the archived layer data either from
 // inConfig (if src is "-"), or from a URI specified in src. Progress output is
 // written to outStream. Repository and tag names can optionally be given in
-// the repo and tag arguments, respectively.
-func (daemon *Daemon) ImportImage(src string, repository, tag string, msg string, inConfig io.ReadCloser, outStream io.Writer, changes []string) error {
+// the repo and tag arguments, respectively. platform is the "os[/arch]" the
+// image is for, and defaults to the daemon's. The archive is streamed into
+// the layer store as it is received, without being stored first.
+func (daemon *Daemon) ImportImage(src string, repository, tag string, msg string, platform string, inConfig io.ReadCloser, outStream io.Writer, changes []string) error {
 	var (
 		sf     = streamformatter.NewJSONStreamFormatter()
 		rc     io.ReadCloser
@@ -33,6 +37,11 @@ func (daemon *Daemon) ImportImage(src string, repository, tag string, msg string
 		newRef reference.Named
 	)
 
+	imageOS, arch, err := parseImportPlatform(platform)
+	if err != nil {
+		return err
+	}
+
 	if repository != "" {
 		var err error
 		newRef, err = reference.ParseNamed(repository)
@@ -99,8 +108,8 @@ func (daemon *Daemon) ImportImage(src string, repository, tag string, msg string
 		V1Image: image.V1Image{
 			DockerVersion: dockerversion.Version,
 			Config:        config,
-			Architecture:  runtime.GOARCH,
-			OS:            runtime.GOOS,
+			Architecture:  arch,
+			OS:            imageOS,
 			Created:       created,
 			Comment:       msg,
 		},
@@ -133,3 +142,24 @@ func (daemon *Daemon) ImportImage(src string, repository, tag string, msg string
 	outStream.Write(sf.FormatStatus("", id.String()))
 	return nil
 }
+
+// parseImportPlatform returns the operating system and architecture of an
+// "os[/arch]" platform. Images can be imported for another architecture, so
+// that base images can be built for it, but not for another operating
+// system, as the daemon could not store its layers.
+func parseImportPlatform(platform string) (string, string, error) {
+	if platform == "" {
+		return runtime.GOOS, runtime.GOARCH, nil
+	}
+	parts := strings.Split(strings.ToLower(platform), "/")
+	if len(parts) > 2 || parts[0] == "" || (len(parts) == 2 && parts[1] == "") {
+		return "", "", fmt.Errorf("invalid platform %q: must be os[/arch]", platform)
+	}
+	if parts[0] != runtime.GOOS {
+		return "", "", fmt.Errorf("cannot import an image for %s on a %s daemon", parts[0], runtime.GOOS)
+	}
+	if len(parts) == 1 {
+		return parts[0], runtime.GOARCH, nil
+	}
+	return parts[0], parts[1], nil
+}
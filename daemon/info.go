@@ -8,6 +8,7 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/container"
+	"github.com/docker/docker/distribution/xfer"
 	"github.com/docker/docker/dockerversion"
 	"github.com/docker/docker/pkg/fileutils"
 	"github.com/docker/docker/pkg/parsers/kernel"
@@ -54,6 +55,7 @@ func (daemon *Daemon) SystemInfo() (*types.Info, error) {
 	}
 
 	sysInfo := sysinfo.New(true)
+	reserved := daemon.reservedResources(nil)
 
 	var cRunning, cPaused, cStopped int32
 	daemon.containers.ApplyAll(func(c *container.Container) {
@@ -106,6 +108,8 @@ func (daemon *Daemon) SystemInfo() (*types.Info, error) {
 		RegistryConfig:     daemon.RegistryService.ServiceConfig(),
 		NCPU:               runtime.NumCPU(),
 		MemTotal:           meminfo.MemTotal,
+		ReservedCPUs:       reserved.cpus,
+		ReservedMemory:     reserved.memory,
 		DockerRootDir:      daemon.configStore.Root,
 		Labels:             daemon.configStore.Labels,
 		ExperimentalBuild:  utils.ExperimentalBuild(),
@@ -116,6 +120,8 @@ func (daemon *Daemon) SystemInfo() (*types.Info, error) {
 		HTTPSProxy:         sockets.GetProxyEnv("https_proxy"),
 		NoProxy:            sockets.GetProxyEnv("no_proxy"),
 		SecurityOptions:    securityOptions,
+		DownloadThrottle:   transferThrottle(daemon.downloadManager.ThrottleState()),
+		UploadThrottle:     transferThrottle(daemon.uploadManager.ThrottleState()),
 	}
 
 	// TODO Windows. Refactor this more once sysinfo is refactored into
@@ -131,6 +137,10 @@ func (daemon *Daemon) SystemInfo() (*types.Info, error) {
 		v.CPUCfsQuota = sysInfo.CPUCfsQuota
 		v.CPUShares = sysInfo.CPUShares
 		v.CPUSet = sysInfo.Cpuset
+		v.CgroupVersion = "1"
+		if sysInfo.CgroupUnified {
+			v.CgroupVersion = "2"
+		}
 	}
 
 	hostname := ""
@@ -181,3 +191,12 @@ func (daemon *Daemon) showPluginsInfo() types.PluginsInfo {
 
 	return pluginsInfo
 }
+
+// transferThrottle converts the state of a transfer throttle for the API.
+func transferThrottle(state xfer.ThrottleState) types.TransferThrottle {
+	return types.TransferThrottle{
+		Rate:            state.Rate,
+		PerLayerRate:    state.PerTransferRate,
+		ActiveTransfers: state.ActiveTransfers,
+	}
+}
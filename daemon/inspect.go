@@ -55,6 +55,7 @@ func (daemon *Daemon) containerInspectCurrent(name string, size bool) (*types.Co
 		},
 		DefaultNetworkSettings: daemon.getDefaultNetworkSettings(container.NetworkSettings.Networks),
 		Networks:               container.NetworkSettings.Networks,
+		Traffic:                daemon.networkTraffic(container),
 	}
 
 	return &types.ContainerJSON{
@@ -120,6 +121,7 @@ func (daemon *Daemon) getInspectData(container *container.Container, size bool)
 		Error:      container.State.Error,
 		StartedAt:  container.State.StartedAt.Format(time.RFC3339Nano),
 		FinishedAt: container.State.FinishedAt.Format(time.RFC3339Nano),
+		JobStatus:  jobStatus(container),
 	}
 
 	contJSONBase := &types.ContainerJSONBase{
@@ -138,6 +140,21 @@ func (daemon *Daemon) getInspectData(container *container.Container, size bool)
 		ExecIDs:      container.GetExecIDs(),
 		HostConfig:   &hostConfig,
 	}
+	if container.CPUPlacement != nil {
+		contJSONBase.CPUPlacement = &types.CPUPlacement{
+			CPUs:     container.CPUPlacement.CPUs,
+			NUMANode: container.CPUPlacement.NUMANode,
+		}
+	}
+	if container.StartTimings != nil {
+		contJSONBase.StartTimings = &types.StartTimings{
+			ImageMount:    container.StartTimings.ImageMount,
+			NetworkSetup:  container.StartTimings.NetworkSetup,
+			RuntimeCreate: container.StartTimings.RuntimeCreate,
+			ProcessStart:  container.StartTimings.ProcessStart,
+			Total:         container.StartTimings.Total,
+		}
+	}
 
 	var (
 		sizeRw     int64
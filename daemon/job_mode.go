@@ -0,0 +1,55 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/errors"
+	"github.com/docker/engine-api/types"
+	containertypes "github.com/docker/engine-api/types/container"
+)
+
+// verifyJobMode checks that the restart policy of a container in job mode
+// lets it complete: a job is restarted on failure at most, never once it
+// exited successfully.
+func verifyJobMode(hostConfig *containertypes.HostConfig) error {
+	if hostConfig.JobMode && (hostConfig.RestartPolicy.IsAlways() || hostConfig.RestartPolicy.IsUnlessStopped()) {
+		return fmt.Errorf("Restart policy %q is not supported in job mode, only \"no\" and \"on-failure\" are", hostConfig.RestartPolicy.Name)
+	}
+	return nil
+}
+
+// jobStatus returns the completion status of a container in job mode, or
+// an empty string for the other containers.
+func jobStatus(c *container.Container) string {
+	if !c.HostConfig.JobMode {
+		return ""
+	}
+	if c.JobStatus == "" {
+		return types.JobStatusPending
+	}
+	return c.JobStatus
+}
+
+// setJobCompleted records the completion status of a container in job mode
+// that exited and that its restart policy doesn't restart, from its exit
+// code.
+func setJobCompleted(c *container.Container) {
+	if !c.HostConfig.JobMode {
+		return
+	}
+	if c.ExitCode == 0 {
+		c.JobStatus = types.JobStatusSucceeded
+	} else {
+		c.JobStatus = types.JobStatusFailed
+	}
+}
+
+// checkJobStartable returns an error if c is a container in job mode which
+// already succeeded, as those are never run again.
+func checkJobStartable(c *container.Container) error {
+	if c.HostConfig.JobMode && c.JobStatus == types.JobStatusSucceeded {
+		return errors.NewRequestConflictError(fmt.Errorf("Container %s is a job which already succeeded and cannot be started again, remove it first", c.ID))
+	}
+	return nil
+}
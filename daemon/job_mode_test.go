@@ -0,0 +1,71 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/docker/docker/container"
+	"github.com/docker/engine-api/types"
+	containertypes "github.com/docker/engine-api/types/container"
+)
+
+func TestVerifyJobMode(t *testing.T) {
+	for policy, valid := range map[string]bool{
+		"":               true,
+		"no":             true,
+		"on-failure":     true,
+		"always":         false,
+		"unless-stopped": false,
+	} {
+		hostConfig := &containertypes.HostConfig{
+			JobMode:       true,
+			RestartPolicy: containertypes.RestartPolicy{Name: policy},
+		}
+		if err := verifyJobMode(hostConfig); (err == nil) != valid {
+			t.Fatalf("Expected restart policy %q to be valid in job mode: %v, got %v", policy, valid, err)
+		}
+		hostConfig.JobMode = false
+		if err := verifyJobMode(hostConfig); err != nil {
+			t.Fatalf("Expected restart policy %q to be valid outside of job mode, got %v", policy, err)
+		}
+	}
+}
+
+func TestJobStatus(t *testing.T) {
+	c := &container.Container{
+		CommonContainer: container.CommonContainer{
+			ID:         "test",
+			State:      container.NewState(),
+			HostConfig: &containertypes.HostConfig{},
+		},
+	}
+	setJobCompleted(c)
+	if status := jobStatus(c); status != "" {
+		t.Fatalf("Expected no job status outside of job mode, got %q", status)
+	}
+
+	c.HostConfig.JobMode = true
+	if status := jobStatus(c); status != types.JobStatusPending {
+		t.Fatalf("Expected job status %q, got %q", types.JobStatusPending, status)
+	}
+
+	c.ExitCode = 1
+	setJobCompleted(c)
+	if status := jobStatus(c); status != types.JobStatusFailed {
+		t.Fatalf("Expected job status %q, got %q", types.JobStatusFailed, status)
+	}
+	if err := checkJobStartable(c); err != nil {
+		t.Fatalf("Expected a failed job to be startable, got %v", err)
+	}
+
+	c.ExitCode = 0
+	setJobCompleted(c)
+	if status := jobStatus(c); status != types.JobStatusSucceeded {
+		t.Fatalf("Expected job status %q, got %q", types.JobStatusSucceeded, status)
+	}
+	err := checkJobStartable(c)
+	if e, ok := err.(interface {
+		HTTPErrorStatusCode() int
+	}); !ok || e.HTTPErrorStatusCode() != 409 {
+		t.Fatalf("Expected a conflict starting a succeeded job, got %v", err)
+	}
+}
@@ -0,0 +1,481 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/errors"
+	"github.com/docker/docker/pkg/cron"
+	"github.com/docker/docker/pkg/ioutils"
+	"github.com/docker/docker/pkg/stringid"
+	"github.com/docker/docker/utils"
+	"github.com/docker/engine-api/types"
+	"golang.org/x/net/context"
+)
+
+// defaultJobHistoryLimit is the number of finished runs of a job whose
+// containers are kept by default.
+const defaultJobHistoryLimit = 10
+
+// Labels of the containers run by the jobs.
+const (
+	jobIDLabel   = "com.docker.job.id"
+	jobNameLabel = "com.docker.job.name"
+)
+
+// scheduledJob is a job and the timer of its next run.
+type scheduledJob struct {
+	types.Job
+	schedule *cron.Schedule
+	timer    *time.Timer
+}
+
+// jobStore holds the jobs, stored as JSON in a file.
+type jobStore struct {
+	mu      sync.Mutex
+	path    string
+	jobs    map[string]*scheduledJob
+	stopped bool
+}
+
+// newJobStore returns the jobs stored at path.
+func newJobStore(path string) (*jobStore, error) {
+	s := &jobStore{
+		path: path,
+		jobs: make(map[string]*scheduledJob),
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, err
+	}
+	var jobs []types.Job
+	if err := json.Unmarshal(b, &jobs); err != nil {
+		return nil, err
+	}
+	for _, j := range jobs {
+		schedule, err := cron.Parse(j.Spec.Schedule)
+		if err != nil {
+			logrus.Errorf("Ignoring job %s: %v", j.Name, err)
+			continue
+		}
+		s.jobs[j.ID] = &scheduledJob{Job: j, schedule: schedule}
+	}
+	return s, nil
+}
+
+// get returns the job by its ID, its name, or a unique prefix of its ID.
+// s must be locked.
+func (s *jobStore) get(name string) (*scheduledJob, error) {
+	if j, ok := s.jobs[name]; ok {
+		return j, nil
+	}
+	var found *scheduledJob
+	for _, j := range s.jobs {
+		if j.Name == name {
+			return j, nil
+		}
+		if strings.HasPrefix(j.ID, name) {
+			if found != nil {
+				return nil, fmt.Errorf("Multiple IDs found with provided prefix: %s", name)
+			}
+			found = j
+		}
+	}
+	if found == nil || name == "" {
+		return nil, errors.NewRequestNotFoundError(fmt.Errorf("No such job: %s", name))
+	}
+	return found, nil
+}
+
+// save writes the jobs to disk. s must be locked.
+func (s *jobStore) save() {
+	jobs := make([]types.Job, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		jobs = append(jobs, j.Job)
+	}
+	b, err := json.Marshal(jobs)
+	if err == nil {
+		err = ioutils.AtomicWriteFile(s.path, b, 0600)
+	}
+	if err != nil {
+		logrus.Errorf("Failed to save the jobs: %v", err)
+	}
+}
+
+// stop stops the timers of the jobs, before the shutdown of the daemon.
+func (s *jobStore) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopped = true
+	for _, j := range s.jobs {
+		if j.timer != nil {
+			j.timer.Stop()
+		}
+	}
+}
+
+// initJobs loads the jobs stored at path, records the runs which finished
+// while the daemon was down, and schedules the next runs.
+func (daemon *Daemon) initJobs(path string) error {
+	s, err := newJobStore(path)
+	if err != nil {
+		return err
+	}
+	daemon.jobs = s
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, j := range s.jobs {
+		for _, run := range j.Runs {
+			if run.Running {
+				daemon.watchJobRun(j.ID, run.ContainerID)
+			}
+		}
+		daemon.scheduleJob(j)
+	}
+	s.save()
+	return nil
+}
+
+// scheduleJob sets the timer of the next run of j. The store must be locked.
+func (daemon *Daemon) scheduleJob(j *scheduledJob) {
+	now := time.Now()
+	j.NextRun = j.schedule.Next(now)
+	if j.NextRun.IsZero() {
+		return
+	}
+	id, due := j.ID, j.NextRun
+	j.timer = time.AfterFunc(due.Sub(now), func() {
+		daemon.runJob(id, due)
+	})
+}
+
+// runJob runs the job id due at scheduled, according to its overlap
+// policy, and schedules its next run.
+func (daemon *Daemon) runJob(id string, scheduled time.Time) {
+	s := daemon.jobs
+	s.mu.Lock()
+	j, ok := s.jobs[id]
+	if !ok || s.stopped {
+		s.mu.Unlock()
+		return
+	}
+	daemon.scheduleJob(j)
+	var running []string
+	for _, run := range j.Runs {
+		if run.Running {
+			running = append(running, run.ContainerID)
+		}
+	}
+	name := j.Name
+	var spec types.JobSpec
+	err := deepCopy(&spec, j.Spec)
+	s.save()
+	s.mu.Unlock()
+
+	if err != nil {
+		logrus.Errorf("Failed to run job %s: %v", name, err)
+		return
+	}
+	if len(running) > 0 {
+		switch spec.OverlapPolicy {
+		case types.JobOverlapAllow:
+		case types.JobOverlapReplace:
+			for _, containerID := range running {
+				if err := daemon.ContainerStop(containerID, 10, nil); err != nil {
+					logrus.Errorf("Failed to stop the previous run %s of job %s: %v", containerID, name, err)
+				}
+			}
+		default:
+			daemon.LogJobEvent(id, "skip", map[string]string{
+				"name":      name,
+				"container": running[len(running)-1],
+			})
+			return
+		}
+	}
+
+	run := types.JobRun{Scheduled: scheduled.UTC(), Started: time.Now().UTC()}
+	if spec.Config.Labels == nil {
+		spec.Config.Labels = make(map[string]string)
+	}
+	spec.Config.Labels[jobIDLabel] = id
+	spec.Config.Labels[jobNameLabel] = name
+	resp, err := daemon.ContainerCreate(types.ContainerCreateConfig{
+		Name:             fmt.Sprintf("%s-%d", name, scheduled.Unix()),
+		Config:           spec.Config,
+		HostConfig:       spec.HostConfig,
+		NetworkingConfig: spec.NetworkingConfig,
+		PullPolicy:       types.PullMissing,
+	})
+	if err == nil {
+		run.ContainerID = resp.ID
+		_, err = daemon.ContainerStart(context.Background(), resp.ID, nil)
+	}
+	if err != nil {
+		run.Finished = time.Now().UTC()
+		run.ExitCode = -1
+		run.Error = err.Error()
+		logrus.Errorf("Failed to run job %s: %v", name, err)
+	} else {
+		run.Running = true
+	}
+
+	s.mu.Lock()
+	if j, ok := s.jobs[id]; ok {
+		j.Runs = append(j.Runs, run)
+		if run.Running {
+			daemon.watchJobRun(id, run.ContainerID)
+		}
+		s.save()
+	}
+	s.mu.Unlock()
+
+	attributes := map[string]string{"name": name}
+	if run.ContainerID != "" {
+		attributes["container"] = run.ContainerID
+	}
+	if run.Error != "" {
+		attributes["error"] = run.Error
+		daemon.LogJobEvent(id, "fail", attributes)
+		return
+	}
+	daemon.LogJobEvent(id, "run", attributes)
+}
+
+// watchJobRun records the end of the run of the job id in containerID once
+// the container stops.
+func (daemon *Daemon) watchJobRun(id, containerID string) {
+	go func() {
+		exitCode := -1
+		if c, err := daemon.GetContainer(containerID); err == nil {
+			exitCode, _ = c.WaitStop(-1 * time.Second)
+		}
+		daemon.finishJobRun(id, containerID, exitCode)
+	}()
+}
+
+// finishJobRun records the end of the run of the job id in containerID, and
+// removes the containers of the finished runs beyond its history limit.
+func (daemon *Daemon) finishJobRun(id, containerID string, exitCode int) {
+	s := daemon.jobs
+	s.mu.Lock()
+	j, ok := s.jobs[id]
+	if !ok {
+		s.mu.Unlock()
+		return
+	}
+	for i := range j.Runs {
+		if run := &j.Runs[i]; run.ContainerID == containerID && run.Running {
+			run.Running = false
+			run.Finished = time.Now().UTC()
+			run.ExitCode = exitCode
+		}
+	}
+	var removed []string
+	if !s.stopped {
+		removed = pruneJobRuns(j)
+	}
+	name := j.Name
+	s.save()
+	s.mu.Unlock()
+
+	daemon.LogJobEvent(id, "finish", map[string]string{
+		"name":      name,
+		"container": containerID,
+		"exitCode":  strconv.Itoa(exitCode),
+	})
+	daemon.removeJobContainers(removed)
+}
+
+// pruneJobRuns drops the oldest finished runs of j beyond its history limit,
+// and returns their containers. The store must be locked.
+func pruneJobRuns(j *scheduledJob) []string {
+	limit := j.Spec.HistoryLimit
+	if limit <= 0 {
+		limit = defaultJobHistoryLimit
+	}
+	finished := 0
+	for _, run := range j.Runs {
+		if !run.Running {
+			finished++
+		}
+	}
+
+	var removed []string
+	runs := j.Runs[:0]
+	for _, run := range j.Runs {
+		if !run.Running && finished > limit {
+			finished--
+			if run.ContainerID != "" {
+				removed = append(removed, run.ContainerID)
+			}
+			continue
+		}
+		runs = append(runs, run)
+	}
+	j.Runs = runs
+	return removed
+}
+
+func (daemon *Daemon) removeJobContainers(ids []string) {
+	for _, id := range ids {
+		if _, err := daemon.GetContainer(id); err != nil {
+			// already removed
+			continue
+		}
+		if err := daemon.ContainerRm(id, &types.ContainerRmConfig{ForceRemove: true, RemoveVolume: true}); err != nil {
+			logrus.Errorf("Failed to remove the container %s of a job run: %v", id, err)
+		}
+	}
+}
+
+// JobCreate creates a job running a new container from spec on its
+// schedule. The settings of the container are validated as for a dry run
+// of ContainerCreate.
+func (daemon *Daemon) JobCreate(name string, spec types.JobSpec) (*types.Job, error) {
+	schedule, err := cron.Parse(spec.Schedule)
+	if err != nil {
+		return nil, errors.NewBadRequestError(err)
+	}
+	switch spec.OverlapPolicy {
+	case "":
+		spec.OverlapPolicy = types.JobOverlapSkip
+	case types.JobOverlapSkip, types.JobOverlapAllow, types.JobOverlapReplace:
+	default:
+		return nil, errors.NewBadRequestError(fmt.Errorf("invalid overlap policy %q, expected %s, %s or %s", spec.OverlapPolicy, types.JobOverlapSkip, types.JobOverlapAllow, types.JobOverlapReplace))
+	}
+	if spec.HistoryLimit < 0 {
+		return nil, errors.NewBadRequestError(fmt.Errorf("invalid history limit %d", spec.HistoryLimit))
+	}
+	if spec.Config == nil || spec.Config.Image == "" {
+		return nil, errors.NewBadRequestError(fmt.Errorf("a job requires the image of its containers"))
+	}
+
+	id := stringid.GenerateNonCryptoID()
+	if name == "" {
+		name = stringid.TruncateID(id)
+	}
+	if !utils.RestrictedNamePattern.MatchString(name) || strings.HasPrefix(name, "/") {
+		return nil, errors.NewBadRequestError(fmt.Errorf("Invalid job name (%s), only %s are allowed", name, utils.RestrictedNameChars))
+	}
+
+	// The container of a run is named after the job and its schedule.
+	params := types.ContainerCreateConfig{
+		Name:   fmt.Sprintf("%s-%d", name, time.Now().Unix()),
+		DryRun: true,
+	}
+	var copied types.JobSpec
+	if err := deepCopy(&copied, spec); err != nil {
+		return nil, err
+	}
+	params.Config, params.HostConfig, params.NetworkingConfig = copied.Config, copied.HostConfig, copied.NetworkingConfig
+	if _, err := daemon.ContainerCreate(params); err != nil {
+		return nil, err
+	}
+
+	s := daemon.jobs
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, j := range s.jobs {
+		if j.Name == name {
+			return nil, errors.NewRequestConflictError(fmt.Errorf("Conflict. The job name %q is already in use by job %s. You have to remove that job to be able to reuse that name.", name, j.ID))
+		}
+	}
+	j := &scheduledJob{
+		Job: types.Job{
+			ID:      id,
+			Name:    name,
+			Created: time.Now().UTC(),
+		},
+		schedule: schedule,
+	}
+	if err := deepCopy(&j.Spec, spec); err != nil {
+		return nil, err
+	}
+	s.jobs[id] = j
+	daemon.scheduleJob(j)
+	s.save()
+
+	daemon.LogJobEvent(id, "create", map[string]string{
+		"name":     name,
+		"schedule": spec.Schedule,
+	})
+	job := j.Job
+	return &job, nil
+}
+
+// Jobs returns the jobs, sorted by name.
+func (daemon *Daemon) Jobs() []*types.Job {
+	s := daemon.jobs
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]*types.Job, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		job := j.Job
+		job.Runs = append([]types.JobRun(nil), j.Runs...)
+		jobs = append(jobs, &job)
+	}
+	sort.Sort(jobsByName(jobs))
+	return jobs
+}
+
+type jobsByName []*types.Job
+
+func (r jobsByName) Len() int           { return len(r) }
+func (r jobsByName) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+func (r jobsByName) Less(i, j int) bool { return r[i].Name < r[j].Name }
+
+// JobInspect returns the job by its ID, its name, or a unique prefix of its
+// ID.
+func (daemon *Daemon) JobInspect(name string) (*types.Job, error) {
+	s := daemon.jobs
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, err := s.get(name)
+	if err != nil {
+		return nil, err
+	}
+	job := j.Job
+	job.Runs = append([]types.JobRun(nil), j.Runs...)
+	return &job, nil
+}
+
+// JobRm removes a job, and the containers of its finished runs. The
+// containers of its running runs are left running.
+func (daemon *Daemon) JobRm(name string) error {
+	s := daemon.jobs
+	s.mu.Lock()
+	j, err := s.get(name)
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	if j.timer != nil {
+		j.timer.Stop()
+	}
+	delete(s.jobs, j.ID)
+	s.save()
+	s.mu.Unlock()
+
+	var removed []string
+	for _, run := range j.Runs {
+		if !run.Running && run.ContainerID != "" {
+			removed = append(removed, run.ContainerID)
+		}
+	}
+	daemon.removeJobContainers(removed)
+	daemon.LogJobEvent(j.ID, "destroy", map[string]string{"name": j.Name})
+	return nil
+}
@@ -0,0 +1,97 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/pkg/cron"
+	"github.com/docker/engine-api/types"
+	containertypes "github.com/docker/engine-api/types/container"
+)
+
+func TestJobStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "jobs.json")
+
+	s, err := newJobStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schedule, err := cron.Parse("@daily")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, j := range []types.Job{
+		{ID: "4b1d0e2c", Name: "backup", Spec: types.JobSpec{Schedule: "@daily", Config: &containertypes.Config{Image: "busybox"}}},
+		{ID: "4b7f9a31", Name: "reports", Spec: types.JobSpec{Schedule: "*/15 * * * *", Config: &containertypes.Config{Image: "busybox"}}},
+	} {
+		s.jobs[j.ID] = &scheduledJob{Job: j, schedule: schedule}
+	}
+	s.save()
+
+	// The jobs are kept across restarts.
+	s, err = newJobStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, expected := range map[string]string{
+		"4b1d0e2c": "backup",
+		"backup":   "backup",
+		"4b7":      "reports",
+		"reports":  "reports",
+	} {
+		j, err := s.get(name)
+		if err != nil {
+			t.Fatalf("Failed to get job %s: %v", name, err)
+		}
+		if j.Name != expected || j.schedule == nil {
+			t.Fatalf("Expected %s to be job %s, got %+v", name, expected, j)
+		}
+	}
+	for _, name := range []string{"", "4b", "nightly"} {
+		if _, err := s.get(name); err == nil {
+			t.Fatalf("Expected getting job %q to fail", name)
+		}
+	}
+}
+
+func TestPruneJobRuns(t *testing.T) {
+	j := &scheduledJob{Job: types.Job{
+		Spec: types.JobSpec{HistoryLimit: 2},
+		Runs: []types.JobRun{
+			{ContainerID: "a"},
+			{ContainerID: "b", Running: true},
+			{Error: "No such image: busybox"},
+			{ContainerID: "d"},
+			{ContainerID: "e"},
+		},
+	}}
+	removed := pruneJobRuns(j)
+	if !reflect.DeepEqual(removed, []string{"a"}) {
+		t.Fatalf("Expected the container of the oldest run to be removed, got %v", removed)
+	}
+	var kept []string
+	for _, run := range j.Runs {
+		kept = append(kept, run.ContainerID)
+	}
+	// The running runs are kept, regardless of the limit.
+	if !reflect.DeepEqual(kept, []string{"b", "d", "e"}) {
+		t.Fatalf("Expected the running run and the last 2 finished runs to be kept, got %v", kept)
+	}
+
+	// The history limit defaults to 10 runs.
+	j.Spec.HistoryLimit = 0
+	for i := 0; i < 12; i++ {
+		j.Runs = append(j.Runs, types.JobRun{ContainerID: "f"})
+	}
+	if removed := pruneJobRuns(j); len(removed) != 4 || len(j.Runs) != 11 {
+		t.Fatalf("Expected 4 runs to be removed and 11 to be kept, got %v and %+v", removed, j.Runs)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/container"
 	"github.com/docker/docker/pkg/signal"
+	"golang.org/x/net/context"
 )
 
 type errNoSuchProcess struct {
@@ -46,52 +47,49 @@ func (daemon *Daemon) ContainerKill(name string, sig uint64) error {
 	if sig == 0 || syscall.Signal(sig) == syscall.SIGKILL {
 		return daemon.Kill(container)
 	}
-	return daemon.killWithSignal(container, int(sig))
+	return daemon.killWithSignal(context.Background(), container, int(sig))
 }
 
 // killWithSignal sends the container the given signal. This wrapper for the
 // host specific kill command prepares the container before attempting
 // to send the signal. An error is returned if the container is paused
 // or not running, or if there is a problem returned from the
-// underlying kill command.
-func (daemon *Daemon) killWithSignal(container *container.Container, sig int) error {
+// underlying kill command. No kill event is logged once ctx is done, as
+// its caller gave up waiting on it.
+func (daemon *Daemon) killWithSignal(ctx context.Context, container *container.Container, sig int) error {
 	logrus.Debugf("Sending %d to %s", sig, container.ID)
-	container.Lock()
-	defer container.Unlock()
-
-	// We could unpause the container for them rather than returning this error
-	if container.Paused {
-		return fmt.Errorf("Container %s is paused. Unpause the container before stopping", container.ID)
-	}
+	container.BeginOperation()
+	defer container.EndOperation()
+	defer daemon.watchOperation(container, "kill")()
 
-	if !container.Running {
-		return errNotRunning{container.ID}
-	}
-
-	container.ExitOnNext()
-
-	if !daemon.IsShuttingDown() {
-		container.HasBeenManuallyStopped = true
+	container.Lock()
+	restarting, err := daemon.prepareKill(container)
+	container.Unlock()
+	if err != nil {
+		return err
 	}
 
 	// if the container is currently restarting we do not need to send the signal
 	// to the process.  Telling the monitor that it should exit on it's next event
 	// loop is enough
-	if container.Restarting {
+	if restarting {
 		return nil
 	}
 
-	if err := daemon.kill(container, sig); err != nil {
-		err = fmt.Errorf("Cannot kill container %s: %s", container.ID, err)
+	if cause := daemon.kill(ctx, container, sig); cause != nil {
+		err := fmt.Errorf("Cannot kill container %s: %s", container.ID, cause)
 		// if container or process not exists, ignore the error
 		if strings.Contains(err.Error(), "container not found") ||
 			strings.Contains(err.Error(), "no such process") {
 			logrus.Warnf("%s", err.Error())
 		} else {
-			return err
+			return runtimeError(err, cause)
 		}
 	}
 
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	attributes := map[string]string{
 		"signal": fmt.Sprintf("%d", sig),
 	}
@@ -99,14 +97,40 @@ func (daemon *Daemon) killWithSignal(container *container.Container, sig int) er
 	return nil
 }
 
+// prepareKill checks that the container can be signaled and tells the
+// restart manager not to restart it. It must be called with the container
+// locked.
+func (daemon *Daemon) prepareKill(container *container.Container) (restarting bool, err error) {
+	// We could unpause the container for them rather than returning this error
+	if container.Paused {
+		return false, fmt.Errorf("Container %s is paused. Unpause the container before stopping", container.ID)
+	}
+
+	if !container.Running {
+		return false, errNotRunning{container.ID}
+	}
+
+	container.ExitOnNext()
+
+	if !daemon.IsShuttingDown() {
+		container.HasBeenManuallyStopped = true
+	}
+	return container.Restarting, nil
+}
+
 // Kill forcefully terminates a container.
 func (daemon *Daemon) Kill(container *container.Container) error {
+	return daemon.killContext(context.Background(), container)
+}
+
+// killContext forcefully terminates a container, until ctx is done.
+func (daemon *Daemon) killContext(ctx context.Context, container *container.Container) error {
 	if !container.IsRunning() {
 		return errNotRunning{container.ID}
 	}
 
 	// 1. Send SIGKILL
-	if err := daemon.killPossiblyDeadProcess(container, int(syscall.SIGKILL)); err != nil {
+	if err := daemon.killPossiblyDeadProcess(ctx, container, int(syscall.SIGKILL)); err != nil {
 		// While normally we might "return err" here we're not going to
 		// because if we can't stop the container by this point then
 		// its probably because its already stopped. Meaning, between
@@ -120,6 +144,9 @@ func (daemon *Daemon) Kill(container *container.Container) error {
 		if isErrNoSuchProcess(err) {
 			return nil
 		}
+		if ctx.Err() != nil {
+			return err
+		}
 
 		if container.IsRunning() {
 			container.WaitStop(2 * time.Second)
@@ -142,8 +169,8 @@ func (daemon *Daemon) Kill(container *container.Container) error {
 }
 
 // killPossibleDeadProcess is a wrapper around killSig() suppressing "no such process" error.
-func (daemon *Daemon) killPossiblyDeadProcess(container *container.Container, sig int) error {
-	err := daemon.killWithSignal(container, sig)
+func (daemon *Daemon) killPossiblyDeadProcess(ctx context.Context, container *container.Container, sig int) error {
+	err := daemon.killWithSignal(ctx, container, sig)
 	if err == syscall.ESRCH {
 		e := errNoSuchProcess{container.GetPID(), sig}
 		logrus.Debug(e)
@@ -0,0 +1,67 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/events"
+	"github.com/docker/docker/libcontainerd"
+	containertypes "github.com/docker/engine-api/types/container"
+	"golang.org/x/net/context"
+)
+
+// unresponsiveRuntime is a runtime whose signals only return, successfully,
+// once the call is cancelled, such as a runtime ignoring the cancellations.
+type unresponsiveRuntime struct {
+	libcontainerd.Client
+	signaled chan struct{}
+}
+
+func (r *unresponsiveRuntime) Signal(ctx context.Context, containerID string, sig int) error {
+	close(r.signaled)
+	<-ctx.Done()
+	return nil
+}
+
+func TestKillWithSignalCancelled(t *testing.T) {
+	runtime := &unresponsiveRuntime{signaled: make(chan struct{})}
+	d := &Daemon{
+		configStore:   &Config{},
+		containerd:    runtime,
+		EventsService: events.New(),
+	}
+	c := &container.Container{
+		CommonContainer: container.CommonContainer{
+			ID:     "6e2d4b8a0c1f",
+			State:  container.NewState(),
+			Config: &containertypes.Config{},
+		},
+	}
+	c.SetRunning(1234, true)
+
+	_, l, cancelSubscription := d.EventsService.Subscribe()
+	defer cancelSubscription()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- d.killWithSignal(ctx, c, 9)
+	}()
+	<-runtime.signaled
+	cancel()
+
+	select {
+	case err := <-errCh:
+		if err != context.Canceled {
+			t.Fatalf("expected the kill to be cancelled, got %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("the kill was not cancelled")
+	}
+	select {
+	case e := <-l:
+		t.Fatalf("unexpected event %v", e)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
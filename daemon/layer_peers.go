@@ -0,0 +1,73 @@
+package daemon
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"path/filepath"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/distribution/peer"
+	"github.com/docker/go-units"
+)
+
+// defaultLayerPeerCacheSize is the default max size of the layer blobs kept
+// to serve to peers.
+const defaultLayerPeerCacheSize = "10GB"
+
+// initLayerPeers sets up the downloads from the layer peers of config, and
+// the listener serving the layer blobs pulled by the daemon to its peers.
+func (daemon *Daemon) initLayerPeers(config *Config) error {
+	if len(config.LayerPeers) > 0 {
+		daemon.layerPeers = peer.NewPeers(config.LayerPeers)
+	}
+	if config.LayerPeerListen == "" {
+		return nil
+	}
+
+	size, err := parseLayerPeerCacheSize(config.LayerPeerCacheSize)
+	if err != nil {
+		return err
+	}
+	cache, err := peer.NewCache(filepath.Join(config.Root, "layer-peer-cache"), size)
+	if err != nil {
+		return err
+	}
+	l, err := net.Listen("tcp", config.LayerPeerListen)
+	if err != nil {
+		return fmt.Errorf("Error listening for layer peers on %s: %v", config.LayerPeerListen, err)
+	}
+	daemon.layerCache = cache
+	daemon.layerPeerListener = l
+
+	logrus.Infof("Serving layers to peers on %s", l.Addr())
+	go func() {
+		if err := http.Serve(l, cache); err != nil && !daemon.shutdown {
+			logrus.Errorf("Error serving layers to peers: %v", err)
+		}
+	}()
+	return nil
+}
+
+// validateLayerPeer validates the URL of a layer peer.
+func validateLayerPeer(val string) (string, error) {
+	u, err := url.Parse(val)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid layer peer %s: must be an http:// or https:// URL", val)
+	}
+	return val, nil
+}
+
+// parseLayerPeerCacheSize returns the max size in bytes of the layer peer
+// cache, such as "10GB", or the default size if size is empty.
+func parseLayerPeerCacheSize(size string) (int64, error) {
+	if size == "" {
+		size = defaultLayerPeerCacheSize
+	}
+	n, err := units.FromHumanSize(size)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid layer peer cache size: %s", size)
+	}
+	return n, nil
+}
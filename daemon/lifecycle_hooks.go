@@ -0,0 +1,201 @@
+package daemon
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/container"
+)
+
+// The events of a container on which the lifecycle hooks run.
+const (
+	hookCreate  = "create"
+	hookStart   = "start"
+	hookDie     = "die"
+	hookDestroy = "destroy"
+)
+
+// The failure policies of the lifecycle hooks.
+const (
+	// hookFailureIgnore logs the failures of the hooks.
+	hookFailureIgnore = "ignore"
+	// hookFailureAbort fails the create or the start of the container on
+	// the failure of a create or start hook.
+	hookFailureAbort = "abort"
+)
+
+const defaultLifecycleHookTimeout = 30
+
+// lifecycleHook is an executable of the host run on an event of the
+// containers.
+type lifecycleHook struct {
+	event string
+	path  string
+}
+
+// hookState is the state of the container passed as JSON on the standard
+// input of the lifecycle hooks.
+type hookState struct {
+	Event    string
+	ID       string
+	Name     string
+	Image    string
+	Labels   map[string]string
+	Pid      int  `json:",omitempty"`
+	ExitCode *int `json:",omitempty"`
+}
+
+// parseLifecycleHook parses a lifecycle hook of the form "<event>=<path>".
+func parseLifecycleHook(val string) (lifecycleHook, error) {
+	parts := strings.SplitN(val, "=", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return lifecycleHook{}, fmt.Errorf("invalid lifecycle hook %s: must be of the form <event>=<path>", val)
+	}
+	switch parts[0] {
+	case hookCreate, hookStart, hookDie, hookDestroy:
+	default:
+		return lifecycleHook{}, fmt.Errorf("invalid lifecycle hook %s: the event must be one of %s, %s, %s or %s", val, hookCreate, hookStart, hookDie, hookDestroy)
+	}
+	if !filepath.IsAbs(parts[1]) {
+		return lifecycleHook{}, fmt.Errorf("invalid lifecycle hook %s: the path must be absolute", val)
+	}
+	return lifecycleHook{event: parts[0], path: parts[1]}, nil
+}
+
+// validateLifecycleHook validates a lifecycle hook of the form
+// "<event>=<path>".
+func validateLifecycleHook(val string) (string, error) {
+	if _, err := parseLifecycleHook(val); err != nil {
+		return "", err
+	}
+	return val, nil
+}
+
+// validateLifecycleHookFailure validates the failure policy of the
+// lifecycle hooks.
+func validateLifecycleHookFailure(policy string) error {
+	switch policy {
+	case "", hookFailureIgnore, hookFailureAbort:
+		return nil
+	}
+	return fmt.Errorf("invalid lifecycle hook failure policy %s: must be %s or %s", policy, hookFailureIgnore, hookFailureAbort)
+}
+
+// newHookState returns the state of c passed to the hooks of event. The
+// state is read when the hooks are triggered, the hooks of die and destroy
+// running in the background.
+func newHookState(c *container.Container, event string) hookState {
+	state := hookState{
+		Event:  event,
+		ID:     c.ID,
+		Name:   strings.TrimPrefix(c.Name, "/"),
+		Image:  c.ImageID.String(),
+		Labels: c.Config.Labels,
+	}
+	if c.Running {
+		state.Pid = c.Pid
+	}
+	if event == hookDie {
+		exitCode := c.ExitCode
+		state.ExitCode = &exitCode
+	}
+	return state
+}
+
+// runLifecycleHooks runs the hooks of event for c in the order they are
+// configured. For the create and start events, the first failure is
+// returned if the failure policy is abort; the other failures are logged.
+// The hooks of the die and destroy events run in the background, as these
+// events cannot be aborted.
+func (daemon *Daemon) runLifecycleHooks(c *container.Container, event string) error {
+	var hooks []lifecycleHook
+	for _, val := range daemon.configStore.LifecycleHooks {
+		// The hooks were validated with the configuration.
+		if hook, err := parseLifecycleHook(val); err == nil && hook.event == event {
+			hooks = append(hooks, hook)
+		}
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	state := newHookState(c, event)
+	timeout := time.Duration(daemon.configStore.LifecycleHookTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultLifecycleHookTimeout * time.Second
+	}
+	abort := daemon.configStore.LifecycleHookFailure == hookFailureAbort && (event == hookCreate || event == hookStart)
+
+	run := func() error {
+		for _, hook := range hooks {
+			if err := runLifecycleHook(hook, state, timeout); err != nil {
+				err = fmt.Errorf("%s hook %s failed for container %s: %v", event, hook.path, c.ID, err)
+				if abort {
+					return err
+				}
+				logrus.Warn(err)
+			}
+		}
+		return nil
+	}
+	if event == hookDie || event == hookDestroy {
+		go run()
+		return nil
+	}
+	return run()
+}
+
+// runLifecycleHook runs hook with state on its standard input and in its
+// environment, killing it after timeout.
+func runLifecycleHook(hook lifecycleHook, state hookState, timeout time.Duration) error {
+	input, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	var output bytes.Buffer
+	cmd := exec.Command(hook.path)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	cmd.Env = append(os.Environ(),
+		"DOCKER_HOOK_EVENT="+state.Event,
+		"DOCKER_CONTAINER_ID="+state.ID,
+		"DOCKER_CONTAINER_NAME="+state.Name,
+	)
+	if state.Pid != 0 {
+		cmd.Env = append(cmd.Env, "DOCKER_CONTAINER_PID="+strconv.Itoa(state.Pid))
+	}
+	if state.ExitCode != nil {
+		cmd.Env = append(cmd.Env, "DOCKER_CONTAINER_EXIT_CODE="+strconv.Itoa(*state.ExitCode))
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+	select {
+	case err = <-done:
+	case <-time.After(timeout):
+		cmd.Process.Kill()
+		<-done
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+	if err != nil {
+		if out := strings.TrimSpace(output.String()); out != "" {
+			return fmt.Errorf("%v: %s", err, out)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLifecycleHook(t *testing.T) {
+	hook, err := parseLifecycleHook("die=/usr/local/bin/notify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hook.event != hookDie || hook.path != "/usr/local/bin/notify" {
+		t.Fatalf("unexpected hook %+v", hook)
+	}
+	for _, invalid := range []string{"die", "die=", "pause=/bin/true", "start=true"} {
+		if _, err := parseLifecycleHook(invalid); err == nil {
+			t.Fatalf("expected %s to be an invalid hook", invalid)
+		}
+	}
+}
+
+func TestRunLifecycleHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test hooks are shell scripts")
+	}
+	dir, err := ioutil.TempDir("", "lifecycle-hooks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	out := filepath.Join(dir, "out")
+	script := filepath.Join(dir, "hook")
+	if err := ioutil.WriteFile(script, []byte("#!/bin/sh\necho $DOCKER_HOOK_EVENT $DOCKER_CONTAINER_EXIT_CODE > "+out+"\ncat >> "+out+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	exitCode := 3
+	state := hookState{Event: hookDie, ID: "id", Name: "name", ExitCode: &exitCode}
+	if err := runLifecycleHook(lifecycleHook{event: hookDie, path: script}, state, 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(b), "die 3\n") || !strings.Contains(string(b), `"ExitCode":3`) {
+		t.Fatalf("unexpected hook output %q", b)
+	}
+
+	failing := filepath.Join(dir, "failing")
+	if err := ioutil.WriteFile(failing, []byte("#!/bin/sh\necho no network\nexit 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := runLifecycleHook(lifecycleHook{event: hookStart, path: failing}, state, 10*time.Second); err == nil || !strings.Contains(err.Error(), "no network") {
+		t.Fatalf("expected the output of the failed hook in the error, got %v", err)
+	}
+
+	slow := filepath.Join(dir, "slow")
+	if err := ioutil.WriteFile(slow, []byte("#!/bin/sh\nexec sleep 10\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := runLifecycleHook(lifecycleHook{event: hookStart, path: slow}, state, 100*time.Millisecond); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected the hook to time out, got %v", err)
+	}
+}
@@ -20,6 +20,7 @@ var acceptedVolumeFilterTags = map[string]bool{
 	"dangling": true,
 	"name":     true,
 	"driver":   true,
+	"label":    true,
 }
 
 var acceptedPsFilterTags = map[string]bool{
@@ -488,6 +489,14 @@ func (daemon *Daemon) filterVolumes(vols []volume.Volume, filter filters.Args) (
 				continue
 			}
 		}
+		if filter.Include("label") {
+			v, ok := vol.(interface {
+				Labels() map[string]string
+			})
+			if !ok || !filter.MatchKVList("label", v.Labels()) {
+				continue
+			}
+		}
 		retVols = append(retVols, vol)
 	}
 	danglingOnly := false
@@ -5,8 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"regexp"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -19,7 +21,9 @@ import (
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
 	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/daemon/logger/loggerutils"
 	"github.com/docker/docker/dockerversion"
+	"github.com/docker/go-units"
 )
 
 const (
@@ -28,6 +32,11 @@ const (
 	regionEnvKey          = "AWS_REGION"
 	logGroupKey           = "awslogs-group"
 	logStreamKey          = "awslogs-stream"
+	logCreateGroupKey     = "awslogs-create-group"
+	datetimeFormatKey     = "awslogs-datetime-format"
+	multilinePatternKey   = "awslogs-multiline-pattern"
+	batchFrequencyKey     = "awslogs-batch-frequency"
+	batchSizeKey          = "awslogs-batch-size"
 	batchPublishFrequency = 5 * time.Second
 
 	// See: http://docs.aws.amazon.com/AmazonCloudWatchLogs/latest/APIReference/API_PutLogEvents.html
@@ -39,6 +48,7 @@ const (
 	maximumBytesPerEvent = 262144 - perEventBytes
 
 	resourceAlreadyExistsCode = "ResourceAlreadyExistsException"
+	resourceNotFoundCode      = "ResourceNotFoundException"
 	dataAlreadyAcceptedCode   = "DataAlreadyAcceptedException"
 	invalidSequenceTokenCode  = "InvalidSequenceTokenException"
 
@@ -46,16 +56,21 @@ const (
 )
 
 type logStream struct {
-	logStreamName string
-	logGroupName  string
-	client        api
-	messages      chan *logger.Message
-	lock          sync.RWMutex
-	closed        bool
-	sequenceToken *string
+	logStreamName    string
+	logGroupName     string
+	logCreateGroup   bool
+	multilinePattern *regexp.Regexp
+	batchFrequency   time.Duration
+	batchSize        int
+	client           api
+	messages         chan *logger.Message
+	lock             sync.RWMutex
+	closed           bool
+	sequenceToken    *string
 }
 
 type api interface {
+	CreateLogGroup(*cloudwatchlogs.CreateLogGroupInput) (*cloudwatchlogs.CreateLogGroupOutput, error)
 	CreateLogStream(*cloudwatchlogs.CreateLogStreamInput) (*cloudwatchlogs.CreateLogStreamOutput, error)
 	PutLogEvents(*cloudwatchlogs.PutLogEventsInput) (*cloudwatchlogs.PutLogEventsOutput, error)
 }
@@ -81,25 +96,53 @@ func init() {
 
 // New creates an awslogs logger using the configuration passed in on the
 // context.  Supported context configuration variables are awslogs-region,
-// awslogs-group, and awslogs-stream.  When available, configuration is
-// also taken from environment variables AWS_REGION, AWS_ACCESS_KEY_ID,
-// AWS_SECRET_ACCESS_KEY, the shared credentials file (~/.aws/credentials), and
-// the EC2 Instance Metadata Service.
+// awslogs-group, awslogs-stream, awslogs-create-group,
+// awslogs-datetime-format, awslogs-multiline-pattern,
+// awslogs-batch-frequency, awslogs-batch-size and tag.  When available,
+// configuration is also taken from environment variables AWS_REGION,
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, the shared credentials file
+// (~/.aws/credentials), and the EC2 Instance Metadata Service.
 func New(ctx logger.Context) (logger.Logger, error) {
 	logGroupName := ctx.Config[logGroupKey]
 	logStreamName := ctx.ContainerID
+	if ctx.Config[logger.TagKey] != "" {
+		tag, err := loggerutils.ParseLogTag(ctx, "")
+		if err != nil {
+			return nil, err
+		}
+		logStreamName = tag
+	}
 	if ctx.Config[logStreamKey] != "" {
 		logStreamName = ctx.Config[logStreamKey]
 	}
+	logCreateGroup := false
+	if ctx.Config[logCreateGroupKey] != "" {
+		var err error
+		if logCreateGroup, err = strconv.ParseBool(ctx.Config[logCreateGroupKey]); err != nil {
+			return nil, err
+		}
+	}
+	multilinePattern, err := parseMultilineOptions(ctx.Config)
+	if err != nil {
+		return nil, err
+	}
+	batchFrequency, batchSize, err := parseBatchOptions(ctx.Config)
+	if err != nil {
+		return nil, err
+	}
 	client, err := newAWSLogsClient(ctx)
 	if err != nil {
 		return nil, err
 	}
 	containerStream := &logStream{
-		logStreamName: logStreamName,
-		logGroupName:  logGroupName,
-		client:        client,
-		messages:      make(chan *logger.Message, 4096),
+		logStreamName:    logStreamName,
+		logGroupName:     logGroupName,
+		logCreateGroup:   logCreateGroup,
+		multilinePattern: multilinePattern,
+		batchFrequency:   batchFrequency,
+		batchSize:        batchSize,
+		client:           client,
+		messages:         make(chan *logger.Message, 4096),
 	}
 	err = containerStream.create()
 	if err != nil {
@@ -182,8 +225,52 @@ func (l *logStream) Close() error {
 	return nil
 }
 
-// create creates a log stream for the instance of the awslogs logging driver
+// create creates a log stream for the instance of the awslogs logging driver,
+// and the log group if it does not exist and awslogs-create-group is set.
 func (l *logStream) create() error {
+	err := l.createLogStream()
+	if l.logCreateGroup {
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == resourceNotFoundCode {
+			if err := l.createLogGroup(); err != nil {
+				return err
+			}
+			err = l.createLogStream()
+		}
+	}
+	return err
+}
+
+// createLogGroup creates the log group of the instance of the awslogs
+// logging driver. It succeeds if the group already exists.
+func (l *logStream) createLogGroup() error {
+	input := &cloudwatchlogs.CreateLogGroupInput{
+		LogGroupName: aws.String(l.logGroupName),
+	}
+
+	_, err := l.client.CreateLogGroup(input)
+
+	if err != nil {
+		if awsErr, ok := err.(awserr.Error); ok {
+			fields := logrus.Fields{
+				"errorCode":    awsErr.Code(),
+				"message":      awsErr.Message(),
+				"origError":    awsErr.OrigErr(),
+				"logGroupName": l.logGroupName,
+			}
+			if awsErr.Code() == resourceAlreadyExistsCode {
+				// Allow creation to succeed
+				logrus.WithFields(fields).Info("Log group already exists")
+				return nil
+			}
+			logrus.WithFields(fields).Error("Failed to create log group")
+		}
+	}
+	return err
+}
+
+// createLogStream creates the log stream of the instance of the awslogs
+// logging driver. It succeeds if the stream already exists.
+func (l *logStream) createLogStream() error {
 	input := &cloudwatchlogs.CreateLogStreamInput{
 		LogGroupName:  aws.String(l.logGroupName),
 		LogStreamName: aws.String(l.logStreamName),
@@ -220,51 +307,108 @@ var newTicker = func(freq time.Duration) *time.Ticker {
 // collectBatch executes as a goroutine to perform batching of log events for
 // submission to the log stream.  Batching is performed on time- and size-
 // bases.  Time-based batching occurs at a 5 second interval (defined in the
-// batchPublishFrequency const).  Size-based batching is performed on the
-// maximum number of events per batch (defined in maximumLogEventsPerPut) and
-// the maximum number of total bytes in a batch (defined in
-// maximumBytesPerPut).  Log messages are split by the maximum bytes per event
-// (defined in maximumBytesPerEvent).  There is a fixed per-event byte overhead
+// batchPublishFrequency const), unless awslogs-batch-frequency is set.
+// Size-based batching is performed on the maximum number of events per batch
+// (defined in maximumLogEventsPerPut) and the maximum number of total bytes in
+// a batch (defined in maximumBytesPerPut, or awslogs-batch-size if it is
+// smaller).  Log messages are split by the maximum bytes per event (defined
+// in maximumBytesPerEvent).  There is a fixed per-event byte overhead
 // (defined in perEventBytes) which is accounted for in split- and batch-
 // calculations.
+//
+// When a multiline pattern is configured, lines are joined into a single
+// event until a line matching the pattern starts the next one, or until
+// adding a line would make the event exceed maximumBytesPerEvent.  A pending
+// multiline event is submitted once no new line has been added to it for a
+// whole batch interval.
 func (l *logStream) collectBatch() {
-	timer := newTicker(batchPublishFrequency)
-	var events []*cloudwatchlogs.InputLogEvent
-	bytes := 0
+	frequency := l.batchFrequency
+	if frequency == 0 {
+		frequency = batchPublishFrequency
+	}
+	timer := newTicker(frequency)
+	b := &batch{maxBytes: l.batchSize}
+	if b.maxBytes == 0 || b.maxBytes > maximumBytesPerPut {
+		b.maxBytes = maximumBytesPerPut
+	}
+	var (
+		pending   []byte
+		pendingTS time.Time
+		updated   bool
+	)
+	flushPending := func() {
+		if pending != nil {
+			l.processEvent(b, pending, pendingTS)
+			pending = nil
+		}
+	}
 	for {
 		select {
 		case <-timer.C:
-			l.publishBatch(events)
-			events = events[:0]
-			bytes = 0
+			if !updated {
+				flushPending()
+			}
+			updated = false
+			l.publishBatch(b.events)
+			b.reset()
 		case msg, more := <-l.messages:
 			if !more {
-				l.publishBatch(events)
+				flushPending()
+				l.publishBatch(b.events)
 				return
 			}
-			unprocessedLine := msg.Line
-			for len(unprocessedLine) > 0 {
-				// Split line length so it does not exceed the maximum
-				lineBytes := len(unprocessedLine)
-				if lineBytes > maximumBytesPerEvent {
-					lineBytes = maximumBytesPerEvent
-				}
-				line := unprocessedLine[:lineBytes]
-				unprocessedLine = unprocessedLine[lineBytes:]
-				if (len(events) >= maximumLogEventsPerPut) || (bytes+lineBytes+perEventBytes > maximumBytesPerPut) {
-					// Publish an existing batch if it's already over the maximum number of events or if adding this
-					// event would push it over the maximum number of total bytes.
-					l.publishBatch(events)
-					events = events[:0]
-					bytes = 0
-				}
-				events = append(events, &cloudwatchlogs.InputLogEvent{
-					Message:   aws.String(string(line)),
-					Timestamp: aws.Int64(msg.Timestamp.UnixNano() / int64(time.Millisecond)),
-				})
-				bytes += (lineBytes + perEventBytes)
+			if l.multilinePattern == nil {
+				l.processEvent(b, msg.Line, msg.Timestamp)
+				continue
+			}
+			if l.multilinePattern.Match(msg.Line) || pending == nil ||
+				len(pending)+1+len(msg.Line) > maximumBytesPerEvent {
+				flushPending()
+				pending = append([]byte{}, msg.Line...)
+				pendingTS = msg.Timestamp
+			} else {
+				pending = append(append(pending, '\n'), msg.Line...)
 			}
+			updated = true
+		}
+	}
+}
+
+// batch holds the events waiting to be submitted with a single PutLogEvents
+// call, along with their size.
+type batch struct {
+	events   []*cloudwatchlogs.InputLogEvent
+	bytes    int
+	maxBytes int
+}
+
+func (b *batch) reset() {
+	b.events = b.events[:0]
+	b.bytes = 0
+}
+
+// processEvent adds a line to the batch, splitting it into several events if
+// it exceeds maximumBytesPerEvent and publishing the batch when it is full.
+func (l *logStream) processEvent(b *batch, unprocessedLine []byte, timestamp time.Time) {
+	for len(unprocessedLine) > 0 {
+		// Split line length so it does not exceed the maximum
+		lineBytes := len(unprocessedLine)
+		if lineBytes > maximumBytesPerEvent {
+			lineBytes = maximumBytesPerEvent
+		}
+		line := unprocessedLine[:lineBytes]
+		unprocessedLine = unprocessedLine[lineBytes:]
+		if (len(b.events) >= maximumLogEventsPerPut) || (b.bytes+lineBytes+perEventBytes > b.maxBytes) {
+			// Publish an existing batch if it's already over the maximum number of events or if adding this
+			// event would push it over the maximum number of total bytes.
+			l.publishBatch(b.events)
+			b.reset()
 		}
+		b.events = append(b.events, &cloudwatchlogs.InputLogEvent{
+			Message:   aws.String(string(line)),
+			Timestamp: aws.Int64(timestamp.UnixNano() / int64(time.Millisecond)),
+		})
+		b.bytes += (lineBytes + perEventBytes)
 	}
 }
 
@@ -333,13 +477,20 @@ func (l *logStream) putLogEvents(events []*cloudwatchlogs.InputLogEvent, sequenc
 }
 
 // ValidateLogOpt looks for awslogs-specific log options awslogs-region,
-// awslogs-group, and awslogs-stream
+// awslogs-group, awslogs-stream, awslogs-create-group,
+// awslogs-datetime-format, awslogs-multiline-pattern,
+// awslogs-batch-frequency and awslogs-batch-size
 func ValidateLogOpt(cfg map[string]string) error {
 	for key := range cfg {
 		switch key {
 		case logGroupKey:
 		case logStreamKey:
+		case logCreateGroupKey:
 		case regionKey:
+		case datetimeFormatKey:
+		case multilinePatternKey:
+		case batchFrequencyKey:
+		case batchSizeKey:
 		default:
 			return fmt.Errorf("unknown log opt '%s' for %s log driver", key, name)
 		}
@@ -347,9 +498,107 @@ func ValidateLogOpt(cfg map[string]string) error {
 	if cfg[logGroupKey] == "" {
 		return fmt.Errorf("must specify a value for log opt '%s'", logGroupKey)
 	}
+	if cfg[logCreateGroupKey] != "" {
+		if _, err := strconv.ParseBool(cfg[logCreateGroupKey]); err != nil {
+			return fmt.Errorf("must specify valid value for log opt '%s': %v", logCreateGroupKey, err)
+		}
+	}
+	if _, err := parseMultilineOptions(cfg); err != nil {
+		return err
+	}
+	if _, _, err := parseBatchOptions(cfg); err != nil {
+		return err
+	}
 	return nil
 }
 
+// parseMultilineOptions returns the pattern matching the first line of a
+// multiline event, built from either awslogs-multiline-pattern or
+// awslogs-datetime-format.
+func parseMultilineOptions(cfg map[string]string) (*regexp.Regexp, error) {
+	multilinePattern := cfg[multilinePatternKey]
+	datetimeFormat := cfg[datetimeFormatKey]
+	if multilinePattern != "" && datetimeFormat != "" {
+		return nil, fmt.Errorf("you cannot configure log opt '%s' and '%s' at the same time", datetimeFormatKey, multilinePatternKey)
+	}
+	if datetimeFormat != "" {
+		multilinePattern = "^" + convertDatetimeFormat(datetimeFormat)
+	}
+	if multilinePattern == "" {
+		return nil, nil
+	}
+	pattern, err := regexp.Compile(multilinePattern)
+	if err != nil {
+		return nil, fmt.Errorf("awslogs could not parse multiline pattern %q: %v", multilinePattern, err)
+	}
+	return pattern, nil
+}
+
+// strftimeToRegex maps the strftime directives supported by
+// awslogs-datetime-format to the regular expression they match.
+var strftimeToRegex = map[byte]string{
+	'a': `(?:Mon|Tue|Wed|Thu|Fri|Sat|Sun)`,
+	'A': `(?:Monday|Tuesday|Wednesday|Thursday|Friday|Saturday|Sunday)`,
+	'b': `(?:Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec)`,
+	'B': `(?:January|February|March|April|May|June|July|August|September|October|November|December)`,
+	'd': `(?:0[1-9]|[1-2]\d|3[0-1])`,
+	'e': `(?: [1-9]|[1-2]\d|3[0-1])`,
+	'f': `\d{1,9}`,
+	'H': `(?:[0-1]\d|2[0-3])`,
+	'I': `(?:0[1-9]|1[0-2])`,
+	'j': `(?:0[0-9][1-9]|[1-2]\d{2}|3[0-5]\d|36[0-6])`,
+	'm': `(?:0[1-9]|1[0-2])`,
+	'M': `[0-5]\d`,
+	'p': `[AP]M`,
+	'S': `[0-5]\d`,
+	'y': `\d{2}`,
+	'Y': `\d{4}`,
+	'z': `[+-]\d{4}`,
+	'Z': `[A-Za-z_ /]+`,
+	'%': `%`,
+}
+
+// convertDatetimeFormat converts a strftime style format into a regular
+// expression matching the timestamps it produces.
+func convertDatetimeFormat(format string) string {
+	var pattern []string
+	for i := 0; i < len(format); i++ {
+		if format[i] == '%' && i+1 < len(format) {
+			if re, ok := strftimeToRegex[format[i+1]]; ok {
+				pattern = append(pattern, re)
+				i++
+				continue
+			}
+		}
+		pattern = append(pattern, regexp.QuoteMeta(string(format[i])))
+	}
+	return strings.Join(pattern, "")
+}
+
+// parseBatchOptions returns the batch frequency and maximum batch size set
+// with awslogs-batch-frequency and awslogs-batch-size. Zero values mean the
+// defaults are used.
+func parseBatchOptions(cfg map[string]string) (time.Duration, int, error) {
+	var (
+		frequency time.Duration
+		size      int64
+		err       error
+	)
+	if v := cfg[batchFrequencyKey]; v != "" {
+		frequency, err = time.ParseDuration(v)
+		if err != nil || frequency <= 0 {
+			return 0, 0, fmt.Errorf("must specify a positive duration for log opt '%s'", batchFrequencyKey)
+		}
+	}
+	if v := cfg[batchSizeKey]; v != "" {
+		size, err = units.FromHumanSize(v)
+		if err != nil || size <= perEventBytes || size > maximumBytesPerPut {
+			return 0, 0, fmt.Errorf("log opt '%s' must be a size between %d and %d bytes", batchSizeKey, perEventBytes+1, maximumBytesPerPut)
+		}
+	}
+	return frequency, int(size), nil
+}
+
 // Len returns the length of a byTimestamp slice.  Len is required by the
 // sort.Interface interface.
 func (slice byTimestamp) Len() int {
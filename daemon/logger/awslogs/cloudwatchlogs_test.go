@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"regexp"
 	"runtime"
 	"strings"
 	"testing"
@@ -625,3 +626,164 @@ func TestCollectBatchMaxTotalBytes(t *testing.T) {
 		t.Errorf("Expected message to be %s but was %s", "B", message[len(message)-1:])
 	}
 }
+
+func TestCreateLogGroupSuccess(t *testing.T) {
+	mockClient := newMockClient()
+	stream := &logStream{
+		client:         mockClient,
+		logGroupName:   groupName,
+		logStreamName:  streamName,
+		logCreateGroup: true,
+	}
+	mockClient.createLogStreamResult <- &createLogStreamResult{
+		errorResult: awserr.New(resourceNotFoundCode, "", nil),
+	}
+	mockClient.createLogGroupResult <- &createLogGroupResult{}
+
+	go func() {
+		<-mockClient.createLogStreamArgument
+		// the stream is created again once the group exists
+		mockClient.createLogStreamResult <- &createLogStreamResult{}
+	}()
+
+	err := stream.create()
+
+	if err != nil {
+		t.Errorf("Received unexpected err: %v\n", err)
+	}
+	argument := <-mockClient.createLogGroupArgument
+	if argument.LogGroupName == nil || *argument.LogGroupName != groupName {
+		t.Errorf("Expected LogGroupName to be %s", groupName)
+	}
+	streamArgument := <-mockClient.createLogStreamArgument
+	if *streamArgument.LogStreamName != streamName {
+		t.Errorf("Expected LogStreamName to be %s", streamName)
+	}
+}
+
+func TestCollectBatchMultilinePattern(t *testing.T) {
+	mockClient := newMockClient()
+	multilinePattern := regexp.MustCompile("xxxx")
+	stream := &logStream{
+		client:           mockClient,
+		logGroupName:     groupName,
+		logStreamName:    streamName,
+		multilinePattern: multilinePattern,
+		sequenceToken:    aws.String(sequenceToken),
+		messages:         make(chan *logger.Message),
+	}
+	mockClient.putLogEventsResult <- &putLogEventsResult{
+		successResult: &cloudwatchlogs.PutLogEventsOutput{
+			NextSequenceToken: aws.String(nextSequenceToken),
+		},
+	}
+	ticks := make(chan time.Time)
+	newTicker = func(_ time.Duration) *time.Ticker {
+		return &time.Ticker{
+			C: ticks,
+		}
+	}
+
+	go stream.collectBatch()
+
+	stream.Log(&logger.Message{Line: []byte(logline)})
+	stream.Log(&logger.Message{Line: []byte(logline)})
+	stream.Log(&logger.Message{Line: []byte("xxxx " + logline)})
+
+	stream.Close()
+
+	argument := <-mockClient.putLogEventsArgument
+	if len(argument.LogEvents) != 2 {
+		t.Fatalf("Expected LogEvents to contain 2 elements, but contains %d", len(argument.LogEvents))
+	}
+	if *argument.LogEvents[0].Message != logline+"\n"+logline {
+		t.Errorf("Expected message to be %q but was %q", logline+"\n"+logline, *argument.LogEvents[0].Message)
+	}
+	if *argument.LogEvents[1].Message != "xxxx "+logline {
+		t.Errorf("Expected message to be %q but was %q", "xxxx "+logline, *argument.LogEvents[1].Message)
+	}
+}
+
+func TestCollectBatchMultilinePatternMaxEventSize(t *testing.T) {
+	mockClient := newMockClientBuffered(2)
+	multilinePattern := regexp.MustCompile("xxxx")
+	stream := &logStream{
+		client:           mockClient,
+		logGroupName:     groupName,
+		logStreamName:    streamName,
+		multilinePattern: multilinePattern,
+		sequenceToken:    aws.String(sequenceToken),
+		messages:         make(chan *logger.Message),
+	}
+	for i := 0; i < 2; i++ {
+		mockClient.putLogEventsResult <- &putLogEventsResult{
+			successResult: &cloudwatchlogs.PutLogEventsOutput{
+				NextSequenceToken: aws.String(nextSequenceToken),
+			},
+		}
+	}
+	ticks := make(chan time.Time)
+	newTicker = func(_ time.Duration) *time.Ticker {
+		return &time.Ticker{
+			C: ticks,
+		}
+	}
+
+	go stream.collectBatch()
+
+	// A continuous stream of lines never matching the pattern: 255 of them,
+	// joined with newlines, fit in an event, and the next one does not.
+	line := strings.Repeat("A", 1023)
+	for i := 0; i < 600; i++ {
+		stream.Log(&logger.Message{Line: []byte(line)})
+	}
+
+	// The lines keep coming, so the pending event is not flushed by the
+	// ticker, but the full events are published.
+	ticks <- time.Time{}
+	argument := <-mockClient.putLogEventsArgument
+	if len(argument.LogEvents) != 2 {
+		t.Fatalf("Expected LogEvents to contain 2 elements, but contains %d", len(argument.LogEvents))
+	}
+	expected := strings.TrimSuffix(strings.Repeat(line+"\n", 255), "\n")
+	for _, event := range argument.LogEvents {
+		if len(*event.Message) > maximumBytesPerEvent {
+			t.Fatalf("Expected events of at most %d bytes, got %d", maximumBytesPerEvent, len(*event.Message))
+		}
+		if *event.Message != expected {
+			t.Errorf("Expected an event of 255 lines, got %d bytes", len(*event.Message))
+		}
+	}
+
+	stream.Close()
+	argument = <-mockClient.putLogEventsArgument
+	if len(argument.LogEvents) != 1 {
+		t.Fatalf("Expected LogEvents to contain 1 element, but contains %d", len(argument.LogEvents))
+	}
+	if expected := strings.TrimSuffix(strings.Repeat(line+"\n", 90), "\n"); *argument.LogEvents[0].Message != expected {
+		t.Errorf("Expected an event of the 90 last lines, got %d bytes", len(*argument.LogEvents[0].Message))
+	}
+}
+
+func TestParseMultilineOptionsDatetimeFormat(t *testing.T) {
+	pattern, err := parseMultilineOptions(map[string]string{
+		datetimeFormatKey: "[%b %d, %Y %H:%M:%S]",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pattern.MatchString("[Jan 02, 2006 15:04:05] first line") {
+		t.Errorf("Expected %q to match the first line of an event", pattern)
+	}
+	if pattern.MatchString("    at continuation line") {
+		t.Errorf("Expected %q not to match a continuation line", pattern)
+	}
+
+	_, err = parseMultilineOptions(map[string]string{
+		datetimeFormatKey:   "%Y",
+		multilinePatternKey: "^x",
+	})
+	if err == nil {
+		t.Fatal("Expected an error when both multiline options are set")
+	}
+}
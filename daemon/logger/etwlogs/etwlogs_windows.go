@@ -9,7 +9,8 @@
 // 4. You can then convert the etl log file to XML using: tracerpt -y trace.etl
 //
 // Each container log message generates a ETW event that also contains:
-// the container name and ID, the timestamp, and the stream type.
+// the container name and ID, the timestamp, and the stream type, as well as
+// the log tag if the tag option is set.
 package etwlogs
 
 import (
@@ -21,6 +22,7 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/daemon/logger/loggerutils"
 )
 
 type etwLogs struct {
@@ -28,6 +30,7 @@ type etwLogs struct {
 	imageName     string
 	containerID   string
 	imageID       string
+	tag           string
 }
 
 const (
@@ -52,6 +55,14 @@ func New(ctx logger.Context) (logger.Logger, error) {
 	if err := registerETWProvider(); err != nil {
 		return nil, err
 	}
+	var tag string
+	if _, ok := ctx.Config[logger.TagKey]; ok {
+		var err error
+		if tag, err = loggerutils.ParseLogTag(ctx, ""); err != nil {
+			unregisterETWProvider()
+			return nil, err
+		}
+	}
 	logrus.Debugf("logging driver etwLogs configured for container: %s.", ctx.ContainerID)
 
 	return &etwLogs{
@@ -59,6 +70,7 @@ func New(ctx logger.Context) (logger.Logger, error) {
 		imageName:     ctx.ContainerImageName,
 		containerID:   ctx.ContainerID,
 		imageID:       ctx.ContainerImageID,
+		tag:           tag,
 	}, nil
 }
 
@@ -84,6 +96,16 @@ func (etwLogger *etwLogs) Name() string {
 }
 
 func createLogMessage(etwLogger *etwLogs, msg *logger.Message) string {
+	if etwLogger.tag != "" {
+		return fmt.Sprintf("container_name: %s, image_name: %s, container_id: %s, image_id: %s, tag: %s, source: %s, log: %s",
+			etwLogger.containerName,
+			etwLogger.imageName,
+			etwLogger.containerID,
+			etwLogger.imageID,
+			etwLogger.tag,
+			msg.Source,
+			msg.Line)
+	}
 	return fmt.Sprintf("container_name: %s, image_name: %s, container_id: %s, image_id: %s, source: %s, log: %s",
 		etwLogger.containerName,
 		etwLogger.imageName,
@@ -3,8 +3,14 @@ package logger
 import (
 	"fmt"
 	"sync"
+
+	"github.com/docker/docker/utils/templates"
 )
 
+// TagKey is the log option holding the log tag template. It is supported by
+// every logging driver.
+const TagKey = "tag"
+
 // Creator builds a logging driver instance with given context.
 type Creator func(Context) (Logger, error)
 
@@ -86,7 +92,9 @@ func GetLogDriver(name string) (Creator, error) {
 }
 
 // ValidateLogOpts checks the options for the given log driver. The
-// options supported are specific to the LogDriver implementation.
+// options supported are specific to the LogDriver implementation, except
+// for the "tag" option which every driver accepts and which is validated
+// here.
 func ValidateLogOpts(name string, cfg map[string]string) error {
 	if name == "none" {
 		return nil
@@ -96,6 +104,19 @@ func ValidateLogOpts(name string, cfg map[string]string) error {
 		return fmt.Errorf("logger: no log driver named '%s' is registered", name)
 	}
 
+	if tag, ok := cfg[TagKey]; ok {
+		if _, err := templates.NewParse("log-tag", tag); err != nil {
+			return fmt.Errorf("logger: invalid log tag template %q: %v", tag, err)
+		}
+		driverCfg := make(map[string]string, len(cfg))
+		for k, v := range cfg {
+			if k != TagKey {
+				driverCfg[k] = v
+			}
+		}
+		cfg = driverCfg
+	}
+
 	validator := factory.getLogOptValidator(name)
 	if validator != nil {
 		return validator(cfg)
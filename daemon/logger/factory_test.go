@@ -0,0 +1,31 @@
+package logger
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestValidateLogOptsTag(t *testing.T) {
+	const driver = "validate-tag-test"
+	if err := RegisterLogDriver(driver, func(Context) (Logger, error) { return nil, nil }); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterLogOptValidator(driver, func(cfg map[string]string) error {
+		for key := range cfg {
+			return fmt.Errorf("unknown log opt %q", key)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ValidateLogOpts(driver, map[string]string{TagKey: "{{.ImageName}}/{{.Name}}/{{.ID}}"}); err != nil {
+		t.Fatalf("expected tag to be accepted for every driver, got %v", err)
+	}
+	if err := ValidateLogOpts(driver, map[string]string{TagKey: "{{.ImageName"}); err == nil {
+		t.Fatal("expected invalid tag template to be rejected")
+	}
+	if err := ValidateLogOpts(driver, map[string]string{"other": "value"}); err == nil {
+		t.Fatal("expected driver validator to be called")
+	}
+}
@@ -0,0 +1,211 @@
+package fluentd
+
+import (
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+const (
+	// dropEventAction is the container event emitted when messages are
+	// discarded because fluentd is unreachable.
+	dropEventAction = "log_dropped"
+
+	maxRetryWait = time.Minute
+)
+
+// asyncWriter forwards encoded messages to fluentd from a background
+// goroutine, so that the container's log copier never blocks on a slow or
+// unavailable fluentd. The number of bytes waiting to be sent is bounded by
+// bufferLimit; messages that do not fit, or that could not be delivered
+// within maxRetries attempts, are dropped and reported.
+type asyncWriter struct {
+	address     string
+	timeout     time.Duration
+	bufferLimit int
+	retryWait   time.Duration
+	maxRetries  int
+
+	mu       sync.Mutex
+	queue    [][]byte
+	queued   int
+	dropped  int
+	dropping bool
+	notify   func(action string, attributes map[string]string)
+	conn     net.Conn
+	wake     chan struct{}
+	closed   chan struct{}
+	done     chan struct{}
+	closing  bool
+}
+
+func newAsyncWriter(host string, port int, bufferLimit int, retryWait time.Duration, maxRetries int) *asyncWriter {
+	w := &asyncWriter{
+		address:     net.JoinHostPort(host, strconv.Itoa(port)),
+		timeout:     defaultTimeout,
+		bufferLimit: bufferLimit,
+		retryWait:   retryWait,
+		maxRetries:  maxRetries,
+		wake:        make(chan struct{}, 1),
+		closed:      make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Write queues data to be sent. It never blocks on the network.
+func (w *asyncWriter) Write(data []byte) {
+	w.mu.Lock()
+	if w.closing || w.queued+len(data) > w.bufferLimit {
+		w.mu.Unlock()
+		w.drop(1, "buffer full")
+		return
+	}
+	w.queue = append(w.queue, data)
+	w.queued += len(data)
+	w.mu.Unlock()
+
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Close stops the writer once the queued messages have been sent, or have
+// been given up on.
+func (w *asyncWriter) Close() error {
+	w.mu.Lock()
+	if w.closing {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closing = true
+	w.mu.Unlock()
+
+	close(w.closed)
+	<-w.done
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.dropped > 0 {
+		logrus.Warnf("fluentd: %d log messages for %s were dropped", w.dropped, w.address)
+	}
+	if w.conn != nil {
+		return w.conn.Close()
+	}
+	return nil
+}
+
+func (w *asyncWriter) setNotifier(notify func(action string, attributes map[string]string)) {
+	w.mu.Lock()
+	w.notify = notify
+	w.mu.Unlock()
+}
+
+func (w *asyncWriter) run() {
+	defer close(w.done)
+	for {
+		w.mu.Lock()
+		if len(w.queue) == 0 {
+			closing := w.closing
+			w.mu.Unlock()
+			if closing {
+				return
+			}
+			select {
+			case <-w.wake:
+			case <-w.closed:
+			}
+			continue
+		}
+		data := w.queue[0]
+		w.mu.Unlock()
+
+		sent := w.send(data)
+
+		w.mu.Lock()
+		w.queue = w.queue[1:]
+		w.queued -= len(data)
+		if sent {
+			w.dropping = false
+		}
+		w.mu.Unlock()
+
+		if !sent {
+			w.drop(1, "max retries exceeded")
+			w.mu.Lock()
+			if w.closing && len(w.queue) > 0 {
+				// fluentd is unreachable and we are shutting down; give
+				// up on the rest of the queue rather than retrying each.
+				n := len(w.queue)
+				w.queue = nil
+				w.queued = 0
+				w.mu.Unlock()
+				w.drop(n, "max retries exceeded")
+				continue
+			}
+			w.mu.Unlock()
+		}
+	}
+}
+
+// send writes data to fluentd, reconnecting with an exponential backoff
+// until it succeeds or maxRetries is exhausted. Once the writer is closing,
+// a failed attempt is not retried.
+func (w *asyncWriter) send(data []byte) bool {
+	wait := w.retryWait
+	for attempt := 0; ; attempt++ {
+		if w.conn == nil {
+			conn, err := net.DialTimeout("tcp", w.address, w.timeout)
+			if err == nil {
+				w.conn = conn
+			}
+		}
+		if w.conn != nil {
+			if _, err := w.conn.Write(data); err == nil {
+				return true
+			}
+			w.conn.Close()
+			w.conn = nil
+		}
+
+		if attempt >= w.maxRetries {
+			return false
+		}
+		select {
+		case <-w.closed:
+			return false
+		case <-time.After(wait):
+		}
+		if wait = time.Duration(float64(wait) * defaultReconnectWaitIncreRate); wait > maxRetryWait {
+			wait = maxRetryWait
+		}
+	}
+}
+
+// drop records that n messages were discarded. An event is emitted for the
+// first message dropped after a period of successful delivery, so that a
+// flood of drops results in a single event.
+func (w *asyncWriter) drop(n int, reason string) {
+	w.mu.Lock()
+	first := !w.dropping
+	w.dropping = true
+	w.dropped += n
+	notify := w.notify
+	w.mu.Unlock()
+
+	if !first {
+		return
+	}
+	logrus.Errorf("fluentd: dropping log messages for %s: %s", w.address, reason)
+	if notify != nil {
+		notify(dropEventAction, map[string]string{
+			"driver": name,
+			"reason": reason,
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package fluentd
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/tinylib/msgp/msgp"
+)
+
+// eventTimeExtType is the msgpack extension type fluentd uses for
+// timestamps with nanosecond precision (EventTime).
+const eventTimeExtType = 0
+
+// eventTime is the fluentd EventTime extension: seconds and nanoseconds
+// since the epoch, each as a big-endian 32-bit unsigned integer.
+type eventTime time.Time
+
+func (t *eventTime) ExtensionType() int8 { return eventTimeExtType }
+
+func (t *eventTime) Len() int { return 8 }
+
+func (t *eventTime) MarshalBinaryTo(b []byte) error {
+	tm := time.Time(*t)
+	binary.BigEndian.PutUint32(b, uint32(tm.Unix()))
+	binary.BigEndian.PutUint32(b[4:], uint32(tm.Nanosecond()))
+	return nil
+}
+
+func (t *eventTime) UnmarshalBinary(b []byte) error {
+	if len(b) != 8 {
+		return msgp.ErrShortBytes
+	}
+	sec := binary.BigEndian.Uint32(b)
+	nsec := binary.BigEndian.Uint32(b[4:])
+	*t = eventTime(time.Unix(int64(sec), int64(nsec)))
+	return nil
+}
+
+// encodeMessage serializes a record in the fluentd forward protocol's
+// message mode: [tag, time, record]. When subSecond is set the time is sent
+// as an EventTime, which fluentd v0.14 and later understand; otherwise it is
+// an integer number of seconds.
+func encodeMessage(tag string, tm time.Time, record map[string]string, subSecond bool) ([]byte, error) {
+	b := msgp.AppendArrayHeader(nil, 3)
+	b = msgp.AppendString(b, tag)
+	if subSecond {
+		et := eventTime(tm)
+		var err error
+		if b, err = msgp.AppendExtension(b, &et); err != nil {
+			return nil, err
+		}
+	} else {
+		b = msgp.AppendInt64(b, tm.Unix())
+	}
+	return msgp.AppendMapStrStr(b, record), nil
+}
@@ -22,6 +22,8 @@ type fluentd struct {
 	containerID   string
 	containerName string
 	writer        *fluent.Fluent
+	async         *asyncWriter
+	subSecond     bool
 	extra         map[string]string
 }
 
@@ -45,6 +47,8 @@ const (
 	retryWaitKey    = "fluentd-retry-wait"
 	maxRetriesKey   = "fluentd-max-retries"
 	asyncConnectKey = "fluentd-async-connect"
+	asyncKey        = "fluentd-async"
+	subSecondKey    = "fluentd-sub-second-precision"
 )
 
 func init() {
@@ -106,6 +110,35 @@ func New(ctx logger.Context) (logger.Logger, error) {
 		}
 	}
 
+	async := false
+	if ctx.Config[asyncKey] != "" {
+		if async, err = strconv.ParseBool(ctx.Config[asyncKey]); err != nil {
+			return nil, err
+		}
+	}
+
+	subSecond := false
+	if ctx.Config[subSecondKey] != "" {
+		if subSecond, err = strconv.ParseBool(ctx.Config[subSecondKey]); err != nil {
+			return nil, err
+		}
+	}
+
+	f := &fluentd{
+		tag:           tag,
+		containerID:   ctx.ContainerID,
+		containerName: ctx.ContainerName,
+		subSecond:     subSecond,
+		extra:         extra,
+	}
+
+	if async {
+		logrus.WithField("container", ctx.ContainerID).
+			Debugf("logging driver fluentd configured in async mode for %s:%d", host, port)
+		f.async = newAsyncWriter(host, port, bufferLimit, time.Duration(retryWait)*time.Millisecond, maxRetries)
+		return f, nil
+	}
+
 	fluentConfig := fluent.Config{
 		FluentPort:   port,
 		FluentHost:   host,
@@ -118,17 +151,11 @@ func New(ctx logger.Context) (logger.Logger, error) {
 	logrus.WithField("container", ctx.ContainerID).WithField("config", fluentConfig).
 		Debug("logging driver fluentd configured")
 
-	log, err := fluent.New(fluentConfig)
+	f.writer, err = fluent.New(fluentConfig)
 	if err != nil {
 		return nil, err
 	}
-	return &fluentd{
-		tag:           tag,
-		containerID:   ctx.ContainerID,
-		containerName: ctx.ContainerName,
-		writer:        log,
-		extra:         extra,
-	}, nil
+	return f, nil
 }
 
 func (f *fluentd) Log(msg *logger.Message) error {
@@ -141,15 +168,35 @@ func (f *fluentd) Log(msg *logger.Message) error {
 	for k, v := range f.extra {
 		data[k] = v
 	}
+	encoded, err := encodeMessage(f.tag, msg.Timestamp, data, f.subSecond)
+	if err != nil {
+		return err
+	}
+	if f.async != nil {
+		f.async.Write(encoded)
+		return nil
+	}
 	// fluent-logger-golang buffers logs from failures and disconnections,
 	// and these are transferred again automatically.
-	return f.writer.PostWithTime(f.tag, msg.Timestamp, data)
+	f.writer.PostRawData(encoded)
+	return nil
 }
 
 func (f *fluentd) Close() error {
+	if f.async != nil {
+		return f.async.Close()
+	}
 	return f.writer.Close()
 }
 
+// SetEventNotifier implements logger.EventNotifier. Only the async mode
+// reports dropped messages.
+func (f *fluentd) SetEventNotifier(notify func(action string, attributes map[string]string)) {
+	if f.async != nil {
+		f.async.setNotifier(notify)
+	}
+}
+
 func (f *fluentd) Name() string {
 	return name
 }
@@ -160,12 +207,13 @@ func ValidateLogOpt(cfg map[string]string) error {
 		switch key {
 		case "env":
 		case "labels":
-		case "tag":
 		case addressKey:
 		case bufferLimitKey:
 		case retryWaitKey:
 		case maxRetriesKey:
 		case asyncConnectKey:
+		case asyncKey:
+		case subSecondKey:
 			// Accepted
 		default:
 			return fmt.Errorf("unknown log opt '%s' for fluentd log driver", key)
@@ -0,0 +1,69 @@
+package fluentd
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/tinylib/msgp/msgp"
+)
+
+func TestEncodeMessageSubSecond(t *testing.T) {
+	tm := time.Unix(1467000000, 123456789)
+	b, err := encodeMessage("docker.test", tm, map[string]string{"log": "hello"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sz, b, err := msgp.ReadArrayHeaderBytes(b)
+	if err != nil || sz != 3 {
+		t.Fatalf("expected a 3 element array, got %d: %v", sz, err)
+	}
+	tag, b, err := msgp.ReadStringBytes(b)
+	if err != nil || tag != "docker.test" {
+		t.Fatalf("unexpected tag %q: %v", tag, err)
+	}
+	var et eventTime
+	if _, err := msgp.ReadExtensionBytes(b, &et); err != nil {
+		t.Fatal(err)
+	}
+	if !time.Time(et).Equal(tm) {
+		t.Fatalf("expected time %v, got %v", tm, time.Time(et))
+	}
+}
+
+func TestAsyncWriterReportsDrops(t *testing.T) {
+	// Reserve a port and close it again so that nothing is listening.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().(*net.TCPAddr)
+	l.Close()
+
+	w := newAsyncWriter("127.0.0.1", addr.Port, 8, time.Millisecond, 0)
+	events := make(chan map[string]string, 10)
+	w.setNotifier(func(action string, attributes map[string]string) {
+		if action != dropEventAction {
+			t.Errorf("unexpected event %q", action)
+		}
+		events <- attributes
+	})
+
+	w.Write([]byte("0123456789"))
+	select {
+	case attrs := <-events:
+		if attrs["reason"] != "buffer full" {
+			t.Fatalf("unexpected drop reason %q", attrs["reason"])
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a drop event for a message larger than the buffer")
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if w.dropped != 1 {
+		t.Fatalf("expected 1 dropped message, got %d", w.dropped)
+	}
+}
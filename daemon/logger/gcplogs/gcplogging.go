@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/daemon/logger/loggerutils"
 
 	"github.com/Sirupsen/logrus"
 	"golang.org/x/net/context"
@@ -72,6 +73,7 @@ type containerInfo struct {
 	ImageID   string            `json:"imageId,omitempty"`
 	Created   time.Time         `json:"created,omitempty"`
 	Command   string            `json:"command,omitempty"`
+	Tag       string            `json:"tag,omitempty"`
 	Metadata  map[string]string `json:"metadata,omitempty"`
 }
 
@@ -132,6 +134,12 @@ func New(ctx logger.Context) (logger.Logger, error) {
 		},
 	}
 
+	if _, ok := ctx.Config[logger.TagKey]; ok {
+		if l.container.Tag, err = loggerutils.ParseLogTag(ctx, ""); err != nil {
+			return nil, err
+		}
+	}
+
 	if ctx.Config[logCmdKey] == "true" {
 		l.container.Command = ctx.Command()
 	}
@@ -21,10 +21,19 @@ import (
 	"github.com/docker/docker/pkg/urlutil"
 )
 
-const name = "gelf"
+const (
+	name        = "gelf"
+	secureProto = "tcp+tls"
+)
+
+// messageWriter is implemented by every GELF transport.
+type messageWriter interface {
+	WriteMessage(*gelf.Message) error
+	Close() error
+}
 
 type gelfLogger struct {
-	writer   *gelf.Writer
+	writer   messageWriter
 	ctx      logger.Context
 	hostname string
 	rawExtra json.RawMessage
@@ -43,7 +52,7 @@ func init() {
 // context. The supported context configuration variable is gelf-address.
 func New(ctx logger.Context) (logger.Logger, error) {
 	// parse gelf address
-	address, err := parseAddress(ctx.Config["gelf-address"])
+	proto, address, err := parseAddress(ctx.Config["gelf-address"])
 	if err != nil {
 		return nil, err
 	}
@@ -88,13 +97,33 @@ func New(ctx logger.Context) (logger.Logger, error) {
 		return nil, err
 	}
 
-	// create new gelfWriter
-	gelfWriter, err := gelf.NewWriter(address)
+	var writer messageWriter
+	if proto == "udp" {
+		writer, err = newUDPWriter(address, ctx.Config)
+	} else {
+		writer, err = newTCPWriter(proto, address, ctx.Config)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("gelf: cannot connect to GELF endpoint: %s %v", address, err)
 	}
 
-	if v, ok := ctx.Config["gelf-compression-type"]; ok {
+	return &gelfLogger{
+		writer:   writer,
+		ctx:      ctx,
+		hostname: hostname,
+		rawExtra: rawExtra,
+	}, nil
+}
+
+// newUDPWriter creates a writer for the UDP transport, which supports
+// compression and chunking of large messages.
+func newUDPWriter(address string, cfg map[string]string) (*gelf.Writer, error) {
+	gelfWriter, err := gelf.NewWriter(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if v, ok := cfg["gelf-compression-type"]; ok {
 		switch v {
 		case "gzip":
 			gelfWriter.CompressionType = gelf.CompressGzip
@@ -103,24 +132,19 @@ func New(ctx logger.Context) (logger.Logger, error) {
 		case "none":
 			gelfWriter.CompressionType = gelf.CompressNone
 		default:
-			return nil, fmt.Errorf("gelf: invalid compression type %q", v)
+			return nil, fmt.Errorf("invalid compression type %q", v)
 		}
 	}
 
-	if v, ok := ctx.Config["gelf-compression-level"]; ok {
+	if v, ok := cfg["gelf-compression-level"]; ok {
 		val, err := strconv.Atoi(v)
 		if err != nil {
-			return nil, fmt.Errorf("gelf: invalid compression level %s, err %v", v, err)
+			return nil, fmt.Errorf("invalid compression level %s, err %v", v, err)
 		}
 		gelfWriter.CompressionLevel = val
 	}
 
-	return &gelfLogger{
-		writer:   gelfWriter,
-		ctx:      ctx,
-		hostname: hostname,
-		rawExtra: rawExtra,
-	}, nil
+	return gelfWriter, nil
 }
 
 func (s *gelfLogger) Log(msg *logger.Message) error {
@@ -154,18 +178,43 @@ func (s *gelfLogger) Name() string {
 
 // ValidateLogOpt looks for gelf specific log option gelf-address.
 func ValidateLogOpt(cfg map[string]string) error {
+	proto, _, err := parseAddress(cfg["gelf-address"])
+	if err != nil {
+		return err
+	}
+
 	for key, val := range cfg {
 		switch key {
 		case "gelf-address":
-		case "tag":
 		case "labels":
 		case "env":
+		case "gelf-tls-ca-cert", "gelf-tls-cert", "gelf-tls-key":
+			if proto != secureProto {
+				return fmt.Errorf("log opt %q is only supported with the tcp+tls transport", key)
+			}
+		case "gelf-tls-skip-verify":
+			if proto != secureProto {
+				return fmt.Errorf("log opt %q is only supported with the tcp+tls transport", key)
+			}
+			if _, err := strconv.ParseBool(val); err != nil {
+				return fmt.Errorf("unknown value %q for log opt %q for gelf log driver", val, key)
+			}
+		case "gelf-tcp-max-reconnect", "gelf-tcp-reconnect-delay", "gelf-tcp-buffer-size":
+			if proto == "udp" {
+				return fmt.Errorf("log opt %q is not supported with the udp transport", key)
+			}
 		case "gelf-compression-level":
+			if proto != "udp" {
+				return fmt.Errorf("log opt %q is only supported with the udp transport", key)
+			}
 			i, err := strconv.Atoi(val)
 			if err != nil || i < flate.DefaultCompression || i > flate.BestCompression {
 				return fmt.Errorf("unknown value %q for log opt %q for gelf log driver", val, key)
 			}
 		case "gelf-compression-type":
+			if proto != "udp" {
+				return fmt.Errorf("log opt %q is only supported with the udp transport", key)
+			}
 			switch val {
 			case "gzip", "zlib", "none":
 			default:
@@ -176,34 +225,35 @@ func ValidateLogOpt(cfg map[string]string) error {
 		}
 	}
 
-	if _, err := parseAddress(cfg["gelf-address"]); err != nil {
+	if _, _, _, err := parseTCPOptions(cfg); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func parseAddress(address string) (string, error) {
+func parseAddress(address string) (string, string, error) {
 	if address == "" {
-		return "", nil
+		return "udp", "", nil
 	}
 	if !urlutil.IsTransportURL(address) {
-		return "", fmt.Errorf("gelf-address should be in form proto://address, got %v", address)
+		return "", "", fmt.Errorf("gelf-address should be in form proto://address, got %v", address)
 	}
 	url, err := url.Parse(address)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
-	// we support only udp
-	if url.Scheme != "udp" {
-		return "", fmt.Errorf("gelf: endpoint needs to be UDP")
+	switch url.Scheme {
+	case "udp", "tcp", secureProto:
+	default:
+		return "", "", fmt.Errorf("gelf: endpoint needs to be UDP, TCP or TCP+TLS")
 	}
 
 	// get host and port
 	if _, _, err = net.SplitHostPort(url.Host); err != nil {
-		return "", fmt.Errorf("gelf: please provide gelf-address as udp://host:port")
+		return "", "", fmt.Errorf("gelf: please provide gelf-address as %s://host:port", url.Scheme)
 	}
 
-	return url.Host, nil
+	return url.Scheme, url.Host, nil
 }
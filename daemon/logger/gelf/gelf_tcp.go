@@ -0,0 +1,310 @@
+// +build linux
+
+package gelf
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Graylog2/go-gelf/gelf"
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/go-connections/tlsconfig"
+)
+
+const (
+	defaultMaxReconnect   = 3
+	defaultReconnectDelay = 1 * time.Second
+	defaultBufferSize     = 1024
+
+	// ioTimeout bounds the time spent connecting to the endpoint or
+	// writing a message to it.
+	ioTimeout = 10 * time.Second
+)
+
+// tcpWriter sends GELF messages over a stream connection (plain TCP or
+// TLS). GELF over TCP does not support chunking or compression; every
+// message is terminated by a null byte instead. Messages that cannot be
+// delivered are kept in a bounded local buffer and resent once the
+// connection has been re-established.
+type tcpWriter struct {
+	mu             sync.Mutex
+	key            string
+	refs           int
+	proto          string
+	address        string
+	tlsConfig      *tls.Config
+	conn           net.Conn
+	pending        [][]byte
+	bufferSize     int
+	maxReconnect   int
+	reconnectDelay time.Duration
+	reconnecting   bool
+}
+
+// tcpPool holds the stream connections that are currently in use, so that
+// containers logging to the same endpoint share a single connection.
+var tcpPool = struct {
+	sync.Mutex
+	writers map[string]*tcpWriter
+}{writers: make(map[string]*tcpWriter)}
+
+// newTCPWriter returns a writer for the given endpoint, reusing an existing
+// pooled connection when one with identical settings is open.
+func newTCPWriter(proto, address string, cfg map[string]string) (*tcpWriter, error) {
+	maxReconnect, reconnectDelay, bufferSize, err := parseTCPOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var tlsConfig *tls.Config
+	if proto == secureProto {
+		if tlsConfig, err = parseTLSConfig(cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	skipVerify, err := parseSkipVerify(cfg)
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("%s://%s|%s|%s|%s|%t|%d|%s|%d", proto, address,
+		cfg["gelf-tls-ca-cert"], cfg["gelf-tls-cert"], cfg["gelf-tls-key"], skipVerify,
+		maxReconnect, reconnectDelay, bufferSize)
+
+	tcpPool.Lock()
+	defer tcpPool.Unlock()
+
+	if w, ok := tcpPool.writers[key]; ok {
+		w.mu.Lock()
+		w.refs++
+		w.mu.Unlock()
+		return w, nil
+	}
+
+	w := &tcpWriter{
+		key:            key,
+		refs:           1,
+		proto:          proto,
+		address:        address,
+		tlsConfig:      tlsConfig,
+		bufferSize:     bufferSize,
+		maxReconnect:   maxReconnect,
+		reconnectDelay: reconnectDelay,
+	}
+	// Fail early if the endpoint is unreachable when the container starts,
+	// like the UDP transport does for unresolvable addresses.
+	if err := w.connect(); err != nil {
+		return nil, err
+	}
+	tcpPool.writers[key] = w
+	return w, nil
+}
+
+// WriteMessage serializes m and sends it, together with any messages that
+// were buffered while the endpoint was unavailable.
+func (w *tcpWriter) WriteMessage(m *gelf.Message) error {
+	var buf bytes.Buffer
+	if err := m.MarshalJSONBuf(&buf); err != nil {
+		return err
+	}
+	buf.WriteByte(0)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	dropped := 0
+	w.pending = append(w.pending, buf.Bytes())
+	if len(w.pending) > w.bufferSize {
+		dropped = len(w.pending) - w.bufferSize
+		w.pending = w.pending[dropped:]
+	}
+
+	if err := w.flush(); err != nil {
+		// The messages stay buffered and are retried on the next write.
+		logrus.Debugf("gelf: buffering messages for %s: %v", w.address, err)
+	}
+	if dropped > 0 {
+		return fmt.Errorf("endpoint %s unavailable, dropped %d buffered messages", w.address, dropped)
+	}
+	return nil
+}
+
+// flush writes out every pending message. When the connection is lost it
+// starts reconnecting in the background and returns, leaving the remaining
+// messages buffered. It must be called with w.mu held.
+func (w *tcpWriter) flush() error {
+	for len(w.pending) > 0 {
+		if w.conn == nil {
+			w.startReconnect()
+			return fmt.Errorf("not connected to GELF endpoint %s", w.address)
+		}
+		w.conn.SetWriteDeadline(time.Now().Add(ioTimeout))
+		if _, err := w.conn.Write(w.pending[0]); err != nil {
+			w.conn.Close()
+			w.conn = nil
+			w.startReconnect()
+			return err
+		}
+		w.pending = w.pending[1:]
+	}
+	return nil
+}
+
+// startReconnect starts re-establishing the connection unless this is
+// already in progress or the writer has been closed. It must be called with
+// w.mu held.
+func (w *tcpWriter) startReconnect() {
+	if w.reconnecting || w.refs == 0 {
+		return
+	}
+	w.reconnecting = true
+	go w.reconnect()
+}
+
+// reconnect tries to re-establish the connection up to maxReconnect times,
+// waiting reconnectDelay between attempts, and then flushes the buffered
+// messages. It does not hold w.mu while dialing or waiting, so the
+// containers sharing the writer keep buffering their messages meanwhile.
+// If every attempt fails, the next write starts over.
+func (w *tcpWriter) reconnect() {
+	var (
+		conn net.Conn
+		err  error
+	)
+	for i := 0; i <= w.maxReconnect; i++ {
+		if i > 0 {
+			time.Sleep(w.reconnectDelay)
+		}
+		if conn, err = w.dial(); err == nil {
+			break
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.reconnecting = false
+	if err != nil {
+		logrus.Warnf("gelf: cannot reconnect to %s, %d messages buffered: %v", w.address, len(w.pending), err)
+		return
+	}
+	if w.refs == 0 {
+		conn.Close()
+		return
+	}
+	w.conn = conn
+	if err := w.flush(); err != nil {
+		logrus.Debugf("gelf: buffering messages for %s: %v", w.address, err)
+	}
+}
+
+func (w *tcpWriter) connect() error {
+	conn, err := w.dial()
+	if err != nil {
+		return err
+	}
+	w.conn = conn
+	return nil
+}
+
+func (w *tcpWriter) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: ioTimeout}
+	if w.tlsConfig != nil {
+		return tls.DialWithDialer(dialer, "tcp", w.address, w.tlsConfig)
+	}
+	return dialer.Dial("tcp", w.address)
+}
+
+// Close releases the caller's reference to the writer. The connection is
+// closed once the last container using it goes away.
+func (w *tcpWriter) Close() error {
+	tcpPool.Lock()
+	defer tcpPool.Unlock()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.refs--
+	if w.refs > 0 {
+		return nil
+	}
+	delete(tcpPool.writers, w.key)
+
+	// Make a last attempt to deliver what is left in the buffer.
+	if len(w.pending) > 0 && w.conn != nil {
+		w.flush()
+	}
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+func parseTCPOptions(cfg map[string]string) (int, time.Duration, int, error) {
+	maxReconnect := defaultMaxReconnect
+	if v, ok := cfg["gelf-tcp-max-reconnect"]; ok {
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 0 {
+			return 0, 0, 0, fmt.Errorf("gelf-tcp-max-reconnect must be a non-negative integer")
+		}
+		maxReconnect = i
+	}
+
+	reconnectDelay := defaultReconnectDelay
+	if v, ok := cfg["gelf-tcp-reconnect-delay"]; ok {
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 1 {
+			return 0, 0, 0, fmt.Errorf("gelf-tcp-reconnect-delay must be a positive integer")
+		}
+		reconnectDelay = time.Duration(i) * time.Second
+	}
+
+	bufferSize := defaultBufferSize
+	if v, ok := cfg["gelf-tcp-buffer-size"]; ok {
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 1 {
+			return 0, 0, 0, fmt.Errorf("gelf-tcp-buffer-size must be a positive integer")
+		}
+		bufferSize = i
+	}
+
+	return maxReconnect, reconnectDelay, bufferSize, nil
+}
+
+func parseTLSConfig(cfg map[string]string) (*tls.Config, error) {
+	skipVerify, err := parseSkipVerify(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := tlsconfig.Options{
+		CAFile:             cfg["gelf-tls-ca-cert"],
+		CertFile:           cfg["gelf-tls-cert"],
+		KeyFile:            cfg["gelf-tls-key"],
+		InsecureSkipVerify: skipVerify,
+	}
+
+	return tlsconfig.Client(opts)
+}
+
+// parseSkipVerify returns whether gelf-tls-skip-verify disables the
+// verification of the server certificate. It is off when the option is not
+// set.
+func parseSkipVerify(cfg map[string]string) (bool, error) {
+	v, ok := cfg["gelf-tls-skip-verify"]
+	if !ok {
+		return false, nil
+	}
+	skipVerify, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("gelf-tls-skip-verify must be a boolean")
+	}
+	return skipVerify, nil
+}
@@ -0,0 +1,180 @@
+// +build linux
+
+package gelf
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Graylog2/go-gelf/gelf"
+)
+
+func TestParseAddress(t *testing.T) {
+	for _, addr := range []string{"udp://127.0.0.1:12201", "tcp://127.0.0.1:12201", "tcp+tls://127.0.0.1:12201"} {
+		if _, _, err := parseAddress(addr); err != nil {
+			t.Fatalf("expected %q to be valid, got %v", addr, err)
+		}
+	}
+	for _, addr := range []string{"127.0.0.1:12201", "http://127.0.0.1:12201", "tcp://127.0.0.1"} {
+		if _, _, err := parseAddress(addr); err == nil {
+			t.Fatalf("expected %q to be invalid", addr)
+		}
+	}
+}
+
+func TestValidateLogOptTransport(t *testing.T) {
+	if err := ValidateLogOpt(map[string]string{
+		"gelf-address":           "tcp://127.0.0.1:12201",
+		"gelf-tcp-max-reconnect": "5",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateLogOpt(map[string]string{
+		"gelf-address":          "tcp://127.0.0.1:12201",
+		"gelf-compression-type": "gzip",
+	}); err == nil {
+		t.Fatal("expected compression to be rejected for tcp")
+	}
+	if err := ValidateLogOpt(map[string]string{
+		"gelf-address":     "tcp://127.0.0.1:12201",
+		"gelf-tls-ca-cert": "/ca.pem",
+	}); err == nil {
+		t.Fatal("expected tls options to be rejected for plain tcp")
+	}
+	if err := ValidateLogOpt(map[string]string{
+		"gelf-address":             "udp://127.0.0.1:12201",
+		"gelf-tcp-reconnect-delay": "1",
+	}); err == nil {
+		t.Fatal("expected tcp options to be rejected for udp")
+	}
+	if err := ValidateLogOpt(map[string]string{
+		"gelf-address":         "tcp+tls://127.0.0.1:12201",
+		"gelf-tls-skip-verify": "yes",
+	}); err == nil {
+		t.Fatal("expected a non-boolean gelf-tls-skip-verify to be rejected")
+	}
+}
+
+func TestParseSkipVerify(t *testing.T) {
+	for v, expected := range map[string]bool{"true": true, "1": true, "false": false, "0": false} {
+		skipVerify, err := parseSkipVerify(map[string]string{"gelf-tls-skip-verify": v})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if skipVerify != expected {
+			t.Fatalf("expected %q to be parsed as %t", v, expected)
+		}
+	}
+	if skipVerify, err := parseSkipVerify(map[string]string{}); err != nil || skipVerify {
+		t.Fatalf("expected verification to be on by default, got %t, %v", skipVerify, err)
+	}
+}
+
+func TestTCPWriterSharesConnectionAndDelimitsMessages(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	received := make(chan []byte, 2)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			b, err := r.ReadBytes(0)
+			if err != nil {
+				return
+			}
+			received <- b
+		}
+	}()
+
+	w1, err := newTCPWriter("tcp", l.Addr().String(), map[string]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w2, err := newTCPWriter("tcp", l.Addr().String(), map[string]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w1 != w2 {
+		t.Fatal("expected writers for the same endpoint to be shared")
+	}
+
+	for _, w := range []*tcpWriter{w1, w2} {
+		if err := w.WriteMessage(&gelf.Message{Version: "1.1", Host: "test", Short: "hello"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		b := <-received
+		if b[len(b)-1] != 0 || !bytes.Contains(b, []byte(`"short_message":"hello"`)) {
+			t.Fatalf("unexpected message %q", b)
+		}
+	}
+
+	w1.Close()
+	if w2.conn == nil {
+		t.Fatal("connection closed while still referenced")
+	}
+	w2.Close()
+	if w2.conn != nil {
+		t.Fatal("expected connection to be closed after last reference")
+	}
+}
+
+func TestTCPWriterDoesNotBlockWhileReconnecting(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := l.Accept(); err == nil {
+			accepted <- conn
+		}
+	}()
+
+	w, err := newTCPWriter("tcp", l.Addr().String(), map[string]string{
+		"gelf-tcp-max-reconnect":   "5",
+		"gelf-tcp-reconnect-delay": "10",
+		"gelf-tcp-buffer-size":     "2",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	// Take the endpoint down
+	l.Close()
+	(<-accepted).Close()
+
+	start := time.Now()
+	var dropped bool
+	for i := 0; i < 100; i++ {
+		if err := w.WriteMessage(&gelf.Message{Version: "1.1", Host: "test", Short: "hello"}); err != nil {
+			dropped = true
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("writes blocked for %s while the endpoint was down", elapsed)
+	}
+	if !dropped {
+		t.Fatal("expected messages beyond the buffer size to be dropped")
+	}
+
+	w.mu.Lock()
+	pending := len(w.pending)
+	w.mu.Unlock()
+	if pending != 2 {
+		t.Fatalf("expected 2 buffered messages, got %d", pending)
+	}
+}
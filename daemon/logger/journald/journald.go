@@ -18,8 +18,9 @@ import (
 const name = "journald"
 
 type journald struct {
-	vars    map[string]string // additional variables and values to send to the journal along with the log message
-	readers readerList
+	vars        map[string]string // additional variables and values to send to the journal along with the log message
+	extraFields map[string]string // journal fields holding labels and env, mapped to the original label or env name
+	readers     readerList
 }
 
 type readerList struct {
@@ -61,11 +62,50 @@ func New(ctx logger.Context) (logger.Logger, error) {
 		"CONTAINER_NAME":    name,
 		"CONTAINER_TAG":     tag,
 	}
-	extraAttrs := ctx.ExtraAttributes(strings.ToTitle)
-	for k, v := range extraAttrs {
-		vars[k] = v
+	extraFields := make(map[string]string)
+	for k, v := range ctx.ExtraAttributes(nil) {
+		field := sanitizeKeyMod(k)
+		if field == "" {
+			continue
+		}
+		if _, ok := vars[field]; ok {
+			// Labels and environment variables never override the
+			// fields describing the container.
+			logrus.Debugf("journald: ignoring extra attribute %s that conflicts with a container field", k)
+			continue
+		}
+		vars[field] = v
+		extraFields[field] = k
+	}
+	return &journald{vars: vars, extraFields: extraFields, readers: readerList{readers: make(map[*logger.LogWatcher]*logger.LogWatcher)}}, nil
+}
+
+// sanitizeKeyMod turns a label or environment variable name into a valid
+// journal field name: uppercase letters, digits and underscores, at most 64
+// characters long, and not starting with an underscore or a digit. Fields
+// starting with an underscore are reserved for trusted fields set by
+// journald itself.
+func sanitizeKeyMod(s string) string {
+	var buf []byte
+	for _, c := range []byte(strings.ToUpper(s)) {
+		switch {
+		case c >= 'A' && c <= 'Z', c == '_':
+		case c >= '0' && c <= '9':
+			if len(buf) == 0 {
+				continue
+			}
+		default:
+			c = '_'
+		}
+		if c == '_' && len(buf) == 0 {
+			continue
+		}
+		buf = append(buf, c)
+	}
+	if len(buf) > 64 {
+		buf = buf[:64]
 	}
-	return &journald{vars: vars, readers: readerList{readers: make(map[*logger.LogWatcher]*logger.LogWatcher)}}, nil
+	return string(buf)
 }
 
 // We don't actually accept any options, but we have to supply a callback for
@@ -75,7 +115,6 @@ func validateLogOpt(cfg map[string]string) error {
 		switch key {
 		case "labels":
 		case "env":
-		case "tag":
 		default:
 			return fmt.Errorf("unknown log opt '%s' for journald log driver", key)
 		}
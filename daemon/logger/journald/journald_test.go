@@ -0,0 +1,22 @@
+// +build linux
+
+package journald
+
+import "testing"
+
+func TestSanitizeKeyMod(t *testing.T) {
+	entries := map[string]string{
+		"io.kubernetes.pod.name":      "IO_KUBERNETES_POD_NAME",
+		"io?.kubernetes.pod\x3a.name": "IO__KUBERNETES_POD__NAME",
+		"?io.kubernetes.pod.name":     "IO_KUBERNETES_POD_NAME",
+		"io123.kubernetes.pod.name":   "IO123_KUBERNETES_POD_NAME",
+		"_io.kubernetes.pod.name":     "IO_KUBERNETES_POD_NAME",
+		"1deployment":                 "DEPLOYMENT",
+		"__":                          "",
+	}
+	for k, v := range entries {
+		if got := sanitizeKeyMod(k); got != v {
+			t.Fatalf("expected %q to be sanitized to %q, got %q", k, v, got)
+		}
+	}
+}
@@ -30,6 +30,25 @@ package journald
 //	}
 //	return rc;
 //}
+//static int get_attribute_field(sd_journal *j, const char *field, const char **value, size_t *length)
+//{
+//	int rc;
+//	size_t prefix = strlen(field) + 1;
+//	*value = NULL;
+//	*length = 0;
+//	rc = sd_journal_get_data(j, field, (const void **) value, length);
+//	if (rc == 0) {
+//		if (*length >= prefix) {
+//			(*value) += prefix;
+//			*length -= prefix;
+//		} else {
+//			*value = NULL;
+//			*length = 0;
+//			rc = -ENOENT;
+//		}
+//	}
+//	return rc;
+//}
 //static int get_priority(sd_journal *j, int *priority)
 //{
 //	const void *data;
@@ -158,7 +177,7 @@ drain:
 			}
 			// Send the log message.
 			cid := s.vars["CONTAINER_ID_FULL"]
-			logWatcher.Msg <- &logger.Message{ContainerID: cid, Line: line, Source: source, Timestamp: timestamp}
+			logWatcher.Msg <- &logger.Message{ContainerID: cid, Line: line, Source: source, Timestamp: timestamp, Attrs: s.readAttributes(j)}
 		}
 		// If we're at the end of the journal, we're done (for now).
 		if C.sd_journal_next(j) <= 0 {
@@ -173,6 +192,26 @@ drain:
 	return retCursor
 }
 
+// readAttributes returns the labels and environment variables that were
+// attached to the current journal entry, keyed by their original names.
+func (s *journald) readAttributes(j *C.sd_journal) logger.LogAttributes {
+	if len(s.extraFields) == 0 {
+		return nil
+	}
+	var value *C.char
+	var length C.size_t
+	attrs := make(logger.LogAttributes, len(s.extraFields))
+	for field, key := range s.extraFields {
+		cfield := C.CString(field)
+		rc := C.get_attribute_field(j, cfield, &value, &length)
+		C.free(unsafe.Pointer(cfield))
+		if rc == 0 {
+			attrs[key] = C.GoStringN(value, C.int(length))
+		}
+	}
+	return attrs
+}
+
 func (s *journald) followJournal(logWatcher *logger.LogWatcher, config logger.ReadConfig, j *C.sd_journal, pfd [2]C.int, cursor string) {
 	s.readers.mu.Lock()
 	s.readers.readers[logWatcher] = logWatcher
@@ -66,8 +66,17 @@ func New(ctx logger.Context) (logger.Logger, error) {
 		return nil, err
 	}
 
+	attrs := ctx.ExtraAttributes(nil)
+	if _, ok := ctx.Config[logger.TagKey]; ok {
+		tag, err := loggerutils.ParseLogTag(ctx, "")
+		if err != nil {
+			return nil, err
+		}
+		attrs[logger.TagKey] = tag
+	}
+
 	var extra []byte
-	if attrs := ctx.ExtraAttributes(nil); len(attrs) > 0 {
+	if len(attrs) > 0 {
 		var err error
 		extra, err = json.Marshal(attrs)
 		if err != nil {
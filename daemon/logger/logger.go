@@ -78,6 +78,13 @@ type LogReader interface {
 	ReadLogs(ReadConfig) *LogWatcher
 }
 
+// EventNotifier is implemented by loggers that report noteworthy conditions,
+// such as dropped messages, back to the daemon so they can be surfaced as
+// container events.
+type EventNotifier interface {
+	SetEventNotifier(func(action string, attributes map[string]string))
+}
+
 // LogWatcher is used when consuming logs read from the LogReader interface.
 type LogWatcher struct {
 	// For sending log messages to a reader.
@@ -10,12 +10,18 @@ import (
 // ParseLogTag generates a context aware tag for consistency across different
 // log drivers based on the context of the running container.
 func ParseLogTag(ctx logger.Context, defaultTemplate string) (string, error) {
-	tagTemplate := ctx.Config["tag"]
+	tagTemplate := ctx.Config[logger.TagKey]
 	if tagTemplate == "" {
 		tagTemplate = defaultTemplate
 	}
 
-	tmpl, err := templates.NewParse("log-tag", tagTemplate)
+	return ParseLogTemplate(ctx, "log-tag", tagTemplate)
+}
+
+// ParseLogTemplate renders a template option against the context of the
+// running container, using the same fields as the log tag.
+func ParseLogTemplate(ctx logger.Context, name, text string) (string, error) {
+	tmpl, err := templates.NewParse(name, text)
 	if err != nil {
 		return "", err
 	}
@@ -32,7 +32,6 @@ const (
 	splunkInsecureSkipVerifyKey = "splunk-insecureskipverify"
 	envKey                      = "env"
 	labelsKey                   = "labels"
-	tagKey                      = "tag"
 )
 
 type splunkLogger struct {
@@ -210,7 +209,6 @@ func ValidateLogOpt(cfg map[string]string) error {
 		case splunkInsecureSkipVerifyKey:
 		case envKey:
 		case labelsKey:
-		case tagKey:
 		default:
 			return fmt.Errorf("unknown log opt '%s' for %s log driver", key, driverName)
 		}
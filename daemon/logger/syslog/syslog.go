@@ -4,6 +4,7 @@
 package syslog
 
 import (
+	"bytes"
 	"crypto/tls"
 	"errors"
 	"fmt"
@@ -11,6 +12,7 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -21,12 +23,20 @@ import (
 	"github.com/docker/docker/daemon/logger"
 	"github.com/docker/docker/daemon/logger/loggerutils"
 	"github.com/docker/docker/pkg/urlutil"
+	"github.com/docker/docker/utils/templates"
 	"github.com/docker/go-connections/tlsconfig"
 )
 
 const (
 	name        = "syslog"
 	secureProto = "tcp+tls"
+
+	// structuredDataID is the SD-ID of the structured data element that
+	// carries the container metadata in rfc5424 messages.
+	structuredDataID = "docker@32473"
+
+	rfc5424TimeFormat      = time.RFC3339
+	rfc5424MicroTimeFormat = "2006-01-02T15:04:05.999999Z07:00"
 )
 
 var facilities = map[string]syslog.Priority{
@@ -87,9 +97,23 @@ func rfc5424microformatterWithAppNameAsTag(p syslog.Priority, hostname, tag, con
 	return msg
 }
 
+// rfc5424formatterWithStructuredData returns an rfc5424 formatter, using
+// the tag as appname like the formatters above, that adds the given
+// structured data element to every message.
+func rfc5424formatterWithStructuredData(timeFormat, sd string) syslog.Formatter {
+	return func(p syslog.Priority, hostname, tag, content string) string {
+		timestamp := time.Now().Format(timeFormat)
+		pid := os.Getpid()
+		msg := fmt.Sprintf("<%d>%d %s %s %s %d %s %s %s",
+			p, 1, timestamp, hostname, tag, pid, tag, sd, content)
+		return msg
+	}
+}
+
 // New creates a syslog logger using the configuration passed in on
 // the context. Supported context configuration variables are
-// syslog-address, syslog-facility, syslog-format.
+// syslog-address, syslog-facility, syslog-format, syslog-framing and
+// syslog-structured-data.
 func New(ctx logger.Context) (logger.Logger, error) {
 	tag, err := loggerutils.ParseLogTag(ctx, "{{.ID}}")
 	if err != nil {
@@ -101,7 +125,13 @@ func New(ctx logger.Context) (logger.Logger, error) {
 		return nil, err
 	}
 
-	facility, err := parseFacility(ctx.Config["syslog-facility"])
+	facilityName := ctx.Config["syslog-facility"]
+	if strings.Contains(facilityName, "{{") {
+		if facilityName, err = loggerutils.ParseLogTemplate(ctx, "syslog-facility", facilityName); err != nil {
+			return nil, err
+		}
+	}
+	facility, err := parseFacility(facilityName)
 	if err != nil {
 		return nil, err
 	}
@@ -111,6 +141,26 @@ func New(ctx logger.Context) (logger.Logger, error) {
 		return nil, err
 	}
 
+	if framing := ctx.Config["syslog-framing"]; framing != "" {
+		if syslogFramer, err = parseFraming(framing); err != nil {
+			return nil, err
+		}
+	}
+
+	if ctx.Config["syslog-structured-data"] != "" {
+		enabled, err := strconv.ParseBool(ctx.Config["syslog-structured-data"])
+		if err != nil {
+			return nil, err
+		}
+		if enabled {
+			timeFormat := rfc5424TimeFormat
+			if ctx.Config["syslog-format"] == "rfc5424micro" {
+				timeFormat = rfc5424MicroTimeFormat
+			}
+			syslogFormatter = rfc5424formatterWithStructuredData(timeFormat, structuredData(ctx))
+		}
+	}
+
 	logTag := path.Base(os.Args[0]) + "/" + tag
 
 	var log *syslog.Writer
@@ -183,6 +233,55 @@ func parseAddress(address string) (string, string, error) {
 	return url.Scheme, host, nil
 }
 
+// structuredData builds the rfc5424 structured data element describing the
+// container, including the labels and environment variables selected with
+// the labels and env options.
+func structuredData(ctx logger.Context) string {
+	params := map[string]string{
+		"container_id":   ctx.ContainerID,
+		"container_name": strings.TrimPrefix(ctx.ContainerName, "/"),
+		"image_id":       ctx.ContainerImageID,
+		"image_name":     ctx.ContainerImageName,
+	}
+	for k, v := range ctx.ExtraAttributes(sdParamName) {
+		params[k] = v
+	}
+
+	names := make([]string, 0, len(params))
+	for k := range params {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	buf.WriteString("[" + structuredDataID)
+	for _, k := range names {
+		fmt.Fprintf(&buf, ` %s="%s"`, k, sdParamEscaper.Replace(params[k]))
+	}
+	buf.WriteString("]")
+	return buf.String()
+}
+
+// sdParamEscaper escapes the characters that are not allowed unescaped in
+// an rfc5424 PARAM-VALUE.
+var sdParamEscaper = strings.NewReplacer(`"`, `\"`, `\`, `\\`, `]`, `\]`)
+
+// sdParamName turns a label or environment variable name into a valid
+// rfc5424 PARAM-NAME: at most 32 printable ASCII characters, excluding
+// '=', ' ', ']' and '"'.
+func sdParamName(key string) string {
+	name := []byte(key)
+	for i, c := range name {
+		if c <= ' ' || c > '~' || c == '=' || c == ']' || c == '"' {
+			name[i] = '_'
+		}
+	}
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	return string(name)
+}
+
 // ValidateLogOpt looks for syslog specific log options
 // syslog-address, syslog-facility.
 func ValidateLogOpt(cfg map[string]string) error {
@@ -196,8 +295,9 @@ func ValidateLogOpt(cfg map[string]string) error {
 		case "syslog-tls-cert":
 		case "syslog-tls-key":
 		case "syslog-tls-skip-verify":
-		case "tag":
 		case "syslog-format":
+		case "syslog-framing":
+		case "syslog-structured-data":
 		default:
 			return fmt.Errorf("unknown log opt '%s' for syslog log driver", key)
 		}
@@ -205,15 +305,48 @@ func ValidateLogOpt(cfg map[string]string) error {
 	if _, _, err := parseAddress(cfg["syslog-address"]); err != nil {
 		return err
 	}
-	if _, err := parseFacility(cfg["syslog-facility"]); err != nil {
+	if facility := cfg["syslog-facility"]; strings.Contains(facility, "{{") {
+		// The template can only be rendered once the container exists.
+		if _, err := templates.NewParse("syslog-facility", facility); err != nil {
+			return err
+		}
+	} else if _, err := parseFacility(facility); err != nil {
 		return err
 	}
 	if _, _, err := parseLogFormat(cfg["syslog-format"]); err != nil {
 		return err
 	}
+	if framing := cfg["syslog-framing"]; framing != "" {
+		if _, err := parseFraming(framing); err != nil {
+			return err
+		}
+	}
+	if v := cfg["syslog-structured-data"]; v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid value %q for syslog-structured-data: %v", v, err)
+		}
+		if enabled && !strings.HasPrefix(cfg["syslog-format"], "rfc5424") {
+			return errors.New("syslog-structured-data requires the rfc5424 or rfc5424micro syslog format")
+		}
+	}
 	return nil
 }
 
+// parseFraming returns the framer for the syslog-framing option. Messages
+// are always terminated by a newline; octet counting additionally prefixes
+// them with their length, as described in RFC 6587 and RFC 5425.
+func parseFraming(framing string) (syslog.Framer, error) {
+	switch framing {
+	case "octet-counted":
+		return syslog.RFC5425MessageLengthFramer, nil
+	case "non-transparent":
+		return syslog.DefaultFramer, nil
+	default:
+		return nil, fmt.Errorf("invalid syslog framing %q", framing)
+	}
+}
+
 func parseFacility(facility string) (syslog.Priority, error) {
 	if facility == "" {
 		return syslog.LOG_DAEMON, nil
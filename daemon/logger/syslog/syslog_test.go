@@ -6,6 +6,8 @@ import (
 	syslog "github.com/RackSec/srslog"
 	"reflect"
 	"testing"
+
+	"github.com/docker/docker/daemon/logger"
 )
 
 func functionMatches(expectedFun interface{}, actualFun interface{}) bool {
@@ -49,3 +51,43 @@ func TestValidateLogOptEmpty(t *testing.T) {
 		t.Fatal("Failed to parse empty config", err)
 	}
 }
+
+func TestValidateLogOptStructuredData(t *testing.T) {
+	if err := ValidateLogOpt(map[string]string{
+		"syslog-format":          "rfc5424",
+		"syslog-structured-data": "true",
+		"syslog-framing":         "octet-counted",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateLogOpt(map[string]string{
+		"syslog-format":          "rfc3164",
+		"syslog-structured-data": "true",
+	}); err == nil {
+		t.Fatal("expected structured data to be rejected for rfc3164")
+	}
+	if err := ValidateLogOpt(map[string]string{"syslog-framing": "invalid"}); err == nil {
+		t.Fatal("expected invalid framing to be rejected")
+	}
+	if err := ValidateLogOpt(map[string]string{"syslog-facility": "{{.Name}}"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStructuredData(t *testing.T) {
+	ctx := logger.Context{
+		Config:             map[string]string{"labels": "com.example.team,bad key"},
+		ContainerID:        "container-id",
+		ContainerName:      "/name",
+		ContainerImageID:   "image-id",
+		ContainerImageName: "image",
+		ContainerLabels: map[string]string{
+			"com.example.team": `a"b]c\d`,
+			"bad key":          "v",
+		},
+	}
+	expected := `[docker@32473 bad_key="v" com.example.team="a\"b\]c\\d" container_id="container-id" container_name="name" image_id="image-id" image_name="image"]`
+	if sd := structuredData(ctx); sd != expected {
+		t.Fatalf("expected %s, got %s", expected, sd)
+	}
+}
@@ -90,7 +90,7 @@ func (daemon *Daemon) ContainerLogs(ctx context.Context, containerName string, c
 				return nil
 			}
 			logLine := msg.Line
-			if config.Details {
+			if config.Details && len(msg.Attrs) > 0 {
 				logLine = append([]byte(msg.Attrs.String()+" "), logLine...)
 			}
 			if config.Timestamps {
@@ -124,6 +124,12 @@ func (daemon *Daemon) StartLogging(container *container.Container) error {
 		return fmt.Errorf("Failed to initialize logging driver: %v", err)
 	}
 
+	if n, ok := l.(logger.EventNotifier); ok {
+		n.SetEventNotifier(func(action string, attributes map[string]string) {
+			daemon.LogContainerEventWithAttributes(container, action, attributes)
+		})
+	}
+
 	copier := logger.NewCopier(container.ID, map[string]io.Reader{"stdout": container.StdoutPipe(), "stderr": container.StderrPipe()}, l)
 	container.LogCopier = copier
 	copier.Run()
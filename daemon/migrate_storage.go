@@ -0,0 +1,292 @@
+package daemon
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/layer"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/chrootarchive"
+	"github.com/docker/docker/pkg/idtools"
+	"github.com/docker/docker/pkg/ioutils"
+)
+
+// migrationMarker is the file written in the image directory of the
+// storage driver being migrated to while the migration is in progress. It
+// holds the name of the storage driver migrated from.
+const migrationMarker = "migrate-storage"
+
+// MigrateStorage converts the images and containers of the daemon root of
+// config from the storage driver they are stored with to the storage driver
+// target, writing its progress to out. The storage driver migrated from is
+// the one of the configuration, or the one found in the daemon root.
+//
+// The daemon must not be running. The data of the storage driver migrated
+// from is kept, and an interrupted migration is resumed by running it
+// again: the layers and containers already converted are skipped.
+func MigrateStorage(config *Config, target string, out io.Writer) error {
+	uidMaps, gidMaps, err := setupRemappedRoot(config)
+	if err != nil {
+		return err
+	}
+	rootUID, rootGID, err := idtools.GetRootUIDGID(uidMaps, gidMaps)
+	if err != nil {
+		return err
+	}
+	if err := setupRoots(config, rootUID, rootGID); err != nil {
+		return err
+	}
+
+	source := os.Getenv("DOCKER_DRIVER")
+	if source == "" {
+		source = config.GraphDriver
+	}
+	if source == target {
+		return fmt.Errorf("the images and containers are already stored with %s", target)
+	}
+	newStore := func(driver string) (layer.Store, error) {
+		return layer.NewStoreFromOptions(layer.StoreOptions{
+			StorePath:                 config.imageRoot(),
+			MetadataStorePathTemplate: filepath.Join(config.imageRoot(), "image", "%s", "layerdb"),
+			GraphDriver:               driver,
+			GraphDriverOptions:        config.GraphOptions,
+			UIDMaps:                   uidMaps,
+			GIDMaps:                   gidMaps,
+		})
+	}
+	src, err := newStore(source)
+	if err != nil {
+		return fmt.Errorf("error opening the storage driver %s: %v", source, err)
+	}
+	defer src.Cleanup()
+	source = src.DriverName()
+	if source == target {
+		return fmt.Errorf("the images and containers are already stored with %s", target)
+	}
+
+	targetRoot := filepath.Join(config.imageRoot(), "image", target)
+	if err := startMigration(targetRoot, source); err != nil {
+		return err
+	}
+	dst, err := newStore(target)
+	if err != nil {
+		return fmt.Errorf("error opening the storage driver %s: %v", target, err)
+	}
+	defer dst.Cleanup()
+
+	fmt.Fprintf(out, "Migrating from %s to %s\n", source, target)
+	layers := layersByDepth(src.Map())
+	for i, l := range layers {
+		fmt.Fprintf(out, "Layer %d/%d %s\n", i+1, len(layers), l.ChainID())
+		if err := migrateLayer(src, dst, l); err != nil {
+			return fmt.Errorf("error migrating layer %s: %v", l.ChainID(), err)
+		}
+	}
+
+	initFunc := func(path string) error {
+		return setupInitLayer(path, rootUID, rootGID)
+	}
+	containersRoot := filepath.Join(config.containerRoot(), "containers")
+	dirs, err := ioutil.ReadDir(containersRoot)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i, d := range dirs {
+		c := container.NewBaseContainer(d.Name(), filepath.Join(containersRoot, d.Name()))
+		if err := c.FromDisk(); err != nil {
+			fmt.Fprintf(out, "Container %d/%d %s: skipped, failed to load: %v\n", i+1, len(dirs), d.Name(), err)
+			continue
+		}
+		fmt.Fprintf(out, "Container %d/%d %s\n", i+1, len(dirs), c.ID)
+		if c.Driver == target {
+			continue
+		}
+		if c.Driver != source {
+			fmt.Fprintf(out, "Container %s: skipped, stored with %s\n", c.ID, c.Driver)
+			continue
+		}
+		if c.IsRunning() {
+			return fmt.Errorf("container %s is running, stop it to migrate it", c.ID)
+		}
+		if err := migrateContainer(src, dst, c, initFunc); err != nil {
+			return fmt.Errorf("error migrating container %s: %v", c.ID, err)
+		}
+		c.Driver = target
+		if err := c.ToDisk(); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintln(out, "Image metadata")
+	if err := copyImageMetadata(filepath.Join(config.imageRoot(), "image", source), targetRoot); err != nil {
+		return fmt.Errorf("error copying the image metadata: %v", err)
+	}
+	if err := os.Remove(filepath.Join(targetRoot, migrationMarker)); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Migrated %d layers and %d containers from %s to %s. The data of %s is kept until removed.\n", len(layers), len(dirs), source, target, source)
+	return nil
+}
+
+// startMigration marks the image directory targetRoot of the storage driver
+// migrated to as being migrated from source. It fails if the storage driver
+// already has images of its own, or is being migrated from another storage
+// driver.
+func startMigration(targetRoot, source string) error {
+	marker := filepath.Join(targetRoot, migrationMarker)
+	b, err := ioutil.ReadFile(marker)
+	if err == nil {
+		if from := strings.TrimSpace(string(b)); from != source {
+			return fmt.Errorf("a migration from %s to %s is in progress, resume it first", from, filepath.Base(targetRoot))
+		}
+		return nil
+	}
+	if !os.IsNotExist(err) {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(targetRoot, "repositories.json")); err == nil {
+		return fmt.Errorf("the storage driver %s already has images, remove %s to migrate to it", filepath.Base(targetRoot), targetRoot)
+	}
+	if err := os.MkdirAll(targetRoot, 0700); err != nil {
+		return err
+	}
+	return ioutils.AtomicWriteFile(marker, []byte(source+"\n"), 0600)
+}
+
+// layersByDepth returns the layers of m, the parents before their children.
+func layersByDepth(m map[layer.ChainID]layer.Layer) []layer.Layer {
+	layers := byDepth{}
+	for _, l := range m {
+		d := 0
+		for p := l.Parent(); p != nil; p = p.Parent() {
+			d++
+		}
+		layers.layers = append(layers.layers, l)
+		layers.depths = append(layers.depths, d)
+	}
+	sort.Sort(layers)
+	return layers.layers
+}
+
+type byDepth struct {
+	layers []layer.Layer
+	depths []int
+}
+
+func (b byDepth) Len() int { return len(b.layers) }
+func (b byDepth) Swap(i, j int) {
+	b.layers[i], b.layers[j] = b.layers[j], b.layers[i]
+	b.depths[i], b.depths[j] = b.depths[j], b.depths[i]
+}
+func (b byDepth) Less(i, j int) bool {
+	if b.depths[i] != b.depths[j] {
+		return b.depths[i] < b.depths[j]
+	}
+	return b.layers[i].ChainID() < b.layers[j].ChainID()
+}
+
+// migrateLayer registers the content of the layer l of src in dst, unless
+// it already is.
+func migrateLayer(src, dst layer.Store, l layer.Layer) error {
+	if _, err := dst.Get(l.ChainID()); err == nil {
+		return nil
+	}
+	var parent layer.ChainID
+	if p := l.Parent(); p != nil {
+		parent = p.ChainID()
+	}
+	ts, err := l.TarStream()
+	if err != nil {
+		return err
+	}
+	defer ts.Close()
+	nl, err := dst.Register(ts, parent)
+	if err != nil {
+		return err
+	}
+	if nl.ChainID() != l.ChainID() {
+		return fmt.Errorf("the layer was migrated as %s", nl.ChainID())
+	}
+	return nil
+}
+
+// migrateContainer creates the read-write layer of c in dst, and copies its
+// changes from src into it. The read-write layer left by an interrupted
+// migration of c is recreated.
+func migrateContainer(src, dst layer.Store, c *container.Container, initFunc layer.MountInit) error {
+	rwLayer, err := src.GetRWLayer(c.ID)
+	if err != nil {
+		return err
+	}
+	var parent layer.ChainID
+	if p := rwLayer.Parent(); p != nil {
+		parent = p.ChainID()
+	}
+	if partial, err := dst.GetRWLayer(c.ID); err == nil {
+		if _, err := dst.ReleaseRWLayer(partial); err != nil {
+			return err
+		}
+	}
+
+	var storageOpt map[string]string
+	if c.HostConfig != nil {
+		storageOpt = c.HostConfig.StorageOpt
+	}
+	nl, err := dst.CreateRWLayer(c.ID, parent, c.MountLabel, initFunc, storageOpt)
+	if err != nil {
+		return err
+	}
+	ts, err := rwLayer.TarStream()
+	if err != nil {
+		return err
+	}
+	defer ts.Close()
+	path, err := nl.Mount(c.MountLabel)
+	if err != nil {
+		return err
+	}
+	if _, err := chrootarchive.ApplyLayer(path, ts); err != nil {
+		nl.Unmount()
+		return err
+	}
+	return nl.Unmount()
+}
+
+// copyImageMetadata copies the image metadata of the storage driver, all of
+// its image directory sourceRoot but the layers, to the image directory
+// targetRoot of the storage driver migrated to.
+func copyImageMetadata(sourceRoot, targetRoot string) error {
+	entries, err := ioutil.ReadDir(sourceRoot)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.Name() == "layerdb" || e.Name() == migrationMarker {
+			continue
+		}
+		target := filepath.Join(targetRoot, e.Name())
+		if err := os.RemoveAll(target); err != nil {
+			return err
+		}
+		if e.IsDir() {
+			if err := archive.CopyWithTar(filepath.Join(sourceRoot, e.Name()), target); err != nil {
+				return err
+			}
+			continue
+		}
+		b, err := ioutil.ReadFile(filepath.Join(sourceRoot, e.Name()))
+		if err != nil {
+			return err
+		}
+		if err := ioutils.AtomicWriteFile(target, b, e.Mode()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/layer"
+)
+
+type fakeLayer struct {
+	layer.Layer
+	chainID layer.ChainID
+	parent  layer.Layer
+}
+
+func (l *fakeLayer) ChainID() layer.ChainID { return l.chainID }
+
+func (l *fakeLayer) Parent() layer.Layer {
+	if l.parent == nil {
+		// a nil *fakeLayer is not a nil layer.Layer
+		return nil
+	}
+	return l.parent
+}
+
+func TestLayersByDepth(t *testing.T) {
+	base := &fakeLayer{chainID: "sha256:d"}
+	a := &fakeLayer{chainID: "sha256:c", parent: base}
+	b := &fakeLayer{chainID: "sha256:b", parent: base}
+	top := &fakeLayer{chainID: "sha256:a", parent: a}
+	m := map[layer.ChainID]layer.Layer{}
+	for _, l := range []*fakeLayer{top, a, b, base} {
+		m[l.chainID] = l
+	}
+
+	var order []layer.ChainID
+	for _, l := range layersByDepth(m) {
+		order = append(order, l.ChainID())
+	}
+	expected := []layer.ChainID{"sha256:d", "sha256:b", "sha256:c", "sha256:a"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Fatalf("Expected the layers in the order %v, got %v", expected, order)
+	}
+}
+
+func TestStartMigration(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "migrate-storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	targetRoot := filepath.Join(tmp, "image", "overlay2")
+
+	if err := startMigration(targetRoot, "aufs"); err != nil {
+		t.Fatal(err)
+	}
+	// resuming the migration
+	if err := startMigration(targetRoot, "aufs"); err != nil {
+		t.Fatalf("Expected the migration to be resumed, got %v", err)
+	}
+	if err := startMigration(targetRoot, "devicemapper"); err == nil {
+		t.Fatal("Expected a migration from another storage driver to fail")
+	}
+
+	// the storage driver has images of its own
+	if err := os.Remove(filepath.Join(targetRoot, migrationMarker)); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(targetRoot, "repositories.json"), []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := startMigration(targetRoot, "aufs"); err == nil {
+		t.Fatal("Expected a migration to a storage driver with images to fail")
+	}
+}
+
+func TestCopyImageMetadata(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "migrate-storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	sourceRoot := filepath.Join(tmp, "aufs")
+	targetRoot := filepath.Join(tmp, "overlay2")
+	for _, f := range []string{
+		"imagedb/content/sha256/abc",
+		"distribution/v2metadata-by-diffid/sha256/def",
+		"layerdb/sha256/abc/diff",
+		"repositories.json",
+		"buildcache.json",
+	} {
+		if err := os.MkdirAll(filepath.Join(sourceRoot, filepath.Dir(f)), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(sourceRoot, f), []byte(f), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the layers are already migrated, and a previous copy was interrupted
+	for _, f := range []string{"layerdb/sha256/123/diff", "imagedb/content/sha256/partial"} {
+		if err := os.MkdirAll(filepath.Join(targetRoot, filepath.Dir(f)), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(targetRoot, f), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := copyImageMetadata(sourceRoot, targetRoot); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{
+		"imagedb/content/sha256/abc",
+		"distribution/v2metadata-by-diffid/sha256/def",
+		"repositories.json",
+		"buildcache.json",
+	} {
+		b, err := ioutil.ReadFile(filepath.Join(targetRoot, f))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != f {
+			t.Fatalf("Expected %s to be copied, got %q", f, b)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(targetRoot, "layerdb/sha256/abc")); !os.IsNotExist(err) {
+		t.Fatalf("Expected the layers not to be copied, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(targetRoot, "layerdb/sha256/123")); err != nil {
+		t.Fatalf("Expected the migrated layers to be kept, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(targetRoot, "imagedb/content/sha256/partial")); !os.IsNotExist(err) {
+		t.Fatalf("Expected the interrupted copy to be replaced, got %v", err)
+	}
+}
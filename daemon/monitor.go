@@ -10,6 +10,7 @@ import (
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/libcontainerd"
 	"github.com/docker/docker/runconfig"
+	"github.com/docker/engine-api/types"
 )
 
 // StateChanged updates daemon state changes from containerd
@@ -32,10 +33,15 @@ func (daemon *Daemon) StateChanged(id string, e libcontainerd.StateInfo) error {
 		c.Wait()
 		c.Reset(false)
 		c.SetStopped(platformConstructExitStatus(e))
+		setJobCompleted(c)
 		attributes := map[string]string{
 			"exitCode": strconv.Itoa(int(e.ExitCode)),
 		}
+		if c.HostConfig.JobMode {
+			attributes["jobStatus"] = c.JobStatus
+		}
 		daemon.LogContainerEventWithAttributes(c, "die", attributes)
+		daemon.runLifecycleHooks(c, hookDie)
 		daemon.Cleanup(c)
 		// FIXME: here is race condition between two RUN instructions in Dockerfile
 		// because they share same runconfig and change image. Must be fixed
@@ -54,6 +60,7 @@ func (daemon *Daemon) StateChanged(id string, e libcontainerd.StateInfo) error {
 			"exitCode": strconv.Itoa(int(e.ExitCode)),
 		}
 		daemon.LogContainerEventWithAttributes(c, "die", attributes)
+		daemon.runLifecycleHooks(c, hookDie)
 		return c.ToDisk()
 	case libcontainerd.StateExitProcess:
 		c.Lock()
@@ -66,6 +73,14 @@ func (daemon *Daemon) StateChanged(id string, e libcontainerd.StateInfo) error {
 			if err := execConfig.CloseStreams(); err != nil {
 				logrus.Errorf("%s: %s", c.ID, err)
 			}
+			if execConfig.NanoCPUs != 0 || execConfig.Memory != 0 {
+				daemon.cleanupExecResources(c, execConfig)
+			}
+			daemon.recordExec(c, execConfig)
+			daemon.LogContainerEventWithAttributes(c, "exec_die", map[string]string{
+				"execID":   execConfig.ID,
+				"exitCode": strconv.Itoa(ec),
+			})
 
 			// remove the exec command from the container's store only and not the
 			// daemon's store so that the exec command can be inspected.
@@ -74,17 +89,32 @@ func (daemon *Daemon) StateChanged(id string, e libcontainerd.StateInfo) error {
 			logrus.Warnf("Ignoring StateExitProcess for %v but no exec command found", e)
 		}
 	case libcontainerd.StateStart, libcontainerd.StateRestore:
+		c.Lock()
+		defer c.Unlock()
 		c.SetRunning(int(e.Pid), e.State == libcontainerd.StateStart)
+		if e.State == libcontainerd.StateStart {
+			if timings := daemon.startTimers.running(c.ID); timings != nil {
+				c.StartTimings = timings
+				observeStartTimings(timings)
+			}
+		}
 		c.HasBeenManuallyStopped = false
+		if c.HostConfig.JobMode {
+			c.JobStatus = types.JobStatusRunning
+		}
 		if err := c.ToDisk(); err != nil {
 			c.Reset(false)
 			return err
 		}
 		daemon.LogContainerEvent(c, "start")
 	case libcontainerd.StatePause:
+		c.Lock()
+		defer c.Unlock()
 		c.Paused = true
 		daemon.LogContainerEvent(c, "pause")
 	case libcontainerd.StateResume:
+		c.Lock()
+		defer c.Unlock()
 		c.Paused = false
 		daemon.LogContainerEvent(c, "unpause")
 	}
@@ -104,8 +134,15 @@ func (daemon *Daemon) AttachStreams(id string, iop libcontainerd.IOPipe) error {
 		s = ec.StreamConfig
 	} else {
 		s = c.StreamConfig
-		if err := daemon.StartLogging(c); err != nil {
+		daemon.startTimers.attached(c.ID)
+		c.Lock()
+		err := daemon.StartLogging(c)
+		// the start of a container being created resets it on failure
+		if err != nil && !c.IsStarting() {
 			c.Reset(false)
+		}
+		c.Unlock()
+		if err != nil {
 			return err
 		}
 	}
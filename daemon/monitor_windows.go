@@ -5,6 +5,7 @@ import (
 
 	"github.com/docker/docker/container"
 	"github.com/docker/docker/libcontainerd"
+	"golang.org/x/net/context"
 )
 
 // platformConstructExitStatus returns a platform specific exit status structure
@@ -28,7 +29,7 @@ func (daemon *Daemon) postRunProcessing(container *container.Container, e libcon
 
 		// Create a new servicing container, which will start, complete the update, and merge back the
 		// results if it succeeded, all as part of the below function call.
-		if err := daemon.containerd.Create((container.ID + "_servicing"), *spec, servicingOption); err != nil {
+		if err := daemon.containerd.Create(context.Background(), (container.ID + "_servicing"), *spec, servicingOption); err != nil {
 			return fmt.Errorf("Post-run update servicing failed: %s", err)
 		}
 	}
@@ -5,9 +5,11 @@ import (
 	"net"
 	"strings"
 
+	"github.com/docker/docker/api/types/backend"
 	netsettings "github.com/docker/docker/daemon/network"
 	"github.com/docker/docker/errors"
 	"github.com/docker/docker/runconfig"
+	runconfigopts "github.com/docker/docker/runconfig/opts"
 	"github.com/docker/engine-api/types"
 	"github.com/docker/engine-api/types/filters"
 	"github.com/docker/engine-api/types/network"
@@ -134,6 +136,15 @@ func (daemon *Daemon) CreateNetwork(create types.NetworkCreateRequest) (*types.N
 	if create.Internal {
 		nwOptions = append(nwOptions, libnetwork.NetworkOptionInternalNetwork())
 	}
+	if create.Isolated {
+		rules, err := runconfigopts.ParseIsolationRules(create.IsolationAllow)
+		if err != nil {
+			return nil, errors.NewBadRequestError(err)
+		}
+		nwOptions = append(nwOptions, libnetwork.NetworkOptionIsolation(rules))
+	} else if len(create.IsolationAllow) > 0 {
+		return nil, errors.NewBadRequestError(fmt.Errorf("isolation rules require an isolated network"))
+	}
 	n, err := c.NewNetwork(driver, create.Name, "", nwOptions...)
 	if err != nil {
 		return nil, err
@@ -176,6 +187,12 @@ func (daemon *Daemon) ConnectContainerToNetwork(containerName, networkName strin
 	if err != nil {
 		return err
 	}
+	// the containers of a user only join the networks of the user
+	if owner := container.Config.Labels[backend.OwnerLabel]; owner != "" && daemon.configStore.Tenancy {
+		if err := daemon.CheckTenant("networks", networkName, owner); err != nil {
+			return err
+		}
+	}
 	return daemon.ConnectToNetwork(container, networkName, endpointConfig)
 }
 
@@ -0,0 +1,57 @@
+package daemon
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/docker/libkv/store"
+	"github.com/docker/libkv/store/boltdb"
+	"github.com/docker/libnetwork/datastore"
+)
+
+// recordedInterface is the interface of an endpoint recorded in the local
+// store of the network controller.
+type recordedInterface struct {
+	SrcName string `json:"srcName"`
+	MAC     string `json:"mac"`
+	Addr    string `json:"addr"`
+}
+
+// recordedInterfaces returns the interfaces of the endpoints recorded in the
+// local store of the network controller of the daemon with root directory
+// root. These are the only networking artifacts left behind by an unclean
+// shutdown that the daemon can tell it created. It must run before the
+// network controller is initialized, as the controller then removes the
+// endpoints of the containers that were running.
+func recordedInterfaces(root string) ([]recordedInterface, error) {
+	path := filepath.Join(root, "network", "files", "local-kv.db")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	kv, err := boltdb.New([]string{path}, &store.Config{Bucket: "libnetwork", ConnectionTimeout: 10 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	defer kv.Close()
+
+	pairs, err := kv.List(datastore.Key(datastore.EndpointKeyPrefix))
+	if err == store.ErrKeyNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var ifaces []recordedInterface
+	for _, pair := range pairs {
+		var ep struct {
+			Iface *recordedInterface `json:"ep_iface"`
+		}
+		if err := json.Unmarshal(pair.Value, &ep); err != nil || ep.Iface == nil {
+			continue
+		}
+		ifaces = append(ifaces, *ep.Iface)
+	}
+	return ifaces, nil
+}
@@ -0,0 +1,74 @@
+package daemon
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/libnetwork/drivers/bridge"
+	"github.com/vishvananda/netlink"
+)
+
+// generatedBridgeName matches the names the bridge driver gives to the
+// bridges of user-defined networks.
+var generatedBridgeName = regexp.MustCompile(`^br-[0-9a-f]{12}$`)
+
+// reclaimStaleNetworkResources removes the bridges of networks that no
+// longer exist, and the veth pairs of the recorded endpoints that were never
+// moved to the network namespace of their container, which an unclean
+// shutdown can leave behind. The veth pairs created by other software are
+// left alone. It must run before any container is started. With dryRun, the
+// stale interfaces are only reported.
+func (daemon *Daemon) reclaimStaleNetworkResources(recorded []recordedInterface, dryRun bool) {
+	links, err := netlink.LinkList()
+	if err != nil {
+		logrus.Warnf("Could not list network interfaces to look for stale ones: %v", err)
+		return
+	}
+
+	// The host end of a veth pair is named by the bridge driver, which
+	// does not record it, but the other end is recorded as the source
+	// interface of the endpoint. Deleting it deletes its peer as well.
+	veths := make(map[string]bool)
+	for _, iface := range recorded {
+		if strings.HasPrefix(iface.SrcName, "veth") {
+			veths[iface.SrcName] = true
+		}
+	}
+
+	inUse := make(map[string]bool)
+	for _, nw := range daemon.netController.Networks() {
+		if nw.Type() != "bridge" {
+			continue
+		}
+		if name, ok := nw.Info().DriverOptions()[bridge.BridgeName]; ok {
+			inUse[name] = true
+		}
+		inUse["br-"+nw.ID()[:12]] = true
+	}
+
+	for _, link := range links {
+		attrs := link.Attrs()
+		var stale bool
+		switch link.Type() {
+		case "bridge":
+			stale = generatedBridgeName.MatchString(attrs.Name) && !inUse[attrs.Name]
+		case "veth":
+			stale = veths[attrs.Name] && attrs.MasterIndex == 0
+		}
+		if !stale {
+			continue
+		}
+		if dryRun {
+			logrus.Infof("Found stale %s interface %s", link.Type(), attrs.Name)
+			continue
+		}
+		// Deleting one end of a veth pair also deletes its peer, which
+		// may still be in the list.
+		if err := netlink.LinkDel(link); err != nil && !strings.Contains(err.Error(), "no such device") {
+			logrus.Warnf("Could not remove stale %s interface %s: %v", link.Type(), attrs.Name, err)
+			continue
+		}
+		logrus.Infof("Removed stale %s interface %s", link.Type(), attrs.Name)
+	}
+}
@@ -0,0 +1,51 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/libkv/store"
+	"github.com/docker/libkv/store/boltdb"
+	"github.com/docker/libnetwork/datastore"
+)
+
+func TestRecordedInterfaces(t *testing.T) {
+	root, err := ioutil.TempDir("", "recorded-interfaces")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	if ifaces, err := recordedInterfaces(root); err != nil || len(ifaces) != 0 {
+		t.Fatalf("expected no interface without a store, got %v, %v", ifaces, err)
+	}
+
+	dir := filepath.Join(root, "network", "files")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	kv, err := boltdb.New([]string{filepath.Join(dir, "local-kv.db")}, &store.Config{Bucket: "libnetwork"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, value := range map[string]string{
+		datastore.Key(datastore.EndpointKeyPrefix, "n1", "e1"): `{"id":"e1","ep_iface":{"srcName":"veth1a2b3c4","mac":"02:42:ac:11:00:02","addr":"172.17.0.2/16"}}`,
+		datastore.Key(datastore.NetworkKeyPrefix, "n1"):        `{"id":"n1"}`,
+	} {
+		if err := kv.Put(key, []byte(value), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	kv.Close()
+
+	ifaces, err := recordedInterfaces(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := recordedInterface{SrcName: "veth1a2b3c4", MAC: "02:42:ac:11:00:02", Addr: "172.17.0.2/16"}
+	if len(ifaces) != 1 || ifaces[0] != expected {
+		t.Fatalf("expected %v, got %v", expected, ifaces)
+	}
+}
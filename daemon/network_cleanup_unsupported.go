@@ -0,0 +1,6 @@
+// +build !linux,!windows
+
+package daemon
+
+func (daemon *Daemon) reclaimStaleNetworkResources(recorded []recordedInterface, dryRun bool) {
+}
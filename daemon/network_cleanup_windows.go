@@ -0,0 +1,64 @@
+package daemon
+
+import (
+	"net"
+	"strings"
+
+	"github.com/Microsoft/hcsshim"
+	"github.com/Sirupsen/logrus"
+	winlibnetwork "github.com/docker/libnetwork/drivers/windows"
+)
+
+// reclaimStaleNetworkResources removes the HNS endpoints left on the daemon's
+// networks by containers that were running when the daemon last stopped.
+// HNS networks themselves are reconciled with the daemon's networks when the
+// network controller is initialized. Only the HNS endpoints matching the
+// MAC and IP addresses of a recorded endpoint are removed, the others may
+// belong to containers run by other software. With dryRun, the stale
+// endpoints are only reported.
+func (daemon *Daemon) reclaimStaleNetworkResources(recorded []recordedInterface, dryRun bool) {
+	endpoints, err := hcsshim.HNSListEndpointRequest("GET", "", "")
+	if err != nil {
+		logrus.Warnf("Could not list HNS endpoints to look for stale ones: %v", err)
+		return
+	}
+
+	networks := make(map[string]bool)
+	for _, nw := range daemon.netController.Networks() {
+		if hnsid, ok := nw.Info().DriverOptions()[winlibnetwork.HNSID]; ok {
+			networks[strings.ToLower(hnsid)] = true
+		}
+	}
+
+	// HNS writes MAC addresses with dashes, and IP addresses without
+	// prefix length.
+	created := make(map[string]bool)
+	for _, iface := range recorded {
+		if iface.MAC == "" || iface.Addr == "" {
+			continue
+		}
+		ip, _, err := net.ParseCIDR(iface.Addr)
+		if err != nil {
+			continue
+		}
+		created[strings.ToLower(strings.Replace(iface.MAC, ":", "-", -1))+"|"+ip.String()] = true
+	}
+
+	for _, ep := range endpoints {
+		if !networks[strings.ToLower(ep.VirtualNetwork)] {
+			continue
+		}
+		if !created[strings.ToLower(ep.MacAddress)+"|"+ep.IPAddress.String()] {
+			continue
+		}
+		if dryRun {
+			logrus.Infof("Found stale HNS endpoint %s on network %s", ep.Id, ep.VirtualNetworkName)
+			continue
+		}
+		if _, err := hcsshim.HNSEndpointRequest("DELETE", ep.Id, ""); err != nil {
+			logrus.Warnf("Could not remove stale HNS endpoint %s: %v", ep.Id, err)
+			continue
+		}
+		logrus.Infof("Removed stale HNS endpoint %s on network %s", ep.Id, ep.VirtualNetworkName)
+	}
+}
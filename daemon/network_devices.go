@@ -0,0 +1,71 @@
+package daemon
+
+import (
+	"fmt"
+	"strings"
+
+	containertypes "github.com/docker/engine-api/types/container"
+)
+
+// maxInterfaceName is the max length of the name of a network interface.
+const maxInterfaceName = 15
+
+// validateInterfaceName checks that name can be the name of a network
+// interface.
+func validateInterfaceName(name string) error {
+	if name == "" || len(name) > maxInterfaceName || name == "." || name == ".." || strings.ContainsAny(name, "/: \t\n") {
+		return fmt.Errorf("invalid network interface name %q", name)
+	}
+	return nil
+}
+
+// verifyNetworkDevices checks the network interfaces of the host that
+// hostConfig moves into the container. The container needs a network
+// namespace of its own, and a given interface can only be moved once.
+func verifyNetworkDevices(hostConfig *containertypes.HostConfig, config *containertypes.Config) error {
+	if len(hostConfig.NetworkDevices) == 0 {
+		return nil
+	}
+	if hostConfig.NetworkMode.IsHost() || hostConfig.NetworkMode.IsContainer() {
+		return fmt.Errorf("Network devices cannot be moved into a container that shares the network namespace of the host or of another container")
+	}
+	if config != nil && config.NetworkDisabled {
+		return fmt.Errorf("Network devices cannot be moved into a container with networking disabled")
+	}
+
+	devices := make(map[string]bool)
+	names := make(map[string]bool)
+	for _, d := range hostConfig.NetworkDevices {
+		if err := validateInterfaceName(d.Name); err != nil {
+			return fmt.Errorf("Invalid network device: %v", err)
+		}
+		device := d.Name
+		if d.VirtualFunction != nil {
+			if *d.VirtualFunction < 0 {
+				return fmt.Errorf("Invalid network device %s: the index of the virtual function must not be negative", d.Name)
+			}
+			device = fmt.Sprintf("%s/vf%d", d.Name, *d.VirtualFunction)
+		}
+		if devices[device] {
+			return fmt.Errorf("Duplicate network device %s", device)
+		}
+		devices[device] = true
+
+		if d.ContainerName != "" {
+			if err := validateInterfaceName(d.ContainerName); err != nil {
+				return fmt.Errorf("Invalid network device %s: %v", device, err)
+			}
+		}
+		// A virtual function keeps its own name on the host.
+		if name := d.ContainerName; name != "" || d.VirtualFunction == nil {
+			if name == "" {
+				name = d.Name
+			}
+			if names[name] {
+				return fmt.Errorf("Duplicate network device name %s in the container", name)
+			}
+			names[name] = true
+		}
+	}
+	return nil
+}
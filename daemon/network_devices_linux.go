@@ -0,0 +1,254 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/pkg/reexec"
+	containertypes "github.com/docker/engine-api/types/container"
+	"github.com/opencontainers/runc/libcontainer/configs"
+	"github.com/opencontainers/specs/specs-go"
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+)
+
+const (
+	// networkDevicesHookName is the name of the prestart hook that moves the
+	// network devices of a container into its network namespace.
+	networkDevicesHookName = "docker-network-devices"
+	// networkDevicesStateFile is the file of the container root that lists
+	// the network devices moved into the container.
+	networkDevicesStateFile = "network-devices.json"
+)
+
+// movedNetworkDevice is a network interface moved into a container.
+type movedNetworkDevice struct {
+	HostName      string
+	ContainerName string
+}
+
+func init() {
+	reexec.Register(networkDevicesHookName, networkDevicesHook)
+}
+
+// networkDevicesSpecHook returns the prestart hook that moves the network
+// devices of c into its network namespace. It must run after the network
+// namespace is set up, so that the names of the interfaces of the
+// container are known.
+func networkDevicesSpecHook(c *container.Container) (specs.Hook, error) {
+	devices, err := json.Marshal(c.HostConfig.NetworkDevices)
+	if err != nil {
+		return specs.Hook{}, err
+	}
+	target, err := os.Readlink(filepath.Join("/proc", strconv.Itoa(os.Getpid()), "exe"))
+	if err != nil {
+		return specs.Hook{}, err
+	}
+	return specs.Hook{
+		Path: target,
+		Args: []string{networkDevicesHookName, c.ID, filepath.Join(c.Root, networkDevicesStateFile), string(devices)},
+	}, nil
+}
+
+// networkDevicesHook is the prestart hook that moves network devices into
+// the network namespace of a container. It expects 4 args {[0]=name,
+// [1]=<container-id>, [2]=<state file>, [3]=<devices as JSON>}, and
+// configs.HookState as JSON on its standard input.
+func networkDevicesHook() {
+	if err := runNetworkDevicesHook(); err != nil {
+		fmt.Fprint(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func runNetworkDevicesHook() error {
+	if len(os.Args) != 4 {
+		return fmt.Errorf("%s expects 4 args, received: %d", networkDevicesHookName, len(os.Args))
+	}
+	var devices []containertypes.NetworkDevice
+	if err := json.Unmarshal([]byte(os.Args[3]), &devices); err != nil {
+		return err
+	}
+	var state configs.HookState
+	if err := json.NewDecoder(os.Stdin).Decode(&state); err != nil {
+		return err
+	}
+	return moveNetworkDevices(os.Args[1], os.Args[2], state.Pid, devices)
+}
+
+// moveNetworkDevices moves devices into the network namespace of pid, and
+// records them in stateFile so that they can be moved back to the host.
+// The devices recorded already are skipped: the network namespace of a
+// container is kept when the container is restarted by its restart policy.
+func moveNetworkDevices(id, stateFile string, pid int, devices []containertypes.NetworkDevice) error {
+	var moved []movedNetworkDevice
+	if data, err := ioutil.ReadFile(stateFile); err == nil {
+		if err := json.Unmarshal(data, &moved); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	hostNs, err := netns.Get()
+	if err != nil {
+		return err
+	}
+	defer hostNs.Close()
+	defer netns.Set(hostNs)
+	containerNs, err := netns.GetFromPid(pid)
+	if err != nil {
+		return err
+	}
+	defer containerNs.Close()
+
+	for i := len(moved); i < len(devices); i++ {
+		d := devices[i]
+		name, err := networkDeviceHostName(d)
+		if err != nil {
+			return err
+		}
+		containerName := d.ContainerName
+		if containerName == "" {
+			containerName = name
+		}
+		// The device is renamed while it is moved so that its name
+		// conflicts neither with the interfaces of the container nor with
+		// the devices moved back to the host.
+		if err := moveLink(name, tmpNetworkDeviceName(id, i), containerName, hostNs, containerNs, true); err != nil {
+			return fmt.Errorf("Error moving network device %s into the container: %v", name, err)
+		}
+		moved = append(moved, movedNetworkDevice{HostName: name, ContainerName: containerName})
+		data, err := json.Marshal(moved)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(stateFile, data, 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreNetworkDevices moves the network devices of c back to the host,
+// with their names on the host. key is the path of the network namespace of
+// the container, which must still exist.
+func (daemon *Daemon) restoreNetworkDevices(c *container.Container, key string) {
+	stateFile := filepath.Join(c.Root, networkDevicesStateFile)
+	data, err := ioutil.ReadFile(stateFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logrus.Warnf("Error reading the network devices of container %s: %v", c.ID, err)
+		}
+		return
+	}
+	var moved []movedNetworkDevice
+	if err := json.Unmarshal(data, &moved); err != nil {
+		logrus.Warnf("Error reading the network devices of container %s: %v", c.ID, err)
+		return
+	}
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	hostNs, err := netns.Get()
+	if err != nil {
+		logrus.Warnf("Error moving the network devices of container %s back to the host: %v", c.ID, err)
+		return
+	}
+	defer hostNs.Close()
+	// The thread must be back in the network namespace of the host before
+	// it is unlocked.
+	defer netns.Set(hostNs)
+	containerNs, err := netns.GetFromPath(key)
+	if err != nil {
+		logrus.Warnf("Error moving the network devices of container %s back to the host: %v", c.ID, err)
+		return
+	}
+	defer containerNs.Close()
+
+	for i, d := range moved {
+		if err := moveLink(d.ContainerName, tmpNetworkDeviceName(c.ID, i), d.HostName, containerNs, hostNs, false); err != nil {
+			logrus.Warnf("Error moving network device %s of container %s back to the host: %v", d.HostName, c.ID, err)
+		}
+	}
+	if err := os.Remove(stateFile); err != nil {
+		logrus.Warnf("Error removing the network devices of container %s: %v", c.ID, err)
+	}
+}
+
+// moveLink moves the link name of the network namespace from to the
+// network namespace to, where it is named newName, and set up if up is
+// true. The link is named tmpName while it is moved. The calling thread must
+// be locked, and is left in from.
+func moveLink(name, tmpName, newName string, from, to netns.NsHandle, up bool) error {
+	if err := netns.Set(from); err != nil {
+		return err
+	}
+	link, err := netlink.LinkByName(name)
+	if err != nil {
+		return err
+	}
+	if err := netlink.LinkSetDown(link); err != nil {
+		return err
+	}
+	if err := netlink.LinkSetName(link, tmpName); err != nil {
+		return err
+	}
+	if err := netlink.LinkSetNsFd(link, int(to)); err != nil {
+		netlink.LinkSetName(link, name)
+		return err
+	}
+
+	if err := netns.Set(to); err != nil {
+		return err
+	}
+	defer netns.Set(from)
+	if link, err = netlink.LinkByName(tmpName); err != nil {
+		return err
+	}
+	if err := netlink.LinkSetName(link, newName); err != nil {
+		return fmt.Errorf("cannot rename the device to %s: %v", newName, err)
+	}
+	if !up {
+		return nil
+	}
+	return netlink.LinkSetUp(link)
+}
+
+// tmpNetworkDeviceName returns the name of the i-th network device of the
+// container id while it is moved.
+func tmpNetworkDeviceName(id string, i int) string {
+	return fmt.Sprintf("dnd%.8s%d", id, i)
+}
+
+// networkDeviceHostName returns the name on the host of the interface of
+// d, which is the interface of the virtual function of d if it has one.
+func networkDeviceHostName(d containertypes.NetworkDevice) (string, error) {
+	if d.VirtualFunction == nil {
+		return d.Name, nil
+	}
+	dir := filepath.Join("/sys/class/net", d.Name, "device", fmt.Sprintf("virtfn%d", *d.VirtualFunction), "net")
+	names, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("%s has no virtual function %d with a network interface on the host", d.Name, *d.VirtualFunction)
+		}
+		return "", err
+	}
+	if len(names) != 1 {
+		return "", fmt.Errorf("virtual function %d of %s has %d network interfaces on the host", *d.VirtualFunction, d.Name, len(names))
+	}
+	return names[0].Name(), nil
+}
@@ -0,0 +1,47 @@
+package daemon
+
+import (
+	"testing"
+
+	containertypes "github.com/docker/engine-api/types/container"
+)
+
+func TestVerifyNetworkDevices(t *testing.T) {
+	vf0, vf1, negative := 0, 1, -1
+	valid := [][]containertypes.NetworkDevice{
+		nil,
+		{{Name: "eth1"}, {Name: "eth2", ContainerName: "net0"}},
+		// The virtual functions keep their name on the host by default.
+		{{Name: "ens2f0", VirtualFunction: &vf0}, {Name: "ens2f0", VirtualFunction: &vf1}, {Name: "ens2f0"}},
+	}
+	for _, devices := range valid {
+		if err := verifyNetworkDevices(&containertypes.HostConfig{NetworkDevices: devices}, nil); err != nil {
+			t.Fatalf("Expected %+v to be valid, got %v", devices, err)
+		}
+	}
+
+	invalid := [][]containertypes.NetworkDevice{
+		{{Name: ""}},
+		{{Name: "a-very-long-interface-name"}},
+		{{Name: "eth1", ContainerName: "net/0"}},
+		{{Name: "ens2f0", VirtualFunction: &negative}},
+		{{Name: "eth1"}, {Name: "eth1", ContainerName: "net0"}},
+		{{Name: "ens2f0", VirtualFunction: &vf1}, {Name: "ens2f0", VirtualFunction: &vf1, ContainerName: "net0"}},
+		{{Name: "eth1", ContainerName: "net0"}, {Name: "eth2", ContainerName: "net0"}},
+	}
+	for _, devices := range invalid {
+		if err := verifyNetworkDevices(&containertypes.HostConfig{NetworkDevices: devices}, nil); err == nil {
+			t.Fatalf("Expected %+v to be invalid", devices)
+		}
+	}
+
+	devices := []containertypes.NetworkDevice{{Name: "eth1"}}
+	for _, mode := range []string{"host", "container:web"} {
+		if err := verifyNetworkDevices(&containertypes.HostConfig{NetworkMode: containertypes.NetworkMode(mode), NetworkDevices: devices}, nil); err == nil {
+			t.Fatalf("Expected network devices to be rejected with the %s network mode", mode)
+		}
+	}
+	if err := verifyNetworkDevices(&containertypes.HostConfig{NetworkDevices: devices}, &containertypes.Config{NetworkDisabled: true}); err == nil {
+		t.Fatal("Expected network devices to be rejected with networking disabled")
+	}
+}
@@ -0,0 +1,8 @@
+// +build !linux
+
+package daemon
+
+import "github.com/docker/docker/container"
+
+func (daemon *Daemon) restoreNetworkDevices(c *container.Container, key string) {
+}
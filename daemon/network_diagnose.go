@@ -0,0 +1,58 @@
+package daemon
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/errors"
+	"github.com/docker/engine-api/types"
+	lntypes "github.com/docker/libnetwork/types"
+)
+
+// DiagnoseNetwork runs the connectivity checks of the endpoint of the
+// running container containerName on the network networkName, and returns
+// their report.
+func (daemon *Daemon) DiagnoseNetwork(networkName, containerName string) (*types.NetworkDiagnostics, error) {
+	if containerName == "" {
+		return nil, errors.NewBadRequestError(fmt.Errorf("a container is required to diagnose network %s", networkName))
+	}
+	n, err := daemon.FindNetwork(networkName)
+	if err != nil {
+		return nil, err
+	}
+	container, err := daemon.GetContainer(containerName)
+	if err != nil {
+		return nil, err
+	}
+	if !container.IsRunning() {
+		return nil, errors.NewRequestConflictError(errNotRunning{container.ID})
+	}
+
+	ep, err := container.GetEndpointInNetwork(n)
+	if err != nil {
+		err := fmt.Errorf("container %s is not connected to network %s", containerName, n.Name())
+		return nil, errors.NewRequestNotFoundError(err)
+	}
+	checks, err := ep.Diagnose()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &types.NetworkDiagnostics{
+		Network:    n.Name(),
+		Container:  strings.TrimPrefix(container.Name, "/"),
+		EndpointID: ep.ID(),
+		Healthy:    true,
+	}
+	for _, c := range checks {
+		if c.Status == lntypes.DiagnosticFailed {
+			report.Healthy = false
+		}
+		report.Checks = append(report.Checks, types.NetworkDiagnosticCheck{
+			Name:   c.Name,
+			Status: string(c.Status),
+			Detail: c.Detail,
+		})
+	}
+	return report, nil
+}
@@ -0,0 +1,16 @@
+package daemon
+
+import "testing"
+
+func TestDiagnoseNetworkRequiresContainer(t *testing.T) {
+	daemon := &Daemon{}
+	_, err := daemon.DiagnoseNetwork("front", "")
+	if err == nil {
+		t.Fatal("expected an error diagnosing a network without container")
+	}
+	if e, ok := err.(interface {
+		HTTPErrorStatusCode() int
+	}); !ok || e.HTTPErrorStatusCode() != 400 {
+		t.Fatalf("expected a bad request error, got %v", err)
+	}
+}
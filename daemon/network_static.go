@@ -0,0 +1,141 @@
+package daemon
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/errors"
+	"github.com/docker/docker/runconfig"
+	containertypes "github.com/docker/engine-api/types/container"
+	networktypes "github.com/docker/engine-api/types/network"
+)
+
+// staticIPs returns the addresses set by the user for the endpoint of
+// epConfig.
+func staticIPs(epConfig *networktypes.EndpointSettings) []net.IP {
+	if !hasUserDefinedIPAddress(epConfig) {
+		return nil
+	}
+	var ips []net.IP
+	for _, addr := range []string{epConfig.IPAMConfig.IPv4Address, epConfig.IPAMConfig.IPv6Address} {
+		if ip := net.ParseIP(addr); ip != nil {
+			ips = append(ips, ip)
+		}
+	}
+	return ips
+}
+
+// staticAddress is a static IP or MAC address of a container on a network.
+type staticAddress struct {
+	network string
+	addr    string
+}
+
+// staticAddresses returns the static IP and MAC addresses of a container on
+// user-defined networks, from the endpoint configurations endpoints, its
+// network mode and its MAC address, by the ID of their network and the
+// address.
+func (daemon *Daemon) staticAddresses(endpoints map[string]*networktypes.EndpointSettings, netMode containertypes.NetworkMode, mac string) map[string]staticAddress {
+	networkID := func(name string) string {
+		if daemon.netController == nil {
+			return name
+		}
+		if n, err := daemon.FindNetwork(name); err == nil {
+			return n.ID()
+		}
+		return name
+	}
+
+	addrs := make(map[string]staticAddress)
+	for name, epConfig := range endpoints {
+		if runconfig.IsPreDefinedNetwork(name) {
+			continue
+		}
+		for _, ip := range staticIPs(epConfig) {
+			addrs[networkID(name)+"/"+ip.String()] = staticAddress{network: name, addr: ip.String()}
+		}
+	}
+	if hw, err := net.ParseMAC(mac); err == nil && netMode.IsUserDefined() {
+		name := netMode.NetworkName()
+		addrs[networkID(name)+"/"+hw.String()] = staticAddress{network: name, addr: hw.String()}
+	}
+	return addrs
+}
+
+// checkStaticAddressConflicts refuses the static addresses of the container
+// id, or of a container being created if id is empty, that are assigned to
+// another container on the same network, whether it runs or not.
+func (daemon *Daemon) checkStaticAddressConflicts(id string, endpoints map[string]*networktypes.EndpointSettings, netMode containertypes.NetworkMode, mac string) error {
+	wanted := daemon.staticAddresses(endpoints, netMode, mac)
+	if len(wanted) == 0 {
+		return nil
+	}
+
+	for _, c := range daemon.List() {
+		if c.ID == id {
+			continue
+		}
+		c.Lock()
+		var addrs map[string]staticAddress
+		if !c.RemovalInProgress && !c.Dead {
+			addrs = daemon.staticAddresses(c.NetworkSettings.Networks, c.HostConfig.NetworkMode, c.Config.MacAddress)
+		}
+		name := c.Name
+		c.Unlock()
+
+		for key := range addrs {
+			if a, ok := wanted[key]; ok {
+				err := fmt.Errorf("Address %s on network %s is already assigned to container %s", a.addr, a.network, strings.TrimPrefix(name, "/"))
+				return errors.NewRequestConflictError(err)
+			}
+		}
+	}
+	return nil
+}
+
+// reserveStaticAddresses keeps the static IP addresses of the container c,
+// which no longer runs, allocated on its networks, so that no other
+// container gets them before it starts again.
+func (daemon *Daemon) reserveStaticAddresses(c *container.Container) {
+	if daemon.netController == nil || c.RemovalInProgress || c.Dead {
+		return
+	}
+	for name, epConfig := range c.NetworkSettings.Networks {
+		ips := staticIPs(epConfig)
+		if len(ips) == 0 {
+			continue
+		}
+		n, err := daemon.FindNetwork(name)
+		if err != nil {
+			continue
+		}
+		if err := n.Reserve(c.ID, ips...); err != nil {
+			logrus.Warnf("Could not reserve the static addresses of container %s on network %s: %v", c.ID, name, err)
+		}
+	}
+}
+
+// releaseStaticAddresses returns the static IP addresses reserved for the
+// container c to its networks, or to the networks passed.
+func (daemon *Daemon) releaseStaticAddresses(c *container.Container, networks ...string) {
+	if daemon.netController == nil {
+		return
+	}
+	if len(networks) == 0 {
+		for name := range c.NetworkSettings.Networks {
+			networks = append(networks, name)
+		}
+	}
+	for _, name := range networks {
+		n, err := daemon.FindNetwork(name)
+		if err != nil {
+			continue
+		}
+		if err := n.Unreserve(c.ID); err != nil {
+			logrus.Warnf("Could not release the static addresses of container %s on network %s: %v", c.ID, name, err)
+		}
+	}
+}
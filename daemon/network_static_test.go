@@ -0,0 +1,79 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/network"
+	containertypes "github.com/docker/engine-api/types/container"
+	networktypes "github.com/docker/engine-api/types/network"
+)
+
+func staticEndpoint(ipv4, ipv6 string) *networktypes.EndpointSettings {
+	return &networktypes.EndpointSettings{
+		IPAMConfig: &networktypes.EndpointIPAMConfig{IPv4Address: ipv4, IPv6Address: ipv6},
+	}
+}
+
+func TestStaticIPs(t *testing.T) {
+	if ips := staticIPs(nil); len(ips) != 0 {
+		t.Fatalf("expected no address, got %v", ips)
+	}
+	if ips := staticIPs(&networktypes.EndpointSettings{}); len(ips) != 0 {
+		t.Fatalf("expected no address, got %v", ips)
+	}
+	ips := staticIPs(staticEndpoint("172.20.0.5", "2001:db8::5"))
+	if len(ips) != 2 || ips[0].String() != "172.20.0.5" || ips[1].String() != "2001:db8::5" {
+		t.Fatalf("unexpected addresses %v", ips)
+	}
+}
+
+func TestCheckStaticAddressConflicts(t *testing.T) {
+	daemon := &Daemon{containers: container.NewMemoryStore()}
+	c := &container.Container{
+		CommonContainer: container.CommonContainer{
+			ID:    "stopped",
+			Name:  "/stopped",
+			State: container.NewState(),
+			Config: &containertypes.Config{
+				MacAddress: "02:42:ac:14:00:05",
+			},
+			HostConfig: &containertypes.HostConfig{NetworkMode: "front"},
+			NetworkSettings: &network.Settings{
+				Networks: map[string]*networktypes.EndpointSettings{
+					"front": staticEndpoint("172.20.0.5", ""),
+				},
+			},
+		},
+	}
+	daemon.containers.Add(c.ID, c)
+
+	endpoints := map[string]*networktypes.EndpointSettings{"front": staticEndpoint("172.20.0.5", "")}
+	err := daemon.checkStaticAddressConflicts("", endpoints, "front", "")
+	if err == nil {
+		t.Fatal("expected a conflict on the IP address of a stopped container")
+	}
+	if e, ok := err.(interface {
+		HTTPErrorStatusCode() int
+	}); !ok || e.HTTPErrorStatusCode() != 409 {
+		t.Fatalf("expected a conflict error, got %v", err)
+	}
+
+	if err := daemon.checkStaticAddressConflicts("", nil, "front", "02:42:ac:14:00:05"); err == nil {
+		t.Fatal("expected a conflict on the MAC address of a stopped container")
+	}
+	if err := daemon.checkStaticAddressConflicts(c.ID, endpoints, "front", ""); err != nil {
+		t.Fatalf("expected no conflict with the container itself, got %v", err)
+	}
+	if err := daemon.checkStaticAddressConflicts("", map[string]*networktypes.EndpointSettings{"back": staticEndpoint("172.20.0.5", "")}, "back", ""); err != nil {
+		t.Fatalf("expected no conflict on another network, got %v", err)
+	}
+	if err := daemon.checkStaticAddressConflicts("", nil, "bridge", "02:42:ac:14:00:05"); err != nil {
+		t.Fatalf("expected no conflict on the default bridge network, got %v", err)
+	}
+
+	c.SetRemovalInProgress()
+	if err := daemon.checkStaticAddressConflicts("", endpoints, "front", ""); err != nil {
+		t.Fatalf("expected no conflict with a container being removed, got %v", err)
+	}
+}
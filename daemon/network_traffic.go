@@ -0,0 +1,114 @@
+package daemon
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/container"
+	"github.com/docker/engine-api/types"
+)
+
+// networkQuotaInterval is the interval at which the traffic of the
+// containers with a network quota is checked against it.
+const networkQuotaInterval = 10 * time.Second
+
+// networkTraffic returns the traffic of the running container c on its
+// endpoints, by network, since it started. It returns nil if c isn't
+// running, or has no network of its own. The caller must hold the lock of
+// c.
+func (daemon *Daemon) networkTraffic(c *container.Container) map[string]types.EndpointTraffic {
+	if !c.Running || c.NetworkSettings == nil || c.NetworkSettings.SandboxID == "" || daemon.netController == nil {
+		return nil
+	}
+	sb, err := daemon.netController.SandboxByID(c.NetworkSettings.SandboxID)
+	if err != nil {
+		return nil
+	}
+	stats, err := sb.EndpointStatistics()
+	if err != nil {
+		logrus.Debugf("Could not get the traffic of container %s: %v", c.ID, err)
+		return nil
+	}
+
+	var traffic map[string]types.EndpointTraffic
+	for name, ep := range c.NetworkSettings.Networks {
+		s, ok := stats[ep.EndpointID]
+		if !ok {
+			continue
+		}
+		if traffic == nil {
+			traffic = make(map[string]types.EndpointTraffic)
+		}
+		traffic[name] = types.EndpointTraffic{
+			EndpointID: ep.EndpointID,
+			RxBytes:    s.RxBytes,
+			TxBytes:    s.TxBytes,
+		}
+	}
+	return traffic
+}
+
+// monitorNetworkQuotas runs a ticker checking the traffic of the running
+// containers with a network quota, emitting a network_quota_exceeded event
+// once per run of a container when the bytes it received and sent exceed
+// its quota.
+func (daemon *Daemon) monitorNetworkQuotas() {
+	// exceeded holds the start time of the run of the containers that
+	// exceeded their quota, by container ID.
+	exceeded := make(map[string]time.Time)
+	for range time.Tick(networkQuotaInterval) {
+		seen := make(map[string]bool)
+		for _, c := range daemon.List() {
+			if daemon.checkNetworkQuota(c, exceeded) {
+				seen[c.ID] = true
+			}
+		}
+		for id := range exceeded {
+			if !seen[id] {
+				delete(exceeded, id)
+			}
+		}
+	}
+}
+
+// checkNetworkQuota checks the traffic of the container c against its
+// network quota, if it has one and is running. It returns whether c
+// exceeded its quota in its current run, recorded in exceeded.
+func (daemon *Daemon) checkNetworkQuota(c *container.Container, exceeded map[string]time.Time) bool {
+	c.Lock()
+	quota := c.HostConfig.NetworkQuota
+	startedAt := c.StartedAt
+	if quota <= 0 || !c.Running {
+		c.Unlock()
+		return false
+	}
+	if t, ok := exceeded[c.ID]; ok && t.Equal(startedAt) {
+		c.Unlock()
+		return true
+	}
+	traffic := daemon.networkTraffic(c)
+	c.Unlock()
+
+	rx, tx := totalTraffic(traffic)
+	if rx+tx <= uint64(quota) {
+		return false
+	}
+	exceeded[c.ID] = startedAt
+	daemon.LogContainerEventWithAttributes(c, "network_quota_exceeded", map[string]string{
+		"quota":   strconv.FormatInt(quota, 10),
+		"rxBytes": strconv.FormatUint(rx, 10),
+		"txBytes": strconv.FormatUint(tx, 10),
+	})
+	return true
+}
+
+// totalTraffic returns the bytes received and sent on all the endpoints of
+// traffic.
+func totalTraffic(traffic map[string]types.EndpointTraffic) (rx, tx uint64) {
+	for _, t := range traffic {
+		rx += t.RxBytes
+		tx += t.TxBytes
+	}
+	return rx, tx
+}
@@ -0,0 +1,49 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/container"
+	"github.com/docker/engine-api/types"
+	containertypes "github.com/docker/engine-api/types/container"
+)
+
+func TestTotalTraffic(t *testing.T) {
+	rx, tx := totalTraffic(map[string]types.EndpointTraffic{
+		"bridge":   {RxBytes: 100, TxBytes: 10},
+		"frontend": {RxBytes: 50, TxBytes: 5},
+	})
+	if rx != 150 || tx != 15 {
+		t.Fatalf("expected 150 bytes received and 15 sent, got %d and %d", rx, tx)
+	}
+}
+
+func TestCheckNetworkQuota(t *testing.T) {
+	daemon := &Daemon{}
+	c := &container.Container{
+		CommonContainer: container.CommonContainer{
+			ID:         "test",
+			State:      container.NewState(),
+			HostConfig: &containertypes.HostConfig{},
+		},
+	}
+	c.Running = true
+	c.StartedAt = time.Now()
+
+	exceeded := map[string]time.Time{}
+	if daemon.checkNetworkQuota(c, exceeded) {
+		t.Fatal("expected a container without quota not to exceed it")
+	}
+
+	c.HostConfig.NetworkQuota = 1
+	exceeded[c.ID] = c.StartedAt
+	if !daemon.checkNetworkQuota(c, exceeded) {
+		t.Fatal("expected the container to have exceeded its quota in its current run")
+	}
+
+	c.StartedAt = c.StartedAt.Add(time.Second)
+	if daemon.checkNetworkQuota(c, exceeded) {
+		t.Fatal("expected the container without traffic not to exceed its quota in a new run")
+	}
+}
@@ -0,0 +1,54 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	containertypes "github.com/docker/engine-api/types/container"
+)
+
+// verifyOCIHooks checks that the OCI hooks of a container are executables
+// of dir, the directory of the hooks allowed by the daemon, once their
+// symbolic links are resolved.
+func verifyOCIHooks(dir string, hooks *containertypes.Hooks) error {
+	if hooks == nil {
+		return nil
+	}
+	all := append(append(append([]containertypes.Hook{}, hooks.Prestart...), hooks.Poststart...), hooks.Poststop...)
+	if len(all) == 0 {
+		return nil
+	}
+	if dir == "" {
+		return fmt.Errorf("OCI hooks are not allowed: the daemon has no OCI hooks directory")
+	}
+	dir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return fmt.Errorf("Error resolving the OCI hooks directory: %v", err)
+	}
+
+	for _, hook := range all {
+		if !filepath.IsAbs(hook.Path) {
+			return fmt.Errorf("Invalid OCI hook %s: the path must be absolute", hook.Path)
+		}
+		path, err := filepath.EvalSymlinks(hook.Path)
+		if err != nil {
+			return fmt.Errorf("Invalid OCI hook %s: %v", hook.Path, err)
+		}
+		if rel, err := filepath.Rel(dir, path); err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("Invalid OCI hook %s: it is not in the OCI hooks directory of the daemon", hook.Path)
+		}
+		fi, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("Invalid OCI hook %s: %v", hook.Path, err)
+		}
+		if !fi.Mode().IsRegular() || fi.Mode().Perm()&0111 == 0 {
+			return fmt.Errorf("Invalid OCI hook %s: it is not an executable file", hook.Path)
+		}
+		if hook.Timeout != nil && *hook.Timeout <= 0 {
+			return fmt.Errorf("Invalid OCI hook %s: the timeout must be greater than 0", hook.Path)
+		}
+	}
+	return nil
+}
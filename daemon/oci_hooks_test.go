@@ -0,0 +1,57 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	containertypes "github.com/docker/engine-api/types/container"
+)
+
+func TestVerifyOCIHooks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oci-hooks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	hooksDir := filepath.Join(dir, "hooks")
+	if err := os.Mkdir(hooksDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	allowed := filepath.Join(hooksDir, "allowed")
+	if err := ioutil.WriteFile(allowed, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	notExecutable := filepath.Join(hooksDir, "not-executable")
+	if err := ioutil.WriteFile(notExecutable, []byte("#!/bin/sh\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outside := filepath.Join(dir, "outside")
+	if err := ioutil.WriteFile(outside, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	escape := filepath.Join(hooksDir, "escape")
+	if err := os.Symlink(outside, escape); err != nil {
+		t.Fatal(err)
+	}
+
+	hooks := func(path string) *containertypes.Hooks {
+		return &containertypes.Hooks{Poststart: []containertypes.Hook{{Path: path}}}
+	}
+	if err := verifyOCIHooks("", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyOCIHooks("", hooks(allowed)); err == nil {
+		t.Fatal("expected hooks to be rejected without hooks directory")
+	}
+	if err := verifyOCIHooks(hooksDir, hooks(allowed)); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{outside, escape, notExecutable, hooksDir, "allowed", filepath.Join(hooksDir, "missing")} {
+		if err := verifyOCIHooks(hooksDir, hooks(path)); err == nil {
+			t.Fatalf("expected the hook %s to be rejected", path)
+		}
+	}
+}
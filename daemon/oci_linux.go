@@ -18,6 +18,7 @@ import (
 	"github.com/docker/docker/pkg/mount"
 	"github.com/docker/docker/pkg/stringutils"
 	"github.com/docker/docker/pkg/symlink"
+	"github.com/docker/docker/pkg/sysinfo"
 	"github.com/docker/docker/volume"
 	containertypes "github.com/docker/engine-api/types/container"
 	"github.com/opencontainers/runc/libcontainer/apparmor"
@@ -77,6 +78,93 @@ func setResources(s *specs.Spec, r containertypes.Resources) error {
 	return nil
 }
 
+// setUnifiedResources translates the resources of r to the files of the
+// unified hierarchy of cgroup v2, and drops the ones cgroup v2 does not
+// support. The limits per block device are left to the runtime, which
+// writes them one device at a time.
+func setUnifiedResources(r *specs.Resources) {
+	unified := make(map[string]string)
+	if m := r.Memory; m != nil {
+		if m.Limit != nil {
+			unified["memory.max"] = strconv.FormatUint(*m.Limit, 10)
+			// memory.swap.max limits the swap alone, not the total of
+			// the memory and the swap
+			if m.Swap != nil {
+				if int64(*m.Swap) == -1 {
+					unified["memory.swap.max"] = "max"
+				} else if *m.Swap >= *m.Limit {
+					unified["memory.swap.max"] = strconv.FormatUint(*m.Swap-*m.Limit, 10)
+				}
+			}
+		}
+		if m.Reservation != nil {
+			unified["memory.low"] = strconv.FormatUint(*m.Reservation, 10)
+		}
+		m.Swappiness, m.Kernel, m.KernelTCP = nil, nil, nil
+	}
+	if c := r.CPU; c != nil {
+		if c.Shares != nil && *c.Shares > 0 {
+			unified["cpu.weight"] = strconv.FormatUint(cpuSharesToWeight(*c.Shares), 10)
+		}
+		if c.Quota != nil || c.Period != nil {
+			quota, period := "max", uint64(100000)
+			if c.Quota != nil && int64(*c.Quota) > 0 {
+				quota = strconv.FormatUint(*c.Quota, 10)
+			}
+			if c.Period != nil && *c.Period > 0 {
+				period = *c.Period
+			}
+			unified["cpu.max"] = quota + " " + strconv.FormatUint(period, 10)
+		}
+		if c.Cpus != nil {
+			unified["cpuset.cpus"] = *c.Cpus
+		}
+		if c.Mems != nil {
+			unified["cpuset.mems"] = *c.Mems
+		}
+	}
+	if b := r.BlockIO; b != nil {
+		if b.Weight != nil && *b.Weight > 0 {
+			unified["io.weight"] = "default " + strconv.FormatUint(blkioWeightToIOWeight(*b.Weight), 10)
+		}
+		b.LeafWeight = nil
+	}
+	if r.Pids != nil && r.Pids.Limit != nil && *r.Pids.Limit != 0 {
+		if *r.Pids.Limit < 0 {
+			unified["pids.max"] = "max"
+		} else {
+			unified["pids.max"] = strconv.FormatInt(*r.Pids.Limit, 10)
+		}
+	}
+	r.DisableOOMKiller = nil
+	if len(unified) > 0 {
+		r.Unified = unified
+	}
+}
+
+// cpuSharesToWeight converts the CPU shares of cgroup v1, from 2 to 262144,
+// to the cpu.weight of cgroup v2, from 1 to 10000.
+func cpuSharesToWeight(shares uint64) uint64 {
+	if shares < 2 {
+		shares = 2
+	} else if shares > 262144 {
+		shares = 262144
+	}
+	return 1 + (shares-2)*9999/262142
+}
+
+// blkioWeightToIOWeight converts the block IO weight of cgroup v1, from 10
+// to 1000, to the io.weight of cgroup v2, from 1 to 10000.
+func blkioWeightToIOWeight(weight uint16) uint64 {
+	w := uint64(weight)
+	if w < 10 {
+		w = 10
+	} else if w > 1000 {
+		w = 1000
+	}
+	return 1 + (w-10)*9999/990
+}
+
 func setDevices(s *specs.Spec, c *container.Container) error {
 	// Build lists of devices allowed and created within the container.
 	var devs []specs.Device
@@ -112,6 +200,38 @@ func setDevices(s *specs.Spec, c *container.Container) error {
 	return nil
 }
 
+// setRootlessDevices replaces the devices of s, which runc would create with
+// mknod, by bind mounts of the device nodes of the host.
+func setRootlessDevices(s *specs.Spec, c *container.Container) {
+	for _, d := range s.Linux.Devices {
+		s.Mounts = append(s.Mounts, specs.Mount{
+			Destination: d.Path,
+			Type:        "bind",
+			Source:      hostDevicePath(d.Path, c.HostConfig.Devices),
+			Options:     []string{"bind", "nosuid", "noexec"},
+		})
+	}
+	s.Linux.Devices = nil
+}
+
+// hostDevicePath returns the path on the host of the device at path in the
+// container, given the device mappings of the container. The default
+// devices and the devices of privileged containers are at the same path on
+// the host.
+func hostDevicePath(path string, mappings []containertypes.DeviceMapping) string {
+	for _, m := range mappings {
+		if path != m.PathInContainer && !strings.HasPrefix(path, m.PathInContainer+"/") {
+			continue
+		}
+		pathOnHost := m.PathOnHost
+		if resolved, err := filepath.EvalSymlinks(pathOnHost); err == nil {
+			pathOnHost = resolved
+		}
+		return pathOnHost + strings.TrimPrefix(path, m.PathInContainer)
+	}
+	return path
+}
+
 func setRlimits(daemon *Daemon, s *specs.Spec, c *container.Container) error {
 	var rlimits []specs.Rlimit
 
@@ -628,11 +748,24 @@ func (daemon *Daemon) createSpec(c *container.Container) (*libcontainerd.Spec, e
 	if err := setResources(&s, c.HostConfig.Resources); err != nil {
 		return nil, fmt.Errorf("linux runtime spec resources: %v", err)
 	}
+	if sysinfo.IsCgroup2UnifiedMode() {
+		setUnifiedResources(s.Linux.Resources)
+		if len(s.Linux.Resources.Unified) > 0 && !daemon.cgroup2Runtime {
+			return nil, fmt.Errorf("linux runtime spec resources: the runtime does not support the resource limits of cgroup v2")
+		}
+	}
 	s.Linux.Resources.OOMScoreAdj = &c.HostConfig.OomScoreAdj
 	s.Linux.Sysctl = c.HostConfig.Sysctls
 	if err := setDevices(&s, c); err != nil {
 		return nil, fmt.Errorf("linux runtime spec devices: %v", err)
 	}
+	// A rootless daemon cannot manage the cgroups of its containers, nor
+	// create device nodes in their user namespace.
+	if daemon.configStore.Rootless {
+		s.Linux.CgroupsPath = nil
+		s.Linux.Resources = &specs.Resources{OOMScoreAdj: &c.HostConfig.OomScoreAdj}
+		setRootlessDevices(&s, c)
+	}
 	if err := setRlimits(daemon, &s, c); err != nil {
 		return nil, fmt.Errorf("linux runtime spec rlimits: %v", err)
 	}
@@ -679,6 +812,19 @@ func (daemon *Daemon) createSpec(c *container.Container) (*libcontainerd.Spec, e
 			}
 		}
 	}
+	if len(c.HostConfig.NetworkDevices) > 0 {
+		hook, err := networkDevicesSpecHook(c)
+		if err != nil {
+			return nil, err
+		}
+		s.Hooks.Prestart = append(s.Hooks.Prestart, hook)
+	}
+	// The hooks of the container run after the network is set up.
+	if hooks := c.HostConfig.Hooks; hooks != nil {
+		s.Hooks.Prestart = append(s.Hooks.Prestart, specHooks(hooks.Prestart)...)
+		s.Hooks.Poststart = append(s.Hooks.Poststart, specHooks(hooks.Poststart)...)
+		s.Hooks.Poststop = append(s.Hooks.Poststop, specHooks(hooks.Poststop)...)
+	}
 
 	if apparmor.IsEnabled() {
 		appArmorProfile := "docker-default"
@@ -705,3 +851,13 @@ func clearReadOnly(m *specs.Mount) {
 	}
 	m.Options = opt
 }
+
+// specHooks converts the OCI hooks of a container to those of its runtime
+// spec.
+func specHooks(hooks []containertypes.Hook) []specs.Hook {
+	var s []specs.Hook
+	for _, h := range hooks {
+		s = append(s, specs.Hook{Path: h.Path, Args: h.Args, Env: h.Env, Timeout: h.Timeout})
+	}
+	return s
+}
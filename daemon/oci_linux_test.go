@@ -0,0 +1,101 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/docker/docker/container"
+	containertypes "github.com/docker/engine-api/types/container"
+	"github.com/opencontainers/specs/specs-go"
+)
+
+func TestSetUnifiedResources(t *testing.T) {
+	limit, swap, reservation, swappiness := uint64(64<<20), uint64(128<<20), uint64(32<<20), uint64(60)
+	shares, quota, period := uint64(1024), uint64(50000), uint64(100000)
+	cpus := "0-1"
+	weight := uint16(500)
+	pids := int64(-1)
+	oomKillDisable := false
+	r := &specs.Resources{
+		Memory:           &specs.Memory{Limit: &limit, Swap: &swap, Reservation: &reservation, Swappiness: &swappiness},
+		CPU:              &specs.CPU{Shares: &shares, Quota: &quota, Period: &period, Cpus: &cpus},
+		BlockIO:          &specs.BlockIO{Weight: &weight},
+		Pids:             &specs.Pids{Limit: &pids},
+		DisableOOMKiller: &oomKillDisable,
+	}
+	setUnifiedResources(r)
+
+	expected := map[string]string{
+		"memory.max":      "67108864",
+		"memory.swap.max": "67108864",
+		"memory.low":      "33554432",
+		"cpu.weight":      "39",
+		"cpu.max":         "50000 100000",
+		"cpuset.cpus":     "0-1",
+		"io.weight":       "default 4950",
+		"pids.max":        "max",
+	}
+	if len(r.Unified) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, r.Unified)
+	}
+	for file, value := range expected {
+		if r.Unified[file] != value {
+			t.Fatalf("expected %s to be %q, got %q", file, value, r.Unified[file])
+		}
+	}
+	if r.Memory.Swappiness != nil || r.DisableOOMKiller != nil {
+		t.Fatal("expected the resources unsupported by cgroup v2 to be dropped")
+	}
+}
+
+func TestSetUnifiedResourcesUnlimited(t *testing.T) {
+	zero := uint16(0)
+	noPids := int64(0)
+	r := &specs.Resources{
+		Memory:  &specs.Memory{},
+		CPU:     &specs.CPU{},
+		BlockIO: &specs.BlockIO{Weight: &zero},
+		Pids:    &specs.Pids{Limit: &noPids},
+	}
+	setUnifiedResources(r)
+	if r.Unified != nil {
+		t.Fatalf("expected no cgroup v2 resources, got %v", r.Unified)
+	}
+}
+
+func TestSetRootlessDevices(t *testing.T) {
+	c := &container.Container{
+		CommonContainer: container.CommonContainer{
+			HostConfig: &containertypes.HostConfig{
+				Resources: containertypes.Resources{
+					Devices: []containertypes.DeviceMapping{
+						{PathOnHost: "/dev/does-not-exist", PathInContainer: "/dev/xvdc"},
+						{PathOnHost: "/dev/snd-does-not-exist", PathInContainer: "/dev/snd"},
+					},
+				},
+			},
+		},
+	}
+	s := &specs.Spec{
+		Linux: specs.Linux{
+			Devices: []specs.Device{{Path: "/dev/null"}, {Path: "/dev/xvdc"}, {Path: "/dev/snd/timer"}},
+		},
+	}
+	setRootlessDevices(s, c)
+
+	if len(s.Linux.Devices) != 0 {
+		t.Fatalf("expected no device to be created, got %v", s.Linux.Devices)
+	}
+	expected := map[string]string{
+		"/dev/null":      "/dev/null",
+		"/dev/xvdc":      "/dev/does-not-exist",
+		"/dev/snd/timer": "/dev/snd-does-not-exist/timer",
+	}
+	if len(s.Mounts) != len(expected) {
+		t.Fatalf("expected a bind mount per device, got %v", s.Mounts)
+	}
+	for _, m := range s.Mounts {
+		if m.Type != "bind" || m.Source != expected[m.Destination] {
+			t.Fatalf("expected %s to be bind mounted from %s, got %v", m.Destination, expected[m.Destination], m)
+		}
+	}
+}
@@ -168,11 +168,30 @@ func (daemon *Daemon) createSpec(c *container.Container) (*libcontainerd.Spec, e
 	// In s.Windows.Resources
 	// @darrenstahlmsft implement these resources
 	cpuShares := uint64(c.HostConfig.CPUShares)
+	cpu := &windowsoci.CPU{
+		Percent:    &c.HostConfig.CPUPercent,
+		Shares:     &cpuShares,
+		CPUGroupID: c.HostConfig.CPUGroup,
+	}
+	if c.HostConfig.CpusetCpus != "" {
+		group, affinity, err := processorAffinity(c.HostConfig.CpusetCpus)
+		if err != nil {
+			return nil, err
+		}
+		cpu.Group = &group
+		cpu.Affinity = &affinity
+	}
+	// The storage QoS limits the IO of the system drive of the container,
+	// the sandbox VHD, which HCS sets up when the container is created.
+	storage := &windowsoci.Storage{}
+	if c.HostConfig.IOMaximumBandwidth != 0 {
+		storage.Bps = &c.HostConfig.IOMaximumBandwidth
+	}
+	if c.HostConfig.IOMaximumIOps != 0 {
+		storage.Iops = &c.HostConfig.IOMaximumIOps
+	}
 	s.Windows.Resources = &windowsoci.Resources{
-		CPU: &windowsoci.CPU{
-			Percent: &c.HostConfig.CPUPercent,
-			Shares:  &cpuShares,
-		},
+		CPU:    cpu,
 		Memory: &windowsoci.Memory{
 		//TODO Limit: ...,
 		//TODO Reservation: ...,
@@ -180,11 +199,7 @@ func (daemon *Daemon) createSpec(c *container.Container) (*libcontainerd.Spec, e
 		Network: &windowsoci.Network{
 		//TODO Bandwidth: ...,
 		},
-		Storage: &windowsoci.Storage{
-			Bps:  &c.HostConfig.IOMaximumBandwidth,
-			Iops: &c.HostConfig.IOMaximumIOps,
-			//TODO SandboxSize: ...,
-		},
+		Storage: storage,
 	}
 	return (*libcontainerd.Spec)(&s), nil
 }
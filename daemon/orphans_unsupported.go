@@ -0,0 +1,15 @@
+// +build !windows
+
+package daemon
+
+// reapOrphanedComputeSystems does nothing, as containerd tracks the
+// containers left running across the restarts of the daemon.
+func (daemon *Daemon) reapOrphanedComputeSystems(config *Config, repository string) bool {
+	return false
+}
+
+// liveRestoreEnabled returns false, as the containers are stopped when the
+// daemon shuts down.
+func (daemon *Daemon) liveRestoreEnabled() bool {
+	return false
+}
@@ -0,0 +1,77 @@
+package daemon
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/Microsoft/hcsshim"
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/libcontainerd"
+)
+
+// The policies of the compute systems found running on startup.
+const (
+	orphanPolicyTerminate = "terminate"
+	orphanPolicyAdopt     = "adopt"
+)
+
+// orphanTerminateTimeout is the timeout, in milliseconds, of the
+// termination of an orphaned compute system.
+const orphanTerminateTimeout = 60 * 1000
+
+func validateOrphanPolicy(policy string) error {
+	switch policy {
+	case "", orphanPolicyTerminate, orphanPolicyAdopt:
+		return nil
+	}
+	return fmt.Errorf("invalid orphan policy %q, must be %s or %s", policy, orphanPolicyTerminate, orphanPolicyAdopt)
+}
+
+// reapOrphanedComputeSystems reconciles, on startup, the compute systems
+// created by the daemon with the containers of repository. A compute
+// system left running by a previous run of the daemon, such as one that
+// crashed, is invisible to the daemon and keeps consuming resources, as its
+// container is restored as exited. With the adopt policy, the compute
+// systems of the containers recorded as running are left for the restore to
+// adopt, and every other one is terminated. With the terminate policy, they
+// are all terminated. With live restore, the compute systems of the
+// containers recorded as running are always left for the restore to
+// reattach to. It returns whether any compute system was adopted.
+func (daemon *Daemon) reapOrphanedComputeSystems(config *Config, repository string) bool {
+	systems, err := hcsshim.EnumerateComputeSystems(libcontainerd.DefaultOwner)
+	if err != nil {
+		logrus.Warnf("Could not list the compute systems to look for orphaned ones: %v", err)
+		return false
+	}
+
+	var adopted bool
+	for _, cs := range systems {
+		if cs.Stopped {
+			continue
+		}
+		c := container.NewBaseContainer(cs.ID, filepath.Join(repository, cs.ID))
+		known := c.FromDisk() == nil && c.ID == cs.ID
+		adopt := config.OrphanPolicy == orphanPolicyAdopt || config.LiveRestore
+		if known && adopt && c.IsRunning() && c.Pid != 0 {
+			logrus.Infof("Adopting the running compute system of container %s", cs.ID)
+			adopted = true
+			continue
+		}
+		if !known {
+			logrus.Warnf("Found running compute system %s of no known container", cs.ID)
+		}
+		if err := hcsshim.TerminateComputeSystem(cs.ID, orphanTerminateTimeout, "reapOrphanedComputeSystems"); err != nil {
+			logrus.Warnf("Could not terminate orphaned compute system %s: %v", cs.ID, err)
+			continue
+		}
+		logrus.Infof("Terminated orphaned compute system %s", cs.ID)
+	}
+	return adopted
+}
+
+// liveRestoreEnabled returns whether the containers are kept running when
+// the daemon shuts down.
+func (daemon *Daemon) liveRestoreEnabled() bool {
+	return daemon.configStore != nil && daemon.configStore.LiveRestore
+}
@@ -23,6 +23,22 @@ func (daemon *Daemon) ContainerPause(name string) error {
 // containerPause pauses the container execution without stopping the process.
 // The execution can be resumed by calling containerUnpause.
 func (daemon *Daemon) containerPause(container *container.Container) error {
+	container.BeginOperation()
+	defer container.EndOperation()
+
+	if err := checkPause(container); err != nil {
+		return err
+	}
+
+	if err := daemon.containerd.Pause(container.ID); err != nil {
+		return runtimeError(fmt.Errorf("Cannot pause container %s: %s", container.ID, err), err)
+	}
+
+	return nil
+}
+
+// checkPause returns an error if the container cannot be paused.
+func checkPause(container *container.Container) error {
 	container.Lock()
 	defer container.Unlock()
 
@@ -41,9 +57,5 @@ func (daemon *Daemon) containerPause(container *container.Container) error {
 		return errContainerIsRestarting(container.ID)
 	}
 
-	if err := daemon.containerd.Pause(container.ID); err != nil {
-		return fmt.Errorf("Cannot pause container %s: %s", container.ID, err)
-	}
-
 	return nil
 }
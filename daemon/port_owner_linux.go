@@ -0,0 +1,85 @@
+package daemon
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// The states of the listening sockets in /proc/net.
+const (
+	tcpListen  = "0A"
+	udpUnbound = "07"
+)
+
+// findPortOwner returns the pid and the name of a process of the host that
+// listens on port, or 0 if there is none or it cannot be resolved.
+func findPortOwner(proto string, port int) (int, string) {
+	state := tcpListen
+	if proto == "udp" {
+		state = udpUnbound
+	}
+	inodes := make(map[string]bool)
+	for _, table := range []string{proto, proto + "6"} {
+		for _, inode := range listeningSockets(filepath.Join("/proc/net", table), state, port) {
+			inodes["socket:["+inode+"]"] = true
+		}
+	}
+	if len(inodes) == 0 {
+		return 0, ""
+	}
+
+	procs, err := ioutil.ReadDir("/proc")
+	if err != nil {
+		return 0, ""
+	}
+	for _, p := range procs {
+		pid, err := strconv.Atoi(p.Name())
+		if err != nil {
+			continue
+		}
+		fdDir := filepath.Join("/proc", p.Name(), "fd")
+		fds, err := ioutil.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			if link, err := os.Readlink(filepath.Join(fdDir, fd.Name())); err == nil && inodes[link] {
+				comm, _ := ioutil.ReadFile(filepath.Join("/proc", p.Name(), "comm"))
+				return pid, strings.TrimSpace(string(comm))
+			}
+		}
+	}
+	return 0, ""
+}
+
+// listeningSockets returns the inodes of the sockets of the table of
+// /proc/net at path that are in state on port.
+func listeningSockets(path, state string, port int) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var inodes []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+		fields := strings.Fields(s.Text())
+		if len(fields) < 10 || fields[3] != state {
+			continue
+		}
+		i := strings.LastIndex(fields[1], ":")
+		if i < 0 {
+			continue
+		}
+		if p, err := strconv.ParseUint(fields[1][i+1:], 16, 16); err == nil && int(p) == port && fields[9] != "0" {
+			inodes = append(inodes, fields[9])
+		}
+	}
+	return inodes
+}
@@ -0,0 +1,8 @@
+// +build !linux
+
+package daemon
+
+// findPortOwner is not supported on this platform.
+func findPortOwner(proto string, port int) (int, string) {
+	return 0, ""
+}
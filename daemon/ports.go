@@ -0,0 +1,179 @@
+package daemon
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/pkg/stringid"
+	"github.com/docker/engine-api/types"
+	"github.com/docker/go-connections/nat"
+)
+
+// The errors of the port allocator and of the userland proxy when a port
+// of the host is already in use.
+var (
+	portAllocatedPattern = regexp.MustCompile(`Bind for (\S+):(\d+) failed: port is already allocated`)
+	addressInUsePattern  = regexp.MustCompile(`listen (tcp|udp)[46]? (\S+):(\d+): bind: address already in use`)
+)
+
+// portConflictError is the error of the start of a container that cannot
+// publish a port of the host already in use, with the owner of the port.
+type portConflictError struct {
+	err      error
+	conflict types.PortConflict
+}
+
+func (e portConflictError) Error() string {
+	c := e.conflict
+	switch {
+	case c.ContainerID != "":
+		return fmt.Sprintf("%v: %s port %d is used by container %s (%s)", e.err, c.Proto, c.HostPort, c.ContainerName, stringid.TruncateID(c.ContainerID))
+	case c.Pid != 0:
+		return fmt.Sprintf("%v: %s port %d is used by process %s (pid %d)", e.err, c.Proto, c.HostPort, c.Process, c.Pid)
+	}
+	return e.err.Error()
+}
+
+// HTTPErrorStatusCode returns the status code of the conflict.
+func (e portConflictError) HTTPErrorStatusCode() int {
+	return http.StatusConflict
+}
+
+// ErrorResponse returns the error with the owner of the port.
+func (e portConflictError) ErrorResponse() types.ErrorResponse {
+	return types.ErrorResponse{Message: e.Error(), PortConflict: &e.conflict}
+}
+
+// parsePortConflict returns the port of the host of err if err is the
+// failure of c to publish a port already in use.
+func parsePortConflict(c *container.Container, err error) (types.PortConflict, bool) {
+	var conflict types.PortConflict
+	if m := portAllocatedPattern.FindStringSubmatch(err.Error()); m != nil {
+		conflict.HostIP = m[1]
+		conflict.HostPort, _ = strconv.Atoi(m[2])
+		// The port allocator does not report the protocol: it is the one
+		// of the binding of c to the port.
+		conflict.Proto = "tcp"
+		for port, bindings := range c.HostConfig.PortBindings {
+			for _, b := range bindings {
+				start, end, err := nat.ParsePortRangeToInt(b.HostPort)
+				if err == nil && start <= conflict.HostPort && conflict.HostPort <= end {
+					conflict.Proto = port.Proto()
+				}
+			}
+		}
+		return conflict, true
+	}
+	if m := addressInUsePattern.FindStringSubmatch(err.Error()); m != nil {
+		conflict.Proto = m[1]
+		conflict.HostIP = m[2]
+		conflict.HostPort, _ = strconv.Atoi(m[3])
+		return conflict, true
+	}
+	return conflict, false
+}
+
+// portConflict returns err with the owner of the port if err is the failure
+// of c to publish a port of the host already in use, err otherwise. The
+// owner is a container of the daemon, or else a process of the host when
+// it can be resolved. It must be called without the lock of c held.
+func (daemon *Daemon) portConflict(c *container.Container, err error) error {
+	conflict, ok := parsePortConflict(c, err)
+	if !ok {
+		return err
+	}
+	for _, other := range daemon.List() {
+		if other.ID == c.ID {
+			continue
+		}
+		other.Lock()
+		owner := other.Running && publishesPort(other, conflict)
+		other.Unlock()
+		if owner {
+			conflict.ContainerID = other.ID
+			conflict.ContainerName = strings.TrimPrefix(other.Name, "/")
+			return portConflictError{err: err, conflict: conflict}
+		}
+	}
+	conflict.Pid, conflict.Process = findPortOwner(conflict.Proto, conflict.HostPort)
+	return portConflictError{err: err, conflict: conflict}
+}
+
+// publishesPort returns whether c publishes the port of conflict. The
+// lock of c must be held.
+func publishesPort(c *container.Container, conflict types.PortConflict) bool {
+	if c.NetworkSettings == nil {
+		return false
+	}
+	for port, bindings := range c.NetworkSettings.Ports {
+		if port.Proto() != conflict.Proto {
+			continue
+		}
+		for _, b := range bindings {
+			if b.HostPort == strconv.Itoa(conflict.HostPort) && sameHostIP(b.HostIP, conflict.HostIP) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sameHostIP returns whether the addresses a and b overlap, an unspecified
+// address overlapping all the others.
+func sameHostIP(a, b string) bool {
+	ipA, ipB := net.ParseIP(a), net.ParseIP(b)
+	if ipA == nil || ipB == nil || ipA.IsUnspecified() || ipB.IsUnspecified() {
+		return true
+	}
+	return ipA.Equal(ipB)
+}
+
+// PortAllocations returns the ports of the host allocated to the published
+// ports of the running containers, sorted by port.
+func (daemon *Daemon) PortAllocations() []types.PortAllocation {
+	allocations := []types.PortAllocation{}
+	for _, c := range daemon.List() {
+		c.Lock()
+		if c.Running && c.NetworkSettings != nil {
+			for port, bindings := range c.NetworkSettings.Ports {
+				for _, b := range bindings {
+					hostPort, err := strconv.Atoi(b.HostPort)
+					if err != nil {
+						continue
+					}
+					allocations = append(allocations, types.PortAllocation{
+						HostIP:        b.HostIP,
+						HostPort:      hostPort,
+						Proto:         port.Proto(),
+						ContainerPort: port.Int(),
+						ContainerID:   c.ID,
+						ContainerName: strings.TrimPrefix(c.Name, "/"),
+					})
+				}
+			}
+		}
+		c.Unlock()
+	}
+	sort.Sort(byHostPort(allocations))
+	return allocations
+}
+
+type byHostPort []types.PortAllocation
+
+func (p byHostPort) Len() int      { return len(p) }
+func (p byHostPort) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
+func (p byHostPort) Less(i, j int) bool {
+	if p[i].HostPort != p[j].HostPort {
+		return p[i].HostPort < p[j].HostPort
+	}
+	if p[i].Proto != p[j].Proto {
+		return p[i].Proto < p[j].Proto
+	}
+	return p[i].HostIP < p[j].HostIP
+}
@@ -0,0 +1,82 @@
+package daemon
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/docker/docker/container"
+	"github.com/docker/engine-api/types"
+	containertypes "github.com/docker/engine-api/types/container"
+	"github.com/docker/go-connections/nat"
+)
+
+func TestParsePortConflict(t *testing.T) {
+	c := &container.Container{
+		CommonContainer: container.CommonContainer{
+			HostConfig: &containertypes.HostConfig{
+				PortBindings: nat.PortMap{
+					"53/udp":   {{HostPort: "5353"}},
+					"8000/tcp": {{HostPort: "8000-8010"}},
+					"80/tcp":   {{HostIP: "127.0.0.1", HostPort: "8080"}},
+				},
+			},
+		},
+	}
+
+	cases := []struct {
+		err      string
+		conflict types.PortConflict
+		ok       bool
+	}{
+		{
+			err:      "driver failed programming external connectivity on endpoint web (abc): Bind for 0.0.0.0:5353 failed: port is already allocated",
+			conflict: types.PortConflict{HostIP: "0.0.0.0", HostPort: 5353, Proto: "udp"},
+			ok:       true,
+		},
+		{
+			err:      "Bind for 0.0.0.0:8005 failed: port is already allocated",
+			conflict: types.PortConflict{HostIP: "0.0.0.0", HostPort: 8005, Proto: "tcp"},
+			ok:       true,
+		},
+		{
+			err:      "Error starting userland proxy: listen tcp 127.0.0.1:8080: bind: address already in use",
+			conflict: types.PortConflict{HostIP: "127.0.0.1", HostPort: 8080, Proto: "tcp"},
+			ok:       true,
+		},
+		{
+			err:      "Error starting userland proxy: listen udp6 [::]:5353: bind: address already in use",
+			conflict: types.PortConflict{HostIP: "[::]", HostPort: 5353, Proto: "udp"},
+			ok:       true,
+		},
+		{
+			err: "Container command 'foo' not found or does not exist",
+		},
+	}
+	for _, tc := range cases {
+		conflict, ok := parsePortConflict(c, errors.New(tc.err))
+		if ok != tc.ok {
+			t.Fatalf("%q: expected %v, got %v", tc.err, tc.ok, ok)
+		}
+		if ok && conflict != tc.conflict {
+			t.Fatalf("%q: expected %+v, got %+v", tc.err, tc.conflict, conflict)
+		}
+	}
+}
+
+func TestSameHostIP(t *testing.T) {
+	cases := []struct {
+		a, b string
+		same bool
+	}{
+		{"", "127.0.0.1", true},
+		{"0.0.0.0", "127.0.0.1", true},
+		{"127.0.0.1", "::", true},
+		{"127.0.0.1", "127.0.0.1", true},
+		{"127.0.0.1", "10.0.0.1", false},
+	}
+	for _, tc := range cases {
+		if same := sameHostIP(tc.a, tc.b); same != tc.same {
+			t.Fatalf("sameHostIP(%q, %q): expected %v, got %v", tc.a, tc.b, tc.same, same)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package daemon
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/pkg/streamformatter"
+	"github.com/docker/docker/pkg/stringid"
+	"github.com/docker/docker/runconfig"
+	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/filters"
+	timetypes "github.com/docker/engine-api/types/time"
+	"github.com/docker/go-units"
+	"github.com/docker/libnetwork/datastore"
+)
+
+// acceptedPruneFilterTags are the filters accepted when pruning containers.
+var acceptedPruneFilterTags = map[string]bool{
+	"label": true,
+	"until": true,
+}
+
+// ContainersPrune removes the stopped containers matching pruneFilters in a
+// single pass. Each removal is reported to outStream as it happens, followed
+// by a types.ContainersPruneReport summarizing the reclaimed space. A
+// container that cannot be removed is reported and skipped.
+func (daemon *Daemon) ContainersPrune(pruneFilters filters.Args, outStream io.Writer) error {
+	if err := pruneFilters.Validate(acceptedPruneFilterTags); err != nil {
+		return err
+	}
+	until, err := getUntilFromPruneFilters(pruneFilters)
+	if err != nil {
+		return err
+	}
+
+	sf := streamformatter.NewJSONStreamFormatter()
+	report := types.ContainersPruneReport{}
+	for _, c := range daemon.List() {
+		if !prunable(c, pruneFilters, until) {
+			continue
+		}
+		sizeRw, _ := daemon.getSize(c)
+		if err := daemon.ContainerRm(c.ID, &types.ContainerRmConfig{}); err != nil {
+			logrus.Warnf("prune: could not remove container %s: %v", c.ID, err)
+			outStream.Write(sf.FormatStatus(stringid.TruncateID(c.ID), "Could not remove: %v", err))
+			continue
+		}
+		if sizeRw > 0 {
+			report.SpaceReclaimed += uint64(sizeRw)
+		}
+		report.ContainersDeleted = append(report.ContainersDeleted, c.ID)
+		outStream.Write(sf.FormatStatus(stringid.TruncateID(c.ID), "Deleted"))
+	}
+
+	outStream.Write(sf.FormatStatus("", "Total reclaimed space: %s", units.HumanSize(float64(report.SpaceReclaimed))))
+	outStream.Write(sf.FormatProgress("", "", nil, report))
+	return nil
+}
+
+// NetworksPrune removes the local networks matching pruneFilters that no
+// container is connected to. Pre-defined networks are never removed. With
+// dryRun, the networks are only reported.
+func (daemon *Daemon) NetworksPrune(pruneFilters filters.Args, dryRun bool) (*types.NetworksPruneReport, error) {
+	nwList, err := daemon.FilterNetworks(pruneFilters)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &types.NetworksPruneReport{}
+	for _, nw := range nwList {
+		if runconfig.IsPreDefinedNetwork(nw.Name()) || nw.Info().Scope() != datastore.LocalScope || len(nw.Endpoints()) > 0 {
+			continue
+		}
+		if !dryRun {
+			if err := nw.Delete(); err != nil {
+				logrus.Warnf("prune: could not remove network %s: %v", nw.Name(), err)
+				continue
+			}
+			daemon.LogNetworkEvent(nw, "destroy")
+		}
+		report.NetworksDeleted = append(report.NetworksDeleted, nw.Name())
+	}
+	return report, nil
+}
+
+// prunable returns whether c is stopped and matches the prune filters.
+func prunable(c *container.Container, pruneFilters filters.Args, until time.Time) bool {
+	c.Lock()
+	defer c.Unlock()
+	if c.Running || c.Paused || c.Restarting || c.RemovalInProgress {
+		return false
+	}
+	if !until.IsZero() && !c.Created.Before(until) {
+		return false
+	}
+	return pruneFilters.MatchKVList("label", c.Config.Labels)
+}
+
+// getUntilFromPruneFilters returns the time before which containers must
+// have been created to be pruned, or the zero time if there is no "until"
+// filter. The filter accepts the same timestamps and relative durations as
+// `docker events --since`.
+func getUntilFromPruneFilters(pruneFilters filters.Args) (time.Time, error) {
+	values := pruneFilters.Get("until")
+	if len(values) == 0 {
+		return time.Time{}, nil
+	}
+	if len(values) > 1 {
+		return time.Time{}, fmt.Errorf("more than one until filter specified")
+	}
+	ts, err := timetypes.GetTimestamp(values[0], time.Now())
+	if err != nil {
+		return time.Time{}, err
+	}
+	seconds, nanoseconds, err := timetypes.ParseTimestamps(ts, 0)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(seconds, nanoseconds), nil
+}
@@ -0,0 +1,257 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/pkg/stringid"
+	"github.com/docker/docker/volume"
+	"github.com/docker/engine-api/types"
+	containertypes "github.com/docker/engine-api/types/container"
+	networktypes "github.com/docker/engine-api/types/network"
+	"github.com/docker/libnetwork"
+	"golang.org/x/net/context"
+)
+
+// refreshPlan is what is needed to recreate a container.
+type refreshPlan struct {
+	params types.ContainerCreateConfig
+	// connect holds the networks to connect the new container to after
+	// it is created, as create accepts a single endpoint.
+	connect  map[string]*networktypes.EndpointSettings
+	warnings []string
+}
+
+// ContainerRefresh replaces the container name with a new container created
+// from the configuration name was created with, and from the image
+// config.Image currently refers to, or the image reference name was created
+// with if config.Image is empty. The new container keeps the name, volumes
+// and networks of the old one, as well as its IP addresses on networks that
+// allow choosing them. A running container is stopped, and the new container
+// started. If the new container cannot be created, the old one is restored.
+func (daemon *Daemon) ContainerRefresh(name string, config *types.ContainerRefreshConfig) (types.ContainerCreateResponse, error) {
+	old, err := daemon.GetContainer(name)
+	if err != nil {
+		return types.ContainerCreateResponse{}, err
+	}
+	if old.IsPaused() {
+		return types.ContainerCreateResponse{}, fmt.Errorf("Cannot refresh paused container %s, try unpause instead", name)
+	}
+
+	plan, err := daemon.planRefresh(old, config.Image)
+	if err != nil {
+		return types.ContainerCreateResponse{}, err
+	}
+	// Fail before touching the old container if the image is missing.
+	if _, err := daemon.GetImage(plan.params.Config.Image); err != nil {
+		return types.ContainerCreateResponse{}, daemon.imageNotExistToErrcode(err)
+	}
+
+	wasRunning := old.IsRunning()
+	if wasRunning {
+		if err := daemon.containerStop(old, config.StopTimeout, nil); err != nil {
+			return types.ContainerCreateResponse{}, fmt.Errorf("Cannot stop container %s: %v", name, err)
+		}
+	}
+
+	oldName := strings.TrimPrefix(old.Name, "/")
+	if err := daemon.ContainerRename(old.ID, stringid.TruncateID(old.ID)+"_"+oldName); err != nil {
+		daemon.restoreRefreshed(old, "", wasRunning)
+		return types.ContainerCreateResponse{}, err
+	}
+
+	resp, err := daemon.ContainerCreate(plan.params)
+	if err != nil {
+		daemon.restoreRefreshed(old, oldName, wasRunning)
+		return resp, err
+	}
+	resp.Warnings = append(plan.warnings, resp.Warnings...)
+
+	c, err := daemon.GetContainer(resp.ID)
+	if err != nil {
+		return resp, err
+	}
+	for nw, epConfig := range plan.connect {
+		if err := daemon.ConnectToNetwork(c, nw, epConfig); err != nil {
+			resp.Warnings = append(resp.Warnings, fmt.Sprintf("Could not connect to network %s: %v", nw, err))
+		}
+	}
+
+	if err := daemon.ContainerRm(old.ID, &types.ContainerRmConfig{}); err != nil {
+		resp.Warnings = append(resp.Warnings, fmt.Sprintf("Could not remove the previous container %s: %v", old.ID, err))
+	}
+
+	daemon.LogContainerEventWithAttributes(c, "refresh", map[string]string{
+		"previousID": old.ID,
+	})
+
+	if wasRunning {
+		if err := daemon.containerStart(context.Background(), c); err != nil {
+			return resp, err
+		}
+	}
+	return resp, nil
+}
+
+// restoreRefreshed gives back its name to a container whose refresh failed,
+// and restarts it if it was running.
+func (daemon *Daemon) restoreRefreshed(c *container.Container, name string, start bool) {
+	if name != "" {
+		if err := daemon.ContainerRename(c.ID, name); err != nil {
+			logrus.Errorf("Failed to restore the name of container %s: %v", c.ID, err)
+		}
+	}
+	if start {
+		if err := daemon.containerStart(context.Background(), c); err != nil {
+			logrus.Errorf("Failed to restart container %s: %v", c.ID, err)
+		}
+	}
+}
+
+// planRefresh returns the configuration to recreate c from the image
+// imageRef refers to. It must be called while c still holds its IP
+// addresses.
+func (daemon *Daemon) planRefresh(c *container.Container, imageRef string) (*refreshPlan, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	params, warnings, err := createConfigOf(c)
+	if err != nil {
+		return nil, err
+	}
+	plan := &refreshPlan{
+		params:   params,
+		connect:  make(map[string]*networktypes.EndpointSettings),
+		warnings: warnings,
+	}
+	if imageRef != "" {
+		plan.params.Config.Image = imageRef
+	}
+
+	// Anonymous volumes are passed by name to keep their data.
+	for dest, mp := range anonymousVolumes(c) {
+		bind := mp.Name + ":" + dest
+		if !mp.RW {
+			bind += ":ro"
+		}
+		plan.params.HostConfig.Binds = append(plan.params.HostConfig.Binds, bind)
+	}
+
+	primary := plan.params.HostConfig.NetworkMode.NetworkName()
+	shortID := stringid.TruncateID(c.ID)
+	for nw, ep := range c.NetworkSettings.Networks {
+		if ep == nil {
+			continue
+		}
+		epConfig := &networktypes.EndpointSettings{
+			Links: ep.Links,
+		}
+		for _, alias := range ep.Aliases {
+			if alias != shortID {
+				epConfig.Aliases = append(epConfig.Aliases, alias)
+			}
+		}
+		if ep.IPAMConfig != nil {
+			ipam := *ep.IPAMConfig
+			epConfig.IPAMConfig = &ipam
+		} else if containertypes.NetworkMode(nw).IsUserDefined() {
+			if n, err := daemon.FindNetwork(nw); err == nil {
+				epConfig.IPAMConfig = keepAddresses(n, ep)
+			}
+		}
+
+		if nw == primary {
+			plan.params.NetworkingConfig = &networktypes.NetworkingConfig{
+				EndpointsConfig: map[string]*networktypes.EndpointSettings{nw: epConfig},
+			}
+		} else {
+			plan.connect[nw] = epConfig
+		}
+	}
+	return plan, nil
+}
+
+// createConfigOf returns the configuration c was created with. c must be
+// locked.
+func createConfigOf(c *container.Container) (types.ContainerCreateConfig, []string, error) {
+	var warnings []string
+	params := types.ContainerCreateConfig{
+		Name:       strings.TrimPrefix(c.Name, "/"),
+		Config:     &containertypes.Config{},
+		HostConfig: &containertypes.HostConfig{},
+	}
+
+	createConfig := c.CreateConfig
+	if createConfig == nil {
+		// Containers created by older daemons only have the configuration
+		// merged with their image's.
+		createConfig = c.Config
+		warnings = append(warnings, "The original configuration of this container was not recorded, the configuration inherited from its previous image is kept")
+	}
+	if err := deepCopy(params.Config, createConfig); err != nil {
+		return params, nil, err
+	}
+	if err := deepCopy(params.HostConfig, c.HostConfig); err != nil {
+		return params, nil, err
+	}
+	if params.Config.Hostname == stringid.TruncateID(c.ID) {
+		params.Config.Hostname = ""
+	}
+	return params, warnings, nil
+}
+
+// anonymousVolumes returns the mount points of c, by destination, of the
+// volumes created for c rather than given by name, bound from the host or
+// shared with another container. c must be locked.
+func anonymousVolumes(c *container.Container) map[string]*volume.MountPoint {
+	bound := make(map[string]bool)
+	for _, bind := range c.HostConfig.Binds {
+		if mp, err := volume.ParseMountSpec(bind, c.HostConfig.VolumeDriver); err == nil {
+			bound[mp.Destination] = true
+		}
+	}
+	anonymous := make(map[string]*volume.MountPoint)
+	for dest, mp := range c.MountPoints {
+		if _, ok := c.Config.Volumes[dest]; !ok || mp.Named || mp.Name == "" || bound[dest] {
+			continue
+		}
+		anonymous[dest] = mp
+	}
+	return anonymous
+}
+
+// keepAddresses returns the IPAM configuration requesting the addresses ep
+// currently has on n, for the address families in which n accepts user
+// specified addresses.
+func keepAddresses(n libnetwork.Network, ep *networktypes.EndpointSettings) *networktypes.EndpointIPAMConfig {
+	ipam := &networktypes.EndpointIPAMConfig{}
+	if ep.IPAddress != "" {
+		v4 := &networktypes.EndpointSettings{IPAMConfig: &networktypes.EndpointIPAMConfig{IPv4Address: ep.IPAddress}}
+		if validateNetworkingConfig(n, v4) == nil {
+			ipam.IPv4Address = ep.IPAddress
+		}
+	}
+	if ep.GlobalIPv6Address != "" {
+		v6 := &networktypes.EndpointSettings{IPAMConfig: &networktypes.EndpointIPAMConfig{IPv6Address: ep.GlobalIPv6Address}}
+		if validateNetworkingConfig(n, v6) == nil {
+			ipam.IPv6Address = ep.GlobalIPv6Address
+		}
+	}
+	if ipam.IPv4Address == "" && ipam.IPv6Address == "" {
+		return nil
+	}
+	return ipam
+}
+
+// deepCopy copies src into dst, which must be a pointer to a value of the
+// same type, so that they share no maps or slices.
+func deepCopy(dst, src interface{}) error {
+	b, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, dst)
+}
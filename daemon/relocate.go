@@ -0,0 +1,86 @@
+package daemon
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/pkg/idtools"
+)
+
+// RelocateDataRoot fixes up the paths the containers keep to the files of
+// the data root moved from the directory from to the data roots of config,
+// writing its progress to out. from is the previous daemon root, or the
+// previous data root of the containers or of the volumes. The images don't
+// keep paths to the data root, and need no fix up.
+//
+// The daemon must not be running.
+func RelocateDataRoot(config *Config, from string, out io.Writer) error {
+	if !filepath.IsAbs(from) {
+		return fmt.Errorf("invalid data root %s, it must be an absolute path", from)
+	}
+	uidMaps, gidMaps, err := setupRemappedRoot(config)
+	if err != nil {
+		return err
+	}
+	rootUID, rootGID, err := idtools.GetRootUIDGID(uidMaps, gidMaps)
+	if err != nil {
+		return err
+	}
+	realRoot, err := canonicalRoot(config.Root)
+	if err != nil {
+		return err
+	}
+	if err := setupRoots(config, rootUID, rootGID); err != nil {
+		return err
+	}
+	// the paths of the containers include the subdirectory of the remapped
+	// root, if any
+	from = filepath.Clean(from) + strings.TrimPrefix(config.Root, realRoot)
+
+	rewrite := relocatePath(from, map[string]string{
+		"containers": filepath.Join(config.containerRoot(), "containers"),
+		"volumes":    filepath.Join(config.volumeRoot(), "volumes"),
+	})
+	containersRoot := filepath.Join(config.containerRoot(), "containers")
+	dirs, err := ioutil.ReadDir(containersRoot)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i, d := range dirs {
+		c := container.NewBaseContainer(d.Name(), filepath.Join(containersRoot, d.Name()))
+		if err := c.FromDisk(); err != nil {
+			fmt.Fprintf(out, "Container %d/%d %s: skipped, failed to load: %v\n", i+1, len(dirs), d.Name(), err)
+			continue
+		}
+		fmt.Fprintf(out, "Container %d/%d %s\n", i+1, len(dirs), c.ID)
+		if c.IsRunning() {
+			return fmt.Errorf("container %s is running, stop it to relocate it", c.ID)
+		}
+		c.RelocatePaths(rewrite)
+		if err := c.ToDisk(); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(out, "Relocated %d containers from %s\n", len(dirs), from)
+	return nil
+}
+
+// relocatePath returns a function rewriting the paths in the subdirectories
+// of from to the directories of dirs, by name of subdirectory. The other
+// paths are kept.
+func relocatePath(from string, dirs map[string]string) func(string) string {
+	return func(p string) string {
+		for name, dir := range dirs {
+			old := filepath.Join(from, name)
+			if p == old || strings.HasPrefix(p, old+string(filepath.Separator)) {
+				return dir + strings.TrimPrefix(p, old)
+			}
+		}
+		return p
+	}
+}
@@ -0,0 +1,83 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/volume"
+	containertypes "github.com/docker/engine-api/types/container"
+)
+
+func TestRelocatePath(t *testing.T) {
+	rewrite := relocatePath("/var/lib/docker", map[string]string{
+		"containers": "/ssd/docker/containers",
+		"volumes":    "/hdd/docker/volumes",
+	})
+	for p, expected := range map[string]string{
+		"/var/lib/docker/containers/abc/hostname":  "/ssd/docker/containers/abc/hostname",
+		"/var/lib/docker/volumes/data/_data":       "/hdd/docker/volumes/data/_data",
+		"/var/lib/docker/containers":               "/ssd/docker/containers",
+		"/var/lib/docker/containers-old/abc/hosts": "/var/lib/docker/containers-old/abc/hosts",
+		"/var/lib/docker/overlay2/abc":             "/var/lib/docker/overlay2/abc",
+		"/srv/data":                                "/srv/data",
+		"":                                         "",
+	} {
+		if actual := rewrite(p); actual != expected {
+			t.Fatalf("Expected %q to be relocated to %q, got %q", p, expected, actual)
+		}
+	}
+}
+
+func TestRelocateDataRoot(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "relocate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	tmp, err = filepath.EvalSymlinks(tmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the containers were moved from the daemon root to their own data root
+	oldRoot := filepath.Join(tmp, "old")
+	config := &Config{}
+	config.Root = filepath.Join(tmp, "root")
+	config.ContainerRoot = filepath.Join(tmp, "containers")
+	id := "4bc3a8d8d5d0"
+	c := container.NewBaseContainer(id, filepath.Join(config.ContainerRoot, "containers", id))
+	if err := os.MkdirAll(c.Root, 0700); err != nil {
+		t.Fatal(err)
+	}
+	c.Config = &containertypes.Config{}
+	c.HostConfig = &containertypes.HostConfig{}
+	c.LogPath = filepath.Join(oldRoot, "containers", id, id+"-json.log")
+	c.MountPoints = map[string]*volume.MountPoint{
+		"/data": {Source: filepath.Join(oldRoot, "volumes", "data", "_data"), Destination: "/data"},
+		"/src":  {Source: "/home/user/src", Destination: "/src"},
+	}
+	if err := c.ToDisk(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RelocateDataRoot(config, oldRoot, ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+
+	c = container.NewBaseContainer(id, c.Root)
+	if err := c.FromDisk(); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []struct{ actual, expected string }{
+		{c.LogPath, filepath.Join(tmp, "containers", "containers", id, id+"-json.log")},
+		{c.MountPoints["/data"].Source, filepath.Join(tmp, "root", "volumes", "data", "_data")},
+		{c.MountPoints["/src"].Source, "/home/user/src"},
+	} {
+		if p.actual != p.expected {
+			t.Fatalf("Expected the path %s, got %s", p.expected, p.actual)
+		}
+	}
+}
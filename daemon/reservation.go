@@ -0,0 +1,120 @@
+package daemon
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/errors"
+	"github.com/docker/docker/pkg/parsers"
+	"github.com/docker/docker/pkg/system"
+	containertypes "github.com/docker/engine-api/types/container"
+	"github.com/docker/go-units"
+)
+
+// defaultCPUPeriod is the CFS period of the containers that set a quota
+// without a period.
+const defaultCPUPeriod = 100000
+
+// reservation is an amount of the CPUs and memory of the host.
+type reservation struct {
+	cpus   float64
+	memory int64
+}
+
+func (r reservation) add(o reservation) reservation {
+	return reservation{cpus: r.cpus + o.cpus, memory: r.memory + o.memory}
+}
+
+// containerReservation returns the CPUs and memory that the resources of a
+// container reserve. The memory is the soft limit if set, the hard limit
+// otherwise. The CPUs are given by the CFS quota, the cpuset or the CPU
+// percent, in that order; a container that only sets CPU shares does not
+// reserve CPUs.
+func containerReservation(resources containertypes.Resources, ncpu int) reservation {
+	var r reservation
+	if resources.MemoryReservation > 0 {
+		r.memory = resources.MemoryReservation
+	} else if resources.Memory > 0 {
+		r.memory = resources.Memory
+	}
+
+	switch {
+	case resources.CPUQuota > 0:
+		period := resources.CPUPeriod
+		if period == 0 {
+			period = defaultCPUPeriod
+		}
+		r.cpus = float64(resources.CPUQuota) / float64(period)
+	case resources.CpusetCpus != "":
+		// The cpuset was validated with the container settings.
+		if n, auto, _ := parseAutoCpuset(resources.CpusetCpus); auto {
+			r.cpus = float64(n)
+		} else if cpus, err := parsers.ParseUintList(resources.CpusetCpus); err == nil {
+			r.cpus = float64(len(cpus))
+		}
+	case resources.CPUPercent > 0:
+		r.cpus = float64(resources.CPUPercent) * float64(ncpu) / 100
+	}
+	return r
+}
+
+// hostCapacity returns the CPUs and memory of the host.
+func hostCapacity() (reservation, error) {
+	meminfo, err := system.ReadMemInfo()
+	if err != nil {
+		return reservation{}, err
+	}
+	return reservation{cpus: float64(runtime.NumCPU()), memory: meminfo.MemTotal}, nil
+}
+
+// reservedResources returns the CPUs and memory reserved by the running
+// containers other than exclude.
+func (daemon *Daemon) reservedResources(exclude *container.Container) reservation {
+	var reserved reservation
+	ncpu := runtime.NumCPU()
+	for _, c := range daemon.List() {
+		if c != exclude && c.IsRunning() && c.HostConfig != nil {
+			reserved = reserved.add(containerReservation(c.HostConfig.Resources, ncpu))
+		}
+	}
+	return reserved
+}
+
+// admitReservation returns an error if the daemon rejects overcommits and
+// the reservations of hostConfig, added to those of the running containers
+// other than c, exceed the capacity of the host times the overcommit factor.
+// c is nil for a container being created.
+func (daemon *Daemon) admitReservation(c *container.Container, hostConfig *containertypes.HostConfig) error {
+	if !daemon.configStore.RejectOvercommit || hostConfig == nil {
+		return nil
+	}
+	capacity, err := hostCapacity()
+	if err != nil {
+		logrus.Warnf("Could not read the capacity of the host, not checking the reservations: %v", err)
+		return nil
+	}
+
+	factor := daemon.configStore.OvercommitFactor
+	if factor <= 0 {
+		factor = 1
+	}
+	requested := containerReservation(hostConfig.Resources, runtime.NumCPU())
+	if err := checkReservation(daemon.reservedResources(c), requested, capacity, factor); err != nil {
+		return errors.NewResourceExhaustedError(err)
+	}
+	return nil
+}
+
+// checkReservation returns an error if requested, added to reserved,
+// exceeds capacity times factor.
+func checkReservation(reserved, requested, capacity reservation, factor float64) error {
+	if requested.cpus > 0 && reserved.cpus+requested.cpus > capacity.cpus*factor {
+		return fmt.Errorf("cannot reserve %.2f CPUs: %.2f of the %.0f CPUs of the host are reserved, and the daemon rejects reservations beyond %gx its capacity", requested.cpus, reserved.cpus, capacity.cpus, factor)
+	}
+	if requested.memory > 0 && float64(reserved.memory+requested.memory) > float64(capacity.memory)*factor {
+		return fmt.Errorf("cannot reserve %s of memory: %s of the %s of the host are reserved, and the daemon rejects reservations beyond %gx its capacity", units.BytesSize(float64(requested.memory)), units.BytesSize(float64(reserved.memory)), units.BytesSize(float64(capacity.memory)), factor)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package daemon
+
+import (
+	"testing"
+
+	containertypes "github.com/docker/engine-api/types/container"
+)
+
+func TestContainerReservation(t *testing.T) {
+	cases := []struct {
+		resources containertypes.Resources
+		expected  reservation
+	}{
+		{containertypes.Resources{CPUShares: 512}, reservation{}},
+		{containertypes.Resources{Memory: 512, MemoryReservation: 256}, reservation{memory: 256}},
+		{containertypes.Resources{Memory: 512}, reservation{memory: 512}},
+		{containertypes.Resources{CPUQuota: 150000}, reservation{cpus: 1.5}},
+		{containertypes.Resources{CPUQuota: 50000, CPUPeriod: 200000, CpusetCpus: "0-3"}, reservation{cpus: 0.25}},
+		{containertypes.Resources{CpusetCpus: "0-2,5"}, reservation{cpus: 4}},
+		{containertypes.Resources{CPUPercent: 50}, reservation{cpus: 4}},
+	}
+	for _, c := range cases {
+		if r := containerReservation(c.resources, 8); r != c.expected {
+			t.Fatalf("expected %+v for %+v, got %+v", c.expected, c.resources, r)
+		}
+	}
+}
+
+func TestCheckReservation(t *testing.T) {
+	capacity := reservation{cpus: 4, memory: 1024}
+	reserved := reservation{cpus: 3, memory: 512}
+
+	if err := checkReservation(reserved, reservation{cpus: 1, memory: 512}, capacity, 1); err != nil {
+		t.Fatalf("expected the reservation up to the capacity to be admitted, got %v", err)
+	}
+	if err := checkReservation(reserved, reservation{cpus: 2}, capacity, 1); err == nil {
+		t.Fatal("expected the CPU overcommit to be rejected")
+	}
+	if err := checkReservation(reserved, reservation{memory: 1024}, capacity, 1); err == nil {
+		t.Fatal("expected the memory overcommit to be rejected")
+	}
+	if err := checkReservation(reserved, reservation{cpus: 2, memory: 1024}, capacity, 1.5); err != nil {
+		t.Fatalf("expected the overcommit within the factor to be admitted, got %v", err)
+	}
+	// A container that reserves nothing is always admitted.
+	if err := checkReservation(reservation{cpus: 8, memory: 4096}, reservation{}, capacity, 1); err != nil {
+		t.Fatalf("expected a container without reservation to be admitted, got %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package daemon
 import (
 	"fmt"
 
+	"github.com/docker/docker/errors"
 	"github.com/docker/docker/libcontainerd"
 )
 
@@ -30,11 +31,18 @@ func (daemon *Daemon) ContainerResize(name string, height, width int) error {
 
 // ContainerExecResize changes the size of the TTY of the process
 // running in the exec with the given name to the given height and
-// width.
+// width. The exec must have been started and not have exited, the
+// runtime keeping the size until the process is added if it is not yet.
 func (daemon *Daemon) ContainerExecResize(name string, height, width int) error {
 	ec, err := daemon.getExecConfig(name)
 	if err != nil {
 		return err
 	}
+	ec.Lock()
+	running := ec.Running
+	ec.Unlock()
+	if !running {
+		return errors.NewRequestConflictError(fmt.Errorf("Exec %s is not running", ec.ID))
+	}
 	return daemon.containerd.Resize(ec.ContainerID, ec.ID, width, height)
 }
@@ -0,0 +1,34 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/exec"
+)
+
+func TestContainerExecResizeNotRunning(t *testing.T) {
+	c := &container.Container{CommonContainer: container.CommonContainer{
+		ID:    "2a8b4c6d8e0f",
+		State: container.NewState(),
+	}}
+	c.SetRunning(1, true)
+	store := container.NewMemoryStore()
+	store.Add(c.ID, c)
+	d := &Daemon{containers: store, execCommands: exec.NewStore()}
+
+	// Neither an exec not started yet, nor one which exited, is resized: the
+	// runtime would keep the size of a process it never adds.
+	ec := exec.NewConfig()
+	ec.ContainerID = c.ID
+	d.execCommands.Add(ec.ID, ec)
+	if err := d.ContainerExecResize(ec.ID, 24, 80); err == nil {
+		t.Fatal("expected the resize of an exec not started to fail")
+	}
+
+	exitCode := 0
+	ec.ExitCode = &exitCode
+	if err := d.ContainerExecResize(ec.ID, 24, 80); err == nil {
+		t.Fatal("expected the resize of an exec which exited to fail")
+	}
+}
@@ -2,8 +2,11 @@ package daemon
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/docker/docker/container"
+	"github.com/docker/docker/pkg/progress"
+	"golang.org/x/net/context"
 )
 
 // ContainerRestart stops and starts a container. It attempts to
@@ -11,23 +14,28 @@ import (
 // stopping it if the timeout is exceeded. If given a negative
 // timeout, ContainerRestart will wait forever until a graceful
 // stop. Returns an error if the container cannot be found, or if
-// there is an underlying error at any stage of the restart.
-func (daemon *Daemon) ContainerRestart(name string, seconds int) error {
+// there is an underlying error at any stage of the restart. If outStream
+// is not nil, the phases of the restart are written to it as JSON progress
+// messages.
+func (daemon *Daemon) ContainerRestart(name string, seconds int, outStream io.Writer) error {
 	container, err := daemon.GetContainer(name)
 	if err != nil {
 		return err
 	}
-	if err := daemon.containerRestart(container, seconds); err != nil {
+	out := newStopProgress(outStream)
+	if err := daemon.containerRestart(container, seconds, out); err != nil {
 		return fmt.Errorf("Cannot restart container %s: %v", name, err)
 	}
+	stopProgressf(out, container, "Restarted")
 	return nil
 }
 
 // containerRestart attempts to gracefully stop and then start the
 // container. When stopping, wait for the given duration in seconds to
 // gracefully stop, before forcefully terminating the container. If
-// given a negative duration, wait forever for a graceful stop.
-func (daemon *Daemon) containerRestart(container *container.Container, seconds int) error {
+// given a negative duration, wait forever for a graceful stop. The phases
+// of the restart are written to out, if not nil.
+func (daemon *Daemon) containerRestart(container *container.Container, seconds int, out progress.Output) error {
 	// Avoid unnecessarily unmounting and then directly mounting
 	// the container when the container stops and then starts
 	// again
@@ -35,11 +43,12 @@ func (daemon *Daemon) containerRestart(container *container.Container, seconds i
 		defer daemon.Unmount(container)
 	}
 
-	if err := daemon.containerStop(container, seconds); err != nil {
+	if err := daemon.containerStop(container, seconds, out); err != nil {
 		return err
 	}
 
-	if err := daemon.containerStart(container); err != nil {
+	stopProgressf(out, container, "Starting")
+	if err := daemon.containerStart(context.Background(), container); err != nil {
 		return err
 	}
 
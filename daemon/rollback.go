@@ -0,0 +1,36 @@
+package daemon
+
+import (
+	"github.com/Sirupsen/logrus"
+)
+
+// rollback undoes the allocations made by an operation of several steps,
+// such as the create of a container, when one of the steps fails. Each
+// step registers how to undo its allocations, and they are undone in the
+// reverse order of the steps.
+type rollback struct {
+	steps []rollbackStep
+}
+
+type rollbackStep struct {
+	name string
+	undo func() error
+}
+
+// add registers undo as the way to release the allocations of the step
+// name.
+func (r *rollback) add(name string, undo func() error) {
+	r.steps = append(r.steps, rollbackStep{name: name, undo: undo})
+}
+
+// run undoes the steps registered, last first. A step failing to be undone
+// is logged, and doesn't prevent the steps before it from being undone.
+func (r *rollback) run(id string) {
+	for i := len(r.steps) - 1; i >= 0; i-- {
+		step := r.steps[i]
+		if err := step.undo(); err != nil {
+			logrus.Errorf("Failed to roll back %s of %s: %v", step.name, id, err)
+		}
+	}
+	r.steps = nil
+}
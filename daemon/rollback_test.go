@@ -0,0 +1,37 @@
+package daemon
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRollback(t *testing.T) {
+	var (
+		undo   rollback
+		undone []string
+	)
+	for _, name := range []string{"name", "root filesystem", "mount points"} {
+		name := name
+		undo.add(name, func() error {
+			undone = append(undone, name)
+			if name == "root filesystem" {
+				return errors.New("device or resource busy")
+			}
+			return nil
+		})
+	}
+	undo.run("test")
+
+	// The steps are undone last first, even when one fails.
+	if expected := []string{"mount points", "root filesystem", "name"}; !reflect.DeepEqual(undone, expected) {
+		t.Fatalf("Expected the steps to be undone as %v, got %v", expected, undone)
+	}
+
+	// The steps are only undone once.
+	undone = nil
+	undo.run("test")
+	if len(undone) != 0 {
+		t.Fatalf("Expected no step to be undone again, got %v", undone)
+	}
+}
@@ -0,0 +1,70 @@
+package daemon
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/pkg/rootless"
+)
+
+// rootlessPorts forwards the ports published by the containers of a rootless
+// daemon from the host to the network namespace of the daemon, where they
+// are published.
+type rootlessPorts struct {
+	sync.Mutex
+	forwarder *rootless.PortForwarder
+	ids       map[string][]int // IDs of the forwardings of each container
+}
+
+// newRootlessPorts returns the port forwarding of the daemon, or nil if the
+// daemon does not run in rootless mode with a network helper.
+func newRootlessPorts() *rootlessPorts {
+	forwarder := rootless.NewPortForwarder()
+	if forwarder == nil {
+		return nil
+	}
+	return &rootlessPorts{
+		forwarder: forwarder,
+		ids:       make(map[string][]int),
+	}
+}
+
+// forward forwards the host ports of the published ports of c.
+func (p *rootlessPorts) forward(c *container.Container) error {
+	if p == nil || c.NetworkSettings == nil {
+		return nil
+	}
+	p.Lock()
+	defer p.Unlock()
+	for port, bindings := range c.NetworkSettings.Ports {
+		for _, binding := range bindings {
+			hostPort, err := strconv.Atoi(binding.HostPort)
+			if err != nil {
+				continue
+			}
+			id, err := p.forwarder.Add(port.Proto(), binding.HostIP, hostPort, hostPort)
+			if err != nil {
+				return err
+			}
+			p.ids[c.ID] = append(p.ids[c.ID], id)
+		}
+	}
+	return nil
+}
+
+// release removes the forwardings of the ports of c.
+func (p *rootlessPorts) release(c *container.Container) {
+	if p == nil {
+		return
+	}
+	p.Lock()
+	defer p.Unlock()
+	for _, id := range p.ids[c.ID] {
+		if err := p.forwarder.Remove(id); err != nil {
+			logrus.Warnf("Failed to remove the port forwarding %d of container %s: %v", id, c.ID, err)
+		}
+	}
+	delete(p.ids, c.ID)
+}
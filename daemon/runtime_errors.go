@@ -0,0 +1,12 @@
+package daemon
+
+import (
+	"github.com/docker/docker/errors"
+)
+
+// runtimeError returns err, the error of a call to the container runtime
+// which failed with cause, typed with the error code matching cause so
+// that the clients don't have to match its message.
+func runtimeError(err, cause error) error {
+	return errors.NewRuntimeError(err, runtimeErrorCode(cause), runtimeSubsystem)
+}
@@ -0,0 +1,46 @@
+// +build linux freebsd
+
+package daemon
+
+import (
+	"syscall"
+
+	"github.com/docker/engine-api/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// runtimeSubsystem is the subsystem reported in the errors of the
+// container runtime.
+const runtimeSubsystem = "containerd"
+
+// runtimeErrorCode returns the error code matching cause, an error of
+// containerd.
+func runtimeErrorCode(cause error) string {
+	if errno, ok := cause.(syscall.Errno); ok {
+		switch errno {
+		case syscall.ENOENT, syscall.ESRCH:
+			return types.ErrorCodeNotFound
+		case syscall.ENOSPC, syscall.ENOMEM, syscall.EAGAIN:
+			return types.ErrorCodeResourceExhausted
+		}
+		return types.ErrorCodeRuntime
+	}
+	switch grpc.Code(cause) {
+	case codes.InvalidArgument:
+		return types.ErrorCodeInvalidParameter
+	case codes.NotFound:
+		return types.ErrorCodeNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return types.ErrorCodeConflict
+	case codes.FailedPrecondition:
+		return types.ErrorCodePreconditionFailed
+	case codes.ResourceExhausted:
+		return types.ErrorCodeResourceExhausted
+	case codes.Unimplemented:
+		return types.ErrorCodeNotImplemented
+	case codes.Unavailable:
+		return types.ErrorCodeUnavailable
+	}
+	return types.ErrorCodeRuntime
+}
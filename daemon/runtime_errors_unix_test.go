@@ -0,0 +1,33 @@
+// +build linux freebsd
+
+package daemon
+
+import (
+	"fmt"
+	"syscall"
+	"testing"
+
+	"github.com/docker/engine-api/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+func TestRuntimeErrorCode(t *testing.T) {
+	for _, c := range []struct {
+		cause    error
+		expected string
+	}{
+		{grpc.Errorf(codes.NotFound, "container not found"), types.ErrorCodeNotFound},
+		{grpc.Errorf(codes.AlreadyExists, "container already exists"), types.ErrorCodeConflict},
+		{grpc.Errorf(codes.ResourceExhausted, "too many containers"), types.ErrorCodeResourceExhausted},
+		{grpc.Errorf(codes.FailedPrecondition, "container is not running"), types.ErrorCodePreconditionFailed},
+		{grpc.Errorf(codes.Unknown, "oci runtime error"), types.ErrorCodeRuntime},
+		{syscall.ENOSPC, types.ErrorCodeResourceExhausted},
+		{syscall.ESRCH, types.ErrorCodeNotFound},
+		{fmt.Errorf("shim exited"), types.ErrorCodeRuntime},
+	} {
+		if code := runtimeErrorCode(c.cause); code != c.expected {
+			t.Fatalf("Expected code %s for %v, got %s", c.expected, c.cause, code)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package daemon
+
+import (
+	"syscall"
+
+	"github.com/Microsoft/hcsshim"
+	"github.com/docker/docker/libcontainerd"
+	"github.com/docker/engine-api/types"
+)
+
+// runtimeSubsystem is the subsystem reported in the errors of the
+// container runtime.
+const runtimeSubsystem = "hcs"
+
+// hcsErrorCodes holds the error codes of the Win32 errors and HRESULTs of
+// the Host Compute Service that have a more specific code than
+// runtime_error.
+var hcsErrorCodes = map[syscall.Errno]string{
+	syscall.ERROR_FILE_NOT_FOUND:       types.ErrorCodeNotFound,
+	syscall.ERROR_PATH_NOT_FOUND:       types.ErrorCodeNotFound,
+	syscall.Errno(0x490):               types.ErrorCodeNotFound, // ERROR_NOT_FOUND
+	syscall.Errno(0xc037010e):          types.ErrorCodeNotFound, // HCS_E_SYSTEM_NOT_FOUND
+	libcontainerd.ErrorBadPathname:     types.ErrorCodeInvalidParameter,
+	syscall.Errno(0xd):                 types.ErrorCodeInvalidParameter, // ERROR_INVALID_DATA
+	syscall.Errno(0xc037010d):          types.ErrorCodeInvalidParameter, // HCS_E_INVALID_JSON
+	syscall.ERROR_ALREADY_EXISTS:       types.ErrorCodeConflict,
+	syscall.Errno(0xc0370103):          types.ErrorCodeConflict,           // HCS_E_OPERATION_PENDING
+	syscall.Errno(0xc0370105):          types.ErrorCodePreconditionFailed, // HCS_E_INVALID_STATE
+	syscall.Errno(0xc0370110):          types.ErrorCodePreconditionFailed, // HCS_E_SYSTEM_ALREADY_STOPPED
+	libcontainerd.ErrorNoNetwork:       types.ErrorCodePreconditionFailed,
+	syscall.Errno(0x8):                 types.ErrorCodeResourceExhausted, // ERROR_NOT_ENOUGH_MEMORY
+	syscall.Errno(0xe):                 types.ErrorCodeResourceExhausted, // ERROR_OUTOFMEMORY
+	syscall.Errno(0x70):                types.ErrorCodeResourceExhausted, // ERROR_DISK_FULL
+	syscall.Errno(0x5af):               types.ErrorCodeResourceExhausted, // ERROR_COMMITMENT_LIMIT
+	syscall.Errno(0x32):                types.ErrorCodeNotImplemented,    // ERROR_NOT_SUPPORTED
+	hcsshim.ERROR_SHUTDOWN_IN_PROGRESS: types.ErrorCodeUnavailable,
+	libcontainerd.ErrorTimeout:         types.ErrorCodeUnavailable,
+}
+
+// runtimeErrorCode returns the error code matching cause, an error of the
+// Host Compute Service.
+func runtimeErrorCode(cause error) string {
+	if herr, ok := cause.(*hcsshim.HcsError); ok {
+		cause = herr.Err
+	}
+	if errno, ok := cause.(syscall.Errno); ok {
+		if code, ok := hcsErrorCodes[errno]; ok {
+			return code
+		}
+	}
+	return types.ErrorCodeRuntime
+}
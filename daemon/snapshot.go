@@ -0,0 +1,94 @@
+package daemon
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/container"
+	"github.com/docker/engine-api/types"
+	"golang.org/x/net/context"
+)
+
+// ContainerSnapshotCreate takes a VSS snapshot of the writable layer and of
+// the mounted volumes of a running Windows container, for the backup agents.
+// The Quiesce command of config runs in the container before the snapshot,
+// and its Thaw command after it.
+func (daemon *Daemon) ContainerSnapshotCreate(ctx context.Context, name string, config *types.ContainerSnapshotConfig) (*types.ContainerSnapshot, error) {
+	if err := snapshotsSupported(); err != nil {
+		return nil, err
+	}
+	c, err := daemon.getActiveContainer(name)
+	if err != nil {
+		return nil, err
+	}
+
+	thaw := func() {
+		if len(config.Thaw) == 0 {
+			return
+		}
+		if err := daemon.runSnapshotHook(ctx, c, config.Thaw); err != nil {
+			logrus.Errorf("Failed to thaw container %s after its snapshot: %v", c.ID, err)
+		}
+	}
+	if len(config.Quiesce) > 0 {
+		if err := daemon.runSnapshotHook(ctx, c, config.Quiesce); err != nil {
+			thaw()
+			return nil, fmt.Errorf("Cannot snapshot container %s, the quiesce command failed: %v", c.ID, err)
+		}
+	}
+	snapshot, err := daemon.createSnapshot(c)
+	thaw()
+	if err != nil {
+		return nil, fmt.Errorf("Cannot snapshot container %s: %v", c.ID, err)
+	}
+
+	daemon.LogContainerEventWithAttributes(c, "snapshot", map[string]string{"snapshotID": snapshot.ID})
+	return snapshot, nil
+}
+
+// ContainerSnapshotRemove removes the snapshot id of a container.
+func (daemon *Daemon) ContainerSnapshotRemove(name, id string) error {
+	if err := snapshotsSupported(); err != nil {
+		return err
+	}
+	c, err := daemon.GetContainer(name)
+	if err != nil {
+		return err
+	}
+	return daemon.removeSnapshot(c, id)
+}
+
+// runSnapshotHook runs cmd in c, and returns an error if it fails.
+func (daemon *Daemon) runSnapshotHook(ctx context.Context, c *container.Container, cmd []string) error {
+	execID, err := daemon.ContainerExecCreate(c.ID, &types.ExecConfig{
+		Cmd:          cmd,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	var output bytes.Buffer
+	if err := daemon.ContainerExecStart(ctx, execID, nil, &output, &output); err != nil {
+		return err
+	}
+	ec := daemon.execCommands.Get(execID)
+	if ec == nil {
+		return errExecNotFound(execID)
+	}
+	defer daemon.unregisterExecCommand(c, ec)
+
+	ec.Lock()
+	defer ec.Unlock()
+	if ec.ExitCode == nil || *ec.ExitCode != 0 {
+		exitCode := -1
+		if ec.ExitCode != nil {
+			exitCode = *ec.ExitCode
+		}
+		return fmt.Errorf("%s exited with %d: %s", strings.Join(cmd, " "), exitCode, strings.TrimSpace(output.String()))
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+// +build !windows
+
+package daemon
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/errors"
+	"github.com/docker/engine-api/types"
+)
+
+// snapshotsSupported returns an error, as the snapshots of the containers
+// rely on VSS.
+func snapshotsSupported() error {
+	err := fmt.Errorf("Snapshots of containers rely on VSS and are only supported on Windows")
+	return errors.NewErrorWithStatusCode(err, http.StatusNotImplemented)
+}
+
+func (daemon *Daemon) createSnapshot(c *container.Container) (*types.ContainerSnapshot, error) {
+	return nil, snapshotsSupported()
+}
+
+func (daemon *Daemon) removeSnapshot(c *container.Container, id string) error {
+	return snapshotsSupported()
+}
+
+// removeSnapshots removes the snapshots of c before its removal.
+func (daemon *Daemon) removeSnapshots(c *container.Container) {
+}
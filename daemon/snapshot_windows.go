@@ -0,0 +1,204 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/errors"
+	"github.com/docker/docker/pkg/stringid"
+	"github.com/docker/engine-api/types"
+)
+
+// snapshotsDir is the directory of the snapshots of a container, under its
+// root directory. Each snapshot is a directory holding the links to the
+// shadow copies of the volumes of the host it covers, and the snapshotFile.
+const (
+	snapshotsDir = "snapshots"
+	snapshotFile = "snapshot.json"
+)
+
+// vssCreateScript creates a VSS shadow copy of the volume %s and prints its
+// ID and device object as JSON. WMI creates the shadow copies of the
+// clients, while the vssadmin command only creates them on Windows Server.
+const vssCreateScript = `$ErrorActionPreference = 'Stop'
+$r = (Get-WmiObject -List Win32_ShadowCopy).Create('%s', 'ClientAccessible')
+if ($r.ReturnValue -ne 0) { throw "VSS returned error $($r.ReturnValue)" }
+$s = Get-WmiObject Win32_ShadowCopy -Filter "ID='$($r.ShadowID)'"
+ConvertTo-Json @{ID = $s.ID; DeviceObject = $s.DeviceObject}`
+
+// vssDeleteScript deletes the VSS shadow copy %s.
+const vssDeleteScript = `$ErrorActionPreference = 'Stop'
+Get-WmiObject Win32_ShadowCopy -Filter "ID='%s'" | ForEach-Object { $_.Delete() }`
+
+// vssShadow is a VSS shadow copy of a volume of the host.
+type vssShadow struct {
+	ID           string // such as {9f8d2a4b-...}
+	Volume       string // such as C:\
+	DeviceObject string // such as \\?\GLOBALROOT\Device\HarddiskVolumeShadowCopy1
+	Link         string // directory link to DeviceObject in the snapshot
+}
+
+// snapshotRecord is the snapshotFile of a snapshot.
+type snapshotRecord struct {
+	Snapshot types.ContainerSnapshot
+	Shadows  []vssShadow
+}
+
+func snapshotsSupported() error {
+	return nil
+}
+
+// createSnapshot creates a shadow copy of each volume of the host holding
+// the writable layer or a mount of c. The shadow copies of different
+// volumes are taken one after the other, so only the files of a same
+// volume are consistent with each other.
+func (daemon *Daemon) createSnapshot(c *container.Container) (snapshot *types.ContainerSnapshot, err error) {
+	c.Lock()
+	rootFS := c.BaseFS
+	mounts := make(map[string]string)
+	for destination, m := range c.MountPoints {
+		if m.Source != "" {
+			mounts[destination] = m.Source
+		}
+	}
+	c.Unlock()
+
+	id := stringid.GenerateNonCryptoID()
+	dir := filepath.Join(c.Root, snapshotsDir, id)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	record := snapshotRecord{
+		Snapshot: types.ContainerSnapshot{
+			ID:        id,
+			Container: c.ID,
+			Created:   time.Now().UTC(),
+		},
+	}
+	defer func() {
+		if err != nil {
+			removeShadows(dir, record.Shadows)
+		}
+	}()
+
+	shadows := make(map[string]vssShadow)
+	snapshotPath := func(path string) (string, error) {
+		volume := filepath.VolumeName(path)
+		if volume == "" {
+			return "", fmt.Errorf("%s isn't on a volume of the host", path)
+		}
+		shadow, ok := shadows[strings.ToLower(volume)]
+		if !ok {
+			var err error
+			if shadow, err = createShadow(volume + `\`); err != nil {
+				return "", err
+			}
+			shadow.Link = filepath.Join(dir, fmt.Sprintf("volume%d", len(shadows)))
+			record.Shadows = append(record.Shadows, shadow)
+			if err := os.Symlink(shadow.DeviceObject+`\`, shadow.Link); err != nil {
+				return "", err
+			}
+			shadows[strings.ToLower(volume)] = shadow
+		}
+		return filepath.Join(shadow.Link, strings.TrimPrefix(path, volume)), nil
+	}
+
+	if record.Snapshot.RootFS, err = snapshotPath(rootFS); err != nil {
+		return nil, err
+	}
+	for destination, source := range mounts {
+		path, err := snapshotPath(source)
+		if err != nil {
+			return nil, err
+		}
+		record.Snapshot.Mounts = append(record.Snapshot.Mounts, types.SnapshotMount{
+			Destination: destination,
+			Source:      path,
+		})
+	}
+
+	b, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, snapshotFile), b, 0600); err != nil {
+		return nil, err
+	}
+	return &record.Snapshot, nil
+}
+
+func (daemon *Daemon) removeSnapshot(c *container.Container, id string) error {
+	dir := filepath.Join(c.Root, snapshotsDir, filepath.Base(id))
+	b, err := ioutil.ReadFile(filepath.Join(dir, snapshotFile))
+	if os.IsNotExist(err) {
+		return errors.NewRequestNotFoundError(fmt.Errorf("No such snapshot: %s", id))
+	}
+	if err != nil {
+		return err
+	}
+	var record snapshotRecord
+	if err := json.Unmarshal(b, &record); err != nil {
+		return err
+	}
+	if err := removeShadows(dir, record.Shadows); err != nil {
+		return err
+	}
+	daemon.LogContainerEventWithAttributes(c, "snapshot_remove", map[string]string{"snapshotID": id})
+	return nil
+}
+
+// removeSnapshots removes the snapshots of c before its removal.
+func (daemon *Daemon) removeSnapshots(c *container.Container) {
+	dirs, err := ioutil.ReadDir(filepath.Join(c.Root, snapshotsDir))
+	if err != nil {
+		return
+	}
+	for _, dir := range dirs {
+		if err := daemon.removeSnapshot(c, dir.Name()); err != nil {
+			logrus.Errorf("Failed to remove snapshot %s of container %s: %v", dir.Name(), c.ID, err)
+		}
+	}
+}
+
+// removeShadows deletes shadows and the directory dir of their snapshot.
+func removeShadows(dir string, shadows []vssShadow) error {
+	for _, shadow := range shadows {
+		if shadow.Link != "" {
+			if err := os.Remove(shadow.Link); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		if _, err := runPowerShell(fmt.Sprintf(vssDeleteScript, shadow.ID)); err != nil {
+			return fmt.Errorf("failed to delete shadow copy %s of %s: %v", shadow.ID, shadow.Volume, err)
+		}
+	}
+	return os.RemoveAll(dir)
+}
+
+func createShadow(volume string) (vssShadow, error) {
+	out, err := runPowerShell(fmt.Sprintf(vssCreateScript, strings.Replace(volume, "'", "''", -1)))
+	if err != nil {
+		return vssShadow{}, fmt.Errorf("failed to create a shadow copy of %s: %v", volume, err)
+	}
+	shadow := vssShadow{Volume: volume}
+	if err := json.Unmarshal(out, &shadow); err != nil {
+		return vssShadow{}, fmt.Errorf("failed to create a shadow copy of %s: %v", volume, err)
+	}
+	return shadow, nil
+}
+
+func runPowerShell(script string) ([]byte, error) {
+	out, err := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("%v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return out, nil
+}
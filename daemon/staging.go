@@ -0,0 +1,73 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/builder"
+	"github.com/docker/docker/distribution"
+	"github.com/docker/docker/pkg/staging"
+	"github.com/docker/go-units"
+)
+
+// initStaging sets up the staging areas of the pulls and of the builds of
+// config, and removes the temporary files left in them by a previous run of
+// the daemon.
+func (daemon *Daemon) initStaging(config *Config) error {
+	var err error
+	if daemon.downloadStaging, err = newStagingArea(config.DownloadStagingDir, config.DownloadStagingSize); err != nil {
+		return err
+	}
+	if daemon.buildStaging, err = newStagingArea(config.BuildStagingDir, config.BuildStagingSize); err != nil {
+		return err
+	}
+
+	for _, s := range []struct {
+		area     *staging.Area
+		prefixes []string
+	}{
+		{daemon.downloadStaging, distribution.StagingPrefixes},
+		{daemon.buildStaging, builder.StagingPrefixes},
+	} {
+		n, err := s.area.Cleanup(s.prefixes...)
+		if err != nil {
+			logrus.Warnf("Failed to remove the stale temporary files of %s: %v", s.area.Dir(), err)
+		}
+		if n > 0 {
+			logrus.Infof("Removed %d stale temporary files from %s", n, s.area.Dir())
+		}
+	}
+	return nil
+}
+
+// newStagingArea returns the staging area dir, or the temporary directory of
+// the daemon if dir is empty, with a quota of size.
+func newStagingArea(dir, size string) (*staging.Area, error) {
+	quota, err := parseStagingSize(size)
+	if err != nil {
+		return nil, err
+	}
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	return staging.New(dir, quota)
+}
+
+// parseStagingSize returns the quota in bytes of a staging area, 0 if size
+// is empty for no quota.
+func parseStagingSize(size string) (int64, error) {
+	if size == "" {
+		return 0, nil
+	}
+	n, err := units.FromHumanSize(size)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid staging size: %s", size)
+	}
+	return n, nil
+}
+
+// BuildStaging returns the staging area of the build contexts.
+func (daemon *Daemon) BuildStaging() *staging.Area {
+	return daemon.buildStaging
+}
@@ -6,29 +6,36 @@ import (
 	"runtime"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/container"
 	"github.com/docker/docker/errors"
 	"github.com/docker/docker/libcontainerd"
+	"github.com/docker/docker/pkg/requestid"
+	"github.com/docker/docker/pkg/tracing"
 	"github.com/docker/docker/runconfig"
+	"github.com/docker/engine-api/types"
 	containertypes "github.com/docker/engine-api/types/container"
+	"golang.org/x/net/context"
 )
 
-// ContainerStart starts a container.
-func (daemon *Daemon) ContainerStart(name string, hostConfig *containertypes.HostConfig) error {
+// ContainerStart starts a container. Its response lists the warnings about
+// the settings of the container, which the host may no longer honor.
+// Waiting on the container runtime is given up once ctx is done.
+func (daemon *Daemon) ContainerStart(ctx context.Context, name string, hostConfig *containertypes.HostConfig) (types.ContainerStartResponse, error) {
 	container, err := daemon.GetContainer(name)
 	if err != nil {
-		return err
+		return types.ContainerStartResponse{}, err
 	}
 
 	if container.IsPaused() {
-		return fmt.Errorf("Cannot start a paused container, try unpause instead.")
+		return types.ContainerStartResponse{}, fmt.Errorf("Cannot start a paused container, try unpause instead.")
 	}
 
 	if container.IsRunning() {
 		err := fmt.Errorf("Container already started")
-		return errors.NewErrorWithStatusCode(err, http.StatusNotModified)
+		return types.ContainerStartResponse{}, errors.NewErrorWithStatusCode(err, http.StatusNotModified)
 	}
 
 	// Windows does not have the backwards compatibility issue here.
@@ -39,13 +46,13 @@ func (daemon *Daemon) ContainerStart(name string, hostConfig *containertypes.Hos
 			logrus.Warn("DEPRECATED: Setting host configuration options when the container starts is deprecated and will be removed in Docker 1.12")
 			oldNetworkMode := container.HostConfig.NetworkMode
 			if err := daemon.setSecurityOptions(container, hostConfig); err != nil {
-				return err
+				return types.ContainerStartResponse{}, err
 			}
 			if err := daemon.mergeAndVerifyLogConfig(&hostConfig.LogConfig); err != nil {
-				return err
+				return types.ContainerStartResponse{}, err
 			}
 			if err := daemon.setHostConfig(container, hostConfig); err != nil {
-				return err
+				return types.ContainerStartResponse{}, err
 			}
 			newNetworkMode := container.HostConfig.NetworkMode
 			if string(oldNetworkMode) != string(newNetworkMode) {
@@ -53,41 +60,68 @@ func (daemon *Daemon) ContainerStart(name string, hostConfig *containertypes.Hos
 				// old networks. It is a deprecated feature and will be removed in Docker 1.12
 				container.NetworkSettings.Networks = nil
 				if err := container.ToDisk(); err != nil {
-					return err
+					return types.ContainerStartResponse{}, err
 				}
 			}
 			container.InitDNSHostConfig()
 		}
 	} else {
 		if hostConfig != nil {
-			return fmt.Errorf("Supplying a hostconfig on start is not supported. It should be supplied on create")
+			return types.ContainerStartResponse{}, fmt.Errorf("Supplying a hostconfig on start is not supported. It should be supplied on create")
 		}
 	}
 
 	// check if hostConfig is in line with the current system settings.
 	// It may happen cgroups are umounted or the like.
-	if _, err = daemon.verifyContainerSettings(container.HostConfig, nil, false); err != nil {
-		return err
+	warnings, err := daemon.verifyContainerSettings(container.HostConfig, nil, false)
+	if err != nil {
+		return types.ContainerStartResponse{}, err
 	}
 	// Adapt for old containers in case we have updates in this function and
 	// old containers never have chance to call the new function in create stage.
 	if err := daemon.adaptContainerSettings(container.HostConfig, false); err != nil {
-		return err
+		return types.ContainerStartResponse{}, err
+	}
+	if err := daemon.admitReservation(container, container.HostConfig); err != nil {
+		return types.ContainerStartResponse{}, err
 	}
 
-	return daemon.containerStart(container)
+	if err := daemon.containerStart(ctx, container); err != nil {
+		return types.ContainerStartResponse{}, daemon.portConflict(container, err)
+	}
+	if err := daemon.runLifecycleHooks(container, hookStart); err != nil {
+		if killErr := daemon.Kill(container); killErr != nil {
+			requestid.Logger(ctx).Errorf("Failed to kill container %s after its start hook failed: %v", container.ID, killErr)
+		}
+		return types.ContainerStartResponse{}, err
+	}
+	warnings = append(warnings, verifyPortBindings(container.HostConfig)...)
+	return types.ContainerStartResponse{
+		Warnings:       warningMessages(warnings),
+		WarningDetails: warnings,
+	}, nil
 }
 
 // Start starts a container
 func (daemon *Daemon) Start(container *container.Container) error {
-	return daemon.containerStart(container)
+	return daemon.containerStart(context.Background(), container)
 }
 
 // containerStart prepares the container to run by setting up everything the
 // container needs, such as storage and networking, as well as links
 // between containers. The container is left waiting for a signal to
 // begin running.
-func (daemon *Daemon) containerStart(container *container.Container) (err error) {
+func (daemon *Daemon) containerStart(ctx context.Context, container *container.Container) (err error) {
+	span, ctx := tracing.StartSpan(ctx, "container start")
+	span.SetTag("container", container.ID)
+	defer func() {
+		span.Finish(err)
+	}()
+
+	container.BeginOperation()
+	defer container.EndOperation()
+	defer daemon.watchOperation(container, "start")()
+
 	container.Lock()
 	defer container.Unlock()
 
@@ -99,6 +133,10 @@ func (daemon *Daemon) containerStart(container *container.Container) (err error)
 		return fmt.Errorf("Container is marked for removal and cannot be started.")
 	}
 
+	if err := checkJobStartable(container); err != nil {
+		return err
+	}
+
 	// if we encounter an error during start we need to ensure that any other
 	// setup has been cleaned up properly
 	defer func() {
@@ -108,29 +146,65 @@ func (daemon *Daemon) containerStart(container *container.Container) (err error)
 			if container.ExitCode == 0 {
 				container.ExitCode = 128
 			}
+			setJobCompleted(container)
 			container.ToDisk()
 			daemon.Cleanup(container)
 		}
 	}()
 
-	if err := daemon.conditionalMountOnStart(container); err != nil {
+	logger := requestid.Logger(ctx).WithField("container", container.ID)
+	begin := time.Now()
+	timer := &startTimer{begin: begin}
+	mountSpan, _ := tracing.StartSpan(ctx, "graphdriver mount")
+	mountSpan.SetTag("graphdriver", daemon.GraphDriverName())
+	err = daemon.conditionalMountOnStart(container)
+	mountSpan.Finish(err)
+	if err != nil {
 		return err
 	}
+	timer.mounted = time.Now()
+	logger.WithField("graphdriver", daemon.GraphDriverName()).Debugf("Mounted the root filesystem in %s", time.Since(begin))
 
 	// Make sure NetworkMode has an acceptable value. We do this to ensure
 	// backwards API compatibility.
 	container.HostConfig = runconfig.SetDefaultNetModeIfBlank(container.HostConfig)
 
-	if err := daemon.initializeNetworking(container); err != nil {
+	begin = time.Now()
+	networkSpan, _ := tracing.StartSpan(ctx, "network setup")
+	err = daemon.initializeNetworking(container)
+	networkSpan.Finish(err)
+	if err != nil {
 		return err
 	}
+	timer.networked = time.Now()
+	logger.Debugf("Initialized the networking in %s", time.Since(begin))
 
 	spec, err := daemon.createSpec(container)
 	if err != nil {
 		return err
 	}
 
-	if err := daemon.containerd.Create(container.ID, *spec, libcontainerd.WithRestartManager(container.RestartManager(true))); err != nil {
+	// Don't hold the lock while the runtime creates the container; the
+	// operation queue keeps other lifecycle operations out meanwhile, and
+	// the starting state keeps the events of the runtime from cleaning up
+	// after the container before the start is done with it.
+	restartManager := container.RestartManager(true)
+	container.SetStarting()
+	container.Unlock()
+	ctx, cancel := daemon.runtimeContext(ctx)
+	daemon.startTimers.add(container.ID, timer)
+	err = daemon.containerd.Create(ctx, container.ID, *spec, libcontainerd.WithRestartManager(restartManager))
+	daemon.startTimers.remove(container.ID)
+	cancel()
+	container.Lock()
+	container.ResetStarting()
+	if err == nil && !container.Running {
+		// the container exited before the lock was taken back, and its
+		// exit left the release of its resources to the start
+		daemon.Cleanup(container)
+	}
+	if err != nil {
+		code := runtimeErrorCode(err)
 		// if we receive an internal error from the initial start of a container then lets
 		// return it instead of entering the restart loop
 		// set to 127 for container cmd not found/does not exist)
@@ -139,6 +213,7 @@ func (daemon *Daemon) containerStart(container *container.Container) (err error)
 			strings.Contains(err.Error(), "system cannot find the file specified") {
 			container.ExitCode = 127
 			err = fmt.Errorf("Container command '%s' not found or does not exist", container.Path)
+			code = types.ErrorCodeNotFound
 		}
 		// set to 126 for container cmd can't be invoked errors
 		if strings.Contains(err.Error(), syscall.EACCES.Error()) {
@@ -148,7 +223,7 @@ func (daemon *Daemon) containerStart(container *container.Container) (err error)
 
 		container.Reset(false)
 
-		return err
+		return errors.NewRuntimeError(err, code, runtimeSubsystem)
 	}
 
 	return nil
@@ -156,7 +231,12 @@ func (daemon *Daemon) containerStart(container *container.Container) (err error)
 
 // Cleanup releases any network resources allocated to the container along with any rules
 // around how containers are linked together.  It also unmounts the container's root filesystem.
+// The resources of a container being created by the runtime are released by
+// its start instead, once the runtime is done.
 func (daemon *Daemon) Cleanup(container *container.Container) {
+	if container.IsStarting() {
+		return
+	}
 	daemon.releaseNetwork(container)
 
 	container.UnmountIpcMounts(detachMounted)
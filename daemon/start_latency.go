@@ -0,0 +1,154 @@
+package daemon
+
+import (
+	"bytes"
+	"expvar"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/container"
+)
+
+// startLatencyBuckets are the upper bounds, in seconds, of the buckets of
+// the histograms of the container start stages.
+var startLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// startLatency holds the histograms of the durations of the container
+// start stages, by stage. It is exported on the /debug/vars endpoint.
+var startLatency = newStartLatency()
+
+func newStartLatency() map[string]*histogram {
+	m := expvar.NewMap("container_start_seconds")
+	stages := map[string]*histogram{}
+	for _, stage := range []string{"image_mount", "network_setup", "runtime_create", "process_start", "total"} {
+		stages[stage] = newHistogram(startLatencyBuckets)
+		m.Set(stage, stages[stage])
+	}
+	return stages
+}
+
+// observeStartTimings adds the stage durations of t to the histograms.
+func observeStartTimings(t *container.StartTimings) {
+	startLatency["image_mount"].observe(t.ImageMount)
+	startLatency["network_setup"].observe(t.NetworkSetup)
+	startLatency["runtime_create"].observe(t.RuntimeCreate)
+	startLatency["process_start"].observe(t.ProcessStart)
+	startLatency["total"].observe(t.Total)
+}
+
+// startTimer records when the stages of a container start in progress
+// end. The runtime stages end in the callbacks of libcontainerd: when the
+// runtime attaches the streams of the process it created, and when it
+// reports the process started.
+type startTimer struct {
+	begin     time.Time
+	mounted   time.Time
+	networked time.Time
+	attached  time.Time
+}
+
+// timings returns the durations of the stages of t, for a start that
+// completed at running.
+func (t *startTimer) timings(running time.Time) *container.StartTimings {
+	attached := t.attached
+	if attached.IsZero() {
+		attached = running
+	}
+	return &container.StartTimings{
+		ImageMount:    t.mounted.Sub(t.begin),
+		NetworkSetup:  t.networked.Sub(t.mounted),
+		RuntimeCreate: attached.Sub(t.networked),
+		ProcessStart:  running.Sub(attached),
+		Total:         running.Sub(t.begin),
+	}
+}
+
+// startTimers holds the timers of the container starts waiting on the
+// runtime, by container ID.
+type startTimers struct {
+	mu     sync.Mutex
+	timers map[string]*startTimer
+}
+
+func (s *startTimers) add(id string, t *startTimer) {
+	s.mu.Lock()
+	if s.timers == nil {
+		s.timers = make(map[string]*startTimer)
+	}
+	s.timers[id] = t
+	s.mu.Unlock()
+}
+
+func (s *startTimers) remove(id string) {
+	s.mu.Lock()
+	delete(s.timers, id)
+	s.mu.Unlock()
+}
+
+// attached records that the runtime attached the streams of the process of
+// the container id, if it is being started.
+func (s *startTimers) attached(id string) {
+	s.mu.Lock()
+	if t, ok := s.timers[id]; ok {
+		t.attached = time.Now()
+	}
+	s.mu.Unlock()
+}
+
+// running returns the stage timings of the start of the container id,
+// whose process runs, or nil if it isn't being started, such as when it is
+// restarted by its restart policy.
+func (s *startTimers) running(id string) *container.StartTimings {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.timers[id]
+	if !ok {
+		return nil
+	}
+	return t.timings(time.Now())
+}
+
+// histogram is a histogram of durations, exported as an expvar.Var with
+// cumulative bucket counts, like the Prometheus histograms.
+type histogram struct {
+	mu      sync.Mutex
+	bounds  []float64
+	buckets []int64
+	count   int64
+	sum     float64
+}
+
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{
+		bounds:  bounds,
+		buckets: make([]int64, len(bounds)),
+	}
+}
+
+func (h *histogram) observe(d time.Duration) {
+	v := d.Seconds()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, bound := range h.bounds {
+		if v <= bound {
+			h.buckets[i]++
+		}
+	}
+	h.count++
+	h.sum += v
+}
+
+// String returns the histogram as JSON, implementing expvar.Var.
+func (h *histogram) String() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `{"count": %d, "sum": %s, "buckets": {`, h.count, strconv.FormatFloat(h.sum, 'g', -1, 64))
+	for i, bound := range h.bounds {
+		fmt.Fprintf(&b, `"%s": %d, `, strconv.FormatFloat(bound, 'g', -1, 64), h.buckets[i])
+	}
+	fmt.Fprintf(&b, `"+Inf": %d}}`, h.count)
+	return b.String()
+}
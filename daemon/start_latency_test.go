@@ -0,0 +1,78 @@
+package daemon
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestStartTimerTimings(t *testing.T) {
+	begin := time.Now()
+	timer := &startTimer{
+		begin:     begin,
+		mounted:   begin.Add(10 * time.Millisecond),
+		networked: begin.Add(30 * time.Millisecond),
+		attached:  begin.Add(100 * time.Millisecond),
+	}
+	timings := timer.timings(begin.Add(110 * time.Millisecond))
+	for _, c := range []struct {
+		stage    string
+		actual   time.Duration
+		expected time.Duration
+	}{
+		{"image mount", timings.ImageMount, 10 * time.Millisecond},
+		{"network setup", timings.NetworkSetup, 20 * time.Millisecond},
+		{"runtime create", timings.RuntimeCreate, 70 * time.Millisecond},
+		{"process start", timings.ProcessStart, 10 * time.Millisecond},
+		{"total", timings.Total, 110 * time.Millisecond},
+	} {
+		if c.actual != c.expected {
+			t.Fatalf("expected %s to take %s, got %s", c.stage, c.expected, c.actual)
+		}
+	}
+}
+
+func TestStartTimers(t *testing.T) {
+	var timers startTimers
+	if timings := timers.running("c1"); timings != nil {
+		t.Fatalf("expected no timings for a container not being started, got %+v", timings)
+	}
+	now := time.Now()
+	timers.add("c1", &startTimer{begin: now, mounted: now, networked: now})
+	timers.attached("c1")
+	timings := timers.running("c1")
+	if timings == nil {
+		t.Fatal("expected the timings of the container being started, got nil")
+	}
+	if timings.Total < timings.RuntimeCreate+timings.ProcessStart {
+		t.Fatalf("expected the total to include the stages, got %+v", timings)
+	}
+	timers.remove("c1")
+	if timings := timers.running("c1"); timings != nil {
+		t.Fatalf("expected no timings once the start is done, got %+v", timings)
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	h := newHistogram([]float64{0.1, 1})
+	h.observe(50 * time.Millisecond)
+	h.observe(500 * time.Millisecond)
+	h.observe(5 * time.Second)
+
+	var v struct {
+		Count   int64
+		Sum     float64
+		Buckets map[string]int64
+	}
+	if err := json.Unmarshal([]byte(h.String()), &v); err != nil {
+		t.Fatalf("invalid histogram JSON %s: %v", h.String(), err)
+	}
+	if v.Count != 3 || v.Sum != 5.55 {
+		t.Fatalf("expected a count of 3 and a sum of 5.55, got %d and %v", v.Count, v.Sum)
+	}
+	for bound, expected := range map[string]int64{"0.1": 1, "1": 2, "+Inf": 3} {
+		if v.Buckets[bound] != expected {
+			t.Fatalf("expected %d in the bucket %s, got %d", expected, bound, v.Buckets[bound])
+		}
+	}
+}
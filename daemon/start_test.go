@@ -0,0 +1,26 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/docker/docker/container"
+)
+
+func TestCleanupLeavesStartingContainerToStart(t *testing.T) {
+	c := &container.Container{
+		CommonContainer: container.CommonContainer{
+			ID:    "starting",
+			State: container.NewState(),
+		},
+	}
+	c.SetStarting()
+
+	// A daemon without a network controller nor a layer store would panic
+	// if the resources of the container were released.
+	(&Daemon{}).Cleanup(c)
+
+	c.ResetStarting()
+	if c.IsStarting() {
+		t.Fatal("Expected the container not to be starting after ResetStarting")
+	}
+}
@@ -2,8 +2,6 @@ package daemon
 
 import (
 	"encoding/json"
-	"errors"
-	"runtime"
 
 	"golang.org/x/net/context"
 
@@ -17,9 +15,6 @@ import (
 // ContainerStats writes information about the container to the stream
 // given in the config object.
 func (daemon *Daemon) ContainerStats(ctx context.Context, prefixOrName string, config *backend.ContainerStatsConfig) error {
-	if runtime.GOOS == "windows" {
-		return errors.New("Windows does not support stats")
-	}
 	// Remote API version (used for backwards compatibility)
 	apiVersion := config.Version
 
@@ -0,0 +1,202 @@
+package daemon
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/container"
+	"github.com/docker/engine-api/types"
+)
+
+// cgroup2Root is the mount point of the unified hierarchy of cgroup v2.
+const cgroup2Root = "/sys/fs/cgroup"
+
+// cgroup2Stats returns the stats of c from its cgroup in the unified
+// hierarchy, which the runtime does not report.
+func (daemon *Daemon) cgroup2Stats(c *container.Container) (*types.StatsJSON, error) {
+	dir, err := cgroup2Path(c.State.Pid)
+	if err != nil {
+		return nil, err
+	}
+	s, err := readCgroup2Stats(filepath.Join(cgroup2Root, dir))
+	if err != nil {
+		return nil, err
+	}
+	// if the container does not set memory limit, use the machineMemory
+	if s.MemoryStats.Limit == 0 || s.MemoryStats.Limit > daemon.statsCollector.machineMemory && daemon.statsCollector.machineMemory > 0 {
+		s.MemoryStats.Limit = daemon.statsCollector.machineMemory
+	}
+	s.CPUStats.OnlineCPUs = uint32(runtime.NumCPU())
+	s.Read = time.Now()
+	return s, nil
+}
+
+// cgroup2Path returns the path of the cgroup of the process pid in the
+// unified hierarchy.
+func cgroup2Path(pid int) (string, error) {
+	f, err := os.Open(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if strings.HasPrefix(s.Text(), "0::") {
+			return strings.TrimPrefix(s.Text(), "0::"), nil
+		}
+	}
+	if err := s.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("no cgroup v2 found for the process %d", pid)
+}
+
+// readCgroup2Stats returns the stats of the cgroup v2 dir. The stats of the
+// controllers not enabled for dir are left empty.
+func readCgroup2Stats(dir string) (*types.StatsJSON, error) {
+	s := &types.StatsJSON{}
+
+	// the times of cpu.stat are in microseconds
+	cpu, err := readCgroup2KeyValues(filepath.Join(dir, "cpu.stat"))
+	if err != nil {
+		return nil, err
+	}
+	s.CPUStats.CPUUsage = types.CPUUsage{
+		TotalUsage:        cpu["usage_usec"] * 1000,
+		UsageInKernelmode: cpu["system_usec"] * 1000,
+		UsageInUsermode:   cpu["user_usec"] * 1000,
+	}
+	s.CPUStats.ThrottlingData = types.ThrottlingData{
+		Periods:          cpu["nr_periods"],
+		ThrottledPeriods: cpu["nr_throttled"],
+		ThrottledTime:    cpu["throttled_usec"] * 1000,
+	}
+
+	if s.MemoryStats.Stats, err = readCgroup2KeyValues(filepath.Join(dir, "memory.stat")); err != nil {
+		return nil, err
+	}
+	if s.MemoryStats.Usage, err = readCgroup2Value(filepath.Join(dir, "memory.current")); err != nil {
+		return nil, err
+	}
+	if s.MemoryStats.MaxUsage, err = readCgroup2Value(filepath.Join(dir, "memory.peak")); err != nil {
+		return nil, err
+	}
+	if s.MemoryStats.Limit, err = readCgroup2Value(filepath.Join(dir, "memory.max")); err != nil {
+		return nil, err
+	}
+	events, err := readCgroup2KeyValues(filepath.Join(dir, "memory.events"))
+	if err != nil {
+		return nil, err
+	}
+	s.MemoryStats.Failcnt = events["max"]
+
+	if s.PidsStats.Current, err = readCgroup2Value(filepath.Join(dir, "pids.current")); err != nil {
+		return nil, err
+	}
+
+	if err := readCgroup2IOStats(filepath.Join(dir, "io.stat"), &s.BlkioStats); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// readCgroup2Value returns the value of the cgroup file path, or 0 if the
+// file does not exist or its value is "max".
+func readCgroup2Value(path string) (uint64, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	v := strings.TrimSpace(string(b))
+	if v == "max" {
+		return 0, nil
+	}
+	return strconv.ParseUint(v, 10, 64)
+}
+
+// readCgroup2KeyValues returns the values of the cgroup file path, made of a
+// key and a value per line, such as cpu.stat.
+func readCgroup2KeyValues(path string) (map[string]uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	values := make(map[string]uint64)
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		v, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value in %s: %v", path, err)
+		}
+		values[fields[0]] = v
+	}
+	return values, s.Err()
+}
+
+// readCgroup2IOStats reads io.stat at path in stats, which has a line per
+// device such as "8:0 rbytes=1024 wbytes=0 rios=1 wios=0".
+func readCgroup2IOStats(path string, stats *types.BlkioStats) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		var major, minor uint64
+		if _, err := fmt.Sscanf(fields[0], "%d:%d", &major, &minor); err != nil {
+			return fmt.Errorf("invalid device in %s: %q", path, fields[0])
+		}
+		for _, field := range fields[1:] {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			v, err := strconv.ParseUint(kv[1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid value in %s: %v", path, err)
+			}
+			entry := types.BlkioStatEntry{Major: major, Minor: minor, Value: v}
+			switch kv[0] {
+			case "rbytes":
+				entry.Op = "Read"
+				stats.IoServiceBytesRecursive = append(stats.IoServiceBytesRecursive, entry)
+			case "wbytes":
+				entry.Op = "Write"
+				stats.IoServiceBytesRecursive = append(stats.IoServiceBytesRecursive, entry)
+			case "rios":
+				entry.Op = "Read"
+				stats.IoServicedRecursive = append(stats.IoServicedRecursive, entry)
+			case "wios":
+				entry.Op = "Write"
+				stats.IoServicedRecursive = append(stats.IoServicedRecursive, entry)
+			}
+		}
+	}
+	return s.Err()
+}
@@ -0,0 +1,57 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadCgroup2Stats(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cgroup2-stats")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"cpu.stat":       "usage_usec 2000\nuser_usec 1500\nsystem_usec 500\nnr_periods 10\nnr_throttled 2\nthrottled_usec 300\n",
+		"memory.current": "4096\n",
+		"memory.max":     "max\n",
+		"memory.stat":    "anon 1024\nfile 2048\n",
+		"memory.events":  "low 0\nhigh 0\nmax 3\noom 1\noom_kill 1\n",
+		"pids.current":   "5\n",
+		"io.stat":        "8:0 rbytes=1024 wbytes=512 rios=4 wios=2 dbytes=0 dios=0\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s, err := readCgroup2Stats(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cpu := s.CPUStats
+	if cpu.CPUUsage.TotalUsage != 2000000 || cpu.CPUUsage.UsageInUsermode != 1500000 || cpu.CPUUsage.UsageInKernelmode != 500000 {
+		t.Fatalf("unexpected cpu usage %+v", cpu.CPUUsage)
+	}
+	if cpu.ThrottlingData.Periods != 10 || cpu.ThrottlingData.ThrottledPeriods != 2 || cpu.ThrottlingData.ThrottledTime != 300000 {
+		t.Fatalf("unexpected throttling data %+v", cpu.ThrottlingData)
+	}
+	mem := s.MemoryStats
+	if mem.Usage != 4096 || mem.Limit != 0 || mem.Failcnt != 3 || mem.Stats["file"] != 2048 {
+		t.Fatalf("unexpected memory stats %+v", mem)
+	}
+	if s.PidsStats.Current != 5 {
+		t.Fatalf("expected 5 pids, got %d", s.PidsStats.Current)
+	}
+	bytes := s.BlkioStats.IoServiceBytesRecursive
+	if len(bytes) != 2 || bytes[0].Op != "Read" || bytes[0].Value != 1024 || bytes[1].Major != 8 || bytes[1].Value != 512 {
+		t.Fatalf("unexpected io bytes %+v", bytes)
+	}
+	if ios := s.BlkioStats.IoServicedRecursive; len(ios) != 2 || ios[1].Op != "Write" || ios[1].Value != 2 {
+		t.Fatalf("unexpected io operations %+v", ios)
+	}
+}
@@ -0,0 +1,15 @@
+// +build !linux,!windows
+
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/docker/docker/container"
+	"github.com/docker/engine-api/types"
+)
+
+// cgroup2Stats returns an error, cgroup v2 is only supported on Linux.
+func (daemon *Daemon) cgroup2Stats(c *container.Container) (*types.StatsJSON, error) {
+	return nil, fmt.Errorf("cgroup v2 is not supported on this platform")
+}
@@ -1,35 +1,33 @@
 package daemon
 
 import (
-	"time"
-
-	"github.com/docker/docker/container"
+	"syscall"
+	"unsafe"
 )
 
-// newStatsCollector returns a new statsCollector for collection stats
-// for a registered container at the specified interval. The collector allows
-// non-running containers to be added and will start processing stats when
-// they are started.
-func (daemon *Daemon) newStatsCollector(interval time.Duration) *statsCollector {
-	return &statsCollector{}
-}
-
-// statsCollector manages and provides container resource stats
-type statsCollector struct {
-}
+var procGetSystemTimes = syscall.NewLazyDLL("kernel32.dll").NewProc("GetSystemTimes")
 
-// collect registers the container with the collector and adds it to
-// the event loop for collection on the specified interval returning
-// a channel for the subscriber to receive on.
-func (s *statsCollector) collect(c *container.Container) chan interface{} {
-	return nil
+// platformInit initializes the state the collector reads the host system
+// CPU usage with. Windows needs none.
+func (s *statsCollector) platformInit() {
 }
 
-// stopCollection closes the channels for all subscribers and removes
-// the container from metrics collection.
-func (s *statsCollector) stopCollection(c *container.Container) {
+// getSystemCPUUsage returns the host system's cpu usage in nanoseconds,
+// the sum of the kernel and user times of all the processors, the kernel
+// time including the idle time.
+func (s *statsCollector) getSystemCPUUsage() (uint64, error) {
+	var idle, kernel, user syscall.Filetime
+	r1, _, err := procGetSystemTimes.Call(
+		uintptr(unsafe.Pointer(&idle)),
+		uintptr(unsafe.Pointer(&kernel)),
+		uintptr(unsafe.Pointer(&user)))
+	if r1 == 0 {
+		return 0, err
+	}
+	return (filetime100ns(kernel) + filetime100ns(user)) * 100, nil
 }
 
-// unsubscribe removes a specific subscriber from receiving updates for a container's stats.
-func (s *statsCollector) unsubscribe(c *container.Container, ch chan interface{}) {
+// filetime100ns returns the duration ft holds, in units of 100 nanoseconds.
+func filetime100ns(ft syscall.Filetime) uint64 {
+	return uint64(ft.HighDateTime)<<32 | uint64(ft.LowDateTime)
 }
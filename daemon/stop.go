@@ -2,21 +2,33 @@ package daemon
 
 import (
 	"fmt"
+	"io"
 	"net/http"
+	"sort"
 	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/container"
 	"github.com/docker/docker/errors"
+	"github.com/docker/docker/pkg/progress"
+	"github.com/docker/docker/pkg/signal"
+	"github.com/docker/docker/pkg/streamformatter"
+	"github.com/docker/docker/pkg/stringid"
+	"golang.org/x/net/context"
 )
 
+// stopProgressInterval is the interval of the countdown written to the
+// progress of a stop while waiting for the container to exit.
+const stopProgressInterval = time.Second
+
 // ContainerStop looks for the given container and terminates it,
 // waiting the given number of seconds before forcefully killing the
 // container. If a negative number of seconds is given, ContainerStop
 // will wait for a graceful termination. An error is returned if the
 // container is not found, is already stopped, or if there is a
-// problem stopping the container.
-func (daemon *Daemon) ContainerStop(name string, seconds int) error {
+// problem stopping the container. If outStream is not nil, the phases
+// of the stop are written to it as JSON progress messages.
+func (daemon *Daemon) ContainerStop(name string, seconds int, outStream io.Writer) error {
 	container, err := daemon.GetContainer(name)
 	if err != nil {
 		return err
@@ -25,9 +37,11 @@ func (daemon *Daemon) ContainerStop(name string, seconds int) error {
 		err := fmt.Errorf("Container %s is already stopped", name)
 		return errors.NewErrorWithStatusCode(err, http.StatusNotModified)
 	}
-	if err := daemon.containerStop(container, seconds); err != nil {
+	out := newStopProgress(outStream)
+	if err := daemon.containerStop(container, seconds, out); err != nil {
 		return fmt.Errorf("Cannot stop container %s: %v", name, err)
 	}
+	stopProgressf(out, container, "Stopped")
 	return nil
 }
 
@@ -35,24 +49,27 @@ func (daemon *Daemon) ContainerStop(name string, seconds int) error {
 // duration in seconds, and then calling SIGKILL and waiting for the
 // process to exit. If a negative duration is given, Stop will wait
 // for the initial signal forever. If the container is not running Stop returns
-// immediately.
-func (daemon *Daemon) containerStop(container *container.Container, seconds int) error {
+// immediately. The phases of the stop are written to out, if not nil.
+func (daemon *Daemon) containerStop(container *container.Container, seconds int, out progress.Output) error {
 	if !container.IsRunning() {
 		return nil
 	}
 
 	stopSignal := container.StopSignal()
 	// 1. Send a stop signal
-	if err := daemon.killPossiblyDeadProcess(container, stopSignal); err != nil {
+	stopProgressf(out, container, "Sending %s", signalName(stopSignal))
+	if err := daemon.killPossiblyDeadProcess(context.Background(), container, stopSignal); err != nil {
 		logrus.Infof("Failed to send signal %d to the process, force killing", stopSignal)
-		if err := daemon.killPossiblyDeadProcess(container, 9); err != nil {
+		stopProgressf(out, container, "Failed to send %s, killing", signalName(stopSignal))
+		if err := daemon.killPossiblyDeadProcess(context.Background(), container, 9); err != nil {
 			return err
 		}
 	}
 
 	// 2. Wait for the process to exit on its own
-	if _, err := container.WaitStop(time.Duration(seconds) * time.Second); err != nil {
+	if err := waitStopWithProgress(container, seconds, out); err != nil {
 		logrus.Infof("Container %v failed to exit within %d seconds of signal %d - using the force", container.ID, seconds, stopSignal)
+		stopProgressf(out, container, "Did not exit within %d seconds, killing", seconds)
 		// 3. If it doesn't, then send SIGKILL
 		if err := daemon.Kill(container); err != nil {
 			container.WaitStop(-1 * time.Second)
@@ -63,3 +80,75 @@ func (daemon *Daemon) containerStop(container *container.Container, seconds int)
 	daemon.LogContainerEvent(container, "stop")
 	return nil
 }
+
+// waitStopWithProgress waits for container to stop for the given duration
+// in seconds, forever if negative, writing a countdown to out every
+// stopProgressInterval.
+func waitStopWithProgress(container *container.Container, seconds int, out progress.Output) error {
+	timeout := time.Duration(seconds) * time.Second
+	if out == nil {
+		_, err := container.WaitStop(timeout)
+		return err
+	}
+
+	stopped := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		begin := time.Now()
+		ticker := time.NewTicker(stopProgressInterval)
+		defer ticker.Stop()
+		for {
+			elapsed := time.Since(begin)
+			if seconds < 0 {
+				stopProgressf(out, container, "Waiting for the container to exit (%s elapsed)", elapsed/time.Second*time.Second)
+			} else {
+				left := timeout - elapsed + time.Second/2
+				if left < 0 {
+					left = 0
+				}
+				stopProgressf(out, container, "Waiting for the container to exit (%s left)", left/time.Second*time.Second)
+			}
+			select {
+			case <-ticker.C:
+			case <-stopped:
+				return
+			}
+		}
+	}()
+	_, err := container.WaitStop(timeout)
+	close(stopped)
+	<-done
+	return err
+}
+
+// newStopProgress returns the progress output of a stop writing to
+// outStream, or nil if outStream is nil.
+func newStopProgress(outStream io.Writer) progress.Output {
+	if outStream == nil {
+		return nil
+	}
+	return streamformatter.NewJSONStreamFormatter().NewProgressOutput(outStream, false)
+}
+
+// stopProgressf writes a phase of the stop of container to out, if not nil.
+func stopProgressf(out progress.Output, container *container.Container, format string, a ...interface{}) {
+	if out != nil {
+		progress.Updatef(out, stringid.TruncateID(container.ID), format, a...)
+	}
+}
+
+// signalName returns the name of the signal sig, such as "SIGTERM".
+func signalName(sig int) string {
+	var names []string
+	for name, s := range signal.SignalMap {
+		if int(s) == sig {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return fmt.Sprintf("signal %d", sig)
+	}
+	sort.Strings(names)
+	return "SIG" + names[0]
+}
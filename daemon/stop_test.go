@@ -0,0 +1,46 @@
+package daemon
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/pkg/progress"
+)
+
+func TestSignalName(t *testing.T) {
+	if name := signalName(15); name != "SIGTERM" {
+		t.Fatalf("expected SIGTERM, got %s", name)
+	}
+	if name := signalName(1000); name != "signal 1000" {
+		t.Fatalf("expected an unknown signal to be named by its number, got %s", name)
+	}
+}
+
+func TestWaitStopWithProgress(t *testing.T) {
+	c := container.NewBaseContainer("2a6b3a8b4d5c6e7f", "")
+	c.SetRunning(1, true)
+
+	ch := make(chan progress.Progress, 100)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		c.SetStoppedLocking(&container.ExitStatus{})
+	}()
+	if err := waitStopWithProgress(c, 10, progress.ChanOutput(ch)); err != nil {
+		t.Fatalf("expected the container to stop, got %v", err)
+	}
+	close(ch)
+	p, ok := <-ch
+	if !ok {
+		t.Fatal("expected a countdown while waiting for the container to exit")
+	}
+	if p.ID != "2a6b3a8b4d5c" || !strings.Contains(p.Action, "10s left") {
+		t.Fatalf("expected a countdown of the container from 10s, got %+v", p)
+	}
+
+	c.SetRunning(1, false)
+	if err := waitStopWithProgress(c, 0, nil); err == nil {
+		t.Fatal("expected a timeout waiting for a running container, got nil")
+	}
+}
@@ -0,0 +1,373 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/api/types/backend"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/errors"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/pkg/ioutils"
+	"github.com/docker/docker/reference"
+	"github.com/docker/docker/runconfig"
+	runconfigopts "github.com/docker/docker/runconfig/opts"
+	"github.com/docker/docker/volume"
+	containertypes "github.com/docker/engine-api/types/container"
+	networktypes "github.com/docker/engine-api/types/network"
+	"github.com/docker/libnetwork"
+)
+
+// imageOwners holds the users owning the images in tenancy mode, stored as
+// JSON in a file. An image has as many owners as users who pulled, built,
+// committed or imported it.
+type imageOwners struct {
+	mu     sync.Mutex
+	path   string
+	owners map[image.ID][]string
+}
+
+// newImageOwners returns the image owners stored at path.
+func newImageOwners(path string) (*imageOwners, error) {
+	o := &imageOwners{
+		path:   path,
+		owners: make(map[image.ID][]string),
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return o, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &o.owners); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// add records tenant as an owner of id.
+func (o *imageOwners) add(id image.ID, tenant string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, owner := range o.owners[id] {
+		if owner == tenant {
+			return
+		}
+	}
+	o.owners[id] = append(o.owners[id], tenant)
+	o.save()
+}
+
+// get returns the owners of id.
+func (o *imageOwners) get(id image.ID) []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]string(nil), o.owners[id]...)
+}
+
+// owns returns whether tenant owns id.
+func (o *imageOwners) owns(id image.ID, tenant string) bool {
+	for _, owner := range o.get(id) {
+		if owner == tenant {
+			return true
+		}
+	}
+	return false
+}
+
+// remove forgets the owners of id.
+func (o *imageOwners) remove(id image.ID) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if _, ok := o.owners[id]; ok {
+		delete(o.owners, id)
+		o.save()
+	}
+}
+
+// save writes the owners to disk. o must be locked.
+func (o *imageOwners) save() {
+	b, err := json.Marshal(o.owners)
+	if err == nil {
+		err = ioutils.AtomicWriteFile(o.path, b, 0600)
+	}
+	if err != nil {
+		logrus.Errorf("Failed to save the owners of the images: %v", err)
+	}
+}
+
+// CheckTenant returns a not found error unless tenant owns the object name
+// of kind, "containers", "exec", "images", "jobs", "networks" or "volumes".
+// The predefined networks are shared by all the users.
+func (daemon *Daemon) CheckTenant(kind, name, tenant string) error {
+	switch kind {
+	case "containers":
+		c, err := daemon.GetContainer(name)
+		if err != nil {
+			return err
+		}
+		if c.Config.Labels[backend.OwnerLabel] != tenant {
+			return errors.NewRequestNotFoundError(fmt.Errorf("No such container: %s", name))
+		}
+	case "exec":
+		ec := daemon.execCommands.Get(name)
+		if ec == nil {
+			return errExecNotFound(name)
+		}
+		if c := daemon.containers.Get(ec.ContainerID); c == nil || c.Config.Labels[backend.OwnerLabel] != tenant {
+			return errExecNotFound(name)
+		}
+	case "images":
+		id, err := daemon.GetImageID(name)
+		if err != nil {
+			return daemon.imageNotExistToErrcode(err)
+		}
+		if !daemon.imageOwners.owns(id, tenant) {
+			return daemon.imageNotExistToErrcode(ErrImageDoesNotExist{name})
+		}
+	case "jobs":
+		j, err := daemon.JobInspect(name)
+		if err != nil {
+			return err
+		}
+		if j.Spec.Config.Labels[backend.OwnerLabel] != tenant {
+			return errors.NewRequestNotFoundError(fmt.Errorf("No such job: %s", name))
+		}
+	case "networks":
+		n, err := daemon.FindNetwork(name)
+		if err != nil {
+			return err
+		}
+		if !runconfig.IsPreDefinedNetwork(n.Name()) && n.Info().Labels()[backend.OwnerLabel] != tenant {
+			return errors.NewRequestNotFoundError(libnetwork.ErrNoSuchNetwork(name))
+		}
+	case "volumes":
+		v, err := daemon.volumes.Get(name)
+		if err != nil {
+			return err
+		}
+		if volumeOwner(v) != tenant {
+			return errors.NewRequestNotFoundError(fmt.Errorf("no such volume: %s", name))
+		}
+	default:
+		return fmt.Errorf("unknown kind of object %s", kind)
+	}
+	return nil
+}
+
+// ClaimImage records tenant as an owner of the image refOrID.
+func (daemon *Daemon) ClaimImage(refOrID, tenant string) error {
+	id, err := daemon.GetImageID(refOrID)
+	if err != nil {
+		return err
+	}
+	daemon.imageOwners.add(id, tenant)
+	return nil
+}
+
+// CheckTenantReference returns a forbidden error if the reference ref, a
+// repository with an optional tag, refers to an image tenant does not own,
+// so that a user doesn't move the references of the others.
+func (daemon *Daemon) CheckTenantReference(ref, tenant string) error {
+	named, err := reference.ParseNamed(ref)
+	if err != nil {
+		return err
+	}
+	id, err := daemon.referenceStore.Get(reference.WithDefaultTag(named))
+	if err != nil {
+		// the reference is new
+		return nil
+	}
+	if !daemon.imageOwners.owns(id, tenant) {
+		return errors.NewRequestForbiddenError(fmt.Errorf("%s refers to an image of another user", ref))
+	}
+	return nil
+}
+
+// ImageOwners returns the users owning the image refOrID in tenancy mode.
+func (daemon *Daemon) ImageOwners(refOrID string) ([]string, error) {
+	id, err := daemon.GetImageID(refOrID)
+	if err != nil {
+		return nil, err
+	}
+	return daemon.imageOwners.get(id), nil
+}
+
+// volumeOwner returns the user owning v in tenancy mode.
+func volumeOwner(v volume.Volume) string {
+	if v, ok := v.(interface {
+		Labels() map[string]string
+	}); ok {
+		return v.Labels()[backend.OwnerLabel]
+	}
+	return ""
+}
+
+// ownerLabels returns the labels of the volumes created for c, which belong
+// to the owner of c in tenancy mode.
+func ownerLabels(c *container.Container) map[string]string {
+	if owner := c.Config.Labels[backend.OwnerLabel]; owner != "" {
+		return map[string]string{backend.OwnerLabel: owner}
+	}
+	return nil
+}
+
+// verifyTenantReferences returns an error if a container of the user owner,
+// created with config, hostConfig and networkingConfig, uses an image, or
+// references containers, networks or volumes, that owner does not own.
+func (daemon *Daemon) verifyTenantReferences(owner string, config *containertypes.Config, hostConfig *containertypes.HostConfig, networkingConfig *networktypes.NetworkingConfig) error {
+	if owner == "" || !daemon.configStore.Tenancy {
+		return nil
+	}
+	if config.Image != "" {
+		if err := daemon.CheckTenant("images", config.Image, owner); err != nil {
+			return err
+		}
+	}
+	if hostConfig == nil {
+		return nil
+	}
+	if err := verifyTenantHostAccess(hostConfig); err != nil {
+		return err
+	}
+
+	var containers []string
+	for _, volumesFrom := range hostConfig.VolumesFrom {
+		containers = append(containers, strings.SplitN(volumesFrom, ":", 2)[0])
+	}
+	for _, link := range hostConfig.Links {
+		name, _, err := runconfigopts.ParseLink(link)
+		if err != nil {
+			return err
+		}
+		containers = append(containers, name)
+	}
+	if hostConfig.NetworkMode.IsContainer() {
+		containers = append(containers, hostConfig.NetworkMode.ConnectedContainer())
+	}
+	if hostConfig.IpcMode.IsContainer() {
+		containers = append(containers, hostConfig.IpcMode.Container())
+	}
+	for _, name := range containers {
+		if err := daemon.CheckTenant("containers", name, owner); err != nil {
+			return err
+		}
+	}
+
+	var networks []string
+	if hostConfig.NetworkMode.IsUserDefined() {
+		networks = append(networks, hostConfig.NetworkMode.NetworkName())
+	}
+	if networkingConfig != nil {
+		for name := range networkingConfig.EndpointsConfig {
+			networks = append(networks, name)
+		}
+	}
+	for _, name := range networks {
+		if err := daemon.CheckTenant("networks", name, owner); err != nil {
+			return err
+		}
+	}
+
+	// the named volumes missing are created for owner
+	for _, bind := range hostConfig.Binds {
+		mp, err := volume.ParseMountSpec(bind, hostConfig.VolumeDriver)
+		if err != nil || mp.Name == "" {
+			continue
+		}
+		if _, err := daemon.volumes.Get(mp.Name); err != nil {
+			continue
+		}
+		if err := daemon.CheckTenant("volumes", mp.Name, owner); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyTenantHostAccess returns a forbidden error if a container of a user
+// created with hostConfig would have access to the host: the users may not
+// bind-mount the paths of the host, use its devices or network interfaces,
+// run privileged containers or hooks, share the namespaces of the host, add
+// capabilities, weaken the confinement of the container, nor set its parent
+// cgroup or its sysctls.
+func verifyTenantHostAccess(hostConfig *containertypes.HostConfig) error {
+	var denied []string
+	if hostConfig.Privileged {
+		denied = append(denied, "privileged mode")
+	}
+	if len(hostConfig.Devices) > 0 {
+		denied = append(denied, "devices of the host")
+	}
+	if len(hostConfig.NetworkDevices) > 0 {
+		denied = append(denied, "network devices of the host")
+	}
+	if h := hostConfig.Hooks; h != nil && len(h.Prestart)+len(h.Poststart)+len(h.Poststop) > 0 {
+		denied = append(denied, "hooks")
+	}
+	if len(hostConfig.CapAdd) > 0 {
+		denied = append(denied, "added capabilities")
+	}
+	if hasTenantDeniedSecurityOpt(hostConfig.SecurityOpt) {
+		denied = append(denied, "seccomp, AppArmor and label disabling security options")
+	}
+	if hostConfig.CgroupParent != "" {
+		denied = append(denied, "parent cgroups")
+	}
+	if len(hostConfig.Sysctls) > 0 {
+		denied = append(denied, "sysctls")
+	}
+	if hostConfig.NetworkMode.IsHost() {
+		denied = append(denied, "host network mode")
+	}
+	if hostConfig.PidMode.IsHost() {
+		denied = append(denied, "host PID mode")
+	}
+	if hostConfig.IpcMode.IsHost() {
+		denied = append(denied, "host IPC mode")
+	}
+	if hostConfig.UTSMode.IsHost() {
+		denied = append(denied, "host UTS mode")
+	}
+	if hostConfig.UsernsMode.IsHost() {
+		denied = append(denied, "host user namespace mode")
+	}
+	for _, bind := range hostConfig.Binds {
+		mp, err := volume.ParseMountSpec(bind, hostConfig.VolumeDriver)
+		if err == nil && mp.Name == "" {
+			denied = append(denied, "bind mounts of host paths")
+			break
+		}
+	}
+	if len(denied) > 0 {
+		return errors.NewRequestForbiddenError(fmt.Errorf("%s not allowed in tenancy mode", strings.Join(denied, ", ")))
+	}
+	return nil
+}
+
+// hasTenantDeniedSecurityOpt returns whether opts set the seccomp or AppArmor
+// profile of a container, a custom profile being able to allow as much as
+// unconfined, or disable its labeling.
+func hasTenantDeniedSecurityOpt(opts []string) bool {
+	for _, opt := range opts {
+		con := strings.SplitN(opt, "=", 2)
+		if len(con) != 2 {
+			con = strings.SplitN(opt, ":", 2)
+		}
+		switch con[0] {
+		case "seccomp", "apparmor":
+			return true
+		case "label":
+			if len(con) == 2 && con[1] == "disable" {
+				return true
+			}
+		}
+	}
+	return false
+}
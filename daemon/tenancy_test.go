@@ -0,0 +1,87 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/image"
+	containertypes "github.com/docker/engine-api/types/container"
+)
+
+func TestImageOwners(t *testing.T) {
+	dir, err := ioutil.TempDir("", "image-owners")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "owners.json")
+
+	o, err := newImageOwners(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := image.ID("sha256:1234")
+	o.add(id, "alice")
+	o.add(id, "bob")
+	o.add(id, "alice")
+
+	o, err = newImageOwners(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owners := o.get(id); len(owners) != 2 || owners[0] != "alice" || owners[1] != "bob" {
+		t.Fatalf("Expected the owners alice and bob, got %v", owners)
+	}
+	if !o.owns(id, "bob") || o.owns(id, "carol") {
+		t.Fatal("Expected the image to be owned by bob only among bob and carol")
+	}
+
+	o.remove(id)
+	if owners := o.get(id); len(owners) != 0 {
+		t.Fatalf("Expected no owners once removed, got %v", owners)
+	}
+}
+
+func TestVerifyTenantHostAccess(t *testing.T) {
+	cases := []struct {
+		hostConfig containertypes.HostConfig
+		denied     bool
+	}{
+		{containertypes.HostConfig{}, false},
+		{containertypes.HostConfig{Binds: []string{"data:/data"}}, false},
+		{containertypes.HostConfig{NetworkMode: "front"}, false},
+		{containertypes.HostConfig{Binds: []string{"/etc:/host/etc:ro"}}, true},
+		{containertypes.HostConfig{Privileged: true}, true},
+		{containertypes.HostConfig{Resources: containertypes.Resources{Devices: []containertypes.DeviceMapping{{PathOnHost: "/dev/sda"}}}}, true},
+		{containertypes.HostConfig{NetworkMode: "host"}, true},
+		{containertypes.HostConfig{PidMode: "host"}, true},
+		{containertypes.HostConfig{IpcMode: "host"}, true},
+		{containertypes.HostConfig{UTSMode: "host"}, true},
+		{containertypes.HostConfig{UsernsMode: "host"}, true},
+		{containertypes.HostConfig{NetworkDevices: []containertypes.NetworkDevice{{Name: "eth1"}}}, true},
+		{containertypes.HostConfig{Hooks: &containertypes.Hooks{Prestart: []containertypes.Hook{{Path: "setup"}}}}, true},
+		{containertypes.HostConfig{Hooks: &containertypes.Hooks{}}, false},
+		{containertypes.HostConfig{CapAdd: []string{"ALL"}}, true},
+		{containertypes.HostConfig{CapAdd: []string{"SYS_ADMIN"}}, true},
+		{containertypes.HostConfig{CapAdd: []string{"NET_ADMIN"}}, true},
+		{containertypes.HostConfig{SecurityOpt: []string{"no-new-privileges"}}, false},
+		{containertypes.HostConfig{SecurityOpt: []string{"label=level:s0:c100,c200"}}, false},
+		{containertypes.HostConfig{SecurityOpt: []string{"apparmor=unconfined"}}, true},
+		{containertypes.HostConfig{SecurityOpt: []string{"apparmor:unconfined"}}, true},
+		{containertypes.HostConfig{SecurityOpt: []string{"seccomp=unconfined"}}, true},
+		{containertypes.HostConfig{SecurityOpt: []string{"label=disable"}}, true},
+		{containertypes.HostConfig{Resources: containertypes.Resources{CgroupParent: "/"}}, true},
+		{containertypes.HostConfig{Sysctls: map[string]string{"net.ipv4.ip_forward": "1"}}, true},
+	}
+	for _, c := range cases {
+		err := verifyTenantHostAccess(&c.hostConfig)
+		if c.denied && err == nil {
+			t.Fatalf("Expected %+v to be denied", c.hostConfig)
+		}
+		if !c.denied && err != nil {
+			t.Fatalf("Unexpected error for %+v: %v", c.hostConfig, err)
+		}
+	}
+}
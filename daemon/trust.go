@@ -0,0 +1,90 @@
+package daemon
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/docker/docker/errors"
+	"github.com/docker/docker/reference"
+	"github.com/docker/docker/trust"
+	"github.com/docker/engine-api/types"
+	"golang.org/x/net/context"
+)
+
+// TrustKeys returns the content trust signing keys of the daemon.
+func (daemon *Daemon) TrustKeys() ([]types.TrustKey, error) {
+	return daemon.trustService.Keys()
+}
+
+// TrustKeyCreate generates a content trust signing key, a root key or the
+// key of a signer.
+func (daemon *Daemon) TrustKeyCreate(req types.TrustKeyCreateRequest) (types.TrustKey, error) {
+	return daemon.trustService.GenerateKey(req)
+}
+
+// TrustKeysBackup returns a tar archive of the encrypted content trust
+// signing keys of the daemon.
+func (daemon *Daemon) TrustKeysBackup() (io.ReadCloser, error) {
+	return daemon.trustService.BackupKeys()
+}
+
+// TrustSigners returns the signers and the base roles of the trust data of a
+// repository.
+func (daemon *Daemon) TrustSigners(ctx context.Context, repository, server string, metaHeaders map[string][]string, authConfig *types.AuthConfig) (types.TrustRepository, error) {
+	ref, err := parseTrustRepository(repository)
+	if err != nil {
+		return types.TrustRepository{}, err
+	}
+	return daemon.trustService.Signers(ctx, ref, &trust.RepositoryConfig{
+		Server:      server,
+		MetaHeaders: metaHeaders,
+		AuthConfig:  authConfig,
+	})
+}
+
+// TrustKeyRotate rotates a key of the trust data of a repository.
+func (daemon *Daemon) TrustKeyRotate(ctx context.Context, repository, server string, req types.TrustRotateRequest, metaHeaders map[string][]string, authConfig *types.AuthConfig) error {
+	ref, err := parseTrustRepository(repository)
+	if err != nil {
+		return err
+	}
+	return daemon.trustService.RotateKey(ctx, ref, req.Role, req.ServerManaged, &trust.RepositoryConfig{
+		Server:      server,
+		MetaHeaders: metaHeaders,
+		AuthConfig:  authConfig,
+		Passphrases: req.TrustPassphrases,
+	})
+}
+
+// TrustSign signs a tag of a repository, as it is in its registry, with the
+// keys of the daemon.
+func (daemon *Daemon) TrustSign(ctx context.Context, repository, server string, req types.TrustSignRequest, metaHeaders map[string][]string, authConfig *types.AuthConfig) (types.TrustTarget, error) {
+	ref, err := parseTrustRepository(repository)
+	if err != nil {
+		return types.TrustTarget{}, err
+	}
+	if req.Tag == "" {
+		return types.TrustTarget{}, errors.NewBadRequestError(fmt.Errorf("a tag of %s is required to sign", repository))
+	}
+	tagged, err := reference.WithTag(ref, req.Tag)
+	if err != nil {
+		return types.TrustTarget{}, err
+	}
+	return daemon.trustService.Sign(ctx, tagged, req.Hardware, &trust.RepositoryConfig{
+		Server:      server,
+		MetaHeaders: metaHeaders,
+		AuthConfig:  authConfig,
+		Passphrases: req.TrustPassphrases,
+	})
+}
+
+func parseTrustRepository(repository string) (reference.Named, error) {
+	ref, err := reference.ParseNamed(repository)
+	if err != nil {
+		return nil, errors.NewBadRequestError(err)
+	}
+	if !reference.IsNameOnly(ref) {
+		return nil, errors.NewBadRequestError(fmt.Errorf("%s is not a repository name: it must not have a tag or a digest", repository))
+	}
+	return ref, nil
+}
@@ -22,6 +22,22 @@ func (daemon *Daemon) ContainerUnpause(name string) error {
 
 // containerUnpause resumes the container execution after the container is paused.
 func (daemon *Daemon) containerUnpause(container *container.Container) error {
+	container.BeginOperation()
+	defer container.EndOperation()
+
+	if err := checkUnpause(container); err != nil {
+		return err
+	}
+
+	if err := daemon.containerd.Resume(container.ID); err != nil {
+		return runtimeError(fmt.Errorf("Cannot unpause container %s: %s", container.ID, err), err)
+	}
+
+	return nil
+}
+
+// checkUnpause returns an error if the container cannot be unpaused.
+func checkUnpause(container *container.Container) error {
 	container.Lock()
 	defer container.Unlock()
 
@@ -35,9 +51,5 @@ func (daemon *Daemon) containerUnpause(container *container.Container) error {
 		return fmt.Errorf("Container %s is not paused", container.ID)
 	}
 
-	if err := daemon.containerd.Resume(container.ID); err != nil {
-		return fmt.Errorf("Cannot unpause container %s: %s", container.ID, err)
-	}
-
 	return nil
 }
@@ -8,9 +8,8 @@ import (
 
 // ContainerUpdate updates configuration of the container
 func (daemon *Daemon) ContainerUpdate(name string, hostConfig *container.HostConfig) ([]string, error) {
-	var warnings []string
-
-	warnings, err := daemon.verifyContainerSettings(hostConfig, nil, true)
+	w, err := daemon.verifyContainerSettings(hostConfig, nil, true)
+	warnings := warningMessages(w)
 	if err != nil {
 		return warnings, err
 	}
@@ -61,10 +60,26 @@ func (daemon *Daemon) update(name string, hostConfig *container.HostConfig) erro
 		return errCannotUpdate(container.ID, fmt.Errorf("Container is marked for removal and cannot be \"update\"."))
 	}
 
+	if container.CPUPlacement != nil && hostConfig.CpusetCpus != "" && hostConfig.CpusetCpus != container.HostConfig.CpusetCpus {
+		return errCannotUpdate(container.ID, fmt.Errorf("Can not update the cpuset of a container with CPUs allocated by the daemon."))
+	}
+
 	if container.IsRunning() && hostConfig.KernelMemory != 0 {
 		return errCannotUpdate(container.ID, fmt.Errorf("Can not update kernel memory to a running container, please stop it first."))
 	}
 
+	if err := verifyUpdatedResources(container, hostConfig.Resources); err != nil {
+		return errCannotUpdate(container.ID, err)
+	}
+
+	if container.HostConfig.JobMode {
+		jobConfig := *hostConfig
+		jobConfig.JobMode = true
+		if err := verifyJobMode(&jobConfig); err != nil {
+			return errCannotUpdate(container.ID, err)
+		}
+	}
+
 	if err := container.UpdateContainer(hostConfig); err != nil {
 		restoreConfig = true
 		return errCannotUpdate(container.ID, err)
@@ -78,7 +93,7 @@ func (daemon *Daemon) update(name string, hostConfig *container.HostConfig) erro
 	// If container is running (including paused), we need to update configs
 	// to the real world.
 	if container.IsRunning() && !container.IsRestarting() {
-		if err := daemon.containerd.UpdateResources(container.ID, toContainerdResources(hostConfig.Resources)); err != nil {
+		if err := daemon.updateResources(container, hostConfig.Resources); err != nil {
 			restoreConfig = true
 			return errCannotUpdate(container.ID, err)
 		}
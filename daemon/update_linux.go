@@ -3,10 +3,25 @@
 package daemon
 
 import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	containerpkg "github.com/docker/docker/container"
 	"github.com/docker/docker/libcontainerd"
+	"github.com/docker/docker/pkg/sysinfo"
+	"github.com/docker/engine-api/types/blkiodev"
 	"github.com/docker/engine-api/types/container"
+	"github.com/opencontainers/runc/libcontainer/cgroups"
+	"github.com/opencontainers/specs/specs-go"
 )
 
+// toContainerdResources returns the resources containerd updates. The
+// memory limits and the block IO throttling are updated by the daemon, as
+// containerd only takes 32 bits values, and does not update the swappiness
+// nor the throttling.
 func toContainerdResources(resources container.Resources) libcontainerd.Resources {
 	var r libcontainerd.Resources
 	r.BlkioWeight = uint32(resources.BlkioWeight)
@@ -15,11 +30,231 @@ func toContainerdResources(resources container.Resources) libcontainerd.Resource
 	r.CpuQuota = uint32(resources.CPUQuota)
 	r.CpusetCpus = resources.CpusetCpus
 	r.CpusetMems = resources.CpusetMems
-	r.MemoryLimit = uint32(resources.Memory)
-	if resources.MemorySwap > 0 {
-		r.MemorySwap = uint32(resources.MemorySwap)
-	}
-	r.MemoryReservation = uint32(resources.MemoryReservation)
 	r.KernelMemoryLimit = uint32(resources.KernelMemory)
 	return r
 }
+
+// verifyUpdatedResources returns an error if the resources of c, updated
+// with resources, are inconsistent.
+func verifyUpdatedResources(c *containerpkg.Container, resources container.Resources) error {
+	current := c.HostConfig.Resources
+	memory, swap, reservation := current.Memory, current.MemorySwap, current.MemoryReservation
+	if resources.Memory != 0 {
+		memory = resources.Memory
+	}
+	if resources.MemorySwap != 0 {
+		swap = resources.MemorySwap
+	}
+	if resources.MemoryReservation != 0 {
+		reservation = resources.MemoryReservation
+	}
+
+	if resources.Memory != 0 && resources.MemorySwap == 0 && swap > 0 && memory > swap {
+		return fmt.Errorf("Memory limit should be smaller than already set memoryswap limit, update the memoryswap at the same time")
+	}
+	if memory > 0 && swap > 0 && swap < memory {
+		return fmt.Errorf("Minimum memoryswap limit should be larger than memory limit, see usage")
+	}
+	if memory == 0 && swap > 0 {
+		return fmt.Errorf("You should always set the Memory limit when using Memoryswap limit, see usage")
+	}
+	if memory > 0 && reservation > memory {
+		return fmt.Errorf("Minimum memory limit should be larger than memory reservation limit, see usage")
+	}
+	return nil
+}
+
+// updateResources updates the resources of the running container c. The
+// runtime only updates the cgroup v1 hierarchies, so the daemon writes the
+// cgroup v2 files of the container itself in the unified hierarchy.
+func (daemon *Daemon) updateResources(c *containerpkg.Container, resources container.Resources) error {
+	if !sysinfo.IsCgroup2UnifiedMode() {
+		if err := daemon.containerd.UpdateResources(c.ID, toContainerdResources(resources)); err != nil {
+			return err
+		}
+		if err := updateMemoryCgroup(c.State.Pid, resources); err != nil {
+			return err
+		}
+		return updateBlkioCgroup(c.State.Pid, resources)
+	}
+
+	r := &specs.Resources{
+		Memory: getMemoryResources(resources),
+		CPU:    getCPUResources(resources),
+	}
+	// memory.swap.max depends on the memory limit, which the container
+	// already has if the update only sets the swap
+	if r.Memory.Swap != nil && r.Memory.Limit == nil && c.HostConfig.Memory > 0 {
+		limit := uint64(c.HostConfig.Memory)
+		r.Memory.Limit = &limit
+	}
+	if resources.BlkioWeight > 0 {
+		weight := resources.BlkioWeight
+		r.BlockIO = &specs.BlockIO{Weight: &weight}
+	}
+	setUnifiedResources(r)
+
+	dir, err := cgroup2Path(c.State.Pid)
+	if err != nil {
+		return err
+	}
+	for file, value := range r.Unified {
+		if err := ioutil.WriteFile(filepath.Join(cgroup2Root, dir, file), []byte(value), 0); err != nil {
+			return err
+		}
+	}
+	return updateIOMax(filepath.Join(cgroup2Root, dir), resources)
+}
+
+// updateMemoryCgroup writes the memory limits of resources to the memory
+// cgroup v1 of the process pid.
+func updateMemoryCgroup(pid int, resources container.Resources) error {
+	if resources.Memory == 0 && resources.MemorySwap == 0 && resources.MemoryReservation == 0 &&
+		(resources.MemorySwappiness == nil || *resources.MemorySwappiness == -1) {
+		return nil
+	}
+	dir, err := cgroupV1Dir(pid, "memory")
+	if err != nil {
+		return err
+	}
+	write := func(file string, value int64) error {
+		return ioutil.WriteFile(filepath.Join(dir, file), []byte(strconv.FormatInt(value, 10)), 0)
+	}
+
+	// The memory limit can't exceed the limit of the memory and the swap,
+	// so the order of the writes depends on whether the limits increase.
+	if resources.Memory != 0 && resources.MemorySwap != 0 {
+		b, err := ioutil.ReadFile(filepath.Join(dir, "memory.limit_in_bytes"))
+		if err != nil {
+			return err
+		}
+		current, err := strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64)
+		if err != nil {
+			return err
+		}
+		if resources.Memory > current {
+			if err := write("memory.memsw.limit_in_bytes", resources.MemorySwap); err != nil {
+				return err
+			}
+			if err := write("memory.limit_in_bytes", resources.Memory); err != nil {
+				return err
+			}
+		} else {
+			if err := write("memory.limit_in_bytes", resources.Memory); err != nil {
+				return err
+			}
+			if err := write("memory.memsw.limit_in_bytes", resources.MemorySwap); err != nil {
+				return err
+			}
+		}
+	} else if resources.Memory != 0 {
+		if err := write("memory.limit_in_bytes", resources.Memory); err != nil {
+			return err
+		}
+	} else if resources.MemorySwap != 0 {
+		if err := write("memory.memsw.limit_in_bytes", resources.MemorySwap); err != nil {
+			return err
+		}
+	}
+	if resources.MemoryReservation != 0 {
+		if err := write("memory.soft_limit_in_bytes", resources.MemoryReservation); err != nil {
+			return err
+		}
+	}
+	if resources.MemorySwappiness != nil && *resources.MemorySwappiness != -1 {
+		if err := write("memory.swappiness", *resources.MemorySwappiness); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateBlkioCgroup writes the block IO throttling of resources to the blkio
+// cgroup v1 of the process pid. A rate of 0 removes the throttling of the
+// device.
+func updateBlkioCgroup(pid int, resources container.Resources) error {
+	throttles := map[string][]*blkiodev.ThrottleDevice{
+		"blkio.throttle.read_bps_device":   resources.BlkioDeviceReadBps,
+		"blkio.throttle.write_bps_device":  resources.BlkioDeviceWriteBps,
+		"blkio.throttle.read_iops_device":  resources.BlkioDeviceReadIOps,
+		"blkio.throttle.write_iops_device": resources.BlkioDeviceWriteIOps,
+	}
+	var dir string
+	for file, devs := range throttles {
+		specDevs, err := getBlkioThrottleDevices(devs)
+		if err != nil {
+			return err
+		}
+		for _, d := range specDevs {
+			if dir == "" {
+				if dir, err = cgroupV1Dir(pid, "blkio"); err != nil {
+					return err
+				}
+			}
+			value := fmt.Sprintf("%d:%d %d", d.Major, d.Minor, *d.Rate)
+			if err := ioutil.WriteFile(filepath.Join(dir, file), []byte(value), 0); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// updateIOMax writes the block IO throttling of resources to io.max in the
+// cgroup v2 dir, one device at a time. A rate of 0 removes the throttling
+// of the device.
+func updateIOMax(dir string, resources container.Resources) error {
+	throttles := []struct {
+		key  string
+		devs []*blkiodev.ThrottleDevice
+	}{
+		{"rbps", resources.BlkioDeviceReadBps},
+		{"wbps", resources.BlkioDeviceWriteBps},
+		{"riops", resources.BlkioDeviceReadIOps},
+		{"wiops", resources.BlkioDeviceWriteIOps},
+	}
+	var devices []string
+	limits := make(map[string][]string)
+	for _, t := range throttles {
+		specDevs, err := getBlkioThrottleDevices(t.devs)
+		if err != nil {
+			return err
+		}
+		for _, d := range specDevs {
+			device := fmt.Sprintf("%d:%d", d.Major, d.Minor)
+			if _, ok := limits[device]; !ok {
+				devices = append(devices, device)
+			}
+			rate := "max"
+			if *d.Rate > 0 {
+				rate = strconv.FormatUint(*d.Rate, 10)
+			}
+			limits[device] = append(limits[device], t.key+"="+rate)
+		}
+	}
+	for _, device := range devices {
+		value := device + " " + strings.Join(limits[device], " ")
+		if err := ioutil.WriteFile(filepath.Join(dir, "io.max"), []byte(value), 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cgroupV1Dir returns the directory of the cgroup v1 of the process pid in
+// the hierarchy of subsystem.
+func cgroupV1Dir(pid int, subsystem string) (string, error) {
+	mountPoint, root, err := cgroups.FindCgroupMountpointAndRoot(subsystem)
+	if err != nil {
+		return "", err
+	}
+	paths, err := cgroups.ParseCgroupFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(root, paths[subsystem])
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(mountPoint, rel), nil
+}
@@ -0,0 +1,48 @@
+// +build linux
+
+package daemon
+
+import (
+	"testing"
+
+	containerpkg "github.com/docker/docker/container"
+	"github.com/docker/engine-api/types/container"
+)
+
+func TestVerifyUpdatedResources(t *testing.T) {
+	const mb = 1024 * 1024
+	c := &containerpkg.Container{
+		CommonContainer: containerpkg.CommonContainer{
+			HostConfig: &container.HostConfig{
+				Resources: container.Resources{
+					Memory:            100 * mb,
+					MemorySwap:        200 * mb,
+					MemoryReservation: 50 * mb,
+				},
+			},
+		},
+	}
+	cases := []struct {
+		resources container.Resources
+		valid     bool
+	}{
+		{container.Resources{Memory: 150 * mb}, true},
+		// the memory exceeds the swap the container already has
+		{container.Resources{Memory: 300 * mb}, false},
+		{container.Resources{Memory: 300 * mb, MemorySwap: 400 * mb}, true},
+		{container.Resources{Memory: 300 * mb, MemorySwap: -1}, true},
+		{container.Resources{MemorySwap: 50 * mb}, false},
+		{container.Resources{MemoryReservation: 150 * mb}, false},
+		{container.Resources{Memory: 40 * mb, MemorySwap: 80 * mb}, false},
+		{container.Resources{Memory: 40 * mb, MemorySwap: 80 * mb, MemoryReservation: 20 * mb}, true},
+	}
+	for _, tc := range cases {
+		err := verifyUpdatedResources(c, tc.resources)
+		if tc.valid && err != nil {
+			t.Fatalf("Expected the update %+v to be valid, got %v", tc.resources, err)
+		}
+		if !tc.valid && err == nil {
+			t.Fatalf("Expected the update %+v to be invalid", tc.resources)
+		}
+	}
+}
@@ -3,6 +3,7 @@
 package daemon
 
 import (
+	containerpkg "github.com/docker/docker/container"
 	"github.com/docker/docker/libcontainerd"
 	"github.com/docker/engine-api/types/container"
 )
@@ -11,3 +12,14 @@ func toContainerdResources(resources container.Resources) libcontainerd.Resource
 	var r libcontainerd.Resources
 	return r
 }
+
+// verifyUpdatedResources returns an error if the resources of c, updated
+// with resources, are inconsistent. This is a no-op on Windows.
+func verifyUpdatedResources(c *containerpkg.Container, resources container.Resources) error {
+	return nil
+}
+
+// updateResources updates the resources of the running container c.
+func (daemon *Daemon) updateResources(c *containerpkg.Container, resources container.Resources) error {
+	return daemon.containerd.UpdateResources(c.ID, toContainerdResources(resources))
+}
@@ -0,0 +1,78 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/docker/docker/daemon/graphdriver"
+	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/distribution/scan"
+	"github.com/docker/docker/pkg/authorization"
+	"github.com/docker/docker/pkg/plugins"
+	"github.com/docker/docker/utils"
+)
+
+// Severities of the diagnostics of ValidateConfig.
+const (
+	DiagnosticError   = "error"
+	DiagnosticWarning = "warning"
+)
+
+// Diagnostic is a problem found by ValidateConfig in the configuration of
+// the daemon. Check is the part of the configuration it concerns, such as
+// "config", "storage-driver", "network", "tls" or "plugin".
+type Diagnostic struct {
+	Check    string
+	Severity string
+	Message  string
+}
+
+// ValidateConfig checks that the daemon can start with config, without
+// starting it: the validity of the settings, the availability of the
+// storage, logging and network drivers, and the reachability of the
+// plugins. It returns the problems found.
+func ValidateConfig(config *Config) []Diagnostic {
+	var diags []Diagnostic
+	addError := func(check string, err error) {
+		diags = append(diags, Diagnostic{Check: check, Severity: DiagnosticError, Message: err.Error()})
+	}
+
+	if err := validateConfiguration(config); err != nil {
+		addError("config", err)
+	}
+	if err := verifyDaemonSettings(config); err != nil {
+		addError("config", err)
+	}
+
+	if name := config.GraphDriver; name != "" && !graphdriver.IsRegistered(name) {
+		if !utils.ExperimentalBuild() {
+			addError("storage-driver", fmt.Errorf("storage driver %s is not supported by this build", name))
+		} else if err := plugins.Probe(name, "GraphDriver"); err != nil {
+			addError("storage-driver", fmt.Errorf("storage driver %s is neither built in nor a reachable plugin: %v", name, err))
+		}
+	}
+
+	if config.LogConfig.Type != "" {
+		if err := logger.ValidateLogOpts(config.LogConfig.Type, config.LogConfig.Config); err != nil {
+			addError("log-driver", err)
+		}
+	}
+
+	if !isBridgeNetworkDisabled(config) {
+		for _, err := range validateNetworkConfig(config) {
+			addError("network", err)
+		}
+	}
+
+	for _, name := range config.AuthorizationPlugins {
+		if err := plugins.Probe(name, authorization.AuthZApiImplements); err != nil {
+			addError("plugin", fmt.Errorf("authorization plugin %s: %v", name, err))
+		}
+	}
+	if name := config.ImageScanner; name != "" {
+		if err := plugins.Probe(name, scan.ScannerAPIImplements); err != nil {
+			addError("plugin", fmt.Errorf("image scanner plugin %s: %v", name, err))
+		}
+	}
+
+	return diags
+}
@@ -0,0 +1,27 @@
+package daemon
+
+import (
+	"testing"
+)
+
+func TestValidateConfigStorageDriver(t *testing.T) {
+	config := &Config{}
+	config.GraphDriver = "nonexistent"
+	diags := ValidateConfig(config)
+	found := false
+	for _, d := range diags {
+		if d.Check == "storage-driver" && d.Severity == DiagnosticError {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected a storage-driver diagnostic, got %+v", diags)
+	}
+
+	config.GraphDriver = "vfs"
+	for _, d := range ValidateConfig(config) {
+		if d.Check == "storage-driver" {
+			t.Fatalf("expected the vfs storage driver to be available, got %+v", d)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+// +build linux freebsd
+
+package daemon
+
+import (
+	"fmt"
+	"net"
+	"os/exec"
+)
+
+// validateNetworkConfig checks that the bridge the containers are attached
+// to exists, and that the iptables command is available when the daemon
+// manages the iptables rules.
+func validateNetworkConfig(config *Config) []error {
+	var errs []error
+	if iface := config.bridgeConfig.Iface; iface != "" {
+		if _, err := net.InterfaceByName(iface); err != nil {
+			errs = append(errs, fmt.Errorf("bridge %s: %v", iface, err))
+		}
+	}
+	if config.bridgeConfig.EnableIPTables {
+		if _, err := exec.LookPath("iptables"); err != nil {
+			errs = append(errs, fmt.Errorf("--iptables requires the iptables command: %v", err))
+		}
+	}
+	return errs
+}
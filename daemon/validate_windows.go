@@ -0,0 +1,16 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/Microsoft/hcsshim"
+)
+
+// validateNetworkConfig checks that the Host Network Service, which the
+// network drivers of the containers rely on, is available.
+func validateNetworkConfig(config *Config) []error {
+	if _, err := hcsshim.HNSListNetworkRequest("GET", "", ""); err != nil {
+		return []error{fmt.Errorf("the Host Network Service is not available: %v", err)}
+	}
+	return nil
+}
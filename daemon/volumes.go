@@ -65,10 +65,21 @@ func (m mounts) parts(i int) int {
 // 2. Select the volumes mounted from another containers. Overrides previously configured mount point destination.
 // 3. Select the bind mounts set by the client. Overrides previously configured mount point destinations.
 // 4. Cleanup old volumes that are about to be reassigned.
-func (daemon *Daemon) registerMountPoints(container *container.Container, hostConfig *containertypes.HostConfig) error {
+func (daemon *Daemon) registerMountPoints(container *container.Container, hostConfig *containertypes.HostConfig) (retErr error) {
 	binds := map[string]bool{}
 	mountPoints := map[string]*volume.MountPoint{}
 
+	// The references taken on volumes are dropped if not all of the mount
+	// points can be registered, since none of them is.
+	var referenced []volume.Volume
+	defer func() {
+		if retErr != nil {
+			for _, v := range referenced {
+				daemon.volumes.Dereference(v, container.ID)
+			}
+		}
+	}()
+
 	// 1. Read already configured mount points.
 	for name, point := range container.MountPoints {
 		mountPoints[name] = point
@@ -102,6 +113,7 @@ func (daemon *Daemon) registerMountPoints(container *container.Container, hostCo
 				if err != nil {
 					return err
 				}
+				referenced = append(referenced, v)
 				cp.Volume = v
 			}
 
@@ -123,10 +135,11 @@ func (daemon *Daemon) registerMountPoints(container *container.Container, hostCo
 
 		if len(bind.Name) > 0 {
 			// create the volume
-			v, err := daemon.volumes.CreateWithRef(bind.Name, bind.Driver, container.ID, nil, nil)
+			v, err := daemon.volumes.CreateWithRef(bind.Name, bind.Driver, container.ID, nil, ownerLabels(container))
 			if err != nil {
 				return err
 			}
+			referenced = append(referenced, v)
 			bind.Volume = v
 			bind.Source = v.Path()
 			// bind.Name is an already existing volume, we need to use that here
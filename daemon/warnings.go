@@ -0,0 +1,74 @@
+package daemon
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/docker/engine-api/types"
+	containertypes "github.com/docker/engine-api/types/container"
+	"github.com/docker/go-connections/nat"
+)
+
+// The codes of the warnings returned on the create and start of a container.
+const (
+	// warningResourceDiscarded is a resource setting that the host does not
+	// support and that is ignored.
+	warningResourceDiscarded = "ResourceDiscarded"
+	// warningSwapLimitDiscarded is a memory limit applied without a swap
+	// limit because the kernel lacks swap accounting.
+	warningSwapLimitDiscarded = "SwapLimitDiscarded"
+	// warningKernelMemoryUnstable is a kernel memory limit on a kernel where
+	// such limits are experimental.
+	warningKernelMemoryUnstable = "KernelMemoryUnstable"
+	// warningIPForwardingDisabled is a container networked on a host with IP
+	// forwarding disabled.
+	warningIPForwardingDisabled = "IPForwardingDisabled"
+	// warningIsolationFallback is a container run with another isolation
+	// than the one requested.
+	warningIsolationFallback = "IsolationFallback"
+	// warningPortPublishedOnAllInterfaces is a port published on all the
+	// interfaces of the host rather than on a given address.
+	warningPortPublishedOnAllInterfaces = "PortPublishedOnAllInterfaces"
+)
+
+// newWarning returns a warning with the given code and formatted message.
+func newWarning(code, format string, args ...interface{}) types.ContainerWarning {
+	return types.ContainerWarning{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// warningMessages returns the messages of warnings listed in the Warnings
+// field of the API responses. The advisories about ports published on all
+// interfaces are left out, as the clients print these messages.
+func warningMessages(warnings []types.ContainerWarning) []string {
+	messages := []string{}
+	for _, w := range warnings {
+		if w.Code != warningPortPublishedOnAllInterfaces {
+			messages = append(messages, w.Message)
+		}
+	}
+	return messages
+}
+
+// verifyPortBindings returns a warning for each port of hostConfig published
+// without a host IP, which makes it reachable on all the interfaces of the
+// host.
+func verifyPortBindings(hostConfig *containertypes.HostConfig) []types.ContainerWarning {
+	var warnings []types.ContainerWarning
+	if hostConfig == nil || hostConfig.NetworkMode.IsHost() {
+		return nil
+	}
+	ports := make([]string, 0, len(hostConfig.PortBindings))
+	for port := range hostConfig.PortBindings {
+		ports = append(ports, string(port))
+	}
+	sort.Strings(ports)
+	for _, port := range ports {
+		for _, pb := range hostConfig.PortBindings[nat.Port(port)] {
+			if pb.HostIP == "" || pb.HostIP == "0.0.0.0" || pb.HostIP == "::" {
+				warnings = append(warnings, newWarning(warningPortPublishedOnAllInterfaces, "Port %s is published on all the interfaces of the host, set a host IP to restrict it.", port))
+				break
+			}
+		}
+	}
+	return warnings
+}
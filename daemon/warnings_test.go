@@ -0,0 +1,39 @@
+package daemon
+
+import (
+	"testing"
+
+	containertypes "github.com/docker/engine-api/types/container"
+	"github.com/docker/go-connections/nat"
+)
+
+func TestVerifyPortBindings(t *testing.T) {
+	hostConfig := &containertypes.HostConfig{
+		PortBindings: nat.PortMap{
+			"80/tcp":  {{HostIP: "", HostPort: "8080"}},
+			"443/tcp": {{HostIP: "127.0.0.1", HostPort: "8443"}},
+			"53/udp":  {{HostIP: "127.0.0.1", HostPort: "53"}, {HostIP: "0.0.0.0", HostPort: "53"}},
+		},
+	}
+	warnings := verifyPortBindings(hostConfig)
+	if len(warnings) != 2 {
+		t.Fatalf("expected 2 warnings, got %v", warnings)
+	}
+	for i, port := range []string{"53/udp", "80/tcp"} {
+		if warnings[i].Code != warningPortPublishedOnAllInterfaces {
+			t.Fatalf("expected code %s, got %s", warningPortPublishedOnAllInterfaces, warnings[i].Code)
+		}
+		expected := "Port " + port + " is published on all the interfaces of the host, set a host IP to restrict it."
+		if warnings[i].Message != expected {
+			t.Fatalf("expected %q, got %q", expected, warnings[i].Message)
+		}
+	}
+	if messages := warningMessages(warnings); len(messages) != 0 {
+		t.Fatalf("expected no messages for the port advisories, got %v", messages)
+	}
+
+	hostConfig.NetworkMode = "host"
+	if warnings := verifyPortBindings(hostConfig); len(warnings) != 0 {
+		t.Fatalf("expected no warnings with the host network, got %v", warnings)
+	}
+}
@@ -0,0 +1,133 @@
+package daemon
+
+import (
+	"bytes"
+	"expvar"
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/container"
+)
+
+// stuckOperations counts the container operations that exceeded the stuck
+// operation timeout. It is exported on the /debug/vars endpoint.
+var stuckOperations = expvar.NewInt("stuck_operations")
+
+// operation is a container operation in progress, holding up the
+// operations queued on the container.
+type operation struct {
+	Container string
+	Operation string
+	Since     time.Time
+	Goroutine int
+}
+
+// operationTracker records the container operations in progress, for the
+// state dumps of the daemon.
+type operationTracker struct {
+	mu  sync.Mutex
+	ops map[*operation]struct{}
+}
+
+func (t *operationTracker) add(op *operation) {
+	t.mu.Lock()
+	if t.ops == nil {
+		t.ops = make(map[*operation]struct{})
+	}
+	t.ops[op] = struct{}{}
+	t.mu.Unlock()
+}
+
+func (t *operationTracker) remove(op *operation) {
+	t.mu.Lock()
+	delete(t.ops, op)
+	t.mu.Unlock()
+}
+
+// list returns the operations in progress, oldest first.
+func (t *operationTracker) list() []operation {
+	t.mu.Lock()
+	ops := make([]operation, 0, len(t.ops))
+	for op := range t.ops {
+		ops = append(ops, *op)
+	}
+	t.mu.Unlock()
+	sort.Sort(byOperationStart(ops))
+	return ops
+}
+
+type byOperationStart []operation
+
+func (s byOperationStart) Len() int           { return len(s) }
+func (s byOperationStart) Less(i, j int) bool { return s[i].Since.Before(s[j].Since) }
+func (s byOperationStart) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// watchOperation starts watching a container operation (start, kill, exec,
+// ...) that may block on the container runtime. The operation is recorded
+// for the state dumps of the daemon. If the operation has not completed
+// within the configured timeout, the stack of the goroutine running it is
+// logged, so that a hung runtime call does not silently hold up the other
+// operations queued on the container. The returned function must be called
+// when the operation completes, from the goroutine that called
+// watchOperation.
+func (daemon *Daemon) watchOperation(c *container.Container, op string) func() {
+	id := currentGoroutineID()
+	start := time.Now()
+	tracked := &operation{Container: c.ID, Operation: op, Since: start, Goroutine: id}
+	daemon.operations.add(tracked)
+
+	if daemon.configStore == nil || daemon.configStore.StuckOperationTimeout <= 0 {
+		return func() {
+			daemon.operations.remove(tracked)
+		}
+	}
+
+	timeout := time.Duration(daemon.configStore.StuckOperationTimeout) * time.Second
+	t := time.AfterFunc(timeout, func() {
+		stuckOperations.Add(1)
+		logrus.Errorf("Container %s: %s has not completed after %v, it may be stuck in the container runtime.\n=== BEGIN goroutine stack ===\n%s\n=== END goroutine stack ===", c.ID, op, timeout, goroutineStack(id))
+	})
+	return func() {
+		daemon.operations.remove(tracked)
+		if !t.Stop() {
+			logrus.Warnf("Container %s: %s completed after %v", c.ID, op, time.Since(start))
+		}
+	}
+}
+
+// currentGoroutineID returns the ID of the calling goroutine, as found in
+// the header of its stack trace.
+func currentGoroutineID() int {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	var id int
+	fmt.Sscanf(string(buf), "goroutine %d ", &id)
+	return id
+}
+
+// goroutineStack returns the stack trace of the goroutine with the given
+// ID, or an empty string if it has exited.
+func goroutineStack(id int) string {
+	var (
+		buf       []byte
+		stackSize int
+	)
+	bufferLen := 16384
+	for stackSize == len(buf) {
+		buf = make([]byte, bufferLen)
+		stackSize = runtime.Stack(buf, true)
+		bufferLen *= 2
+	}
+
+	prefix := []byte(fmt.Sprintf("goroutine %d [", id))
+	for _, stack := range bytes.Split(buf[:stackSize], []byte("\n\n")) {
+		if bytes.HasPrefix(stack, prefix) {
+			return string(stack)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,89 @@
+package daemon
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/container"
+)
+
+// logCapture is a logrus hook sending the messages of the entries logged to
+// a channel.
+type logCapture chan string
+
+func (l logCapture) Levels() []logrus.Level {
+	return []logrus.Level{logrus.ErrorLevel, logrus.WarnLevel}
+}
+
+func (l logCapture) Fire(entry *logrus.Entry) error {
+	l <- entry.Message
+	return nil
+}
+
+// stuckInRuntime stands for a runtime call which does not return until
+// release is closed.
+func stuckInRuntime(release chan struct{}) {
+	<-release
+}
+
+func TestWatchOperationStuck(t *testing.T) {
+	logger := logrus.StandardLogger()
+	hooks := logger.Hooks
+	logs := make(logCapture, 10)
+	logger.Hooks = make(logrus.LevelHooks)
+	logger.Hooks.Add(logs)
+	defer func() {
+		logger.Hooks = hooks
+	}()
+
+	config := &Config{}
+	config.StuckOperationTimeout = 1
+	d := &Daemon{configStore: config}
+	c := &container.Container{CommonContainer: container.CommonContainer{ID: "6e2d4b8a0c1f"}}
+
+	stuck := stuckOperations.Value()
+	release := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		done := d.watchOperation(c, "start")
+		stuckInRuntime(release)
+		done()
+		close(finished)
+	}()
+
+	var msg string
+	select {
+	case msg = <-logs:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the stuck operation was not reported")
+	}
+	if n := stuckOperations.Value(); n != stuck+1 {
+		t.Fatalf("expected stuck_operations to be %d, got %d", stuck+1, n)
+	}
+	if !strings.Contains(msg, "Container 6e2d4b8a0c1f: start has not completed after 1s") {
+		t.Fatalf("unexpected report of the stuck operation: %s", msg)
+	}
+	// the stack of the goroutine running the operation is captured
+	if !strings.Contains(msg, "=== BEGIN goroutine stack ===\ngoroutine ") || !strings.Contains(msg, "stuckInRuntime") {
+		t.Fatalf("expected the stack of the stuck goroutine in the report, got %s", msg)
+	}
+	if ops := d.operations.list(); len(ops) != 1 || ops[0].Operation != "start" {
+		t.Fatalf("expected the stuck operation to be tracked, got %v", ops)
+	}
+
+	close(release)
+	<-finished
+	select {
+	case msg = <-logs:
+		if !strings.Contains(msg, "Container 6e2d4b8a0c1f: start completed after") {
+			t.Fatalf("unexpected report of the completion of the operation: %s", msg)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("the completion of the stuck operation was not reported")
+	}
+	if ops := d.operations.list(); len(ops) != 0 {
+		t.Fatalf("expected no operation tracked, got %v", ops)
+	}
+}
@@ -0,0 +1,125 @@
+package daemon
+
+import (
+	"fmt"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/layer"
+	"golang.org/x/net/context"
+)
+
+const (
+	// wedgedKillTimeout is how long the removal of a container with
+	// cleanup waits for the runtime to kill it before reconciling its state
+	// without the runtime.
+	wedgedKillTimeout = 15 * time.Second
+	// wedgedRetryTimeout is the timeout of each retry of the runtime-level
+	// removal of a wedged container.
+	wedgedRetryTimeout = 30 * time.Second
+)
+
+// wedgedRetryDelays are the delays between the retries of the runtime-level
+// removal of a wedged container.
+var wedgedRetryDelays = []time.Duration{
+	5 * time.Second, 10 * time.Second, 30 * time.Second, time.Minute,
+	2 * time.Minute, 5 * time.Minute, 5 * time.Minute, 5 * time.Minute,
+	5 * time.Minute, 5 * time.Minute,
+}
+
+// killWedged kills the running container c for its removal with cleanup.
+// If the runtime fails to kill it within wedgedKillTimeout, such as a
+// zombie compute system or a container whose shim died, the state of c is
+// reconciled without the runtime: it is marked stopped, and its network,
+// mounts and volumes are released. It returns whether the runtime-level
+// removal of c is left to be retried.
+func (daemon *Daemon) killWedged(c *container.Container) bool {
+	// the kill is cancelled once the timeout is reached, so that it does
+	// not log a kill event after the state of c is reconciled
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- daemon.killContext(ctx, c)
+	}()
+	var err error
+	select {
+	case err = <-errCh:
+		if err == nil {
+			return false
+		}
+	case <-time.After(wedgedKillTimeout):
+		err = fmt.Errorf("no response within %s", wedgedKillTimeout)
+		cancel()
+	}
+	logrus.Warnf("Could not kill wedged container %s, releasing its resources without the runtime: %v", c.ID, err)
+
+	c.SetStoppedLocking(&container.ExitStatus{ExitCode: 128 + int(syscall.SIGKILL)})
+	// no exit will come from the runtime to clean up after the container
+	daemon.Cleanup(c)
+	return true
+}
+
+// retryWedgedRemoval retries, in the background, the runtime-level removal
+// of the wedged container id, removed by the daemon: killing it in the
+// runtime if killRuntime is set, then releasing its read-write layer
+// rwLayer if not nil. It gives up after the last of wedgedRetryDelays, or
+// when the daemon shuts down.
+func (daemon *Daemon) retryWedgedRemoval(id string, killRuntime bool, rwLayer layer.RWLayer) {
+	if !killRuntime && rwLayer == nil {
+		return
+	}
+	shutdown := context.Background()
+	if daemon.shutdownCtx != nil {
+		shutdown = daemon.shutdownCtx
+	}
+	go func() {
+		for attempt, delay := range wedgedRetryDelays {
+			select {
+			case <-time.After(delay):
+			case <-shutdown.Done():
+				return
+			}
+			if killRuntime {
+				ctx, cancel := context.WithTimeout(shutdown, wedgedRetryTimeout)
+				err := daemon.containerd.Signal(ctx, id, int(syscall.SIGKILL))
+				cancel()
+				if err != nil && !isRuntimeGone(err) {
+					logrus.Warnf("Attempt %d to kill the removed container %s in the runtime failed: %v", attempt+1, id, err)
+					continue
+				}
+				killRuntime = false
+			}
+			if rwLayer != nil {
+				metadata, err := daemon.layerStore.ReleaseRWLayer(rwLayer)
+				layer.LogReleaseMetadata(metadata)
+				if err != nil && err != layer.ErrMountDoesNotExist {
+					logrus.Warnf("Attempt %d to remove the root filesystem of the removed container %s failed: %v", attempt+1, id, err)
+					continue
+				}
+				rwLayer = nil
+			}
+			logrus.Infof("Completed the removal of the wedged container %s", id)
+			return
+		}
+		logrus.Errorf("Gave up removing the wedged container %s after %d attempts", id, len(wedgedRetryDelays))
+	}()
+}
+
+// isRuntimeGone returns whether err, returned by the runtime for a
+// container, tells it no longer has the container.
+func isRuntimeGone(err error) bool {
+	if isErrNoSuchProcess(err) || err == syscall.ESRCH {
+		return true
+	}
+	msg := err.Error()
+	for _, s := range []string{"container not found", "invalid container", "no such process"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+// Package delta implements the binary diffs between two layers that a
+// registry can serve for delta pulls. A delta rebuilds the uncompressed tar
+// archive of a layer from the uncompressed tar archive of a base layer, as a
+// sequence of ranges copied from the base and of new data.
+package delta
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// magic starts every delta.
+const magic = "docker-layer-delta-v1\n"
+
+// The operations of a delta. Each is a single byte, followed by its
+// arguments as 64-bit big-endian integers.
+const (
+	// opCopy copies the range of the base given by an offset and a length.
+	opCopy = 'C'
+	// opData is followed by a length, and that many bytes of data.
+	opData = 'D'
+	// opEnd ends the delta.
+	opEnd = 'E'
+)
+
+// ErrInvalidDelta is returned by Apply for a malformed delta.
+var ErrInvalidDelta = errors.New("invalid layer delta")
+
+// Apply writes to w the layer rebuilt from base by the delta read from r.
+func Apply(w io.Writer, base io.ReaderAt, r io.Reader) error {
+	br := bufio.NewReader(r)
+	header := make([]byte, len(magic))
+	if _, err := io.ReadFull(br, header); err != nil || string(header) != magic {
+		return ErrInvalidDelta
+	}
+
+	var args [2]uint64
+	for {
+		op, err := br.ReadByte()
+		if err != nil {
+			return truncated(err)
+		}
+		switch op {
+		case opCopy:
+			if err := binary.Read(br, binary.BigEndian, args[:]); err != nil {
+				return truncated(err)
+			}
+			n, err := io.Copy(w, io.NewSectionReader(base, int64(args[0]), int64(args[1])))
+			if err != nil {
+				return err
+			}
+			if n != int64(args[1]) {
+				return fmt.Errorf("%v: copy of %d bytes at offset %d beyond the end of the base", ErrInvalidDelta, args[1], args[0])
+			}
+		case opData:
+			if err := binary.Read(br, binary.BigEndian, args[:1]); err != nil {
+				return truncated(err)
+			}
+			if _, err := io.CopyN(w, br, int64(args[0])); err != nil {
+				return truncated(err)
+			}
+		case opEnd:
+			return nil
+		default:
+			return fmt.Errorf("%v: unknown operation %q", ErrInvalidDelta, op)
+		}
+	}
+}
+
+func truncated(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return fmt.Errorf("%v: unexpected end of delta", ErrInvalidDelta)
+	}
+	return err
+}
+
+// Writer writes a delta.
+type Writer struct {
+	w *bufio.Writer
+}
+
+// NewWriter returns a Writer writing a delta to w.
+func NewWriter(w io.Writer) (*Writer, error) {
+	dw := &Writer{w: bufio.NewWriter(w)}
+	if _, err := dw.w.WriteString(magic); err != nil {
+		return nil, err
+	}
+	return dw, nil
+}
+
+// Copy adds the copy of length bytes of the base at offset.
+func (dw *Writer) Copy(offset, length int64) error {
+	if err := dw.w.WriteByte(opCopy); err != nil {
+		return err
+	}
+	return binary.Write(dw.w, binary.BigEndian, [2]uint64{uint64(offset), uint64(length)})
+}
+
+// Data adds the bytes p.
+func (dw *Writer) Data(p []byte) error {
+	if err := dw.w.WriteByte(opData); err != nil {
+		return err
+	}
+	if err := binary.Write(dw.w, binary.BigEndian, uint64(len(p))); err != nil {
+		return err
+	}
+	_, err := dw.w.Write(p)
+	return err
+}
+
+// Close ends the delta. It does not close the underlying writer.
+func (dw *Writer) Close() error {
+	if err := dw.w.WriteByte(opEnd); err != nil {
+		return err
+	}
+	return dw.w.Flush()
+}
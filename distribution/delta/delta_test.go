@@ -0,0 +1,63 @@
+package delta
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	base := strings.NewReader("the quick brown fox jumps over the lazy dog")
+
+	var d bytes.Buffer
+	w, err := NewWriter(&d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Copy(0, 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Data([]byte("red")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Copy(15, 28); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := Apply(&out, base, bytes.NewReader(d.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "the quick red fox jumps over the lazy dog"; out.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, out.String())
+	}
+
+	// A truncated delta is invalid.
+	out.Reset()
+	if err := Apply(&out, base, bytes.NewReader(d.Bytes()[:d.Len()-1])); err == nil {
+		t.Fatal("expected an error for a truncated delta")
+	}
+}
+
+func TestApplyInvalid(t *testing.T) {
+	base := strings.NewReader("base")
+
+	var d bytes.Buffer
+	w, err := NewWriter(&d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Copy(2, 10)
+	w.Close()
+
+	var out bytes.Buffer
+	if err := Apply(&out, base, &d); err == nil {
+		t.Fatal("expected an error for a copy beyond the end of the base")
+	}
+	if err := Apply(&out, base, strings.NewReader("not a delta")); err != ErrInvalidDelta {
+		t.Fatalf("expected %v, got %v", ErrInvalidDelta, err)
+	}
+}
@@ -0,0 +1,31 @@
+package distribution
+
+import (
+	"github.com/docker/distribution/digest"
+	"github.com/docker/docker/distribution/scan"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/pkg/progress"
+	"github.com/docker/docker/reference"
+)
+
+// scanImage submits the image imageID, pulled or pushed as ref, to the scan
+// hook. The manifest is the one the image is pulled from, if any.
+func scanImage(hook *scan.Hook, action string, ref reference.Named, imageID image.ID, manifestDigest digest.Digest, manifest []byte, is image.Store, out progress.Output) error {
+	img, err := is.Get(imageID)
+	if err != nil {
+		return err
+	}
+	req := &scan.Request{
+		Action:         action,
+		Name:           ref.String(),
+		ImageID:        imageID.String(),
+		ManifestDigest: manifestDigest.String(),
+		Manifest:       manifest,
+		Config:         img.RawJSON(),
+		DiffIDs:        make([]string, len(img.RootFS.DiffIDs)),
+	}
+	for i, diffID := range img.RootFS.DiffIDs {
+		req.DiffIDs[i] = diffID.String()
+	}
+	return hook.Check(req, out)
+}
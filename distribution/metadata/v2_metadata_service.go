@@ -17,6 +17,9 @@ type V2MetadataService struct {
 type V2Metadata struct {
 	Digest           digest.Digest
 	SourceRepository string
+	// MediaType is the media type of the blob, which tells its compression.
+	// It is empty for the gzip compressed blobs recorded before it was.
+	MediaType string `json:",omitempty"`
 }
 
 // maxMetadata is the number of metadata entries to keep per layer DiffID.
@@ -0,0 +1,167 @@
+// Package peer lets daemons fetch the compressed layer blobs of images from
+// each other rather than from registries.
+//
+// Each daemon keeps the blobs it downloaded in a Cache, which it serves over
+// HTTP to its peers. Blobs are addressed by digest, and fetched blobs are
+// verified against it like blobs downloaded from registries.
+package peer
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/distribution/digest"
+)
+
+// blobsPath is the URL path under which a Cache serves its blobs.
+const blobsPath = "/blobs/"
+
+// Cache stores compressed layer blobs by digest in a directory, removing the
+// least recently used ones past a size limit.
+type Cache struct {
+	root string
+	size int64
+	mu   sync.Mutex
+}
+
+// NewCache returns a cache storing blobs under root, up to size bytes, or
+// without limit if size is 0.
+func NewCache(root string, size int64) (*Cache, error) {
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return nil, err
+	}
+	return &Cache{root: root, size: size}, nil
+}
+
+func (c *Cache) path(dgst digest.Digest) string {
+	return filepath.Join(c.root, string(dgst.Algorithm()), dgst.Hex())
+}
+
+// Add stores the blob with digest dgst read from r. The caller must have
+// verified that r has this digest.
+func (c *Cache) Add(dgst digest.Digest, r io.Reader) error {
+	if err := dgst.Validate(); err != nil {
+		return err
+	}
+	dir := filepath.Join(c.root, string(dgst.Algorithm()))
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := os.Rename(f.Name(), c.path(dgst)); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	c.prune()
+	return nil
+}
+
+// Open returns the blob with digest dgst and its size.
+func (c *Cache) Open(dgst digest.Digest) (*os.File, int64, error) {
+	if err := dgst.Validate(); err != nil {
+		return nil, 0, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	f, err := os.Open(c.path(dgst))
+	if err != nil {
+		return nil, 0, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	// The modification time records the last use of the blob.
+	now := time.Now()
+	os.Chtimes(f.Name(), now, now)
+	return f, fi.Size(), nil
+}
+
+// prune removes the least recently used blobs until the cache fits its size
+// limit. c must be locked.
+func (c *Cache) prune() {
+	if c.size <= 0 {
+		return
+	}
+	var (
+		blobs []os.FileInfo
+		paths = make(map[os.FileInfo]string)
+		total int64
+	)
+	filepath.Walk(c.root, func(path string, fi os.FileInfo, err error) error {
+		if err != nil || fi.IsDir() || strings.HasPrefix(fi.Name(), ".tmp-") {
+			return nil
+		}
+		blobs = append(blobs, fi)
+		paths[fi] = path
+		total += fi.Size()
+		return nil
+	})
+	sort.Sort(byModTime(blobs))
+	for _, fi := range blobs {
+		if total <= c.size {
+			return
+		}
+		if err := os.Remove(paths[fi]); err != nil {
+			logrus.Warnf("Could not remove cached blob %s: %v", paths[fi], err)
+			continue
+		}
+		total -= fi.Size()
+	}
+}
+
+type byModTime []os.FileInfo
+
+func (s byModTime) Len() int           { return len(s) }
+func (s byModTime) Less(i, j int) bool { return s[i].ModTime().Before(s[j].ModTime()) }
+func (s byModTime) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// ServeHTTP serves the blobs of the cache at /blobs/<digest>.
+func (c *Cache) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "HEAD" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !strings.HasPrefix(r.URL.Path, blobsPath) {
+		http.NotFound(w, r)
+		return
+	}
+	dgst, err := digest.ParseDigest(strings.TrimPrefix(r.URL.Path, blobsPath))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f, _, err := c.Open(dgst)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Docker-Content-Digest", dgst.String())
+	http.ServeContent(w, r, "", time.Time{}, f)
+}
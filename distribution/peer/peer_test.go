@@ -0,0 +1,142 @@
+package peer
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/docker/distribution/digest"
+	"golang.org/x/net/context"
+)
+
+func newTestCache(t *testing.T, size int64) (*Cache, func()) {
+	root, err := ioutil.TempDir("", "peer-cache-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewCache(root, size)
+	if err != nil {
+		os.RemoveAll(root)
+		t.Fatal(err)
+	}
+	return c, func() { os.RemoveAll(root) }
+}
+
+func addBlob(t *testing.T, c *Cache, content string) digest.Digest {
+	dgst := digest.FromBytes([]byte(content))
+	if err := c.Add(dgst, bytes.NewReader([]byte(content))); err != nil {
+		t.Fatal(err)
+	}
+	return dgst
+}
+
+func TestCacheAddOpen(t *testing.T) {
+	c, cleanup := newTestCache(t, 0)
+	defer cleanup()
+
+	dgst := addBlob(t, c, "layer")
+	f, size, err := c.Open(dgst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	content, err := ioutil.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "layer" || size != 5 {
+		t.Fatalf("expected 5 bytes of %q, got %d bytes of %q", "layer", size, content)
+	}
+
+	if _, _, err := c.Open(digest.FromBytes([]byte("missing"))); err == nil {
+		t.Fatal("expected an error opening a missing blob")
+	}
+}
+
+func TestCachePrune(t *testing.T) {
+	c, cleanup := newTestCache(t, 10)
+	defer cleanup()
+
+	old := addBlob(t, c, "aaaa")
+	used := addBlob(t, c, "bbbb")
+	past := time.Now().Add(-time.Hour)
+	os.Chtimes(c.path(old), past, past)
+	os.Chtimes(c.path(used), past.Add(time.Minute), past.Add(time.Minute))
+
+	// Using the blob makes it the most recently used.
+	f, _, err := c.Open(used)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	last := addBlob(t, c, "cccc")
+	if _, err := os.Stat(c.path(old)); !os.IsNotExist(err) {
+		t.Fatalf("expected the least recently used blob to be pruned, got %v", err)
+	}
+	for _, dgst := range []digest.Digest{used, last} {
+		if _, err := os.Stat(c.path(dgst)); err != nil {
+			t.Fatalf("expected %s to be kept: %v", dgst, err)
+		}
+	}
+}
+
+func TestPeersOpen(t *testing.T) {
+	c, cleanup := newTestCache(t, 0)
+	defer cleanup()
+	dgst := addBlob(t, c, "layer")
+
+	server := httptest.NewServer(c)
+	defer server.Close()
+	empty := httptest.NewServer(http.NotFoundHandler())
+	defer empty.Close()
+
+	peers := NewPeers([]string{empty.URL, "http://127.0.0.1:1", server.URL + "/"})
+	for i := 0; i < 3; i++ {
+		blob, size, url, err := peers.Open(context.Background(), dgst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := ioutil.ReadAll(blob)
+		blob.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != "layer" || size != 5 || url != server.URL+"/" {
+			t.Fatalf("expected 5 bytes of %q from %s, got %d bytes of %q from %s", "layer", server.URL, size, content, url)
+		}
+	}
+
+	if _, _, _, err := peers.Open(context.Background(), digest.FromBytes([]byte("missing"))); err == nil {
+		t.Fatal("expected an error opening a blob no peer has")
+	}
+}
+
+func TestCacheServeHTTPInvalidDigest(t *testing.T) {
+	c, cleanup := newTestCache(t, 0)
+	defer cleanup()
+
+	for path, code := range map[string]int{
+		"/blobs/sha256:../../etc/passwd": http.StatusBadRequest,
+		"/v2/":                           http.StatusNotFound,
+	} {
+		rec := httptest.NewRecorder()
+		c.ServeHTTP(rec, &http.Request{Method: "GET", URL: mustParseURL(t, path)})
+		if rec.Code != code {
+			t.Fatalf("%s: expected status %d, got %d", path, code, rec.Code)
+		}
+	}
+}
+
+func mustParseURL(t *testing.T, path string) *url.URL {
+	u, err := url.Parse(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
@@ -0,0 +1,68 @@
+package peer
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/distribution/digest"
+	"golang.org/x/net/context"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+// Peers fetches blobs from the caches of other daemons.
+type Peers struct {
+	urls   []string
+	client *http.Client
+}
+
+// NewPeers returns a client fetching blobs from the daemons serving their
+// cache at urls, such as "http://10.0.0.2:5080".
+func NewPeers(urls []string) *Peers {
+	dialer := &net.Dialer{
+		// Peers are expected on the local network; an unreachable
+		// one must not hold the pull back for long.
+		Timeout: 2 * time.Second,
+	}
+	return &Peers{
+		urls: urls,
+		client: &http.Client{
+			Transport: &http.Transport{
+				Dial:                  dialer.Dial,
+				ResponseHeaderTimeout: 5 * time.Second,
+			},
+		},
+	}
+}
+
+// Open returns the blob with digest dgst from the first peer that has it,
+// starting from a random one to spread the load, along with its size and
+// the URL of the peer. The caller must verify the content of the blob.
+func (p *Peers) Open(ctx context.Context, dgst digest.Digest) (io.ReadCloser, int64, string, error) {
+	if len(p.urls) == 0 {
+		return nil, 0, "", fmt.Errorf("no peer has blob %s", dgst)
+	}
+	start := rand.Intn(len(p.urls))
+	for i := range p.urls {
+		url := p.urls[(start+i)%len(p.urls)]
+		resp, err := ctxhttp.Get(ctx, p.client, strings.TrimRight(url, "/")+blobsPath+dgst.String())
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, 0, "", ctx.Err()
+			}
+			logrus.Debugf("Could not reach peer %s: %v", url, err)
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			continue
+		}
+		return resp.Body, resp.ContentLength, url, nil
+	}
+	return nil, 0, "", fmt.Errorf("no peer has blob %s", dgst)
+}
@@ -6,15 +6,32 @@ import (
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/api"
 	"github.com/docker/docker/distribution/metadata"
+	"github.com/docker/docker/distribution/peer"
+	"github.com/docker/docker/distribution/scan"
 	"github.com/docker/docker/distribution/xfer"
 	"github.com/docker/docker/image"
+	"github.com/docker/docker/layer"
 	"github.com/docker/docker/pkg/progress"
+	"github.com/docker/docker/pkg/staging"
 	"github.com/docker/docker/reference"
 	"github.com/docker/docker/registry"
 	"github.com/docker/engine-api/types"
 	"golang.org/x/net/context"
 )
 
+const (
+	// downloadFilePrefix is the prefix of the temporary files in which the
+	// layers are downloaded.
+	downloadFilePrefix = "GetImageBlob"
+	// deltaBaseFilePrefix is the prefix of the temporary files in which
+	// the bases of the delta downloads are prepared.
+	deltaBaseFilePrefix = "GetImageBlobDeltaBase"
+)
+
+// StagingPrefixes are the prefixes of the names of the temporary files of
+// the pulls in their staging area.
+var StagingPrefixes = []string{downloadFilePrefix, deltaBaseFilePrefix}
+
 // ImagePullConfig stores pull configuration.
 type ImagePullConfig struct {
 	// MetaHeaders stores HTTP headers with metadata about the image
@@ -39,6 +56,22 @@ type ImagePullConfig struct {
 	ReferenceStore reference.Store
 	// DownloadManager manages concurrent pulls.
 	DownloadManager *xfer.LayerDownloadManager
+	// LayerPeers, if set, are tried before the registry to download the
+	// layer blobs of v2 images.
+	LayerPeers *peer.Peers
+	// LayerCache, if set, keeps the downloaded layer blobs to serve them
+	// to the peers of the daemon.
+	LayerCache *peer.Cache
+	// DeltaLayerStore, if set, enables the experimental delta pulls: the
+	// layers of a new version of an image are rebuilt from the layers of
+	// the version already pulled, and from diffs served by the registry.
+	DeltaLayerStore layer.Store
+	// ScanHook, if set, scans the images pulled from v2 registries before
+	// they are tagged.
+	ScanHook *scan.Hook
+	// Staging is the area in which the layers are downloaded. The layers
+	// are downloaded in the temporary directory if it is nil.
+	Staging *staging.Area
 }
 
 // Puller is an interface that abstracts pulling for different API versions.
@@ -88,6 +121,10 @@ func Pull(ctx context.Context, ref reference.Named, imagePullConfig *ImagePullCo
 		return err
 	}
 
+	if err := imagePullConfig.RegistryService.CheckAccess(repoInfo); err != nil {
+		return err
+	}
+
 	endpoints, err := imagePullConfig.RegistryService.LookupPullEndpoints(repoInfo.Hostname())
 	if err != nil {
 		return err
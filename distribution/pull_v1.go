@@ -4,10 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"net"
 	"net/url"
-	"os"
 	"strings"
 	"time"
 
@@ -21,6 +19,7 @@ import (
 	"github.com/docker/docker/layer"
 	"github.com/docker/docker/pkg/ioutils"
 	"github.com/docker/docker/pkg/progress"
+	"github.com/docker/docker/pkg/staging"
 	"github.com/docker/docker/pkg/stringid"
 	"github.com/docker/docker/reference"
 	"github.com/docker/docker/registry"
@@ -45,10 +44,14 @@ func (p *v1Puller) Pull(ctx context.Context, ref reference.Named) error {
 	if err != nil {
 		return err
 	}
+	base := registry.NewTransport(tlsConfig)
+	if p.endpoint.Proxy != nil {
+		base.Proxy = p.endpoint.Proxy
+	}
 	// Adds Docker-specific headers as well as user-specified headers (metaHeaders)
 	tr := transport.NewTransport(
 		// TODO(tiborvass): was ReceiveTimeout
-		registry.NewTransport(tlsConfig),
+		base,
 		registry.DockerHeaders(dockerversion.DockerUserAgent(ctx), p.config.MetaHeaders)...,
 	)
 	client := registry.HTTPClient(tr)
@@ -226,6 +229,7 @@ func (p *v1Puller) pullImage(ctx context.Context, v1ID, endpoint string, localNa
 			layersDownloaded: layersDownloaded,
 			layerSize:        imgSize,
 			session:          p.session,
+			staging:          p.config.Staging,
 		}
 
 		descriptors = append(descriptors, layerDescriptor)
@@ -284,7 +288,8 @@ type v1LayerDescriptor struct {
 	layersDownloaded *bool
 	layerSize        int64
 	session          *registry.Session
-	tmpFile          *os.File
+	staging          *staging.Area
+	tmpFile          *staging.File
 }
 
 func (ld *v1LayerDescriptor) Key() string {
@@ -314,13 +319,13 @@ func (ld *v1LayerDescriptor) Download(ctx context.Context, progressOutput progre
 	}
 	*ld.layersDownloaded = true
 
-	ld.tmpFile, err = ioutil.TempFile("", "GetImageBlob")
+	ld.tmpFile, err = ld.staging.TempFile(downloadFilePrefix)
 	if err != nil {
 		layerReader.Close()
 		return nil, 0, err
 	}
 
-	reader := progress.NewProgressReader(ioutils.NewCancelReadCloser(ctx, layerReader), progressOutput, ld.layerSize, ld.ID(), "Downloading")
+	reader := progress.NewProgressReader(xfer.ThrottleReader(ctx, ioutils.NewCancelReadCloser(ctx, layerReader)), progressOutput, ld.layerSize, ld.ID(), "Downloading")
 	defer reader.Close()
 
 	_, err = io.Copy(ld.tmpFile, reader)
@@ -341,8 +346,7 @@ func (ld *v1LayerDescriptor) Download(ctx context.Context, progressOutput progre
 	ld.tmpFile = nil
 
 	return ioutils.NewReadCloserWrapper(tmpFile, func() error {
-		tmpFile.Close()
-		err := os.RemoveAll(tmpFile.Name())
+		err := tmpFile.Remove()
 		if err != nil {
 			logrus.Errorf("Failed to remove temp file: %s", tmpFile.Name())
 		}
@@ -352,8 +356,7 @@ func (ld *v1LayerDescriptor) Download(ctx context.Context, progressOutput progre
 
 func (ld *v1LayerDescriptor) Close() {
 	if ld.tmpFile != nil {
-		ld.tmpFile.Close()
-		if err := os.RemoveAll(ld.tmpFile.Name()); err != nil {
+		if err := ld.tmpFile.Remove(); err != nil {
 			logrus.Errorf("Failed to remove temp file: %s", ld.tmpFile.Name())
 		}
 		ld.tmpFile = nil
@@ -5,7 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
+	"net/http"
 	"net/url"
 	"os"
 	"runtime"
@@ -20,12 +20,15 @@ import (
 	"github.com/docker/distribution/registry/client/auth"
 	"github.com/docker/distribution/registry/client/transport"
 	"github.com/docker/docker/distribution/metadata"
+	"github.com/docker/docker/distribution/peer"
+	"github.com/docker/docker/distribution/scan"
 	"github.com/docker/docker/distribution/xfer"
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/image/v1"
 	"github.com/docker/docker/layer"
 	"github.com/docker/docker/pkg/ioutils"
 	"github.com/docker/docker/pkg/progress"
+	"github.com/docker/docker/pkg/staging"
 	"github.com/docker/docker/pkg/stringid"
 	"github.com/docker/docker/reference"
 	"github.com/docker/docker/registry"
@@ -51,6 +54,8 @@ type v2Puller struct {
 	config            *ImagePullConfig
 	repoInfo          *registry.RepositoryInfo
 	repo              distribution.Repository
+	// transport is the authenticated transport of repo.
+	transport http.RoundTripper
 	// confirmedV2 is set to true if we confirm we're talking to a v2
 	// registry. This is used to limit fallbacks to the v1 protocol.
 	confirmedV2 bool
@@ -58,7 +63,7 @@ type v2Puller struct {
 
 func (p *v2Puller) Pull(ctx context.Context, ref reference.Named) (err error) {
 	// TODO(tiborvass): was ReceiveTimeout
-	p.repo, p.confirmedV2, err = NewV2Repository(ctx, p.repoInfo, p.endpoint, p.config.MetaHeaders, p.config.AuthConfig, "pull")
+	p.repo, p.transport, p.confirmedV2, err = newV2Repository(ctx, p.repoInfo, p.endpoint, p.config.MetaHeaders, p.config.AuthConfig, "pull")
 	if err != nil {
 		logrus.Warnf("Error getting v2 registry: %v", err)
 		return err
@@ -131,8 +136,15 @@ type v2LayerDescriptor struct {
 	repoInfo          *registry.RepositoryInfo
 	repo              distribution.Repository
 	V2MetadataService *metadata.V2MetadataService
-	tmpFile           *os.File
+	staging           *staging.Area
+	tmpFile           *staging.File
 	verifier          digest.Verifier
+	peers             *peer.Peers
+	cache             *peer.Cache
+	mediaType         string
+	// delta, if set, is the base and the expected diff ID of a delta
+	// download of the layer.
+	delta *deltaDownload
 }
 
 func (ld *v2LayerDescriptor) Key() string {
@@ -156,7 +168,7 @@ func (ld *v2LayerDescriptor) Download(ctx context.Context, progressOutput progre
 	)
 
 	if ld.tmpFile == nil {
-		ld.tmpFile, err = createDownloadFile()
+		ld.tmpFile, err = ld.staging.TempFile(downloadFilePrefix)
 		if err != nil {
 			return nil, 0, xfer.DoNotRetry{Err: err}
 		}
@@ -166,11 +178,10 @@ func (ld *v2LayerDescriptor) Download(ctx context.Context, progressOutput progre
 			logrus.Debugf("error seeking to end of download file: %v", err)
 			offset = 0
 
-			ld.tmpFile.Close()
-			if err := os.Remove(ld.tmpFile.Name()); err != nil {
+			if err := ld.tmpFile.Remove(); err != nil {
 				logrus.Errorf("Failed to remove temp file: %s", ld.tmpFile.Name())
 			}
-			ld.tmpFile, err = createDownloadFile()
+			ld.tmpFile, err = ld.staging.TempFile(downloadFilePrefix)
 			if err != nil {
 				return nil, 0, xfer.DoNotRetry{Err: err}
 			}
@@ -179,6 +190,27 @@ func (ld *v2LayerDescriptor) Download(ctx context.Context, progressOutput progre
 		}
 	}
 
+	if offset == 0 && ld.delta != nil {
+		size, ok, err := ld.downloadDelta(ctx, progressOutput)
+		if err != nil {
+			return nil, 0, err
+		}
+		if ok {
+			// The layer rebuilt is not the blob, so it is not cached.
+			return ld.handOff(size, false)
+		}
+	}
+
+	if offset == 0 && ld.peers != nil {
+		size, ok, err := ld.downloadFromPeers(ctx, progressOutput)
+		if err != nil {
+			return nil, 0, err
+		}
+		if ok {
+			return ld.handOff(size, true)
+		}
+	}
+
 	tmpFile := ld.tmpFile
 	blobs := ld.repo.Blobs(ctx)
 
@@ -224,7 +256,7 @@ func (ld *v2LayerDescriptor) Download(ctx context.Context, progressOutput progre
 		}
 	}
 
-	reader := progress.NewProgressReader(ioutils.NewCancelReadCloser(ctx, layerDownload), progressOutput, size-offset, ld.ID(), "Downloading")
+	reader := progress.NewProgressReader(xfer.ThrottleReader(ctx, ioutils.NewCancelReadCloser(ctx, layerDownload)), progressOutput, size-offset, ld.ID(), "Downloading")
 	defer reader.Close()
 
 	if ld.verifier == nil {
@@ -267,10 +299,68 @@ func (ld *v2LayerDescriptor) Download(ctx context.Context, progressOutput progre
 
 	logrus.Debugf("Downloaded %s to tempfile %s", ld.ID(), tmpFile.Name())
 
-	_, err = tmpFile.Seek(0, os.SEEK_SET)
+	return ld.handOff(size, true)
+}
+
+// downloadFromPeers downloads the blob to ld.tmpFile from one of the peers of
+// the daemon. It returns the size of the blob, and whether a peer had it with
+// the right digest; otherwise ld.tmpFile is left empty for the registry.
+func (ld *v2LayerDescriptor) downloadFromPeers(ctx context.Context, progressOutput progress.Output) (int64, bool, error) {
+	blob, size, url, err := ld.peers.Open(ctx, ld.digest)
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, false, ctx.Err()
+		}
+		logrus.Debugf("Could not download %s from peers: %v", ld.digest, err)
+		return 0, false, nil
+	}
+	if size < 0 {
+		size = 0
+	}
+
+	verifier, err := digest.NewDigestVerifier(ld.digest)
+	if err != nil {
+		blob.Close()
+		return 0, false, xfer.DoNotRetry{Err: err}
+	}
+	reader := progress.NewProgressReader(xfer.ThrottleReader(ctx, ioutils.NewCancelReadCloser(ctx, blob)), progressOutput, size, ld.ID(), "Downloading from peer")
+	defer reader.Close()
+
+	_, err = io.Copy(ld.tmpFile, io.TeeReader(reader, verifier))
+	if err == nil && !verifier.Verified() {
+		err = fmt.Errorf("verification failed for digest %s", ld.digest)
+	}
+	if err != nil {
+		logrus.Warnf("Could not download %s from peer %s: %v", ld.digest, url, err)
+		if err := ld.truncateDownloadFile(); err != nil {
+			return 0, false, xfer.DoNotRetry{Err: err}
+		}
+		if ctx.Err() != nil {
+			return 0, false, ctx.Err()
+		}
+		return 0, false, nil
+	}
+
+	progress.Update(progressOutput, ld.ID(), "Download complete")
+	logrus.Debugf("Downloaded %s from peer %s to tempfile %s", ld.ID(), url, ld.tmpFile.Name())
+	return size, true, nil
+}
+
+// handOff adds the verified blob downloaded to ld.tmpFile to the blob cache
+// if cache is set, and hands off the file to the download manager, so it will
+// only be closed once.
+func (ld *v2LayerDescriptor) handOff(size int64, cache bool) (io.ReadCloser, int64, error) {
+	tmpFile := ld.tmpFile
+
+	_, err := tmpFile.Seek(0, os.SEEK_SET)
+	if err == nil && cache && ld.cache != nil {
+		if err := ld.cache.Add(ld.digest, tmpFile); err != nil {
+			logrus.Warnf("Could not add %s to the layer blob cache: %v", ld.digest, err)
+		}
+		_, err = tmpFile.Seek(0, os.SEEK_SET)
+	}
 	if err != nil {
-		tmpFile.Close()
-		if err := os.Remove(tmpFile.Name()); err != nil {
+		if err := tmpFile.Remove(); err != nil {
 			logrus.Errorf("Failed to remove temp file: %s", tmpFile.Name())
 		}
 		ld.tmpFile = nil
@@ -278,13 +368,10 @@ func (ld *v2LayerDescriptor) Download(ctx context.Context, progressOutput progre
 		return nil, 0, xfer.DoNotRetry{Err: err}
 	}
 
-	// hand off the temporary file to the download manager, so it will only
-	// be closed once
 	ld.tmpFile = nil
 
 	return ioutils.NewReadCloserWrapper(tmpFile, func() error {
-		tmpFile.Close()
-		err := os.RemoveAll(tmpFile.Name())
+		err := tmpFile.Remove()
 		if err != nil {
 			logrus.Errorf("Failed to remove temp file: %s", tmpFile.Name())
 		}
@@ -294,8 +381,7 @@ func (ld *v2LayerDescriptor) Download(ctx context.Context, progressOutput progre
 
 func (ld *v2LayerDescriptor) Close() {
 	if ld.tmpFile != nil {
-		ld.tmpFile.Close()
-		if err := os.RemoveAll(ld.tmpFile.Name()); err != nil {
+		if err := ld.tmpFile.Remove(); err != nil {
 			logrus.Errorf("Failed to remove temp file: %s", ld.tmpFile.Name())
 		}
 	}
@@ -320,7 +406,7 @@ func (ld *v2LayerDescriptor) truncateDownloadFile() error {
 
 func (ld *v2LayerDescriptor) Registered(diffID layer.DiffID) {
 	// Cache mapping from this layer's DiffID to the blobsum
-	ld.V2MetadataService.Add(diffID, metadata.V2Metadata{Digest: ld.digest, SourceRepository: ld.repoInfo.FullName()})
+	ld.V2MetadataService.Add(diffID, metadata.V2Metadata{Digest: ld.digest, SourceRepository: ld.repoInfo.FullName(), MediaType: ld.mediaType})
 }
 
 func (p *v2Puller) pullV2Tag(ctx context.Context, ref reference.Named) (tagUpdated bool, err error) {
@@ -390,6 +476,16 @@ func (p *v2Puller) pullV2Tag(ctx context.Context, ref reference.Named) (tagUpdat
 
 	progress.Message(p.config.ProgressOutput, "", "Digest: "+manifestDigest.String())
 
+	if p.config.ScanHook != nil {
+		_, payload, err := manifest.Payload()
+		if err != nil {
+			return false, err
+		}
+		if err := scanImage(p.config.ScanHook, scan.ActionPull, ref, imageID, manifestDigest, payload, p.config.ImageStore, p.config.ProgressOutput); err != nil {
+			return false, err
+		}
+	}
+
 	oldTagImageID, err := p.config.ReferenceStore.Get(ref)
 	if err == nil {
 		if oldTagImageID == imageID {
@@ -461,6 +557,9 @@ func (p *v2Puller) pullSchema1(ctx context.Context, ref reference.Named, unverif
 			repoInfo:          p.repoInfo,
 			repo:              p.repo,
 			V2MetadataService: p.V2MetadataService,
+			peers:             p.config.LayerPeers,
+			cache:             p.config.LayerCache,
+			staging:           p.config.Staging,
 		}
 
 		descriptors = append(descriptors, layerDescriptor)
@@ -527,6 +626,10 @@ func (p *v2Puller) pullSchema2(ctx context.Context, ref reference.Named, mfst *s
 			repo:              p.repo,
 			repoInfo:          p.repoInfo,
 			V2MetadataService: p.V2MetadataService,
+			peers:             p.config.LayerPeers,
+			cache:             p.config.LayerCache,
+			mediaType:         d.MediaType,
+			staging:           p.config.Staging,
 		}
 
 		descriptors = append(descriptors, layerDescriptor)
@@ -537,7 +640,9 @@ func (p *v2Puller) pullSchema2(ctx context.Context, ref reference.Named, mfst *s
 		unmarshalledConfig image.Image  // deserialized image config
 		downloadRootFS     image.RootFS // rootFS to use for registering layers.
 	)
-	if runtime.GOOS == "windows" {
+	if runtime.GOOS == "windows" || p.config.DeltaLayerStore != nil {
+		// The diff IDs of the config are needed to verify the layers
+		// rebuilt from deltas.
 		configJSON, unmarshalledConfig, err = receiveConfig(configChan, errChan)
 		if err != nil {
 			return "", "", err
@@ -545,6 +650,12 @@ func (p *v2Puller) pullSchema2(ctx context.Context, ref reference.Named, mfst *s
 		if unmarshalledConfig.RootFS == nil {
 			return "", "", errors.New("image config has no rootfs section")
 		}
+		if compat, err := image.HostOSCompatibility(&unmarshalledConfig); compat == image.OSIncompatible {
+			return "", "", fmt.Errorf("cannot pull image: %v", err)
+		}
+		setDeltaDownloads(descriptors, p.deltaDownloads(ref, unmarshalledConfig.RootFS.DiffIDs))
+	}
+	if runtime.GOOS == "windows" {
 		downloadRootFS = *unmarshalledConfig.RootFS
 		downloadRootFS.DiffIDs = []layer.DiffID{}
 	} else {
@@ -834,7 +945,3 @@ func fixManifestLayers(m *schema1.Manifest) error {
 
 	return nil
 }
-
-func createDownloadFile() (*os.File, error) {
-	return ioutil.TempFile("", "GetImageBlob")
-}
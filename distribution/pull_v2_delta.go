@@ -0,0 +1,198 @@
+package distribution
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/distribution/digest"
+	"github.com/docker/docker/distribution/delta"
+	"github.com/docker/docker/distribution/xfer"
+	"github.com/docker/docker/layer"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/ioutils"
+	"github.com/docker/docker/pkg/progress"
+	"github.com/docker/docker/reference"
+	"golang.org/x/net/context"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+// deltaDownload is a delta download of a layer: the registry serves under
+// blobsURL the delta that rebuilds the layer with the given diff ID from the
+// local base layer, pulled from the blob baseDigest.
+type deltaDownload struct {
+	transport   http.RoundTripper
+	blobsURL    string
+	baseDigest  digest.Digest
+	layerStore  layer.Store
+	baseChainID layer.ChainID
+	diffID      layer.DiffID
+}
+
+// deltaDownloads returns the delta downloads of the layers with the given
+// diff IDs of the image pulled for ref. The base of each layer is the layer
+// at the same position in the image that ref currently refers to, if it was
+// pulled from the same repository. The returned slice has an entry for each
+// layer, nil for the layers downloaded in full.
+func (p *v2Puller) deltaDownloads(ref reference.Named, diffIDs []layer.DiffID) []*deltaDownload {
+	if p.config.DeltaLayerStore == nil {
+		return nil
+	}
+	baseID, err := p.config.ReferenceStore.Get(ref)
+	if err != nil {
+		return nil
+	}
+	base, err := p.config.ImageStore.Get(baseID)
+	if err != nil || base.RootFS == nil {
+		return nil
+	}
+
+	var downloads []*deltaDownload
+	for i, diffID := range diffIDs {
+		if i >= len(base.RootFS.DiffIDs) {
+			break
+		}
+		baseDiffID := base.RootFS.DiffIDs[i]
+		if baseDiffID == diffID {
+			// the layer is already there
+			continue
+		}
+		baseDigest, ok := p.sourceDigest(baseDiffID)
+		if !ok {
+			continue
+		}
+		if downloads == nil {
+			downloads = make([]*deltaDownload, len(diffIDs))
+		}
+		downloads[i] = &deltaDownload{
+			transport:   p.transport,
+			blobsURL:    fmt.Sprintf("%s/v2/%s/blobs", p.endpoint.URL, p.repo.Named().Name()),
+			baseDigest:  baseDigest,
+			layerStore:  p.config.DeltaLayerStore,
+			baseChainID: layer.CreateChainID(base.RootFS.DiffIDs[:i+1]),
+			diffID:      diffID,
+		}
+	}
+	return downloads
+}
+
+// sourceDigest returns the digest of the blob of the layer with the given
+// diff ID in the repository pulled.
+func (p *v2Puller) sourceDigest(diffID layer.DiffID) (digest.Digest, bool) {
+	metadata, err := p.V2MetadataService.GetMetadata(diffID)
+	if err != nil {
+		return "", false
+	}
+	for _, m := range metadata {
+		if m.SourceRepository == p.repoInfo.FullName() {
+			return m.Digest, true
+		}
+	}
+	return "", false
+}
+
+// setDeltaDownloads sets the delta downloads of the layer descriptors, in
+// the order of the layers of the image config.
+func setDeltaDownloads(descriptors []xfer.DownloadDescriptor, downloads []*deltaDownload) {
+	if len(downloads) != len(descriptors) {
+		return
+	}
+	for i, d := range descriptors {
+		if downloads[i] != nil {
+			d.(*v2LayerDescriptor).delta = downloads[i]
+		}
+	}
+}
+
+// downloadDelta rebuilds the uncompressed layer in ld.tmpFile from its base
+// layer and the delta served by the registry. It returns the size of the
+// layer, and whether the delta could be applied and the result has the
+// expected diff ID; otherwise ld.tmpFile is left empty for a full download.
+func (ld *v2LayerDescriptor) downloadDelta(ctx context.Context, progressOutput progress.Output) (int64, bool, error) {
+	d := ld.delta
+	url := fmt.Sprintf("%s/%s/delta/%s", d.blobsURL, ld.digest, d.baseDigest)
+
+	baseLayer, err := d.layerStore.Get(d.baseChainID)
+	if err != nil {
+		logrus.Debugf("Could not get the base layer of the delta of %s: %v", ld.digest, err)
+		return 0, false, nil
+	}
+	defer layer.ReleaseAndLog(d.layerStore, baseLayer)
+
+	resp, err := ctxhttp.Get(ctx, &http.Client{Transport: d.transport}, url)
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, false, ctx.Err()
+		}
+		logrus.Debugf("Could not download the delta of %s: %v", ld.digest, err)
+		return 0, false, nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		logrus.Debugf("Could not download the delta of %s from %s: %s", ld.digest, url, resp.Status)
+		return 0, false, nil
+	}
+
+	size, err := ld.applyDelta(ctx, baseLayer, resp.Body, resp.ContentLength, progressOutput)
+	if err != nil {
+		logrus.Warnf("Could not apply the delta of %s: %v", ld.digest, err)
+		if err := ld.truncateDownloadFile(); err != nil {
+			return 0, false, xfer.DoNotRetry{Err: err}
+		}
+		if ctx.Err() != nil {
+			return 0, false, ctx.Err()
+		}
+		return 0, false, nil
+	}
+
+	progress.Update(progressOutput, ld.ID(), "Download complete")
+	logrus.Debugf("Rebuilt %s from a delta to tempfile %s", ld.ID(), ld.tmpFile.Name())
+	return size, true, nil
+}
+
+// applyDelta writes to ld.tmpFile the layer rebuilt from baseLayer by the
+// delta read from body, and verifies its diff ID.
+func (ld *v2LayerDescriptor) applyDelta(ctx context.Context, baseLayer layer.Layer, body io.ReadCloser, size int64, progressOutput progress.Output) (int64, error) {
+	// The delta copies ranges of the base at any offset, so it is spooled
+	// to a file that can be read at random.
+	progress.Update(progressOutput, ld.ID(), "Preparing delta base")
+	baseFile, err := ld.staging.TempFile(deltaBaseFilePrefix)
+	if err != nil {
+		return 0, err
+	}
+	defer baseFile.Remove()
+	baseTar, err := baseLayer.TarStream()
+	if err != nil {
+		return 0, err
+	}
+	_, err = io.Copy(baseFile, baseTar)
+	baseTar.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	if size < 0 {
+		size = 0
+	}
+	reader := progress.NewProgressReader(xfer.ThrottleReader(ctx, ioutils.NewCancelReadCloser(ctx, body)), progressOutput, size, ld.ID(), "Downloading delta")
+	defer reader.Close()
+	deltaStream, err := archive.DecompressStream(reader)
+	if err != nil {
+		return 0, err
+	}
+	defer deltaStream.Close()
+
+	verifier, err := digest.NewDigestVerifier(digest.Digest(ld.delta.diffID))
+	if err != nil {
+		return 0, err
+	}
+	if err := delta.Apply(io.MultiWriter(ld.tmpFile, verifier), baseFile, deltaStream); err != nil {
+		return 0, err
+	}
+	if !verifier.Verified() {
+		return 0, fmt.Errorf("verification failed for diff ID %s", ld.delta.diffID)
+	}
+	return ld.tmpFile.Seek(0, os.SEEK_CUR)
+}
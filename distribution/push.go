@@ -8,9 +8,11 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/distribution/metadata"
+	"github.com/docker/docker/distribution/scan"
 	"github.com/docker/docker/distribution/xfer"
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/layer"
+	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/progress"
 	"github.com/docker/docker/reference"
 	"github.com/docker/docker/registry"
@@ -48,6 +50,12 @@ type ImagePushConfig struct {
 	TrustKey libtrust.PrivateKey
 	// UploadManager dispatches uploads.
 	UploadManager *xfer.LayerUploadManager
+	// LayerCompression is the compression of the layers uploaded, gzip
+	// unless set to zstd.
+	LayerCompression archive.Compression
+	// ScanHook, if set, scans the images before they are pushed to v2
+	// registries.
+	ScanHook *scan.Hook
 }
 
 // Pusher is an interface that abstracts pushing for different API versions.
@@ -100,6 +108,10 @@ func Push(ctx context.Context, ref reference.Named, imagePushConfig *ImagePushCo
 		return err
 	}
 
+	if err := imagePushConfig.RegistryService.CheckAccess(repoInfo); err != nil {
+		return err
+	}
+
 	endpoints, err := imagePushConfig.RegistryService.LookupPushEndpoints(repoInfo.Hostname())
 	if err != nil {
 		return err
@@ -191,16 +203,26 @@ func Push(ctx context.Context, ref reference.Named, imagePushConfig *ImagePushCo
 // is finished. This allows the caller to make sure the goroutine finishes
 // before it releases any resources connected with the reader that was
 // passed in.
-func compress(in io.Reader) (io.ReadCloser, chan struct{}) {
+func compress(in io.Reader, compression archive.Compression) (io.ReadCloser, chan struct{}) {
 	compressionDone := make(chan struct{})
 
 	pipeReader, pipeWriter := io.Pipe()
 	// Use a bufio.Writer to avoid excessive chunking in HTTP request.
 	bufWriter := bufio.NewWriterSize(pipeWriter, compressionBufSize)
-	compressor := gzip.NewWriter(bufWriter)
 
 	go func() {
-		_, err := io.Copy(compressor, in)
+		var (
+			compressor io.WriteCloser
+			err        error
+		)
+		if compression == archive.Zstd {
+			compressor, err = archive.CompressStream(bufWriter, archive.Zstd)
+		} else {
+			compressor = gzip.NewWriter(bufWriter)
+		}
+		if err == nil {
+			_, err = io.Copy(compressor, in)
+		}
 		if err == nil {
 			err = compressor.Close()
 		}
@@ -0,0 +1,53 @@
+package distribution
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/docker/docker/distribution/metadata"
+	"github.com/docker/docker/pkg/archive"
+)
+
+func TestCompress(t *testing.T) {
+	compressions := []archive.Compression{archive.Gzip}
+	if _, err := exec.LookPath("zstd"); err == nil {
+		compressions = append(compressions, archive.Zstd)
+	}
+	for _, compression := range compressions {
+		r, done := compress(strings.NewReader("layer data"), compression)
+		compressed, err := ioutil.ReadAll(r)
+		<-done
+		if err != nil {
+			t.Fatal(err)
+		}
+		if detected := archive.DetectCompression(compressed); detected != compression {
+			t.Fatalf("expected a %s stream, got %s", compression.Extension(), detected.Extension())
+		}
+
+		decompressed, err := archive.DecompressStream(bytes.NewReader(compressed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadAll(decompressed)
+		decompressed.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != "layer data" {
+			t.Fatalf("expected %q, got %q", "layer data", data)
+		}
+	}
+}
+
+func TestLayerMediaType(t *testing.T) {
+	if mediaType := layerMediaType(metadata.V2Metadata{}); mediaType != schema2.MediaTypeLayer {
+		t.Fatalf("expected the blobs recorded without media type to be %s, got %s", schema2.MediaTypeLayer, mediaType)
+	}
+	if mediaType := layerMediaType(metadata.V2Metadata{MediaType: mediaTypeLayerZstd}); mediaType != mediaTypeLayerZstd {
+		t.Fatalf("expected %s, got %s", mediaTypeLayerZstd, mediaType)
+	}
+}
@@ -34,10 +34,14 @@ func (p *v1Pusher) Push(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	base := registry.NewTransport(tlsConfig)
+	if p.endpoint.Proxy != nil {
+		base.Proxy = p.endpoint.Proxy
+	}
 	// Adds Docker-specific headers as well as user-specified headers (metaHeaders)
 	tr := transport.NewTransport(
 		// TODO(tiborvass): was NoTimeout
-		registry.NewTransport(tlsConfig),
+		base,
 		registry.DockerHeaders(dockerversion.DockerUserAgent(ctx), p.config.MetaHeaders)...,
 	)
 	client := registry.HTTPClient(tr)
@@ -14,9 +14,11 @@ import (
 	distreference "github.com/docker/distribution/reference"
 	"github.com/docker/distribution/registry/client"
 	"github.com/docker/docker/distribution/metadata"
+	"github.com/docker/docker/distribution/scan"
 	"github.com/docker/docker/distribution/xfer"
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/layer"
+	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/ioutils"
 	"github.com/docker/docker/pkg/progress"
 	"github.com/docker/docker/pkg/stringid"
@@ -25,6 +27,10 @@ import (
 	"golang.org/x/net/context"
 )
 
+// mediaTypeLayerZstd is the media type of the layers compressed with zstd, as
+// defined by the OCI image specification.
+const mediaTypeLayerZstd = "application/vnd.oci.image.layer.v1.tar+zstd"
+
 // PushResult contains the tag, manifest digest, and manifest size from the
 // push. It's used to signal this information to the trust code in the client
 // so it can sign the manifest if necessary.
@@ -119,6 +125,12 @@ func (p *v2Pusher) pushV2Tag(ctx context.Context, ref reference.NamedTagged, ima
 		return fmt.Errorf("could not find image from tag %s: %v", ref.String(), err)
 	}
 
+	if p.config.ScanHook != nil {
+		if err := scanImage(p.config.ScanHook, scan.ActionPush, ref, imageID, "", nil, p.config.ImageStore, p.config.ProgressOutput); err != nil {
+			return err
+		}
+	}
+
 	var l layer.Layer
 
 	topLayerID := img.RootFS.ChainID()
@@ -139,6 +151,7 @@ func (p *v2Pusher) pushV2Tag(ctx context.Context, ref reference.NamedTagged, ima
 		repoInfo:          p.repoInfo,
 		repo:              p.repo,
 		pushState:         &p.pushState,
+		compression:       p.config.LayerCompression,
 	}
 
 	// Loop bounds condition is to avoid pushing the base layer on Windows.
@@ -225,6 +238,7 @@ type v2PushDescriptor struct {
 	repo              distribution.Repository
 	pushState         *pushState
 	remoteDescriptor  distribution.Descriptor
+	compression       archive.Compression
 }
 
 func (pd *v2PushDescriptor) Key() string {
@@ -320,7 +334,7 @@ func (pd *v2PushDescriptor) Upload(ctx context.Context, progressOutput progress.
 		case distribution.ErrBlobMounted:
 			progress.Updatef(progressOutput, pd.ID(), "Mounted from %s", err.From.Name())
 
-			err.Descriptor.MediaType = schema2.MediaTypeLayer
+			err.Descriptor.MediaType = layerMediaType(mountFrom)
 
 			pd.pushState.Lock()
 			pd.pushState.confirmedV2 = true
@@ -328,7 +342,7 @@ func (pd *v2PushDescriptor) Upload(ctx context.Context, progressOutput progress.
 			pd.pushState.Unlock()
 
 			// Cache mapping from this layer's DiffID to the blobsum
-			if err := pd.v2MetadataService.Add(diffID, metadata.V2Metadata{Digest: mountFrom.Digest, SourceRepository: pd.repoInfo.FullName()}); err != nil {
+			if err := pd.v2MetadataService.Add(diffID, metadata.V2Metadata{Digest: mountFrom.Digest, SourceRepository: pd.repoInfo.FullName(), MediaType: mountFrom.MediaType}); err != nil {
 				return distribution.Descriptor{}, xfer.DoNotRetry{Err: err}
 			}
 			return err.Descriptor, nil
@@ -360,7 +374,8 @@ func (pd *v2PushDescriptor) Upload(ctx context.Context, progressOutput progress.
 	size, _ := pd.layer.DiffSize()
 
 	reader := progress.NewProgressReader(ioutils.NewCancelReadCloser(ctx, arch), progressOutput, size, pd.ID(), "Pushing")
-	compressedReader, compressionDone := compress(reader)
+	compressedReader, compressionDone := compress(reader, pd.compression)
+	compressedReader = xfer.ThrottleReader(ctx, compressedReader)
 	defer func() {
 		reader.Close()
 		<-compressionDone
@@ -383,8 +398,13 @@ func (pd *v2PushDescriptor) Upload(ctx context.Context, progressOutput progress.
 	logrus.Debugf("uploaded layer %s (%s), %d bytes", diffID, pushDigest, nn)
 	progress.Update(progressOutput, pd.ID(), "Pushed")
 
+	mediaType := schema2.MediaTypeLayer
+	if pd.compression == archive.Zstd {
+		mediaType = mediaTypeLayerZstd
+	}
+
 	// Cache mapping from this layer's DiffID to the blobsum
-	if err := pd.v2MetadataService.Add(diffID, metadata.V2Metadata{Digest: pushDigest, SourceRepository: pd.repoInfo.FullName()}); err != nil {
+	if err := pd.v2MetadataService.Add(diffID, metadata.V2Metadata{Digest: pushDigest, SourceRepository: pd.repoInfo.FullName(), MediaType: mediaType}); err != nil {
 		return distribution.Descriptor{}, xfer.DoNotRetry{Err: err}
 	}
 
@@ -396,7 +416,7 @@ func (pd *v2PushDescriptor) Upload(ctx context.Context, progressOutput progress.
 
 	descriptor := distribution.Descriptor{
 		Digest:    pushDigest,
-		MediaType: schema2.MediaTypeLayer,
+		MediaType: mediaType,
 		Size:      nn,
 	}
 	pd.pushState.remoteLayers[diffID] = descriptor
@@ -414,6 +434,16 @@ func (pd *v2PushDescriptor) Descriptor() distribution.Descriptor {
 	return pd.remoteDescriptor
 }
 
+// layerMediaType returns the media type of the blob described by meta. Layers
+// already in a registry are reused with their compression, whatever the
+// compression of the layers pushed.
+func layerMediaType(meta metadata.V2Metadata) string {
+	if meta.MediaType != "" {
+		return meta.MediaType
+	}
+	return schema2.MediaTypeLayer
+}
+
 // layerAlreadyExists checks if the registry already know about any of the
 // metadata passed in the "metadata" slice. If it finds one that the registry
 // knows about, it returns the known digest and "true".
@@ -426,7 +456,7 @@ func layerAlreadyExists(ctx context.Context, metadata []metadata.V2Metadata, rep
 		descriptor, err := repo.Blobs(ctx).Stat(ctx, meta.Digest)
 		switch err {
 		case nil:
-			descriptor.MediaType = schema2.MediaTypeLayer
+			descriptor.MediaType = layerMediaType(meta)
 			return descriptor, true, nil
 		case distribution.ErrBlobUnknown:
 			// nop
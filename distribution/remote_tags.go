@@ -0,0 +1,132 @@
+package distribution
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/distribution"
+	"github.com/docker/docker/reference"
+	"github.com/docker/docker/registry"
+	"github.com/docker/engine-api/types"
+	"golang.org/x/net/context"
+)
+
+// RemoteTagsConfig stores the configuration of a listing of the tags of a
+// repository in its registry.
+type RemoteTagsConfig struct {
+	// MetaHeaders stores HTTP headers with metadata about the request
+	MetaHeaders map[string][]string
+	// AuthConfig holds authentication credentials for authenticating with
+	// the registry.
+	AuthConfig *types.AuthConfig
+	// RegistryService is the registry service to use for TLS configuration
+	// and endpoint lookup.
+	RegistryService *registry.Service
+	// Digests requests the digests of the manifests of the tags, which
+	// takes a request to the registry per tag.
+	Digests bool
+}
+
+// RemoteTags returns the tags of the repository ref in its registry, sorted
+// by name. Only the registries supporting the v2 protocol list their tags.
+func RemoteTags(ctx context.Context, ref reference.Named, config *RemoteTagsConfig) ([]types.RemoteTag, error) {
+	var tags []types.RemoteTag
+	err := withV2Endpoints(ctx, ref, config, func(repoInfo *registry.RepositoryInfo, endpoint registry.APIEndpoint) error {
+		var err error
+		tags, err = remoteTagsV2(ctx, repoInfo, endpoint, config)
+		return err
+	})
+	return tags, err
+}
+
+// RemoteTagDescriptor returns the descriptor of the manifest of the tag ref in
+// its registry, as a content trust target is made of.
+func RemoteTagDescriptor(ctx context.Context, ref reference.NamedTagged, config *RemoteTagsConfig) (distribution.Descriptor, error) {
+	var desc distribution.Descriptor
+	err := withV2Endpoints(ctx, ref, config, func(repoInfo *registry.RepositoryInfo, endpoint registry.APIEndpoint) error {
+		repo, _, err := NewV2Repository(ctx, repoInfo, endpoint, config.MetaHeaders, config.AuthConfig, "pull")
+		if err != nil {
+			return err
+		}
+		desc, err = repo.Tags(ctx).Get(ctx, ref.Tag())
+		return err
+	})
+	return desc, err
+}
+
+// withV2Endpoints calls fn with the v2 endpoints of the registry of ref in
+// order, until one succeeds or fails with an error that is not worth
+// trying the next endpoint for.
+func withV2Endpoints(ctx context.Context, ref reference.Named, config *RemoteTagsConfig, fn func(*registry.RepositoryInfo, registry.APIEndpoint) error) error {
+	repoInfo, err := config.RegistryService.ResolveRepository(ref)
+	if err != nil {
+		return err
+	}
+	if err := validateRepoName(repoInfo.Name()); err != nil {
+		return err
+	}
+	if err := config.RegistryService.CheckAccess(repoInfo); err != nil {
+		return err
+	}
+
+	endpoints, err := config.RegistryService.LookupPullEndpoints(repoInfo.Hostname())
+	if err != nil {
+		return err
+	}
+	var lastErr error
+	for _, endpoint := range endpoints {
+		if endpoint.Version != registry.APIVersion2 {
+			continue
+		}
+		logrus.Debugf("Trying to reach %s at %s %s", repoInfo.Name(), endpoint.URL, endpoint.Version)
+		err := fn(repoInfo, endpoint)
+		if err == nil {
+			return nil
+		}
+		if fallbackErr, ok := err.(fallbackError); ok {
+			err = fallbackErr.err
+		} else if !continueOnError(err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		default:
+		}
+		logrus.Debugf("Attempting next endpoint after error: %v", err)
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no v2 endpoint found for %s", repoInfo.Name())
+	}
+	return lastErr
+}
+
+// remoteTagsV2 lists the tags of repoInfo from the v2 registry endpoint.
+func remoteTagsV2(ctx context.Context, repoInfo *registry.RepositoryInfo, endpoint registry.APIEndpoint, config *RemoteTagsConfig) ([]types.RemoteTag, error) {
+	repo, _, err := NewV2Repository(ctx, repoInfo, endpoint, config.MetaHeaders, config.AuthConfig, "pull")
+	if err != nil {
+		return nil, err
+	}
+	tagService := repo.Tags(ctx)
+	names, err := tagService.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	tags := make([]types.RemoteTag, 0, len(names))
+	for _, name := range names {
+		tag := types.RemoteTag{Tag: name}
+		if config.Digests {
+			desc, err := tagService.Get(ctx, name)
+			if err != nil {
+				return nil, fmt.Errorf("Error getting the digest of %s:%s: %v", repoInfo.Name(), name, err)
+			}
+			tag.Digest = desc.Digest.String()
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
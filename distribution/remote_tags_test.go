@@ -0,0 +1,77 @@
+package distribution
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/docker/docker/reference"
+	"github.com/docker/docker/registry"
+	"github.com/docker/engine-api/types"
+	registrytypes "github.com/docker/engine-api/types/registry"
+	"golang.org/x/net/context"
+)
+
+const (
+	digestLatest = "sha256:a59906e33509d14c036c8678d687bd4eec81ed7c4b8ce907b888c607f6a1e0e6"
+	digestStable = "sha256:8ddb0d6c5e7a1d5a55d76d89d1a8a3b1a7e5e9a0f6b3c1b4e6b3d2d4f0a5a1c2"
+)
+
+func newTagsRegistry(t *testing.T) *httptest.Server {
+	digests := map[string]string{"latest": digestLatest, "stable": digestStable}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Docker-Distribution-API-Version", "registry/2.0")
+		switch r.URL.Path {
+		case "/v2/":
+		case "/v2/library/testremotename/tags/list":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"name": "library/testremotename", "tags": ["stable", "latest"]}`)
+		case "/v2/library/testremotename/manifests/latest", "/v2/library/testremotename/manifests/stable":
+			tag := r.URL.Path[len("/v2/library/testremotename/manifests/"):]
+			w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.v2+json")
+			w.Header().Set("Content-Length", "0")
+			w.Header().Set("Docker-Content-Digest", digests[tag])
+		default:
+			t.Logf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestRemoteTagsV2(t *testing.T) {
+	ts := newTagsRegistry(t)
+	defer ts.Close()
+
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoint := registry.APIEndpoint{URL: uri, Version: registry.APIVersion2, TrimHostname: true}
+	n, _ := reference.ParseNamed("testremotename")
+	repoInfo := &registry.RepositoryInfo{
+		Named: n,
+		Index: &registrytypes.IndexInfo{Name: "testrepo"},
+	}
+
+	config := &RemoteTagsConfig{MetaHeaders: http.Header{}, AuthConfig: &types.AuthConfig{}}
+	tags, err := remoteTagsV2(context.Background(), repoInfo, endpoint, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []types.RemoteTag{{Tag: "latest"}, {Tag: "stable"}}
+	if fmt.Sprint(tags) != fmt.Sprint(expected) {
+		t.Fatalf("expected %v, got %v", expected, tags)
+	}
+
+	config.Digests = true
+	tags, err = remoteTagsV2(context.Background(), repoInfo, endpoint, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []types.RemoteTag{{Tag: "latest", Digest: digestLatest}, {Tag: "stable", Digest: digestStable}}
+	if fmt.Sprint(tags) != fmt.Sprint(expected) {
+		t.Fatalf("expected %v, got %v", expected, tags)
+	}
+}
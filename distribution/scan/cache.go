@@ -0,0 +1,83 @@
+package scan
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/distribution/digest"
+	"github.com/docker/docker/pkg/ioutils"
+	"github.com/docker/engine-api/types"
+)
+
+// Cache holds the results of the scans of images by image ID, stored as
+// JSON in a file.
+type Cache struct {
+	mu      sync.Mutex
+	path    string
+	results map[digest.Digest]types.ImageScan
+}
+
+// NewCache returns the cache stored at path.
+func NewCache(path string) (*Cache, error) {
+	c := &Cache{
+		path:    path,
+		results: make(map[digest.Digest]types.ImageScan),
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &c.results); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Get returns the result of the scan of the image id.
+func (c *Cache) Get(id digest.Digest) (types.ImageScan, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result, ok := c.results[id]
+	return result, ok
+}
+
+// Set records the result of the scan of the image id.
+func (c *Cache) Set(id digest.Digest, result types.ImageScan) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.results[id] = result
+	c.save()
+}
+
+// Remove forgets the results of the scans of the images ids.
+func (c *Cache) Remove(ids ...digest.Digest) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	removed := false
+	for _, id := range ids {
+		if _, ok := c.results[id]; ok {
+			delete(c.results, id)
+			removed = true
+		}
+	}
+	if removed {
+		c.save()
+	}
+}
+
+// save writes the results to disk. c must be locked.
+func (c *Cache) save() {
+	b, err := json.Marshal(c.results)
+	if err == nil {
+		err = ioutils.AtomicWriteFile(c.path, b, 0600)
+	}
+	if err != nil {
+		logrus.Errorf("Failed to save the image scan results: %v", err)
+	}
+}
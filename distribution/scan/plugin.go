@@ -0,0 +1,51 @@
+package scan
+
+import (
+	"errors"
+
+	"github.com/docker/docker/pkg/plugins"
+)
+
+const (
+	// ScannerAPIImplements is the name of the interface all image scanner
+	// plugins implement.
+	ScannerAPIImplements = "ImageScanner"
+
+	// scannerAPIScan is the url of the scans of images.
+	scannerAPIScan = "ImageScanner.Scan"
+)
+
+// scannerPlugin is an internal adapter to the docker plugin system.
+type scannerPlugin struct {
+	plugin *plugins.Plugin
+	name   string
+}
+
+// NewPlugin returns the scanner backed by the image scanner plugin name.
+func NewPlugin(name string) Scanner {
+	return &scannerPlugin{name: name}
+}
+
+func (s *scannerPlugin) Name() string {
+	return s.name
+}
+
+func (s *scannerPlugin) Scan(req *Request) (*Response, error) {
+	// Lazy loading of the plugin
+	if s.plugin == nil {
+		plugin, err := plugins.Get(s.name, ScannerAPIImplements)
+		if err != nil {
+			return nil, err
+		}
+		s.plugin = plugin
+	}
+
+	resp := &Response{}
+	if err := s.plugin.Client.Call(scannerAPIScan, req, resp); err != nil {
+		return nil, err
+	}
+	if resp.Err != "" {
+		return nil, errors.New(resp.Err)
+	}
+	return resp, nil
+}
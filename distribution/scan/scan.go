@@ -0,0 +1,138 @@
+// Package scan submits the images pulled and pushed by the daemon to an
+// image scanner plugin, and enforces a policy on the images that fail their
+// scan.
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/docker/distribution/digest"
+	"github.com/docker/docker/pkg/progress"
+	"github.com/docker/engine-api/types"
+)
+
+// The policies on the images that fail their scan.
+const (
+	// PolicyWarn pulls or pushes the images with a warning.
+	PolicyWarn = "warn"
+	// PolicyBlock fails the pull or the push of the images.
+	PolicyBlock = "block"
+)
+
+// The statuses of the scan of an image.
+const (
+	StatusPassed = "passed"
+	StatusFailed = "failed"
+)
+
+// The actions of the daemon on which the images are scanned.
+const (
+	ActionPull = "pull"
+	ActionPush = "push"
+)
+
+// Request is the image submitted to a scanner.
+type Request struct {
+	// Action is "pull" after an image is pulled, or "push" before an
+	// image is pushed.
+	Action string
+	// Name is the reference pulled or pushed.
+	Name string
+	// ImageID is the digest of the configuration of the image.
+	ImageID string
+	// ManifestDigest and Manifest are the manifest the image is pulled
+	// from. An image is scanned before its manifest is built on a push.
+	ManifestDigest string          `json:",omitempty"`
+	Manifest       json.RawMessage `json:",omitempty"`
+	// Config is the configuration of the image.
+	Config json.RawMessage
+	// DiffIDs are the digests of the uncompressed layers of the image,
+	// from the base layer.
+	DiffIDs []string
+}
+
+// Response is the verdict of a scanner on an image.
+type Response struct {
+	// Passed is set if the image passes the scan.
+	Passed bool
+	// Msg describes the result of the scan.
+	Msg string `json:",omitempty"`
+	// Err stores a message in case the scan failed to run.
+	Err string `json:",omitempty"`
+}
+
+// Scanner scans images.
+type Scanner interface {
+	// Name returns the name of the scanner.
+	Name() string
+	// Scan scans the image of the request.
+	Scan(*Request) (*Response, error)
+}
+
+// ValidatePolicy validates the policy on the images that fail their scan. An
+// empty policy is PolicyWarn.
+func ValidatePolicy(policy string) error {
+	switch policy {
+	case "", PolicyWarn, PolicyBlock:
+		return nil
+	}
+	return fmt.Errorf("invalid image scan policy %s: must be %s or %s", policy, PolicyWarn, PolicyBlock)
+}
+
+// Hook scans the images with a scanner, and enforces a policy on the images
+// that fail their scan. The results of the scans are cached by image ID.
+type Hook struct {
+	scanner Scanner
+	policy  string
+	cache   *Cache
+}
+
+// NewHook returns a hook scanning the images with scanner, enforcing policy
+// and caching the results in cache.
+func NewHook(scanner Scanner, policy string, cache *Cache) *Hook {
+	return &Hook{scanner: scanner, policy: policy, cache: cache}
+}
+
+// Check scans the image of req, unless the result of its scan is cached. An
+// image that fails its scan, or that cannot be scanned, is reported on out
+// with PolicyWarn, and fails the check with PolicyBlock.
+func (h *Hook) Check(req *Request, out progress.Output) error {
+	id := digest.Digest(req.ImageID)
+	result, ok := h.cache.Get(id)
+	if !ok {
+		resp, err := h.scanner.Scan(req)
+		if err != nil {
+			return h.enforce(out, fmt.Sprintf("the image scanner %s failed to scan %s: %v", h.scanner.Name(), req.Name, err))
+		}
+		result = types.ImageScan{
+			Scanner: h.scanner.Name(),
+			Status:  StatusFailed,
+			Message: resp.Msg,
+			Scanned: time.Now().UTC().Format(time.RFC3339Nano),
+		}
+		if resp.Passed {
+			result.Status = StatusPassed
+		}
+		h.cache.Set(id, result)
+	}
+
+	if result.Status == StatusPassed {
+		return nil
+	}
+	msg := fmt.Sprintf("%s failed the scan of the image scanner %s", req.Name, result.Scanner)
+	if result.Message != "" {
+		msg += ": " + result.Message
+	}
+	return h.enforce(out, msg)
+}
+
+// enforce fails with msg with PolicyBlock, or reports it on out.
+func (h *Hook) enforce(out progress.Output, msg string) error {
+	if h.policy == PolicyBlock {
+		return fmt.Errorf("%s (blocked by the image scan policy)", msg)
+	}
+	progress.Message(out, "", "Warning: "+msg)
+	return nil
+}
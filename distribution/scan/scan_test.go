@@ -0,0 +1,123 @@
+package scan
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/distribution/digest"
+	"github.com/docker/docker/pkg/progress"
+)
+
+type fakeScanner struct {
+	resp  *Response
+	err   error
+	scans int
+}
+
+func (s *fakeScanner) Name() string {
+	return "fake"
+}
+
+func (s *fakeScanner) Scan(*Request) (*Response, error) {
+	s.scans++
+	return s.resp, s.err
+}
+
+type messages []string
+
+func (m *messages) WriteProgress(p progress.Progress) error {
+	*m = append(*m, p.Message)
+	return nil
+}
+
+func newTestCache(t *testing.T) (*Cache, func()) {
+	dir, err := ioutil.TempDir("", "scan-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewCache(filepath.Join(dir, "scans.json"))
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return c, func() { os.RemoveAll(dir) }
+}
+
+func TestHookCachesResults(t *testing.T) {
+	c, cleanup := newTestCache(t)
+	defer cleanup()
+
+	scanner := &fakeScanner{resp: &Response{Passed: true}}
+	h := NewHook(scanner, PolicyBlock, c)
+	req := &Request{Action: ActionPull, Name: "busybox:latest", ImageID: "sha256:a"}
+	for i := 0; i < 2; i++ {
+		if err := h.Check(req, &messages{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if scanner.scans != 1 {
+		t.Fatalf("expected the image to be scanned once, got %d scans", scanner.scans)
+	}
+
+	reloaded, err := NewCache(c.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, ok := reloaded.Get(digest.Digest("sha256:a"))
+	if !ok || result.Status != StatusPassed || result.Scanner != "fake" {
+		t.Fatalf("unexpected cached result %+v", result)
+	}
+	reloaded.Remove(digest.Digest("sha256:a"))
+	if _, ok := reloaded.Get(digest.Digest("sha256:a")); ok {
+		t.Fatal("expected the result to be removed")
+	}
+}
+
+func TestHookPolicy(t *testing.T) {
+	c, cleanup := newTestCache(t)
+	defer cleanup()
+
+	failed := &fakeScanner{resp: &Response{Msg: "2 critical vulnerabilities"}}
+	req := &Request{Action: ActionPush, Name: "busybox:latest", ImageID: "sha256:b"}
+
+	out := &messages{}
+	if err := NewHook(failed, PolicyWarn, c).Check(req, out); err != nil {
+		t.Fatal(err)
+	}
+	if len(*out) != 1 || !strings.Contains((*out)[0], "2 critical vulnerabilities") {
+		t.Fatalf("expected a warning, got %v", *out)
+	}
+
+	err := NewHook(failed, PolicyBlock, c).Check(req, &messages{})
+	if err == nil || !strings.Contains(err.Error(), "2 critical vulnerabilities") {
+		t.Fatalf("expected the image to be blocked, got %v", err)
+	}
+	if failed.scans != 1 {
+		t.Fatalf("expected the image to be scanned once, got %d scans", failed.scans)
+	}
+
+	// the images that cannot be scanned are not cached
+	broken := &fakeScanner{err: errors.New("unreachable")}
+	req.ImageID = "sha256:c"
+	if err := NewHook(broken, PolicyBlock, c).Check(req, &messages{}); err == nil {
+		t.Fatal("expected the image to be blocked")
+	}
+	if _, ok := c.Get(digest.Digest("sha256:c")); ok {
+		t.Fatal("expected the failure of the scanner not to be cached")
+	}
+}
+
+func TestValidatePolicy(t *testing.T) {
+	for _, policy := range []string{"", PolicyWarn, PolicyBlock} {
+		if err := ValidatePolicy(policy); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ValidatePolicy("ignore"); err == nil {
+		t.Fatal("expected an error for an invalid policy")
+	}
+}
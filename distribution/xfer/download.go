@@ -23,6 +23,7 @@ const maxDownloadAttempts = 5
 type LayerDownloadManager struct {
 	layerStore layer.Store
 	tm         TransferManager
+	throttle   Throttle
 }
 
 // SetConcurrency set the max concurrent downloads for each pull
@@ -30,6 +31,18 @@ func (ldm *LayerDownloadManager) SetConcurrency(concurrency int) {
 	ldm.tm.SetConcurrency(concurrency)
 }
 
+// SetRateLimit sets the maximum bandwidth of all the downloads, and of each
+// download, in bytes per second. 0 means unlimited.
+func (ldm *LayerDownloadManager) SetRateLimit(rate, perDownloadRate int64) {
+	ldm.throttle.SetRate(rate, perDownloadRate)
+}
+
+// ThrottleState returns the bandwidth limits of the downloads and the number
+// of layers being downloaded.
+func (ldm *LayerDownloadManager) ThrottleState() ThrottleState {
+	return ldm.throttle.State()
+}
+
 // NewLayerDownloadManager returns a new LayerDownloadManager.
 func NewLayerDownloadManager(layerStore layer.Store, concurrencyLimit int) *LayerDownloadManager {
 	return &LayerDownloadManager{
@@ -245,7 +258,7 @@ func (ldm *LayerDownloadManager) makeDownloadFunc(descriptor DownloadDescriptor,
 			defer descriptor.Close()
 
 			for {
-				downloadReader, size, err = descriptor.Download(d.Transfer.Context(), progressOutput)
+				downloadReader, size, err = descriptor.Download(withThrottle(d.Transfer.Context(), &ldm.throttle), progressOutput)
 				if err == nil {
 					break
 				}
@@ -0,0 +1,148 @@
+package xfer
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// throttleChunkSize is the largest read accounted at once, so that throttled
+// transfers progress smoothly.
+const throttleChunkSize = 32 * 1024
+
+// rateLimiter limits the rate of the data read through it.
+type rateLimiter struct {
+	mu sync.Mutex
+	// rate is the limit in bytes per second, 0 if unlimited.
+	rate int64
+	// next is the time at which the data accounted so far will have been
+	// transferred at rate.
+	next time.Time
+}
+
+// reserve accounts n bytes and returns how long to wait to keep under the
+// rate.
+func (l *rateLimiter) reserve(n int) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.rate <= 0 {
+		return 0
+	}
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	l.next = l.next.Add(time.Duration(int64(n) * int64(time.Second) / l.rate))
+	return l.next.Sub(now)
+}
+
+func (l *rateLimiter) setRate(rate int64) {
+	l.mu.Lock()
+	l.rate = rate
+	l.next = time.Time{}
+	l.mu.Unlock()
+}
+
+// Throttle limits the bandwidth of the transfers of a transfer manager, in
+// aggregate and per transfer.
+type Throttle struct {
+	aggregate   rateLimiter
+	perTransfer int64
+	active      int32
+}
+
+// ThrottleState describes the limits of a Throttle and the number of
+// transfers currently going through it.
+type ThrottleState struct {
+	Rate            int64
+	PerTransferRate int64
+	ActiveTransfers int
+}
+
+// SetRate sets the aggregate and per transfer limits of t, in bytes per
+// second. A limit of 0 disables it. Transfers in progress keep their per
+// transfer limit.
+func (t *Throttle) SetRate(rate, perTransferRate int64) {
+	t.aggregate.setRate(rate)
+	atomic.StoreInt64(&t.perTransfer, perTransferRate)
+}
+
+// State returns the current state of t.
+func (t *Throttle) State() ThrottleState {
+	t.aggregate.mu.Lock()
+	rate := t.aggregate.rate
+	t.aggregate.mu.Unlock()
+	return ThrottleState{
+		Rate:            rate,
+		PerTransferRate: atomic.LoadInt64(&t.perTransfer),
+		ActiveTransfers: int(atomic.LoadInt32(&t.active)),
+	}
+}
+
+type throttleKey struct{}
+
+// withThrottle returns a context carrying t, for the transfer to use in
+// ThrottleReader.
+func withThrottle(ctx context.Context, t *Throttle) context.Context {
+	return context.WithValue(ctx, throttleKey{}, t)
+}
+
+// ThrottleReader returns a reader limiting the rate at which r is read to the
+// limits of the throttle of the transfer ctx belongs to. Transfers should
+// wrap the reader of the data they send or receive over the network with
+// it. If ctx has no throttle, r is returned.
+func ThrottleReader(ctx context.Context, r io.ReadCloser) io.ReadCloser {
+	t, ok := ctx.Value(throttleKey{}).(*Throttle)
+	if !ok || t == nil {
+		return r
+	}
+	atomic.AddInt32(&t.active, 1)
+	return &throttledReader{
+		ctx:      ctx,
+		r:        r,
+		throttle: t,
+		transfer: rateLimiter{rate: atomic.LoadInt64(&t.perTransfer)},
+	}
+}
+
+type throttledReader struct {
+	ctx      context.Context
+	r        io.ReadCloser
+	throttle *Throttle
+	transfer rateLimiter
+	closed   int32
+}
+
+func (r *throttledReader) Read(p []byte) (int, error) {
+	if len(p) > throttleChunkSize {
+		p = p[:throttleChunkSize]
+	}
+	n, err := r.r.Read(p)
+	if n <= 0 {
+		return n, err
+	}
+	delay := r.throttle.aggregate.reserve(n)
+	if d := r.transfer.reserve(n); d > delay {
+		delay = d
+	}
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-r.ctx.Done():
+			return n, r.ctx.Err()
+		}
+	}
+	return n, err
+}
+
+func (r *throttledReader) Close() error {
+	if atomic.CompareAndSwapInt32(&r.closed, 0, 1) {
+		atomic.AddInt32(&r.throttle.active, -1)
+	}
+	return r.r.Close()
+}
@@ -0,0 +1,70 @@
+package xfer
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestThrottleReaderWithoutThrottle(t *testing.T) {
+	r := ioutil.NopCloser(bytes.NewReader(nil))
+	if ThrottleReader(context.Background(), r) != r {
+		t.Fatal("expected the reader to be returned unchanged")
+	}
+}
+
+func TestThrottleReader(t *testing.T) {
+	var throttle Throttle
+	throttle.SetRate(0, 256*1024)
+	ctx := withThrottle(context.Background(), &throttle)
+
+	r := ThrottleReader(ctx, ioutil.NopCloser(bytes.NewReader(make([]byte, 64*1024))))
+	if state := throttle.State(); state.ActiveTransfers != 1 || state.PerTransferRate != 256*1024 {
+		t.Fatalf("unexpected throttle state %+v", state)
+	}
+
+	start := time.Now()
+	if _, err := io.Copy(ioutil.Discard, r); err != nil {
+		t.Fatal(err)
+	}
+	// 64KB at 256KB/s takes a quarter of a second.
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Fatalf("expected the read to be throttled, took %v", elapsed)
+	}
+
+	r.Close()
+	r.Close()
+	if state := throttle.State(); state.ActiveTransfers != 0 {
+		t.Fatalf("expected no active transfer, got %d", state.ActiveTransfers)
+	}
+}
+
+func TestThrottleReaderCancel(t *testing.T) {
+	var throttle Throttle
+	throttle.SetRate(1024, 0)
+	ctx, cancel := context.WithCancel(withThrottle(context.Background(), &throttle))
+
+	r := ThrottleReader(ctx, ioutil.NopCloser(bytes.NewReader(make([]byte, 64*1024))))
+	defer r.Close()
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+	done := make(chan error)
+	go func() {
+		_, err := io.Copy(ioutil.Discard, r)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Fatalf("expected the read to be cancelled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the throttled read was not cancelled")
+	}
+}
@@ -16,7 +16,8 @@ const maxUploadAttempts = 5
 // LayerUploadManager provides task management and progress reporting for
 // uploads.
 type LayerUploadManager struct {
-	tm TransferManager
+	tm       TransferManager
+	throttle Throttle
 }
 
 // SetConcurrency set the max concurrent uploads for each push
@@ -24,6 +25,18 @@ func (lum *LayerUploadManager) SetConcurrency(concurrency int) {
 	lum.tm.SetConcurrency(concurrency)
 }
 
+// SetRateLimit sets the maximum bandwidth of all the uploads, and of each
+// upload, in bytes per second. 0 means unlimited.
+func (lum *LayerUploadManager) SetRateLimit(rate, perUploadRate int64) {
+	lum.throttle.SetRate(rate, perUploadRate)
+}
+
+// ThrottleState returns the bandwidth limits of the uploads and the number
+// of layers being uploaded.
+func (lum *LayerUploadManager) ThrottleState() ThrottleState {
+	return lum.throttle.State()
+}
+
 // NewLayerUploadManager returns a new LayerUploadManager.
 func NewLayerUploadManager(concurrencyLimit int) *LayerUploadManager {
 	return &LayerUploadManager{
@@ -118,7 +131,7 @@ func (lum *LayerUploadManager) makeUploadFunc(descriptor UploadDescriptor) DoFun
 
 			retries := 0
 			for {
-				remoteDescriptor, err := descriptor.Upload(u.Transfer.Context(), progressOutput)
+				remoteDescriptor, err := descriptor.Upload(withThrottle(u.Transfer.Context(), &lum.throttle), progressOutput)
 				if err == nil {
 					u.remoteDescriptor = remoteDescriptor
 					break
@@ -1,12 +1,22 @@
 package errors
 
-import "net/http"
+import (
+	"net/http"
+
+	"github.com/docker/engine-api/types"
+)
+
+// statusTooManyRequests is the status of the requests the daemon lacks the
+// resources to satisfy. net/http only defines it as of Go 1.6.
+const statusTooManyRequests = 429
 
 // apiError is an error wrapper that also
 // holds information about response status codes.
 type apiError struct {
 	error
 	statusCode int
+	code       string
+	subsystem  string
 }
 
 // HTTPErrorStatusCode returns a status code.
@@ -14,12 +24,71 @@ func (e apiError) HTTPErrorStatusCode() int {
 	return e.statusCode
 }
 
+// ErrorCode returns the stable code of the error.
+func (e apiError) ErrorCode() string {
+	if e.code != "" {
+		return e.code
+	}
+	return StatusCode(e.statusCode)
+}
+
+// Subsystem returns the subsystem of the container
+// runtime the error comes from, if any.
+func (e apiError) Subsystem() string {
+	return e.subsystem
+}
+
+// codeStatus holds the HTTP status codes of the
+// error codes, the most specific status first.
+var codeStatus = []struct {
+	code   string
+	status int
+}{
+	{types.ErrorCodeInvalidParameter, http.StatusBadRequest},
+	{types.ErrorCodeUnauthorized, http.StatusUnauthorized},
+	{types.ErrorCodeForbidden, http.StatusForbidden},
+	{types.ErrorCodeNotFound, http.StatusNotFound},
+	{types.ErrorCodeConflict, http.StatusConflict},
+	{types.ErrorCodePreconditionFailed, http.StatusPreconditionFailed},
+	{types.ErrorCodeResourceExhausted, statusTooManyRequests},
+	{types.ErrorCodeNotImplemented, http.StatusNotImplemented},
+	{types.ErrorCodeUnavailable, http.StatusServiceUnavailable},
+	{types.ErrorCodeInternal, http.StatusInternalServerError},
+	{types.ErrorCodeRuntime, http.StatusInternalServerError},
+}
+
+// StatusCode returns the error code of the errors
+// sent with the HTTP status code statusCode, for
+// the errors without a code of their own.
+func StatusCode(statusCode int) string {
+	for _, cs := range codeStatus {
+		if cs.status == statusCode {
+			return cs.code
+		}
+	}
+	if statusCode >= 400 && statusCode < 500 {
+		return types.ErrorCodeInvalidParameter
+	}
+	return types.ErrorCodeInternal
+}
+
+// codeStatusCode returns the HTTP status code of
+// the errors with the error code code.
+func codeStatusCode(code string) int {
+	for _, cs := range codeStatus {
+		if cs.code == code {
+			return cs.status
+		}
+	}
+	return http.StatusInternalServerError
+}
+
 // NewErrorWithStatusCode allows you to associate
 // a specific HTTP Status Code to an error.
 // The Server will take that code and set
 // it as the response status.
 func NewErrorWithStatusCode(err error, code int) error {
-	return apiError{err, code}
+	return apiError{error: err, statusCode: code}
 }
 
 // NewBadRequestError creates a new API error
@@ -45,3 +114,27 @@ func NewRequestNotFoundError(err error) error {
 func NewRequestConflictError(err error) error {
 	return NewErrorWithStatusCode(err, http.StatusConflict)
 }
+
+// NewPreconditionFailedError creates a new API error
+// that has the 412 HTTP status code associated to it,
+// for the requests that the state of their object
+// prevents from being done.
+func NewPreconditionFailedError(err error) error {
+	return NewErrorWithStatusCode(err, http.StatusPreconditionFailed)
+}
+
+// NewResourceExhaustedError creates a new API error
+// that has the 429 HTTP status code associated to it,
+// for the requests that the daemon lacks the resources,
+// or the quota, to satisfy.
+func NewResourceExhaustedError(err error) error {
+	return NewErrorWithStatusCode(err, statusTooManyRequests)
+}
+
+// NewRuntimeError creates a new API error for a failure
+// of subsystem, a subsystem of the container runtime.
+// code is the error code of the failure, or the
+// runtime_error code if it has no more specific cause.
+func NewRuntimeError(err error, code, subsystem string) error {
+	return apiError{error: err, statusCode: codeStatusCode(code), code: code, subsystem: subsystem}
+}
@@ -7,6 +7,11 @@ source 'hack/.vendor-helpers.sh'
 
 # the following lines are in sorted order, FYI
 clone git github.com/Azure/go-ansiterm 388960b655244e76e24c75f48631564eaefade62
+# vendor/ carries local hcsshim changes (compute system properties and
+# enumeration, HNS endpoint queries, HCS handle API for pause and resume)
+# pending upstream, with zhcsshim.go regenerated by mksyscall_windows.go; bump
+# this pin to the upstream release once they are merged, ./hack/vendor.sh
+# reverts them
 clone git github.com/Microsoft/hcsshim v0.2.2
 clone git github.com/Microsoft/go-winio v0.3.4
 clone git github.com/Sirupsen/logrus v0.10.0 # logrus is a common dependency among multiple deps
@@ -24,11 +29,16 @@ clone git golang.org/x/net 78cb2c067747f08b343f20614155233ab4ea2ad3 https://gith
 clone git golang.org/x/sys eb2c74142fd19a79b3f237334c7384d5167b1b46 https://github.com/golang/sys.git
 clone git github.com/docker/go-units 651fc226e7441360384da338d0fd37f2440ffbe3
 clone git github.com/docker/go-connections v0.2.0
+# vendor/ carries local engine-api changes (API types of this tree) pending upstream;
+# bump this pin to the upstream commit once they are merged, ./hack/vendor.sh reverts them
 clone git github.com/docker/engine-api e374c4fb5b121a8fd4295ec5eb91a8068c6304f4
 clone git github.com/RackSec/srslog 259aed10dfa74ea2961eddd1d9847619f6e98837
 clone git github.com/imdario/mergo 0.2.1
 
 #get libnetwork packages
+# vendor/ carries local libnetwork changes (published port ranges, egress and
+# isolation policies, address reservations, diagnostics) pending upstream; bump
+# this pin to the upstream commit once they are merged, ./hack/vendor.sh reverts them
 clone git github.com/docker/libnetwork b66c0385f30c6aa27b2957ed1072682c19a0b0b4
 clone git github.com/docker/go-events 2e7d352816128aa84f4d29b2a21d400133701a0d
 clone git github.com/armon/go-radix e39d623f12e8e41c7b5529e9a9dd67a1e2261f80
@@ -67,6 +77,9 @@ clone git github.com/docker/go v1.5.1-1-1-gbaf439e
 clone git github.com/agl/ed25519 d2b94fd789ea21d12fac1a4443dd3a3f79cda72c
 
 clone git github.com/opencontainers/runc d49ece5a83da3dcb820121d6850e2b61bd0a5fbe # libcontainer
+# vendor/ carries a local specs change (Resources.Unified, the cgroup v2 resources)
+# pending upstream; bump this pin to the upstream commit once it is merged,
+# ./hack/vendor.sh reverts it
 clone git github.com/opencontainers/specs f955d90e70a98ddfb886bd930ffd076da9b67998 # specs
 clone git github.com/seccomp/libseccomp-golang 1b506fc7c24eec5a3693cdcbed40d9c226cfc6a1
 # libcontainer deps (see src/github.com/opencontainers/runc/Godeps/Godeps.json)
@@ -0,0 +1,79 @@
+package image
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// OSCompatibility describes whether an image can run on a host.
+type OSCompatibility int
+
+const (
+	// OSCompatible images run on the host with any isolation.
+	OSCompatible OSCompatibility = iota
+	// OSCompatibleHyperV images only run in Hyper-V containers, whose
+	// utility VM provides the kernel and features they were built for.
+	OSCompatibleHyperV
+	// OSIncompatible images were built for a newer version of the
+	// operating system than the host's and cannot run on it.
+	OSIncompatible
+)
+
+// CheckOSCompatibility compares the os.version and os.features of a Windows
+// image with the version and features of the host. Windows images can only
+// share the kernel of a host with the same major, minor and build numbers.
+// An image without os.version, or for another operating system, is
+// considered compatible. The returned error explains why an image is not
+// OSCompatible.
+func CheckOSCompatibility(img *Image, hostVersion string, hostFeatures []string) (OSCompatibility, error) {
+	if img.OS != "windows" || img.OSVersion == "" {
+		return OSCompatible, nil
+	}
+	imageBuild, ok := parseOSBuild(img.OSVersion)
+	if !ok {
+		return OSCompatible, nil
+	}
+	hostBuild, ok := parseOSBuild(hostVersion)
+	if !ok {
+		return OSCompatible, nil
+	}
+
+	for i := range imageBuild {
+		if imageBuild[i] > hostBuild[i] {
+			return OSIncompatible, fmt.Errorf("image was built for Windows %s, which is newer than this host's %s", img.OSVersion, hostVersion)
+		}
+		if imageBuild[i] < hostBuild[i] {
+			return OSCompatibleHyperV, fmt.Errorf("image was built for Windows %s, which differs from this host's %s", img.OSVersion, hostVersion)
+		}
+	}
+
+	supported := make(map[string]bool, len(hostFeatures))
+	for _, f := range hostFeatures {
+		supported[strings.ToLower(f)] = true
+	}
+	for _, f := range img.OSFeatures {
+		if !supported[strings.ToLower(f)] {
+			return OSCompatibleHyperV, fmt.Errorf("image requires the %s OS feature, which this host does not provide", f)
+		}
+	}
+	return OSCompatible, nil
+}
+
+// parseOSBuild returns the major, minor and build numbers of a Windows
+// version such as "10.0.14393" or "10.0.14393.321".
+func parseOSBuild(version string) ([3]int, bool) {
+	var build [3]int
+	parts := strings.Split(version, ".")
+	if len(parts) < len(build) {
+		return build, false
+	}
+	for i := range build {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil {
+			return build, false
+		}
+		build[i] = n
+	}
+	return build, true
+}
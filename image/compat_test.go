@@ -0,0 +1,32 @@
+package image
+
+import "testing"
+
+func TestCheckOSCompatibility(t *testing.T) {
+	cases := []struct {
+		os, version string
+		features    []string
+		expected    OSCompatibility
+	}{
+		{"linux", "", nil, OSCompatible},
+		{"windows", "", nil, OSCompatible},
+		{"windows", "unknown", nil, OSCompatible},
+		{"windows", "10.0.14393", nil, OSCompatible},
+		{"windows", "10.0.14393.321", nil, OSCompatible},
+		{"windows", "10.0.14393", []string{"win32k"}, OSCompatible},
+		{"windows", "10.0.14393", []string{"ntfs"}, OSCompatibleHyperV},
+		{"windows", "10.0.14300", nil, OSCompatibleHyperV},
+		{"windows", "10.0.15063", nil, OSIncompatible},
+		{"windows", "10.1.0", nil, OSIncompatible},
+	}
+	for _, c := range cases {
+		img := &Image{V1Image: V1Image{OS: c.os}, OSVersion: c.version, OSFeatures: c.features}
+		actual, err := CheckOSCompatibility(img, "10.0.14393", []string{"WIN32K"})
+		if actual != c.expected {
+			t.Fatalf("%s %s %v: expected %d, got %d (%v)", c.os, c.version, c.features, c.expected, actual, err)
+		}
+		if (err == nil) != (actual == OSCompatible) {
+			t.Fatalf("%s %s %v: unexpected error %v", c.os, c.version, c.features, err)
+		}
+	}
+}
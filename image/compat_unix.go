@@ -0,0 +1,9 @@
+// +build !windows
+
+package image
+
+// HostOSCompatibility returns whether img can run on this host. Only
+// Windows images are tied to the version of their host.
+func HostOSCompatibility(img *Image) (OSCompatibility, error) {
+	return OSCompatible, nil
+}
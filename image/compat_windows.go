@@ -0,0 +1,28 @@
+package image
+
+import (
+	"fmt"
+
+	"github.com/docker/docker/pkg/system"
+	"golang.org/x/sys/windows/registry"
+)
+
+// HostOSCompatibility returns whether img can run on this host, as described
+// by CheckOSCompatibility.
+func HostOSCompatibility(img *Image) (OSCompatibility, error) {
+	osv := system.GetOSVersion()
+	return CheckOSCompatibility(img, fmt.Sprintf("%d.%d.%d", osv.MajorVersion, osv.MinorVersion, osv.Build), hostOSFeatures())
+}
+
+// hostOSFeatures returns the os.features the host provides to process
+// isolated containers. Nano Server hosts do not provide win32k.
+func hostOSFeatures() []string {
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\Windows NT\CurrentVersion\Server\ServerLevels`, registry.QUERY_VALUE)
+	if err == nil {
+		defer k.Close()
+		if nano, _, err := k.GetIntegerValue("NanoServer"); err == nil && nano == 1 {
+			return nil
+		}
+	}
+	return []string{"win32k"}
+}
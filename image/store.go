@@ -200,12 +200,14 @@ func (is *store) Get(id ID) (*Image, error) {
 	return img, nil
 }
 
+// Delete removes the image id from the store, and releases its layer. The
+// layer is released without holding the lock of the store, as removing the
+// layers no longer referenced can take long.
 func (is *store) Delete(id ID) ([]layer.Metadata, error) {
 	is.Lock()
-	defer is.Unlock()
-
 	imageMeta := is.images[id]
 	if imageMeta == nil {
+		is.Unlock()
 		return nil, fmt.Errorf("unrecognized image ID %s", id.String())
 	}
 	for id := range imageMeta.children {
@@ -220,6 +222,7 @@ func (is *store) Delete(id ID) ([]layer.Metadata, error) {
 	}
 	delete(is.images, id)
 	is.fs.Delete(id)
+	is.Unlock()
 
 	if imageMeta.layer != nil {
 		return is.ls.Release(imageMeta.layer)
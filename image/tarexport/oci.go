@@ -0,0 +1,385 @@
+package tarexport
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/docker/distribution/digest"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/layer"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/progress"
+	"github.com/docker/docker/pkg/system"
+	"github.com/docker/docker/reference"
+)
+
+// Files and media types of the OCI image layout, as defined by the OCI image
+// specification.
+const (
+	ociLayoutFileName = "oci-layout"
+	ociIndexFileName  = "index.json"
+	ociBlobsDirName   = "blobs"
+	ociLayoutVersion  = "1.0.0"
+
+	ociMediaTypeManifest     = "application/vnd.oci.image.manifest.v1+json"
+	ociMediaTypeConfig       = "application/vnd.oci.image.config.v1+json"
+	ociMediaTypeLayer        = "application/vnd.oci.image.layer.v1.tar"
+	ociMediaTypeLayerGzip    = "application/vnd.oci.image.layer.v1.tar+gzip"
+	ociMediaTypeLayerZstd    = "application/vnd.oci.image.layer.v1.tar+zstd"
+	dockerMediaTypeManifest  = "application/vnd.docker.distribution.manifest.v2+json"
+	dockerMediaTypeLayerGzip = "application/vnd.docker.image.rootfs.diff.tar.gzip"
+
+	// ociRefNameAnnotation holds the tag of an image in the index.
+	ociRefNameAnnotation = "org.opencontainers.image.ref.name"
+	// imageNameAnnotation holds the full reference of an image in the
+	// index, as containerd does, since the OCI annotation only holds the
+	// tag.
+	imageNameAnnotation = "io.containerd.image.name"
+)
+
+type ociLayout struct {
+	ImageLayoutVersion string `json:"imageLayoutVersion"`
+}
+
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      digest.Digest     `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type ociIndex struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	Manifests     []ociDescriptor `json:"manifests"`
+}
+
+type ociManifest struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	MediaType     string          `json:"mediaType,omitempty"`
+	Config        ociDescriptor   `json:"config"`
+	Layers        []ociDescriptor `json:"layers"`
+}
+
+type ociExporter struct {
+	*tarexporter
+}
+
+// NewOCIExporter returns a new ImageExporter that saves images as a tar
+// archive of an OCI image layout. Loading accepts the same archives as the
+// exporter returned by NewTarExporter.
+func NewOCIExporter(is image.Store, ls layer.Store, rs reference.Store, loggerImgEvent LogImageEvent) image.Exporter {
+	return &ociExporter{
+		tarexporter: &tarexporter{
+			is:             is,
+			ls:             ls,
+			rs:             rs,
+			loggerImgEvent: loggerImgEvent,
+		},
+	}
+}
+
+// Save writes the images matching names to outStream as a tar archive of an
+// OCI image layout: each image is described by a manifest referenced from
+// index.json, and the manifests, image configurations and uncompressed layers
+// are stored as blobs named by their digest.
+func (e *ociExporter) Save(names []string, outStream io.Writer) error {
+	images, err := e.parseNames(names)
+	if err != nil {
+		return err
+	}
+
+	tempDir, err := ioutil.TempDir("", "docker-export-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+
+	if err := os.MkdirAll(filepath.Join(tempDir, ociBlobsDirName, string(digest.Canonical)), 0755); err != nil {
+		return err
+	}
+
+	index := ociIndex{SchemaVersion: 2, Manifests: []ociDescriptor{}}
+	for id, imageDescr := range images {
+		desc, err := e.saveOCIImage(tempDir, id)
+		if err != nil {
+			return err
+		}
+		if len(imageDescr.refs) == 0 {
+			index.Manifests = append(index.Manifests, desc)
+		}
+		for _, ref := range imageDescr.refs {
+			d := desc
+			d.Annotations = map[string]string{
+				ociRefNameAnnotation: ref.Tag(),
+				imageNameAnnotation:  ref.String(),
+			}
+			index.Manifests = append(index.Manifests, d)
+		}
+		e.loggerImgEvent.LogImageEvent(id.String(), id.String(), "save")
+	}
+
+	if err := writeOCIFile(filepath.Join(tempDir, ociLayoutFileName), ociLayout{ImageLayoutVersion: ociLayoutVersion}); err != nil {
+		return err
+	}
+	if err := writeOCIFile(filepath.Join(tempDir, ociIndexFileName), index); err != nil {
+		return err
+	}
+
+	fs, err := archive.Tar(tempDir, archive.Uncompressed)
+	if err != nil {
+		return err
+	}
+	defer fs.Close()
+
+	_, err = io.Copy(outStream, fs)
+	return err
+}
+
+// saveOCIImage writes the blobs of the image with the given id to the layout
+// rooted at dir and returns the descriptor of its manifest.
+func (e *ociExporter) saveOCIImage(dir string, id image.ID) (ociDescriptor, error) {
+	img, err := e.is.Get(id)
+	if err != nil {
+		return ociDescriptor{}, err
+	}
+	if len(img.RootFS.DiffIDs) == 0 {
+		return ociDescriptor{}, fmt.Errorf("empty export - not implemented")
+	}
+
+	config, err := writeOCIBlob(dir, ociMediaTypeConfig, img.RawJSON())
+	if err != nil {
+		return ociDescriptor{}, err
+	}
+	manifest := ociManifest{
+		SchemaVersion: 2,
+		MediaType:     ociMediaTypeManifest,
+		Config:        config,
+	}
+
+	rootFS := *img.RootFS
+	for i := range img.RootFS.DiffIDs {
+		rootFS.DiffIDs = img.RootFS.DiffIDs[:i+1]
+		desc, err := e.saveOCILayer(dir, rootFS.ChainID(), img.RootFS.DiffIDs[i])
+		if err != nil {
+			return ociDescriptor{}, err
+		}
+		manifest.Layers = append(manifest.Layers, desc)
+	}
+
+	content, err := json.Marshal(manifest)
+	if err != nil {
+		return ociDescriptor{}, err
+	}
+	return writeOCIBlob(dir, ociMediaTypeManifest, content)
+}
+
+// saveOCILayer writes the uncompressed layer with the given chain ID to the
+// layout rooted at dir, unless a previous image already did. The digest of
+// an uncompressed layer is its diff ID.
+func (e *ociExporter) saveOCILayer(dir string, id layer.ChainID, diffID layer.DiffID) (ociDescriptor, error) {
+	desc := ociDescriptor{
+		MediaType: ociMediaTypeLayer,
+		Digest:    digest.Digest(diffID),
+	}
+	blobPath := filepath.Join(dir, ociBlobsDirName, desc.Digest.Algorithm().String(), desc.Digest.Hex())
+	if fi, err := os.Stat(blobPath); err == nil {
+		desc.Size = fi.Size()
+		return desc, nil
+	}
+
+	l, err := e.ls.Get(id)
+	if err != nil {
+		return ociDescriptor{}, err
+	}
+	defer layer.ReleaseAndLog(e.ls, l)
+
+	arch, err := l.TarStream()
+	if err != nil {
+		return ociDescriptor{}, err
+	}
+	defer arch.Close()
+
+	f, err := os.Create(blobPath)
+	if err != nil {
+		return ociDescriptor{}, err
+	}
+	defer f.Close()
+	if desc.Size, err = io.Copy(f, arch); err != nil {
+		return ociDescriptor{}, err
+	}
+	return desc, nil
+}
+
+func writeOCIBlob(dir, mediaType string, content []byte) (ociDescriptor, error) {
+	desc := ociDescriptor{
+		MediaType: mediaType,
+		Digest:    digest.FromBytes(content),
+		Size:      int64(len(content)),
+	}
+	blobPath := filepath.Join(dir, ociBlobsDirName, desc.Digest.Algorithm().String(), desc.Digest.Hex())
+	if err := ioutil.WriteFile(blobPath, content, 0644); err != nil {
+		return ociDescriptor{}, err
+	}
+	return desc, system.Chtimes(blobPath, time.Unix(0, 0), time.Unix(0, 0))
+}
+
+func writeOCIFile(path string, v interface{}) error {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path, content, 0644); err != nil {
+		return err
+	}
+	return system.Chtimes(path, time.Unix(0, 0), time.Unix(0, 0))
+}
+
+// isOCILayout returns whether dir holds an OCI image layout.
+func isOCILayout(dir string) bool {
+	layoutPath, err := safePath(dir, ociLayoutFileName)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(layoutPath)
+	return err == nil
+}
+
+// ociLoad loads the images of the OCI image layout rooted at dir. Images are
+// tagged from the containerd image name annotation when present, or else
+// from the OCI reference name annotation when it is a full reference.
+func (l *tarexporter) ociLoad(dir string, outStream io.Writer, progressOutput progress.Output) error {
+	var layout ociLayout
+	if err := readOCIFile(dir, ociLayoutFileName, &layout); err != nil {
+		return err
+	}
+	if layout.ImageLayoutVersion != ociLayoutVersion {
+		return fmt.Errorf("unsupported OCI image layout version %q", layout.ImageLayoutVersion)
+	}
+	var index ociIndex
+	if err := readOCIFile(dir, ociIndexFileName, &index); err != nil {
+		return err
+	}
+
+	for _, desc := range index.Manifests {
+		if desc.MediaType != ociMediaTypeManifest && desc.MediaType != dockerMediaTypeManifest {
+			return fmt.Errorf("unsupported manifest media type %q for %s", desc.MediaType, desc.Digest)
+		}
+		var manifest ociManifest
+		if err := readOCIBlob(dir, desc.Digest, &manifest); err != nil {
+			return err
+		}
+		imgID, err := l.ociLoadImage(dir, manifest, progressOutput)
+		if err != nil {
+			return err
+		}
+
+		name := desc.Annotations[imageNameAnnotation]
+		if name == "" {
+			name = desc.Annotations[ociRefNameAnnotation]
+		}
+		if name != "" {
+			named, err := reference.ParseNamed(name)
+			if err != nil {
+				fmt.Fprintf(outStream, "Not tagging image %s with %q: %v\n", imgID, name, err)
+			} else if ref, ok := named.(reference.NamedTagged); !ok {
+				fmt.Fprintf(outStream, "Not tagging image %s with %q: no tag\n", imgID, name)
+			} else if err := l.setLoadedTag(ref, imgID, outStream); err != nil {
+				return err
+			}
+		}
+		l.loggerImgEvent.LogImageEvent(imgID.String(), imgID.String(), "load")
+	}
+	return nil
+}
+
+func (l *tarexporter) ociLoadImage(dir string, manifest ociManifest, progressOutput progress.Output) (image.ID, error) {
+	configPath, err := ociBlobPath(dir, manifest.Config.Digest)
+	if err != nil {
+		return "", err
+	}
+	config, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return "", err
+	}
+	if actual := digest.FromBytes(config); actual != manifest.Config.Digest {
+		return "", fmt.Errorf("invalid config digest: expected %q, got %q", manifest.Config.Digest, actual)
+	}
+	img, err := image.NewFromJSON(config)
+	if err != nil {
+		return "", err
+	}
+	if expected, actual := len(manifest.Layers), len(img.RootFS.DiffIDs); expected != actual {
+		return "", fmt.Errorf("invalid manifest, layers length mismatch: expected %d, got %d", expected, actual)
+	}
+
+	rootFS := *img.RootFS
+	rootFS.DiffIDs = nil
+	for i, diffID := range img.RootFS.DiffIDs {
+		switch manifest.Layers[i].MediaType {
+		case ociMediaTypeLayer, ociMediaTypeLayerGzip, ociMediaTypeLayerZstd, dockerMediaTypeLayerGzip:
+		default:
+			return "", fmt.Errorf("unsupported layer media type %q for %s", manifest.Layers[i].MediaType, manifest.Layers[i].Digest)
+		}
+		r := rootFS
+		r.Append(diffID)
+		newLayer, err := l.ls.Get(r.ChainID())
+		if err != nil {
+			layerPath, err := ociBlobPath(dir, manifest.Layers[i].Digest)
+			if err != nil {
+				return "", err
+			}
+			newLayer, err = l.loadLayer(layerPath, rootFS, diffID.String(), progressOutput)
+			if err != nil {
+				return "", err
+			}
+		}
+		defer layer.ReleaseAndLog(l.ls, newLayer)
+		if expected, actual := diffID, newLayer.DiffID(); expected != actual {
+			return "", fmt.Errorf("invalid diffID for layer %d: expected %q, got %q", i, expected, actual)
+		}
+		rootFS.Append(diffID)
+	}
+
+	return l.is.Create(config)
+}
+
+// ociBlobPath returns the path of the blob with the given digest in the
+// layout rooted at dir.
+func ociBlobPath(dir string, dgst digest.Digest) (string, error) {
+	if err := dgst.Validate(); err != nil {
+		return "", err
+	}
+	return safePath(dir, filepath.Join(ociBlobsDirName, dgst.Algorithm().String(), dgst.Hex()))
+}
+
+func readOCIBlob(dir string, dgst digest.Digest, v interface{}) error {
+	blobPath, err := ociBlobPath(dir, dgst)
+	if err != nil {
+		return err
+	}
+	content, err := ioutil.ReadFile(blobPath)
+	if err != nil {
+		return err
+	}
+	if actual := digest.FromBytes(content); actual != dgst {
+		return fmt.Errorf("invalid blob digest: expected %q, got %q", dgst, actual)
+	}
+	return json.Unmarshal(content, v)
+}
+
+func readOCIFile(dir, name string, v interface{}) error {
+	path, err := safePath(dir, name)
+	if err != nil {
+		return err
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(content, v)
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/docker/docker/pkg/integration/checker"
+	"github.com/docker/engine-api/types"
 	"github.com/go-check/check"
 	"golang.org/x/net/websocket"
 )
@@ -65,6 +66,49 @@ func (s *DockerSuite) TestGetContainersAttachWebsocket(c *check.C) {
 	c.Assert(actual, checker.DeepEquals, expected, check.Commentf("Websocket didn't return the expected data"))
 }
 
+func (s *DockerSuite) TestGetContainersAttachWebsocketFramed(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	out, _ := dockerCmd(c, "run", "-dit", "busybox", "cat")
+
+	rwc, err := sockConn(time.Duration(10 * time.Second))
+	c.Assert(err, checker.IsNil)
+
+	config, err := websocket.NewConfig(
+		"/containers/"+strings.TrimSpace(out)+"/attach/ws?stream=1&stdin=1&stdout=1&stderr=1",
+		"http://localhost",
+	)
+	c.Assert(err, checker.IsNil)
+	config.Protocol = []string{"docker.attach.v2"}
+
+	ws, err := websocket.NewClient(config, rwc)
+	c.Assert(err, checker.IsNil)
+	defer ws.Close()
+
+	c.Assert(websocket.Message.Send(ws, []byte("\x04"+`{"Type":"resize","Height":40,"Width":100}`)), checker.IsNil)
+	c.Assert(websocket.Message.Send(ws, []byte("\x00hello")), checker.IsNil)
+
+	outChan := make(chan []byte)
+	go func() {
+		var frame []byte
+		for websocket.Message.Receive(ws, &frame) == nil {
+			outChan <- frame
+		}
+		close(outChan)
+	}()
+
+	var stdout []byte
+	for !strings.Contains(string(stdout), "hello") {
+		select {
+		case frame, ok := <-outChan:
+			c.Assert(ok, checker.True, check.Commentf("Websocket closed before returning the expected data"))
+			c.Assert(frame[0], checker.Equals, byte(1), check.Commentf("Unexpected frame %q", frame))
+			stdout = append(stdout, frame[1:]...)
+		case <-time.After(5 * time.Second):
+			c.Fatal("Timeout reading from ws")
+		}
+	}
+}
+
 // regression gh14320
 func (s *DockerSuite) TestPostContainersAttachContainerNotFound(c *check.C) {
 	req, client, err := newRequestClient("POST", "/containers/doesnotexist/attach", nil, "")
@@ -77,16 +121,16 @@ func (s *DockerSuite) TestPostContainersAttachContainerNotFound(c *check.C) {
 	body, err := readBody(resp.Body)
 	c.Assert(err, checker.IsNil)
 	c.Assert(resp.StatusCode, checker.Equals, http.StatusNotFound)
-	expected := "No such container: doesnotexist\r\n"
-	c.Assert(string(body), checker.Equals, expected)
+	errResp := getErrorResponse(c, body)
+	c.Assert(errResp.Message, checker.Equals, "No such container: doesnotexist")
+	c.Assert(errResp.Code, checker.Equals, types.ErrorCodeNotFound)
 }
 
 func (s *DockerSuite) TestGetContainersWsAttachContainerNotFound(c *check.C) {
 	status, body, err := sockRequest("GET", "/containers/doesnotexist/attach/ws", nil)
 	c.Assert(status, checker.Equals, http.StatusNotFound)
 	c.Assert(err, checker.IsNil)
-	expected := "No such container: doesnotexist\n"
-	c.Assert(string(body), checker.Contains, expected)
+	c.Assert(getErrorResponse(c, body).Message, checker.Equals, "No such container: doesnotexist")
 }
 
 func (s *DockerSuite) TestPostContainersAttach(c *check.C) {
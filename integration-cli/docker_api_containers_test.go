@@ -592,7 +592,7 @@ func (s *DockerSuite) TestContainerApiBadPort(c *check.C) {
 	status, b, err := sockRequest("POST", "/containers/create", config)
 	c.Assert(err, checker.IsNil)
 	c.Assert(status, checker.Equals, http.StatusInternalServerError)
-	c.Assert(strings.TrimSpace(string(b)), checker.Equals, `Invalid port specification: "aa80"`, check.Commentf("Incorrect error msg: %s", string(b)))
+	c.Assert(getErrorResponse(c, b).Message, checker.Equals, `Invalid port specification: "aa80"`, check.Commentf("Incorrect error msg: %s", string(b)))
 }
 
 func (s *DockerSuite) TestContainerApiCreate(c *check.C) {
@@ -1066,7 +1066,7 @@ func (s *DockerSuite) TestContainerApiCopyResourcePathEmpty(c *check.C) {
 	status, body, err := sockRequest("POST", "/containers/"+name+"/copy", postData)
 	c.Assert(err, checker.IsNil)
 	c.Assert(status, checker.Equals, http.StatusInternalServerError)
-	c.Assert(string(body), checker.Matches, "Path cannot be empty\n")
+	c.Assert(getErrorResponse(c, body).Message, checker.Equals, "Path cannot be empty")
 }
 
 func (s *DockerSuite) TestContainerApiCopyResourcePathNotFound(c *check.C) {
@@ -1082,7 +1082,7 @@ func (s *DockerSuite) TestContainerApiCopyResourcePathNotFound(c *check.C) {
 	status, body, err := sockRequest("POST", "/containers/"+name+"/copy", postData)
 	c.Assert(err, checker.IsNil)
 	c.Assert(status, checker.Equals, http.StatusInternalServerError)
-	c.Assert(string(body), checker.Matches, "Could not find the file /notexist in container "+name+"\n")
+	c.Assert(getErrorResponse(c, body).Message, checker.Equals, "Could not find the file /notexist in container "+name)
 }
 
 func (s *DockerSuite) TestContainerApiCopyContainerNotFound(c *check.C) {
@@ -1108,11 +1108,136 @@ func (s *DockerSuite) TestContainerApiDelete(c *check.C) {
 	c.Assert(status, checker.Equals, http.StatusNoContent)
 }
 
+func (s *DockerSuite) TestContainerApiPrune(c *check.C) {
+	dockerCmd(c, "create", "--name", "prune-labelled", "--label", "prune=yes", "busybox")
+	dockerCmd(c, "create", "--name", "prune-unlabelled", "busybox")
+	runSleepingContainer(c, "--name", "prune-running", "--label", "prune=yes")
+
+	filterJSON := url.QueryEscape(`{"label":{"prune=yes":true}}`)
+	res, body, err := sockRequestRaw("POST", "/containers/prune?filters="+filterJSON, nil, "")
+	c.Assert(err, checker.IsNil)
+	c.Assert(res.StatusCode, checker.Equals, http.StatusOK)
+	b, err := readBody(body)
+	c.Assert(err, checker.IsNil)
+	c.Assert(string(b), checker.Contains, "Deleted")
+
+	out, _ := dockerCmd(c, "ps", "-a", "--format", "{{.Names}}")
+	c.Assert(out, checker.Not(checker.Contains), "prune-labelled")
+	c.Assert(out, checker.Contains, "prune-unlabelled")
+	c.Assert(out, checker.Contains, "prune-running")
+
+	status, _, err := sockRequest("POST", "/containers/prune?filters="+url.QueryEscape(`{"name":{"foo":true}}`), nil)
+	c.Assert(err, checker.IsNil)
+	c.Assert(status, checker.Equals, http.StatusInternalServerError)
+}
+
+func (s *DockerSuite) TestContainerApiBatchKill(c *check.C) {
+	runSleepingContainer(c, "--name", "batch1", "--label", "batch=yes")
+	runSleepingContainer(c, "--name", "batch2", "--label", "batch=yes")
+	runSleepingContainer(c, "--name", "batch-other")
+
+	filterJSON := url.QueryEscape(`{"label":{"batch=yes":true}}`)
+	status, body, err := sockRequest("POST", "/containers/kill?parallelism=1&filters="+filterJSON, nil)
+	c.Assert(err, checker.IsNil)
+	c.Assert(status, checker.Equals, http.StatusOK)
+
+	var report types.ContainersBatchReport
+	c.Assert(json.Unmarshal(body, &report), checker.IsNil)
+	c.Assert(report.Results, checker.HasLen, 2)
+	for _, result := range report.Results {
+		c.Assert(result.Error, checker.Equals, "")
+		c.Assert(inspectField(c, result.Name, "State.Running"), checker.Equals, "false")
+	}
+	c.Assert(inspectField(c, "batch-other", "State.Running"), checker.Equals, "true")
+
+	status, _, err = sockRequest("POST", "/containers/kill?parallelism=1000", nil)
+	c.Assert(err, checker.IsNil)
+	c.Assert(status, checker.Equals, http.StatusBadRequest)
+}
+
+func (s *DockerSuite) TestContainerApiRefresh(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	dockerCmd(c, "tag", "busybox", "refresh:latest")
+	dockerCmd(c, "network", "create", "--subnet", "172.28.0.0/16", "refreshnet")
+	out, _ := dockerCmd(c, "run", "-d", "--name", "refresh", "--net", "refreshnet", "-v", "/data", "refresh:latest", "top")
+	oldID := strings.TrimSpace(out)
+	c.Assert(waitRun(oldID), checker.IsNil)
+	dockerCmd(c, "exec", "refresh", "sh", "-c", "echo hello > /data/file")
+	oldIP := inspectField(c, "refresh", "NetworkSettings.Networks.refreshnet.IPAddress")
+
+	_, err := buildImage("refresh:latest", "FROM busybox\nLABEL refreshed=true", true)
+	c.Assert(err, checker.IsNil)
+
+	status, body, err := sockRequest("POST", "/containers/refresh/refresh?t=1", nil)
+	c.Assert(err, checker.IsNil)
+	c.Assert(status, checker.Equals, http.StatusCreated, check.Commentf(string(body)))
+	var resp types.ContainerCreateResponse
+	c.Assert(json.Unmarshal(body, &resp), checker.IsNil)
+	c.Assert(resp.ID, checker.Not(checker.Equals), oldID)
+	c.Assert(waitRun(resp.ID), checker.IsNil)
+
+	c.Assert(inspectField(c, "refresh", "Id"), checker.Equals, resp.ID)
+	c.Assert(inspectField(c, "refresh", "Config.Labels.refreshed"), checker.Equals, "true")
+	c.Assert(inspectField(c, "refresh", "NetworkSettings.Networks.refreshnet.IPAddress"), checker.Equals, oldIP)
+	out, _ = dockerCmd(c, "exec", "refresh", "cat", "/data/file")
+	c.Assert(strings.TrimSpace(out), checker.Equals, "hello")
+
+	out, _ = dockerCmd(c, "ps", "-a", "-q", "--no-trunc")
+	c.Assert(out, checker.Not(checker.Contains), oldID)
+}
+
+func (s *DockerSuite) TestContainerApiClone(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	dockerCmd(c, "run", "--name", "clone-src", "-v", "/data", "busybox", "sh", "-c", "echo layer > /file && echo volume > /data/file")
+
+	status, body, err := sockRequest("POST", "/containers/clone-src/clone?name=clone-empty", nil)
+	c.Assert(err, checker.IsNil)
+	c.Assert(status, checker.Equals, http.StatusCreated, check.Commentf(string(body)))
+	_, _, err = dockerCmdWithError("cp", "clone-empty:/file", "-")
+	c.Assert(err, checker.NotNil)
+
+	status, body, err = sockRequest("POST", "/containers/clone-src/clone?name=clone-full&layer=1&volumes=1", nil)
+	c.Assert(err, checker.IsNil)
+	c.Assert(status, checker.Equals, http.StatusCreated, check.Commentf(string(body)))
+	var resp types.ContainerCreateResponse
+	c.Assert(json.Unmarshal(body, &resp), checker.IsNil)
+	c.Assert(inspectField(c, "clone-full", "State.Running"), checker.Equals, "false")
+	c.Assert(inspectField(c, "clone-full", "Id"), checker.Equals, resp.ID)
+
+	out, _ := dockerCmd(c, "cp", "clone-full:/file", "-")
+	c.Assert(out, checker.Contains, "layer")
+	out, _ = dockerCmd(c, "cp", "clone-full:/data/file", "-")
+	c.Assert(out, checker.Contains, "volume")
+
+	srcVolume, err := inspectMountSourceField("clone-src", "/data")
+	c.Assert(err, checker.IsNil)
+	cloneVolume, err := inspectMountSourceField("clone-full", "/data")
+	c.Assert(err, checker.IsNil)
+	c.Assert(cloneVolume, checker.Not(checker.Equals), srcVolume)
+}
+
+func (s *DockerSuite) TestContainerApiSnapshotNotSupported(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	runSleepingContainer(c, "--name", "snapshot-linux")
+
+	config := types.ContainerSnapshotConfig{Quiesce: []string{"touch", "/quiesced"}}
+	status, body, err := sockRequest("POST", "/containers/snapshot-linux/snapshots", config)
+	c.Assert(err, checker.IsNil)
+	c.Assert(status, checker.Equals, http.StatusNotImplemented)
+	c.Assert(string(body), checker.Contains, "only supported on Windows")
+
+	// the quiesce command doesn't run when snapshots aren't supported
+	_, _, err = dockerCmdWithError("exec", "snapshot-linux", "test", "-e", "/quiesced")
+	c.Assert(err, checker.NotNil)
+}
+
 func (s *DockerSuite) TestContainerApiDeleteNotExist(c *check.C) {
 	status, body, err := sockRequest("DELETE", "/containers/doesnotexist", nil)
 	c.Assert(err, checker.IsNil)
 	c.Assert(status, checker.Equals, http.StatusNotFound)
-	c.Assert(string(body), checker.Matches, "No such container: doesnotexist\n")
+	errResp := getErrorResponse(c, body)
+	c.Assert(errResp.Message, checker.Equals, "No such container: doesnotexist")
+	c.Assert(errResp.Code, checker.Equals, types.ErrorCodeNotFound)
 }
 
 func (s *DockerSuite) TestContainerApiDeleteForce(c *check.C) {
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/docker/docker/pkg/integration/checker"
+	"github.com/docker/engine-api/types"
 	"github.com/go-check/check"
 )
 
@@ -152,6 +153,27 @@ func (s *DockerSuite) TestExecApiStartWithDetach(c *check.C) {
 	}
 }
 
+func (s *DockerSuite) TestExecApiHistory(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	runSleepingContainer(c, "--name", "test")
+	dockerCmd(c, "exec", "test", "true")
+	dockerCmdWithError("exec", "test", "sh", "-c", "exit 3")
+
+	status, body, err := sockRequest("GET", "/containers/test/execs", nil)
+	c.Assert(err, checker.IsNil)
+	c.Assert(status, checker.Equals, http.StatusOK)
+
+	var history []types.ExecRecord
+	c.Assert(json.Unmarshal(body, &history), checker.IsNil)
+	c.Assert(history, checker.HasLen, 2)
+	c.Assert(history[0].Cmd, checker.DeepEquals, []string{"true"})
+	c.Assert(history[1].Cmd, checker.DeepEquals, []string{"sh", "-c", "exit 3"})
+	c.Assert(history[1].ExitCode, checker.NotNil)
+	c.Assert(*history[1].ExitCode, checker.Equals, 3)
+	c.Assert(history[1].Finished, checker.NotNil)
+	c.Assert(history[1].Finished.Before(history[1].Started), checker.False)
+}
+
 func createExec(c *check.C, name string) string {
 	_, b, err := sockRequest("POST", fmt.Sprintf("/containers/%s/exec", name), map[string]interface{}{"Cmd": []string{"true"}})
 	c.Assert(err, checker.IsNil, check.Commentf(string(b)))
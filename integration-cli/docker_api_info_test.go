@@ -1,7 +1,11 @@
 package main
 
 import (
+	"archive/tar"
+	"bytes"
+	"io"
 	"net/http"
+	"strings"
 
 	"github.com/docker/docker/pkg/integration/checker"
 	"github.com/go-check/check"
@@ -39,3 +43,24 @@ func (s *DockerSuite) TestInfoApi(c *check.C) {
 		c.Assert(out, checker.Contains, linePrefix)
 	}
 }
+
+func (s *DockerSuite) TestInfoApiBundle(c *check.C) {
+	status, body, err := sockRequest("GET", "/info/bundle", nil)
+	c.Assert(err, checker.IsNil)
+	c.Assert(status, checker.Equals, http.StatusOK)
+
+	var names []string
+	tr := tar.NewReader(bytes.NewReader(body))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		c.Assert(err, checker.IsNil)
+		names = append(names, hdr.Name)
+	}
+	files := strings.Join(names, "\n") + "\n"
+	for _, name := range []string{"goroutines.txt", "heap.pprof", "info.json", "version.json", "daemon.json", "engine.log"} {
+		c.Assert(files, checker.Contains, name+"\n")
+	}
+}
@@ -42,6 +42,31 @@ func (s *DockerSuite) TestApiNetworkCreateDelete(c *check.C) {
 	c.Assert(isNetworkAvailable(c, name), checker.Equals, false)
 }
 
+func (s *DockerSuite) TestApiNetworksPrune(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	createNetwork(c, types.NetworkCreateRequest{Name: "prune-unused"}, true)
+	createNetwork(c, types.NetworkCreateRequest{Name: "prune-used"}, true)
+	runSleepingContainer(c, "--net", "prune-used")
+
+	// A dry run reports the unused network without removing it
+	status, body, err := sockRequest("POST", "/networks/prune?dryrun=1", nil)
+	c.Assert(err, checker.IsNil)
+	c.Assert(status, checker.Equals, http.StatusOK)
+	var report types.NetworksPruneReport
+	c.Assert(json.Unmarshal(body, &report), checker.IsNil)
+	c.Assert(report.NetworksDeleted, checker.DeepEquals, []string{"prune-unused"})
+	c.Assert(isNetworkAvailable(c, "prune-unused"), checker.Equals, true)
+
+	status, body, err = sockRequest("POST", "/networks/prune", nil)
+	c.Assert(err, checker.IsNil)
+	c.Assert(status, checker.Equals, http.StatusOK)
+	c.Assert(json.Unmarshal(body, &report), checker.IsNil)
+	c.Assert(report.NetworksDeleted, checker.DeepEquals, []string{"prune-unused"})
+	c.Assert(isNetworkAvailable(c, "prune-unused"), checker.Equals, false)
+	c.Assert(isNetworkAvailable(c, "prune-used"), checker.Equals, true)
+	c.Assert(isNetworkAvailable(c, "bridge"), checker.Equals, true)
+}
+
 func (s *DockerSuite) TestApiNetworkCreateCheckDuplicate(c *check.C) {
 	testRequires(c, DaemonIsLinux)
 	name := "testcheckduplicate"
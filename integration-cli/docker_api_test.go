@@ -60,7 +60,7 @@ func (s *DockerSuite) TestApiClientVersionNewerThanServer(c *check.C) {
 	c.Assert(err, checker.IsNil)
 	c.Assert(status, checker.Equals, http.StatusBadRequest)
 	expected := fmt.Sprintf("client is newer than server (client API version: %s, server API version: %s)", version, api.DefaultVersion)
-	c.Assert(strings.TrimSpace(string(body)), checker.Equals, expected)
+	c.Assert(getErrorResponse(c, body).Message, checker.Equals, expected)
 }
 
 func (s *DockerSuite) TestApiClientVersionOldNotSupported(c *check.C) {
@@ -99,3 +99,26 @@ func (s *DockerSuite) TestApiDockerApiVersion(c *check.C) {
 		c.Fatalf("Out didn't have 'xxx' for the API version, had:\n%s", out)
 	}
 }
+
+func (s *DockerSuite) TestApiRequestID(c *check.C) {
+	conn, err := sockConn(time.Duration(10 * time.Second))
+	c.Assert(err, checker.IsNil)
+
+	client := httputil.NewClientConn(conn, nil)
+	defer client.Close()
+
+	req, err := http.NewRequest("GET", "/version", nil)
+	c.Assert(err, checker.IsNil)
+	res, err := client.Do(req)
+	c.Assert(err, checker.IsNil)
+	res.Body.Close()
+	c.Assert(res.Header.Get("X-Request-Id"), checker.HasLen, 32)
+
+	req, err = http.NewRequest("GET", "/version", nil)
+	c.Assert(err, checker.IsNil)
+	req.Header.Set("X-Request-Id", "run-42")
+	res, err = client.Do(req)
+	c.Assert(err, checker.IsNil)
+	res.Body.Close()
+	c.Assert(res.Header.Get("X-Request-Id"), checker.Equals, "run-42")
+}
@@ -6981,3 +6981,114 @@ func (s *DockerSuite) TestBuildLabelsOverride(c *check.C) {
 	}
 
 }
+
+func (s *DockerSuite) TestBuildOutputLocal(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	dir, err := ioutil.TempDir("", "build-output")
+	c.Assert(err, check.IsNil)
+	defer os.RemoveAll(dir)
+
+	buildCmd := exec.Command(dockerBinary, "build", "--output", "type=local,dest="+dir, "-")
+	buildCmd.Stdin = strings.NewReader(`FROM busybox
+		RUN echo -n built > /result`)
+	out, _, err := runCommandWithOutput(buildCmd)
+	c.Assert(err, check.IsNil, check.Commentf(out))
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, "result"))
+	c.Assert(err, check.IsNil)
+	c.Assert(string(content), check.Equals, "built")
+
+	// The image built is not kept without a tag.
+	imageID := regexp.MustCompile("Successfully built ([0-9a-f]+)").FindStringSubmatch(out)
+	c.Assert(imageID, checker.HasLen, 2, check.Commentf(out))
+	_, _, err = dockerCmdWithError("inspect", imageID[1])
+	c.Assert(err, check.NotNil)
+}
+
+func (s *DockerSuite) TestBuildRunMount(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	dir, err := ioutil.TempDir("", "build-run-mount")
+	c.Assert(err, check.IsNil)
+	defer os.RemoveAll(dir)
+	secret := filepath.Join(dir, "secret")
+	c.Assert(ioutil.WriteFile(secret, []byte("s3cr3t"), 0600), check.IsNil)
+
+	// The content of a cache mount persists across builds, but neither the
+	// cache nor the secret is committed to the image.
+	dockerfile := `FROM busybox
+		RUN --mount=type=cache,target=/cache,id=testbuildrunmount --mount=type=secret,id=pass cat /run/secrets/pass >> /cache/log && cat /cache/log`
+	for _, expected := range []string{"s3cr3t", "s3cr3ts3cr3t"} {
+		buildCmd := exec.Command(dockerBinary, "build", "--no-cache", "-t", "testbuildrunmount", "--secret", "id=pass,src="+secret, "-")
+		buildCmd.Stdin = strings.NewReader(dockerfile)
+		out, _, err := runCommandWithOutput(buildCmd)
+		c.Assert(err, check.IsNil, check.Commentf(out))
+		c.Assert(out, checker.Contains, expected)
+	}
+	out, _, err := dockerCmdWithError("run", "--rm", "testbuildrunmount", "cat", "/cache/log", "/run/secrets/pass")
+	c.Assert(err, check.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Not(checker.Contains), "s3cr3t")
+
+	// A secret that is not passed fails the build.
+	buildCmd := exec.Command(dockerBinary, "build", "-")
+	buildCmd.Stdin = strings.NewReader(dockerfile)
+	out, _, err = runCommandWithOutput(buildCmd)
+	c.Assert(err, check.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "secret pass was not provided")
+}
+
+func (s *DockerSuite) TestBuildCheck(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	dockerfile := `FROM busybox
+		MAINTAINER someone
+		CMD top`
+
+	buildCmd := exec.Command(dockerBinary, "build", "--check", "-t", "testbuildcheck", "-")
+	buildCmd.Stdin = strings.NewReader(dockerfile)
+	out, _, err := runCommandWithOutput(buildCmd)
+	c.Assert(err, check.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "[WARNING] FromUnpinned (line 1)")
+	c.Assert(out, checker.Contains, "[WARNING] MaintainerDeprecated (line 2)")
+	c.Assert(out, checker.Contains, "[WARNING] ShellFormSignals (line 3)")
+	c.Assert(out, checker.Contains, "Check complete, 3 warning(s) found")
+
+	// Nothing is built in check mode.
+	_, _, err = dockerCmdWithError("inspect", "testbuildcheck")
+	c.Assert(err, check.NotNil)
+
+	// A build reports the same warnings and succeeds.
+	buildCmd = exec.Command(dockerBinary, "build", "-t", "testbuildcheck", "-")
+	buildCmd.Stdin = strings.NewReader(dockerfile)
+	out, _, err = runCommandWithOutput(buildCmd)
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "[WARNING] FromUnpinned (line 1)")
+
+	buildCmd = exec.Command(dockerBinary, "build", "--check", "-")
+	buildCmd.Stdin = strings.NewReader(`FROM busybox:1.24
+		CMD ["top"]`)
+	out, _, err = runCommandWithOutput(buildCmd)
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "Check complete, 0 warning(s) found")
+}
+
+func (s *DockerSuite) TestBuilderPrune(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	name := "testbuilderprune"
+	id, err := buildImage(name, `FROM busybox
+		RUN echo cached > /file
+		LABEL prune=true`, true)
+	c.Assert(err, check.IsNil)
+
+	// The build cache of a tagged image is kept.
+	out, _ := dockerCmd(c, "builder", "prune", "--force")
+	c.Assert(out, checker.Not(checker.Contains), id)
+
+	// Once untagged, the image of the RUN instruction is unused.
+	parent := inspectField(c, name, "Parent")
+	dockerCmd(c, "rmi", "--no-prune", name)
+	out, _ = dockerCmd(c, "builder", "prune", "--force", "--filter", "until=1h")
+	c.Assert(out, checker.Not(checker.Contains), parent)
+
+	out, _ = dockerCmd(c, "builder", "prune", "--force")
+	c.Assert(out, checker.Contains, "Deleted: "+parent)
+	c.Assert(out, checker.Contains, "Total reclaimed space:")
+}
@@ -156,6 +156,18 @@ func (s *DockerRegistrySuite) TestRunByDigest(c *check.C) {
 	c.Assert(res, checker.Equals, imageReference)
 }
 
+func (s *DockerRegistrySuite) TestRunPullAlways(c *check.C) {
+	_, err := setupImage(c)
+	c.Assert(err, checker.IsNil)
+
+	// a stale local image with the same name is replaced by the pull
+	dockerCmd(c, "tag", "busybox", repoName)
+
+	out, _ := dockerCmd(c, "run", "--rm", "--pull=always", repoName, "sh", "-c", "echo found=$digest")
+	c.Assert(out, checker.Contains, "found=1")
+	c.Assert(out, checker.Not(checker.Contains), "Unable to find image")
+}
+
 func (s *DockerRegistrySuite) TestRemoveImageByDigest(c *check.C) {
 	digest, err := setupImage(c)
 	c.Assert(err, checker.IsNil, check.Commentf("error setting up image"))
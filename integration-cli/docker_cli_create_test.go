@@ -478,3 +478,37 @@ func (s *DockerSuite) TestCreate64ByteHexID(c *check.C) {
 
 	dockerCmd(c, "create", imageID)
 }
+
+func (s *DockerSuite) TestCreateDryRun(c *check.C) {
+	name := "test-create-dry-run"
+	out, _ := dockerCmd(c, "create", "--dry-run", "--name", name, "busybox", "true")
+	c.Assert(strings.TrimSpace(out), checker.Equals, "")
+
+	out, _ = dockerCmd(c, "ps", "-a")
+	c.Assert(out, checker.Not(checker.Contains), name)
+
+	out, _, err := dockerCmdWithError("create", "--dry-run", "--net", "network-does-not-exist", "busybox", "true")
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "network-does-not-exist")
+
+	dockerCmd(c, "create", "--name", name, "busybox", "true")
+	out, _, err = dockerCmdWithError("create", "--dry-run", "--name", name, "busybox", "true")
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "is already in use")
+}
+
+func (s *DockerSuite) TestCreateFailedRollsBack(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	name := "test-create-rollback"
+	out, _, err := dockerCmdWithError("create", "--name", name, "-v", "test-create-rollback-1:/data", "-v", "test-create-rollback-2:/data", "busybox", "true")
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "Duplicate mount point")
+
+	// The volume referenced before the failure is not left in use
+	dockerCmd(c, "volume", "rm", "test-create-rollback-1")
+
+	// The name is released, and no container is left behind
+	out, _ = dockerCmd(c, "ps", "-a")
+	c.Assert(out, checker.Not(checker.Contains), name)
+	dockerCmd(c, "create", "--name", name, "busybox", "true")
+}
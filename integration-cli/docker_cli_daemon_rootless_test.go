@@ -0,0 +1,74 @@
+// +build !windows
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/docker/docker/pkg/integration/checker"
+	"github.com/go-check/check"
+)
+
+// rootless mode test: run the daemon as an unprivileged user, and run a
+// container using the default devices and a device of the host
+func (s *DockerDaemonSuite) TestDaemonRootlessRunContainer(c *check.C) {
+	testRequires(c, SameHostDaemon, UserNamespaceInKernel, NotUserNamespace, rootlessUser)
+	user := os.Getenv("DOCKER_ROOTLESS_USER")
+
+	binary, err := exec.LookPath(dockerBinary)
+	c.Assert(err, checker.IsNil)
+
+	runtimeDir := filepath.Join(s.d.folder, "run")
+	dataDir := filepath.Join(s.d.folder, "data")
+	for _, dir := range []string{runtimeDir, dataDir} {
+		c.Assert(os.MkdirAll(dir, 0700), checker.IsNil)
+	}
+	bb := filepath.Join(s.d.folder, "busybox.tar")
+	dockerCmd(c, "save", "--output", bb, "busybox:latest")
+	out, _, err := runCommandWithOutput(exec.Command("chown", "-R", user, s.d.folder))
+	c.Assert(err, checker.IsNil, check.Commentf("Output: %s", out))
+
+	logFile, err := os.Create(filepath.Join(s.d.folder, "docker.log"))
+	c.Assert(err, checker.IsNil)
+	defer logFile.Close()
+
+	daemon := exec.Command("sudo", "-u", user, "env",
+		"XDG_RUNTIME_DIR="+runtimeDir, "XDG_DATA_HOME="+dataDir, "PATH="+os.Getenv("PATH"),
+		binary, "daemon", "--rootless", "--rootless-net-helper=", "--debug")
+	daemon.Stdout, daemon.Stderr = logFile, logFile
+	c.Assert(daemon.Start(), checker.IsNil)
+	defer func() {
+		daemon.Process.Signal(syscall.SIGTERM)
+		daemon.Wait()
+	}()
+
+	host := "unix://" + filepath.Join(runtimeDir, "docker", "docker.sock")
+	cli := func(args ...string) (string, error) {
+		out, _, err := runCommandWithOutput(exec.Command("sudo", append([]string{"-u", user, binary, "-H", host}, args...)...))
+		return out, err
+	}
+	started := false
+	for i := 0; i < 60; i++ {
+		if _, err := cli("version"); err == nil {
+			started = true
+			break
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	c.Assert(started, checker.True, check.Commentf("The rootless daemon did not start, see %s", logFile.Name()))
+
+	out, err = cli("load", "--input", bb)
+	c.Assert(err, checker.IsNil, check.Commentf("Output: %s", out))
+
+	// the devices are bind mounted from the host, not created in the user
+	// namespace of the daemon
+	out, err = cli("run", "--rm", "--net=none", "--device", "/dev/zero:/dev/myzero", "busybox",
+		"sh", "-c", "stat -c %t,%T /dev/null && head -c 4 /dev/urandom | wc -c && head -c 3 /dev/myzero | wc -c")
+	c.Assert(err, checker.IsNil, check.Commentf("Output: %s", out))
+	c.Assert(strings.Fields(out), checker.DeepEquals, []string{"1,3", "4", "3"})
+}
@@ -9,11 +9,14 @@ import (
 	"io"
 	"io/ioutil"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"strconv"
 	"strings"
 	"sync"
@@ -22,6 +25,7 @@ import (
 
 	"github.com/docker/docker/pkg/integration/checker"
 	"github.com/docker/docker/pkg/mount"
+	eventtypes "github.com/docker/engine-api/types/events"
 	"github.com/docker/go-units"
 	"github.com/docker/libnetwork/iptables"
 	"github.com/docker/libtrust"
@@ -2349,3 +2353,263 @@ func (s *DockerDaemonSuite) TestDaemonMaxConcurrencyWithConfigFileReload(c *chec
 	c.Assert(string(content), checker.Contains, expectedMaxConcurrentUploads)
 	c.Assert(string(content), checker.Contains, expectedMaxConcurrentDownloads)
 }
+
+func (s *DockerDaemonSuite) TestDaemonRequireDigestPins(c *check.C) {
+	err := s.d.StartWithBusybox("--require-digest-pins", "--digest-pin-exemption=busybox")
+	c.Assert(err, check.IsNil)
+
+	out, err := s.d.Cmd("create", "busybox", "true")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+
+	out, err = s.d.Cmd("tag", "busybox", "pinned:v1")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	out, err = s.d.Cmd("create", "pinned:v1", "true")
+	c.Assert(err, check.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "is not pinned by digest")
+
+	id, err := s.d.Cmd("inspect", "--format={{.Id}}", "pinned:v1")
+	c.Assert(err, check.IsNil, check.Commentf(id))
+	out, err = s.d.Cmd("create", strings.TrimSpace(id), "true")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+}
+
+func (s *DockerDaemonSuite) TestDaemonRegistryAccessLists(c *check.C) {
+	err := s.d.StartWithBusybox("--allowed-registry=docker.io/library", "--blocked-registry=docker.io/library/ubuntu")
+	c.Assert(err, check.IsNil)
+
+	out, err := s.d.Cmd("pull", "ubuntu")
+	c.Assert(err, check.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "is a blocked registry")
+
+	out, err = s.d.Cmd("pull", "127.0.0.1:5000/busybox")
+	c.Assert(err, check.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "is not in an allowed registry")
+
+	out, err = s.d.Cmd("tag", "busybox", "127.0.0.1:5000/busybox")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	out, err = s.d.Cmd("push", "127.0.0.1:5000/busybox")
+	c.Assert(err, check.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "is not in an allowed registry")
+
+	dockerfile, err := ioutil.TempDir("", "registry-access")
+	c.Assert(err, check.IsNil)
+	defer os.RemoveAll(dockerfile)
+	err = ioutil.WriteFile(filepath.Join(dockerfile, "Dockerfile"), []byte("FROM 127.0.0.1:5000/busybox\n"), 0644)
+	c.Assert(err, check.IsNil)
+	out, err = s.d.Cmd("build", dockerfile)
+	c.Assert(err, check.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "is not in an allowed registry")
+}
+
+func (s *DockerDaemonSuite) TestDaemonProxyConfiguration(c *check.C) {
+	c.Assert(s.d.Start("--http-proxy=http://proxy.example.com:3128", "--https-proxy=http://proxy.example.com:3129", "--no-proxy=.example.com"), check.IsNil)
+
+	out, err := s.d.Cmd("info")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "Http Proxy: http://proxy.example.com:3128")
+	c.Assert(out, checker.Contains, "Https Proxy: http://proxy.example.com:3129")
+	c.Assert(out, checker.Contains, "No Proxy: .example.com")
+}
+
+func (s *DockerDaemonSuite) TestDaemonTransferRateLimits(c *check.C) {
+	c.Assert(s.d.Start("--max-download-rate=50MB", "--max-upload-rate-per-layer=1MB"), check.IsNil)
+
+	out, err := s.d.Cmd("info")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "Download Throttle:\n Rate: 50 MB/s\n Per Layer Rate: unlimited\n")
+	c.Assert(out, checker.Contains, "Upload Throttle:\n Rate: unlimited\n Per Layer Rate: 1 MB/s\n")
+}
+
+func (s *DockerDaemonSuite) TestDaemonLayerPeers(c *check.C) {
+	c.Assert(s.d.Start("--layer-peer=ftp://10.0.0.2"), check.NotNil, check.Commentf("Daemon shouldn't start with an invalid layer peer"))
+
+	c.Assert(s.d.Start("--layer-peer-listen=127.0.0.1:5080", "--layer-peer=http://127.0.0.1:5081"), check.IsNil)
+
+	resp, err := http.Get("http://127.0.0.1:5080/blobs/sha256:" + strings.Repeat("0", 64))
+	c.Assert(err, check.IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, check.Equals, http.StatusNotFound)
+}
+
+func (s *DockerDaemonSuite) TestDaemonRejectOvercommit(c *check.C) {
+	testRequires(c, cpuCfsQuota)
+	err := s.d.StartWithBusybox("--reject-overcommit")
+	c.Assert(err, check.IsNil)
+
+	// More CPUs than the host has
+	quota := strconv.Itoa((runtime.NumCPU() + 1) * 100000)
+	out, err := s.d.Cmd("create", "--cpu-quota", quota, "busybox", "true")
+	c.Assert(err, check.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "cannot reserve")
+
+	out, err = s.d.Cmd("create", "--cpu-quota", "100000", "busybox", "true")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+
+	out, err = s.d.Cmd("info")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "Reserved CPUs: 0.00")
+}
+
+func (s *DockerDaemonSuite) TestDaemonEventWebhook(c *check.C) {
+	received := make(chan eventtypes.Message, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ev eventtypes.Message
+		if err := json.NewDecoder(r.Body).Decode(&ev); err == nil {
+			received <- ev
+		}
+	}))
+	defer srv.Close()
+
+	err := s.d.StartWithBusybox("--event-webhook", srv.URL, "--event-webhook-filter", "event=die")
+	c.Assert(err, check.IsNil)
+
+	out, err := s.d.Cmd("run", "--name", "webhook", "busybox", "sh", "-c", "exit 2")
+	c.Assert(err, check.NotNil, check.Commentf(out))
+
+	select {
+	case ev := <-received:
+		c.Assert(ev.Action, checker.Equals, "die")
+		c.Assert(ev.Actor.Attributes["name"], checker.Equals, "webhook")
+		c.Assert(ev.Actor.Attributes["exitCode"], checker.Equals, "2")
+	case <-time.After(10 * time.Second):
+		c.Fatal("timed out waiting for the webhook")
+	}
+}
+
+func (s *DockerDaemonSuite) TestDaemonLifecycleHooks(c *check.C) {
+	dir, err := ioutil.TempDir("", "lifecycle-hooks")
+	c.Assert(err, check.IsNil)
+	defer os.RemoveAll(dir)
+
+	out := filepath.Join(dir, "out")
+	hook := filepath.Join(dir, "hook")
+	err = ioutil.WriteFile(hook, []byte("#!/bin/sh\necho $DOCKER_HOOK_EVENT $DOCKER_CONTAINER_NAME $DOCKER_CONTAINER_EXIT_CODE >> "+out+"\n"), 0755)
+	c.Assert(err, check.IsNil)
+	failing := filepath.Join(dir, "failing")
+	err = ioutil.WriteFile(failing, []byte("#!/bin/sh\necho hook refused\nexit 1\n"), 0755)
+	c.Assert(err, check.IsNil)
+
+	err = s.d.StartWithBusybox("--lifecycle-hook", "create="+hook, "--lifecycle-hook", "die="+hook, "--lifecycle-hook", "destroy="+hook)
+	c.Assert(err, check.IsNil)
+
+	cmdOut, err := s.d.Cmd("run", "--name", "hooked", "busybox", "sh", "-c", "exit 4")
+	c.Assert(err, check.NotNil, check.Commentf(cmdOut))
+	cmdOut, err = s.d.Cmd("rm", "hooked")
+	c.Assert(err, check.IsNil, check.Commentf(cmdOut))
+
+	// The die and destroy hooks run in the background.
+	expected := "create hooked\ndie hooked 4\ndestroy hooked\n"
+	var b []byte
+	for i := 0; i < 50; i++ {
+		if b, _ = ioutil.ReadFile(out); string(b) == expected {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	c.Assert(string(b), checker.Equals, expected)
+
+	c.Assert(s.d.Restart("--lifecycle-hook", "create="+failing, "--lifecycle-hook-failure", "abort"), check.IsNil)
+	cmdOut, err = s.d.Cmd("create", "--name", "refused", "busybox", "true")
+	c.Assert(err, check.NotNil, check.Commentf(cmdOut))
+	c.Assert(cmdOut, checker.Contains, "hook refused")
+	cmdOut, err = s.d.Cmd("inspect", "refused")
+	c.Assert(err, check.NotNil, check.Commentf(cmdOut))
+}
+
+func (s *DockerDaemonSuite) TestDaemonOCIHooks(c *check.C) {
+	testRequires(c, SameHostDaemon)
+	dir, err := ioutil.TempDir("", "oci-hooks")
+	c.Assert(err, check.IsNil)
+	defer os.RemoveAll(dir)
+
+	out := filepath.Join(dir, "out")
+	hook := filepath.Join(dir, "hook")
+	err = ioutil.WriteFile(hook, []byte("#!/bin/sh\necho $1 >> "+out+"\n"), 0755)
+	c.Assert(err, check.IsNil)
+
+	err = s.d.StartWithBusybox()
+	c.Assert(err, check.IsNil)
+	cmdOut, err := s.d.Cmd("run", "--oci-hook", "poststart="+hook+" started", "busybox", "true")
+	c.Assert(err, check.NotNil, check.Commentf(cmdOut))
+	c.Assert(cmdOut, checker.Contains, "the daemon has no OCI hooks directory")
+
+	c.Assert(s.d.Restart("--oci-hooks-dir", dir), check.IsNil)
+	cmdOut, err = s.d.Cmd("run", "--oci-hook", "poststart="+hook+" started", "--oci-hook", "poststop="+hook+" stopped", "busybox", "true")
+	c.Assert(err, check.IsNil, check.Commentf(cmdOut))
+	// The poststop hook may run after the client returns.
+	var b []byte
+	for i := 0; i < 50; i++ {
+		if b, _ = ioutil.ReadFile(out); string(b) == "started\nstopped\n" {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	c.Assert(string(b), checker.Equals, "started\nstopped\n")
+
+	cmdOut, err = s.d.Cmd("run", "--oci-hook", "prestart=/bin/true", "busybox", "true")
+	c.Assert(err, check.NotNil, check.Commentf(cmdOut))
+	c.Assert(cmdOut, checker.Contains, "not in the OCI hooks directory")
+}
+
+func (s *DockerDaemonSuite) TestDaemonPublishedPortRange(c *check.C) {
+	testRequires(c, SameHostDaemon, DaemonIsLinux)
+	err := s.d.StartWithBusybox("--published-port-range=40000-40001")
+	c.Assert(err, check.IsNil)
+
+	for i := 0; i < 2; i++ {
+		out, err := s.d.Cmd("run", "-d", "-p", "80", "busybox", "top")
+		c.Assert(err, check.IsNil, check.Commentf(out))
+		out, err = s.d.Cmd("port", strings.TrimSpace(out), "80")
+		c.Assert(err, check.IsNil, check.Commentf(out))
+		c.Assert(strings.TrimSpace(out), checker.Matches, "0.0.0.0:4000[01]")
+	}
+	// The range is exhausted.
+	out, err := s.d.Cmd("run", "-d", "-p", "80", "busybox", "top")
+	c.Assert(err, check.NotNil, check.Commentf(out))
+
+	c.Assert(s.d.Stop(), check.IsNil)
+	c.Assert(s.d.Start("--published-port-range=40001-40000"), check.NotNil)
+}
+
+func (s *DockerDaemonSuite) TestDaemonAPIStreamIdleTimeout(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	err := s.d.StartWithBusybox("--api-stream-idle-timeout=2")
+	c.Assert(err, check.IsNil)
+
+	out, err := s.d.Cmd("run", "-d", "--name", "test", "busybox", "top")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+
+	// The exec prints nothing for longer than the timeout.
+	start := time.Now()
+	out, err = s.d.Cmd("exec", "test", "sleep", "30")
+	c.Assert(err, check.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "stream closed by the daemon after 2s without traffic")
+	c.Assert(time.Since(start) < 20*time.Second, checker.True)
+
+	// No event happens for longer than the timeout.
+	start = time.Now()
+	out, err = s.d.Cmd("events")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	c.Assert(time.Since(start) < 20*time.Second, checker.True)
+
+	c.Assert(s.d.Stop(), check.IsNil)
+	c.Assert(s.d.Start("--api-stream-idle-timeout=-1"), check.NotNil)
+}
+
+func (s *DockerDaemonSuite) TestDaemonAPILimit(c *check.C) {
+	err := s.d.Start("--api-limit=method=GET,path=/containers/json,rate=0.01,burst=1")
+	c.Assert(err, check.IsNil)
+
+	out, err := s.d.Cmd("ps")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	out, err = s.d.Cmd("ps")
+	c.Assert(err, check.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "too many requests to GET /containers/json")
+
+	// The other endpoints aren't limited.
+	out, err = s.d.Cmd("images")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+
+	c.Assert(s.d.Stop(), check.IsNil)
+	c.Assert(s.d.Start("--api-limit=path=/containers/json"), check.NotNil)
+}
@@ -715,3 +715,21 @@ func (s *DockerSuite) TestEventsUntilInThePast(c *check.C) {
 	c.Assert(out, checker.Not(checker.Contains), "test-container2")
 	c.Assert(out, checker.Contains, "test-container")
 }
+
+func (s *DockerSuite) TestEventsFormat(c *check.C) {
+	since := daemonUnixTime(c)
+	dockerCmd(c, "run", "--name", "events-format", "busybox", "sh", "-c", "exit 3")
+	imageID := inspectField(c, "events-format", "Image")
+	dockerCmd(c, "rm", "events-format")
+	until := daemonUnixTime(c)
+
+	out, _ := dockerCmd(c, "events", "--since", since, "--until", until, "--filter", "container=events-format", "--format", "{{.Action}} {{.Actor.Attributes.imageID}} {{.Actor.Attributes.exitCode}}")
+	c.Assert(out, checker.Contains, "die "+imageID+" 3\n")
+	c.Assert(out, checker.Contains, "destroy "+imageID+" 3\n")
+}
+
+func (s *DockerSuite) TestEventsFormatInvalidTemplate(c *check.C) {
+	out, _, err := dockerCmdWithError("events", "--format", "{{.Action")
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "Template parsing error")
+}
@@ -366,3 +366,21 @@ func (s *DockerSuite) TestEventsFilterNetworkID(c *check.C) {
 	c.Assert(events[0], checker.Contains, "test-event-network-local")
 	c.Assert(events[0], checker.Contains, "type=bridge")
 }
+
+func (s *DockerSuite) TestEventsSecurityAudit(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+
+	since := daemonUnixTime(c)
+
+	dockerCmd(c, "create", "--name", "audit-safe", "busybox", "true")
+	out, _ := dockerCmd(c, "create", "--name", "audit-risky", "--privileged", "--pid=host", "busybox", "true")
+	id := strings.TrimSpace(out)
+
+	out, _ = dockerCmd(c, "events", "--filter", "type=security", "--since", since, "--until", daemonUnixTime(c))
+	events := strings.Split(strings.TrimSpace(out), "\n")
+	c.Assert(events, checker.HasLen, 1, check.Commentf("%s", out))
+
+	c.Assert(events[0], checker.Contains, "security create "+id)
+	c.Assert(events[0], checker.Contains, "name=audit-risky")
+	c.Assert(events[0], checker.Contains, "options=privileged,pid=host")
+}
@@ -68,3 +68,18 @@ func (s *DockerSuite) TestExecTTY(c *check.C) {
 	c.Assert(err, checker.IsNil)
 	c.Assert(bytes.Contains(buf, []byte("hello")), checker.Equals, true, check.Commentf(string(buf[:read])))
 }
+
+func (s *DockerSuite) TestExecResourceLimits(c *check.C) {
+	testRequires(c, DaemonIsLinux, memoryLimitSupport, cpuCfsQuota)
+	runSleepingContainer(c, "--name=limited", "-m", "128m")
+
+	out, _ := dockerCmd(c, "exec", "--cpus", "0.5", "-m", "32m", "limited", "cat", "/proc/self/cgroup")
+	c.Assert(out, checker.Contains, "/exec-")
+
+	out, _ = dockerCmd(c, "exec", "-m", "32m", "limited", "sh", "-c", "cat /sys/fs/cgroup/memory/exec-*/memory.limit_in_bytes")
+	c.Assert(strings.TrimSpace(out), checker.Equals, "33554432")
+
+	out, _, err := dockerCmdWithError("exec", "-m", "1k", "limited", "true")
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "Minimum memory limit allowed is 4MB")
+}
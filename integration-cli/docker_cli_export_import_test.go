@@ -47,3 +47,23 @@ func (s *DockerSuite) TestExportContainerWithOutputAndImportImage(c *check.C) {
 	cleanedImageID := strings.TrimSpace(out)
 	c.Assert(cleanedImageID, checker.Not(checker.Equals), "", check.Commentf("output should have been an image id"))
 }
+
+func (s *DockerSuite) TestExportContainerSnapshot(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+
+	// the stopped containers are exported as is
+	dockerCmd(c, "run", "--name", "exportsnapshotstopped", "busybox", "true")
+	out, _ := dockerCmd(c, "export", "--snapshot", "exportsnapshotstopped")
+	c.Assert(out, checker.Contains, "bin/busybox")
+
+	dockerCmd(c, "run", "-d", "--name", "exportsnapshot", "busybox", "top")
+	out, _, err := dockerCmdWithError("export", "--snapshot", "exportsnapshot")
+	switch daemonStorageDriver {
+	case "btrfs", "devicemapper", "zfs":
+		c.Assert(err, checker.IsNil, check.Commentf(out))
+		c.Assert(out, checker.Contains, "bin/busybox")
+	default:
+		c.Assert(err, checker.NotNil)
+		c.Assert(out, checker.Contains, "cannot snapshot container exportsnapshot")
+	}
+}
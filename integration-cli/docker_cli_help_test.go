@@ -115,6 +115,14 @@ func (s *DockerSuite) TestHelpTextVerify(c *check.C) {
 
 		// Add some 'two word' commands - would be nice to automatically
 		// calculate this list - somehow
+		cmdsToTest = append(cmdsToTest, "builder prune")
+		cmdsToTest = append(cmdsToTest, "container clone")
+		cmdsToTest = append(cmdsToTest, "container prune")
+		cmdsToTest = append(cmdsToTest, "container refresh")
+		cmdsToTest = append(cmdsToTest, "job create")
+		cmdsToTest = append(cmdsToTest, "job inspect")
+		cmdsToTest = append(cmdsToTest, "job ls")
+		cmdsToTest = append(cmdsToTest, "job rm")
 		cmdsToTest = append(cmdsToTest, "volume create")
 		cmdsToTest = append(cmdsToTest, "volume inspect")
 		cmdsToTest = append(cmdsToTest, "volume ls")
@@ -139,7 +147,7 @@ func (s *DockerSuite) TestHelpTextVerify(c *check.C) {
 		}
 
 		// Number of commands for standard release and experimental release
-		standard := 41
+		standard := 43
 		experimental := 1
 		expected := standard + experimental
 		if isLocalDaemon {
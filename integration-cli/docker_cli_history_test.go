@@ -89,6 +89,16 @@ func (s *DockerSuite) TestHistoryImageWithComment(c *check.C) {
 	c.Assert(actualValue, checker.Contains, comment)
 }
 
+func (s *DockerSuite) TestHistoryImageFromCommitRecordsContainer(c *check.C) {
+	name := "testhistorycommitorigin"
+	dockerCmd(c, "run", "--name", name, "busybox", "echo", "hello")
+	id := inspectField(c, name, "Id")
+	dockerCmd(c, "commit", name, name)
+
+	out, _ := dockerCmd(c, "history", "--no-trunc", name)
+	c.Assert(strings.Split(out, "\n")[1], checker.Contains, "#(commit) "+name+" "+id[:12]+": echo hello")
+}
+
 func (s *DockerSuite) TestHistoryHumanOptionFalse(c *check.C) {
 	out, _ := dockerCmd(c, "history", "--human=false", "busybox")
 	lines := strings.Split(out, "\n")
@@ -263,6 +263,25 @@ func (s *DockerSuite) TestImagesFormat(c *check.C) {
 	c.Assert(expected, checker.DeepEquals, names, check.Commentf("Expected array with truncated names: %v, got: %v", expected, names))
 }
 
+func (s *DockerSuite) TestImagesFormatSharedSize(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	_, err := buildImage("sharedsize", `FROM busybox
+		RUN dd if=/dev/zero of=/file bs=1k count=100`, true)
+	c.Assert(err, checker.IsNil)
+
+	out, _ := dockerCmd(c, "images", "--format", "{{.SharedSize}} {{.UniqueSize}} {{.Containers}}", "sharedsize")
+	// The busybox layers are shared with the busybox image.
+	fields := strings.Fields(out)
+	c.Assert(fields, checker.HasLen, 5, check.Commentf(out))
+	c.Assert(fields[0]+fields[1], checker.Not(checker.Equals), "0B", check.Commentf(out))
+	c.Assert(fields[2]+fields[3], checker.Matches, "10[0-9.]*kB", check.Commentf(out))
+	c.Assert(fields[4], checker.Equals, "0")
+
+	dockerCmd(c, "create", "sharedsize")
+	out, _ = dockerCmd(c, "images", "--format", "{{.Containers}}", "sharedsize")
+	c.Assert(strings.TrimSpace(out), checker.Equals, "1")
+}
+
 // ImagesDefaultFormatAndQuiet
 func (s *DockerSuite) TestImagesFormatDefaultFormat(c *check.C) {
 	testRequires(c, DaemonIsLinux)
@@ -121,3 +121,29 @@ func (s *DockerSuite) TestImportFileNonExistentFile(c *check.C) {
 	_, _, err := dockerCmdWithError("import", "example.com/myImage.tar")
 	c.Assert(err, checker.NotNil, check.Commentf("import non-existing file must failed"))
 }
+
+func (s *DockerSuite) TestImportWithPlatformAndChanges(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	dockerCmd(c, "run", "--name", "test-import", "busybox", "true")
+
+	temporaryFile, err := ioutil.TempFile("", "exportImportTest")
+	c.Assert(err, checker.IsNil, check.Commentf("failed to create temporary file"))
+	defer os.Remove(temporaryFile.Name())
+
+	runCmd := exec.Command(dockerBinary, "export", "test-import")
+	runCmd.Stdout = bufio.NewWriter(temporaryFile)
+
+	_, err = runCommand(runCmd)
+	c.Assert(err, checker.IsNil, check.Commentf("failed to export a container"))
+
+	out, _ := dockerCmd(c, "import", "--platform", "linux/arm64", "--change", "STOPSIGNAL SIGKILL", temporaryFile.Name())
+	image := strings.TrimSpace(out)
+
+	c.Assert(inspectField(c, image, "Architecture"), checker.Equals, "arm64")
+	c.Assert(inspectField(c, image, "Os"), checker.Equals, "linux")
+	c.Assert(inspectField(c, image, "Config.StopSignal"), checker.Equals, "SIGKILL")
+
+	out, _, err = dockerCmdWithError("import", "--platform", "windows", temporaryFile.Name())
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "cannot import an image for windows")
+}
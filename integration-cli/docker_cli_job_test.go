@@ -0,0 +1,46 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/docker/docker/pkg/integration/checker"
+	"github.com/go-check/check"
+)
+
+func (s *DockerSuite) TestJobCreateInspectRemove(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	out, _ := dockerCmd(c, "job", "create", "--name", "nightly", "--schedule", "0 3 * * *", "--overlap", "replace", "--history", "3", "-e", "FOO=bar", "busybox", "true")
+	c.Assert(strings.TrimSpace(out), checker.Equals, "nightly")
+
+	out, _ = dockerCmd(c, "job", "inspect", "--format", "{{.Spec.Schedule}} {{.Spec.OverlapPolicy}} {{.Spec.HistoryLimit}} {{.Spec.Config.Env}} {{.NextRun.Hour}} {{.NextRun.Minute}}", "nightly")
+	c.Assert(strings.TrimSpace(out), checker.Equals, "0 3 * * * replace 3 [FOO=bar] 3 0")
+
+	out, _ = dockerCmd(c, "job", "ls")
+	c.Assert(out, checker.Contains, "nightly")
+	c.Assert(out, checker.Contains, "0 3 * * *")
+
+	// the names of the jobs are unique
+	out, _, err := dockerCmdWithError("job", "create", "--name", "nightly", "--schedule", "@hourly", "busybox", "true")
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "already in use")
+
+	dockerCmd(c, "job", "rm", "nightly")
+	out, _ = dockerCmd(c, "job", "ls", "-q")
+	c.Assert(out, checker.Not(checker.Contains), "nightly")
+}
+
+func (s *DockerSuite) TestJobCreateInvalid(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	out, _, err := dockerCmdWithError("job", "create", "--schedule", "0 25 * * *", "busybox", "true")
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "invalid value \"25\" in hour field")
+
+	out, _, err = dockerCmdWithError("job", "create", "--schedule", "@daily", "--overlap", "queue", "busybox", "true")
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "invalid overlap policy")
+
+	// the settings of the containers are validated on creation
+	out, _, err = dockerCmdWithError("job", "create", "--schedule", "@daily", "busybox:doesnotexist", "true")
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "No such image")
+}
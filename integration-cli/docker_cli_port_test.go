@@ -1,13 +1,18 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"net"
+	"net/http"
+	"os"
+	"os/exec"
 	"regexp"
 	"sort"
 	"strings"
 
 	"github.com/docker/docker/pkg/integration/checker"
+	"github.com/docker/engine-api/types"
 	"github.com/go-check/check"
 )
 
@@ -317,3 +322,84 @@ func (s *DockerSuite) TestPortBindingOnSandbox(c *check.C) {
 		check.Commentf("Port mapping on the new network is expected to succeed"))
 
 }
+
+func (s *DockerSuite) TestPortContiguousRange(c *check.C) {
+	testRequires(c, DaemonIsLinux, SameHostDaemon, NotUserNamespace)
+	out, _ := dockerCmd(c, "run", "-d", "-p", "30000-30004:30000-30004", "busybox", "top")
+	id := strings.TrimSpace(out)
+
+	out, _ = dockerCmd(c, "port", id)
+	for port := 30000; port <= 30004; port++ {
+		c.Assert(out, checker.Contains, fmt.Sprintf("%d/tcp -> 0.0.0.0:%d", port, port))
+	}
+
+	// The range is forwarded with a single rule.
+	ipt, err := exec.Command("iptables", "-t", "nat", "-S", "DOCKER").CombinedOutput()
+	c.Assert(err, checker.IsNil, check.Commentf(string(ipt)))
+	c.Assert(strings.Count(string(ipt), "--dport 30000:30004"), checker.Equals, 1, check.Commentf(string(ipt)))
+
+	dockerCmd(c, "rm", "-f", id)
+	ipt, err = exec.Command("iptables", "-t", "nat", "-S", "DOCKER").CombinedOutput()
+	c.Assert(err, checker.IsNil, check.Commentf(string(ipt)))
+	c.Assert(string(ipt), checker.Not(checker.Contains), "--dport 30000:30004")
+
+	// The ports are released.
+	out, _ = dockerCmd(c, "run", "-d", "-p", "30002:30002", "busybox", "top")
+	dockerCmd(c, "rm", "-f", strings.TrimSpace(out))
+}
+
+func (s *DockerSuite) TestPortConflictOwner(c *check.C) {
+	testRequires(c, DaemonIsLinux, SameHostDaemon, NotUserNamespace)
+	dockerCmd(c, "run", "-d", "--name", "owner", "-p", "9877:80", "busybox", "top")
+	ownerID := inspectField(c, "owner", "Id")
+
+	status, body, err := sockRequest("GET", "/ports", nil)
+	c.Assert(err, checker.IsNil)
+	c.Assert(status, checker.Equals, http.StatusOK)
+	var allocations []types.PortAllocation
+	c.Assert(json.Unmarshal(body, &allocations), checker.IsNil)
+	c.Assert(allocations, checker.HasLen, 1)
+	c.Assert(allocations[0], checker.DeepEquals, types.PortAllocation{
+		HostIP:        "0.0.0.0",
+		HostPort:      9877,
+		Proto:         "tcp",
+		ContainerPort: 80,
+		ContainerID:   ownerID,
+		ContainerName: "owner",
+	})
+
+	dockerCmd(c, "create", "--name", "conflict", "-p", "9877:80", "busybox", "top")
+	out, _, err := dockerCmdWithError("start", "conflict")
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "tcp port 9877 is used by container owner")
+
+	status, body, err = sockRequest("POST", "/containers/conflict/start", nil)
+	c.Assert(err, checker.IsNil)
+	c.Assert(status, checker.Equals, http.StatusConflict)
+	var errResp types.ErrorResponse
+	c.Assert(json.Unmarshal(body, &errResp), checker.IsNil)
+	c.Assert(errResp.PortConflict, checker.NotNil)
+	c.Assert(errResp.PortConflict.ContainerID, checker.Equals, ownerID)
+	c.Assert(errResp.PortConflict.HostPort, checker.Equals, 9877)
+
+	// The clients of older API versions get the error as text.
+	status, body, err = sockRequest("POST", "/v1.23/containers/conflict/start", nil)
+	c.Assert(err, checker.IsNil)
+	c.Assert(status, checker.Equals, http.StatusConflict)
+	c.Assert(string(body), checker.Contains, "tcp port 9877 is used by container owner")
+	c.Assert(json.Unmarshal(body, &errResp), checker.NotNil)
+
+	// A process of the host is reported when no container owns the port.
+	dockerCmd(c, "rm", "-f", "owner")
+	l, err := net.Listen("tcp", "0.0.0.0:9877")
+	c.Assert(err, checker.IsNil)
+	defer l.Close()
+	status, body, err = sockRequest("POST", "/containers/conflict/start", nil)
+	c.Assert(err, checker.IsNil)
+	c.Assert(status, checker.Equals, http.StatusConflict)
+	errResp = types.ErrorResponse{}
+	c.Assert(json.Unmarshal(body, &errResp), checker.IsNil)
+	c.Assert(errResp.PortConflict, checker.NotNil)
+	c.Assert(errResp.PortConflict.ContainerID, checker.Equals, "")
+	c.Assert(errResp.PortConflict.Pid, checker.Equals, os.Getpid())
+}
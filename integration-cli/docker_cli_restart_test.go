@@ -240,3 +240,36 @@ func (s *DockerSuite) TestRestartContainerwithRestartPolicy(c *check.C) {
 	dockerCmd(c, "start", id1)
 	dockerCmd(c, "start", id2)
 }
+
+func (s *DockerSuite) TestRestartJobModeFailed(c *check.C) {
+	out, _ := dockerCmd(c, "run", "-d", "--job", "--restart=on-failure:2", "busybox", "false")
+	id := strings.TrimSpace(out)
+
+	err := waitInspect(id, "{{ .State.JobStatus }}", "failed", 30*time.Second)
+	c.Assert(err, checker.IsNil)
+	c.Assert(inspectField(c, id, "RestartCount"), checker.Equals, "2")
+	c.Assert(inspectField(c, id, "State.ExitCode"), checker.Equals, "1")
+
+	// a failed job can be run again
+	dockerCmd(c, "start", id)
+}
+
+func (s *DockerSuite) TestRestartJobModeSucceeded(c *check.C) {
+	out, _ := dockerCmd(c, "run", "-d", "--job", "--restart=on-failure:2", "busybox", "true")
+	id := strings.TrimSpace(out)
+
+	err := waitInspect(id, "{{ .State.JobStatus }}", "succeeded", 30*time.Second)
+	c.Assert(err, checker.IsNil)
+	c.Assert(inspectField(c, id, "RestartCount"), checker.Equals, "0")
+
+	out, _, err = dockerCmdWithError("start", id)
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "already succeeded")
+	c.Assert(inspectField(c, id, "State.JobStatus"), checker.Equals, "succeeded")
+}
+
+func (s *DockerSuite) TestRestartJobModeAlwaysConflict(c *check.C) {
+	out, _, err := dockerCmdWithError("run", "-d", "--job", "--restart=always", "busybox", "true")
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "not supported in job mode")
+}
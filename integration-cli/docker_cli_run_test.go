@@ -4370,3 +4370,14 @@ func (s *DockerSuite) TestRunTooLongHostname(c *check.C) {
 
 	}
 }
+
+func (s *DockerSuite) TestRunPullNever(c *check.C) {
+	out, _, err := dockerCmdWithError("run", "--pull=never", "busybox-does-not-exist", "true")
+	c.Assert(err, checker.NotNil, check.Commentf("Expected docker run to fail!"))
+	c.Assert(out, checker.Contains, "No such image")
+	c.Assert(out, checker.Not(checker.Contains), "Unable to find image")
+
+	out, _, err = dockerCmdWithError("run", "--pull=sometimes", "busybox", "true")
+	c.Assert(err, checker.NotNil, check.Commentf("Expected docker run to fail!"))
+	c.Assert(out, checker.Contains, "invalid --pull value")
+}
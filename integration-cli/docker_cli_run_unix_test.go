@@ -1169,3 +1169,47 @@ func (s *DockerSuite) TestRunUserDeviceAllowed(c *check.C) {
 	out, _ := dockerCmd(c, "run", "--device", "/dev/snd/timer:w", "busybox", "cat", file)
 	c.Assert(out, checker.Contains, fmt.Sprintf("c %d:%d w", stat.Rdev/256, stat.Rdev%256))
 }
+
+func (s *DockerSuite) TestRunAutoCpuset(c *check.C) {
+	testRequires(c, cgroupCpuset)
+
+	out, _ := dockerCmd(c, "run", "-d", "--cpuset-cpus=auto:1", "busybox", "top")
+	id := strings.TrimSpace(out)
+	placement := inspectField(c, id, "CPUPlacement.CPUs")
+	c.Assert(placement, checker.Not(checker.Equals), "")
+	c.Assert(inspectField(c, id, "HostConfig.CpusetCpus"), checker.Equals, placement)
+
+	out, _ = dockerCmd(c, "exec", id, "cat", "/sys/fs/cgroup/cpuset/cpuset.cpus")
+	c.Assert(strings.TrimSpace(out), checker.Equals, placement)
+
+	out, _, err := dockerCmdWithError("run", "--cpuset-cpus=auto:0", "busybox", "true")
+	c.Assert(err, checker.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "must be a positive integer")
+}
+
+func (s *DockerSuite) TestRunNetworkDevice(c *check.C) {
+	testRequires(c, DaemonIsLinux, SameHostDaemon, NotUserNamespace)
+
+	out, err := exec.Command("ip", "link", "add", "name", "dtestnd0", "type", "dummy").CombinedOutput()
+	c.Assert(err, checker.IsNil, check.Commentf(string(out)))
+	defer exec.Command("ip", "link", "delete", "dtestnd0").Run()
+
+	// The interface is moved into the container, under its new name.
+	dockerOut, _ := dockerCmd(c, "run", "--network-device=dtestnd0:net1", "busybox", "sh", "-c", "ip link show net1 && ! ip link show dtestnd0")
+	c.Assert(dockerOut, checker.Contains, "net1")
+
+	// It is back on the host, under its name, once the container is cleaned
+	// up.
+	for i := 0; ; i++ {
+		out, err = exec.Command("ip", "link", "show", "dtestnd0").CombinedOutput()
+		if err == nil {
+			break
+		}
+		c.Assert(i < 50, checker.True, check.Commentf("The network device is not back on the host: %s", out))
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	dockerOut, _, err = dockerCmdWithError("run", "--net=host", "--network-device=dtestnd0", "busybox", "true")
+	c.Assert(err, checker.NotNil, check.Commentf(dockerOut))
+	c.Assert(dockerOut, checker.Contains, "Network devices cannot be moved")
+}
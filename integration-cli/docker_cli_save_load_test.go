@@ -350,3 +350,42 @@ func (s *DockerSuite) TestSaveLoadParents(c *check.C) {
 	inspectOut = inspectField(c, idFoo, "Parent")
 	c.Assert(inspectOut, checker.Equals, "")
 }
+
+func (s *DockerSuite) TestSaveAndLoadOCILayout(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	name := "test-save-and-load-oci"
+	dockerCmd(c, "run", "--name", name, "busybox", "true")
+
+	repoName := "foobar-save-load-oci:v1"
+	deleteImages(repoName)
+	dockerCmd(c, "commit", name, repoName)
+	before := inspectField(c, repoName, "Id")
+
+	tmpDir, err := ioutil.TempDir("", "save-load-oci")
+	c.Assert(err, checker.IsNil)
+	defer os.RemoveAll(tmpDir)
+
+	dockerCmd(c, "save", "--format", "oci", "-o", tmpDir, repoName)
+
+	layout, err := ioutil.ReadFile(filepath.Join(tmpDir, "oci-layout"))
+	c.Assert(err, checker.IsNil)
+	c.Assert(string(layout), checker.Contains, `"imageLayoutVersion":"1.0.0"`)
+
+	var index struct {
+		Manifests []struct {
+			Digest      digest.Digest
+			Annotations map[string]string
+		}
+	}
+	content, err := ioutil.ReadFile(filepath.Join(tmpDir, "index.json"))
+	c.Assert(err, checker.IsNil)
+	c.Assert(json.Unmarshal(content, &index), checker.IsNil)
+	c.Assert(index.Manifests, checker.HasLen, 1)
+	c.Assert(index.Manifests[0].Annotations["org.opencontainers.image.ref.name"], checker.Equals, "v1")
+	_, err = os.Stat(filepath.Join(tmpDir, "blobs", "sha256", index.Manifests[0].Digest.Hex()))
+	c.Assert(err, checker.IsNil)
+
+	deleteImages(repoName)
+	dockerCmd(c, "load", "-i", tmpDir)
+	c.Assert(inspectField(c, repoName, "Id"), checker.Equals, before)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"archive/tar"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/pkg/integration/checker"
+	"github.com/go-check/check"
+)
+
+func (s *DockerSuite) TestTrustGenerateAndBackupKeys(c *check.C) {
+	generateCmd := exec.Command(dockerBinary, "trust", "generate", "--root")
+	trustCmdEnv(generateCmd, "", "12345678", "")
+	out, _, err := runCommandWithOutput(generateCmd)
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "-----BEGIN PUBLIC KEY-----")
+	c.Assert(out, checker.Contains, "Generated root key ")
+	rootKeyID := strings.Fields(strings.SplitN(out, "Generated root key ", 2)[1])[0]
+
+	generateCmd = exec.Command(dockerBinary, "trust", "generate", "alice")
+	trustCmdEnv(generateCmd, "", "", "12345678")
+	out, _, err = runCommandWithOutput(generateCmd)
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "Generated alice key ")
+
+	out, _ = dockerCmd(c, "trust", "keys", "-q")
+	c.Assert(out, checker.Contains, rootKeyID)
+
+	// the hardware token only holds root keys
+	out, _, err = dockerCmdWithError("trust", "generate", "--pkcs11", "bob")
+	c.Assert(err, checker.NotNil, check.Commentf(out))
+
+	backup := filepath.Join(c.MkDir(), "keys.tar")
+	dockerCmd(c, "trust", "backup", "-o", backup)
+	f, err := os.Open(backup)
+	c.Assert(err, checker.IsNil)
+	defer f.Close()
+	found := false
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		c.Assert(err, checker.IsNil)
+		if strings.Contains(hdr.Name, rootKeyID) {
+			found = true
+		}
+	}
+	c.Assert(found, checker.True, check.Commentf("the root key %s is not in the backup", rootKeyID))
+}
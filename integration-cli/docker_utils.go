@@ -222,6 +222,14 @@ func readBody(b io.ReadCloser) ([]byte, error) {
 	return ioutil.ReadAll(b)
 }
 
+// getErrorResponse decodes the JSON body of an error response of the
+// remote API.
+func getErrorResponse(c *check.C, body []byte) types.ErrorResponse {
+	var resp types.ErrorResponse
+	c.Assert(json.Unmarshal(body, &resp), check.IsNil, check.Commentf("Expected an error response, got %q", body))
+	return resp
+}
+
 func deleteContainer(container string) error {
 	container = strings.TrimSpace(strings.Replace(container, "\n", " ", -1))
 	rmArgs := strings.Split(fmt.Sprintf("rm -fv %v", container), " ")
@@ -3,6 +3,8 @@
 package main
 
 import (
+	"os"
+
 	"github.com/docker/docker/pkg/sysinfo"
 )
 
@@ -99,6 +101,12 @@ var (
 		},
 		"Test requires that bridge-nf-call-ip6tables support be enabled in the daemon.",
 	}
+	rootlessUser = testRequirement{
+		func() bool {
+			return os.Getenv("DOCKER_ROOTLESS_USER") != ""
+		},
+		"Test requires DOCKER_ROOTLESS_USER to be set to an unprivileged user with subordinate IDs.",
+	}
 )
 
 func init() {
@@ -56,6 +56,10 @@ func (fms *fileMetadataStore) getLayerFilename(layer ChainID, filename string) s
 	return filepath.Join(fms.getLayerDirectory(layer), filename)
 }
 
+func (fms *fileMetadataStore) getRemovingDirectory(cacheID string) string {
+	return filepath.Join(fms.root, "removing", cacheID)
+}
+
 func (fms *fileMetadataStore) getMountDirectory(mount string) string {
 	return filepath.Join(fms.root, "mounts", mount)
 }
@@ -324,3 +328,33 @@ func (fms *fileMetadataStore) Remove(layer ChainID) error {
 func (fms *fileMetadataStore) RemoveMount(mount string) error {
 	return os.RemoveAll(fms.getMountDirectory(mount))
 }
+
+func (fms *fileMetadataStore) StartRemove(layer ChainID, cacheID string) error {
+	removingDir := fms.getRemovingDirectory(cacheID)
+	if err := os.MkdirAll(filepath.Dir(removingDir), 0700); err != nil {
+		return err
+	}
+	return os.Rename(fms.getLayerDirectory(layer), removingDir)
+}
+
+func (fms *fileMetadataStore) ListRemoving() ([]string, error) {
+	fileInfos, err := ioutil.ReadDir(filepath.Join(fms.root, "removing"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+
+	var cacheIDs []string
+	for _, fi := range fileInfos {
+		if fi.IsDir() {
+			cacheIDs = append(cacheIDs, fi.Name())
+		}
+	}
+	return cacheIDs, nil
+}
+
+func (fms *fileMetadataStore) FinishRemove(cacheID string) error {
+	return os.RemoveAll(fms.getRemovingDirectory(cacheID))
+}
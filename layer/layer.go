@@ -137,6 +137,24 @@ type RWLayer interface {
 	// from the base layer.
 	Changes() ([]archive.Change, error)
 
+	// WalkChanges calls fn for each change of the mutable layer
+	// from the base layer, without collecting them first when the
+	// driver supports it.
+	WalkChanges(fn func(archive.Change) error) error
+
+	// SnapshotTarStream returns a tar archive of the changes in a
+	// point-in-time snapshot of the mutable layer, taken without
+	// stopping writes to it. graphdriver.ErrSnapshotNotSupported is
+	// returned if the driver cannot snapshot a layer in use.
+	SnapshotTarStream() (io.ReadCloser, error)
+
+	// MountSnapshot mounts a point-in-time snapshot of the mutable
+	// layer, taken without stopping writes to it, and returns its path
+	// and the function unmounting and removing the snapshot.
+	// graphdriver.ErrSnapshotNotSupported is returned if the driver
+	// cannot snapshot a layer in use.
+	MountSnapshot(mountLabel string) (string, func() error, error)
+
 	// Metadata returns the low level metadata for the mutable layer
 	Metadata() (map[string]string, error)
 }
@@ -169,6 +187,7 @@ type MountInit func(root string) error
 type Store interface {
 	Register(io.Reader, ChainID) (Layer, error)
 	Get(ChainID) (Layer, error)
+	Map() map[ChainID]Layer
 	Release(Layer) ([]Metadata, error)
 
 	CreateRWLayer(id string, parent ChainID, mountLabel string, initFunc MountInit, storageOpt map[string]string) (RWLayer, error)
@@ -224,6 +243,14 @@ type MetadataStore interface {
 
 	Remove(ChainID) error
 	RemoveMount(string) error
+
+	// StartRemove moves the metadata of a layer out of the store, and
+	// records its cache ID as pending removal from the driver until
+	// FinishRemove is called.
+	StartRemove(layer ChainID, cacheID string) error
+	// ListRemoving returns the cache IDs of the layers pending removal.
+	ListRemoving() ([]string, error)
+	FinishRemove(cacheID string) error
 }
 
 // CreateChainID returns ID for a layerDigest slice
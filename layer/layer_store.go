@@ -77,6 +77,8 @@ func NewStoreFromGraphDriver(store MetadataStore, driver graphdriver.Driver) (St
 		mounts:   map[string]*mountedLayer{},
 	}
 
+	ls.removePending()
+
 	ids, mounts, err := store.List()
 	if err != nil {
 		return nil, err
@@ -243,9 +245,7 @@ func (ls *layerStore) Register(ts io.Reader, parent ChainID) (Layer, error) {
 		// Release parent chain if error
 		defer func() {
 			if err != nil {
-				ls.layerL.Lock()
-				ls.releaseLayer(p)
-				ls.layerL.Unlock()
+				ls.releaseAndRemove(p)
 			}
 		}()
 		if p.depth() >= maxLayerDepth {
@@ -345,16 +345,26 @@ func (ls *layerStore) Get(l ChainID) (Layer, error) {
 	return layer.getReference(), nil
 }
 
-func (ls *layerStore) deleteLayer(layer *roLayer, metadata *Metadata) error {
-	err := ls.driver.Remove(layer.cacheID)
-	if err != nil {
-		return err
-	}
+// Map returns all the layers of the store by chain ID. The layers are not
+// referenced, so they must not be released.
+func (ls *layerStore) Map() map[ChainID]Layer {
+	ls.layerL.Lock()
+	defer ls.layerL.Unlock()
 
-	err = ls.store.Remove(layer.chainID)
-	if err != nil {
-		return err
+	layers := make(map[ChainID]Layer, len(ls.layerMap))
+	for k, v := range ls.layerMap {
+		layers[k] = v
 	}
+	return layers
+}
+
+// unregisterLayer moves the metadata of layer out of the store, so that a
+// layer with the same chain ID can be registered again right away. The
+// metadata is kept as pending removal until removeLayers removes the content
+// of the layer from the driver, so that the content is not orphaned if the
+// removal fails or the daemon stops.
+func (ls *layerStore) unregisterLayer(layer *roLayer, metadata *Metadata) error {
+	var err error
 	metadata.DiffID = layer.diffID
 	metadata.ChainID = layer.chainID
 	metadata.Size, err = layer.Size()
@@ -363,11 +373,36 @@ func (ls *layerStore) deleteLayer(layer *roLayer, metadata *Metadata) error {
 	}
 	metadata.DiffSize = layer.size
 
-	return nil
+	return ls.store.StartRemove(layer.chainID, layer.cacheID)
+}
+
+// removePending removes the layers left pending removal by a previous
+// daemon, which failed or stopped before removing them from the driver.
+func (ls *layerStore) removePending() {
+	cacheIDs, err := ls.store.ListRemoving()
+	if err != nil {
+		logrus.Errorf("Error listing the layers pending removal: %v", err)
+		return
+	}
+	for _, cacheID := range cacheIDs {
+		if err := ls.driver.Remove(cacheID); err != nil {
+			logrus.Errorf("Error removing layer with cache ID %s: %v", cacheID, err)
+			continue
+		}
+		if err := ls.store.FinishRemove(cacheID); err != nil {
+			logrus.Errorf("Error removing the metadata of layer with cache ID %s: %v", cacheID, err)
+		}
+	}
 }
 
-func (ls *layerStore) releaseLayer(l *roLayer) ([]Metadata, error) {
+// releaseLayer releases a reference to l, and unregisters l and its parents
+// as they are no longer referenced. It must be called with layerL held, and
+// the layers it returns must then be passed to removeLayers without holding
+// layerL: removing the content of a layer from the driver can take long, and
+// must not block the pulls and container creates using the other layers.
+func (ls *layerStore) releaseLayer(l *roLayer) ([]*roLayer, []Metadata, error) {
 	depth := 0
+	var layers []*roLayer
 	removed := []Metadata{}
 	for {
 		if l.referenceCount == 0 {
@@ -375,7 +410,7 @@ func (ls *layerStore) releaseLayer(l *roLayer) ([]Metadata, error) {
 		}
 		l.referenceCount--
 		if l.referenceCount != 0 {
-			return removed, nil
+			return layers, removed, nil
 		}
 
 		if len(removed) == 0 && depth > 0 {
@@ -385,15 +420,16 @@ func (ls *layerStore) releaseLayer(l *roLayer) ([]Metadata, error) {
 			panic("cannot delete referenced layer")
 		}
 		var metadata Metadata
-		if err := ls.deleteLayer(l, &metadata); err != nil {
-			return nil, err
+		if err := ls.unregisterLayer(l, &metadata); err != nil {
+			return layers, removed, err
 		}
 
 		delete(ls.layerMap, l.chainID)
+		layers = append(layers, l)
 		removed = append(removed, metadata)
 
 		if l.parent == nil {
-			return removed, nil
+			return layers, removed, nil
 		}
 
 		depth++
@@ -401,20 +437,61 @@ func (ls *layerStore) releaseLayer(l *roLayer) ([]Metadata, error) {
 	}
 }
 
+// removeLayers removes the content of the layers unregistered by
+// releaseLayer from the driver, children first, and then their metadata. It
+// returns the number of layers removed before the first error. The layers not
+// removed stay pending removal until the daemon restarts.
+func (ls *layerStore) removeLayers(layers []*roLayer) (int, error) {
+	for i, l := range layers {
+		if err := ls.driver.Remove(l.cacheID); err != nil {
+			logrus.Errorf("Error removing layer %s with cache ID %s: %v", l.chainID, l.cacheID, err)
+			return i, err
+		}
+		if err := ls.store.FinishRemove(l.cacheID); err != nil {
+			logrus.Errorf("Error removing the metadata of layer %s with cache ID %s: %v", l.chainID, l.cacheID, err)
+			return i, err
+		}
+	}
+	return len(layers), nil
+}
+
+// releaseAndRemove releases a reference to l as releaseLayer does, and
+// removes the layers unregistered once layerL is released.
+func (ls *layerStore) releaseAndRemove(l *roLayer) ([]Metadata, error) {
+	ls.layerL.Lock()
+	layers, removed, err := ls.releaseLayer(l)
+	ls.layerL.Unlock()
+
+	return ls.finishRelease(layers, removed, err)
+}
+
+// finishRelease removes the layers unregistered by releaseLayer, and returns
+// the metadata of the layers removed along with the first error.
+func (ls *layerStore) finishRelease(layers []*roLayer, removed []Metadata, err error) ([]Metadata, error) {
+	n, rmErr := ls.removeLayers(layers)
+	if err == nil {
+		err = rmErr
+	}
+	return removed[:n], err
+}
+
 func (ls *layerStore) Release(l Layer) ([]Metadata, error) {
 	ls.layerL.Lock()
-	defer ls.layerL.Unlock()
 	layer, ok := ls.layerMap[l.ChainID()]
 	if !ok {
+		ls.layerL.Unlock()
 		return []Metadata{}, nil
 	}
 	if !layer.hasReference(l) {
+		ls.layerL.Unlock()
 		return nil, ErrLayerNotRetained
 	}
 
 	layer.deleteReference(l)
+	layers, removed, err := ls.releaseLayer(layer)
+	ls.layerL.Unlock()
 
-	return ls.releaseLayer(layer)
+	return ls.finishRelease(layers, removed, err)
 }
 
 func (ls *layerStore) CreateRWLayer(name string, parent ChainID, mountLabel string, initFunc MountInit, storageOpt map[string]string) (RWLayer, error) {
@@ -438,9 +515,7 @@ func (ls *layerStore) CreateRWLayer(name string, parent ChainID, mountLabel stri
 		// Release parent chain if error
 		defer func() {
 			if err != nil {
-				ls.layerL.Lock()
-				ls.releaseLayer(p)
-				ls.layerL.Unlock()
+				ls.releaseAndRemove(p)
 			}
 		}()
 	}
@@ -552,10 +627,8 @@ func (ls *layerStore) ReleaseRWLayer(l RWLayer) ([]Metadata, error) {
 
 	delete(ls.mounts, m.Name())
 
-	ls.layerL.Lock()
-	defer ls.layerL.Unlock()
 	if m.parent != nil {
-		return ls.releaseLayer(m.parent)
+		return ls.releaseAndRemove(m.parent)
 	}
 
 	return []Metadata{}, nil
@@ -2,6 +2,7 @@ package layer
 
 import (
 	"bytes"
+	"errors"
 	"io"
 	"io/ioutil"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/docker/distribution/digest"
 	"github.com/docker/docker/daemon/graphdriver"
@@ -786,3 +788,180 @@ func TestTarStreamVerification(t *testing.T) {
 		t.Fatalf("wrong error returned from tarstream: %q", err)
 	}
 }
+
+// blockingRemoveDriver blocks the removals of layers until proceed is closed.
+type blockingRemoveDriver struct {
+	graphdriver.Driver
+	removing chan string
+	proceed  chan struct{}
+}
+
+func (d *blockingRemoveDriver) Remove(id string) error {
+	d.removing <- id
+	<-d.proceed
+	return d.Driver.Remove(id)
+}
+
+func TestReleaseDoesNotBlockStore(t *testing.T) {
+	td, err := ioutil.TempDir("", "layerstore-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(td)
+	graph, graphcleanup := newTestGraphDriver(t)
+	defer graphcleanup()
+	driver := &blockingRemoveDriver{
+		Driver:   graph,
+		removing: make(chan string, 10),
+		proceed:  make(chan struct{}),
+	}
+	fms, err := NewFSMetadataStore(td)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ls, err := NewStoreFromGraphDriver(fms, driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tar1, err := tarFromFiles(newTestFile("/etc/profile", []byte("# Base configuration"), 0644))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tar2, err := tarFromFiles(newTestFile("/root/.bashrc", []byte("# Root configuration"), 0644))
+	if err != nil {
+		t.Fatal(err)
+	}
+	layer1, err := ls.Register(bytes.NewReader(tar1), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	layer2, err := ls.Register(bytes.NewReader(tar2), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	released := make(chan error)
+	go func() {
+		_, err := ls.Release(layer1)
+		released <- err
+	}()
+	select {
+	case <-driver.removing:
+	case <-time.After(10 * time.Second):
+		t.Fatal("layer was not removed")
+	}
+
+	// While the content of layer1 is being removed, the store is usable,
+	// and the same layer can be registered again.
+	done := make(chan error)
+	go func() {
+		l, err := ls.Get(layer2.ChainID())
+		if err == nil {
+			ls.Release(l)
+			_, err = ls.Register(bytes.NewReader(tar1), "")
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("store blocked by the removal of a layer")
+	}
+
+	close(driver.proceed)
+	if err := <-released; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ls.Get(layer1.ChainID()); err != nil {
+		t.Fatalf("layer registered again was removed: %v", err)
+	}
+}
+
+// failingRemoveDriver fails the removal of the layer with cache ID fail.
+type failingRemoveDriver struct {
+	graphdriver.Driver
+	fail string
+}
+
+func (d *failingRemoveDriver) Remove(id string) error {
+	if id == d.fail {
+		return errors.New("remove failed")
+	}
+	return d.Driver.Remove(id)
+}
+
+func TestReleaseKeepsPendingRemoval(t *testing.T) {
+	td, err := ioutil.TempDir("", "layerstore-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(td)
+	graph, graphcleanup := newTestGraphDriver(t)
+	defer graphcleanup()
+	driver := &failingRemoveDriver{Driver: graph}
+	fms, err := NewFSMetadataStore(td)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ls, err := NewStoreFromGraphDriver(fms, driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	layer1, err := createLayer(ls, "", initWithFiles(newTestFile("/etc/profile", []byte("# Base configuration"), 0644)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	layer2, err := createLayer(ls, layer1.ChainID(), initWithFiles(newTestFile("/root/.bashrc", []byte("# Root configuration"), 0644)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	layer3, err := createLayer(ls, layer2.ChainID(), initWithFiles(newTestFile("/root/.profile", []byte("# Root profile"), 0644)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range []Layer{layer1, layer2} {
+		if _, err := ls.Release(l); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cacheID1 := ls.(*layerStore).layerMap[layer1.ChainID()].cacheID
+	cacheID2 := ls.(*layerStore).layerMap[layer2.ChainID()].cacheID
+
+	// The removal of layer2 fails once layer3 is removed: the metadata of
+	// layer3 is returned, and layer2 and layer1 are left pending removal.
+	driver.fail = cacheID2
+	metadata, err := ls.Release(layer3)
+	if err == nil {
+		t.Fatal("expected the release to fail")
+	}
+	if len(metadata) != 1 || metadata[0].ChainID != layer3.ChainID() {
+		t.Fatalf("expected the metadata of the removed layer, got %v", metadata)
+	}
+	pending, err := fms.ListRemoving()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 2 {
+		t.Fatalf("expected 2 layers pending removal, got %v", pending)
+	}
+	if !graph.Exists(cacheID1) || !graph.Exists(cacheID2) {
+		t.Fatal("expected the content of the layers pending removal to be kept")
+	}
+
+	// A new store removes the layers left pending removal.
+	driver.fail = ""
+	if _, err := NewStoreFromGraphDriver(fms, driver); err != nil {
+		t.Fatal(err)
+	}
+	if pending, err := fms.ListRemoving(); err != nil || len(pending) != 0 {
+		t.Fatalf("expected no layer pending removal, got %v, %v", pending, err)
+	}
+	if graph.Exists(cacheID1) || graph.Exists(cacheID2) {
+		t.Fatal("expected the content of the layers to be removed")
+	}
+}
@@ -45,9 +45,7 @@ func (ls *layerStore) CreateRWLayerByGraphID(name string, graphID string, parent
 		// Release parent chain if error
 		defer func() {
 			if err != nil {
-				ls.layerL.Lock()
-				ls.releaseLayer(p)
-				ls.layerL.Unlock()
+				ls.releaseAndRemove(p)
 			}
 		}()
 	}
@@ -159,9 +157,7 @@ func (ls *layerStore) RegisterByGraphID(graphID string, parent ChainID, diffID D
 		// Release parent chain if error
 		defer func() {
 			if err != nil {
-				ls.layerL.Lock()
-				ls.releaseLayer(p)
-				ls.layerL.Unlock()
+				ls.releaseAndRemove(p)
 			}
 		}()
 	}
@@ -8,6 +8,7 @@ import (
 	"sort"
 	"testing"
 
+	"github.com/docker/docker/daemon/graphdriver"
 	"github.com/docker/docker/pkg/archive"
 )
 
@@ -228,3 +229,99 @@ func (cs *changeSorter) Swap(i, j int) {
 func (cs *changeSorter) Less(i, j int) bool {
 	return cs.changes[i].Path < cs.changes[j].Path
 }
+
+func TestMountSnapshotNotSupported(t *testing.T) {
+	ls, _, cleanup := newTestStore(t)
+	defer cleanup()
+
+	layer, err := createLayer(ls, "", initWithFiles(newTestFile("testfile.txt", []byte("base data!"), 0644)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := ls.CreateRWLayer("snapshot-mount", layer.ChainID(), "", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.SnapshotTarStream(); err != graphdriver.ErrSnapshotNotSupported {
+		t.Fatalf("Expected %v from the vfs driver, got %v", graphdriver.ErrSnapshotNotSupported, err)
+	}
+	if _, _, err := m.MountSnapshot(""); err != graphdriver.ErrSnapshotNotSupported {
+		t.Fatalf("Expected %v from the vfs driver, got %v", graphdriver.ErrSnapshotNotSupported, err)
+	}
+}
+
+// snapshotDriver snapshots the vfs layers by copying them, as vfs creates
+// its layers with a copy of their parent.
+type snapshotDriver struct {
+	graphdriver.Driver
+}
+
+func (d *snapshotDriver) Snapshot(id, parent string) error {
+	return d.Create(id, parent, "", nil)
+}
+
+func TestMountSnapshot(t *testing.T) {
+	// TODO Windows: Figure out why this is failing
+	if runtime.GOOS == "windows" {
+		t.Skip("Failing on Windows")
+	}
+	td, err := ioutil.TempDir("", "layerstore-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(td)
+	graph, graphcleanup := newTestGraphDriver(t)
+	defer graphcleanup()
+	driver := &snapshotDriver{graph}
+	fms, err := NewFSMetadataStore(td)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ls, err := NewStoreFromGraphDriver(fms, driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	layer, err := createLayer(ls, "", initWithFiles(newTestFile("base.txt", []byte("base data!"), 0644)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := ls.CreateRWLayer("snapshot-mount", layer.ChainID(), "", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := m.Mount("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := newTestFile("before.txt", []byte("written before"), 0644).ApplyFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshotPath, release, err := m.MountSnapshot("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshotPath == path {
+		t.Fatalf("Expected the snapshot to be mounted apart from the layer, got %s", snapshotPath)
+	}
+	if err := newTestFile("after.txt", []byte("written after"), 0644).ApplyFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	for file, expected := range map[string]bool{"base.txt": true, "before.txt": true, "after.txt": false} {
+		_, err := os.Stat(filepath.Join(snapshotPath, file))
+		if exists := err == nil; exists != expected {
+			t.Fatalf("Expected %s to exist in the snapshot: %t, got %t", file, expected, exists)
+		}
+	}
+
+	if err := release(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(snapshotPath); !os.IsNotExist(err) {
+		t.Fatalf("Expected the snapshot to be removed, got %v", err)
+	}
+}
@@ -4,7 +4,11 @@ import (
 	"io"
 	"sync"
 
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/graphdriver"
 	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/ioutils"
+	"github.com/docker/docker/pkg/stringid"
 )
 
 type mountedLayer struct {
@@ -36,6 +40,60 @@ func (ml *mountedLayer) TarStream() (io.ReadCloser, error) {
 	return archiver, nil
 }
 
+func (ml *mountedLayer) SnapshotTarStream() (io.ReadCloser, error) {
+	snapshotID, err := ml.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	archiver, err := ml.layerStore.driver.Diff(snapshotID, ml.cacheParent())
+	if err != nil {
+		ml.removeSnapshot(snapshotID)
+		return nil, err
+	}
+	return ioutils.NewReadCloserWrapper(archiver, func() error {
+		err := archiver.Close()
+		ml.removeSnapshot(snapshotID)
+		return err
+	}), nil
+}
+
+func (ml *mountedLayer) MountSnapshot(mountLabel string) (string, func() error, error) {
+	snapshotID, err := ml.snapshot()
+	if err != nil {
+		return "", nil, err
+	}
+	dir, err := ml.layerStore.driver.Get(snapshotID, mountLabel)
+	if err != nil {
+		ml.removeSnapshot(snapshotID)
+		return "", nil, err
+	}
+	return dir, func() error {
+		err := ml.layerStore.driver.Put(snapshotID)
+		ml.removeSnapshot(snapshotID)
+		return err
+	}, nil
+}
+
+// snapshot creates a snapshot of the layer in the driver, and returns its
+// ID.
+func (ml *mountedLayer) snapshot() (string, error) {
+	snapshotter, ok := ml.layerStore.driver.(graphdriver.Snapshotter)
+	if !ok {
+		return "", graphdriver.ErrSnapshotNotSupported
+	}
+	snapshotID := ml.mountID + "-snapshot-" + stringid.GenerateNonCryptoID()
+	if err := snapshotter.Snapshot(snapshotID, ml.mountID); err != nil {
+		return "", err
+	}
+	return snapshotID, nil
+}
+
+func (ml *mountedLayer) removeSnapshot(snapshotID string) {
+	if err := ml.layerStore.driver.Remove(snapshotID); err != nil {
+		logrus.Errorf("Failed to remove snapshot %s: %v", snapshotID, err)
+	}
+}
+
 func (ml *mountedLayer) Name() string {
 	return ml.name
 }
@@ -66,6 +124,22 @@ func (ml *mountedLayer) Changes() ([]archive.Change, error) {
 	return ml.layerStore.driver.Changes(ml.mountID, ml.cacheParent())
 }
 
+func (ml *mountedLayer) WalkChanges(fn func(archive.Change) error) error {
+	if walker, ok := ml.layerStore.driver.(graphdriver.ChangeWalker); ok {
+		return walker.WalkChanges(ml.mountID, ml.cacheParent(), fn)
+	}
+	changes, err := ml.Changes()
+	if err != nil {
+		return err
+	}
+	for _, change := range changes {
+		if err := fn(change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (ml *mountedLayer) Metadata() (map[string]string, error) {
 	return ml.layerStore.driver.GetMetadata(ml.mountID)
 }
@@ -2,27 +2,153 @@ package libcontainerd
 
 import (
 	"fmt"
+	"runtime"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/docker/docker/pkg/locker"
 )
 
+// stateLockTimeout is how long State waits for the lock of a container
+// before giving up on reading the container.
+const stateLockTimeout = time.Second
+
 // clientCommon contains the platform agnostic fields used in the client structure
 type clientCommon struct {
 	backend    Backend
 	containers map[string]*container
 	locker     *locker.Locker
 	mapMutex   sync.RWMutex // protects read/write oprations from containers map
+
+	// operations are the operations holding or waiting for the lock of
+	// each container, reported by State.
+	operations map[string][]*Operation
+	opsMutex   sync.Mutex
 }
 
 func (clnt *client) lock(containerID string) {
+	op := &Operation{Name: callerName(), Since: time.Now()}
+	clnt.opsMutex.Lock()
+	if clnt.operations == nil {
+		clnt.operations = make(map[string][]*Operation)
+	}
+	clnt.operations[containerID] = append(clnt.operations[containerID], op)
+	clnt.opsMutex.Unlock()
+
 	clnt.locker.Lock(containerID)
+
+	clnt.opsMutex.Lock()
+	op.Holding = true
+	op.Since = time.Now()
+	clnt.opsMutex.Unlock()
 }
 
 func (clnt *client) unlock(containerID string) {
+	clnt.opsMutex.Lock()
+	ops := clnt.operations[containerID]
+	for i, op := range ops {
+		if op.Holding {
+			ops = append(ops[:i], ops[i+1:]...)
+			break
+		}
+	}
+	if len(ops) == 0 {
+		delete(clnt.operations, containerID)
+	} else {
+		clnt.operations[containerID] = ops
+	}
+	clnt.opsMutex.Unlock()
+
 	clnt.locker.Unlock(containerID)
 }
 
+// callerName returns the name of the function that called the caller of
+// callerName, such as "(*client).Signal".
+func callerName() string {
+	pc, _, _, ok := runtime.Caller(2)
+	if !ok {
+		return "unknown"
+	}
+	name := runtime.FuncForPC(pc).Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.TrimPrefix(name, "libcontainerd.")
+}
+
+// State returns the state of the containers of the client and the
+// operations on them, sorted by container ID. The lock of each container
+// is only waited for stateLockTimeout, so that the operations stuck while
+// holding it do not block the dump of the state.
+func (clnt *client) State() []ContainerState {
+	ids := make(map[string]struct{})
+	clnt.mapMutex.RLock()
+	for id := range clnt.containers {
+		ids[id] = struct{}{}
+	}
+	clnt.mapMutex.RUnlock()
+	clnt.opsMutex.Lock()
+	for id := range clnt.operations {
+		ids[id] = struct{}{}
+	}
+	clnt.opsMutex.Unlock()
+
+	states := make([]ContainerState, 0, len(ids))
+	for id := range ids {
+		state := ContainerState{ID: id}
+		if clnt.tryLock(id, stateLockTimeout) {
+			if ctr, err := clnt.getContainer(id); err == nil {
+				state.Pid = ctr.systemPid
+				state.Restarting = ctr.restarting
+				for name := range ctr.processes {
+					state.Processes = append(state.Processes, name)
+				}
+				sort.Strings(state.Processes)
+			}
+			clnt.locker.Unlock(id)
+		} else {
+			state.Locked = true
+		}
+		clnt.opsMutex.Lock()
+		for _, op := range clnt.operations[id] {
+			state.Operations = append(state.Operations, *op)
+		}
+		clnt.opsMutex.Unlock()
+		states = append(states, state)
+	}
+	sort.Sort(byContainerID(states))
+	return states
+}
+
+// tryLock takes the lock of a container without recording an operation,
+// unless it is not acquired within timeout. The lock is then released as
+// soon as it is acquired.
+func (clnt *client) tryLock(containerID string, timeout time.Duration) bool {
+	acquired := make(chan struct{})
+	go func() {
+		clnt.locker.Lock(containerID)
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		return true
+	case <-time.After(timeout):
+		go func() {
+			<-acquired
+			clnt.locker.Unlock(containerID)
+		}()
+		return false
+	}
+}
+
+type byContainerID []ContainerState
+
+func (s byContainerID) Len() int           { return len(s) }
+func (s byContainerID) Less(i, j int) bool { return s[i].ID < s[j].ID }
+func (s byContainerID) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
 // must hold a lock for cont.containerID
 func (clnt *client) appendContainer(cont *container) {
 	clnt.mapMutex.Lock()
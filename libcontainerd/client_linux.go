@@ -8,15 +8,29 @@ import (
 	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	containerd "github.com/docker/containerd/api/grpc/types"
 	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/docker/pkg/mount"
+	"github.com/docker/docker/pkg/requestid"
+	"github.com/docker/docker/pkg/tracing"
 	"github.com/opencontainers/specs/specs-go"
 	"golang.org/x/net/context"
 )
 
+const (
+	// containerdCreateTimeout and containerdAddProcessTimeout bound the
+	// containerd calls creating a container and starting a process in it,
+	// which would otherwise wait for as long as their caller does.
+	containerdCreateTimeout     = 2 * time.Minute
+	containerdAddProcessTimeout = time.Minute
+	// abandonedKillTimeout bounds the kill of a container or a process
+	// started for a caller which has since gone away.
+	abandonedKillTimeout = 10 * time.Second
+)
+
 type client struct {
 	clientCommon
 
@@ -26,7 +40,7 @@ type client struct {
 	exitNotifiers map[string]*exitNotifier
 }
 
-func (clnt *client) AddProcess(containerID, processFriendlyName string, specp Process) error {
+func (clnt *client) AddProcess(ctx context.Context, containerID, processFriendlyName string, specp Process) error {
 	clnt.lock(containerID)
 	defer clnt.unlock(containerID)
 	container, err := clnt.getContainer(containerID)
@@ -87,10 +101,22 @@ func (clnt *client) AddProcess(containerID, processFriendlyName string, specp Pr
 		return err
 	}
 
-	if _, err := clnt.remote.apiClient.AddProcess(context.Background(), r); err != nil {
+	begin := time.Now()
+	span, _ := tracing.StartSpan(ctx, "containerd AddProcess")
+	addCtx, cancel := context.WithTimeout(ctx, containerdAddProcessTimeout)
+	_, err = clnt.remote.apiClient.AddProcess(addCtx, r)
+	if err != nil && addCtx.Err() != nil {
+		// containerd may have started the process before the call was
+		// cancelled or timed out
+		clnt.killAbandoned(containerID, processFriendlyName)
+	}
+	cancel()
+	span.Finish(err)
+	if err != nil {
 		p.closeFifos(iopipe)
 		return err
 	}
+	requestid.Logger(ctx).WithField("container", containerID).Debugf("containerd started the process %s in %s", processFriendlyName, time.Since(begin))
 
 	container.processes[processFriendlyName] = p
 
@@ -129,7 +155,7 @@ func (clnt *client) prepareBundleDir(uid, gid int) (string, error) {
 	return p, nil
 }
 
-func (clnt *client) Create(containerID string, spec Spec, options ...CreateOption) (err error) {
+func (clnt *client) Create(ctx context.Context, containerID string, spec Spec, options ...CreateOption) (err error) {
 	clnt.lock(containerID)
 	defer clnt.unlock(containerID)
 
@@ -176,13 +202,30 @@ func (clnt *client) Create(containerID string, spec Spec, options ...CreateOptio
 		return err
 	}
 
-	return container.start()
+	return container.start(ctx)
+}
+
+// killAbandoned kills the process processFriendlyName of the container
+// containerID, which containerd may have started for a caller which has
+// since gone away. Killing the init process of a container makes containerd
+// delete the container.
+func (clnt *client) killAbandoned(containerID, processFriendlyName string) {
+	ctx, cancel := context.WithTimeout(context.Background(), abandonedKillTimeout)
+	defer cancel()
+	_, err := clnt.remote.apiClient.Signal(ctx, &containerd.SignalRequest{
+		Id:     containerID,
+		Pid:    processFriendlyName,
+		Signal: uint32(syscall.SIGKILL),
+	})
+	if err != nil {
+		logrus.Warnf("Failed to kill abandoned process %s of %s: %v", processFriendlyName, containerID, err)
+	}
 }
 
-func (clnt *client) Signal(containerID string, sig int) error {
+func (clnt *client) Signal(ctx context.Context, containerID string, sig int) error {
 	clnt.lock(containerID)
 	defer clnt.unlock(containerID)
-	_, err := clnt.remote.apiClient.Signal(context.Background(), &containerd.SignalRequest{
+	_, err := clnt.remote.apiClient.Signal(ctx, &containerd.SignalRequest{
 		Id:     containerID,
 		Pid:    InitFriendlyName,
 		Signal: uint32(sig),
@@ -301,6 +344,21 @@ func (clnt *client) GetPidsForContainer(containerID string) ([]int, error) {
 	return pids, nil
 }
 
+// GetPidForProcess returns the process ID on the host of the process
+// processFriendlyName running in a container.
+func (clnt *client) GetPidForProcess(containerID, processFriendlyName string) (int, error) {
+	cont, err := clnt.getContainerdContainer(containerID)
+	if err != nil {
+		return 0, err
+	}
+	for _, p := range cont.Processes {
+		if p.Pid == processFriendlyName {
+			return int(p.SystemPid), nil
+		}
+	}
+	return 0, fmt.Errorf("no such process %s in container %s", processFriendlyName, containerID)
+}
+
 // Summary returns a summary of the processes running in a container.
 // This is a no-op on Linux.
 func (clnt *client) Summary(containerID string) ([]Summary, error) {
@@ -400,3 +458,42 @@ func (en *exitNotifier) close() {
 func (en *exitNotifier) wait() <-chan struct{} {
 	return en.c
 }
+
+// exitedProcesses returns the exec'd processes of ctr that containerd no
+// longer knows about. Caller needs to lock container ID before calling this
+// method.
+func (clnt *client) exitedProcesses(ctr *container) ([]string, error) {
+	cont, err := clnt.getContainerdContainer(ctr.containerID)
+	if err != nil {
+		return nil, err
+	}
+	live := make(map[string]struct{}, len(cont.Processes))
+	for _, p := range cont.Processes {
+		live[p.Pid] = struct{}{}
+	}
+	var exited []string
+	for name := range ctr.processes {
+		if _, ok := live[name]; !ok {
+			exited = append(exited, name)
+		}
+	}
+	return exited, nil
+}
+
+// reapProcess forgets about an exec'd process whose exit was never reported
+// and notifies the backend that it exited. Caller needs to lock container ID
+// before calling this method.
+func (clnt *client) reapProcess(ctr *container, processFriendlyName string) {
+	ctr.cleanProcess(processFriendlyName)
+	st := StateInfo{
+		CommonStateInfo: CommonStateInfo{
+			State:     StateExitProcess,
+			ExitCode:  unknownExitCode,
+			ProcessID: processFriendlyName,
+		}}
+	clnt.q.append(ctr.containerID, func() {
+		if err := clnt.backend.StateChanged(ctr.containerID, st); err != nil {
+			logrus.Error(err)
+		}
+	})
+}
@@ -0,0 +1,195 @@
+package libcontainerd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Microsoft/hcsshim"
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/pkg/ioutils"
+)
+
+// containerState is the state of a running container the client saves in
+// its state directory, so that it can reattach to its compute system after
+// a restart of the daemon.
+type containerState struct {
+	Spec      Spec
+	Pid       uint32
+	StartedAt time.Time
+	Processes map[string]processState
+}
+
+// processState is the state of an exec'd process of a running container.
+type processState struct {
+	Pid         uint32
+	CommandLine string
+	Terminal    bool
+}
+
+func (clnt *client) statePath(containerID string) string {
+	return filepath.Join(clnt.stateDir, containerID+".json")
+}
+
+// saveState saves the state of the running container ctr. Caller needs to
+// lock container ID before calling this method.
+func (clnt *client) saveState(ctr *container) {
+	if clnt.stateDir == "" {
+		return
+	}
+	st := containerState{
+		Spec:      ctr.ociSpec,
+		Pid:       ctr.systemPid,
+		StartedAt: ctr.startedAt,
+		Processes: make(map[string]processState),
+	}
+	for name, p := range ctr.processes {
+		st.Processes[name] = processState{Pid: p.systemPid, CommandLine: p.commandLine, Terminal: p.terminal}
+	}
+	b, err := json.Marshal(st)
+	if err == nil {
+		err = ioutils.AtomicWriteFile(clnt.statePath(ctr.containerID), b, 0600)
+	}
+	if err != nil {
+		logrus.Warnf("Failed to save the state of container %s: %v", ctr.containerID, err)
+	}
+}
+
+// removeState removes the saved state of the container containerID.
+func (clnt *client) removeState(containerID string) {
+	if clnt.stateDir == "" {
+		return
+	}
+	if err := os.Remove(clnt.statePath(containerID)); err != nil && !os.IsNotExist(err) {
+		logrus.Warnf("Failed to remove the state of container %s: %v", containerID, err)
+	}
+}
+
+// loadRestorable loads, when the client starts, the saved state of the
+// containers whose compute systems are still running, to be reattached when
+// they are restored. The state of the others is removed.
+func (clnt *client) loadRestorable() {
+	clnt.restorable = make(map[string]*containerState)
+	if clnt.stateDir == "" {
+		return
+	}
+	files, err := ioutil.ReadDir(clnt.stateDir)
+	if err != nil || len(files) == 0 {
+		return
+	}
+
+	running := make(map[string]bool)
+	systems, err := hcsshim.EnumerateComputeSystems(DefaultOwner)
+	if err != nil {
+		logrus.Warnf("Could not list the compute systems to reattach to: %v", err)
+		return
+	}
+	for _, cs := range systems {
+		if !cs.Stopped {
+			running[cs.ID] = true
+		}
+	}
+
+	for _, f := range files {
+		containerID := strings.TrimSuffix(f.Name(), ".json")
+		if f.IsDir() || containerID == f.Name() {
+			continue
+		}
+		if !running[containerID] {
+			clnt.removeState(containerID)
+			continue
+		}
+		b, err := ioutil.ReadFile(clnt.statePath(containerID))
+		if err != nil {
+			logrus.Warnf("Failed to read the state of container %s: %v", containerID, err)
+			continue
+		}
+		st := &containerState{}
+		if err := json.Unmarshal(b, st); err != nil {
+			logrus.Warnf("Failed to read the state of container %s: %v", containerID, err)
+			clnt.removeState(containerID)
+			continue
+		}
+		clnt.restorable[containerID] = st
+	}
+}
+
+// reattach tracks again the running compute system of the container
+// containerID, from the state saved by a previous run of the daemon: its
+// init and exec'd processes are waited for again, and it is restarted by its
+// restart policy when it exits. The stdio of its processes can't be opened
+// again, HCS only returning it when a process is created, so the streams
+// attached to the backend have no output.
+func (clnt *client) reattach(containerID string, st *containerState, options ...CreateOption) error {
+	clnt.lock(containerID)
+	defer clnt.unlock(containerID)
+	if _, err := clnt.getContainer(containerID); err == nil {
+		return nil
+	}
+
+	ctr := &container{
+		containerCommon: containerCommon{
+			process: process{
+				processCommon: processCommon{
+					containerID:  containerID,
+					client:       clnt,
+					friendlyName: InitFriendlyName,
+				},
+				commandLine: strings.Join(st.Spec.Process.Args, " "),
+				terminal:    st.Spec.Process.Terminal,
+			},
+			processes: make(map[string]*process),
+			startedAt: st.StartedAt,
+		},
+		ociSpec: st.Spec,
+		options: options,
+	}
+	for _, option := range options {
+		if err := option.Apply(ctr); err != nil {
+			logrus.Error(err)
+		}
+	}
+	ctr.systemPid = st.Pid
+	for name, ps := range st.Processes {
+		p := ctr.newProcess(name)
+		p.systemPid = ps.Pid
+		p.commandLine = ps.CommandLine
+		p.terminal = ps.Terminal
+		ctr.processes[name] = p
+	}
+	clnt.appendContainer(ctr)
+
+	// Make sure the lock is not held while calling back into the daemon
+	clnt.unlock(containerID)
+	err := clnt.backend.AttachStreams(containerID, IOPipe{Terminal: st.Spec.Process.Terminal})
+	clnt.lock(containerID)
+	if err != nil {
+		clnt.deleteContainer(containerID)
+		return err
+	}
+
+	go ctr.waitExit(ctr.systemPid, InitFriendlyName, true)
+	for name, p := range ctr.processes {
+		go ctr.waitExit(p.systemPid, name, false)
+	}
+
+	logrus.Debugf("lcd reattached to the compute system of container %s, init pid %d, %d exec'd processes", containerID, ctr.systemPid, len(ctr.processes))
+	return clnt.backend.StateChanged(containerID, StateInfo{
+		CommonStateInfo: CommonStateInfo{
+			State: StateRestore,
+			Pid:   ctr.systemPid,
+		}})
+}
+
+// takeRestorable returns, and forgets, the saved state of the container
+// containerID loaded when the client started, if any.
+func (clnt *client) takeRestorable(containerID string) *containerState {
+	clnt.restorableMu.Lock()
+	defer clnt.restorableMu.Unlock()
+	st := clnt.restorable[containerID]
+	delete(clnt.restorable, containerID)
+	return st
+}
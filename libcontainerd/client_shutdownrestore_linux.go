@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/Sirupsen/logrus"
+	"golang.org/x/net/context"
 )
 
 func (clnt *client) Restore(containerID string, options ...CreateOption) error {
@@ -20,12 +21,12 @@ func (clnt *client) Restore(containerID string, options ...CreateOption) error {
 		clnt.appendContainer(container)
 		clnt.unlock(cont.Id)
 
-		if err := clnt.Signal(containerID, int(syscall.SIGTERM)); err != nil {
+		if err := clnt.Signal(context.Background(), containerID, int(syscall.SIGTERM)); err != nil {
 			logrus.Errorf("error sending sigterm to %v: %v", containerID, err)
 		}
 		select {
 		case <-time.After(10 * time.Second):
-			if err := clnt.Signal(containerID, int(syscall.SIGKILL)); err != nil {
+			if err := clnt.Signal(context.Background(), containerID, int(syscall.SIGKILL)); err != nil {
 				logrus.Errorf("error sending sigkill to %v: %v", containerID, err)
 			}
 			select {
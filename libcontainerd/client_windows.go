@@ -7,16 +7,28 @@ import (
 	"io"
 	"path/filepath"
 	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/Microsoft/hcsshim"
 	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/pkg/tracing"
+	"golang.org/x/net/context"
 )
 
 type client struct {
 	clientCommon
 
-	// Platform specific properties below here (none presently on Windows)
+	// Platform specific properties below here.
+
+	// stateDir is where the state of the running containers is saved, to
+	// reattach to their compute systems after a restart of the daemon.
+	stateDir string
+	// restorable is the state of the containers whose compute systems were
+	// found running when the client started, until they are restored.
+	restorable   map[string]*containerState
+	restorableMu sync.Mutex
 }
 
 // Win32 error codes that are used for various workarounds
@@ -27,6 +39,7 @@ const (
 	ErrorNoNetwork     = syscall.Errno(1222)       // The network is not present or not started
 	ErrorBadPathname   = syscall.Errno(161)        // The specified path is invalid
 	ErrorInvalidObject = syscall.Errno(0x800710D8) // The object identifier does not represent a valid object
+	ErrorTimeout       = syscall.Errno(1460)       // The operation returned because the timeout period expired
 )
 
 type layer struct {
@@ -86,6 +99,9 @@ type containerInit struct {
 	Layers                  []layer     // List of storage layers
 	ProcessorWeight         uint64      `json:",omitempty"` // CPU Shares 0..10000 on Windows; where 0 will be omitted and HCS will default.
 	ProcessorMaximum        int64       `json:",omitempty"` // CPU maximum usage percent 1..100
+	ProcessorGroup          uint16      `json:",omitempty"` // Processor group of the processors of ProcessorAffinity
+	ProcessorAffinity       uint64      `json:",omitempty"` // Mask of the processors of ProcessorGroup the container runs on
+	CPUGroupID              string      `json:",omitempty"` // CPU group of the host that runs a Hyper-V container
 	StorageIOPSMaximum      uint64      `json:",omitempty"` // Maximum Storage IOPS
 	StorageBandwidthMaximum uint64      `json:",omitempty"` // Maximum Storage Bandwidth in bytes per second
 	StorageSandboxSize      uint64      `json:",omitempty"` // Size in bytes that the container system drive should be expanded to if smaller
@@ -99,20 +115,20 @@ type containerInit struct {
 	Servicing               bool        // True if this container is for servicing
 }
 
-// defaultOwner is a tag passed to HCS to allow it to differentiate between
+// DefaultOwner is a tag passed to HCS to allow it to differentiate between
 // container creator management stacks. We hard code "docker" in the case
 // of docker.
-const defaultOwner = "docker"
+const DefaultOwner = "docker"
 
 // Create is the entrypoint to create a container from a spec, and if successfully
 // created, start it too.
-func (clnt *client) Create(containerID string, spec Spec, options ...CreateOption) error {
+func (clnt *client) Create(ctx context.Context, containerID string, spec Spec, options ...CreateOption) error {
 	logrus.Debugln("LCD client.Create() with spec", spec)
 
 	cu := &containerInit{
 		SystemType: "Container",
 		Name:       containerID,
-		Owner:      defaultOwner,
+		Owner:      DefaultOwner,
 
 		VolumePath:              spec.Root.Path,
 		IgnoreFlushesDuringBoot: spec.Windows.FirstStart,
@@ -132,6 +148,13 @@ func (clnt *client) Create(containerID string, spec Spec, options ...CreateOptio
 			if spec.Windows.Resources.CPU.Percent != nil {
 				cu.ProcessorMaximum = *spec.Windows.Resources.CPU.Percent * 100 // ProcessorMaximum is a value between 1 and 10000
 			}
+			if spec.Windows.Resources.CPU.Affinity != nil {
+				cu.ProcessorAffinity = *spec.Windows.Resources.CPU.Affinity
+				if spec.Windows.Resources.CPU.Group != nil {
+					cu.ProcessorGroup = *spec.Windows.Resources.CPU.Group
+				}
+			}
+			cu.CPUGroupID = spec.Windows.Resources.CPU.CPUGroupID
 		}
 		if spec.Windows.Resources.Memory != nil {
 			if spec.Windows.Resources.Memory.Limit != nil {
@@ -201,7 +224,22 @@ func (clnt *client) Create(containerID string, spec Spec, options ...CreateOptio
 
 	// Create the compute system
 	configuration := string(configurationb)
-	if err := hcsshim.CreateComputeSystem(containerID, configuration); err != nil {
+	span, _ := tracing.StartSpan(ctx, "hcs CreateComputeSystem")
+	err = callWithContext(ctx, func() error {
+		return hcsshim.CreateComputeSystem(containerID, configuration)
+	}, func(err error) {
+		if err == nil {
+			terminateAbandoned(containerID)
+		}
+	})
+	span.Finish(err)
+	if err != nil {
+		return err
+	}
+
+	// Don't start the container if the caller has gone away meanwhile
+	if err := ctx.Err(); err != nil {
+		terminateAbandoned(containerID)
 		return err
 	}
 
@@ -215,6 +253,7 @@ func (clnt *client) Create(containerID string, spec Spec, options ...CreateOptio
 					friendlyName: InitFriendlyName,
 				},
 				commandLine: strings.Join(spec.Process.Args, " "),
+				terminal:    spec.Process.Terminal,
 			},
 			processes: make(map[string]*process),
 		},
@@ -232,7 +271,7 @@ func (clnt *client) Create(containerID string, spec Spec, options ...CreateOptio
 	// internal structure, and also keep HCS in sync by deleting the
 	// container there.
 	logrus.Debugf("Create() id=%s, Calling start()", containerID)
-	if err := container.start(); err != nil {
+	if err := container.start(ctx); err != nil {
 		clnt.deleteContainer(containerID)
 		return err
 	}
@@ -244,7 +283,7 @@ func (clnt *client) Create(containerID string, spec Spec, options ...CreateOptio
 
 // AddProcess is the handler for adding a process to an already running
 // container. It's called through docker exec.
-func (clnt *client) AddProcess(containerID, processFriendlyName string, procToAdd Process) error {
+func (clnt *client) AddProcess(ctx context.Context, containerID, processFriendlyName string, procToAdd Process) error {
 
 	clnt.lock(containerID)
 	defer clnt.unlock(containerID)
@@ -258,6 +297,14 @@ func (clnt *client) AddProcess(containerID, processFriendlyName string, procToAd
 		ConsoleSize:    procToAdd.InitialConsoleSize,
 	}
 
+	// The console may have been resized before the process was started.
+	if size, ok := container.consoleSizes[processFriendlyName]; ok {
+		delete(container.consoleSizes, processFriendlyName)
+		if procToAdd.Terminal {
+			createProcessParms.ConsoleSize = size
+		}
+	}
+
 	// Take working directory from the process to add if it is defined,
 	// otherwise take from the first process.
 	if procToAdd.Cwd != "" {
@@ -280,12 +327,31 @@ func (clnt *client) AddProcess(containerID, processFriendlyName string, procToAd
 	var stdout, stderr io.ReadCloser
 	var pid uint32
 	iopipe := &IOPipe{Terminal: procToAdd.Terminal}
-	pid, iopipe.Stdin, stdout, stderr, err = hcsshim.CreateProcessInComputeSystem(
-		containerID,
-		true,
-		true,
-		!procToAdd.Terminal,
-		createProcessParms)
+	span, _ := tracing.StartSpan(ctx, "hcs CreateProcessInComputeSystem")
+	err = callWithContext(ctx, func() error {
+		var err error
+		pid, iopipe.Stdin, stdout, stderr, err = hcsshim.CreateProcessInComputeSystem(
+			containerID,
+			true,
+			true,
+			!procToAdd.Terminal,
+			createProcessParms)
+		return err
+	}, func(err error) {
+		if err != nil {
+			return
+		}
+		// Nobody is going to attach to the process; get rid of it.
+		for _, c := range []io.Closer{iopipe.Stdin, stdout, stderr} {
+			if c != nil {
+				c.Close()
+			}
+		}
+		if err := hcsshim.TerminateProcessInComputeSystem(containerID, pid); err != nil {
+			logrus.Warnf("Failed to terminate abandoned pid %d in %s: %q", pid, containerID, err)
+		}
+	})
+	span.Finish(err)
 	if err != nil {
 		logrus.Errorf("AddProcess %s CreateProcessInComputeSystem() failed %s", containerID, err)
 		return err
@@ -309,29 +375,42 @@ func (clnt *client) AddProcess(containerID, processFriendlyName string, procToAd
 				systemPid:    pid,
 			},
 			commandLine: createProcessParms.CommandLine,
+			terminal:    procToAdd.Terminal,
 		}
+	clnt.saveState(container)
 
 	// Make sure the lock is not held while calling back into the daemon
 	clnt.unlock(containerID)
 
 	// Tell the engine to attach streams back to the client
-	if err := clnt.backend.AttachStreams(processFriendlyName, *iopipe); err != nil {
-		return err
-	}
+	err = clnt.backend.AttachStreams(processFriendlyName, *iopipe)
 
 	// Lock again so that the defer unlock doesn't fail. (I really don't like this code)
 	clnt.lock(containerID)
 
+	if err != nil {
+		// Nobody waits for the process; don't leave it running untracked.
+		delete(container.processes, processFriendlyName)
+		delete(container.consoleSizes, processFriendlyName)
+		clnt.saveState(container)
+		if err := hcsshim.TerminateProcessInComputeSystem(containerID, pid); err != nil {
+			logrus.Warnf("Failed to terminate pid %d in %s after failing to attach its streams: %q", pid, containerID, err)
+		}
+		return err
+	}
+
 	// Spin up a go routine waiting for exit to handle cleanup
 	go container.waitExit(pid, processFriendlyName, false)
 
 	return nil
 }
 
-// Signal handles `docker stop` on Windows. While Linux has support for
-// the full range of signals, signals aren't really implemented on Windows.
-// We fake supporting regular stop and -9 to force kill.
-func (clnt *client) Signal(containerID string, sig int) error {
+// Signal handles `docker stop` and `docker kill` on Windows. While Linux has
+// support for the full range of signals, signals aren't really implemented
+// on Windows. We fake supporting -9 to force kill, and SIGINT to interrupt a
+// process with a terminal by typing CTRL+C on its console. Any other signal,
+// or SIGINT when the process can't be interrupted, does a regular stop.
+func (clnt *client) Signal(ctx context.Context, containerID string, sig int) error {
 	var (
 		cont *container
 		err  error
@@ -344,31 +423,61 @@ func (clnt *client) Signal(containerID string, sig int) error {
 		return err
 	}
 
-	cont.manualStopRequested = true
-
 	logrus.Debugf("lcd: Signal() containerID=%s sig=%d pid=%d", containerID, sig, cont.systemPid)
-	context := fmt.Sprintf("Signal: sig=%d pid=%d", sig, cont.systemPid)
+	hcsContext := fmt.Sprintf("Signal: sig=%d pid=%d", sig, cont.systemPid)
+
+	if syscall.Signal(sig) == syscall.SIGINT && cont.terminal && cont.stdin != nil {
+		// The process may handle the interruption and carry on, so this
+		// isn't a manual stop.
+		err := cont.stdin.interrupt()
+		if err == nil {
+			return nil
+		}
+		logrus.Debugf("lcd: cannot interrupt pid %d in %s, stopping it: %v", cont.systemPid, containerID, err)
+	}
+
+	cont.manualStopRequested = true
 
 	if syscall.Signal(sig) == syscall.SIGKILL {
 		// Terminate the compute system
-		if err := hcsshim.TerminateComputeSystem(containerID, hcsshim.TimeoutInfinite, context); err != nil {
+		err = callWithContext(ctx, func() error {
+			return hcsshim.TerminateComputeSystem(containerID, hcsshim.TimeoutInfinite, hcsContext)
+		}, nil)
+		if err != nil && err != ctx.Err() {
 			logrus.Errorf("Failed to terminate %s - %q", containerID, err)
+			err = nil
 		}
 	} else {
 		// Terminate Process
-		if err = hcsshim.TerminateProcessInComputeSystem(containerID, cont.systemPid); err != nil {
-			logrus.Warnf("Failed to terminate pid %d in %s: %q", cont.systemPid, containerID, err)
+		pid := cont.systemPid
+		err = callWithContext(ctx, func() error {
+			return hcsshim.TerminateProcessInComputeSystem(containerID, pid)
+		}, nil)
+		if err != nil && err != ctx.Err() {
+			logrus.Warnf("Failed to terminate pid %d in %s: %q", pid, containerID, err)
 			// Ignore errors
 			err = nil
 		}
 	}
 
-	return nil
+	return err
+}
+
+// terminateAbandoned terminates a compute system that was created for a
+// caller which has since gone away.
+func terminateAbandoned(containerID string) {
+	if err := hcsshim.TerminateComputeSystem(containerID, hcsshim.TimeoutInfinite, "abandoned"); err != nil {
+		logrus.Warnf("Failed to terminate abandoned compute system %s: %q", containerID, err)
+	}
 }
 
 // Resize handles a CLI event to resize an interactive docker run or docker exec
 // window.
 func (clnt *client) Resize(containerID, processFriendlyName string, width, height int) error {
+	if width <= 0 || height <= 0 {
+		return nil
+	}
+
 	// Get the libcontainerd container object
 	clnt.lock(containerID)
 	defer clnt.unlock(containerID)
@@ -377,41 +486,127 @@ func (clnt *client) Resize(containerID, processFriendlyName string, width, heigh
 		return err
 	}
 
-	if processFriendlyName == InitFriendlyName {
-		logrus.Debugln("Resizing systemPID in", containerID, cont.process.systemPid)
-		return hcsshim.ResizeConsoleInComputeSystem(containerID, cont.process.systemPid, height, width)
-	}
-
-	for _, p := range cont.processes {
-		if p.friendlyName == processFriendlyName {
-			logrus.Debugln("Resizing exec'd process", containerID, p.systemPid)
-			return hcsshim.ResizeConsoleInComputeSystem(containerID, p.systemPid, height, width)
+	p := &cont.process
+	if processFriendlyName != InitFriendlyName {
+		var ok bool
+		if p, ok = cont.processes[processFriendlyName]; !ok {
+			// The client resizes the console of an exec'd process as soon as
+			// it attaches to it, which can be before it is started. The
+			// daemon only resizes the processes it is adding or has added,
+			// and the size is dropped when AddProcess fails or the process
+			// exits.
+			logrus.Debugf("Resizing the console of exec'd process %s of %s once started", processFriendlyName, containerID)
+			if cont.consoleSizes == nil {
+				cont.consoleSizes = make(map[string][2]int)
+			}
+			cont.consoleSizes[processFriendlyName] = [2]int{height, width}
+			return nil
 		}
 	}
+	if !p.terminal {
+		return nil
+	}
 
-	return fmt.Errorf("Resize could not find containerID %s to resize", containerID)
-
+	logrus.Debugln("Resizing process", processFriendlyName, "in", containerID, p.systemPid)
+	return hcsshim.ResizeConsoleInComputeSystem(containerID, p.systemPid, height, width)
 }
 
-// Pause handles pause requests for containers
+// Pause handles pause requests for containers. Only the compute systems of
+// Hyper-V containers can be paused.
 func (clnt *client) Pause(containerID string) error {
-	return errors.New("Windows: Containers cannot be paused")
+	return clnt.setPaused(containerID, true)
 }
 
 // Resume handles resume requests for containers
 func (clnt *client) Resume(containerID string) error {
-	return errors.New("Windows: Containers cannot be paused")
+	return clnt.setPaused(containerID, false)
+}
+
+// setPaused pauses or resumes the compute system of the Hyper-V container
+// containerID, and tells the backend.
+func (clnt *client) setPaused(containerID string, pause bool) error {
+	clnt.lock(containerID)
+	cont, err := clnt.getContainer(containerID)
+	if err != nil {
+		clnt.unlock(containerID)
+		return err
+	}
+	if !cont.isHyperV() {
+		clnt.unlock(containerID)
+		return errors.New("Windows: Only Hyper-V containers can be paused, this container runs with process isolation")
+	}
+
+	state := StatePause
+	if pause {
+		if cont.paused {
+			clnt.unlock(containerID)
+			return fmt.Errorf("container %s is already paused", containerID)
+		}
+		err = hcsshim.PauseComputeSystem(containerID, hcsshim.TimeoutInfinite)
+	} else {
+		if !cont.paused {
+			clnt.unlock(containerID)
+			return fmt.Errorf("container %s is not paused", containerID)
+		}
+		state = StateResume
+		err = hcsshim.ResumeComputeSystem(containerID, hcsshim.TimeoutInfinite)
+	}
+	if err != nil {
+		clnt.unlock(containerID)
+		return err
+	}
+	cont.paused = pause
+	clnt.unlock(containerID)
+
+	return clnt.backend.StateChanged(containerID, StateInfo{
+		CommonStateInfo: CommonStateInfo{
+			State: state,
+			Pid:   cont.systemPid,
+		}})
 }
 
-// Stats handles stats requests for containers
+// Stats handles stats requests for containers, querying the statistics of
+// the compute system of the container.
 func (clnt *client) Stats(containerID string) (*Stats, error) {
-	return nil, errors.New("Windows: Stats not implemented")
+	clnt.lock(containerID)
+	_, err := clnt.getContainer(containerID)
+	clnt.unlock(containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The lock isn't held while querying the compute system, which can take
+	// long enough to delay the exits of the processes of the container.
+	props, err := hcsshim.GetComputeSystemProperties(containerID, hcsshim.PropertyStatistics)
+	if err != nil {
+		return nil, err
+	}
+	if props.Statistics == nil {
+		return nil, fmt.Errorf("no statistics returned for the compute system of container %s", containerID)
+	}
+	return (*Stats)(props.Statistics), nil
 }
 
-// Restore is the handler for restoring a container
-func (clnt *client) Restore(containerID string, unusedOnWindows ...CreateOption) error {
-	// TODO Windows: Implement this. For now, just tell the backend the container exited.
+// Restore is the handler for restoring a container. The compute system of
+// a container left running by a previous run of the daemon is reattached to
+// if the client saved its state, or else adopted if the pid of its init
+// process is known. Otherwise the backend is told the container exited.
+func (clnt *client) Restore(containerID string, options ...CreateOption) error {
 	logrus.Debugf("lcd Restore %s", containerID)
+	if st := clnt.takeRestorable(containerID); st != nil {
+		if err := clnt.reattach(containerID, st, options...); err != nil {
+			logrus.Errorf("error reattaching to the compute system of %s: %v", containerID, err)
+		} else {
+			return nil
+		}
+	}
+	if props, err := hcsshim.GetComputeSystemProperties(containerID, 0); err == nil && !props.Stopped {
+		if err := clnt.adopt(containerID, options...); err != nil {
+			logrus.Errorf("error adopting the compute system of %s: %v", containerID, err)
+		} else {
+			return nil
+		}
+	}
 	return clnt.backend.StateChanged(containerID, StateInfo{
 		CommonStateInfo: CommonStateInfo{
 			State:    StateExit,
@@ -419,6 +614,51 @@ func (clnt *client) Restore(containerID string, unusedOnWindows ...CreateOption)
 		}})
 }
 
+// adopt tracks again the running compute system of the container
+// containerID, created by a previous run of the daemon, waiting for the exit
+// of its init process. Its streams can't be attached again, so its output
+// is lost, and it isn't restarted by its restart policy when it exits, as
+// its spec isn't known.
+func (clnt *client) adopt(containerID string, options ...CreateOption) error {
+	clnt.lock(containerID)
+	defer clnt.unlock(containerID)
+	if _, err := clnt.getContainer(containerID); err == nil {
+		return fmt.Errorf("container %s is already active", containerID)
+	}
+
+	ctr := &container{
+		containerCommon: containerCommon{
+			process: process{
+				processCommon: processCommon{
+					containerID:  containerID,
+					client:       clnt,
+					friendlyName: InitFriendlyName,
+				},
+			},
+			processes: make(map[string]*process),
+		},
+	}
+	for _, option := range options {
+		if err := option.Apply(ctr); err != nil {
+			logrus.Error(err)
+		}
+	}
+	if ctr.systemPid == 0 {
+		return fmt.Errorf("the pid of the init process of container %s is unknown", containerID)
+	}
+	ctr.restartManager = nil
+	ctr.startedAt = time.Now()
+	clnt.appendContainer(ctr)
+	go ctr.waitExit(ctr.systemPid, InitFriendlyName, true)
+
+	logrus.Debugf("lcd adopted the compute system of container %s, init pid %d", containerID, ctr.systemPid)
+	return clnt.backend.StateChanged(containerID, StateInfo{
+		CommonStateInfo: CommonStateInfo{
+			State: StateRestore,
+			Pid:   ctr.systemPid,
+		}})
+}
+
 // GetPidsForContainer returns a list of process IDs running in a container.
 // Although implemented, this is not used in Windows.
 func (clnt *client) GetPidsForContainer(containerID string) ([]int, error) {
@@ -439,6 +679,23 @@ func (clnt *client) GetPidsForContainer(containerID string) ([]int, error) {
 	return pids, nil
 }
 
+// GetPidForProcess returns the process ID of the process processFriendlyName
+// running in a container. It is a process ID of the host unless the
+// container is a Hyper-V container.
+func (clnt *client) GetPidForProcess(containerID, processFriendlyName string) (int, error) {
+	clnt.lock(containerID)
+	defer clnt.unlock(containerID)
+	cont, err := clnt.getContainer(containerID)
+	if err != nil {
+		return 0, err
+	}
+	p, ok := cont.processes[processFriendlyName]
+	if !ok {
+		return 0, fmt.Errorf("no such process %s in container %s", processFriendlyName, containerID)
+	}
+	return int(p.processCommon.systemPid), nil
+}
+
 // Summary returns a summary of the processes running in a container.
 // This is present in Windows to support docker top. In linux, the
 // engine shells out to ps to get process information. On Windows, as
@@ -454,10 +711,14 @@ func (clnt *client) Summary(containerID string) ([]Summary, error) {
 		return nil, err
 	}
 
-	// Add the first process
+	// Add the first process, whose command isn't known if it was adopted
+	var command string
+	if len(cont.ociSpec.Process.Args) > 0 {
+		command = cont.ociSpec.Process.Args[0]
+	}
 	s = append(s, Summary{
 		Pid:     cont.containerCommon.systemPid,
-		Command: cont.ociSpec.Process.Args[0]})
+		Command: command})
 	// And add all the exec'd processes
 	for _, p := range cont.processes {
 		s = append(s, Summary{
@@ -474,3 +735,42 @@ func (clnt *client) UpdateResources(containerID string, resources Resources) err
 	// but we should return nil for enabling updating container
 	return nil
 }
+
+// exitedProcesses returns the exec'd processes of ctr that are no longer
+// running in the compute system. Caller needs to lock container ID before
+// calling this method.
+func (clnt *client) exitedProcesses(ctr *container) ([]string, error) {
+	var exited []string
+	for name, p := range ctr.processes {
+		// A zero timeout returns immediately, with a timeout error if the
+		// process is still running.
+		_, err := hcsshim.WaitForProcessInComputeSystem(ctr.containerID, p.systemPid, 0)
+		if err != nil {
+			herr, ok := err.(*hcsshim.HcsError)
+			if !ok || herr.Err == ErrorTimeout || herr.Err == syscall.Errno(syscall.WAIT_TIMEOUT) {
+				continue
+			}
+		}
+		exited = append(exited, name)
+	}
+	return exited, nil
+}
+
+// reapProcess forgets about an exec'd process whose exit was never reported
+// and notifies the backend that it exited. Caller needs to lock container ID
+// before calling this method.
+func (clnt *client) reapProcess(ctr *container, processFriendlyName string) {
+	delete(ctr.processes, processFriendlyName)
+	si := StateInfo{
+		CommonStateInfo: CommonStateInfo{
+			State:     StateExitProcess,
+			ExitCode:  unknownExitCode,
+			ProcessID: processFriendlyName,
+		}}
+	// Make sure the lock is not held while calling back into the daemon
+	go func() {
+		if err := clnt.backend.StateChanged(ctr.containerID, si); err != nil {
+			logrus.Error(err)
+		}
+	}()
+}
@@ -38,3 +38,19 @@ func (rm restartManager) Apply(p interface{}) error {
 	}
 	return fmt.Errorf("WithRestartManager option not supported for this client")
 }
+
+// WithRestoredPid sets the pid of the init process of a container being
+// restored, as recorded by the daemon, for the runtimes that don't track it.
+func WithRestoredPid(pid int) CreateOption {
+	return restoredPid(pid)
+}
+
+type restoredPid int
+
+func (pid restoredPid) Apply(p interface{}) error {
+	if pr, ok := p.(*container); ok {
+		pr.systemPid = uint32(pid)
+		return nil
+	}
+	return fmt.Errorf("WithRestoredPid option not supported for this client")
+}
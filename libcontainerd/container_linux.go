@@ -10,6 +10,8 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	containerd "github.com/docker/containerd/api/grpc/types"
+	"github.com/docker/docker/pkg/requestid"
+	"github.com/docker/docker/pkg/tracing"
 	"github.com/docker/docker/restartmanager"
 	"github.com/opencontainers/specs/specs-go"
 	"golang.org/x/net/context"
@@ -65,7 +67,7 @@ func (ctr *container) spec() (*specs.Spec, error) {
 	return &spec, nil
 }
 
-func (ctr *container) start() error {
+func (ctr *container) start(ctx context.Context) error {
 	spec, err := ctr.spec()
 	if err != nil {
 		return nil
@@ -86,11 +88,22 @@ func (ctr *container) start() error {
 	}
 	ctr.client.appendContainer(ctr)
 
-	resp, err := ctr.client.remote.apiClient.CreateContainer(context.Background(), r)
+	begin := time.Now()
+	span, _ := tracing.StartSpan(ctx, "containerd CreateContainer")
+	createCtx, cancel := context.WithTimeout(ctx, containerdCreateTimeout)
+	resp, err := ctr.client.remote.apiClient.CreateContainer(createCtx, r)
+	if err != nil && createCtx.Err() != nil {
+		// containerd may have created the container before the call was
+		// cancelled or timed out
+		ctr.client.killAbandoned(ctr.containerID, InitFriendlyName)
+	}
+	cancel()
+	span.Finish(err)
 	if err != nil {
 		ctr.closeFifos(iopipe)
 		return err
 	}
+	requestid.Logger(ctx).WithField("container", ctr.containerID).Debugf("containerd created the container in %s", time.Since(begin))
 	ctr.startedAt = time.Now()
 
 	if err := ctr.client.backend.AttachStreams(ctr.containerID, *iopipe); err != nil {
@@ -160,7 +173,7 @@ func (ctr *container) handleEvent(e *containerd.Event) error {
 							logrus.Error(err)
 						}
 					} else {
-						ctr.start()
+						ctr.start(context.Background())
 					}
 				}()
 			}
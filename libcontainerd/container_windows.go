@@ -8,6 +8,8 @@ import (
 
 	"github.com/Microsoft/hcsshim"
 	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/pkg/tracing"
+	"golang.org/x/net/context"
 )
 
 type container struct {
@@ -22,6 +24,19 @@ type container struct {
 	ociSpec Spec
 
 	manualStopRequested bool
+
+	// paused is set while the compute system of a Hyper-V container is
+	// paused.
+	paused bool
+
+	// consoleSizes are the sizes, height and width, of the consoles of the
+	// processes resized before they were started, by friendly name.
+	consoleSizes map[string][2]int
+}
+
+// isHyperV returns whether the container runs in a Hyper-V partition.
+func (ctr *container) isHyperV() bool {
+	return ctr.ociSpec.Windows.HvRuntime != nil
 }
 
 func (ctr *container) newProcess(friendlyName string) *process {
@@ -34,13 +49,16 @@ func (ctr *container) newProcess(friendlyName string) *process {
 	}
 }
 
-func (ctr *container) start() error {
+func (ctr *container) start(ctx context.Context) error {
 	var err error
 
 	// Start the container.  If this is a servicing container, this call will block
 	// until the container is done with the servicing execution.
 	logrus.Debugln("Starting container ", ctr.containerID)
-	if err = hcsshim.StartComputeSystem(ctr.containerID); err != nil {
+	span, _ := tracing.StartSpan(ctx, "hcs StartComputeSystem")
+	err = hcsshim.StartComputeSystem(ctr.containerID)
+	span.Finish(err)
+	if err != nil {
 		logrus.Errorf("Failed to start compute system: %s", err)
 		return err
 	}
@@ -82,12 +100,14 @@ func (ctr *container) start() error {
 	// is only created if it we're not -t.
 	var pid uint32
 	var stdout, stderr io.ReadCloser
+	span, _ = tracing.StartSpan(ctx, "hcs CreateProcessInComputeSystem")
 	pid, iopipe.Stdin, stdout, stderr, err = hcsshim.CreateProcessInComputeSystem(
 		ctr.containerID,
 		true,
 		true,
 		!ctr.ociSpec.Process.Terminal,
 		createProcessParms)
+	span.Finish(err)
 	if err != nil {
 		logrus.Errorf("CreateProcessInComputeSystem() failed %s", err)
 
@@ -102,6 +122,10 @@ func (ctr *container) start() error {
 	}
 	ctr.startedAt = time.Now()
 
+	stdin := &processInput{WriteCloser: iopipe.Stdin}
+	iopipe.Stdin = stdin
+	ctr.process.stdin = stdin
+
 	// Convert io.ReadClosers to io.Readers
 	if stdout != nil {
 		iopipe.Stdout = openReaderFromPipe(stdout)
@@ -117,6 +141,7 @@ func (ctr *container) start() error {
 	// Spin up a go routine waiting for exit to handle cleanup
 	go ctr.waitExit(pid, InitFriendlyName, true)
 
+	ctr.client.saveState(ctr)
 	ctr.client.appendContainer(ctr)
 
 	if err := ctr.client.backend.AttachStreams(ctr.containerID, *iopipe); err != nil {
@@ -165,6 +190,18 @@ func (ctr *container) waitExit(pid uint32, processFriendlyName string, isFirstPr
 	// But it could have been an exec'd process which exited
 	if !isFirstProcessToStart {
 		si.State = StateExitProcess
+		ctr.client.lock(ctr.containerID)
+		_, tracked := ctr.processes[processFriendlyName]
+		delete(ctr.processes, processFriendlyName)
+		delete(ctr.consoleSizes, processFriendlyName)
+		if tracked {
+			ctr.client.saveState(ctr)
+		}
+		ctr.client.unlock(ctr.containerID)
+		if !tracked {
+			// The process was already reaped and reported as exited.
+			return nil
+		}
 	} else {
 		// Since this is the init process, always call into vmcompute.dll to
 		// shutdown the container after we have completed.
@@ -196,6 +233,7 @@ func (ctr *container) waitExit(pid uint32, processFriendlyName string, isFirstPr
 		} else {
 			logrus.Debugf("Completed shutting down container %s", ctr.containerID)
 		}
+		ctr.client.removeState(ctr.containerID)
 
 		if !ctr.manualStopRequested && ctr.restartManager != nil {
 			restart, wait, err := ctr.restartManager.ShouldRestart(uint32(exitCode), false, time.Since(ctr.startedAt))
@@ -215,7 +253,7 @@ func (ctr *container) waitExit(pid uint32, processFriendlyName string, isFirstPr
 						}
 						logrus.Error(err)
 					} else {
-						ctr.client.Create(ctr.containerID, ctr.ociSpec, ctr.options...)
+						ctr.client.Create(context.Background(), ctr.containerID, ctr.ociSpec, ctr.options...)
 					}
 				}()
 			}
@@ -1,7 +1,9 @@
 package libcontainerd
 
 import (
+	"errors"
 	"io"
+	"sync"
 )
 
 // process keeps the state for both main container process and exec process.
@@ -12,6 +14,47 @@ type process struct {
 
 	// commandLine is to support returning summary information for docker top
 	commandLine string
+
+	// terminal is set if the process runs with an emulated console, whose
+	// size can be changed.
+	terminal bool
+
+	// stdin is the input of the main process of a container, through which
+	// it is interrupted.
+	stdin *processInput
+}
+
+// processInput is the stdin pipe of a process, shared by the backend
+// copying the input of the client and the interruptions of the process.
+type processInput struct {
+	sync.Mutex
+	io.WriteCloser
+	closed bool
+}
+
+func (i *processInput) Write(p []byte) (int, error) {
+	i.Lock()
+	defer i.Unlock()
+	return i.WriteCloser.Write(p)
+}
+
+func (i *processInput) Close() error {
+	i.Lock()
+	defer i.Unlock()
+	i.closed = true
+	return i.WriteCloser.Close()
+}
+
+// interrupt types CTRL+C on the console of the process, which the console
+// turns into a CTRL_C event.
+func (i *processInput) interrupt() error {
+	i.Lock()
+	defer i.Unlock()
+	if i.closed {
+		return errors.New("the input of the process is closed")
+	}
+	_, err := i.WriteCloser.Write([]byte{0x03})
+	return err
 }
 
 func openReaderFromPipe(p io.ReadCloser) io.Reader {
@@ -0,0 +1,73 @@
+package libcontainerd
+
+import (
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+const (
+	// processReapInterval is how often the processes tracked by a client
+	// are reconciled with the ones known to the runtime.
+	processReapInterval = 30 * time.Second
+
+	// unknownExitCode is reported for processes that disappeared from the
+	// runtime without their exit status being delivered.
+	unknownExitCode = 255
+)
+
+// reapProcesses periodically reconciles the exec'd processes tracked by the
+// client with the processes the runtime still knows about, until closing
+// returns true. A process that is missing from the runtime on two
+// consecutive checks is reported as exited, so that an exit notification
+// lost by the runtime does not leave the exec running forever from the
+// daemon's point of view.
+func (clnt *client) reapProcesses(closing func() bool) {
+	missing := make(map[string]struct{})
+	for {
+		time.Sleep(processReapInterval)
+		if closing != nil && closing() {
+			return
+		}
+		missing = clnt.reapOnce(missing)
+	}
+}
+
+// reapOnce reaps the processes that are missing from the runtime and were
+// already missing on the previous check. It returns the processes seen
+// missing for the first time.
+func (clnt *client) reapOnce(missing map[string]struct{}) map[string]struct{} {
+	clnt.mapMutex.RLock()
+	ids := make([]string, 0, len(clnt.containers))
+	for id := range clnt.containers {
+		ids = append(ids, id)
+	}
+	clnt.mapMutex.RUnlock()
+
+	newlyMissing := make(map[string]struct{})
+	for _, id := range ids {
+		clnt.lock(id)
+		ctr, err := clnt.getContainer(id)
+		if err != nil || ctr.restarting || len(ctr.processes) == 0 {
+			clnt.unlock(id)
+			continue
+		}
+		exited, err := clnt.exitedProcesses(ctr)
+		if err != nil {
+			logrus.Debugf("libcontainerd: could not list processes of container %s: %v", id, err)
+			clnt.unlock(id)
+			continue
+		}
+		for _, name := range exited {
+			key := id + "/" + name
+			if _, ok := missing[key]; !ok {
+				newlyMissing[key] = struct{}{}
+				continue
+			}
+			logrus.Warnf("libcontainerd: process %s of container %s is gone but its exit was never reported, reaping it", name, id)
+			clnt.reapProcess(ctr, name)
+		}
+		clnt.unlock(id)
+	}
+	return newlyMissing
+}
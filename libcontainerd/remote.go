@@ -1,5 +1,7 @@
 package libcontainerd
 
+import "golang.org/x/net/context"
+
 // Remote on Linux defines the accesspoint to the containerd grpc API.
 // Remote on Windows is largely an unimplemented interface as there is
 // no remote containerd.
@@ -9,6 +11,9 @@ type Remote interface {
 	// Cleanup stops containerd if it was started by libcontainerd.
 	// Note this is not used on Windows as there is no remote containerd.
 	Cleanup()
+	// Ping checks that containerd responds, until ctx is done.
+	// Note this always succeeds on Windows as there is no remote containerd.
+	Ping(ctx context.Context) error
 }
 
 // RemoteOption allows to configure parameters of remotes.
@@ -29,6 +29,8 @@ import (
 const (
 	maxConnectionRetryCount   = 3
 	connectionRetryDelay      = 3 * time.Second
+	maxRetryDelay             = time.Minute
+	containerdCheckInterval   = time.Second
 	containerdShutdownTimeout = 15 * time.Second
 	containerdBinary          = "docker-containerd"
 	containerdPidFilename     = "docker-containerd.pid"
@@ -36,6 +38,15 @@ const (
 	eventTimestampFilename    = "event.ts"
 )
 
+// RuntimeBinary is the OCI runtime run by the containerd started by the
+// daemon.
+const RuntimeBinary = "docker-runc"
+
+// retrySleep waits between the retries of the restarts of containerd and of
+// the subscriptions to its events.  retrySleep is a variable such that the
+// implementation can be swapped out for unit tests.
+var retrySleep = time.Sleep
+
 type remote struct {
 	sync.RWMutex
 	apiClient     containerd.APIClient
@@ -50,6 +61,11 @@ type remote struct {
 	eventTsPath   string
 	pastEvents    map[string]*containerd.Event
 	runtimeArgs   []string
+	oomScore      int
+	// lastEventTs and lastEvents track the most recent events dispatched,
+	// so that events replayed after a reconnection are not handled twice.
+	lastEventTs uint64
+	lastEvents  map[string]struct{}
 }
 
 // New creates a fresh instance of libcontainerd remote.
@@ -64,6 +80,7 @@ func New(stateDir string, options ...RemoteOption) (_ Remote, err error) {
 		daemonPid:   -1,
 		eventTsPath: filepath.Join(stateDir, eventTimestampFilename),
 		pastEvents:  make(map[string]*containerd.Event),
+		lastEvents:  make(map[string]struct{}),
 	}
 	for _, option := range options {
 		if err := option.Apply(r); err != nil {
@@ -102,7 +119,7 @@ func New(stateDir string, options ...RemoteOption) (_ Remote, err error) {
 
 	go r.handleConnectionChange()
 
-	if err := r.startEventsMonitor(); err != nil {
+	if err := r.startEventsMonitor(false); err != nil {
 		return nil, err
 	}
 
@@ -127,12 +144,12 @@ func (r *remote) handleConnectionChange() {
 				transientFailureCount++
 				if transientFailureCount >= maxConnectionRetryCount {
 					transientFailureCount = 0
+					// containerd is unresponsive; kill it and let
+					// superviseContainerd start a new instance.
 					if utils.IsProcessAlive(r.daemonPid) {
+						logrus.Errorf("containerd (%d) is not responding, killing it", r.daemonPid)
 						utils.KillProcess(r.daemonPid)
 					}
-					if err := r.runContainerdDaemon(); err != nil { //FIXME: Handle error
-						logrus.Errorf("error restarting containerd: %v", err)
-					}
 				} else {
 					state = grpc.Idle
 					time.Sleep(connectionRetryDelay)
@@ -149,7 +166,9 @@ func (r *remote) Cleanup() {
 	if r.daemonPid == -1 {
 		return
 	}
+	r.Lock()
 	r.closeManually = true
+	r.Unlock()
 	r.rpcConn.Close()
 	// Ask the daemon to quit
 	syscall.Kill(r.daemonPid, syscall.SIGTERM)
@@ -172,6 +191,11 @@ func (r *remote) Cleanup() {
 	os.Remove(filepath.Join(r.stateDir, containerdSockFilename))
 }
 
+func (r *remote) Ping(ctx context.Context) error {
+	_, err := r.apiClient.GetServerVersion(ctx, &containerd.GetServerVersionRequest{})
+	return err
+}
+
 func (r *remote) Client(b Backend) (Client, error) {
 	c := &client{
 		clientCommon: clientCommon{
@@ -186,6 +210,8 @@ func (r *remote) Client(b Backend) (Client, error) {
 	r.Lock()
 	r.clients = append(r.clients, c)
 	r.Unlock()
+
+	go c.reapProcesses(r.isClosing)
 	return c, nil
 }
 
@@ -239,7 +265,17 @@ func (r *remote) getLastEventTimestamp() int64 {
 	return t.Unix()
 }
 
-func (r *remote) startEventsMonitor() error {
+func (r *remote) isClosing() bool {
+	r.RLock()
+	defer r.RUnlock()
+	return r.closeManually
+}
+
+// startEventsMonitor subscribes to the containerd events that happened
+// since the last one recorded. On a reconnection (replay is true), the
+// events that were missed while the connection was down are handled as
+// if they were live.
+func (r *remote) startEventsMonitor(replay bool) error {
 	// First, get past events
 	er := &containerd.EventsRequest{
 		Timestamp: uint64(r.getLastEventTimestamp()),
@@ -248,26 +284,51 @@ func (r *remote) startEventsMonitor() error {
 	if err != nil {
 		return err
 	}
-	go r.handleEventStream(events)
+	go r.handleEventStream(events, replay)
 	return nil
 }
 
-func (r *remote) handleEventStream(events containerd.API_EventsClient) {
+// resubscribeEvents restores the events stream after it was interrupted,
+// retrying with an exponential backoff until containerd is reachable again.
+func (r *remote) resubscribeEvents() {
+	delay := connectionRetryDelay
+	for !r.isClosing() {
+		err := r.startEventsMonitor(true)
+		if err == nil {
+			logrus.Infof("libcontainerd: events stream from containerd restored")
+			return
+		}
+		logrus.Errorf("libcontainerd: failed to subscribe to containerd events, retrying in %v: %v", delay, err)
+		retrySleep(delay)
+		if delay *= 2; delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+	}
+}
+
+func (r *remote) handleEventStream(events containerd.API_EventsClient, replay bool) {
 	live := false
 	for {
 		e, err := events.Recv()
 		if err != nil {
 			if grpc.ErrorDesc(err) == transport.ErrConnClosing.Desc &&
-				r.closeManually {
+				r.isClosing() {
 				// ignore error if grpc remote connection is closed manually
 				return
 			}
 			logrus.Errorf("failed to receive event from containerd: %v", err)
-			go r.startEventsMonitor()
+			go r.resubscribeEvents()
 			return
 		}
 
-		if live == false {
+		if live == false && replay {
+			logrus.Debugf("received missed containerd event: %#v", e)
+			if e.Type == stateLive {
+				live = true
+				continue
+			}
+			r.dispatchEvent(e)
+		} else if live == false {
 			logrus.Debugf("received past containerd event: %#v", e)
 
 			// Pause/Resume events should never happens after exit one
@@ -284,29 +345,50 @@ func (r *remote) handleEventStream(events containerd.API_EventsClient) {
 			}
 		} else {
 			logrus.Debugf("received containerd event: %#v", e)
+			r.dispatchEvent(e)
+		}
+	}
+}
 
-			var container *container
-			var c *client
-			r.RLock()
-			for _, c = range r.clients {
-				container, err = c.getContainer(e.Id)
-				if err == nil {
-					break
-				}
-			}
-			r.RUnlock()
-			if container == nil {
-				logrus.Errorf("no state for container: %q", err)
-				continue
-			}
-
-			if err := container.handleEvent(e); err != nil {
-				logrus.Errorf("error processing state change for %s: %v", e.Id, err)
-			}
+// dispatchEvent hands an event over to the container it relates to. Events
+// that were already dispatched are skipped, as the events stream restarts
+// from the timestamp of the last event seen, with a one second precision.
+func (r *remote) dispatchEvent(e *containerd.Event) {
+	key := fmt.Sprintf("%s/%s/%s/%d", e.Id, e.Type, e.Pid, e.Status)
+	if e.Timestamp < r.lastEventTs {
+		return
+	}
+	if e.Timestamp > r.lastEventTs {
+		r.lastEventTs = e.Timestamp
+		r.lastEvents = make(map[string]struct{})
+	}
+	if _, seen := r.lastEvents[key]; seen {
+		return
+	}
+	r.lastEvents[key] = struct{}{}
 
-			r.updateEventTimestamp(time.Unix(int64(e.Timestamp), 0))
+	var (
+		container *container
+		err       error
+	)
+	r.RLock()
+	for _, c := range r.clients {
+		container, err = c.getContainer(e.Id)
+		if err == nil {
+			break
 		}
 	}
+	r.RUnlock()
+	if container == nil {
+		logrus.Errorf("no state for container: %q", err)
+		return
+	}
+
+	if err := container.handleEvent(e); err != nil {
+		logrus.Errorf("error processing state change for %s: %v", e.Id, err)
+	}
+
+	r.updateEventTimestamp(time.Unix(int64(e.Timestamp), 0))
 }
 
 func (r *remote) runContainerdDaemon() error {
@@ -332,6 +414,7 @@ func (r *remote) runContainerdDaemon() error {
 		if utils.IsProcessAlive(int(pid)) {
 			logrus.Infof("previous instance of containerd still alive (%d)", pid)
 			r.daemonPid = int(pid)
+			go r.superviseContainerd(int(pid), nil)
 			return nil
 		}
 	}
@@ -352,7 +435,7 @@ func (r *remote) runContainerdDaemon() error {
 	args := []string{
 		"-l", fmt.Sprintf("unix://%s", r.rpcAddr),
 		"--shim", "docker-containerd-shim",
-		"--runtime", "docker-runc",
+		"--runtime", RuntimeBinary,
 		"--metrics-interval=0",
 	}
 	if r.debugLog {
@@ -388,11 +471,68 @@ func (r *remote) runContainerdDaemon() error {
 		return err
 	}
 
-	go cmd.Wait() // Reap our child when needed
+	if r.oomScore != 0 {
+		if err := setOOMScore(cmd.Process.Pid, r.oomScore); err != nil {
+			logrus.Warnf("failed to set the OOM score of containerd: %v", err)
+		}
+	}
+
+	exited := make(chan struct{})
+	go func() {
+		cmd.Wait() // Reap our child when needed
+		close(exited)
+	}()
 	r.daemonPid = cmd.Process.Pid
+	go r.superviseContainerd(cmd.Process.Pid, exited)
 	return nil
 }
 
+// superviseContainerd waits for the containerd process started or adopted
+// by the daemon to exit and, unless the daemon is shutting down, starts a
+// new instance, retrying with an exponential backoff. exited is closed when
+// the process exits; if it is nil, the process is not a child of the daemon
+// and is polled instead.
+func (r *remote) superviseContainerd(pid int, exited <-chan struct{}) {
+	if exited == nil {
+		polled := make(chan struct{})
+		go func() {
+			for utils.IsProcessAlive(pid) {
+				time.Sleep(containerdCheckInterval)
+			}
+			close(polled)
+		}()
+		exited = polled
+	}
+	<-exited
+	if r.isClosing() {
+		return
+	}
+	logrus.Errorf("containerd (%d) exited, starting a new instance", pid)
+
+	delay := connectionRetryDelay
+	for !r.isClosing() {
+		err := r.runContainerdDaemon()
+		if err == nil {
+			return
+		}
+		logrus.Errorf("error restarting containerd, retrying in %v: %v", delay, err)
+		retrySleep(delay)
+		if delay *= 2; delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+	}
+}
+
+func setOOMScore(pid, score int) error {
+	f, err := os.OpenFile(fmt.Sprintf("/proc/%d/oom_score_adj", pid), os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(strconv.Itoa(score))
+	return err
+}
+
 // WithRemoteAddr sets the external containerd socket to connect to.
 func WithRemoteAddr(addr string) RemoteOption {
 	return rpcAddr(addr)
@@ -452,3 +592,19 @@ func (d debugLog) Apply(r Remote) error {
 	}
 	return fmt.Errorf("WithDebugLog option not supported for this remote")
 }
+
+// WithOOMScore defines the oom_score_adj to set for the containerd process
+// started by libcontainerd. Zero leaves it unchanged.
+func WithOOMScore(score int) RemoteOption {
+	return oomScore(score)
+}
+
+type oomScore int
+
+func (o oomScore) Apply(r Remote) error {
+	if remote, ok := r.(*remote); ok {
+		remote.oomScore = int(o)
+		return nil
+	}
+	return fmt.Errorf("WithOOMScore option not supported for this remote")
+}
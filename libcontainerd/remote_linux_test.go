@@ -0,0 +1,246 @@
+package libcontainerd
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	containerd "github.com/docker/containerd/api/grpc/types"
+	"github.com/docker/docker/pkg/locker"
+	"github.com/docker/docker/utils"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// fakeSleep replaces retrySleep, recording the delays instead of sleeping,
+// and calls fn, if set, after each of them.
+type fakeSleep struct {
+	mu     sync.Mutex
+	delays []time.Duration
+	fn     func(n int)
+}
+
+func (s *fakeSleep) sleep(d time.Duration) {
+	s.mu.Lock()
+	s.delays = append(s.delays, d)
+	n := len(s.delays)
+	s.mu.Unlock()
+	if s.fn != nil {
+		s.fn(n)
+	}
+}
+
+func (s *fakeSleep) recorded() []time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]time.Duration(nil), s.delays...)
+}
+
+func TestSuperviseContainerdBackoff(t *testing.T) {
+	dir, err := ioutil.TempDir("", "libcontainerd-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("sleep not found")
+	}
+	// containerd can't be started until it is found in PATH.
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", dir)
+
+	r := &remote{stateDir: dir, daemonPid: -1, rpcAddr: filepath.Join(dir, containerdSockFilename)}
+	s := &fakeSleep{fn: func(n int) {
+		if n == 6 {
+			script := "#!/bin/sh\nexec " + sleep + " 60\n"
+			if err := ioutil.WriteFile(filepath.Join(dir, containerdBinary), []byte(script), 0755); err != nil {
+				t.Error(err)
+			}
+		}
+	}}
+	defer func(orig func(time.Duration)) { retrySleep = orig }(retrySleep)
+	retrySleep = s.sleep
+
+	exited := make(chan struct{})
+	close(exited)
+	r.superviseContainerd(1, exited)
+
+	expected := []time.Duration{3 * time.Second, 6 * time.Second, 12 * time.Second, 24 * time.Second, 48 * time.Second, time.Minute}
+	if delays := s.recorded(); !reflect.DeepEqual(delays, expected) {
+		t.Fatalf("expected the restarts to be retried after %v, got %v", expected, delays)
+	}
+
+	r.Lock()
+	r.closeManually = true
+	pid := r.daemonPid
+	r.Unlock()
+	defer utils.KillProcess(pid)
+	if pid <= 0 || !utils.IsProcessAlive(pid) {
+		t.Fatalf("expected containerd to be restarted, got pid %d", pid)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, containerdPidFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != strconv.Itoa(pid) {
+		t.Fatalf("expected the pid file to hold %d, got %q", pid, b)
+	}
+}
+
+// fakeEventsStream returns events, then blocks until end, if set, is closed
+// and fails.
+type fakeEventsStream struct {
+	grpc.ClientStream
+	events []*containerd.Event
+	end    chan struct{}
+}
+
+func (s *fakeEventsStream) Recv() (*containerd.Event, error) {
+	if len(s.events) > 0 {
+		e := s.events[0]
+		s.events = s.events[1:]
+		return e, nil
+	}
+	if s.end != nil {
+		<-s.end
+	}
+	return nil, errors.New("transport is closing")
+}
+
+// fakeAPIClient serves the subscriptions to the events of containerd with
+// streams, failing when the next one is nil.
+type fakeAPIClient struct {
+	containerd.APIClient
+	mu       sync.Mutex
+	requests []uint64
+	streams  []*fakeEventsStream
+}
+
+func (c *fakeAPIClient) Events(ctx context.Context, in *containerd.EventsRequest, opts ...grpc.CallOption) (containerd.API_EventsClient, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests = append(c.requests, in.Timestamp)
+	if len(c.streams) == 0 {
+		return nil, errors.New("no more streams")
+	}
+	s := c.streams[0]
+	c.streams = c.streams[1:]
+	if s == nil {
+		return nil, errors.New("connection refused")
+	}
+	return s, nil
+}
+
+// fakeBackend records the state changes of the containers.
+type fakeBackend struct {
+	states chan string
+}
+
+func (b *fakeBackend) StateChanged(containerID string, state StateInfo) error {
+	b.states <- containerID + " " + state.State
+	return nil
+}
+
+func (b *fakeBackend) AttachStreams(processFriendlyName string, io IOPipe) error {
+	return nil
+}
+
+func TestEventsReplayAfterReconnect(t *testing.T) {
+	dir, err := ioutil.TempDir("", "libcontainerd-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	event := func(typ string, ts uint64) *containerd.Event {
+		return &containerd.Event{Id: "c1", Type: typ, Pid: InitFriendlyName, Timestamp: ts}
+	}
+	end := make(chan struct{})
+	api := &fakeAPIClient{streams: []*fakeEventsStream{
+		// the first stream is lost after a live event
+		{events: []*containerd.Event{event(StatePause, 99), event(stateLive, 100), event(StateResume, 101)}},
+		// containerd is not reachable on the first reconnection
+		nil,
+		// the events missed are replayed, from the last one dispatched
+		{events: []*containerd.Event{event(StateResume, 101), event(StatePause, 102), event(stateLive, 103), event(StateResume, 103)}, end: end},
+	}}
+	r := &remote{
+		apiClient:   api,
+		daemonPid:   -1,
+		stateDir:    dir,
+		eventTsPath: filepath.Join(dir, eventTimestampFilename),
+		pastEvents:  make(map[string]*containerd.Event),
+		lastEvents:  make(map[string]struct{}),
+	}
+	backend := &fakeBackend{states: make(chan string, 10)}
+	clnt := &client{
+		clientCommon: clientCommon{
+			backend:    backend,
+			containers: make(map[string]*container),
+			locker:     locker.New(),
+		},
+		remote:        r,
+		exitNotifiers: make(map[string]*exitNotifier),
+	}
+	clnt.appendContainer(clnt.newContainer(filepath.Join(dir, "c1")))
+	r.clients = append(r.clients, clnt)
+
+	s := &fakeSleep{}
+	defer func(orig func(time.Duration)) { retrySleep = orig }(retrySleep)
+	retrySleep = s.sleep
+	defer func() {
+		r.Lock()
+		r.closeManually = true
+		r.Unlock()
+		close(end)
+	}()
+
+	// The timestamp of the last event is saved, so that the daemon
+	// subscribes from it.
+	r.updateEventTimestamp(time.Unix(98, 0))
+	if err := r.startEventsMonitor(false); err != nil {
+		t.Fatal(err)
+	}
+
+	var states []string
+	for len(states) < 3 {
+		select {
+		case state := <-backend.states:
+			states = append(states, state)
+		case <-time.After(10 * time.Second):
+			t.Fatalf("expected 3 state changes, got %v", states)
+		}
+	}
+	// the event replayed which was already dispatched is skipped
+	expected := []string{"c1 resume", "c1 pause", "c1 resume"}
+	if !reflect.DeepEqual(states, expected) {
+		t.Fatalf("expected the state changes %v, got %v", expected, states)
+	}
+	select {
+	case state := <-backend.states:
+		t.Fatalf("unexpected state change %s", state)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	api.mu.Lock()
+	requests := api.requests
+	api.mu.Unlock()
+	if expected := []uint64{98, 101, 101}; !reflect.DeepEqual(requests, expected) {
+		t.Fatalf("expected the events to be requested from %v, got %v", expected, requests)
+	}
+	if delays := s.recorded(); !reflect.DeepEqual(delays, []time.Duration{connectionRetryDelay}) {
+		t.Fatalf("expected the subscription to be retried after %v, got %v", connectionRetryDelay, delays)
+	}
+	if e := r.pastEvents["c1"]; e == nil || e.Type != StatePause || e.Timestamp != 99 {
+		t.Fatalf("expected the past pause event to be kept, got %v", e)
+	}
+}
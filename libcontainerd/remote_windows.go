@@ -1,8 +1,14 @@
 package libcontainerd
 
-import "github.com/docker/docker/pkg/locker"
+import (
+	"os"
+
+	"github.com/docker/docker/pkg/locker"
+	"golang.org/x/net/context"
+)
 
 type remote struct {
+	stateDir string
 }
 
 func (r *remote) Client(b Backend) (Client, error) {
@@ -12,7 +18,10 @@ func (r *remote) Client(b Backend) (Client, error) {
 			containers: make(map[string]*container),
 			locker:     locker.New(),
 		},
+		stateDir: r.stateDir,
 	}
+	c.loadRestorable()
+	go c.reapProcesses(nil)
 	return c, nil
 }
 
@@ -20,8 +29,19 @@ func (r *remote) Client(b Backend) (Client, error) {
 func (r *remote) Cleanup() {
 }
 
-// New creates a fresh instance of libcontainerd remote. On Windows,
-// this is not used as there is no remote containerd process.
-func New(_ string, _ ...RemoteOption) (Remote, error) {
-	return &remote{}, nil
+// Ping is a no-op on Windows. It is here to implement the interface.
+func (r *remote) Ping(ctx context.Context) error {
+	return nil
+}
+
+// New creates a fresh instance of libcontainerd remote. On Windows, there
+// is no remote containerd process, and stateDir is where the clients save
+// the state of the running containers, if set.
+func New(stateDir string, _ ...RemoteOption) (Remote, error) {
+	if stateDir != "" {
+		if err := os.MkdirAll(stateDir, 0700); err != nil {
+			return nil, err
+		}
+	}
+	return &remote{stateDir: stateDir}, nil
 }
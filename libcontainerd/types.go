@@ -1,6 +1,11 @@
 package libcontainerd
 
-import "io"
+import (
+	"io"
+	"time"
+
+	"golang.org/x/net/context"
+)
 
 // State constants used in state change reporting.
 const (
@@ -30,19 +35,46 @@ type Backend interface {
 	AttachStreams(processFriendlyName string, io IOPipe) error
 }
 
-// Client provides access to containerd features.
+// Client provides access to containerd features. Methods taking a context
+// give up waiting on the runtime, and return the context's error, once it
+// is done.
 type Client interface {
-	Create(containerID string, spec Spec, options ...CreateOption) error
-	Signal(containerID string, sig int) error
-	AddProcess(containerID, processFriendlyName string, process Process) error
+	Create(ctx context.Context, containerID string, spec Spec, options ...CreateOption) error
+	Signal(ctx context.Context, containerID string, sig int) error
+	AddProcess(ctx context.Context, containerID, processFriendlyName string, process Process) error
 	Resize(containerID, processFriendlyName string, width, height int) error
 	Pause(containerID string) error
 	Resume(containerID string) error
 	Restore(containerID string, options ...CreateOption) error
 	Stats(containerID string) (*Stats, error)
 	GetPidsForContainer(containerID string) ([]int, error)
+	GetPidForProcess(containerID, processFriendlyName string) (int, error)
 	Summary(containerID string) ([]Summary, error)
 	UpdateResources(containerID string, resources Resources) error
+	State() []ContainerState
+}
+
+// ContainerState is the state of a container in the client, as dumped by
+// the daemon for debugging.
+type ContainerState struct {
+	ID         string
+	Pid        uint32   `json:",omitempty"`
+	Processes  []string `json:",omitempty"`
+	Restarting bool     `json:",omitempty"`
+	// Locked is set when the lock of the container was held for too long
+	// to read its processes.
+	Locked     bool        `json:",omitempty"`
+	Operations []Operation `json:",omitempty"`
+}
+
+// Operation is an operation of the client on a container, holding its lock
+// or waiting for it.
+type Operation struct {
+	Name    string
+	Holding bool
+	// Since is when the operation took the lock if it holds it, or started
+	// waiting for it otherwise.
+	Since time.Time
 }
 
 // CreateOption allows to configure parameters of container creation.
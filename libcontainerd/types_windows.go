@@ -1,6 +1,9 @@
 package libcontainerd
 
-import "github.com/docker/docker/libcontainerd/windowsoci"
+import (
+	"github.com/Microsoft/hcsshim"
+	"github.com/docker/docker/libcontainerd/windowsoci"
+)
 
 // Spec is the base configuration for the container.
 type Spec windowsoci.WindowsSpec
@@ -26,8 +29,8 @@ type StateInfo struct {
 	UpdatePending bool // Indicates that there are some update operations pending that should be completed by a servicing container.
 }
 
-// Stats contains a stats properties from containerd.
-type Stats struct{}
+// Stats contains the statistics of the compute system of a container.
+type Stats hcsshim.Statistics
 
 // Resources defines updatable container resource values.
 type Resources struct{}
@@ -1,6 +1,10 @@
 package libcontainerd
 
-import "strings"
+import (
+	"strings"
+
+	"golang.org/x/net/context"
+)
 
 // setupEnvironmentVariables convert a string array of environment variables
 // into a map as required by the HCS. Source array is in format [v1=k1] [v2=k2] etc.
@@ -19,3 +23,30 @@ func setupEnvironmentVariables(a []string) map[string]string {
 func (s *ServicingOption) Apply(interface{}) error {
 	return nil
 }
+
+// callWithContext runs fn, a call into HCS which cannot itself be
+// cancelled, and returns its error. If ctx is done first, the context's
+// error is returned straight away and fn is left to complete in the
+// background; abandoned, if not nil, is then called with the result of fn
+// so that whatever it set up can be torn down.
+func callWithContext(ctx context.Context, fn func() error, abandoned func(error)) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- fn()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		go func() {
+			err := <-done
+			if abandoned != nil {
+				abandoned(err)
+			}
+		}()
+		return ctx.Err()
+	}
+}
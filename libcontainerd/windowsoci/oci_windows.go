@@ -139,6 +139,12 @@ type CPU struct {
 	Shares *uint64 `json:"shares,omitempty"`
 	// Percent of available CPUs usable by the container.
 	Percent *int64 `json:"percent,omitempty"`
+	// Processor group of the CPUs in Affinity.
+	Group *uint16 `json:"group,omitempty"`
+	// Mask of the CPUs of Group the container runs on.
+	Affinity *uint64 `json:"affinity,omitempty"`
+	// ID of the CPU group of the host that runs a Hyper-V container.
+	CPUGroupID string `json:"cpuGroupId,omitempty"`
 }
 
 // Network network resource management information
@@ -136,6 +136,39 @@ func TestDecompressStreamXz(t *testing.T) {
 	}
 }
 
+func TestCompressDecompressStreamZstd(t *testing.T) {
+	if _, err := exec.LookPath("zstd"); err != nil {
+		t.Skip("zstd not present")
+	}
+	var compressed bytes.Buffer
+	w, err := CompressStream(&compressed, Zstd)
+	if err != nil {
+		t.Fatalf("Failed to create a zstd stream: %v", err)
+	}
+	if _, err := w.Write([]byte("layer data")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if compression := DetectCompression(compressed.Bytes()); compression != Zstd {
+		t.Fatalf("Expected the stream to be detected as zstd, got %s", (&compression).Extension())
+	}
+
+	r, err := DecompressStream(&compressed)
+	if err != nil {
+		t.Fatalf("Failed to decompress a zstd stream: %v", err)
+	}
+	defer r.Close()
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "layer data" {
+		t.Fatalf("Expected the decompressed stream to be %q, got %q", "layer data", data)
+	}
+}
+
 func TestCompressStreamXzUnsuported(t *testing.T) {
 	dest, err := os.Create(tmp + "dest")
 	if err != nil {
@@ -205,6 +238,13 @@ func TestExtensionXz(t *testing.T) {
 		t.Fatalf("The extension of a bzip2 archive should be 'tar.xz'")
 	}
 }
+func TestExtensionZstd(t *testing.T) {
+	compression := Zstd
+	output := compression.Extension()
+	if output != "tar.zst" {
+		t.Fatalf("The extension of a zstd archive should be 'tar.zst'")
+	}
+}
 
 func TestCmdStreamLargeStderr(t *testing.T) {
 	cmd := exec.Command("sh", "-c", "dd if=/dev/zero bs=1k count=1000 of=/dev/stderr; echo hello")
@@ -29,6 +29,9 @@ const (
 	ChangeAdd
 	// ChangeDelete represents the delete operation.
 	ChangeDelete
+	// ChangeRename represents the rename operation. Renames are only
+	// reported by WalkChangesDirs.
+	ChangeRename
 )
 
 func (c ChangeType) String() string {
@@ -39,20 +42,36 @@ func (c ChangeType) String() string {
 		return "A"
 	case ChangeDelete:
 		return "D"
+	case ChangeRename:
+		return "R"
 	}
 	return ""
 }
 
 // Change represents a change, it wraps the change type and path.
 // It describes changes of the files in the path respect to the
-// parent layers. The change could be modify, add, delete.
+// parent layers. The change could be modify, add, delete or rename.
 // This is used for layer diff.
 type Change struct {
 	Path string
 	Kind ChangeType
+	// OldPath is the path the file was renamed from, for renames.
+	OldPath string `json:",omitempty"`
+	// Size is the size of the file, for files that were added, modified
+	// or renamed. It is not set for directories.
+	Size int64 `json:",omitempty"`
+	// Mode is the mode of the file, for files that were added, modified
+	// or renamed.
+	Mode os.FileMode `json:",omitempty"`
+	// OldMode is the mode of the file in the parent layers, when a
+	// modification changed it.
+	OldMode os.FileMode `json:",omitempty"`
 }
 
 func (change *Change) String() string {
+	if change.Kind == ChangeRename {
+		return fmt.Sprintf("%s %s -> %s", change.Kind, change.OldPath, change.Path)
+	}
 	return fmt.Sprintf("%s %s", change.Kind, change.Path)
 }
 
@@ -81,10 +100,21 @@ func sameFsTimeSpec(a, b syscall.Timespec) bool {
 // Changes walks the path rw and determines changes for the files in the path,
 // with respect to the parent layers
 func Changes(layers []string, rw string) ([]Change, error) {
-	var (
-		changes     []Change
-		changedDirs = make(map[string]struct{})
-	)
+	var changes []Change
+	if err := WalkChanges(layers, rw, func(change Change) error {
+		changes = append(changes, change)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// WalkChanges is like Changes, but calls fn for each change as it is found
+// instead of collecting them. The walk stops at the first error returned by
+// fn.
+func WalkChanges(layers []string, rw string, fn func(Change) error) error {
+	changedDirs := make(map[string]struct{})
 
 	err := filepath.Walk(rw, func(path string, f os.FileInfo, err error) error {
 		if err != nil {
@@ -124,6 +154,10 @@ func Changes(layers []string, rw string) ([]Change, error) {
 		} else {
 			// Otherwise, the file was added
 			change.Kind = ChangeAdd
+			change.Mode = f.Mode()
+			if !f.IsDir() {
+				change.Size = f.Size()
+			}
 
 			// ...Unless it already existed in a top layer, in which case, it's a modification
 			for _, layer := range layers {
@@ -143,6 +177,9 @@ func Changes(layers []string, rw string) ([]Change, error) {
 						}
 					}
 					change.Kind = ChangeModify
+					if stat.Mode()&os.ModeType == f.Mode()&os.ModeType && stat.Mode() != f.Mode() {
+						change.OldMode = stat.Mode()
+					}
 					break
 				}
 			}
@@ -158,19 +195,20 @@ func Changes(layers []string, rw string) ([]Change, error) {
 		if change.Kind == ChangeAdd || change.Kind == ChangeDelete {
 			parent := filepath.Dir(path)
 			if _, ok := changedDirs[parent]; !ok && parent != "/" {
-				changes = append(changes, Change{Path: parent, Kind: ChangeModify})
+				if err := fn(Change{Path: parent, Kind: ChangeModify}); err != nil {
+					return err
+				}
 				changedDirs[parent] = struct{}{}
 			}
 		}
 
 		// Record change
-		changes = append(changes, change)
-		return nil
+		return fn(change)
 	})
 	if err != nil && !os.IsNotExist(err) {
-		return nil, err
+		return err
 	}
-	return changes, nil
+	return nil
 }
 
 // FileInfo describes the information of a file.
@@ -181,6 +219,8 @@ type FileInfo struct {
 	children   map[string]*FileInfo
 	capability []byte
 	added      bool
+	mode       os.FileMode
+	ino        uint64
 }
 
 // LookUp looks up the file information of a file.
@@ -212,20 +252,64 @@ func (info *FileInfo) path() string {
 	return filepath.Join(info.parent.path(), info.name)
 }
 
-func (info *FileInfo) addChanges(oldInfo *FileInfo, changes *[]Change) {
+// change returns a change of the given kind for info, with its size and mode.
+func (info *FileInfo) change(kind ChangeType) Change {
+	change := Change{
+		Path: info.path(),
+		Kind: kind,
+		Mode: info.mode,
+	}
+	if !info.isDir() {
+		change.Size = info.stat.Size()
+	}
+	return change
+}
 
-	sizeAtEntry := len(*changes)
+// changeWalker reports the changes between two trees of file information
+// one at a time.
+type changeWalker struct {
+	fn func(Change) error
+	// pending are the unchanged directories being walked that must be
+	// reported, before the first change found inside them, so that their
+	// permissions are saved and restored along with their content.
+	pending []*FileInfo
+	// renamedFrom maps added files to the deleted files they were renamed
+	// from, and renamed holds those deleted files.
+	renamedFrom map[*FileInfo]*FileInfo
+	renamed     map[*FileInfo]bool
+}
 
+func (w *changeWalker) emit(change Change) error {
+	for _, dir := range w.pending {
+		if err := w.fn(Change{Path: dir.path(), Kind: ChangeModify, Mode: dir.mode}); err != nil {
+			return err
+		}
+	}
+	w.pending = w.pending[:0]
+	return w.fn(change)
+}
+
+func (w *changeWalker) walk(info, oldInfo *FileInfo) error {
 	if oldInfo == nil {
 		// add
-		change := Change{
-			Path: info.path(),
-			Kind: ChangeAdd,
+		change := info.change(ChangeAdd)
+		if old, ok := w.renamedFrom[info]; ok {
+			change.Kind = ChangeRename
+			change.OldPath = old.path()
+		}
+		if err := w.emit(change); err != nil {
+			return err
 		}
-		*changes = append(*changes, change)
 		info.added = true
 	}
 
+	// If there are changes inside this directory, we need to add it, even if the directory
+	// itself wasn't changed. This is needed to properly save and restore filesystem permissions.
+	pushed := info.isDir() && !info.added && info.parent != nil
+	if pushed {
+		w.pending = append(w.pending, info)
+	}
+
 	// We make a copy so we can modify it to detect additions
 	// also, we only recurse on the old dir if the new info is a directory
 	// otherwise any previous delete/change is considered recursive
@@ -250,11 +334,13 @@ func (info *FileInfo) addChanges(oldInfo *FileInfo, changes *[]Change) {
 			// back mtime
 			if statDifferent(oldStat, newStat) ||
 				bytes.Compare(oldChild.capability, newChild.capability) != 0 {
-				change := Change{
-					Path: newChild.path(),
-					Kind: ChangeModify,
+				change := newChild.change(ChangeModify)
+				if oldChild.mode&os.ModeType == newChild.mode&os.ModeType && oldChild.mode != newChild.mode {
+					change.OldMode = oldChild.mode
+				}
+				if err := w.emit(change); err != nil {
+					return err
 				}
-				*changes = append(*changes, change)
 				newChild.added = true
 			}
 
@@ -262,38 +348,77 @@ func (info *FileInfo) addChanges(oldInfo *FileInfo, changes *[]Change) {
 			delete(oldChildren, name)
 		}
 
-		newChild.addChanges(oldChild, changes)
+		if err := w.walk(newChild, oldChild); err != nil {
+			return err
+		}
 	}
 	for _, oldChild := range oldChildren {
+		if w.renamed[oldChild] {
+			// reported as a rename by the file it was renamed to
+			continue
+		}
 		// delete
-		change := Change{
-			Path: oldChild.path(),
-			Kind: ChangeDelete,
+		if err := w.emit(Change{Path: oldChild.path(), Kind: ChangeDelete}); err != nil {
+			return err
 		}
-		*changes = append(*changes, change)
 	}
 
-	// If there were changes inside this directory, we need to add it, even if the directory
-	// itself wasn't changed. This is needed to properly save and restore filesystem permissions.
-	// As this runs on the daemon side, file paths are OS specific.
-	if len(*changes) > sizeAtEntry && info.isDir() && !info.added && info.path() != string(os.PathSeparator) {
-		change := Change{
-			Path: info.path(),
-			Kind: ChangeModify,
+	if pushed && len(w.pending) > 0 && w.pending[len(w.pending)-1] == info {
+		// nothing changed inside this directory
+		w.pending = w.pending[:len(w.pending)-1]
+	}
+	return nil
+}
+
+// detectRenames finds the regular files of info that are absent from
+// oldInfo and that have the same inode, size and modification time as a
+// regular file of oldInfo that is absent from info.
+func (info *FileInfo) detectRenames(oldInfo *FileInfo, w *changeWalker) {
+	byIno := make(map[uint64]*FileInfo)
+	oldInfo.each(func(old *FileInfo) {
+		if old.ino != 0 && old.mode.IsRegular() {
+			byIno[old.ino] = old
 		}
-		// Let's insert the directory entry before the recently added entries located inside this dir
-		*changes = append(*changes, change) // just to resize the slice, will be overwritten
-		copy((*changes)[sizeAtEntry+1:], (*changes)[sizeAtEntry:])
-		(*changes)[sizeAtEntry] = change
+	})
+	if len(byIno) == 0 {
+		return
 	}
+	w.renamedFrom = make(map[*FileInfo]*FileInfo)
+	w.renamed = make(map[*FileInfo]bool)
+	info.each(func(f *FileInfo) {
+		if f.ino == 0 || !f.mode.IsRegular() {
+			return
+		}
+		old, ok := byIno[f.ino]
+		if !ok || statDifferent(old.stat, f.stat) {
+			return
+		}
+		if oldInfo.LookUp(f.path()) != nil || info.LookUp(old.path()) != nil {
+			return
+		}
+		w.renamedFrom[f] = old
+		w.renamed[old] = true
+		delete(byIno, f.ino)
+	})
+}
 
+// each calls fn for every file below info.
+func (info *FileInfo) each(fn func(*FileInfo)) {
+	for _, child := range info.children {
+		fn(child)
+		child.each(fn)
+	}
 }
 
 // Changes add changes to file information.
 func (info *FileInfo) Changes(oldInfo *FileInfo) []Change {
 	var changes []Change
 
-	info.addChanges(oldInfo, &changes)
+	w := &changeWalker{fn: func(change Change) error {
+		changes = append(changes, change)
+		return nil
+	}}
+	w.walk(info, oldInfo)
 
 	return changes
 }
@@ -329,6 +454,31 @@ func ChangesDirs(newDir, oldDir string) ([]Change, error) {
 	return newRoot.Changes(oldRoot), nil
 }
 
+// WalkChangesDirs is like ChangesDirs, but calls fn for each change as it is
+// found instead of collecting them, and reports a regular file that was
+// removed from oldDir and added to newDir with the same inode, size and
+// modification time as a single ChangeRename. Renames can therefore only be
+// detected when newDir is a snapshot of oldDir that preserves inode numbers.
+// The walk stops at the first error returned by fn.
+func WalkChangesDirs(newDir, oldDir string, fn func(Change) error) error {
+	if oldDir == "" {
+		emptyDir, err := ioutil.TempDir("", "empty")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(emptyDir)
+		oldDir = emptyDir
+	}
+	oldRoot, newRoot, err := collectFileInfoForChanges(oldDir, newDir)
+	if err != nil {
+		return err
+	}
+
+	w := &changeWalker{fn: fn}
+	newRoot.detectRenames(oldRoot, w)
+	return w.walk(newRoot, oldRoot)
+}
+
 // ChangesSize calculates the size in bytes of the provided changes, based on newDir.
 func ChangesSize(newDir string, changes []Change) int64 {
 	var (
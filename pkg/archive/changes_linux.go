@@ -71,6 +71,8 @@ func walkchunk(path string, fi os.FileInfo, dir string, root *FileInfo) error {
 		name:     filepath.Base(path),
 		children: make(map[string]*FileInfo),
 		parent:   parent,
+		mode:     fi.Mode(),
+		ino:      getIno(fi),
 	}
 	cpath := filepath.Join(dir, path)
 	stat, err := system.FromStatT(fi.Sys().(*syscall.Stat_t))
@@ -76,6 +76,8 @@ func collectFileInfo(sourceDir string) (*FileInfo, error) {
 			name:     filepath.Base(relPath),
 			children: make(map[string]*FileInfo),
 			parent:   parent,
+			mode:     f.Mode(),
+			ino:      getIno(f),
 		}
 
 		s, err := system.Lstat(path)
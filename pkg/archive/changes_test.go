@@ -98,21 +98,26 @@ func createSampleDir(t *testing.T, root string) {
 }
 
 func TestChangeString(t *testing.T) {
-	modifiyChange := Change{"change", ChangeModify}
+	modifiyChange := Change{Path: "change", Kind: ChangeModify}
 	toString := modifiyChange.String()
 	if toString != "C change" {
 		t.Fatalf("String() of a change with ChangeModifiy Kind should have been %s but was %s", "C change", toString)
 	}
-	addChange := Change{"change", ChangeAdd}
+	addChange := Change{Path: "change", Kind: ChangeAdd}
 	toString = addChange.String()
 	if toString != "A change" {
 		t.Fatalf("String() of a change with ChangeAdd Kind should have been %s but was %s", "A change", toString)
 	}
-	deleteChange := Change{"change", ChangeDelete}
+	deleteChange := Change{Path: "change", Kind: ChangeDelete}
 	toString = deleteChange.String()
 	if toString != "D change" {
 		t.Fatalf("String() of a change with ChangeDelete Kind should have been %s but was %s", "D change", toString)
 	}
+	renameChange := Change{Path: "change", Kind: ChangeRename, OldPath: "old"}
+	toString = renameChange.String()
+	if toString != "R old -> change" {
+		t.Fatalf("String() of a change with ChangeRename Kind should have been %s but was %s", "R old -> change", toString)
+	}
 }
 
 func TestChangesWithNoChanges(t *testing.T) {
@@ -182,11 +187,11 @@ func TestChangesWithChanges(t *testing.T) {
 	}
 
 	expectedChanges := []Change{
-		{"/dir1", ChangeModify},
-		{"/dir1/file1-1", ChangeModify},
-		{"/dir1/file1-2", ChangeDelete},
-		{"/dir1/subfolder", ChangeModify},
-		{"/dir1/subfolder/newFile", ChangeAdd},
+		{Path: "/dir1", Kind: ChangeModify},
+		{Path: "/dir1/file1-1", Kind: ChangeModify},
+		{Path: "/dir1/file1-2", Kind: ChangeDelete},
+		{Path: "/dir1/subfolder", Kind: ChangeModify},
+		{Path: "/dir1/subfolder/newFile", Kind: ChangeAdd},
 	}
 	checkChanges(expectedChanges, changes, t)
 }
@@ -225,8 +230,8 @@ func TestChangesWithChangesGH13590(t *testing.T) {
 	}
 
 	expectedChanges := []Change{
-		{"/dir1/dir2/dir3", ChangeModify},
-		{"/dir1/dir2/dir3/file1.txt", ChangeAdd},
+		{Path: "/dir1/dir2/dir3", Kind: ChangeModify},
+		{Path: "/dir1/dir2/dir3/file1.txt", Kind: ChangeAdd},
 	}
 	checkChanges(expectedChanges, changes, t)
 
@@ -247,7 +252,7 @@ func TestChangesWithChangesGH13590(t *testing.T) {
 	}
 
 	expectedChanges = []Change{
-		{"/dir1/dir2/dir3/file.txt", ChangeModify},
+		{Path: "/dir1/dir2/dir3/file.txt", Kind: ChangeModify},
 	}
 	checkChanges(expectedChanges, changes, t)
 }
@@ -282,6 +287,82 @@ func TestChangesDirsEmpty(t *testing.T) {
 	os.RemoveAll(dst)
 }
 
+func TestWalkChangesDirsRenamesAndSizes(t *testing.T) {
+	// Renames are detected by inode, which is always 0 on Windows.
+	if runtime.GOOS == "windows" {
+		t.Skip("inodes on Windows")
+	}
+	src, err := ioutil.TempDir("", "docker-changes-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(src)
+	dst, err := ioutil.TempDir("", "docker-changes-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dst)
+
+	if err := ioutil.WriteFile(path.Join(src, "moved"), []byte("moved"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path.Join(src, "chmod"), []byte("chmod"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// A hard link keeps the inode, size and mtime, as a rename in a
+	// snapshot does.
+	if err := os.Mkdir(path.Join(dst, "dir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Link(path.Join(src, "moved"), path.Join(dst, "dir", "renamed")); err != nil {
+		t.Fatal(err)
+	}
+	if err := copyDir(path.Join(src, "chmod"), path.Join(dst, "chmod")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path.Join(dst, "chmod"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path.Join(dst, "added"), []byte("added!"), 0640); err != nil {
+		t.Fatal(err)
+	}
+
+	var changes []Change
+	if err := WalkChangesDirs(dst, src, func(c Change) error {
+		changes = append(changes, c)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	sort.Sort(changesByPath(changes))
+
+	expectedChanges := []Change{
+		{Path: "/added", Kind: ChangeAdd, Size: 6, Mode: 0640},
+		{Path: "/chmod", Kind: ChangeModify, Size: 5, Mode: 0600, OldMode: 0644},
+		{Path: "/dir", Kind: ChangeAdd, Mode: os.ModeDir | 0755},
+		{Path: "/dir/renamed", Kind: ChangeRename, OldPath: "/moved", Size: 5, Mode: 0644},
+	}
+	if len(changes) != len(expectedChanges) {
+		t.Fatalf("expected %d changes, got %v", len(expectedChanges), changes)
+	}
+	for i, c := range changes {
+		if c != expectedChanges[i] {
+			t.Fatalf("expected %+v, got %+v", expectedChanges[i], c)
+		}
+	}
+
+	// ChangesDirs never reports renames.
+	changes, err = ChangesDirs(dst, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range changes {
+		if c.Kind == ChangeRename {
+			t.Fatalf("unexpected rename %s", c.String())
+		}
+	}
+}
+
 func mutateSampleDir(t *testing.T, root string) {
 	// Remove a regular file
 	if err := os.RemoveAll(path.Join(root, "file1")); err != nil {
@@ -389,18 +470,18 @@ func TestChangesDirsMutated(t *testing.T) {
 	sort.Sort(changesByPath(changes))
 
 	expectedChanges := []Change{
-		{"/dir1", ChangeDelete},
-		{"/dir2", ChangeModify},
-		{"/dirnew", ChangeAdd},
-		{"/file1", ChangeDelete},
-		{"/file2", ChangeModify},
-		{"/file3", ChangeModify},
-		{"/file4", ChangeModify},
-		{"/file5", ChangeModify},
-		{"/filenew", ChangeAdd},
-		{"/symlink1", ChangeDelete},
-		{"/symlink2", ChangeModify},
-		{"/symlinknew", ChangeAdd},
+		{Path: "/dir1", Kind: ChangeDelete},
+		{Path: "/dir2", Kind: ChangeModify},
+		{Path: "/dirnew", Kind: ChangeAdd},
+		{Path: "/file1", Kind: ChangeDelete},
+		{Path: "/file2", Kind: ChangeModify},
+		{Path: "/file3", Kind: ChangeModify},
+		{Path: "/file4", Kind: ChangeModify},
+		{Path: "/file5", Kind: ChangeModify},
+		{Path: "/filenew", Kind: ChangeAdd},
+		{Path: "/symlink1", Kind: ChangeDelete},
+		{Path: "/symlink2", Kind: ChangeModify},
+		{Path: "/symlinknew", Kind: ChangeAdd},
 	}
 
 	for i := 0; i < max(len(changes), len(expectedChanges)); i++ {
@@ -411,7 +492,7 @@ func TestChangesDirsMutated(t *testing.T) {
 			t.Fatalf("no change for expected change %s\n", expectedChanges[i].String())
 		}
 		if changes[i].Path == expectedChanges[i].Path {
-			if changes[i] != expectedChanges[i] {
+			if changes[i].Kind != expectedChanges[i].Kind {
 				t.Fatalf("Wrong change for %s, expected %s, got %s\n", changes[i].Path, changes[i].String(), expectedChanges[i].String())
 			}
 		} else if changes[i].Path < expectedChanges[i].Path {
@@ -553,7 +634,7 @@ func checkChanges(expectedChanges, changes []Change, t *testing.T) {
 			t.Fatalf("no change for expected change %s\n", expectedChanges[i].String())
 		}
 		if changes[i].Path == expectedChanges[i].Path {
-			if changes[i] != expectedChanges[i] {
+			if changes[i].Kind != expectedChanges[i].Kind {
 				t.Fatalf("Wrong change for %s, expected %s, got %s\n", changes[i].Path, changes[i].String(), expectedChanges[i].String())
 			}
 		} else if changes[i].Path < expectedChanges[i].Path {
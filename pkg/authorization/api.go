@@ -1,5 +1,7 @@
 package authorization
 
+import "github.com/docker/docker/pkg/peercred"
+
 const (
 	// AuthZApiRequest is the url for daemon request authorization
 	AuthZApiRequest = "AuthZPlugin.AuthZReq"
@@ -19,6 +21,10 @@ type Request struct {
 	// UserAuthNMethod holds the mechanism used to extract user details (e.g., krb)
 	UserAuthNMethod string `json:"UserAuthNMethod,omitempty"`
 
+	// PeerCredentials holds the process, user and group IDs of the client
+	// process connected to a Unix socket of the daemon
+	PeerCredentials *peercred.Credentials `json:"PeerCredentials,omitempty"`
+
 	// RequestMethod holds the HTTP method (GET/POST/PUT)
 	RequestMethod string `json:"RequestMethod,omitempty"`
 
@@ -10,6 +10,7 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/pkg/ioutils"
+	"github.com/docker/docker/pkg/peercred"
 )
 
 const maxBodySize = 1048576 // 1MB
@@ -44,6 +45,7 @@ func NewCtx(authZPlugins []Plugin, user, userAuthNMethod, requestMethod, request
 type Ctx struct {
 	user            string
 	userAuthNMethod string
+	peerCredentials *peercred.Credentials
 	requestMethod   string
 	requestURI      string
 	plugins         []Plugin
@@ -70,6 +72,7 @@ func (ctx *Ctx) AuthZRequest(w http.ResponseWriter, r *http.Request) error {
 	ctx.authReq = &Request{
 		User:            ctx.user,
 		UserAuthNMethod: ctx.userAuthNMethod,
+		PeerCredentials: ctx.peerCredentials,
 		RequestMethod:   ctx.requestMethod,
 		RequestURI:      ctx.requestURI,
 		RequestBody:     body,
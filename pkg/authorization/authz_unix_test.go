@@ -19,9 +19,11 @@ import (
 	"bytes"
 	"strings"
 
+	"github.com/docker/docker/pkg/peercred"
 	"github.com/docker/docker/pkg/plugins"
 	"github.com/docker/go-connections/tlsconfig"
 	"github.com/gorilla/mux"
+	"golang.org/x/net/context"
 )
 
 const pluginAddress = "authzplugin.sock"
@@ -118,6 +120,29 @@ func TestAuthZResponsePlugin(t *testing.T) {
 	}
 }
 
+func TestMiddlewarePeerCredentials(t *testing.T) {
+	server := authZPluginTestServer{t: t}
+	go server.start()
+	defer server.stop()
+
+	server.replayResponse = Response{Allow: true}
+	middleware := NewMiddleware([]Plugin{createTestPlugin(t)})
+	handler := middleware.WrapHandler(func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		return nil
+	})
+
+	creds := peercred.Credentials{PID: 42, UID: 1000, GID: 100}
+	r, _ := http.NewRequest("GET", "/containers/json", nil)
+	r.RemoteAddr = creds.String()
+	if err := handler(context.Background(), httptest.NewRecorder(), r, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if server.recordedRequest.PeerCredentials == nil || *server.recordedRequest.PeerCredentials != creds {
+		t.Fatalf("Expected the credentials %v, got %v", creds, server.recordedRequest.PeerCredentials)
+	}
+}
+
 func TestResponseModifier(t *testing.T) {
 	r := httptest.NewRecorder()
 	m := NewResponseModifier(r)
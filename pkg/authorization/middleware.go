@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/pkg/peercred"
 	"golang.org/x/net/context"
 )
 
@@ -38,6 +39,10 @@ func (m Middleware) WrapHandler(handler func(ctx context.Context, w http.Respons
 		}
 
 		authCtx := NewCtx(m.plugins, user, userAuthNMethod, r.Method, r.RequestURI)
+		// the clients of the Unix sockets are identified by their credentials
+		if creds, ok := peercred.Parse(r.RemoteAddr); ok {
+			authCtx.peerCredentials = &creds
+		}
 
 		if err := authCtx.AuthZRequest(w, r); err != nil {
 			logrus.Errorf("AuthZRequest for %s %s returned error: %s", r.Method, r.RequestURI, err)
@@ -0,0 +1,86 @@
+package cron
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseInvalid(t *testing.T) {
+	for spec, expected := range map[string]string{
+		"":              "expected 5 fields, got 0",
+		"* * * *":       "expected 5 fields, got 4",
+		"60 * * * *":    "invalid value \"60\" in minute field",
+		"* 24 * * *":    "invalid value \"24\" in hour field",
+		"* * 0 * *":     "invalid value \"0\" in day of month field",
+		"* * * foo *":   "invalid value \"foo\" in month field",
+		"* * * * 8":     "invalid value \"8\" in day of week field",
+		"5-1 * * * *":   "invalid range \"5-1\" in minute field",
+		"*/0 * * * *":   "invalid step \"0\" in minute field",
+		"@fortnightly":  "expected 5 fields, got 1",
+		"1,,2 * * * *":  "invalid value \"\" in minute field",
+		"* * * * mon-x": "invalid value \"x\" in day of week field",
+	} {
+		_, err := Parse(spec)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected %q to fail with %q, got %v", spec, expected, err)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	// Wednesday
+	from := time.Date(2016, time.June, 15, 10, 30, 45, 0, time.UTC)
+	for spec, expected := range map[string]time.Time{
+		"* * * * *":        time.Date(2016, time.June, 15, 10, 31, 0, 0, time.UTC),
+		"0 3 * * *":        time.Date(2016, time.June, 16, 3, 0, 0, 0, time.UTC),
+		"30 10 * * *":      time.Date(2016, time.June, 16, 10, 30, 0, 0, time.UTC),
+		"*/15 * * * *":     time.Date(2016, time.June, 15, 10, 45, 0, 0, time.UTC),
+		"5/20 * * * *":     time.Date(2016, time.June, 15, 10, 45, 0, 0, time.UTC),
+		"0 9-17/4 * * *":   time.Date(2016, time.June, 15, 13, 0, 0, 0, time.UTC),
+		"0 0 1 * *":        time.Date(2016, time.July, 1, 0, 0, 0, 0, time.UTC),
+		"0 0 * * sun":      time.Date(2016, time.June, 19, 0, 0, 0, 0, time.UTC),
+		"0 0 * * 7":        time.Date(2016, time.June, 19, 0, 0, 0, 0, time.UTC),
+		"0 0 * * MON-FRI":  time.Date(2016, time.June, 16, 0, 0, 0, 0, time.UTC),
+		"0 0 1,15 * *":     time.Date(2016, time.July, 1, 0, 0, 0, 0, time.UTC),
+		"0 12 1 jan,jul *": time.Date(2016, time.July, 1, 12, 0, 0, 0, time.UTC),
+		"0 0 29 2 *":       time.Date(2020, time.February, 29, 0, 0, 0, 0, time.UTC),
+		"@hourly":          time.Date(2016, time.June, 15, 11, 0, 0, 0, time.UTC),
+		"@yearly":          time.Date(2017, time.January, 1, 0, 0, 0, 0, time.UTC),
+		"@weekly":          time.Date(2016, time.June, 19, 0, 0, 0, 0, time.UTC),
+		"0 0 30 2 *":       {},
+		// either the day of the month or the day of the week matches
+		"0 0 20 * fri":     time.Date(2016, time.June, 17, 0, 0, 0, 0, time.UTC),
+		"0 0 1-31/10 * *":  time.Date(2016, time.June, 21, 0, 0, 0, 0, time.UTC),
+		" 45  10 * * wed ": time.Date(2016, time.June, 15, 10, 45, 0, 0, time.UTC),
+		"0,31 10,11 * * *": time.Date(2016, time.June, 15, 10, 31, 0, 0, time.UTC),
+		"59 23 31 12 *":    time.Date(2016, time.December, 31, 23, 59, 0, 0, time.UTC),
+		"0 0 * dec sat":    time.Date(2016, time.December, 3, 0, 0, 0, 0, time.UTC),
+	} {
+		s, err := Parse(spec)
+		if err != nil {
+			t.Errorf("Failed to parse %q: %v", spec, err)
+			continue
+		}
+		if next := s.Next(from); !next.Equal(expected) {
+			t.Errorf("Expected %q to run next at %v, got %v", spec, expected, next)
+		}
+	}
+}
+
+func TestNextLocation(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	s, err := Parse("0 3 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := s.Next(time.Date(2016, time.June, 15, 0, 0, 0, 0, time.UTC))
+	if expected := time.Date(2016, time.June, 15, 3, 0, 0, 0, time.UTC); !next.Equal(expected) {
+		t.Fatalf("Expected %v, got %v", expected, next)
+	}
+	// 04:00 UTC is 06:00 in loc, past 03:00 there
+	next = s.Next(time.Date(2016, time.June, 15, 4, 0, 0, 0, time.UTC).In(loc))
+	if expected := time.Date(2016, time.June, 16, 3, 0, 0, 0, loc); !next.Equal(expected) {
+		t.Fatalf("Expected %v, got %v", expected, next)
+	}
+}
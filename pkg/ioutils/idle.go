@@ -0,0 +1,126 @@
+package ioutils
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// IdleWatcher calls a function once no activity was reported to it for a
+// timeout. The activity is reported with Touch, or by the reads and writes
+// of the streams wrapped with Reader and Writer.
+type IdleWatcher struct {
+	timeout time.Duration
+	onIdle  func()
+	last    int64 // time of the last activity in nanoseconds, accessed atomically
+
+	mu      sync.Mutex
+	timer   *time.Timer
+	stopped bool
+	idle    bool
+}
+
+// NewIdleWatcher returns an IdleWatcher calling onIdle, from its own
+// goroutine, once timeout elapsed without any activity.
+func NewIdleWatcher(timeout time.Duration, onIdle func()) *IdleWatcher {
+	w := &IdleWatcher{
+		timeout: timeout,
+		onIdle:  onIdle,
+	}
+	w.Touch()
+
+	w.mu.Lock()
+	w.timer = time.AfterFunc(timeout, w.check)
+	w.mu.Unlock()
+	return w
+}
+
+func (w *IdleWatcher) check() {
+	w.mu.Lock()
+	if w.stopped {
+		w.mu.Unlock()
+		return
+	}
+	elapsed := time.Duration(time.Now().UnixNano() - atomic.LoadInt64(&w.last))
+	if elapsed < w.timeout {
+		w.timer.Reset(w.timeout - elapsed)
+		w.mu.Unlock()
+		return
+	}
+	w.stopped = true
+	w.idle = true
+	w.mu.Unlock()
+
+	w.onIdle()
+}
+
+// Touch reports an activity, postponing the timeout.
+func (w *IdleWatcher) Touch() {
+	atomic.StoreInt64(&w.last, time.Now().UnixNano())
+}
+
+// Stop stops the watcher. onIdle isn't called after Stop returns, unless it
+// was already running.
+func (w *IdleWatcher) Stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.stopped = true
+	w.timer.Stop()
+}
+
+// Timeout returns the timeout of the watcher.
+func (w *IdleWatcher) Timeout() time.Duration {
+	return w.timeout
+}
+
+// Idle returns whether the timeout elapsed and onIdle was called.
+func (w *IdleWatcher) Idle() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.idle
+}
+
+// Reader returns a reader reporting an activity for each read of r
+// returning data.
+func (w *IdleWatcher) Reader(r io.Reader) io.Reader {
+	return &idleReader{r: r, w: w}
+}
+
+// Writer returns a writer reporting an activity for each write to wr. The
+// writer flushes wr when it is flushed, if wr can be.
+func (w *IdleWatcher) Writer(wr io.Writer) io.Writer {
+	return &idleWriter{wr: wr, w: w}
+}
+
+type idleReader struct {
+	r io.Reader
+	w *IdleWatcher
+}
+
+func (r *idleReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.w.Touch()
+	}
+	return n, err
+}
+
+type idleWriter struct {
+	wr io.Writer
+	w  *IdleWatcher
+}
+
+func (w *idleWriter) Write(p []byte) (int, error) {
+	n, err := w.wr.Write(p)
+	if n > 0 {
+		w.w.Touch()
+	}
+	return n, err
+}
+
+func (w *idleWriter) Flush() {
+	if f, ok := w.wr.(flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,66 @@
+package ioutils
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIdleWatcherTimeout(t *testing.T) {
+	idle := make(chan struct{})
+	w := NewIdleWatcher(10*time.Millisecond, func() { close(idle) })
+	defer w.Stop()
+
+	select {
+	case <-idle:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the idle function wasn't called")
+	}
+	if !w.Idle() {
+		t.Fatal("expected the watcher to be idle")
+	}
+}
+
+func TestIdleWatcherActivity(t *testing.T) {
+	idle := make(chan struct{})
+	w := NewIdleWatcher(100*time.Millisecond, func() { close(idle) })
+	defer w.Stop()
+
+	r := w.Reader(strings.NewReader("abcdef"))
+	wr := w.Writer(&bytes.Buffer{})
+	start := time.Now()
+	p := make([]byte, 1)
+	for i := 0; i < 6; i++ {
+		time.Sleep(30 * time.Millisecond)
+		if i%2 == 0 {
+			r.Read(p)
+		} else {
+			wr.Write(p)
+		}
+	}
+
+	select {
+	case <-idle:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the idle function wasn't called")
+	}
+	if elapsed := time.Since(start); elapsed < 280*time.Millisecond {
+		t.Fatalf("expected the activity to postpone the timeout, the watcher was idle after %s", elapsed)
+	}
+}
+
+func TestIdleWatcherStop(t *testing.T) {
+	idle := make(chan struct{})
+	w := NewIdleWatcher(10*time.Millisecond, func() { close(idle) })
+	w.Stop()
+
+	select {
+	case <-idle:
+		t.Fatal("the idle function was called after Stop")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if w.Idle() {
+		t.Fatal("expected the watcher not to be idle")
+	}
+}
@@ -0,0 +1,46 @@
+package listeners
+
+import (
+	"crypto/tls"
+	"net"
+	"time"
+)
+
+// tcpKeepAliveListener sets TCP keep-alive on the connections it accepts, so
+// that the connections severed by a load balancer or a firewall are detected
+// and closed instead of being kept open forever.
+type tcpKeepAliveListener struct {
+	*net.TCPListener
+	period time.Duration
+}
+
+func (l tcpKeepAliveListener) Accept() (net.Conn, error) {
+	c, err := l.AcceptTCP()
+	if err != nil {
+		return nil, err
+	}
+	c.SetKeepAlive(true)
+	c.SetKeepAlivePeriod(l.period)
+	return c, nil
+}
+
+// newTCPSocket creates a TCP listener on addr, sending keep-alive probes
+// every keepAlive on the accepted connections unless keepAlive is 0. The
+// listener is wrapped in a TLS one if tlsConfig is set, negotiating HTTP/1.1
+// unless tlsConfig sets other protocols.
+func newTCPSocket(addr string, tlsConfig *tls.Config, keepAlive time.Duration) (net.Listener, error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if keepAlive > 0 {
+		l = tcpKeepAliveListener{l.(*net.TCPListener), keepAlive}
+	}
+	if tlsConfig != nil {
+		if len(tlsConfig.NextProtos) == 0 {
+			tlsConfig.NextProtos = []string{"http/1.1"}
+		}
+		l = tls.NewListener(l, tlsConfig)
+	}
+	return l, nil
+}
@@ -7,15 +7,19 @@ import (
 	"fmt"
 	"net"
 	"strconv"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/coreos/go-systemd/activation"
+	"github.com/docker/docker/pkg/peercred"
 	"github.com/docker/go-connections/sockets"
 )
 
 // Init creates new listeners for the server.
+// The keep-alive probes are sent every keepAlive on the TCP connections,
+// unless it is 0.
 // TODO: Clean up the fact that socketGroup and tlsConfig aren't always used.
-func Init(proto, addr, socketGroup string, tlsConfig *tls.Config) ([]net.Listener, error) {
+func Init(proto, addr, socketGroup string, tlsConfig *tls.Config, keepAlive time.Duration) ([]net.Listener, error) {
 	ls := []net.Listener{}
 
 	switch proto {
@@ -24,9 +28,12 @@ func Init(proto, addr, socketGroup string, tlsConfig *tls.Config) ([]net.Listene
 		if err != nil {
 			return nil, err
 		}
-		ls = append(ls, fds...)
+		// the activated sockets may be Unix sockets
+		for _, l := range fds {
+			ls = append(ls, peercred.NewListener(l))
+		}
 	case "tcp":
-		l, err := sockets.NewTCPSocket(addr, tlsConfig)
+		l, err := newTCPSocket(addr, tlsConfig, keepAlive)
 		if err != nil {
 			return nil, err
 		}
@@ -36,7 +43,7 @@ func Init(proto, addr, socketGroup string, tlsConfig *tls.Config) ([]net.Listene
 		if err != nil {
 			return nil, fmt.Errorf("can't create unix socket %s: %v", addr, err)
 		}
-		ls = append(ls, l)
+		ls = append(ls, peercred.NewListener(l))
 	default:
 		return nil, fmt.Errorf("invalid protocol format: %q", proto)
 	}
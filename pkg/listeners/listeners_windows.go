@@ -5,18 +5,20 @@ import (
 	"fmt"
 	"net"
 	"strings"
+	"time"
 
 	"github.com/Microsoft/go-winio"
-	"github.com/docker/go-connections/sockets"
 )
 
 // Init creates new listeners for the server.
-func Init(proto, addr, socketGroup string, tlsConfig *tls.Config) ([]net.Listener, error) {
+// The keep-alive probes are sent every keepAlive on the TCP connections,
+// unless it is 0.
+func Init(proto, addr, socketGroup string, tlsConfig *tls.Config, keepAlive time.Duration) ([]net.Listener, error) {
 	ls := []net.Listener{}
 
 	switch proto {
 	case "tcp":
-		l, err := sockets.NewTCPSocket(addr, tlsConfig)
+		l, err := newTCPSocket(addr, tlsConfig, keepAlive)
 		if err != nil {
 			return nil, err
 		}
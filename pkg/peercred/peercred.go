@@ -0,0 +1,79 @@
+// Package peercred captures the credentials of the processes connecting to
+// the Unix sockets of a server. The credentials are passed to the HTTP
+// handlers in the remote address of the connections, as HTTP requests carry
+// the remote address of their connection but not the connection itself.
+package peercred
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// Credentials are the process, user and group IDs of the process at the
+// other end of a Unix socket connection, when it connected.
+type Credentials struct {
+	PID int32  `json:"Pid"`
+	UID uint32 `json:"Uid"`
+	GID uint32 `json:"Gid"`
+}
+
+// String returns the remote address of the connections of c.
+func (c Credentials) String() string {
+	return fmt.Sprintf("pid=%d,uid=%d,gid=%d", c.PID, c.UID, c.GID)
+}
+
+// Parse returns the credentials held by the remote address addr of a
+// connection accepted by a listener of NewListener, and false if addr
+// doesn't hold credentials.
+func Parse(addr string) (Credentials, bool) {
+	var c Credentials
+	if _, err := fmt.Sscanf(addr, "pid=%d,uid=%d,gid=%d", &c.PID, &c.UID, &c.GID); err != nil || c.String() != addr {
+		return Credentials{}, false
+	}
+	return c, true
+}
+
+// NewListener returns a listener accepting the connections of l, and
+// setting the remote address of the Unix socket connections to the
+// credentials of their peer. The other connections, and the connections
+// whose credentials can't be read, are returned unchanged.
+func NewListener(l net.Listener) net.Listener {
+	return &listener{l}
+}
+
+type listener struct {
+	net.Listener
+}
+
+func (l *listener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	uc, ok := c.(*net.UnixConn)
+	if !ok {
+		return c, nil
+	}
+	creds, err := getCredentials(uc)
+	if err != nil {
+		logrus.Debugf("Error reading the credentials of the peer of %s: %v", l.Addr(), err)
+		return c, nil
+	}
+	return &conn{
+		UnixConn: uc,
+		addr:     &net.UnixAddr{Name: creds.String(), Net: "unix"},
+	}, nil
+}
+
+// conn is a Unix socket connection whose remote address holds the
+// credentials of its peer.
+type conn struct {
+	*net.UnixConn
+	addr net.Addr
+}
+
+func (c *conn) RemoteAddr() net.Addr {
+	return c.addr
+}
@@ -0,0 +1,26 @@
+package peercred
+
+import (
+	"net"
+	"syscall"
+)
+
+// getCredentials reads the credentials of the peer of c with SO_PEERCRED.
+func getCredentials(c *net.UnixConn) (Credentials, error) {
+	// File returns a duplicate of the descriptor of c, switched to blocking
+	// mode. The mode is shared with c, so it is switched back to non-blocking
+	// mode for c to keep working with the network poller.
+	f, err := c.File()
+	if err != nil {
+		return Credentials{}, err
+	}
+	defer f.Close()
+	fd := int(f.Fd())
+	defer syscall.SetNonblock(fd, true)
+
+	ucred, err := syscall.GetsockoptUcred(fd, syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	if err != nil {
+		return Credentials{}, err
+	}
+	return Credentials{PID: ucred.Pid, UID: ucred.Uid, GID: ucred.Gid}, nil
+}
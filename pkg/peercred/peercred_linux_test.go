@@ -0,0 +1,64 @@
+package peercred
+
+import (
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestListenerCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "peercred")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	l, err := net.Listen("unix", filepath.Join(dir, "sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l = NewListener(l)
+	defer l.Close()
+
+	client, err := net.Dial("unix", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	c, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	creds, ok := Parse(c.RemoteAddr().String())
+	if !ok {
+		t.Fatalf("Expected the remote address to hold credentials, got %q", c.RemoteAddr())
+	}
+	expected := Credentials{PID: int32(os.Getpid()), UID: uint32(os.Getuid()), GID: uint32(os.Getgid())}
+	if creds != expected {
+		t.Fatalf("Expected %v, got %v", expected, creds)
+	}
+	if _, ok := c.(interface {
+		CloseWrite() error
+	}); !ok {
+		t.Fatal("Expected the connection to support CloseWrite")
+	}
+
+	// Reading the credentials must leave the connection usable
+	if err := c.SetReadDeadline(time.Now().Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(c, buf); err != nil || string(buf) != "ping" {
+		t.Fatalf("Expected to read %q, got %q, %v", "ping", buf, err)
+	}
+}
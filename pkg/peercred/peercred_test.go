@@ -0,0 +1,17 @@
+package peercred
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	creds := Credentials{PID: 42, UID: 1000, GID: 100}
+	parsed, ok := Parse(creds.String())
+	if !ok || parsed != creds {
+		t.Fatalf("Expected %v, got %v, %v", creds, parsed, ok)
+	}
+
+	for _, addr := range []string{"", "@", "10.0.0.2:51234", "pid=42,uid=1000", "pid=42,uid=1000,gid=100,extra"} {
+		if _, ok := Parse(addr); ok {
+			t.Fatalf("Expected %q not to hold credentials", addr)
+		}
+	}
+}
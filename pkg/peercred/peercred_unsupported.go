@@ -0,0 +1,12 @@
+// +build !linux
+
+package peercred
+
+import (
+	"errors"
+	"net"
+)
+
+func getCredentials(c *net.UnixConn) (Credentials, error) {
+	return Credentials{}, errors.New("reading the credentials of the peers of Unix sockets is only supported on Linux")
+}
@@ -132,3 +132,24 @@ func TestClientScheme(t *testing.T) {
 		}
 	}
 }
+
+func TestProbe(t *testing.T) {
+	addr := setupRemotePluginServer()
+	defer teardownRemotePluginServer()
+
+	mux.HandleFunc("/Plugin.Activate", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", transport.VersionMimetype)
+		io.WriteString(w, `{"Implements": ["authz"]}`)
+	})
+
+	pl := newLocalPlugin("test", addr)
+	if err := probe(pl, "authz"); err != nil {
+		t.Fatal(err)
+	}
+	if err := probe(pl, "VolumeDriver"); err != ErrNotImplements {
+		t.Fatalf("Expected %v, got %v", ErrNotImplements, err)
+	}
+	if err := probe(newLocalPlugin("test", "tcp://127.0.0.1:1"), "authz"); err == nil {
+		t.Fatal("Expected an unreachable plugin to fail the probe")
+	}
+}
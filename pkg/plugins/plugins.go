@@ -23,7 +23,9 @@
 package plugins
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -36,6 +38,9 @@ var (
 	ErrNotImplements = errors.New("Plugin does not implement the requested driver")
 )
 
+// probeTimeout is how long Probe waits for the handshake of a plugin.
+const probeTimeout = 10 * time.Second
+
 type plugins struct {
 	sync.Mutex
 	plugins map[string]*Plugin
@@ -207,6 +212,52 @@ func Get(name, imp string) (*Plugin, error) {
 	return nil, ErrNotImplements
 }
 
+// Probe checks that the plugin name can be found and answers the handshake
+// as implementing imp, without the retries of Get. Unlike Get, it does not
+// keep the plugin, nor call the handlers of the subsystems it implements.
+func Probe(name, imp string) error {
+	registry := newLocalRegistry()
+	pl, err := registry.Plugin(name)
+	if err != nil {
+		return err
+	}
+	return probe(pl, imp)
+}
+
+func probe(pl *Plugin, imp string) error {
+	c, err := NewClient(pl.Addr, pl.TLSConfig)
+	if err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		body, err := c.callWithRetry("Plugin.Activate", nil, false)
+		if err != nil {
+			done <- err
+			return
+		}
+		defer body.Close()
+		var m Manifest
+		if err := json.NewDecoder(body).Decode(&m); err != nil {
+			done <- err
+			return
+		}
+		for _, i := range m.Implements {
+			if i == imp {
+				done <- nil
+				return
+			}
+		}
+		done <- ErrNotImplements
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(probeTimeout):
+		return fmt.Errorf("timeout waiting for the handshake of plugin %s", pl.Name)
+	}
+}
+
 // Handle adds the specified function to the extpointHandlers.
 func Handle(iface string, fn func(string, *Client)) {
 	extpointHandlers[iface] = fn
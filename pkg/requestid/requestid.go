@@ -0,0 +1,105 @@
+// Package requestid carries the ID of an API request in the contexts of the
+// operations it starts, so that the log lines of the daemon, of
+// libcontainerd and of the graph drivers about a request can be correlated.
+package requestid
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"golang.org/x/net/context"
+)
+
+const (
+	// Header is the HTTP header holding the ID of a request, in the
+	// requests of the clients supplying it and in the responses.
+	Header = "X-Request-Id"
+	// TraceParentHeader is the W3C trace context header, whose trace ID
+	// is used as the ID of the requests without a Header.
+	TraceParentHeader = "Traceparent"
+
+	// LogField is the field of the log lines holding the ID of a request.
+	LogField = "request-id"
+
+	// maxLength is the maximum length of the IDs supplied by the clients.
+	maxLength = 128
+)
+
+type key struct{}
+
+// Generate returns a new random request ID of 32 hexadecimal characters,
+// the format of the W3C trace IDs.
+func Generate() string {
+	b := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		panic(err) // This shouldn't happen
+	}
+	return hex.EncodeToString(b)
+}
+
+// FromHeader returns the request ID supplied in h, either in a Header or as
+// the trace ID of a TraceParentHeader, and an empty string if h doesn't
+// hold a valid one.
+func FromHeader(h http.Header) string {
+	if id := h.Get(Header); Valid(id) {
+		return id
+	}
+	// version-traceid-parentid-flags, such as
+	// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+	parts := strings.Split(h.Get(TraceParentHeader), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[1]) != 32 {
+		return ""
+	}
+	traceID := strings.ToLower(parts[1])
+	if _, err := hex.DecodeString(traceID); err != nil || strings.Trim(traceID, "0") == "" {
+		return ""
+	}
+	return traceID
+}
+
+// Valid returns whether id may be used as a request ID: it must be at most
+// 128 letters, digits, dots, colons, dashes and underscores, so that it
+// can be written as is in the log lines.
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '.', c == ':', c == '-', c == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// WithID returns a copy of ctx carrying the request ID id.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, key{}, id)
+}
+
+// FromContext returns the request ID carried by ctx, and an empty string
+// if ctx doesn't belong to a request.
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(key{}).(string)
+	return id
+}
+
+// Logger returns a logger adding the request ID carried by ctx, if any, to
+// its log lines.
+func Logger(ctx context.Context) *logrus.Entry {
+	entry := logrus.NewEntry(logrus.StandardLogger())
+	if id := FromContext(ctx); id != "" {
+		return entry.WithField(LogField, id)
+	}
+	return entry
+}
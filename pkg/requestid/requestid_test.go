@@ -0,0 +1,60 @@
+package requestid
+
+import (
+	"net/http"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestGenerate(t *testing.T) {
+	id := Generate()
+	if len(id) != 32 || !Valid(id) {
+		t.Fatalf("Unexpected request ID %q", id)
+	}
+	if other := Generate(); other == id {
+		t.Fatalf("Expected different request IDs, got %q twice", id)
+	}
+}
+
+func TestFromHeader(t *testing.T) {
+	cases := []struct {
+		header   http.Header
+		expected string
+	}{
+		{http.Header{}, ""},
+		{http.Header{"X-Request-Id": {"build-42:step.3"}}, "build-42:step.3"},
+		{http.Header{"X-Request-Id": {"bad id\n"}}, ""},
+		{http.Header{"Traceparent": {"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01"}}, "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{http.Header{"Traceparent": {"00-00000000000000000000000000000000-00f067aa0ba902b7-01"}}, ""},
+		{http.Header{"Traceparent": {"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}}, ""},
+		{http.Header{"Traceparent": {"00-4bf92f3577b34da6-00f067aa0ba902b7-01"}}, ""},
+		{http.Header{"Traceparent": {"00-4bf92f3577b34da6a3ce929d0e0e473z-00f067aa0ba902b7-01"}}, ""},
+		{http.Header{
+			"X-Request-Id": {"abc"},
+			"Traceparent":  {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		}, "abc"},
+	}
+	for _, c := range cases {
+		if id := FromHeader(c.header); id != c.expected {
+			t.Fatalf("Expected %q for %v, got %q", c.expected, c.header, id)
+		}
+	}
+}
+
+func TestContext(t *testing.T) {
+	if id := FromContext(context.Background()); id != "" {
+		t.Fatalf("Expected no request ID, got %q", id)
+	}
+	if _, ok := Logger(context.Background()).Data[LogField]; ok {
+		t.Fatal("Expected no request ID in the log fields")
+	}
+
+	ctx := WithID(context.Background(), "abc")
+	if id := FromContext(ctx); id != "abc" {
+		t.Fatalf("Expected abc, got %q", id)
+	}
+	if id := Logger(ctx).Data[LogField]; id != "abc" {
+		t.Fatalf("Expected abc in the log fields, got %v", id)
+	}
+}
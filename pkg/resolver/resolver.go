@@ -0,0 +1,221 @@
+// Package resolver resolves host names with nameservers and host entries of
+// its own rather than with the resolver of the host, and dials the addresses
+// it resolves.
+package resolver
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/docker/libnetwork/resolvconf"
+	"github.com/miekg/dns"
+)
+
+const (
+	defaultDNSPort = "53"
+	queryTimeout   = 5 * time.Second
+)
+
+// Resolver resolves host names with the host entries it is given, then with
+// its nameservers. Host names are queried as fully qualified names: the
+// search domains of the host are not used.
+type Resolver struct {
+	nameservers []string
+	hosts       map[string][]net.IP
+	client      *dns.Client
+}
+
+// New returns a Resolver querying nameservers, addresses of the form ip or
+// ip:port, over TCP if tcp is true and over UDP otherwise. If there are no
+// nameservers, the Resolver queries the nameservers of /etc/resolv.conf over
+// TCP if tcp is true, and uses the resolver of the host otherwise. hosts are
+// host entries of the form host:ip, which take precedence over the
+// nameservers.
+func New(nameservers []string, tcp bool, hosts []string) (*Resolver, error) {
+	r := &Resolver{
+		hosts:  make(map[string][]net.IP),
+		client: &dns.Client{Net: "udp", DialTimeout: queryTimeout, ReadTimeout: queryTimeout, WriteTimeout: queryTimeout},
+	}
+	if tcp {
+		r.client.Net = "tcp"
+		if len(nameservers) == 0 {
+			rc, err := resolvconf.Get()
+			if err != nil {
+				return nil, err
+			}
+			nameservers = resolvconf.GetNameservers(rc.Content, 0)
+			if len(nameservers) == 0 {
+				return nil, fmt.Errorf("no nameserver to query over TCP")
+			}
+		}
+	}
+	for _, ns := range nameservers {
+		addr, err := parseNameserver(ns)
+		if err != nil {
+			return nil, err
+		}
+		r.nameservers = append(r.nameservers, addr)
+	}
+	for _, h := range hosts {
+		host, ip, err := ParseHost(h)
+		if err != nil {
+			return nil, err
+		}
+		r.hosts[host] = append(r.hosts[host], ip)
+	}
+	return r, nil
+}
+
+// ValidateNameserver validates a nameserver of the form ip or ip:port.
+func ValidateNameserver(val string) (string, error) {
+	if _, err := parseNameserver(val); err != nil {
+		return "", err
+	}
+	return val, nil
+}
+
+func parseNameserver(val string) (string, error) {
+	if ip := net.ParseIP(strings.Trim(val, "[]")); ip != nil {
+		return net.JoinHostPort(ip.String(), defaultDNSPort), nil
+	}
+	host, port, err := net.SplitHostPort(val)
+	if err != nil || net.ParseIP(host) == nil || port == "" {
+		return "", fmt.Errorf("invalid nameserver %s: must be of the form ip or ip:port", val)
+	}
+	return val, nil
+}
+
+// ValidateHost validates a host entry of the form host:ip.
+func ValidateHost(val string) (string, error) {
+	if _, _, err := ParseHost(val); err != nil {
+		return "", err
+	}
+	return val, nil
+}
+
+// ParseHost parses a host entry of the form host:ip into its normalized
+// host name and its address.
+func ParseHost(val string) (string, net.IP, error) {
+	parts := strings.SplitN(val, ":", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", nil, fmt.Errorf("invalid host entry %s: must be of the form host:ip", val)
+	}
+	ip := net.ParseIP(strings.Trim(parts[1], "[]"))
+	if ip == nil {
+		return "", nil, fmt.Errorf("invalid host entry %s: %s is not an IP address", val, parts[1])
+	}
+	return normalizeHost(parts[0]), ip, nil
+}
+
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+// LookupIP returns the addresses of host, its IPv4 addresses first.
+func (r *Resolver) LookupIP(host string) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, nil
+	}
+	if ips, ok := r.hosts[normalizeHost(host)]; ok {
+		return ips, nil
+	}
+	if len(r.nameservers) == 0 {
+		return net.LookupIP(host)
+	}
+
+	var (
+		ips     []net.IP
+		lastErr error
+	)
+	for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		found, err := r.query(host, qtype)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		ips = append(ips, found...)
+	}
+	if len(ips) == 0 {
+		if lastErr != nil {
+			return nil, fmt.Errorf("lookup %s: %v", host, lastErr)
+		}
+		return nil, fmt.Errorf("lookup %s: no such host", host)
+	}
+	return ips, nil
+}
+
+// query returns the addresses of type qtype of host, from the first
+// nameserver that answers.
+func (r *Resolver) query(host string, qtype uint16) ([]net.IP, error) {
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(host), qtype)
+
+	var lastErr error
+	for _, ns := range r.nameservers {
+		resp, _, err := r.client.Exchange(m, ns)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		switch resp.Rcode {
+		case dns.RcodeSuccess:
+		case dns.RcodeNameError:
+			return nil, nil
+		default:
+			lastErr = fmt.Errorf("%s answered %s", ns, dns.RcodeToString[resp.Rcode])
+			continue
+		}
+		// The answer holds the records of the CNAME chain of host, if any,
+		// followed by its addresses.
+		var ips []net.IP
+		for _, rr := range resp.Answer {
+			switch rr := rr.(type) {
+			case *dns.A:
+				ips = append(ips, rr.A)
+			case *dns.AAAA:
+				ips = append(ips, rr.AAAA)
+			}
+		}
+		return ips, nil
+	}
+	return nil, lastErr
+}
+
+// Dialer dials the addresses resolved by a Resolver.
+type Dialer struct {
+	resolver *Resolver
+	dialer   *net.Dialer
+}
+
+// Dialer returns a Dialer resolving host names with r and connecting with d.
+func (r *Resolver) Dialer(d *net.Dialer) *Dialer {
+	return &Dialer{resolver: r, dialer: d}
+}
+
+// Dial connects to address on network, trying the addresses of its host in
+// order until one succeeds.
+func (d *Dialer) Dial(network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	ips, err := d.resolver.LookupIP(host)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for _, ip := range ips {
+		conn, err := d.dialer.Dial(network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("lookup %s: no such host", host)
+	}
+	return nil, lastErr
+}
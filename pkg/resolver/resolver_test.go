@@ -0,0 +1,132 @@
+package resolver
+
+import (
+	"net"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+// startServer starts a nameserver over proto answering that registry.local
+// is 10.0.0.1, and returns its address.
+func startServer(t *testing.T, proto string) (string, func()) {
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(req)
+		q := req.Question[0]
+		switch {
+		case q.Name != "registry.local.":
+			m.SetRcode(req, dns.RcodeNameError)
+		case q.Qtype == dns.TypeA:
+			rr, _ := dns.NewRR("registry.local. 60 IN A 10.0.0.1")
+			m.Answer = append(m.Answer, rr)
+		}
+		w.WriteMsg(m)
+	})
+
+	started := make(chan struct{})
+	srv := &dns.Server{Handler: handler, NotifyStartedFunc: func() { close(started) }}
+	var addr string
+	if proto == "tcp" {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		srv.Listener = l
+		addr = l.Addr().String()
+	} else {
+		pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		srv.PacketConn = pc
+		addr = pc.LocalAddr().String()
+	}
+	go srv.ActivateAndServe()
+	<-started
+	return addr, func() { srv.Shutdown() }
+}
+
+func TestLookupIP(t *testing.T) {
+	for _, proto := range []string{"udp", "tcp"} {
+		addr, stop := startServer(t, proto)
+		defer stop()
+
+		r, err := New([]string{addr}, proto == "tcp", []string{"Mirror.Local.:10.0.0.2", "v6.local:::1"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		cases := []struct {
+			host string
+			ip   string
+		}{
+			{"registry.local", "10.0.0.1"},
+			{"mirror.local", "10.0.0.2"},
+			{"v6.local", "::1"},
+			{"192.168.1.1", "192.168.1.1"},
+		}
+		for _, tc := range cases {
+			ips, err := r.LookupIP(tc.host)
+			if err != nil {
+				t.Fatalf("%s: %s: %v", proto, tc.host, err)
+			}
+			if len(ips) != 1 || !ips[0].Equal(net.ParseIP(tc.ip)) {
+				t.Fatalf("%s: %s: expected %s, got %v", proto, tc.host, tc.ip, ips)
+			}
+		}
+		if ips, err := r.LookupIP("unknown.local"); err == nil {
+			t.Fatalf("%s: expected an error, got %v", proto, ips)
+		}
+	}
+}
+
+func TestDial(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		if conn, err := l.Accept(); err == nil {
+			conn.Close()
+		}
+	}()
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+
+	r, err := New(nil, false, []string{"registry.local:127.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := r.Dialer(&net.Dialer{}).Dial("tcp", net.JoinHostPort("registry.local", port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+}
+
+func TestNewInvalid(t *testing.T) {
+	if _, err := New([]string{"ns.local"}, false, nil); err == nil {
+		t.Fatal("expected an error for a nameserver that is not an IP address")
+	}
+	if _, err := New(nil, false, []string{"registry.local"}); err == nil {
+		t.Fatal("expected an error for a host entry without address")
+	}
+	if _, err := New(nil, false, []string{"registry.local:registry"}); err == nil {
+		t.Fatal("expected an error for a host entry with an invalid address")
+	}
+}
+
+func TestValidateNameserver(t *testing.T) {
+	valid := []string{"8.8.8.8", "8.8.8.8:5353", "::1", "[::1]:5353"}
+	for _, v := range valid {
+		if _, err := ValidateNameserver(v); err != nil {
+			t.Fatalf("%s: %v", v, err)
+		}
+	}
+	invalid := []string{"", "ns.local", "ns.local:53", "8.8.8.8:"}
+	for _, v := range invalid {
+		if _, err := ValidateNameserver(v); err == nil {
+			t.Fatalf("%s: expected an error", v)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package rootless
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+)
+
+// PortForwarder forwards ports of the host to the network namespace of the
+// daemon, through the API socket of the network helper.
+type PortForwarder struct {
+	socket string
+}
+
+// NewPortForwarder returns the port forwarder of the network helper of the
+// daemon run by Run, or nil if the daemon has no network helper.
+func NewPortForwarder() *PortForwarder {
+	socket := os.Getenv(netAPIEnv)
+	if socket == "" {
+		return nil
+	}
+	return &PortForwarder{socket: socket}
+}
+
+type hostfwd struct {
+	Proto     string `json:"proto"`
+	HostAddr  string `json:"host_addr,omitempty"`
+	HostPort  int    `json:"host_port"`
+	GuestPort int    `json:"guest_port"`
+}
+
+// Add forwards the port hostPort of the address hostIP of the host to the
+// port guestPort of the network namespace of the daemon, for proto, "tcp"
+// or "udp". It returns the ID of the forwarding.
+func (f *PortForwarder) Add(proto, hostIP string, hostPort, guestPort int) (int, error) {
+	var ret struct {
+		ID int `json:"id"`
+	}
+	err := f.call("add_hostfwd", hostfwd{Proto: proto, HostAddr: hostIP, HostPort: hostPort, GuestPort: guestPort}, &ret)
+	if err != nil {
+		return 0, fmt.Errorf("failed to forward the %s port %s:%d of the host: %v", proto, hostIP, hostPort, err)
+	}
+	return ret.ID, nil
+}
+
+// Remove removes the forwarding id.
+func (f *PortForwarder) Remove(id int) error {
+	return f.call("remove_hostfwd", map[string]int{"id": id}, nil)
+}
+
+// call executes a command of the API of the network helper, and decodes its
+// return value in ret.
+func (f *PortForwarder) call(execute string, arguments interface{}, ret interface{}) error {
+	conn, err := net.Dial("unix", f.socket)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	req := map[string]interface{}{"execute": execute, "arguments": arguments}
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return err
+	}
+	// the helper answers once the request is complete
+	if err := conn.(*net.UnixConn).CloseWrite(); err != nil {
+		return err
+	}
+
+	var resp struct {
+		Return json.RawMessage `json:"return"`
+		Error  *struct {
+			Desc string `json:"desc"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return errors.New(resp.Error.Desc)
+	}
+	if ret == nil || len(resp.Return) == 0 {
+		return nil
+	}
+	return json.Unmarshal(resp.Return, ret)
+}
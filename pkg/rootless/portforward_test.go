@@ -0,0 +1,87 @@
+package rootless
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// serveNetAPI serves the API of a network helper on a socket in dir, which
+// answers every request with resp, and sends the requests it gets to reqs.
+func serveNetAPI(t *testing.T, dir string, resp string, reqs chan<- map[string]interface{}) *PortForwarder {
+	socket := filepath.Join(dir, "net.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		defer l.Close()
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			var req map[string]interface{}
+			if err := json.NewDecoder(conn).Decode(&req); err == nil {
+				reqs <- req
+			}
+			conn.Write([]byte(resp))
+			conn.Close()
+		}
+	}()
+	return &PortForwarder{socket: socket}
+}
+
+func TestPortForwarderAdd(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rootless-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	reqs := make(chan map[string]interface{}, 1)
+	f := serveNetAPI(t, dir, `{"return": {"id": 7}}`, reqs)
+	id, err := f.Add("tcp", "0.0.0.0", 8080, 8080)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 7 {
+		t.Fatalf("Expected the forwarding 7, got %d", id)
+	}
+	req := <-reqs
+	if req["execute"] != "add_hostfwd" {
+		t.Fatalf("Expected an add_hostfwd request, got %v", req)
+	}
+	args := req["arguments"].(map[string]interface{})
+	if args["proto"] != "tcp" || args["host_addr"] != "0.0.0.0" || args["host_port"] != 8080.0 || args["guest_port"] != 8080.0 {
+		t.Fatalf("Unexpected arguments %v", args)
+	}
+}
+
+func TestPortForwarderError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rootless-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	reqs := make(chan map[string]interface{}, 1)
+	f := serveNetAPI(t, dir, `{"error": {"desc": "bad request"}}`, reqs)
+	if err := f.Remove(3); err == nil || err.Error() != "bad request" {
+		t.Fatalf("Expected the error of the network helper, got %v", err)
+	}
+	req := <-reqs
+	if req["execute"] != "remove_hostfwd" || req["arguments"].(map[string]interface{})["id"] != 3.0 {
+		t.Fatalf("Unexpected request %v", req)
+	}
+}
+
+func TestNewPortForwarder(t *testing.T) {
+	os.Unsetenv(netAPIEnv)
+	if f := NewPortForwarder(); f != nil {
+		t.Fatalf("Expected no port forwarder without a network helper, got %v", f)
+	}
+}
@@ -0,0 +1,47 @@
+// Package rootless runs the daemon as an unprivileged user: the daemon is
+// root of a user namespace mapped to the user and its subordinate IDs, in its
+// own mount and network namespaces. A user mode network helper, such as
+// slirp4netns, connects the network namespace of the daemon to the network of
+// the host, and forwards the ports published by containers.
+package rootless
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/docker/pkg/homedir"
+)
+
+const (
+	// childEnv is set in the environment of the daemon run by Run.
+	childEnv = "DOCKERD_ROOTLESS_CHILD"
+	// netAPIEnv is the path of the API socket of the network helper, in
+	// the environment of the daemon run by Run.
+	netAPIEnv = "DOCKERD_ROOTLESS_NET_API"
+)
+
+// IsChild returns whether the process is the daemon run by Run in its
+// namespaces.
+func IsChild() bool {
+	return os.Getenv(childEnv) == "1"
+}
+
+// Dirs returns the default directories of a rootless daemon: its data
+// directory, $XDG_DATA_HOME/docker, and its runtime directory,
+// $XDG_RUNTIME_DIR/docker.
+func Dirs() (dataDir, runtimeDir string, err error) {
+	xdgRuntimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if xdgRuntimeDir == "" {
+		return "", "", errors.New("XDG_RUNTIME_DIR must be set to run the daemon in rootless mode")
+	}
+	xdgDataHome := os.Getenv("XDG_DATA_HOME")
+	if xdgDataHome == "" {
+		home := homedir.Get()
+		if home == "" {
+			return "", "", errors.New("XDG_DATA_HOME or HOME must be set to run the daemon in rootless mode")
+		}
+		xdgDataHome = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(xdgDataHome, "docker"), filepath.Join(xdgRuntimeDir, "docker"), nil
+}
@@ -0,0 +1,175 @@
+// +build linux
+
+package rootless
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/pkg/idtools"
+	"github.com/docker/docker/pkg/reexec"
+	"github.com/opencontainers/runc/libcontainer/user"
+)
+
+const childInit = "dockerd-rootless-child"
+
+func init() {
+	reexec.Register(childInit, runChild)
+}
+
+// runChild waits for the parent to set up the namespaces of the child, and
+// runs the daemon in them.
+func runChild() {
+	// The parent closes the synchronization pipe once the ID mappings of
+	// the user namespace are written.
+	syncPipe := os.NewFile(3, "sync")
+	if _, err := ioutil.ReadAll(syncPipe); err != nil {
+		logrus.Fatalf("Failed to wait for the rootless namespaces: %v", err)
+	}
+	syncPipe.Close()
+
+	// The mounts of the daemon stay in its mount namespace.
+	if err := syscall.Mount("", "/", "", syscall.MS_REC|syscall.MS_SLAVE, ""); err != nil {
+		logrus.Fatalf("Failed to isolate the mounts of the rootless daemon: %v", err)
+	}
+
+	args := append([]string{"dockerd"}, os.Args[1:]...)
+	env := append(os.Environ(), childEnv+"=1")
+	if err := syscall.Exec(reexec.Self(), args, env); err != nil {
+		logrus.Fatalf("Failed to run the rootless daemon: %v", err)
+	}
+}
+
+// Run runs the daemon with args as root of a new user namespace, mapped to
+// the current user and its subordinate IDs in /etc/subuid and /etc/subgid,
+// in new mount and network namespaces. netHelper, if set, connects the
+// network namespace to the network of the host. Run forwards the signals it
+// gets to the daemon, and returns once the daemon exits.
+func Run(args []string, netHelper string) error {
+	uid, gid := os.Getuid(), os.Getgid()
+	if uid == 0 {
+		return errors.New("the rootless mode is for unprivileged users: run the daemon without --rootless as root")
+	}
+	_, runtimeDir, err := Dirs()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(runtimeDir, 0700); err != nil {
+		return err
+	}
+
+	syncR, syncW, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer syncW.Close()
+
+	cmd := reexec.Command(append([]string{childInit}, args...)...)
+	cmd.SysProcAttr.Cloneflags = syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWNET
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.ExtraFiles = []*os.File{syncR}
+	cmd.Env = os.Environ()
+	netAPI := filepath.Join(runtimeDir, "net.sock")
+	if netHelper != "" {
+		os.Remove(netAPI)
+		cmd.Env = append(cmd.Env, netAPIEnv+"="+netAPI)
+	}
+	if err := cmd.Start(); err != nil {
+		syncR.Close()
+		return fmt.Errorf("failed to start the rootless daemon: %v", err)
+	}
+	syncR.Close()
+	pid := cmd.Process.Pid
+
+	if err := writeIDMappings(pid, uid, gid); err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return err
+	}
+	if netHelper != "" {
+		helper := exec.Command(netHelper, "--configure", "--mtu=65520", "--api-socket", netAPI, strconv.Itoa(pid), "tap0")
+		helper.Stdout, helper.Stderr = os.Stdout, os.Stderr
+		helper.SysProcAttr = &syscall.SysProcAttr{Pdeathsig: syscall.SIGKILL}
+		if err := helper.Start(); err != nil {
+			cmd.Process.Kill()
+			cmd.Wait()
+			return fmt.Errorf("failed to start the network helper %s: %v", netHelper, err)
+		}
+		defer func() {
+			helper.Process.Kill()
+			helper.Wait()
+			os.Remove(netAPI)
+		}()
+	}
+	// the daemon runs once the synchronization pipe is closed
+	syncW.Close()
+
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGHUP, syscall.SIGUSR1)
+	defer signal.Stop(sigc)
+	go func() {
+		for sig := range sigc {
+			cmd.Process.Signal(sig)
+		}
+	}()
+	return cmd.Wait()
+}
+
+// writeIDMappings maps the root of the user namespace of the process pid to
+// uid and gid, and the other IDs to the subordinate IDs of the user, with the
+// setuid helpers newuidmap and newgidmap. Without subordinate IDs, only root
+// is mapped.
+func writeIDMappings(pid, uid, gid int) error {
+	u, err := user.LookupUid(uid)
+	if err != nil {
+		return err
+	}
+	g, err := user.LookupGid(gid)
+	if err != nil {
+		return err
+	}
+	uidMaps, gidMaps, err := idtools.CreateIDMappings(u.Name, g.Name)
+	if err != nil {
+		logrus.Warnf("Mapping only the root of the rootless daemon: %v", err)
+		return writeRootMapping(pid, uid, gid)
+	}
+	if err := runIDMapHelper("newuidmap", pid, uid, uidMaps); err != nil {
+		return err
+	}
+	return runIDMapHelper("newgidmap", pid, gid, gidMaps)
+}
+
+// runIDMapHelper runs helper to map root to id, and the IDs from 1 to
+// subIDs, in the user namespace of pid.
+func runIDMapHelper(helper string, pid, id int, subIDs []idtools.IDMap) error {
+	args := []string{strconv.Itoa(pid), "0", strconv.Itoa(id), "1"}
+	for _, m := range subIDs {
+		args = append(args, strconv.Itoa(m.ContainerID+1), strconv.Itoa(m.HostID), strconv.Itoa(m.Size))
+	}
+	if out, err := exec.Command(helper, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to map the IDs of the rootless daemon with %s: %v: %s", helper, err, out)
+	}
+	return nil
+}
+
+// writeRootMapping maps the root of the user namespace of pid to uid and
+// gid, which an unprivileged user may do without the setuid helpers.
+func writeRootMapping(pid, uid, gid int) error {
+	proc := filepath.Join("/proc", strconv.Itoa(pid))
+	if err := ioutil.WriteFile(filepath.Join(proc, "uid_map"), []byte(fmt.Sprintf("0 %d 1\n", uid)), 0); err != nil {
+		return err
+	}
+	// setgroups must be denied to map the group without privileges
+	if err := ioutil.WriteFile(filepath.Join(proc, "setgroups"), []byte("deny"), 0); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(proc, "gid_map"), []byte(fmt.Sprintf("0 %d 1\n", gid)), 0)
+}
@@ -0,0 +1,27 @@
+package rootless
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirs(t *testing.T) {
+	defer os.Setenv("XDG_RUNTIME_DIR", os.Getenv("XDG_RUNTIME_DIR"))
+	defer os.Setenv("XDG_DATA_HOME", os.Getenv("XDG_DATA_HOME"))
+
+	os.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+	os.Setenv("XDG_DATA_HOME", "/home/user/.data")
+	dataDir, runtimeDir, err := Dirs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dataDir != filepath.Join("/home/user/.data", "docker") || runtimeDir != filepath.Join("/run/user/1000", "docker") {
+		t.Fatalf("Unexpected directories %s and %s", dataDir, runtimeDir)
+	}
+
+	os.Setenv("XDG_RUNTIME_DIR", "")
+	if _, _, err := Dirs(); err == nil {
+		t.Fatal("Expected an error without XDG_RUNTIME_DIR")
+	}
+}
@@ -0,0 +1,11 @@
+// +build !linux
+
+package rootless
+
+import "errors"
+
+// Run runs the daemon with args in rootless mode, which is only supported
+// on Linux.
+func Run(args []string, netHelper string) error {
+	return errors.New("the rootless mode is only supported on Linux")
+}
@@ -21,8 +21,10 @@ const (
 // really support signals in any way, shape or form that Unix does.
 //
 // We have these so that docker kill can be used to gracefully (TERM) and
-// forcibly (KILL) terminate a container on Windows.
+// forcibly (KILL) terminate a container on Windows, and to interrupt the
+// main process of a container with a terminal (INT).
 var SignalMap = map[string]syscall.Signal{
+	"INT":  syscall.SIGINT,
 	"KILL": syscall.SIGKILL,
 	"TERM": syscall.SIGTERM,
 }
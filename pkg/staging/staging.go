@@ -0,0 +1,245 @@
+// Package staging manages the directories in which the daemon stages the
+// temporary files of its transfers, such as the layers being downloaded or
+// the contexts of the builds, with a quota on their total size.
+package staging
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/docker/docker/pkg/ioutils"
+	"github.com/docker/go-units"
+)
+
+// QuotaError is returned when staging more data would exceed the quota of
+// an area.
+type QuotaError struct {
+	Dir   string
+	Quota int64
+}
+
+func (e QuotaError) Error() string {
+	return fmt.Sprintf("the staging area %s is full: its quota of %s is exceeded", e.Dir, units.BytesSize(float64(e.Quota)))
+}
+
+// Area is a directory in which temporary files are staged. The size of the
+// data staged in its files, and read by the readers it returns, is limited
+// by its quota. The nil Area is the temporary directory of the system,
+// without quota.
+type Area struct {
+	dir   string
+	quota int64
+
+	mu   sync.Mutex
+	used int64
+}
+
+// New returns the area dir, created if it doesn't exist, with a quota of
+// quota bytes. A quota of 0 is unlimited.
+func New(dir string, quota int64) (*Area, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &Area{dir: dir, quota: quota}, nil
+}
+
+// Dir returns the directory of the area.
+func (a *Area) Dir() string {
+	if a == nil {
+		return os.TempDir()
+	}
+	return a.dir
+}
+
+// Used returns the size of the data staged in the area.
+func (a *Area) Used() int64 {
+	if a == nil {
+		return 0
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.used
+}
+
+func (a *Area) reserve(n int64) error {
+	if a == nil || n <= 0 {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.quota > 0 && a.used+n > a.quota {
+		return QuotaError{Dir: a.dir, Quota: a.quota}
+	}
+	a.used += n
+	return nil
+}
+
+func (a *Area) release(n int64) {
+	if a == nil || n <= 0 {
+		return
+	}
+	a.mu.Lock()
+	a.used -= n
+	a.mu.Unlock()
+}
+
+// TempFile creates a temporary file with a name starting with prefix in the
+// area.
+func (a *Area) TempFile(prefix string) (*File, error) {
+	var dir string
+	if a != nil {
+		dir = a.dir
+	}
+	f, err := ioutil.TempFile(dir, prefix)
+	if err != nil {
+		return nil, err
+	}
+	return &File{File: f, area: a}, nil
+}
+
+// TempDir creates a temporary directory with a name starting with prefix in
+// the area. The data written to it is not counted against the quota of the
+// area; only the data read from the readers returned by Reader is.
+func (a *Area) TempDir(prefix string) (string, error) {
+	var dir string
+	if a != nil {
+		dir = a.dir
+	}
+	return ioutils.TempDir(dir, prefix)
+}
+
+// Reader returns a reader of r which fails with a QuotaError when the data
+// read from it exceeds the quota of the area, for the data read from r to
+// be staged, such as a tar stream extracted to a temporary directory. The
+// data read is counted against the quota until release is called.
+func (a *Area) Reader(r io.Reader) (reader io.Reader, release func()) {
+	qr := &quotaReader{r: r, area: a}
+	return qr, qr.release
+}
+
+type quotaReader struct {
+	r    io.Reader
+	area *Area
+
+	mu   sync.Mutex
+	size int64
+}
+
+func (r *quotaReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		if qerr := r.area.reserve(int64(n)); qerr != nil {
+			return 0, qerr
+		}
+		r.mu.Lock()
+		r.size += int64(n)
+		r.mu.Unlock()
+	}
+	return n, err
+}
+
+func (r *quotaReader) release() {
+	r.mu.Lock()
+	size := r.size
+	r.size = 0
+	r.mu.Unlock()
+	r.area.release(size)
+}
+
+// File is a temporary file of an area. Its size is counted against the
+// quota of the area until it is truncated or removed: a write growing it
+// beyond the quota fails with a QuotaError.
+type File struct {
+	*os.File
+	area *Area
+	size int64
+}
+
+// Write writes p at the current offset of the file.
+func (f *File) Write(p []byte) (int, error) {
+	if f.area == nil {
+		return f.File.Write(p)
+	}
+	offset, err := f.File.Seek(0, os.SEEK_CUR)
+	if err != nil {
+		return 0, err
+	}
+	growth := offset + int64(len(p)) - f.size
+	if err := f.area.reserve(growth); err != nil {
+		return 0, err
+	}
+	n, err := f.File.Write(p)
+	if growth > 0 {
+		// only keep the growth actually written
+		written := offset + int64(n) - f.size
+		if written < 0 {
+			written = 0
+		}
+		f.area.release(growth - written)
+		f.size += written
+	}
+	return n, err
+}
+
+// WriteString is like Write, but writes the contents of s.
+func (f *File) WriteString(s string) (int, error) {
+	return f.Write([]byte(s))
+}
+
+// ReadFrom reads from r until EOF, through Write so that the data read is
+// counted against the quota of the area.
+func (f *File) ReadFrom(r io.Reader) (int64, error) {
+	return io.Copy(struct{ io.Writer }{f}, r)
+}
+
+// Truncate changes the size of the file to size, which is at most its
+// current size.
+func (f *File) Truncate(size int64) error {
+	if err := f.File.Truncate(size); err != nil {
+		return err
+	}
+	if size < f.size {
+		f.area.release(f.size - size)
+		f.size = size
+	}
+	return nil
+}
+
+// Remove closes and removes the file.
+func (f *File) Remove() error {
+	f.File.Close()
+	f.area.release(f.size)
+	f.size = 0
+	return os.RemoveAll(f.Name())
+}
+
+// Cleanup removes the files and directories of the area with a name
+// starting with one of prefixes, left by a previous run of the daemon. It
+// returns the number of entries removed.
+func (a *Area) Cleanup(prefixes ...string) (int, error) {
+	entries, err := ioutil.ReadDir(a.Dir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	removed := 0
+	for _, e := range entries {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(e.Name(), prefix) {
+				if err := os.RemoveAll(filepath.Join(a.Dir(), e.Name())); err != nil {
+					return removed, err
+				}
+				removed++
+				break
+			}
+		}
+	}
+	return removed, nil
+}
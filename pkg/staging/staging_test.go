@@ -0,0 +1,163 @@
+package staging
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestArea(t *testing.T, quota int64) (*Area, func()) {
+	dir, err := ioutil.TempDir("", "staging-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := New(filepath.Join(dir, "area"), quota)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return a, func() { os.RemoveAll(dir) }
+}
+
+func TestFileQuota(t *testing.T) {
+	a, cleanup := newTestArea(t, 10)
+	defer cleanup()
+
+	f, err := a.TempFile("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(f.Name()) != a.Dir() {
+		t.Fatalf("expected the file in %s, got %s", a.Dir(), f.Name())
+	}
+	if _, err := f.Write([]byte("12345678")); err != nil {
+		t.Fatal(err)
+	}
+	if used := a.Used(); used != 8 {
+		t.Fatalf("expected 8 bytes used, got %d", used)
+	}
+	if _, err := f.Write([]byte("9abc")); err == nil {
+		t.Fatal("expected a quota error writing beyond the quota, got nil")
+	} else if _, ok := err.(QuotaError); !ok {
+		t.Fatalf("expected a quota error, got %v", err)
+	}
+	if used := a.Used(); used != 8 {
+		t.Fatalf("expected 8 bytes used after the failed write, got %d", used)
+	}
+
+	// rewriting the data already staged doesn't count against the quota
+	if _, err := f.Seek(0, os.SEEK_SET); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("1234567890")); err != nil {
+		t.Fatal(err)
+	}
+	if used := a.Used(); used != 10 {
+		t.Fatalf("expected 10 bytes used, got %d", used)
+	}
+
+	if err := f.Truncate(4); err != nil {
+		t.Fatal(err)
+	}
+	if used := a.Used(); used != 4 {
+		t.Fatalf("expected 4 bytes used after the truncation, got %d", used)
+	}
+	if err := f.Remove(); err != nil {
+		t.Fatal(err)
+	}
+	if used := a.Used(); used != 0 {
+		t.Fatalf("expected no bytes used after the removal, got %d", used)
+	}
+	if _, err := os.Stat(f.Name()); !os.IsNotExist(err) {
+		t.Fatalf("expected the file to be removed, got %v", err)
+	}
+}
+
+func TestFileCopyQuota(t *testing.T) {
+	a, cleanup := newTestArea(t, 10)
+	defer cleanup()
+
+	f, err := a.TempFile("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Remove()
+	if _, err := io.Copy(f, strings.NewReader("0123456789abcdef")); err == nil {
+		t.Fatal("expected a quota error copying beyond the quota, got nil")
+	}
+	if used := a.Used(); used > 10 {
+		t.Fatalf("expected at most 10 bytes used, got %d", used)
+	}
+}
+
+func TestReaderQuota(t *testing.T) {
+	a, cleanup := newTestArea(t, 10)
+	defer cleanup()
+
+	r, release := a.Reader(strings.NewReader("01234567"))
+	if _, err := ioutil.ReadAll(r); err != nil {
+		t.Fatal(err)
+	}
+	if used := a.Used(); used != 8 {
+		t.Fatalf("expected 8 bytes used, got %d", used)
+	}
+	r2, release2 := a.Reader(strings.NewReader("01234567"))
+	if _, err := ioutil.ReadAll(r2); err == nil {
+		t.Fatal("expected a quota error reading beyond the quota, got nil")
+	}
+	release2()
+	release()
+	if used := a.Used(); used != 0 {
+		t.Fatalf("expected no bytes used after the release, got %d", used)
+	}
+}
+
+func TestNilArea(t *testing.T) {
+	var a *Area
+	if a.Dir() != os.TempDir() {
+		t.Fatalf("expected the nil area in %s, got %s", os.TempDir(), a.Dir())
+	}
+	f, err := a.TempFile("staging-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Remove()
+	data := bytes.Repeat([]byte("a"), 1024)
+	if _, err := f.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	r, release := a.Reader(bytes.NewReader(data))
+	defer release()
+	if _, err := ioutil.ReadAll(r); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCleanup(t *testing.T) {
+	a, cleanup := newTestArea(t, 0)
+	defer cleanup()
+
+	for _, name := range []string{"GetImageBlob123", "docker-builder456", "other"} {
+		if err := os.Mkdir(filepath.Join(a.Dir(), name), 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	n, err := a.Cleanup("GetImageBlob", "docker-builder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Fatalf("expected 2 entries removed, got %d", n)
+	}
+	entries, err := ioutil.ReadDir(a.Dir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "other" {
+		t.Fatalf("expected only other to be kept, got %v", entries)
+	}
+}
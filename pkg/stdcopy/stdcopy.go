@@ -22,6 +22,9 @@ const (
 	Stdout
 	// Stderr represents standard error steam type.
 	Stderr
+	// Systemerr represents errors originating from the daemon, such as the
+	// notice of a stream it ended.
+	Systemerr
 
 	stdWriterPrefixLen = 8
 	stdWriterFdIndex   = 0
@@ -72,7 +75,7 @@ func (w *stdWriter) Write(p []byte) (n int, err error) {
 // and written to the underlying `w` stream.
 // This allows multiple write streams (e.g. stdout and stderr) to be muxed into a single connection.
 // `t` indicates the id of the stream to encapsulate.
-// It can be stdcopy.Stdin, stdcopy.Stdout, stdcopy.Stderr.
+// It can be stdcopy.Stdin, stdcopy.Stdout, stdcopy.Stderr, stdcopy.Systemerr.
 func NewStdWriter(w io.Writer, t StdType) io.Writer {
 	return &stdWriter{
 		Writer: w,
@@ -88,6 +91,7 @@ func NewStdWriter(w io.Writer, t StdType) io.Writer {
 //
 // StdCopy will read until it hits EOF on `src`. It will then return a nil error.
 // In other words: if `err` is non nil, it indicates a real underlying error.
+// A Systemerr frame stops the copy, its content is returned as an error.
 //
 // `written` will hold the total number of bytes written to `dstout` and `dsterr`.
 func StdCopy(dstout, dsterr io.Writer, src io.Reader) (written int64, err error) {
@@ -129,6 +133,9 @@ func StdCopy(dstout, dsterr io.Writer, src io.Reader) (written int64, err error)
 		case Stderr:
 			// Write on stderr
 			out = dsterr
+		case Systemerr:
+			// The frame is returned as an error once fully read
+			out = nil
 		default:
 			logrus.Debugf("Error selecting output fd: (%d)", buf[stdWriterFdIndex])
 			return 0, fmt.Errorf("Unrecognized input header: %d", buf[stdWriterFdIndex])
@@ -164,6 +171,10 @@ func StdCopy(dstout, dsterr io.Writer, src io.Reader) (written int64, err error)
 			}
 		}
 
+		if out == nil {
+			return written, fmt.Errorf("error from daemon in stream: %s", string(buf[stdWriterPrefixLen:frameSize+stdWriterPrefixLen]))
+		}
+
 		// Write the retrieved frame (without header)
 		nw, ew = out.Write(buf[stdWriterPrefixLen : frameSize+stdWriterPrefixLen])
 		if ew != nil {
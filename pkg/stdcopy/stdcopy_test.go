@@ -196,6 +196,22 @@ func TestStdCopyWithInvalidInputHeader(t *testing.T) {
 	}
 }
 
+func TestStdCopyReturnsSystemErrors(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	NewStdWriter(buffer, Stdout).Write([]byte("output"))
+	NewStdWriter(buffer, Systemerr).Write([]byte("stream closed"))
+	NewStdWriter(buffer, Stdout).Write([]byte("ignored"))
+
+	stdout := new(bytes.Buffer)
+	written, err := StdCopy(stdout, ioutil.Discard, buffer)
+	if err == nil || !strings.Contains(err.Error(), "stream closed") {
+		t.Fatalf("Expected the system error, got %v", err)
+	}
+	if written != int64(len("output")) || stdout.String() != "output" {
+		t.Fatalf("Expected the output before the system error, got %q", stdout.String())
+	}
+}
+
 func TestStdCopyWithCorruptedPrefix(t *testing.T) {
 	data := []byte{0x01, 0x02, 0x03}
 	src := bytes.NewReader(data)
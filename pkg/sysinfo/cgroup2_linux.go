@@ -0,0 +1,116 @@
+package sysinfo
+
+import (
+	"io/ioutil"
+	"path"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/Sirupsen/logrus"
+)
+
+const (
+	// cgroup2Root is the mount point of the unified hierarchy.
+	cgroup2Root = "/sys/fs/cgroup"
+	// cgroup2SuperMagic is the filesystem type of the unified hierarchy.
+	cgroup2SuperMagic = 0x63677270
+)
+
+var (
+	unifiedOnce sync.Once
+	unified     bool
+)
+
+// IsCgroup2UnifiedMode returns whether the host mounts the cgroups in the
+// unified hierarchy of cgroup v2, rather than one hierarchy per controller.
+func IsCgroup2UnifiedMode() bool {
+	unifiedOnce.Do(func() {
+		var st syscall.Statfs_t
+		if err := syscall.Statfs(cgroup2Root, &st); err == nil {
+			unified = int64(st.Type) == cgroup2SuperMagic
+		}
+	})
+	return unified
+}
+
+// checkCgroup2 sets the cgroup features of sysInfo from the controllers
+// available in the unified hierarchy mounted at mountPoint.
+func checkCgroup2(sysInfo *SysInfo, mountPoint string, quiet bool) {
+	controllers := make(map[string]bool)
+	b, err := ioutil.ReadFile(path.Join(mountPoint, "cgroup.controllers"))
+	if err != nil {
+		logrus.Warnf("Failed to read the cgroup v2 controllers: %v", err)
+	}
+	for _, c := range strings.Fields(string(b)) {
+		controllers[c] = true
+	}
+	warn := func(msg string) {
+		if !quiet {
+			logrus.Warn(msg)
+		}
+	}
+
+	if controllers["memory"] {
+		// memory.max, memory.swap.max and memory.low; cgroup v2 has no
+		// OOM killer control, swappiness nor kernel memory limit.
+		sysInfo.cgroupMemInfo = cgroupMemInfo{
+			MemoryLimit:       true,
+			SwapLimit:         true,
+			MemoryReservation: true,
+		}
+		warn("cgroup v2 does not support oom control, memory swappiness nor kernel memory limit")
+	} else {
+		warn("Unable to find the memory controller of cgroup v2")
+	}
+
+	if controllers["cpu"] {
+		// cpu.weight and cpu.max
+		sysInfo.cgroupCPUInfo = cgroupCPUInfo{
+			CPUShares:    true,
+			CPUCfsPeriod: true,
+			CPUCfsQuota:  true,
+		}
+	} else {
+		warn("Unable to find the cpu controller of cgroup v2")
+	}
+
+	if controllers["io"] {
+		// io.weight and io.max
+		sysInfo.cgroupBlkioInfo = cgroupBlkioInfo{
+			BlkioWeight:          true,
+			BlkioWeightDevice:    true,
+			BlkioReadBpsDevice:   true,
+			BlkioWriteBpsDevice:  true,
+			BlkioReadIOpsDevice:  true,
+			BlkioWriteIOpsDevice: true,
+		}
+	} else {
+		warn("Unable to find the io controller of cgroup v2")
+	}
+
+	if controllers["cpuset"] {
+		cpus, err := ioutil.ReadFile(path.Join(mountPoint, "cpuset.cpus.effective"))
+		if err == nil {
+			mems, err := ioutil.ReadFile(path.Join(mountPoint, "cpuset.mems.effective"))
+			if err == nil {
+				sysInfo.cgroupCpusetInfo = cgroupCpusetInfo{
+					Cpuset: true,
+					Cpus:   strings.TrimSpace(string(cpus)),
+					Mems:   strings.TrimSpace(string(mems)),
+				}
+			}
+		}
+	} else {
+		warn("Unable to find the cpuset controller of cgroup v2")
+	}
+
+	sysInfo.PidsLimit = controllers["pids"]
+	if !sysInfo.PidsLimit {
+		warn("Unable to find the pids controller of cgroup v2")
+	}
+
+	// cgroup v2 controls the devices with eBPF programs, which are always
+	// available.
+	sysInfo.CgroupDevicesEnabled = true
+}
@@ -27,6 +27,9 @@ type SysInfo struct {
 
 	// Whether the cgroup has the mountpoint of "devices" or not
 	CgroupDevicesEnabled bool
+
+	// Whether the cgroups are mounted in the unified hierarchy of cgroup v2
+	CgroupUnified bool
 }
 
 type cgroupMemInfo struct {
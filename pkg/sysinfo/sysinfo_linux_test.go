@@ -56,3 +56,43 @@ func TestCgroupEnabled(t *testing.T) {
 		t.Fatal("cgroupEnabled should be true")
 	}
 }
+
+func TestCheckCgroup2(t *testing.T) {
+	cgroupDir, err := ioutil.TempDir("", "cgroup2-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(cgroupDir)
+
+	files := map[string]string{
+		"cgroup.controllers":    "cpuset cpu memory pids\n",
+		"cpuset.cpus.effective": "0-3\n",
+		"cpuset.mems.effective": "0\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(path.Join(cgroupDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sysInfo := &SysInfo{}
+	checkCgroup2(sysInfo, cgroupDir, true)
+	if !sysInfo.MemoryLimit || !sysInfo.SwapLimit || !sysInfo.MemoryReservation {
+		t.Fatalf("expected the memory limits to be supported, got %+v", sysInfo.cgroupMemInfo)
+	}
+	if sysInfo.OomKillDisable || sysInfo.MemorySwappiness || sysInfo.KernelMemory {
+		t.Fatalf("expected the cgroup v1 memory controls to be unsupported, got %+v", sysInfo.cgroupMemInfo)
+	}
+	if !sysInfo.CPUShares || !sysInfo.CPUCfsQuota {
+		t.Fatalf("expected the cpu limits to be supported, got %+v", sysInfo.cgroupCPUInfo)
+	}
+	if sysInfo.BlkioWeight {
+		t.Fatal("expected the io limits to be unsupported without the io controller")
+	}
+	if !sysInfo.Cpuset || sysInfo.Cpus != "0-3" || sysInfo.Mems != "0" {
+		t.Fatalf("expected the cpuset 0-3 on node 0, got %+v", sysInfo.cgroupCpusetInfo)
+	}
+	if !sysInfo.PidsLimit || !sysInfo.CgroupDevicesEnabled {
+		t.Fatal("expected the pids and devices controllers to be enabled")
+	}
+}
@@ -5,3 +5,8 @@ func New(quiet bool) *SysInfo {
 	sysInfo := &SysInfo{}
 	return sysInfo
 }
+
+// IsCgroup2UnifiedMode returns false, windows has no cgroups.
+func IsCgroup2UnifiedMode() bool {
+	return false
+}
@@ -0,0 +1,380 @@
+// Package tracing records the spans of the operations of the daemon, such
+// as the API requests, the container starts or the image pulls, and exports
+// them to a collector accepting the Zipkin v2 JSON format, such as Zipkin or
+// Jaeger.
+//
+// The spans are recorded once a Tracer is set with SetTracer. Until then,
+// StartSpan returns a nil *Span, whose methods do nothing.
+package tracing
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/pkg/requestid"
+	"golang.org/x/net/context"
+)
+
+const (
+	// flushInterval is the interval between the exports of the spans.
+	flushInterval = 5 * time.Second
+	// flushSize is the number of pending spans exported at once, before
+	// the flushInterval elapses.
+	flushSize = 100
+	// maxPending is the number of pending spans above which the new ones
+	// are dropped, when the collector can't keep up.
+	maxPending = 10000
+)
+
+var (
+	// droppedSpans counts the spans dropped because of a collector too
+	// slow or unreachable, and exportedSpans the spans exported. They are
+	// exported on the /debug/vars endpoint.
+	droppedSpans  = expvar.NewInt("tracing_dropped_spans")
+	exportedSpans = expvar.NewInt("tracing_exported_spans")
+)
+
+var (
+	mu     sync.RWMutex
+	tracer *Tracer
+)
+
+// SetTracer sets the tracer recording the spans started afterwards, nil to
+// stop recording them.
+func SetTracer(t *Tracer) {
+	mu.Lock()
+	tracer = t
+	mu.Unlock()
+}
+
+func currentTracer() *Tracer {
+	mu.RLock()
+	defer mu.RUnlock()
+	return tracer
+}
+
+// Tracer exports the spans it records to a collector.
+type Tracer struct {
+	endpoint    string
+	serviceName string
+	sampleRate  float64
+	client      *http.Client
+
+	mu      sync.Mutex
+	pending []*zipkinSpan
+	flush   chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewTracer returns a tracer exporting the spans of serviceName to the
+// Zipkin v2 endpoint of a collector, such as http://zipkin:9411/api/v2/spans.
+// It samples sampleRate of the traces started by the daemon, between 0 and
+// 1, and the traces sampled by the clients which supplied a W3C traceparent
+// header.
+func NewTracer(endpoint, serviceName string, sampleRate float64) *Tracer {
+	t := &Tracer{
+		endpoint:    endpoint,
+		serviceName: serviceName,
+		sampleRate:  sampleRate,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		flush:       make(chan struct{}, 1),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	go t.run()
+	return t
+}
+
+// Close exports the pending spans and stops the tracer.
+func (t *Tracer) Close() error {
+	close(t.stop)
+	<-t.done
+	return nil
+}
+
+func (t *Tracer) run() {
+	defer close(t.done)
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-t.flush:
+		case <-t.stop:
+			t.export()
+			return
+		}
+		t.export()
+	}
+}
+
+func (t *Tracer) record(s *zipkinSpan) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.pending) >= maxPending {
+		droppedSpans.Add(1)
+		return
+	}
+	t.pending = append(t.pending, s)
+	if len(t.pending) == flushSize {
+		select {
+		case t.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// export sends the pending spans to the collector.
+func (t *Tracer) export() {
+	t.mu.Lock()
+	spans := t.pending
+	t.pending = nil
+	t.mu.Unlock()
+
+	for len(spans) > 0 {
+		n := len(spans)
+		if n > flushSize {
+			n = flushSize
+		}
+		if err := t.send(spans[:n]); err != nil {
+			logrus.Debugf("Failed to export %d tracing spans to %s: %v", n, t.endpoint, err)
+			droppedSpans.Add(int64(n))
+		} else {
+			exportedSpans.Add(int64(n))
+		}
+		spans = spans[n:]
+	}
+}
+
+func (t *Tracer) send(spans []*zipkinSpan) error {
+	b, err := json.Marshal(spans)
+	if err != nil {
+		return err
+	}
+	resp, err := t.client.Post(t.endpoint, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// sampled returns whether to sample a new trace.
+func (t *Tracer) sampled() bool {
+	if t.sampleRate >= 1 {
+		return true
+	}
+	return float64(randomUint64()>>11)/(1<<53) < t.sampleRate
+}
+
+// spanContext identifies a span within its trace.
+type spanContext struct {
+	traceID string
+	spanID  string
+	sampled bool
+}
+
+type key struct{}
+
+func fromContext(ctx context.Context) (spanContext, bool) {
+	if ctx == nil {
+		return spanContext{}, false
+	}
+	sc, ok := ctx.Value(key{}).(spanContext)
+	return sc, ok
+}
+
+// WithRemoteParent returns a copy of ctx in which the spans started are the
+// children of the span of the client identified by the W3C traceparent
+// header of h, if any.
+func WithRemoteParent(ctx context.Context, h http.Header) context.Context {
+	// version-traceid-parentid-flags, such as
+	// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+	parts := strings.Split(h.Get(requestid.TraceParentHeader), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return ctx
+	}
+	traceID, spanID := strings.ToLower(parts[1]), strings.ToLower(parts[2])
+	flags, err := hex.DecodeString(parts[3])
+	if !isHexID(traceID) || !isHexID(spanID) || err != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, key{}, spanContext{
+		traceID: traceID,
+		spanID:  spanID,
+		sampled: flags[0]&1 == 1,
+	})
+}
+
+// Span is an operation of a trace. The methods of a nil *Span do nothing,
+// so that the callers don't need to check whether the span is recorded.
+type Span struct {
+	tracer *Tracer
+	span   zipkinSpan
+	start  time.Time
+
+	mu       sync.Mutex
+	finished bool
+}
+
+// StartSpan starts a span named name, child of the span of ctx, or root of
+// a new trace if ctx has no span. It returns a nil span if no tracer is set
+// or if the trace isn't sampled, and a copy of ctx carrying the span.
+func StartSpan(ctx context.Context, name string) (*Span, context.Context) {
+	t := currentTracer()
+	if t == nil {
+		return nil, ctx
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	parent, ok := fromContext(ctx)
+	if !ok {
+		parent = spanContext{sampled: t.sampled()}
+		// The request IDs generated by the daemon, or supplied in a
+		// traceparent header, are trace IDs.
+		if id := requestid.FromContext(ctx); len(id) == 32 && isHexID(id) {
+			parent.traceID = id
+		} else {
+			parent.traceID = randomID() + randomID()
+		}
+	}
+	sc := spanContext{traceID: parent.traceID, spanID: randomID(), sampled: parent.sampled}
+	ctx = context.WithValue(ctx, key{}, sc)
+	if !sc.sampled {
+		return nil, ctx
+	}
+
+	start := time.Now()
+	s := &Span{
+		tracer: t,
+		start:  start,
+		span: zipkinSpan{
+			TraceID:       sc.traceID,
+			ID:            sc.spanID,
+			ParentID:      parent.spanID,
+			Name:          name,
+			Timestamp:     start.UnixNano() / int64(time.Microsecond),
+			LocalEndpoint: &zipkinEndpoint{ServiceName: t.serviceName},
+		},
+	}
+	if id := requestid.FromContext(ctx); id != "" {
+		s.SetTag(requestid.LogField, id)
+	}
+	return s, ctx
+}
+
+// SetTag annotates the span with the value of a tag, such as the ID of the
+// container it operates on.
+func (s *Span) SetTag(key, value string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.span.Tags == nil {
+		s.span.Tags = make(map[string]string)
+	}
+	s.span.Tags[key] = value
+}
+
+// SetKind sets the Zipkin kind of the span, such as SERVER for the API
+// requests.
+func (s *Span) SetKind(kind string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.span.Kind = kind
+	s.mu.Unlock()
+}
+
+// Finish ends the span, failed with err if it isn't nil, and records it.
+// The calls after the first one do nothing.
+func (s *Span) Finish(err error) {
+	if s == nil {
+		return
+	}
+	if err != nil {
+		s.SetTag("error", err.Error())
+	}
+	s.mu.Lock()
+	if s.finished {
+		s.mu.Unlock()
+		return
+	}
+	s.finished = true
+	duration := time.Since(s.start) / time.Microsecond
+	if duration < 1 {
+		duration = 1
+	}
+	s.span.Duration = int64(duration)
+	span := s.span
+	s.mu.Unlock()
+
+	s.tracer.record(&span)
+}
+
+// zipkinSpan is a span in the Zipkin v2 JSON format.
+type zipkinSpan struct {
+	TraceID       string            `json:"traceId"`
+	ID            string            `json:"id"`
+	ParentID      string            `json:"parentId,omitempty"`
+	Name          string            `json:"name"`
+	Kind          string            `json:"kind,omitempty"`
+	Timestamp     int64             `json:"timestamp"`
+	Duration      int64             `json:"duration"`
+	LocalEndpoint *zipkinEndpoint   `json:"localEndpoint,omitempty"`
+	Tags          map[string]string `json:"tags,omitempty"`
+}
+
+type zipkinEndpoint struct {
+	ServiceName string `json:"serviceName"`
+}
+
+// randomID returns a random span ID of 16 hexadecimal characters.
+func randomID() string {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, randomUint64())
+	return hex.EncodeToString(b)
+}
+
+func randomUint64() uint64 {
+	b := make([]byte, 8)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		panic(err) // This shouldn't happen
+	}
+	return binary.BigEndian.Uint64(b)
+}
+
+// isHexID returns whether id is a valid trace or span ID: lowercase
+// hexadecimal, and not only zeros.
+func isHexID(id string) bool {
+	if strings.Trim(id, "0") == "" {
+		return false
+	}
+	for _, c := range id {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,107 @@
+package tracing
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/docker/docker/pkg/requestid"
+	"golang.org/x/net/context"
+)
+
+type collector struct {
+	mu    sync.Mutex
+	spans []zipkinSpan
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var spans []zipkinSpan
+	if err := json.NewDecoder(r.Body).Decode(&spans); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.mu.Lock()
+	c.spans = append(c.spans, spans...)
+	c.mu.Unlock()
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func TestStartSpanWithoutTracer(t *testing.T) {
+	ctx := context.Background()
+	span, spanCtx := StartSpan(ctx, "start")
+	if span != nil || spanCtx != ctx {
+		t.Fatalf("Expected no span without a tracer, got %v", span)
+	}
+	// the methods of a nil span do nothing
+	span.SetTag("container", "web")
+	span.Finish(nil)
+}
+
+func TestTracerExport(t *testing.T) {
+	c := &collector{}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	tracer := NewTracer(srv.URL, "dockerd", 1)
+	SetTracer(tracer)
+	defer SetTracer(nil)
+
+	ctx := requestid.WithID(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736")
+	root, ctx := StartSpan(ctx, "POST /containers/{name:.*}/start")
+	root.SetKind("SERVER")
+	child, _ := StartSpan(ctx, "mount")
+	child.SetTag("container", "web")
+	child.Finish(errors.New("no space left on device"))
+	root.Finish(nil)
+	root.Finish(nil)
+	tracer.Close()
+
+	if len(c.spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %+v", c.spans)
+	}
+	childSpan, rootSpan := c.spans[0], c.spans[1]
+	if rootSpan.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || childSpan.TraceID != rootSpan.TraceID {
+		t.Fatalf("Expected the spans to belong to the trace of the request ID, got %+v", c.spans)
+	}
+	if rootSpan.ParentID != "" || childSpan.ParentID != rootSpan.ID || len(rootSpan.ID) != 16 {
+		t.Fatalf("Unexpected parents %+v", c.spans)
+	}
+	if rootSpan.Kind != "SERVER" || rootSpan.LocalEndpoint.ServiceName != "dockerd" || rootSpan.Duration < 1 {
+		t.Fatalf("Unexpected root span %+v", rootSpan)
+	}
+	if childSpan.Tags["container"] != "web" || childSpan.Tags["error"] != "no space left on device" || childSpan.Tags["request-id"] == "" {
+		t.Fatalf("Unexpected tags %v", childSpan.Tags)
+	}
+}
+
+func TestWithRemoteParent(t *testing.T) {
+	c := &collector{}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	tracer := NewTracer(srv.URL, "dockerd", 0)
+	SetTracer(tracer)
+	defer SetTracer(nil)
+
+	// the clients decide whether their traces are sampled
+	for _, traceparent := range []string{
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-00",
+		"",
+	} {
+		ctx := WithRemoteParent(context.Background(), http.Header{"Traceparent": {traceparent}})
+		span, _ := StartSpan(ctx, "GET /version")
+		span.Finish(nil)
+	}
+	tracer.Close()
+
+	if len(c.spans) != 1 {
+		t.Fatalf("Expected 1 span, got %+v", c.spans)
+	}
+	if s := c.spans[0]; s.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || s.ParentID != "00f067aa0ba902b7" {
+		t.Fatalf("Expected the span to be a child of the remote span, got %+v", s)
+	}
+}
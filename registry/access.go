@@ -0,0 +1,70 @@
+package registry
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/reference"
+)
+
+// CheckAccess returns an error if the repository name is under a blocked
+// registry or namespace, or if allowed registries are configured and name
+// is under none of them.
+func (s *Service) CheckAccess(name reference.Named) error {
+	return s.config.checkAccess(name)
+}
+
+func (config *serviceConfig) checkAccess(name reference.Named) error {
+	for _, scope := range config.blockedScopes {
+		if inRegistryScope(name, scope) {
+			return fmt.Errorf("access to %s is denied: %s is a blocked registry", name.FullName(), scope)
+		}
+	}
+	if len(config.allowedScopes) == 0 {
+		return nil
+	}
+	for _, scope := range config.allowedScopes {
+		if inRegistryScope(name, scope) {
+			return nil
+		}
+	}
+	return fmt.Errorf("access to %s is denied: it is not in an allowed registry", name.FullName())
+}
+
+// inRegistryScope returns whether the repository name is scope, or is under
+// the registry or namespace scope.
+func inRegistryScope(name reference.Named, scope string) bool {
+	return strings.HasPrefix(name.FullName()+"/", scope+"/")
+}
+
+// ValidateRegistryScope validates a registry hostname, optionally followed by
+// a namespace, such as "registry.example.com:5000" or "docker.io/library",
+// and returns it normalized. A scope without hostname is a namespace of the
+// default registry.
+func ValidateRegistryScope(val string) (string, error) {
+	// Name a repository in the scope to have it normalized like the
+	// repositories it is matched against.
+	scope := strings.TrimSuffix(val, "/")
+	named, err := reference.WithName(scope + "/x")
+	if err != nil {
+		return "", fmt.Errorf("invalid registry scope %q: %v", val, err)
+	}
+	if !strings.Contains(scope, "/") && (strings.ContainsAny(scope, ".:") || scope == "localhost") {
+		return named.Hostname(), nil
+	}
+	return strings.TrimSuffix(named.FullName(), "/x"), nil
+}
+
+func normalizeRegistryScopes(scopes []string) []string {
+	var normalized []string
+	for _, scope := range scopes {
+		s, err := ValidateRegistryScope(scope)
+		if err != nil {
+			logrus.Warnf("Ignoring %v", err)
+			continue
+		}
+		normalized = append(normalized, s)
+	}
+	return normalized
+}
@@ -0,0 +1,74 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/docker/docker/reference"
+)
+
+func TestValidateRegistryScope(t *testing.T) {
+	valid := map[string]string{
+		"registry.example.com":          "registry.example.com",
+		"registry.example.com:5000/":    "registry.example.com:5000",
+		"localhost":                     "localhost",
+		"docker.io":                     "docker.io",
+		"index.docker.io":               "docker.io",
+		"docker.io/library":             "docker.io/library",
+		"myorg":                         "docker.io/myorg",
+		"registry.example.com/team/sub": "registry.example.com/team/sub",
+	}
+	for val, expected := range valid {
+		scope, err := ValidateRegistryScope(val)
+		if err != nil {
+			t.Fatalf("expected %s to be valid, got %v", val, err)
+		}
+		if scope != expected {
+			t.Fatalf("expected %s to be normalized to %s, got %s", val, expected, scope)
+		}
+	}
+	for _, val := range []string{"", "UPPER", "https://registry.example.com", "registry.example.com//team"} {
+		if _, err := ValidateRegistryScope(val); err == nil {
+			t.Fatalf("expected %s to be invalid", val)
+		}
+	}
+}
+
+func TestCheckAccess(t *testing.T) {
+	config := newServiceConfig(ServiceOptions{
+		AllowedRegistries: []string{"registry.example.com", "docker.io/library"},
+		BlockedRegistries: []string{"registry.example.com/untrusted"},
+	})
+
+	allowed := []string{"busybox", "docker.io/library/ubuntu:16.04", "registry.example.com/app", "registry.example.com/team/app:v1"}
+	for _, name := range allowed {
+		ref, err := reference.ParseNamed(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := config.checkAccess(ref); err != nil {
+			t.Fatalf("expected access to %s, got %v", name, err)
+		}
+	}
+
+	denied := []string{"myorg/app", "registry.example.com:5000/app", "registry.example.com/untrusted/app", "registry.example.comx/app"}
+	for _, name := range denied {
+		ref, err := reference.ParseNamed(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := config.checkAccess(ref); err == nil {
+			t.Fatalf("expected access to %s to be denied", name)
+		}
+	}
+
+	// Without allowed registries, only the blocked ones are denied.
+	config = newServiceConfig(ServiceOptions{BlockedRegistries: []string{"docker.io"}})
+	ref, _ := reference.ParseNamed("registry.example.com/app")
+	if err := config.checkAccess(ref); err != nil {
+		t.Fatalf("expected access to %s, got %v", ref, err)
+	}
+	ref, _ = reference.ParseNamed("myorg/app")
+	if err := config.checkAccess(ref); err == nil {
+		t.Fatalf("expected access to %s to be denied", ref)
+	}
+}
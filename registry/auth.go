@@ -105,8 +105,12 @@ func (err fallbackError) Error() string {
 func loginV2(authConfig *types.AuthConfig, endpoint APIEndpoint, userAgent string) (string, string, error) {
 	logrus.Debugf("attempting v2 login to registry endpoint %s", strings.TrimRight(endpoint.URL.String(), "/")+"/v2/")
 
+	base := NewTransport(endpoint.TLSConfig)
+	if endpoint.Proxy != nil {
+		base.Proxy = endpoint.Proxy
+	}
 	modifiers := DockerHeaders(userAgent, nil)
-	authTransport := transport.NewTransport(NewTransport(endpoint.TLSConfig), modifiers...)
+	authTransport := transport.NewTransport(base, modifiers...)
 
 	challengeManager, foundV2, err := PingV2Registry(endpoint, authTransport)
 	if err != nil {
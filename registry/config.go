@@ -21,12 +21,32 @@ type ServiceOptions struct {
 	// V2Only controls access to legacy registries.  If it is set to true via the
 	// command line flag the daemon will not attempt to contact v1 legacy registries
 	V2Only bool `json:"disable-legacy-registry,omitempty"`
+
+	// AllowedRegistries, if not empty, restricts pulls and pushes to the
+	// repositories under these registries or namespaces.
+	AllowedRegistries []string `json:"allowed-registries,omitempty"`
+	// BlockedRegistries denies pulls and pushes to the repositories under
+	// these registries or namespaces.
+	BlockedRegistries []string `json:"blocked-registries,omitempty"`
+
+	// RegistryProxies maps registry hostnames to the URL of the proxy to
+	// reach them through, or to "direct" to bypass the daemon's proxy.
+	RegistryProxies map[string]string `json:"registry-proxies,omitempty"`
 }
 
 // serviceConfig holds daemon configuration for the registry service.
 type serviceConfig struct {
 	registrytypes.ServiceConfig
 	V2Only bool
+
+	// allowedScopes and blockedScopes hold the normalized registry
+	// access lists.
+	allowedScopes []string
+	blockedScopes []string
+
+	// proxies holds the parsed registry proxies, a nil URL meaning that
+	// the registry is reached directly.
+	proxies map[string]*url.URL
 }
 
 var (
@@ -78,6 +98,18 @@ func (options *ServiceOptions) InstallCliFlags(cmd *flag.FlagSet, usageFn func(s
 	cmd.Var(insecureRegistries, []string{"-insecure-registry"}, usageFn("Enable insecure registry communication"))
 
 	cmd.BoolVar(&options.V2Only, []string{"-disable-legacy-registry"}, false, usageFn("Do not contact legacy registries"))
+
+	allowedRegistries := opts.NewNamedListOptsRef("allowed-registries", &options.AllowedRegistries, ValidateRegistryScope)
+	cmd.Var(allowedRegistries, []string{"-allowed-registry"}, usageFn("Only allow pulls and pushes to this registry or namespace"))
+
+	blockedRegistries := opts.NewNamedListOptsRef("blocked-registries", &options.BlockedRegistries, ValidateRegistryScope)
+	cmd.Var(blockedRegistries, []string{"-blocked-registry"}, usageFn("Deny pulls and pushes to this registry or namespace"))
+
+	if options.RegistryProxies == nil {
+		options.RegistryProxies = make(map[string]string)
+	}
+	registryProxies := opts.NewNamedMapOpts("registry-proxies", options.RegistryProxies, ValidateRegistryProxy)
+	cmd.Var(registryProxies, []string{"-registry-proxy"}, usageFn("Set the proxy to a registry, as registry=proxy URL or registry=direct"))
 }
 
 // newServiceConfig returns a new instance of ServiceConfig
@@ -99,6 +131,9 @@ func newServiceConfig(options ServiceOptions) *serviceConfig {
 		},
 		V2Only: options.V2Only,
 	}
+	config.allowedScopes = normalizeRegistryScopes(options.AllowedRegistries)
+	config.blockedScopes = normalizeRegistryScopes(options.BlockedRegistries)
+	config.proxies = parseRegistryProxies(options.RegistryProxies)
 	// Split --insecure-registry into CIDR and registry-specific settings.
 	for _, r := range options.InsecureRegistries {
 		// Check if CIDR was passed to --insecure-registry
@@ -0,0 +1,99 @@
+package registry
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/reference"
+)
+
+// directProxy is the registry proxy value to reach a registry without proxy.
+const directProxy = "direct"
+
+// proxyFor returns the function selecting the proxy to endpoint, an endpoint
+// of the registry hostname, or nil if no proxy is configured for it. A proxy
+// configured for the host of a mirror takes precedence over the proxy of the
+// registry it mirrors.
+func (config *serviceConfig) proxyFor(hostname string, endpoint APIEndpoint) func(*http.Request) (*url.URL, error) {
+	proxyURL, ok := config.proxies[endpoint.URL.Host]
+	if !ok && !endpoint.Mirror {
+		proxyURL, ok = config.proxies[normalizeProxyHostname(hostname)]
+	}
+	if !ok {
+		return nil
+	}
+	if proxyURL == nil {
+		return func(*http.Request) (*url.URL, error) {
+			return nil, nil
+		}
+	}
+	return http.ProxyURL(proxyURL)
+}
+
+// ValidateRegistryProxy validates a registry proxy of the form
+// hostname=proxy, where proxy is the URL of a proxy or "direct".
+func ValidateRegistryProxy(val string) (string, error) {
+	parts := strings.SplitN(val, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", fmt.Errorf("invalid registry proxy %q, the format is registry=proxy", val)
+	}
+	if _, err := parseRegistryProxy(parts[1]); err != nil {
+		return "", err
+	}
+	return normalizeProxyHostname(parts[0]) + "=" + parts[1], nil
+}
+
+// ValidateProxyURL validates the URL of an HTTP proxy. A URL without scheme
+// is an HTTP proxy.
+func ValidateProxyURL(val string) (string, error) {
+	if _, err := parseProxyURL(val); err != nil {
+		return "", err
+	}
+	return val, nil
+}
+
+func parseRegistryProxies(proxies map[string]string) map[string]*url.URL {
+	parsed := make(map[string]*url.URL, len(proxies))
+	for hostname, proxy := range proxies {
+		proxyURL, err := parseRegistryProxy(proxy)
+		if err != nil {
+			logrus.Warnf("Ignoring the proxy to %s: %v", hostname, err)
+			continue
+		}
+		parsed[normalizeProxyHostname(hostname)] = proxyURL
+	}
+	return parsed
+}
+
+func parseRegistryProxy(proxy string) (*url.URL, error) {
+	if proxy == directProxy {
+		return nil, nil
+	}
+	return parseProxyURL(proxy)
+}
+
+func parseProxyURL(proxy string) (*url.URL, error) {
+	if !strings.Contains(proxy, "://") {
+		proxy = "http://" + proxy
+	}
+	proxyURL, err := url.Parse(proxy)
+	if err != nil || proxyURL.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q", proxy)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %s", proxyURL.Scheme)
+	}
+	return proxyURL, nil
+}
+
+func normalizeProxyHostname(hostname string) string {
+	if hostname == reference.LegacyDefaultHostname {
+		return reference.DefaultHostname
+	}
+	return hostname
+}
@@ -0,0 +1,89 @@
+package registry
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestValidateRegistryProxy(t *testing.T) {
+	valid := map[string]string{
+		"registry.example.com=http://proxy:3128":   "registry.example.com=http://proxy:3128",
+		"index.docker.io=proxy.example.com:8080":   "docker.io=proxy.example.com:8080",
+		"localhost:5000=direct":                    "localhost:5000=direct",
+		"registry.example.com=https://proxy:3129/": "registry.example.com=https://proxy:3129/",
+	}
+	for val, expected := range valid {
+		normalized, err := ValidateRegistryProxy(val)
+		if err != nil {
+			t.Fatalf("expected %s to be valid, got %v", val, err)
+		}
+		if normalized != expected {
+			t.Fatalf("expected %s to be normalized to %s, got %s", val, expected, normalized)
+		}
+	}
+	for _, val := range []string{"registry.example.com", "=http://proxy", "registry.example.com=", "registry.example.com=ftp://proxy"} {
+		if _, err := ValidateRegistryProxy(val); err == nil {
+			t.Fatalf("expected %s to be invalid", val)
+		}
+	}
+}
+
+func TestProxyFor(t *testing.T) {
+	config := newServiceConfig(ServiceOptions{
+		Mirrors: []string{"https://mirror.example.com"},
+		RegistryProxies: map[string]string{
+			"index.docker.io":      "http://hub-proxy:3128",
+			"registry.example.com": "direct",
+		},
+	})
+	s := &Service{config: config}
+
+	proxyOf := func(endpoint APIEndpoint) *url.URL {
+		req, err := http.NewRequest("GET", endpoint.URL.String(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		proxyURL, err := endpoint.Proxy(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return proxyURL
+	}
+
+	endpoints, err := s.LookupPullEndpoints("docker.io")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, endpoint := range endpoints {
+		if endpoint.Mirror {
+			if endpoint.Proxy != nil {
+				t.Fatalf("expected no proxy for mirror %s", endpoint.URL)
+			}
+			continue
+		}
+		if proxyURL := proxyOf(endpoint); proxyURL == nil || proxyURL.Host != "hub-proxy:3128" {
+			t.Fatalf("expected hub-proxy:3128 for %s, got %v", endpoint.URL, proxyURL)
+		}
+	}
+
+	endpoints, err = s.LookupPullEndpoints("registry.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, endpoint := range endpoints {
+		if endpoint.Proxy == nil || proxyOf(endpoint) != nil {
+			t.Fatalf("expected %s to be reached directly", endpoint.URL)
+		}
+	}
+
+	endpoints, err = s.LookupPullEndpoints("other.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, endpoint := range endpoints {
+		if endpoint.Proxy != nil {
+			t.Fatalf("expected no proxy for %s", endpoint.URL)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package registry
+
+import (
+	"net"
+	"net/url"
+
+	"github.com/docker/docker/pkg/resolver"
+	"github.com/docker/go-connections/sockets"
+	"golang.org/x/net/proxy"
+)
+
+// hostResolver, if not nil, resolves the hostnames of the registries instead
+// of the resolver of the host.
+var hostResolver *resolver.Resolver
+
+// SetResolver makes the transports returned by NewTransport, and the dialers
+// returned by Dialer, resolve hostnames with r. It must be called before the
+// first request to a registry.
+func SetResolver(r *resolver.Resolver) {
+	hostResolver = r
+}
+
+// Dialer returns the function connecting with direct, through the proxy of
+// the ALL_PROXY environment variable if it is set, and resolving hostnames
+// with the resolver set by SetResolver if any.
+func Dialer(direct *net.Dialer) func(network, addr string) (net.Conn, error) {
+	if hostResolver == nil {
+		if proxyDialer, err := sockets.DialerFromEnvironment(direct); err == nil {
+			return proxyDialer.Dial
+		}
+		return direct.Dial
+	}
+
+	dialer := hostResolver.Dialer(direct)
+	allProxy := sockets.GetProxyEnv("all_proxy")
+	if allProxy == "" {
+		return dialer.Dial
+	}
+	proxyURL, err := url.Parse(allProxy)
+	if err != nil {
+		return dialer.Dial
+	}
+	proxyDialer, err := proxy.FromURL(proxyURL, dialer)
+	if err != nil {
+		return dialer.Dial
+	}
+	if noProxy := sockets.GetProxyEnv("no_proxy"); noProxy != "" {
+		perHost := proxy.NewPerHost(proxyDialer, dialer)
+		perHost.AddFromString(noProxy)
+		return perHost.Dial
+	}
+	return proxyDialer.Dial
+}
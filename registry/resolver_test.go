@@ -0,0 +1,49 @@
+package registry
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/docker/docker/pkg/resolver"
+)
+
+func TestTransportResolver(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	defer ts.Close()
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, port, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := resolver.New(nil, false, []string{"registry.resolver.invalid:127.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetResolver(r)
+	defer SetResolver(nil)
+
+	client := &http.Client{Transport: NewTransport(nil)}
+	resp, err := client.Get("http://registry.resolver.invalid:" + port)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "ok" {
+		t.Fatalf("expected ok, got %s", body)
+	}
+}
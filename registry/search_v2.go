@@ -0,0 +1,91 @@
+package registry
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/docker/distribution/registry/client"
+	"github.com/docker/distribution/registry/client/auth"
+	"github.com/docker/distribution/registry/client/transport"
+	"github.com/docker/engine-api/types"
+	registrytypes "github.com/docker/engine-api/types/registry"
+	"golang.org/x/net/context"
+)
+
+const (
+	// catalogPageSize is the number of repositories requested per page of
+	// the catalog of a registry.
+	catalogPageSize = 100
+	// maxCatalogEntries bounds the number of repositories read from the
+	// catalog of a registry for a search.
+	maxCatalogEntries = 10000
+)
+
+// catalogScope is the token scope of the catalog of a registry.
+type catalogScope struct{}
+
+func (catalogScope) String() string {
+	return "registry:catalog:*"
+}
+
+// searchCatalog searches the catalog of the v2 registry of endpoint for the
+// repositories whose name contains term. The registries without catalog,
+// or denying access to it, return an error.
+func searchCatalog(endpoint APIEndpoint, term string, authConfig *types.AuthConfig, userAgent string, headers http.Header) (*registrytypes.SearchResults, error) {
+	base := NewTransport(endpoint.TLSConfig)
+	if endpoint.Proxy != nil {
+		base.Proxy = endpoint.Proxy
+	}
+	modifiers := DockerHeaders(userAgent, headers)
+	authTransport := transport.NewTransport(base, modifiers...)
+
+	challengeManager, _, err := PingV2Registry(endpoint, authTransport)
+	if err != nil {
+		return nil, err
+	}
+	if authConfig == nil {
+		authConfig = &types.AuthConfig{}
+	}
+	creds := loginCredentialStore{authConfig: authConfig}
+	tokenHandler := auth.NewTokenHandlerWithOptions(auth.TokenHandlerOptions{
+		Transport:   authTransport,
+		Credentials: creds,
+		Scopes:      []auth.Scope{catalogScope{}},
+		ClientID:    AuthClientID,
+	})
+	basicHandler := auth.NewBasicHandler(creds)
+	modifiers = append(modifiers, auth.NewAuthorizer(challengeManager, tokenHandler, basicHandler))
+	tr := transport.NewTransport(base, modifiers...)
+
+	ctx := context.Background()
+	reg, err := client.NewRegistry(ctx, endpoint.URL.String(), tr)
+	if err != nil {
+		return nil, err
+	}
+
+	results := &registrytypes.SearchResults{Query: term, Results: []registrytypes.SearchResult{}}
+	var (
+		entries = make([]string, catalogPageSize)
+		last    string
+		read    int
+	)
+	for read < maxCatalogEntries {
+		n, err := reg.Repositories(ctx, entries, last)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		for _, name := range entries[:n] {
+			if strings.Contains(name, term) {
+				results.Results = append(results.Results, registrytypes.SearchResult{Name: name})
+			}
+		}
+		read += n
+		if err == io.EOF || n == 0 {
+			break
+		}
+		last = entries[n-1]
+	}
+	results.NumResults = len(results.Results)
+	return results, nil
+}
@@ -0,0 +1,38 @@
+package registry
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestSearchCatalog(t *testing.T) {
+	repositories := []string{"library/busybox", "team/busybox-tools", "team/nginx"}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Docker-Distribution-API-Version", "registry/2.0")
+		switch r.URL.Path {
+		case "/v2/":
+		case "/v2/_catalog":
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string][]string{"repositories": repositories})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoint := APIEndpoint{URL: uri, Version: APIVersion2}
+	results, err := searchCatalog(endpoint, "busybox", nil, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results.NumResults != 2 || results.Results[0].Name != "library/busybox" || results.Results[1].Name != "team/busybox-tools" {
+		t.Fatalf("unexpected results %+v", results)
+	}
+}
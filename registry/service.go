@@ -92,8 +92,10 @@ func splitReposSearchTerm(reposName string) (string, string) {
 }
 
 // Search queries the public registry for images matching the specified
-// search terms, and returns the results.
-func (s *Service) Search(term string, authConfig *types.AuthConfig, userAgent string, headers map[string][]string) (*registrytypes.SearchResults, error) {
+// search terms, and returns at most limit results if limit is not 0. The
+// private registries are searched through their catalog when they support
+// the v2 protocol, and through the v1 search otherwise.
+func (s *Service) Search(term string, limit int, authConfig *types.AuthConfig, userAgent string, headers map[string][]string) (*registrytypes.SearchResults, error) {
 	if err := validateNoScheme(term); err != nil {
 		return nil, err
 	}
@@ -105,6 +107,14 @@ func (s *Service) Search(term string, authConfig *types.AuthConfig, userAgent st
 		return nil, err
 	}
 
+	if !index.Official {
+		results, err := s.searchV2(index.Name, remoteName, authConfig, userAgent, http.Header(headers))
+		if err == nil {
+			return results, nil
+		}
+		logrus.Debugf("Falling back to the v1 search of %s: %v", index.Name, err)
+	}
+
 	// *TODO: Search multiple indexes.
 	endpoint, err := NewV1Endpoint(index, userAgent, http.Header(headers))
 	if err != nil {
@@ -123,9 +133,35 @@ func (s *Service) Search(term string, authConfig *types.AuthConfig, userAgent st
 			localName = strings.SplitN(localName, "/", 2)[1]
 		}
 
-		return r.SearchRepositories(localName)
+		return r.SearchRepositories(localName, limit)
+	}
+	return r.SearchRepositories(remoteName, limit)
+}
+
+// searchV2 searches the catalog of the v2 endpoints of the registry
+// hostname for the repositories whose name contains term, returning the
+// results of the first endpoint that serves its catalog.
+func (s *Service) searchV2(hostname, term string, authConfig *types.AuthConfig, userAgent string, headers http.Header) (*registrytypes.SearchResults, error) {
+	endpoints, err := s.lookupEndpoints(hostname)
+	if err != nil {
+		return nil, err
+	}
+	lastErr := fmt.Errorf("no v2 endpoint found for %s", hostname)
+	for _, endpoint := range endpoints {
+		if endpoint.Version != APIVersion2 || endpoint.Mirror {
+			continue
+		}
+		results, err := searchCatalog(endpoint, term, authConfig, userAgent, headers)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		for i := range results.Results {
+			results.Results[i].Name = hostname + "/" + results.Results[i].Name
+		}
+		return results, nil
 	}
-	return r.SearchRepositories(remoteName)
+	return nil, lastErr
 }
 
 // ResolveRepository splits a repository name into its components
@@ -147,11 +183,14 @@ type APIEndpoint struct {
 	Official     bool
 	TrimHostname bool
 	TLSConfig    *tls.Config
+	// Proxy, if not nil, selects the proxy to the endpoint instead of the
+	// proxy settings of the daemon.
+	Proxy func(*http.Request) (*url.URL, error)
 }
 
 // ToV1Endpoint returns a V1 API endpoint based on the APIEndpoint
 func (e APIEndpoint) ToV1Endpoint(userAgent string, metaHeaders http.Header) (*V1Endpoint, error) {
-	return newV1Endpoint(*e.URL, e.TLSConfig, userAgent, metaHeaders)
+	return newV1Endpoint(*e.URL, e.TLSConfig, e.Proxy, userAgent, metaHeaders)
 }
 
 // TLSConfig constructs a client TLS configuration based on server defaults
@@ -191,15 +230,16 @@ func (s *Service) lookupEndpoints(hostname string) (endpoints []APIEndpoint, err
 		return nil, err
 	}
 
-	if s.config.V2Only {
-		return endpoints, nil
+	if !s.config.V2Only {
+		legacyEndpoints, err := s.lookupV1Endpoints(hostname)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, legacyEndpoints...)
 	}
 
-	legacyEndpoints, err := s.lookupV1Endpoints(hostname)
-	if err != nil {
-		return nil, err
+	for i := range endpoints {
+		endpoints[i].Proxy = s.config.proxyFor(hostname, endpoints[i])
 	}
-	endpoints = append(endpoints, legacyEndpoints...)
-
 	return endpoints, nil
 }
@@ -720,10 +720,14 @@ func shouldRedirect(response *http.Response) bool {
 	return response.StatusCode >= 300 && response.StatusCode < 400
 }
 
-// SearchRepositories performs a search against the remote repository
-func (r *Session) SearchRepositories(term string) (*registrytypes.SearchResults, error) {
+// SearchRepositories performs a search against the remote repository,
+// asking for at most limit results if limit is not 0.
+func (r *Session) SearchRepositories(term string, limit int) (*registrytypes.SearchResults, error) {
 	logrus.Debugf("Index server: %s", r.indexEndpoint)
 	u := r.indexEndpoint.String() + "search?q=" + url.QueryEscape(term)
+	if limit > 0 {
+		u += "&n=" + strconv.Itoa(limit)
+	}
 
 	req, err := http.NewRequest("GET", u, nil)
 	if err != nil {
@@ -22,6 +22,19 @@ var ErrRestartCanceled = errors.New("restart canceled")
 type RestartManager interface {
 	Cancel() error
 	ShouldRestart(exitCode uint32, hasBeenManuallyStopped bool, executionDuration time.Duration) (bool, chan error, error)
+	Status() Status
+}
+
+// Status is the state of a restart manager, as dumped by the daemon for
+// debugging.
+type Status struct {
+	Policy       string
+	RestartCount int
+	// Pending is set while a restart waits for its delay to expire at
+	// RestartAt.
+	Pending   bool      `json:",omitempty"`
+	RestartAt time.Time `json:",omitempty"`
+	Canceled  bool      `json:",omitempty"`
 }
 
 type restartManager struct {
@@ -31,6 +44,7 @@ type restartManager struct {
 	restartCount int
 	timeout      time.Duration
 	active       bool
+	restartAt    time.Time
 	cancel       chan struct{}
 	canceled     bool
 }
@@ -98,6 +112,7 @@ func (rm *restartManager) ShouldRestart(exitCode uint32, hasBeenManuallyStopped
 
 	unlockOnExit = false
 	rm.active = true
+	rm.restartAt = time.Now().Add(rm.timeout)
 	rm.Unlock()
 
 	ch := make(chan error)
@@ -117,6 +132,21 @@ func (rm *restartManager) ShouldRestart(exitCode uint32, hasBeenManuallyStopped
 	return true, ch, nil
 }
 
+func (rm *restartManager) Status() Status {
+	rm.Lock()
+	defer rm.Unlock()
+	status := Status{
+		Policy:       rm.policy.Name,
+		RestartCount: rm.restartCount,
+		Canceled:     rm.canceled,
+	}
+	if rm.active {
+		status.Pending = true
+		status.RestartAt = rm.restartAt
+	}
+	return status
+}
+
 func (rm *restartManager) Cancel() error {
 	rm.Do(func() {
 		rm.Lock()
@@ -32,3 +32,27 @@ func TestRestartManagerTimeoutReset(t *testing.T) {
 		t.Fatalf("restart manager should have a timeout of 100ms but has %s", rm.timeout)
 	}
 }
+
+func TestRestartManagerStatus(t *testing.T) {
+	rm := New(container.RestartPolicy{Name: "on-failure"}, 2)
+	if status := rm.Status(); status.Pending || status.RestartCount != 2 || status.Policy != "on-failure" {
+		t.Fatalf("unexpected status of an idle restart manager: %+v", status)
+	}
+	should, wait, err := rm.ShouldRestart(1, false, 1*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !should {
+		t.Fatal("container should be restarted")
+	}
+	status := rm.Status()
+	if !status.Pending || status.RestartCount != 3 || status.RestartAt.IsZero() {
+		t.Fatalf("unexpected status of a pending restart: %+v", status)
+	}
+	if err := <-wait; err != nil {
+		t.Fatal(err)
+	}
+	if status := rm.Status(); status.Pending {
+		t.Fatalf("expected no pending restart after the delay, got %+v", status)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"net"
 	"os"
 	"strings"
+
+	"github.com/docker/libnetwork/types"
 )
 
 // ValidateAttach validates that the specified string is a valid attach option.
@@ -60,6 +62,51 @@ func ValidateExtraHost(val string) (string, error) {
 	return val, nil
 }
 
+// ValidateEgressRule validates that the specified string is a valid egress
+// rule and returns it. An egress rule is in the form destination[:port[/proto]]
+// where destination is an IP address, a CIDR network or a domain name.
+func ValidateEgressRule(val string) (string, error) {
+	var r types.EgressRule
+	if err := r.FromString(val); err != nil {
+		return "", err
+	}
+	return val, nil
+}
+
+// ParseEgressRules parses the egress rules of an egress policy.
+func ParseEgressRules(vals []string) ([]types.EgressRule, error) {
+	rules := make([]types.EgressRule, len(vals))
+	for i, val := range vals {
+		if err := rules[i].FromString(val); err != nil {
+			return nil, err
+		}
+	}
+	return rules, nil
+}
+
+// ValidateIsolationRule validates that the specified string is a valid
+// isolation rule and returns it. An isolation rule is in the form
+// from=selector,to=selector where a selector is label:key[=value] or
+// alias:name.
+func ValidateIsolationRule(val string) (string, error) {
+	var r types.IsolationRule
+	if err := r.FromString(val); err != nil {
+		return "", err
+	}
+	return val, nil
+}
+
+// ParseIsolationRules parses the isolation rules of a network.
+func ParseIsolationRules(vals []string) ([]types.IsolationRule, error) {
+	rules := make([]types.IsolationRule, len(vals))
+	for i, val := range vals {
+		if err := rules[i].FromString(val); err != nil {
+			return nil, err
+		}
+	}
+	return rules, nil
+}
+
 // ValidateMACAddress validates a MAC address.
 func ValidateMACAddress(val string) (string, error) {
 	_, err := net.ParseMAC(strings.TrimSpace(val))
@@ -93,6 +93,107 @@ func TestValidateExtraHosts(t *testing.T) {
 	}
 }
 
+func TestValidateEgressRule(t *testing.T) {
+	valid := []string{
+		`10.0.0.0/8`,
+		`192.168.1.10:443`,
+		`192.168.1.10:53/udp`,
+		`2001:db8::/32`,
+		`[2001:db8::1]:443/tcp`,
+		`example.com`,
+		`*.example.com:443`,
+	}
+
+	invalid := map[string]string{
+		`10.0.0.0/33`:          `invalid network`,
+		`example.com:http`:     `invalid port`,
+		`example.com:0`:        `invalid port`,
+		`example.com:53/icmp`:  `invalid protocol`,
+		`-example.com`:         `invalid destination`,
+		`[2001:db8::1]443/tcp`: `invalid format`,
+	}
+
+	for _, rule := range valid {
+		if _, err := ValidateEgressRule(rule); err != nil {
+			t.Fatalf("ValidateEgressRule(`"+rule+"`) should succeed: error %v", err)
+		}
+	}
+
+	for rule, expectedError := range invalid {
+		if _, err := ValidateEgressRule(rule); err == nil {
+			t.Fatalf("ValidateEgressRule(`%q`) should have failed validation", rule)
+		} else {
+			if !strings.Contains(err.Error(), expectedError) {
+				t.Fatalf("ValidateEgressRule(`%q`) error should contain %q, got %v", rule, expectedError, err)
+			}
+		}
+	}
+}
+
+func TestParseEgressRules(t *testing.T) {
+	rules, err := ParseEgressRules([]string{`192.168.1.10:443`, `*.example.com:53/udp`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(rules))
+	}
+	if s := rules[0].String(); s != "192.168.1.10/32:443/tcp" {
+		t.Fatalf("expected 192.168.1.10/32:443/tcp, got %s", s)
+	}
+	if !rules[1].MatchDomain("www.example.com.") || rules[1].MatchDomain("example.com.") {
+		t.Fatalf("expected %s to match the subdomains of example.com only", rules[1].String())
+	}
+}
+
+func TestValidateIsolationRule(t *testing.T) {
+	valid := []string{
+		`from=label:tier=web,to=label:tier=db`,
+		`from=alias:web`,
+		`to=label:monitored`,
+	}
+
+	invalid := map[string]string{
+		`from=web`:                `invalid selector`,
+		`from=label:`:             `invalid selector`,
+		`from=name:web`:           `invalid selector`,
+		`src=alias:web`:           `invalid field`,
+		`from=alias:web,to`:       `invalid format`,
+		`from=alias:web,to=label`: `invalid selector`,
+	}
+
+	for _, rule := range valid {
+		if _, err := ValidateIsolationRule(rule); err != nil {
+			t.Fatalf("ValidateIsolationRule(`"+rule+"`) should succeed: error %v", err)
+		}
+	}
+
+	for rule, expectedError := range invalid {
+		if _, err := ValidateIsolationRule(rule); err == nil {
+			t.Fatalf("ValidateIsolationRule(`%q`) should have failed validation", rule)
+		} else if !strings.Contains(err.Error(), expectedError) {
+			t.Fatalf("ValidateIsolationRule(`%q`) error should contain %q, got %v", rule, expectedError, err)
+		}
+	}
+}
+
+func TestParseIsolationRules(t *testing.T) {
+	rules, err := ParseIsolationRules([]string{`from=label:tier=web,to=alias:db`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 1 {
+		t.Fatalf("expected 1 rule, got %d", len(rules))
+	}
+	r := rules[0]
+	if !r.From.Match(map[string]string{"tier": "web"}, nil) || r.From.Match(map[string]string{"tier": "db"}, nil) {
+		t.Fatalf("expected %s to select the containers labeled tier=web", r.String())
+	}
+	if !r.To.Match(nil, []string{"app", "db"}) || r.To.Match(nil, []string{"web"}) {
+		t.Fatalf("expected %s to select the containers aliased db", r.String())
+	}
+}
+
 func TestValidateMACAddress(t *testing.T) {
 	if _, err := ValidateMACAddress(`92:d0:c6:0a:29:33`); err != nil {
 		t.Fatalf("ValidateMACAddress(`92:d0:c6:0a:29:33`) got %s", err)
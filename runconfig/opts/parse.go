@@ -49,6 +49,7 @@ func Parse(cmd *flag.FlagSet, args []string) (*container.Config, *container.Host
 		flDNSSearch         = opts.NewListOpts(opts.ValidateDNSSearch)
 		flDNSOptions        = opts.NewListOpts(nil)
 		flExtraHosts        = opts.NewListOpts(ValidateExtraHost)
+		flEgressAllow       = opts.NewListOpts(ValidateEgressRule)
 		flVolumesFrom       = opts.NewListOpts(nil)
 		flEnvFile           = opts.NewListOpts(nil)
 		flCapAdd            = opts.NewListOpts(nil)
@@ -58,6 +59,8 @@ func Parse(cmd *flag.FlagSet, args []string) (*container.Config, *container.Host
 		flStorageOpt        = opts.NewListOpts(nil)
 		flLabelsFile        = opts.NewListOpts(nil)
 		flLoggingOpts       = opts.NewListOpts(nil)
+		flOCIHooks          = opts.NewListOpts(nil)
+		flNetworkDevices    = opts.NewListOpts(nil)
 		flPrivileged        = cmd.Bool([]string{"-privileged"}, false, "Give extended privileges to this container")
 		flPidMode           = cmd.String([]string{"-pid"}, "", "PID namespace to use")
 		flUTSMode           = cmd.String([]string{"-uts"}, "", "UTS namespace to use")
@@ -78,6 +81,7 @@ func Parse(cmd *flag.FlagSet, args []string) (*container.Config, *container.Host
 		flWorkingDir        = cmd.String([]string{"w", "-workdir"}, "", "Working directory inside the container")
 		flCPUShares         = cmd.Int64([]string{"#c", "-cpu-shares"}, 0, "CPU shares (relative weight)")
 		flCPUPercent        = cmd.Int64([]string{"-cpu-percent"}, 0, "CPU percent (Windows only)")
+		flCPUGroup          = cmd.String([]string{"-cpu-group"}, "", "ID of the CPU group to run a Hyper-V container in (Windows only)")
 		flCPUPeriod         = cmd.Int64([]string{"-cpu-period"}, 0, "Limit CPU CFS (Completely Fair Scheduler) period")
 		flCPUQuota          = cmd.Int64([]string{"-cpu-quota"}, 0, "Limit CPU CFS (Completely Fair Scheduler) quota")
 		flCpusetCpus        = cmd.String([]string{"-cpuset-cpus"}, "", "CPUs in which to allow execution (0-3, 0,1)")
@@ -93,6 +97,7 @@ func Parse(cmd *flag.FlagSet, args []string) (*container.Config, *container.Host
 		flIpcMode           = cmd.String([]string{"-ipc"}, "", "IPC namespace to use")
 		flPidsLimit         = cmd.Int64([]string{"-pids-limit"}, 0, "Tune container pids limit (set -1 for unlimited)")
 		flRestartPolicy     = cmd.String([]string{"-restart"}, "no", "Restart policy to apply when a container exits")
+		flJobMode           = cmd.Bool([]string{"-job"}, false, "Run as a job, not restarted once it succeeds")
 		flReadonlyRootfs    = cmd.Bool([]string{"-read-only"}, false, "Mount the container's root filesystem as read only")
 		flLoggingDriver     = cmd.String([]string{"-log-driver"}, "", "Logging driver for container")
 		flCgroupParent      = cmd.String([]string{"-cgroup-parent"}, "", "Optional parent cgroup for the container")
@@ -100,6 +105,7 @@ func Parse(cmd *flag.FlagSet, args []string) (*container.Config, *container.Host
 		flStopSignal        = cmd.String([]string{"-stop-signal"}, signal.DefaultStopSignal, fmt.Sprintf("Signal to stop a container, %v by default", signal.DefaultStopSignal))
 		flIsolation         = cmd.String([]string{"-isolation"}, "", "Container isolation technology")
 		flShmSize           = cmd.String([]string{"-shm-size"}, "", "Size of /dev/shm, default value is 64MB")
+		flNetworkQuota      = cmd.String([]string{"-network-quota"}, "", "Bytes received and sent after which a network_quota_exceeded event is emitted")
 	)
 
 	cmd.Var(&flAttach, []string{"a", "-attach"}, "Attach to STDIN, STDOUT or STDERR")
@@ -123,6 +129,7 @@ func Parse(cmd *flag.FlagSet, args []string) (*container.Config, *container.Host
 	cmd.Var(&flDNSSearch, []string{"-dns-search"}, "Set custom DNS search domains")
 	cmd.Var(&flDNSOptions, []string{"-dns-opt"}, "Set DNS options")
 	cmd.Var(&flExtraHosts, []string{"-add-host"}, "Add a custom host-to-IP mapping (host:ip)")
+	cmd.Var(&flEgressAllow, []string{"-egress-allow"}, "Allow the container to reach a network, IP or domain, with an optional port, refusing all other destinations")
 	cmd.Var(&flVolumesFrom, []string{"-volumes-from"}, "Mount volumes from the specified container(s)")
 	cmd.Var(&flCapAdd, []string{"-cap-add"}, "Add Linux capabilities")
 	cmd.Var(&flCapDrop, []string{"-cap-drop"}, "Drop Linux capabilities")
@@ -132,6 +139,8 @@ func Parse(cmd *flag.FlagSet, args []string) (*container.Config, *container.Host
 	cmd.Var(flUlimits, []string{"-ulimit"}, "Ulimit options")
 	cmd.Var(flSysctls, []string{"-sysctl"}, "Sysctl options")
 	cmd.Var(&flLoggingOpts, []string{"-log-opt"}, "Log driver options")
+	cmd.Var(&flOCIHooks, []string{"-oci-hook"}, "Add an OCI hook run by the runtime (<stage>=<path> [args...])")
+	cmd.Var(&flNetworkDevices, []string{"-network-device"}, "Move a network interface of the host into the container (<name>[/vf<index>][:<name in container>])")
 
 	cmd.Require(flag.Min, 1)
 
@@ -211,6 +220,17 @@ func Parse(cmd *flag.FlagSet, args []string) (*container.Config, *container.Host
 		}
 	}
 
+	var networkQuota int64
+	if *flNetworkQuota != "" {
+		networkQuota, err = units.RAMInBytes(*flNetworkQuota)
+		if err != nil {
+			return nil, nil, nil, cmd, err
+		}
+		if networkQuota <= 0 {
+			return nil, nil, nil, cmd, fmt.Errorf("invalid value: %s. Network quota must be positive", *flNetworkQuota)
+		}
+	}
+
 	// TODO FIXME units.RAMInBytes should have a uint64 version
 	var maxIOBandwidth int64
 	if *flIOMaxBandwidth != "" {
@@ -351,6 +371,16 @@ func Parse(cmd *flag.FlagSet, args []string) (*container.Config, *container.Host
 		return nil, nil, nil, cmd, err
 	}
 
+	hooks, err := parseOCIHooks(flOCIHooks.GetAll())
+	if err != nil {
+		return nil, nil, nil, cmd, err
+	}
+
+	networkDevices, err := parseNetworkDevices(flNetworkDevices.GetAll())
+	if err != nil {
+		return nil, nil, nil, cmd, err
+	}
+
 	resources := container.Resources{
 		CgroupParent:         *flCgroupParent,
 		Memory:               flMemory,
@@ -360,6 +390,7 @@ func Parse(cmd *flag.FlagSet, args []string) (*container.Config, *container.Host
 		KernelMemory:         KernelMemory,
 		OomKillDisable:       flOomKillDisable,
 		CPUPercent:           *flCPUPercent,
+		CPUGroup:             *flCPUGroup,
 		CPUShares:            *flCPUShares,
 		CPUPeriod:            *flCPUPeriod,
 		CpusetCpus:           *flCpusetCpus,
@@ -431,6 +462,9 @@ func Parse(cmd *flag.FlagSet, args []string) (*container.Config, *container.Host
 		CapDrop:        strslice.StrSlice(flCapDrop.GetAll()),
 		GroupAdd:       flGroupAdd.GetAll(),
 		RestartPolicy:  restartPolicy,
+		JobMode:        *flJobMode,
+		NetworkQuota:   networkQuota,
+		EgressAllow:    flEgressAllow.GetAll(),
 		SecurityOpt:    securityOpts,
 		StorageOpt:     storageOpts,
 		ReadonlyRootfs: *flReadonlyRootfs,
@@ -441,6 +475,8 @@ func Parse(cmd *flag.FlagSet, args []string) (*container.Config, *container.Host
 		Resources:      resources,
 		Tmpfs:          tmpfs,
 		Sysctls:        flSysctls.GetAll(),
+		Hooks:          hooks,
+		NetworkDevices: networkDevices,
 	}
 
 	// When allocating stdin in attached mode, close stdin at client disconnect
@@ -565,6 +601,73 @@ func parseStorageOpts(storageOpts []string) (map[string]string, error) {
 	return m, nil
 }
 
+// parseOCIHooks parses OCI hooks of the form "<stage>=<path> [args...]",
+// where the stage is prestart, poststart or poststop. The arguments of a
+// hook start with its path.
+func parseOCIHooks(hooks []string) (*container.Hooks, error) {
+	if len(hooks) == 0 {
+		return nil, nil
+	}
+	h := &container.Hooks{}
+	for _, hook := range hooks {
+		arr := strings.SplitN(hook, "=", 2)
+		if len(arr) != 2 {
+			return nil, fmt.Errorf("Invalid OCI hook %s: must be of the form <stage>=<path> [args...]", hook)
+		}
+		args := strings.Fields(arr[1])
+		if len(args) == 0 {
+			return nil, fmt.Errorf("Invalid OCI hook %s: the path is missing", hook)
+		}
+		oci := container.Hook{Path: args[0], Args: args}
+		switch arr[0] {
+		case "prestart":
+			h.Prestart = append(h.Prestart, oci)
+		case "poststart":
+			h.Poststart = append(h.Poststart, oci)
+		case "poststop":
+			h.Poststop = append(h.Poststop, oci)
+		default:
+			return nil, fmt.Errorf("Invalid OCI hook %s: the stage must be prestart, poststart or poststop", hook)
+		}
+	}
+	return h, nil
+}
+
+// parseNetworkDevices parses network devices of the form
+// "<name>[/vf<index>][:<name in container>]", where <index> is the index of
+// a virtual function of the SR-IOV physical function <name>.
+func parseNetworkDevices(devices []string) ([]container.NetworkDevice, error) {
+	var parsed []container.NetworkDevice
+	for _, device := range devices {
+		var d container.NetworkDevice
+		arr := strings.SplitN(device, ":", 2)
+		if len(arr) == 2 {
+			if arr[1] == "" {
+				return nil, fmt.Errorf("Invalid network device %s: the name in the container is empty", device)
+			}
+			d.ContainerName = arr[1]
+		}
+		d.Name = arr[0]
+		if i := strings.Index(arr[0], "/"); i >= 0 {
+			d.Name = arr[0][:i]
+			vf := arr[0][i+1:]
+			if !strings.HasPrefix(vf, "vf") {
+				return nil, fmt.Errorf("Invalid network device %s: must be of the form <name>[/vf<index>][:<name in container>]", device)
+			}
+			index, err := strconv.Atoi(vf[2:])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("Invalid network device %s: invalid virtual function index %s", device, vf[2:])
+			}
+			d.VirtualFunction = &index
+		}
+		if d.Name == "" {
+			return nil, fmt.Errorf("Invalid network device %s: the name is empty", device)
+		}
+		parsed = append(parsed, d)
+	}
+	return parsed, nil
+}
+
 // ParseRestartPolicy returns the parsed policy or an error indicating what is incorrect
 func ParseRestartPolicy(policy string) (container.RestartPolicy, error) {
 	p := container.RestartPolicy{}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"reflect"
 	"runtime"
 	"strings"
 	"testing"
@@ -584,6 +585,26 @@ func TestParseRestartPolicy(t *testing.T) {
 	}
 }
 
+func TestParseJobMode(t *testing.T) {
+	if _, hostconfig := mustParse(t, ""); hostconfig.JobMode {
+		t.Fatalf("Expected the job mode to be disabled by default")
+	}
+	if _, hostconfig := mustParse(t, "--job --restart=on-failure:3"); !hostconfig.JobMode || hostconfig.RestartPolicy.MaximumRetryCount != 3 {
+		t.Fatalf("Expected the job mode with 3 retries, got %+v and %+v", hostconfig.JobMode, hostconfig.RestartPolicy)
+	}
+}
+
+func TestParseNetworkQuota(t *testing.T) {
+	if _, hostconfig := mustParse(t, "--network-quota=10g"); hostconfig.NetworkQuota != 10*1024*1024*1024 {
+		t.Fatalf("Expected a network quota of 10g, got %d", hostconfig.NetworkQuota)
+	}
+	for _, quota := range []string{"0", "-1", "big"} {
+		if _, _, _, _, err := parseRun([]string{"--network-quota=" + quota, "img", "cmd"}); err == nil {
+			t.Fatalf("Expected an error for the network quota %s", quota)
+		}
+	}
+}
+
 func TestParseLoggingOpts(t *testing.T) {
 	// logging opts ko
 	if _, _, _, _, err := parseRun([]string{"--log-driver=none", "--log-opt=anything", "img", "cmd"}); err == nil || err.Error() != "invalid logging opts for driver none" {
@@ -599,6 +620,50 @@ func TestParseLoggingOpts(t *testing.T) {
 	}
 }
 
+func TestParseOCIHooks(t *testing.T) {
+	_, hostconfig, _, _, err := parseRun([]string{"--oci-hook=prestart=/opt/hooks/gpu --device 0", "--oci-hook=poststop=/opt/hooks/cleanup", "img", "cmd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hooks := hostconfig.Hooks
+	if hooks == nil || len(hooks.Prestart) != 1 || len(hooks.Poststart) != 0 || len(hooks.Poststop) != 1 {
+		t.Fatalf("Expected a prestart and a poststop hook, got %+v", hooks)
+	}
+	if hooks.Prestart[0].Path != "/opt/hooks/gpu" || !reflect.DeepEqual(hooks.Prestart[0].Args, []string{"/opt/hooks/gpu", "--device", "0"}) {
+		t.Fatalf("Unexpected prestart hook %+v", hooks.Prestart[0])
+	}
+
+	for _, invalid := range []string{"/opt/hooks/gpu", "prestart=", "poststart= ", "prerun=/opt/hooks/gpu"} {
+		if _, _, _, _, err := parseRun([]string{"--oci-hook=" + invalid, "img", "cmd"}); err == nil {
+			t.Fatalf("Expected an error for the OCI hook %s", invalid)
+		}
+	}
+	if _, hostconfig, _, _, err := parseRun([]string{"img", "cmd"}); err != nil || hostconfig.Hooks != nil {
+		t.Fatalf("Expected no hooks, got %+v, %v", hostconfig.Hooks, err)
+	}
+}
+
+func TestParseNetworkDevices(t *testing.T) {
+	_, hostconfig, _, _, err := parseRun([]string{"--network-device=eth1", "--network-device=ens2f0/vf3:net0", "img", "cmd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vf := 3
+	expected := []container.NetworkDevice{
+		{Name: "eth1"},
+		{Name: "ens2f0", VirtualFunction: &vf, ContainerName: "net0"},
+	}
+	if !reflect.DeepEqual(hostconfig.NetworkDevices, expected) {
+		t.Fatalf("Expected network devices %+v, got %+v", expected, hostconfig.NetworkDevices)
+	}
+
+	for _, invalid := range []string{"", ":net0", "eth1:", "/vf1", "ens2f0/1", "ens2f0/vf", "ens2f0/vf-1", "ens2f0/vfx"} {
+		if _, _, _, _, err := parseRun([]string{"--network-device=" + invalid, "img", "cmd"}); err == nil {
+			t.Fatalf("Expected an error for the network device %q", invalid)
+		}
+	}
+}
+
 func TestParseEnvfileVariables(t *testing.T) {
 	e := "open nonexistent: no such file or directory"
 	if runtime.GOOS == "windows" {
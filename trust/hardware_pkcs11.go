@@ -0,0 +1,24 @@
+// +build pkcs11
+
+package trust
+
+import (
+	"fmt"
+
+	"github.com/docker/notary/passphrase"
+	"github.com/docker/notary/trustmanager"
+	"github.com/docker/notary/trustmanager/yubikey"
+)
+
+// hardwareKeyStore returns the key store of the hardware token of the host,
+// which backs up the keys it generates to backupStore.
+func hardwareKeyStore(backupStore trustmanager.KeyStore, ret passphrase.Retriever) (trustmanager.KeyStore, error) {
+	if !yubikey.IsAccessible() {
+		return nil, fmt.Errorf("no hardware token is accessible through PKCS#11")
+	}
+	store, err := yubikey.NewYubiStore(backupStore, ret)
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
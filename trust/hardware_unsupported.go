@@ -0,0 +1,15 @@
+// +build !pkcs11
+
+package trust
+
+import (
+	"fmt"
+
+	"github.com/docker/notary/passphrase"
+	"github.com/docker/notary/trustmanager"
+)
+
+// hardwareKeyStore returns an error: the daemon is built without PKCS#11.
+func hardwareKeyStore(backupStore trustmanager.KeyStore, ret passphrase.Retriever) (trustmanager.KeyStore, error) {
+	return nil, fmt.Errorf("hardware tokens are not supported: the daemon is built without PKCS#11")
+}
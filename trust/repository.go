@@ -0,0 +1,314 @@
+package trust
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/distribution/registry/client/auth"
+	"github.com/docker/distribution/registry/client/transport"
+	"github.com/docker/docker/distribution"
+	"github.com/docker/docker/dockerversion"
+	"github.com/docker/docker/reference"
+	"github.com/docker/docker/registry"
+	"github.com/docker/engine-api/types"
+	registrytypes "github.com/docker/engine-api/types/registry"
+	"github.com/docker/notary/client"
+	"github.com/docker/notary/cryptoservice"
+	"github.com/docker/notary/passphrase"
+	"github.com/docker/notary/trustmanager"
+	"github.com/docker/notary/trustpinning"
+	"github.com/docker/notary/tuf/data"
+	"golang.org/x/net/context"
+)
+
+// RepositoryConfig stores the parameters of a request on the trust data of
+// a repository.
+type RepositoryConfig struct {
+	// Server is the URL of the notary server. It defaults to the one of
+	// the registry of the repository.
+	Server string
+	// MetaHeaders stores HTTP headers with metadata about the request
+	MetaHeaders map[string][]string
+	// AuthConfig holds the credentials of the registry, which the notary
+	// server authenticates with.
+	AuthConfig *types.AuthConfig
+	// Passphrases unlock the keys of the daemon.
+	Passphrases types.TrustPassphrases
+}
+
+// trustServer returns the URL of the notary server of index, unless server
+// is set.
+func trustServer(index *registrytypes.IndexInfo, server string) (string, error) {
+	if server != "" {
+		u, err := url.Parse(server)
+		if err != nil || u.Scheme != "https" {
+			return "", fmt.Errorf("valid https URL required for trust server, got %s", server)
+		}
+		return server, nil
+	}
+	if index.Official {
+		return registry.NotaryServer, nil
+	}
+	return "https://" + index.Name, nil
+}
+
+type credentialStore struct {
+	auth *types.AuthConfig
+}
+
+func (cs credentialStore) Basic(*url.URL) (string, string) {
+	return cs.auth.Username, cs.auth.Password
+}
+
+func (cs credentialStore) RefreshToken(*url.URL, string) string {
+	return cs.auth.IdentityToken
+}
+
+func (cs credentialStore) SetRefreshToken(*url.URL, string, string) {
+}
+
+// repository returns the notary repository of repoInfo, authenticated for
+// actions on the notary server.
+func (s *Service) repository(ctx context.Context, repoInfo *registry.RepositoryInfo, config *RepositoryConfig, ret passphrase.Retriever, actions ...string) (*client.NotaryRepository, error) {
+	server, err := trustServer(repoInfo.Index, config.Server)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := s.registryService.TLSConfig(u.Host)
+	if err != nil {
+		return nil, err
+	}
+	base := registry.NewTransport(tlsConfig)
+	modifiers := registry.DockerHeaders(dockerversion.DockerUserAgent(ctx), config.MetaHeaders)
+	authTransport := transport.NewTransport(base, modifiers...)
+
+	challengeManager := auth.NewSimpleChallengeManager()
+	pingClient := &http.Client{Transport: authTransport, Timeout: 5 * time.Second}
+	resp, err := pingClient.Get(server + "/v2/")
+	if err != nil {
+		return nil, fmt.Errorf("error contacting notary server %s: %v", server, err)
+	}
+	defer resp.Body.Close()
+	if err := challengeManager.AddResponse(resp); err != nil {
+		return nil, err
+	}
+
+	authConfig := config.AuthConfig
+	if authConfig == nil {
+		authConfig = &types.AuthConfig{}
+	}
+	creds := credentialStore{auth: authConfig}
+	tokenHandler := auth.NewTokenHandler(authTransport, creds, repoInfo.FullName(), actions...)
+	basicHandler := auth.NewBasicHandler(creds)
+	modifiers = append(modifiers, auth.NewAuthorizer(challengeManager, tokenHandler, basicHandler))
+	tr := transport.NewTransport(base, modifiers...)
+
+	return client.NewNotaryRepository(s.root, repoInfo.FullName(), server, tr, ret, trustpinning.TrustPinConfig{})
+}
+
+// Signers returns the roles of the trust data of the repository ref and
+// their keys.
+func (s *Service) Signers(ctx context.Context, ref reference.Named, config *RepositoryConfig) (types.TrustRepository, error) {
+	repoInfo, err := s.registryService.ResolveRepository(ref)
+	if err != nil {
+		return types.TrustRepository{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	repo, err := s.repository(ctx, repoInfo, config, retriever(config.Passphrases), "pull")
+	if err != nil {
+		return types.TrustRepository{}, err
+	}
+	roles, err := repo.ListRoles()
+	if err != nil {
+		return types.TrustRepository{}, err
+	}
+
+	result := types.TrustRepository{Name: repoInfo.FullName(), Signers: []types.TrustRole{}, BaseRoles: []types.TrustRole{}}
+	for _, r := range roles {
+		role := types.TrustRole{Name: r.Name, KeyIDs: r.KeyIDs, Paths: r.Paths}
+		sort.Strings(role.KeyIDs)
+		if data.IsDelegation(r.Name) {
+			role.Name = strings.TrimPrefix(r.Name, data.CanonicalTargetsRole+"/")
+			result.Signers = append(result.Signers, role)
+		} else {
+			result.BaseRoles = append(result.BaseRoles, role)
+		}
+	}
+	sort.Sort(byRoleName(result.Signers))
+	sort.Sort(byRoleName(result.BaseRoles))
+	return result, nil
+}
+
+type byRoleName []types.TrustRole
+
+func (r byRoleName) Len() int           { return len(r) }
+func (r byRoleName) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+func (r byRoleName) Less(i, j int) bool { return r[i].Name < r[j].Name }
+
+// RotateKey replaces the key of role in the trust data of the repository ref
+// with a new key of the daemon, or with a key of the notary server if
+// serverManaged is set, and publishes the trust data.
+func (s *Service) RotateKey(ctx context.Context, ref reference.Named, role string, serverManaged bool, config *RepositoryConfig) error {
+	switch role {
+	case data.CanonicalRootRole, data.CanonicalTargetsRole, data.CanonicalSnapshotRole, data.CanonicalTimestampRole:
+	default:
+		return fmt.Errorf("invalid role %s: the keys of root, targets, snapshot and timestamp rotate", role)
+	}
+	repoInfo, err := s.registryService.ResolveRepository(ref)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	repo, err := s.repository(ctx, repoInfo, config, retriever(config.Passphrases), "push", "pull")
+	if err != nil {
+		return err
+	}
+	if err := repo.Update(false); err != nil {
+		return err
+	}
+	return repo.RotateKey(role, serverManaged)
+}
+
+// Sign signs the tag ref, as it is in its registry, into the trust data of
+// its repository and publishes the trust data. The trust data of a
+// repository without any is initialized with a root key of the daemon, from
+// the hardware token if hardware is set.
+func (s *Service) Sign(ctx context.Context, ref reference.NamedTagged, hardware bool, config *RepositoryConfig) (types.TrustTarget, error) {
+	repoInfo, err := s.registryService.ResolveRepository(ref)
+	if err != nil {
+		return types.TrustTarget{}, err
+	}
+	desc, err := distribution.RemoteTagDescriptor(ctx, ref, &distribution.RemoteTagsConfig{
+		MetaHeaders:     config.MetaHeaders,
+		AuthConfig:      config.AuthConfig,
+		RegistryService: s.registryService,
+	})
+	if err != nil {
+		return types.TrustTarget{}, err
+	}
+	h, err := hex.DecodeString(desc.Digest.Hex())
+	if err != nil {
+		return types.TrustTarget{}, err
+	}
+	target := &client.Target{
+		Name:   ref.Tag(),
+		Hashes: data.Hashes{string(desc.Digest.Algorithm()): h},
+		Length: desc.Size,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ret := retriever(config.Passphrases)
+	repo, err := s.repository(ctx, repoInfo, config, ret, "push", "pull")
+	if err != nil {
+		return types.TrustTarget{}, err
+	}
+
+	var roles []string
+	switch err := repo.Update(false); err.(type) {
+	case client.ErrRepoNotInitialized, client.ErrRepositoryNotExist:
+		rootKeyID, err := s.rootKeyID(repo, hardware, ret)
+		if err != nil {
+			return types.TrustTarget{}, err
+		}
+		// The notary server manages the snapshot key, as for a push.
+		if err := repo.Initialize(rootKeyID, data.CanonicalSnapshotRole); err != nil {
+			return types.TrustTarget{}, err
+		}
+		roles = []string{data.CanonicalTargetsRole}
+	case nil:
+		if roles, err = signableRoles(repo, target); err != nil {
+			return types.TrustTarget{}, err
+		}
+	default:
+		return types.TrustTarget{}, err
+	}
+
+	if err := repo.AddTarget(target, roles...); err != nil {
+		return types.TrustTarget{}, err
+	}
+	if err := repo.Publish(); err != nil {
+		return types.TrustTarget{}, err
+	}
+	return types.TrustTarget{Tag: ref.Tag(), Digest: desc.Digest.String(), Size: desc.Size, Roles: roles}, nil
+}
+
+// rootKeyID returns the ID of the root key to initialize the trust data of
+// repo with: the first root key of the hardware token if hardware is set, or
+// of the daemon otherwise. A root key is generated if there is none.
+func (s *Service) rootKeyID(repo *client.NotaryRepository, hardware bool, ret passphrase.Retriever) (string, error) {
+	cs := repo.CryptoService
+	if hardware {
+		fileStore, err := trustmanager.NewKeyFileStore(s.root, ret)
+		if err != nil {
+			return "", err
+		}
+		hardwareStore, err := hardwareKeyStore(fileStore, ret)
+		if err != nil {
+			return "", err
+		}
+		cs = cryptoservice.NewCryptoService(hardwareStore)
+	}
+
+	keys := cs.ListKeys(data.CanonicalRootRole)
+	if len(keys) > 0 {
+		sort.Strings(keys)
+		return keys[0], nil
+	}
+	pubKey, err := cs.Create(data.CanonicalRootRole, "", data.ECDSAKey)
+	if err != nil {
+		return "", err
+	}
+	return pubKey.ID(), nil
+}
+
+// signableRoles returns the roles target is signed into: the direct
+// delegations of the targets role the daemon has a key of and whose paths
+// allow target, or the targets role if the repository has no delegation.
+func signableRoles(repo *client.NotaryRepository, target *client.Target) ([]string, error) {
+	delegations, err := repo.GetDelegationRoles()
+	if err != nil {
+		return nil, err
+	}
+	if len(delegations) == 0 {
+		return []string{data.CanonicalTargetsRole}, nil
+	}
+
+	keyIDs := make(map[string]bool)
+	for fullKeyID := range repo.CryptoService.ListAllKeys() {
+		keyIDs[path.Base(fullKeyID)] = true
+	}
+	var roles []string
+	for _, delegation := range delegations {
+		if path.Dir(delegation.Name) != data.CanonicalTargetsRole || !delegation.CheckPaths(target.Name) {
+			continue
+		}
+		for _, keyID := range delegation.KeyIDs {
+			if keyIDs[keyID] {
+				roles = append(roles, delegation.Name)
+				break
+			}
+		}
+	}
+	if len(roles) == 0 {
+		return nil, fmt.Errorf("the daemon has no key of a signer allowed to sign %s", target.Name)
+	}
+	return roles, nil
+}
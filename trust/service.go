@@ -0,0 +1,176 @@
+// Package trust manages the content trust signing keys of the daemon, and
+// signs the tags of repositories with them on behalf of the clients.
+package trust
+
+import (
+	"encoding/pem"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/registry"
+	"github.com/docker/engine-api/types"
+	"github.com/docker/notary"
+	"github.com/docker/notary/cryptoservice"
+	"github.com/docker/notary/passphrase"
+	"github.com/docker/notary/trustmanager"
+	"github.com/docker/notary/tuf/data"
+)
+
+// hardwareAlias is the alias the hardware token asks the PIN of its user
+// with.
+const hardwareAlias = "yubikey"
+
+// Service manages the content trust keys stored in a directory of the
+// daemon, and the trust data of the repositories signed with them.
+type Service struct {
+	// mu serializes the changes to the keys and to the trust data.
+	mu              sync.Mutex
+	root            string
+	registryService *registry.Service
+}
+
+// NewService returns a Service storing its keys and the trust data of the
+// repositories under root.
+func NewService(root string, registryService *registry.Service) (*Service, error) {
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return nil, err
+	}
+	return &Service{root: root, registryService: registryService}, nil
+}
+
+// retriever returns the passphrase retriever answering with the secrets of a
+// request: the root passphrase for the root keys, the PIN for the hardware
+// token and the repository passphrase for the other keys. A passphrase is
+// only tried once since there is nobody to ask for another one.
+func retriever(p types.TrustPassphrases) passphrase.Retriever {
+	return func(keyName, alias string, createNew bool, attempts int) (string, bool, error) {
+		if attempts > 0 {
+			return "", true, nil
+		}
+		var secret string
+		switch alias {
+		case data.CanonicalRootRole:
+			secret = p.RootPassphrase
+		case hardwareAlias:
+			secret = p.PIN
+		default:
+			secret = p.RepositoryPassphrase
+		}
+		if secret == "" {
+			return "", true, fmt.Errorf("no passphrase given for the %s key %s", alias, keyName)
+		}
+		return secret, false, nil
+	}
+}
+
+// Keys returns the keys of the daemon, sorted by role then by ID.
+func (s *Service) Keys() ([]types.TrustKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fileStore, err := trustmanager.NewKeyFileStore(s.root, retriever(types.TrustPassphrases{}))
+	if err != nil {
+		return nil, err
+	}
+	keys := []types.TrustKey{}
+	listed := make(map[string]bool)
+	// The keys of the hardware token are also in the file store, which
+	// they were backed up to when they were generated.
+	if hardwareStore, err := hardwareKeyStore(fileStore, retriever(types.TrustPassphrases{})); err == nil {
+		for id, info := range hardwareStore.ListKeys() {
+			keys = append(keys, types.TrustKey{ID: id, Role: info.Role, GUN: info.Gun, Hardware: true})
+			listed[id] = true
+		}
+	}
+	for id, info := range fileStore.ListKeys() {
+		if !listed[id] {
+			keys = append(keys, types.TrustKey{ID: id, Role: info.Role, GUN: info.Gun})
+		}
+	}
+	sort.Sort(byRoleAndID(keys))
+	return keys, nil
+}
+
+type byRoleAndID []types.TrustKey
+
+func (k byRoleAndID) Len() int      { return len(k) }
+func (k byRoleAndID) Swap(i, j int) { k[i], k[j] = k[j], k[i] }
+func (k byRoleAndID) Less(i, j int) bool {
+	if k[i].Role != k[j].Role {
+		return k[i].Role < k[j].Role
+	}
+	return k[i].ID < k[j].ID
+}
+
+// GenerateKey generates a root key, or the key of the signer req.Name,
+// encrypted with the passphrase of req for its role.
+func (s *Service) GenerateKey(req types.TrustKeyCreateRequest) (types.TrustKey, error) {
+	role := req.Name
+	switch {
+	case req.Root && req.Name != "":
+		return types.TrustKey{}, fmt.Errorf("a root key does not have a signer name")
+	case req.Root:
+		role = data.CanonicalRootRole
+		if req.RootPassphrase == "" {
+			return types.TrustKey{}, fmt.Errorf("a root key requires a root passphrase")
+		}
+	case req.Name == "":
+		return types.TrustKey{}, fmt.Errorf("a signer key requires the name of its signer")
+	case data.ValidRole(req.Name) || data.IsDelegation(req.Name):
+		return types.TrustKey{}, fmt.Errorf("invalid signer name %s: it is the name of a role", req.Name)
+	case req.RepositoryPassphrase == "":
+		return types.TrustKey{}, fmt.Errorf("a signer key requires a repository passphrase")
+	}
+	if req.Hardware && !req.Root {
+		return types.TrustKey{}, fmt.Errorf("the hardware token only holds root keys")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ret := retriever(req.TrustPassphrases)
+	fileStore, err := trustmanager.NewKeyFileStore(s.root, ret)
+	if err != nil {
+		return types.TrustKey{}, err
+	}
+	cs := cryptoservice.NewCryptoService(fileStore)
+	if req.Hardware {
+		hardwareStore, err := hardwareKeyStore(fileStore, ret)
+		if err != nil {
+			return types.TrustKey{}, err
+		}
+		cs = cryptoservice.NewCryptoService(hardwareStore)
+	}
+	pubKey, err := cs.Create(role, "", data.ECDSAKey)
+	if err != nil {
+		return types.TrustKey{}, err
+	}
+	return types.TrustKey{
+		ID:        pubKey.ID(),
+		Role:      role,
+		Hardware:  req.Hardware,
+		PublicKey: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubKey.Public()})),
+	}, nil
+}
+
+// BackupKeys returns a tar archive of the private keys of the daemon, as
+// encrypted in their files. The root keys of the hardware token are part of
+// it since they are backed up to files when they are generated.
+func (s *Service) BackupKeys() (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	privateDir := filepath.Join(s.root, notary.PrivDir)
+	if _, err := os.Stat(privateDir); err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("the daemon has no content trust key")
+		}
+		return nil, err
+	}
+	return archive.TarWithOptions(privateDir, &archive.TarOptions{Compression: archive.Uncompressed})
+}
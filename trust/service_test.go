@@ -0,0 +1,168 @@
+package trust
+
+import (
+	"archive/tar"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/registry"
+	"github.com/docker/engine-api/types"
+	registrytypes "github.com/docker/engine-api/types/registry"
+)
+
+func newTestService(t *testing.T) (*Service, func()) {
+	root, err := ioutil.TempDir("", "trust-service-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewService(root, registry.NewService(registry.ServiceOptions{}))
+	if err != nil {
+		os.RemoveAll(root)
+		t.Fatal(err)
+	}
+	return s, func() { os.RemoveAll(root) }
+}
+
+func TestGenerateKey(t *testing.T) {
+	s, cleanup := newTestService(t)
+	defer cleanup()
+
+	rootKey, err := s.GenerateKey(types.TrustKeyCreateRequest{
+		Root:             true,
+		TrustPassphrases: types.TrustPassphrases{RootPassphrase: "rootpassphrase"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rootKey.Role != "root" || rootKey.ID == "" || !strings.HasPrefix(rootKey.PublicKey, "-----BEGIN PUBLIC KEY-----") {
+		t.Fatalf("unexpected root key %+v", rootKey)
+	}
+	signerKey, err := s.GenerateKey(types.TrustKeyCreateRequest{
+		Name:             "alice",
+		TrustPassphrases: types.TrustPassphrases{RepositoryPassphrase: "repopassphrase"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := s.Keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []types.TrustKey{{ID: signerKey.ID, Role: "alice"}, {ID: rootKey.ID, Role: "root"}}
+	if len(keys) != len(expected) || keys[0] != expected[0] || keys[1] != expected[1] {
+		t.Fatalf("expected %+v, got %+v", expected, keys)
+	}
+}
+
+func TestGenerateKeyInvalid(t *testing.T) {
+	s, cleanup := newTestService(t)
+	defer cleanup()
+
+	invalid := []types.TrustKeyCreateRequest{
+		// a root key without passphrase
+		{Root: true},
+		// a root key with a signer name
+		{Root: true, Name: "alice", TrustPassphrases: types.TrustPassphrases{RootPassphrase: "rootpassphrase"}},
+		// a signer key without name
+		{TrustPassphrases: types.TrustPassphrases{RepositoryPassphrase: "repopassphrase"}},
+		// a signer key named after a role
+		{Name: "targets/alice", TrustPassphrases: types.TrustPassphrases{RepositoryPassphrase: "repopassphrase"}},
+		// a signer key without passphrase
+		{Name: "alice"},
+		// a signer key on the hardware token
+		{Name: "alice", Hardware: true, TrustPassphrases: types.TrustPassphrases{RepositoryPassphrase: "repopassphrase"}},
+	}
+	for _, req := range invalid {
+		if key, err := s.GenerateKey(req); err == nil {
+			t.Fatalf("%+v: expected an error, got %+v", req, key)
+		}
+	}
+}
+
+func TestBackupKeys(t *testing.T) {
+	s, cleanup := newTestService(t)
+	defer cleanup()
+
+	if _, err := s.BackupKeys(); err == nil {
+		t.Fatal("expected an error without keys")
+	}
+	key, err := s.GenerateKey(types.TrustKeyCreateRequest{
+		Root:             true,
+		TrustPassphrases: types.TrustPassphrases{RootPassphrase: "rootpassphrase"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := s.BackupKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+	tr := tar.NewReader(archive)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			t.Fatalf("the backup has no file of the key %s", key.ID)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(hdr.Name, key.ID) {
+			continue
+		}
+		content, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(content), "Proc-Type: 4,ENCRYPTED") {
+			t.Fatalf("the key %s is not encrypted in the backup:\n%s", key.ID, content)
+		}
+		return
+	}
+}
+
+func TestRetriever(t *testing.T) {
+	ret := retriever(types.TrustPassphrases{RootPassphrase: "root", RepositoryPassphrase: "repository", PIN: "123456"})
+	cases := map[string]string{"root": "root", "targets": "repository", "targets/alice": "repository", "yubikey": "123456"}
+	for alias, expected := range cases {
+		secret, giveup, err := ret("key", alias, false, 0)
+		if err != nil || giveup || secret != expected {
+			t.Fatalf("%s: expected %q, got %q (giveup %v, err %v)", alias, expected, secret, giveup, err)
+		}
+	}
+	if _, giveup, _ := ret("key", "root", false, 1); !giveup {
+		t.Fatal("expected to give up after a wrong passphrase")
+	}
+	if _, giveup, err := retriever(types.TrustPassphrases{})("key", "root", false, 0); !giveup || err == nil {
+		t.Fatal("expected an error without passphrase")
+	}
+}
+
+func TestTrustServer(t *testing.T) {
+	cases := []struct {
+		index  registrytypes.IndexInfo
+		server string
+		url    string
+	}{
+		{registrytypes.IndexInfo{Name: "docker.io", Official: true}, "", registry.NotaryServer},
+		{registrytypes.IndexInfo{Name: "registry.example.com:5000"}, "", "https://registry.example.com:5000"},
+		{registrytypes.IndexInfo{Name: "docker.io", Official: true}, "https://notary.example.com", "https://notary.example.com"},
+	}
+	for _, tc := range cases {
+		u, err := trustServer(&tc.index, tc.server)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u != tc.url {
+			t.Fatalf("%s %q: expected %s, got %s", tc.index.Name, tc.server, tc.url, u)
+		}
+	}
+	if _, err := trustServer(&registrytypes.IndexInfo{Name: "docker.io"}, "http://notary.example.com"); err == nil {
+		t.Fatal("expected an error for a server without https")
+	}
+}
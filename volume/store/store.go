@@ -135,7 +135,11 @@ func (s *VolumeStore) List() ([]volume.Volume, []string, error) {
 			continue
 		}
 
-		out = append(out, v)
+		labels, err := s.getLabels(name)
+		if err != nil {
+			logrus.Warnf("Failed to get the labels of volume %s: %v", v.Name(), err)
+		}
+		out = append(out, volumeWithLabels{v, labels})
 		s.locks.Unlock(v.Name())
 	}
 	return out, warnings, nil
@@ -333,34 +337,41 @@ func (s *VolumeStore) Get(name string) (volume.Volume, error) {
 	return v, nil
 }
 
-// getVolume requests the volume, if the driver info is stored it just accesses that driver,
-// if the driver is unknown it probes all drivers until it finds the first volume with that name.
-// it is expected that callers of this function hold any necessary locks
-func (s *VolumeStore) getVolume(name string) (volume.Volume, error) {
+// getLabels returns the labels of the volume name stored in the metadata of
+// the volumes.
+func (s *VolumeStore) getLabels(name string) (map[string]string, error) {
 	labels := map[string]string{}
+	if s.db == nil {
+		return labels, nil
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(volumeBucketName))
+		data := b.Get([]byte(name))
 
-	if s.db != nil {
-		// get meta
-		if err := s.db.Update(func(tx *bolt.Tx) error {
-			b := tx.Bucket([]byte(volumeBucketName))
-			data := b.Get([]byte(name))
+		if string(data) == "" {
+			return nil
+		}
 
-			if string(data) == "" {
-				return nil
-			}
+		var meta volumeMetadata
+		buf := bytes.NewBuffer(data)
 
-			var meta volumeMetadata
-			buf := bytes.NewBuffer(data)
+		if err := json.NewDecoder(buf).Decode(&meta); err != nil {
+			return err
+		}
+		labels = meta.Labels
 
-			if err := json.NewDecoder(buf).Decode(&meta); err != nil {
-				return err
-			}
-			labels = meta.Labels
+		return nil
+	})
+	return labels, err
+}
 
-			return nil
-		}); err != nil {
-			return nil, err
-		}
+// getVolume requests the volume, if the driver info is stored it just accesses that driver,
+// if the driver is unknown it probes all drivers until it finds the first volume with that name.
+// it is expected that callers of this function hold any necessary locks
+func (s *VolumeStore) getVolume(name string) (volume.Volume, error) {
+	labels, err := s.getLabels(name)
+	if err != nil {
+		return nil, err
 	}
 
 	logrus.Debugf("Getting volume reference for name: %s", name)